@@ -1,34 +1,64 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/coroot/coroot/api/views"
+	"github.com/coroot/coroot/api/views/global"
 	"github.com/coroot/coroot/auditor"
 	"github.com/coroot/coroot/cache"
+	"github.com/coroot/coroot/ci"
+	"github.com/coroot/coroot/clickhouse"
 	cloud_pricing "github.com/coroot/coroot/cloud-pricing"
 	"github.com/coroot/coroot/constructor"
 	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/flux"
+	"github.com/coroot/coroot/i18n"
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/notifications"
 	"github.com/coroot/coroot/prom"
 	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/tsdb"
 	"github.com/coroot/coroot/utils"
 	"github.com/gorilla/mux"
+	"io"
 	"k8s.io/klog"
 	"net/http"
+	"net/url"
+	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Api struct {
-	cache    *cache.Cache
-	db       *db.DB
-	pricing  *cloud_pricing.Manager
-	readOnly bool
+	cache      *cache.Cache
+	db         *db.DB
+	pricing    *cloud_pricing.Manager
+	tsdb       *tsdb.Storage
+	readOnly   bool
+	reports    *reportCache
+	clickhouse *clickhouseIngester
+
+	worldMemoryLimit int64
 }
 
-func NewApi(cache *cache.Cache, db *db.DB, pricing *cloud_pricing.Manager, readOnly bool) *Api {
-	return &Api{cache: cache, db: db, pricing: pricing, readOnly: readOnly}
+func NewApi(cache *cache.Cache, db *db.DB, pricing *cloud_pricing.Manager, storage *tsdb.Storage, readOnly bool, worldMemoryLimit int64) *Api {
+	return &Api{
+		cache:            cache,
+		db:               db,
+		pricing:          pricing,
+		tsdb:             storage,
+		readOnly:         readOnly,
+		reports:          newReportCache(),
+		clickhouse:       newClickhouseIngester(),
+		worldMemoryLimit: worldMemoryLimit,
+	}
 }
 
 func (api *Api) Projects(w http.ResponseWriter, _ *http.Request) {
@@ -52,6 +82,78 @@ func (api *Api) Projects(w http.ResponseWriter, _ *http.Request) {
 	utils.WriteJson(w, res)
 }
 
+// configMaxSize limits the size of an imported configuration document.
+const configMaxSize = 10 << 20
+
+// Config exports or imports the configuration of all projects as a YAML document.
+func (api *Api) Config(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if api.readOnly {
+			return
+		}
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, configMaxSize))
+		if err != nil {
+			klog.Warningln("failed to read body:", err)
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+		var cfg db.Config
+		if err := utils.UnmarshalYaml(data, &cfg); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid YAML document", http.StatusBadRequest)
+			return
+		}
+		stored, err := api.db.ExportConfig()
+		if err != nil {
+			klog.Errorln("failed to export config:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		storedByName := map[string]db.ProjectConfig{}
+		for _, pc := range stored.Projects {
+			storedByName[pc.Name] = pc
+		}
+		for i := range cfg.Projects {
+			pc := &cfg.Projects[i]
+			if s, ok := storedByName[pc.Name]; ok {
+				restoreMasked(reflect.ValueOf(pc).Elem(), reflect.ValueOf(&s).Elem())
+			}
+			if err := validateProjectConfig(pc); err != nil {
+				klog.Warningln("bad request:", err)
+				http.Error(w, fmt.Sprintf("Project %s: %s", pc.Name, err), http.StatusBadRequest)
+				return
+			}
+		}
+		if err := api.db.ImportConfig(&cfg); err != nil {
+			klog.Errorln("failed to import config:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	cfg, err := api.db.ExportConfig()
+	if err != nil {
+		klog.Errorln("failed to export config:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	// the secrets are exported in plain text only on explicit request
+	if api.readOnly || r.URL.Query().Get("secrets") != "true" {
+		if err = maskConfigSecrets(cfg); err != nil {
+			klog.Errorln("failed to mask secrets:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+	data, err := utils.MarshalYaml(cfg)
+	if err != nil {
+		klog.Errorln("failed to encode config:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(data)
+}
+
 func (api *Api) Project(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := db.ProjectId(vars["project"])
@@ -72,6 +174,8 @@ func (api *Api) Project(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			res.Name = project.Name
+			res.Timezone = project.Settings.Timezone
+			res.Language = project.Settings.Language
 		}
 		utils.WriteJson(w, res)
 
@@ -99,6 +203,11 @@ func (api *Api) Project(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		if err := api.db.SaveProjectLocale(id, form.Timezone, form.Language); err != nil {
+			klog.Errorln("failed to save project locale:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
 		http.Error(w, string(id), http.StatusOK)
 
 	case http.MethodDelete:
@@ -188,6 +297,69 @@ func (api *Api) Overview(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJson(w, views.Overview(world, mux.Vars(r)["view"]))
 }
 
+// Global merges the health of the applications across the projects (all of them, or those listed in the projects query parameter).
+func (api *Api) Global(w http.ResponseWriter, r *http.Request) {
+	projects, err := api.db.GetProjects()
+	if err != nil {
+		klog.Errorln("failed to get projects:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	now := timeseries.Now()
+	q := r.URL.Query()
+	from := utils.ParseTime(now, q.Get("from"), now.Add(-timeseries.Hour))
+	to := utils.ParseTime(now, q.Get("to"), now)
+	var ids *utils.StringSet
+	if v := q.Get("projects"); v != "" {
+		ids = utils.NewStringSet(strings.Split(v, ",")...)
+	}
+
+	label := q.Get("label")
+	var summaries []*global.Summary
+	for _, project := range projects {
+		if ids != nil && !ids.Has(string(project.Id)) {
+			continue
+		}
+		summary, err := api.globalSummary(r.Context(), project, from, to, label)
+		if err != nil {
+			klog.Errorf("failed to load world of project %s: %s", project.Id, err)
+			continue
+		}
+		if summary != nil {
+			summaries = append(summaries, summary)
+		}
+	}
+	utils.WriteJson(w, views.Global(summaries))
+}
+
+// globalSummary returns the summary of the project's applications for the cross-project view. Summaries are cached
+// in the report cache, so the view doesn't audit every project on each request.
+func (api *Api) globalSummary(ctx context.Context, project *db.Project, from, to timeseries.Time, label string) (*global.Summary, error) {
+	rng, err := api.getWorldRange(project, from, to)
+	if err != nil || rng == nil {
+		return nil, err
+	}
+	cacheKey := api.reports.key(project.Id, model.ApplicationId{}, rng, url.Values{"global": {label}})
+	if body := api.reports.get(cacheKey); body != nil {
+		var summary global.Summary
+		if err = json.Unmarshal(body, &summary); err == nil {
+			return &summary, nil
+		}
+	}
+	world, err := api.loadWorldInRange(ctx, project, rng)
+	if err != nil || world == nil {
+		return nil, err
+	}
+	auditor.Audit(world, project)
+	summary := views.GlobalSummary(project, world, label)
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+	api.reports.put(cacheKey, body)
+	return summary, nil
+}
+
 func (api *Api) Search(w http.ResponseWriter, r *http.Request) {
 	world, _, err := api.loadWorldByRequest(r)
 	if err != nil {
@@ -201,6 +373,43 @@ func (api *Api) Search(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJson(w, views.Search(world))
 }
 
+// GraphQL executes a GraphQL query against the audited world. The query can be passed in the body of a POST request
+// ({"query": "...", "variables": {...}}) or in the query and variables params of a GET request.
+func (api *Api) GraphQL(w http.ResponseWriter, r *http.Request) {
+	var form GraphQLForm
+	if r.Method == http.MethodPost {
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid query", http.StatusBadRequest)
+			return
+		}
+	} else {
+		form.Query = r.URL.Query().Get("query")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &form.Variables); err != nil {
+				http.Error(w, "Invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+		if !form.Valid() {
+			http.Error(w, "Invalid query", http.StatusBadRequest)
+			return
+		}
+	}
+	world, project, err := api.loadWorldByRequest(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if world == nil {
+		http.Error(w, "No data", http.StatusNotFound)
+		return
+	}
+	auditor.Audit(world, project)
+	utils.WriteJson(w, views.GraphQL(world, form.Query, form.Variables))
+}
+
 func (api *Api) Configs(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	projectId := db.ProjectId(vars["project"])
@@ -213,6 +422,40 @@ func (api *Api) Configs(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJson(w, views.Configs(checkConfigs))
 }
 
+// PrometheusRules exports the check configs of the project as a Prometheus rule file.
+// The checks that can't be expressed as Prometheus rules are listed in the comments at the top of the file.
+func (api *Api) PrometheusRules(w http.ResponseWriter, r *http.Request) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	checkConfigs, err := api.db.GetCheckConfigs(projectId)
+	if err != nil {
+		klog.Errorln("failed to get check configs:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	rules := views.PrometheusRules(checkConfigs, project.Settings.CustomChecks, project.Prometheus.RefreshInterval)
+	data, err := utils.MarshalYaml(rules)
+	if err != nil {
+		klog.Errorln("failed to encode rules:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	for _, s := range rules.Skipped {
+		_, _ = fmt.Fprintf(w, "# skipped %s\n", s)
+	}
+	_, _ = w.Write(data)
+}
+
 func (api *Api) Categories(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	projectId := db.ProjectId(vars["project"])
@@ -244,145 +487,1958 @@ func (api *Api) Categories(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJson(w, views.Categories(p))
 }
 
-func (api *Api) Integrations(w http.ResponseWriter, r *http.Request) {
+func (api *Api) CustomChecks(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
 
-	if r.Method == http.MethodPut {
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form CustomCheckForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid title, query or application patterns", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveCustomCheck(projectId, form.Get(id))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteCustomCheck(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	checks := p.Settings.CustomChecks
+	if checks == nil {
+		checks = []model.CustomCheck{}
+	}
+	utils.WriteJson(w, checks)
+}
+
+func (api *Api) Dashboards(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form DashboardForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid name or panels", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveDashboard(projectId, form.Get(id))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteDashboard(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if id == "" {
+		p, err := api.db.GetProject(projectId)
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		dashboards := p.Settings.Dashboards
+		if dashboards == nil {
+			dashboards = []model.Dashboard{}
+		}
+		utils.WriteJson(w, dashboards)
+		return
+	}
+
+	world, project, err := api.loadWorldByRequest(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if world == nil {
+		return
+	}
+	for _, d := range project.Settings.Dashboards {
+		if d.Id == id {
+			auditor.Audit(world, project)
+			utils.WriteJson(w, views.Dashboard(r.Context(), api.cache.GetCacheClient(project), world, d))
+			return
+		}
+	}
+	http.Error(w, "Dashboard not found", http.StatusNotFound)
+}
+
+func (api *Api) Digests(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form DigestScheduleForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid name, period, schedule, channels or content", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveDigestSchedule(projectId, form.Get(id))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteDigestSchedule(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	schedules := p.Settings.DigestSchedules
+	if schedules == nil {
+		schedules = []model.DigestSchedule{}
+	}
+	utils.WriteJson(w, schedules)
+}
+
+func (api *Api) Webhooks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form WebhookForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid name, URL, headers or events", http.StatusBadRequest)
+			return
+		}
+		p, err := api.db.GetProject(projectId)
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		wh := form.Get(id)
+		restoreMaskedItem(&wh, p.Settings.Webhooks)
+		id, err := api.db.SaveWebhook(projectId, wh)
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteWebhook(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJson(w, maskWebhooks(p.Settings.Webhooks))
+}
+
+func (api *Api) NotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var form NotificationTemplateForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid destination or template", http.StatusBadRequest)
+			return
+		}
+		if form.Preview {
+			title, body, _ := form.Render(p.Name, p.Settings.Integrations.BaseUrl)
+			utils.WriteJson(w, model.NotificationTemplate{Destination: form.Destination, Title: title, Body: body})
+			return
+		}
+		if api.readOnly {
+			return
+		}
+		if err := api.db.SaveNotificationTemplate(projectId, form.NotificationTemplate); err != nil {
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	templates := p.Settings.NotificationTemplates
+	if templates == nil {
+		templates = model.NotificationTemplates{}
+	}
+	utils.WriteJson(w, templates)
+}
+
+func (api *Api) EscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form EscalationPolicyForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid name, severity or steps", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveEscalationPolicy(projectId, form.Get(id))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteEscalationPolicy(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	policies := p.Settings.EscalationPolicies
+	if policies == nil {
+		policies = []model.EscalationPolicy{}
+	}
+	utils.WriteJson(w, policies)
+}
+
+func (api *Api) NotificationRoutes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form NotificationRouteForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid name, conditions, destination or target", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveNotificationRoute(projectId, form.Get(id))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteNotificationRoute(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	routes := p.Settings.NotificationRoutes
+	if routes == nil {
+		routes = []model.NotificationRoute{}
+	}
+	utils.WriteJson(w, routes)
+}
+
+func (api *Api) OnCall(w http.ResponseWriter, r *http.Request) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+
+	if r.Method == http.MethodPost {
+		if api.readOnly {
+			return
+		}
+		var form OnCallForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid quiet hours or on-call schedule", http.StatusBadRequest)
+			return
+		}
+		if err := api.db.SaveOnCallSettings(projectId, form.QuietHours, form.Schedule); err != nil {
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	now := timeseries.Now()
+	utils.WriteJson(w, struct {
+		QuietHours model.QuietHours     `json:"quiet_hours"`
+		Schedule   model.OnCallSchedule `json:"schedule"`
+		Quiet      bool                 `json:"quiet"`
+		Current    *model.OnCallMember  `json:"current"`
+	}{
+		QuietHours: p.Settings.QuietHours,
+		Schedule:   p.Settings.OnCall,
+		Quiet:      p.QuietHoursActive(now),
+		Current:    p.Settings.OnCall.Current(now),
+	})
+}
+
+func (api *Api) NotificationLimits(w http.ResponseWriter, r *http.Request) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+
+	if r.Method == http.MethodPost {
+		if api.readOnly {
+			return
+		}
+		var form NotificationLimitsForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid limits", http.StatusBadRequest)
+			return
+		}
+		if err := api.db.SaveNotificationLimits(projectId, form.NotificationLimits); err != nil {
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJson(w, p.Settings.NotificationLimits)
+}
+
+// NotificationDeliveries returns the log of the notification attempts, the latest first.
+func (api *Api) NotificationDeliveries(w http.ResponseWriter, r *http.Request) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	failedOnly := r.URL.Query().Get("failed") == "true"
+	from := timeseries.Now().Add(-db.NotificationDeliveryRetention)
+	deliveries, err := api.db.GetNotificationDeliveries(projectId, from, failedOnly, limit)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJson(w, deliveries)
+}
+
+// SlackInteractions handles the buttons of the interactive incident messages. The request is verified with the signing secret
+// of the Slack integration of the project the incident belongs to.
+func (api *Api) SlackInteractions(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	action, err := notifications.ParseSlackAction(body)
+	if err != nil {
+		klog.Warningln("invalid slack interaction:", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	if action == nil {
+		return
+	}
+	project, err := api.db.GetProject(action.ProjectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	cfg := project.Settings.Integrations.Slack
+	if cfg == nil || cfg.SigningSecret == "" {
+		http.Error(w, "", http.StatusForbidden)
+		return
+	}
+	if err = notifications.VerifySlackRequest(r.Header, body, cfg.SigningSecret); err != nil {
+		klog.Warningln("invalid slack signature:", err)
+		http.Error(w, "", http.StatusUnauthorized)
+		return
+	}
+	if api.readOnly {
+		return
+	}
+	if action.ApplicationId.IsZero() {
+		if action.ApplicationId, err = api.db.GetIncidentApplicationId(project.Id, action.IncidentKey); err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+	now := timeseries.Now()
+	var text string
+	switch action.Type {
+	case "ack":
+		err = api.db.AcknowledgeIncident(project.Id, action.IncidentKey, "@"+action.User, now)
+		text = fmt.Sprintf("The %s incident has been acknowledged by @%s", action.ApplicationId.Name, action.User)
+	case "silence":
+		m := model.CheckMute{
+			ApplicationId: action.ApplicationId,
+			CheckId:       action.CheckId,
+			Until:         now.Add(action.Duration),
+			Comment:       fmt.Sprintf("silenced from Slack by @%s", action.User),
+		}
+		_, err = api.db.SaveCheckMute(project.Id, m)
+		text = fmt.Sprintf("The %s check of %s has been silenced for %s by @%s", action.CheckId, action.ApplicationId.Name, utils.FormatDuration(action.Duration, 1), action.User)
+	}
+	if err != nil {
+		klog.Errorln("failed to handle slack interaction:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	if err = action.Respond(ctx, text); err != nil {
+		klog.Errorln("failed to respond to slack interaction:", err)
+	}
+}
+
+// AcknowledgeIncident stops the escalation of the incident.
+func (api *Api) AcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	key := vars["incident"]
+
+	if r.Method == http.MethodPost {
+		if api.readOnly {
+			return
+		}
+		var form AcknowledgeIncidentForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+		if err := api.db.AcknowledgeIncident(projectId, key, form.By, timeseries.Now()); err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to acknowledge incident:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	incident, err := api.db.GetIncidentByKey(projectId, key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJson(w, map[string]any{
+		"acknowledged":    incident.Acknowledged(),
+		"acknowledged_at": incident.AcknowledgedAt,
+		"acknowledged_by": incident.AcknowledgedBy,
+	})
+}
+
+func (api *Api) Repositories(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form RepositoryForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid image or repository URL", http.StatusBadRequest)
+			return
+		}
+		p, err := api.db.GetProject(projectId)
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		repo := form.Get(id)
+		restoreMaskedItem(&repo, p.Settings.Repositories)
+		id, err := api.db.SaveRepository(projectId, repo)
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteRepository(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJson(w, maskRepositories(p.Settings.Repositories))
+}
+
+func (api *Api) LogChecks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form LogCheckForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid title, regexp, threshold or application patterns", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveLogCheck(projectId, form.Get(id))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteLogCheck(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	checks := p.Settings.LogChecks
+	if checks == nil {
+		checks = []model.LogCheck{}
+	}
+	utils.WriteJson(w, checks)
+}
+
+func (api *Api) CompositeChecks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form CompositeCheckForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid title, checks, operator or application patterns", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveCompositeCheck(projectId, form.Get(id))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteCompositeCheck(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	checks := p.Settings.CompositeChecks
+	if checks == nil {
+		checks = []model.CompositeCheck{}
+	}
+	utils.WriteJson(w, checks)
+}
+
+func (api *Api) CheckTemplates(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form CheckTemplateForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid name, checks or application patterns", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveCheckTemplate(projectId, form.Get(id))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteCheckTemplate(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	templates := p.Settings.CheckTemplates
+	if templates == nil {
+		templates = []model.CheckTemplate{}
+	}
+	utils.WriteJson(w, templates)
+}
+
+func (api *Api) Integrations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+
+	if r.Method == http.MethodPut {
+		if api.readOnly {
+			return
+		}
+		var form IntegrationsForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid base url", http.StatusBadRequest)
+			return
+		}
+		if err := api.db.SaveIntegrationsBaseUrl(projectId, form.BaseUrl); err != nil {
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJson(w, views.Integrations(p))
+}
+
+func (api *Api) Integration(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	t := db.IntegrationType(vars["type"])
+	form := NewIntegrationForm(t)
+	if form == nil {
+		klog.Warningln("unknown integration type:", t)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		form.Get(project, api.readOnly)
+		utils.WriteJson(w, form)
+		return
+	}
+
+	if api.readOnly {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost, http.MethodPut:
+		if err := ReadAndValidate(r, form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "invalid data", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	switch r.Method {
+	case http.MethodPost:
+		if err := form.Test(ctx, project); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	case http.MethodPut:
+		if err := form.Update(ctx, project, false); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	case http.MethodDelete:
+		if err := form.Update(ctx, project, true); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	default:
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := api.db.SaveProjectIntegration(project, t); err != nil {
+		klog.Errorln("failed to save:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (api *Api) Prom(w http.ResponseWriter, r *http.Request) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	p := project.Prometheus
+	if p.Embedded {
+		http.Error(w, "The embedded TSDB doesn't provide the Prometheus HTTP API.", http.StatusNotImplemented)
+		return
+	}
+	c, err := prom.NewApiClient(p.ClientConfig())
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	c.Proxy(r, w)
+}
+
+// RemoteWrite accepts metrics pushed by the agents using the Prometheus remote-write protocol
+// and stores them in the embedded TSDB of the project.
+func (api *Api) RemoteWrite(w http.ResponseWriter, r *http.Request) {
+	if api.tsdb == nil {
+		http.Error(w, "The embedded TSDB is disabled.", http.StatusNotFound)
+		return
+	}
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			klog.Warningln("project not found:", projectId)
+			http.Error(w, "Project not found.", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if err = checkIngestionKey(r, project); err != nil {
+		klog.Warningf("%s: remote-write request rejected: %s", projectId, err)
+		http.Error(w, "Invalid ingestion key.", http.StatusUnauthorized)
+		return
+	}
+	if !project.Prometheus.Embedded {
+		http.Error(w, "The project isn't configured to use the embedded TSDB.", http.StatusBadRequest)
+		return
+	}
+	req, err := tsdb.DecodeWriteRequest(r.Body)
+	if err != nil {
+		klog.Warningln("invalid remote-write request:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// the per-query metrics have a high cardinality, so they are stored in ClickHouse if it's configured
+	var querySamples []clickhouse.QuerySample
+	cfg := project.Settings.Integrations.Clickhouse
+	if cfg != nil && cfg.Ingest {
+		querySamples = clickhouse.ExtractQueryStats(req)
+	}
+	if err = api.tsdb.Write(r.Context(), projectId, req); err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if len(querySamples) > 0 {
+		clients, err := api.clickhouse.getClients(projectId, *cfg)
+		if err == nil {
+			err = clients.store.InsertQueryStats(r.Context(), querySamples)
+		}
+		if err != nil { // the request can be retried since the TSDB skips the samples it already has
+			klog.Errorln("failed to insert query stats:", err)
+			http.Error(w, "", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *Api) App(w http.ResponseWriter, r *http.Request) {
+	id, err := model.NewApplicationIdFromString(mux.Vars(r)["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+mux.Vars(r)["app"], http.StatusBadRequest)
+		return
+	}
+	project, from, to, err := api.getProjectAndRange(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		return
+	}
+	rng, err := api.getWorldRange(project, from, to)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if rng == nil {
+		return
+	}
+	cacheKey := api.reports.key(project.Id, id, rng, r.URL.Query())
+	if body := api.reports.get(cacheKey); body != nil {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+		return
+	}
+	world, err := api.loadWorldInRange(r.Context(), project, rng)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if world == nil {
+		return
+	}
+	app := world.GetApplication(id)
+	if app == nil {
+		klog.Warningln("application not found:", id)
+		http.Error(w, "Application not found", http.StatusNotFound)
+		return
+	}
+	auditor.Audit(world, project)
+	if mode := r.URL.Query().Get("compare"); mode != "" {
+		api.compare(r.Context(), project, world, app, mode)
+	}
+	if r.URL.Query().Get("summary") == "true" {
+		for _, report := range app.Reports {
+			for _, widget := range report.Widgets {
+				widget.SummaryOnly()
+			}
+		}
+	}
+	if limit, _ := strconv.Atoi(r.URL.Query().Get("table_limit")); limit > 0 {
+		for _, report := range app.Reports {
+			for _, widget := range report.Widgets {
+				if widget.Table != nil && len(widget.Table.Rows) > limit {
+					widget.Table = widget.Table.Query(model.TableQuery{Limit: limit})
+				}
+			}
+		}
+	}
+	if t := translator(r, project); t != nil {
+		for _, report := range app.Reports {
+			report.Translate(t.T)
+		}
+	}
+	body, err := json.Marshal(views.Application(world, app))
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	api.reports.put(cacheKey, body)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// translator returns the translator for the language chosen by the user (the lang argument) or,
+// if it's not set, for the project language.
+func translator(r *http.Request, project *db.Project) *i18n.Translator {
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = project.Settings.Language
+	}
+	return i18n.Get(lang)
+}
+
+// compare overlays the reports of the application with the data of the baseline window.
+func (api *Api) compare(ctx context.Context, project *db.Project, world *model.World, app *model.Application, mode string) {
+	offset := model.CompareOffset(mode, app, world.Ctx)
+	if offset <= 0 {
+		return
+	}
+	baseline, err := api.loadWorld(ctx, project, world.Ctx.From.Add(-offset), world.Ctx.To.Add(-offset))
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	if baseline == nil {
+		return
+	}
+	baselineApp := baseline.GetApplication(app.Id)
+	if baselineApp == nil {
+		return
+	}
+	auditor.Audit(baseline, project)
+	model.CompareReports(app.Reports, baselineApp.Reports, offset)
+}
+
+func (api *Api) ReportsV1(w http.ResponseWriter, r *http.Request) {
+	id, err := model.NewApplicationIdFromString(mux.Vars(r)["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+mux.Vars(r)["app"], http.StatusBadRequest)
+		return
+	}
+	world, project, err := api.loadWorldByRequest(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if world == nil {
+		http.Error(w, "No data", http.StatusNotFound)
+		return
+	}
+	app := world.GetApplication(id)
+	if app == nil {
+		klog.Warningln("application not found:", id)
+		http.Error(w, "Application not found", http.StatusNotFound)
+		return
+	}
+	auditor.Audit(world, project)
+	utils.WriteJson(w, views.ReportsV1(world, app, r.URL.Query().Get("report"), r.URL.Query().Get("summary") == "true"))
+}
+
+// DeploymentVerdictV1 returns the verdict on the latest deployment of the application, so that CD systems can halt
+// or roll back the rollout. To be used as an Argo Rollouts web metric or a Flagger webhook, it responds with
+// 412 Precondition Failed if the verdict is "fail".
+func (api *Api) DeploymentVerdictV1(w http.ResponseWriter, r *http.Request) {
+	api.deploymentVerdict(w, r, "")
+}
+
+// DeploymentVerdictByIdV1 returns the verdict on the given deployment. The deployment is identified by its id,
+// the name of its ReplicaSet (or controller revision), or the pod template hash (e.g., podTemplateHashValue: Latest
+// in an Argo Rollouts AnalysisTemplate). While the deployment isn't discovered yet, the verdict is inconclusive,
+// so that CD systems keep polling.
+func (api *Api) DeploymentVerdictByIdV1(w http.ResponseWriter, r *http.Request) {
+	api.deploymentVerdict(w, r, mux.Vars(r)["id"])
+}
+
+func (api *Api) deploymentVerdict(w http.ResponseWriter, r *http.Request, deploymentId string) {
+	id, err := model.NewApplicationIdFromString(mux.Vars(r)["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+mux.Vars(r)["app"], http.StatusBadRequest)
+		return
+	}
+	world, project, err := api.loadWorldByRequest(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if world == nil {
+		http.Error(w, "No data", http.StatusNotFound)
+		return
+	}
+	app := world.GetApplication(id)
+	if app == nil {
+		klog.Warningln("application not found:", id)
+		http.Error(w, "Application not found", http.StatusNotFound)
+		return
+	}
+	if len(app.Deployments) == 0 && deploymentId == "" {
+		http.Error(w, "No deployments found", http.StatusNotFound)
+		return
+	}
+	statuses := model.CalcApplicationDeploymentStatuses(app, world.CheckConfigs, world.Ctx.To)
+	project.Settings.MaintenanceWindows.ApplyToDeployments(statuses)
+	idx := len(statuses) - 1
+	if deploymentId != "" {
+		idx = -1
+		for i := len(statuses) - 1; i >= 0; i-- {
+			if d := statuses[i].Deployment; d.Id() == deploymentId || d.Name == deploymentId || d.Hash() == deploymentId {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx < 0 {
+		utils.WriteJson(w, views.PendingDeploymentVerdictV1(app, deploymentId))
+		return
+	}
+	ds := statuses[idx]
+	ds.CalcCanary(app, world.Ctx.To)
+	res := views.DeploymentVerdictV1(app, ds)
+	if res.Verdict == string(model.DeploymentVerdictFail) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	utils.WriteJson(w, res)
+}
+
+// ChangeV1 records a change of the application (e.g., a feature flag flip, a DB migration, or an infrastructure change),
+// which is treated like a deployment. It responds with the id of the deployment. The timestamps must be within
+// model.ApplicationChangeMaxAge, and a change can't start at the same time as another deployment of the application.
+func (api *Api) ChangeV1(w http.ResponseWriter, r *http.Request) {
+	if api.readOnly {
+		return
+	}
+	id, err := model.NewApplicationIdFromString(mux.Vars(r)["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+mux.Vars(r)["app"], http.StatusBadRequest)
+		return
+	}
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	var form ChangeForm
+	if err := ReadAndValidate(r, &form); err != nil {
+		klog.Warningln("bad request:", err)
+		http.Error(w, "Invalid type, description, URL or timestamps", http.StatusBadRequest)
+		return
+	}
+	if _, err := api.db.GetProject(projectId); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	d := form.Get(id, timeseries.Now())
+	if err := api.db.AddApplicationDeployment(projectId, d); err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			http.Error(w, "A deployment or change of the application started at the same time has already been recorded", http.StatusConflict)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJson(w, d.Id())
+}
+
+// FluxEventV1 receives events from the Flux notification-controller (a Provider of the generic or generic-hmac type)
+// and records reconciliations of Kustomizations and HelmReleases as deployments of the affected applications, so that
+// changes that don't roll out new ReplicaSets (e.g., of ConfigMaps) are visible too. The events must be signed with
+// the ingestion key of the project (generic-hmac) or carry it in the X-API-Key header (generic).
+func (api *Api) FluxEventV1(w http.ResponseWriter, r *http.Request) {
+	if api.readOnly {
+		return
+	}
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookEventSize))
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+	if err = checkFluxEvent(r, data, project); err != nil {
+		klog.Warningf("%s: Flux event rejected: %s", projectId, err)
+		http.Error(w, "Invalid ingestion key or signature.", http.StatusUnauthorized)
+		return
+	}
+	var e flux.Event
+	if err = json.Unmarshal(data, &e); err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+	if !e.IsRollout() {
+		return
+	}
+	deployments, err := api.db.GetApplicationDeployments(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	var ids []model.ApplicationId
+	switch e.InvolvedObject.Kind {
+	case flux.KindKustomization:
+		for _, o := range e.ChangedWorkloads() {
+			ids = append(ids, model.NewApplicationId(o.Namespace, model.ApplicationKind(o.Kind), o.Name))
+		}
+	case flux.KindHelmRelease:
+		release := e.InvolvedObject
+		for id := range deployments {
+			if id.Namespace == release.Namespace && (id.Name == release.Name || strings.HasPrefix(id.Name, release.Name+"-")) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	t := timeseries.Time(e.Timestamp.Unix())
+	if e.Timestamp.IsZero() {
+		t = timeseries.Now()
+	}
+	sync := &model.ApplicationDeploymentSync{
+		Application: e.InvolvedObject.String(),
+		Revision:    e.Revision(),
+		Status:      "Succeeded",
+		Automated:   true,
+	}
+	for _, id := range ids {
+		if d := model.FindApplicationDeployment(deployments[id], t); d != nil {
+			if d.Details != nil && d.Details.Sync != nil {
+				continue
+			}
+			if d.Details == nil {
+				d.Details = &model.ApplicationDeploymentDetails{}
+			}
+			d.Details.Sync = sync
+			if err := api.db.SaveApplicationDeploymentDetails(projectId, d); err != nil {
+				klog.Errorln(err)
+				http.Error(w, "", http.StatusInternalServerError)
+				return
+			}
+			continue
+		}
+		d := &model.ApplicationDeployment{
+			ApplicationId: id,
+			Name:          "flux-" + sync.ShortRevision(),
+			StartedAt:     t,
+			FinishedAt:    t,
+			Details:       &model.ApplicationDeploymentDetails{Sync: sync, ConfigOnly: true},
+		}
+		if err := api.db.SaveApplicationDeployment(projectId, d); err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// CIEventV1 receives CI pipeline runs: workflow_run events of GitHub webhooks, build notifications of the Jenkins
+// Notification plugin, or runs in the generic format. The runs are linked to the deployments of the same revisions
+// by the deployments watcher. GitHub events must be signed with the ingestion key of the project used as the secret
+// of the webhook, the other events must carry the key in the X-API-Key header or as a bearer token.
+func (api *Api) CIEventV1(w http.ResponseWriter, r *http.Request) {
+	if api.readOnly {
+		return
+	}
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	provider := vars["provider"]
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookEventSize))
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+	if provider == ci.ProviderGitHub {
+		err = checkIngestionSignature(r, "X-Hub-Signature-256", data, project)
+	} else {
+		err = checkIngestionKey(r, project)
+	}
+	if err != nil {
+		klog.Warningf("%s: CI event rejected: %s", projectId, err)
+		http.Error(w, "Invalid ingestion key or signature.", http.StatusUnauthorized)
+		return
+	}
+	if provider == ci.ProviderGitHub && r.Header.Get("X-GitHub-Event") != "workflow_run" {
+		return
+	}
+	p, err := ci.Parse(provider, data)
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+	if p == nil {
+		return
+	}
+	if err := api.db.SaveCIPipeline(projectId, p); err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// CompareDeployments renders the windows around two deployments of the application (the a and b query parameters,
+// the last two deployments by default) side by side.
+func (api *Api) CompareDeployments(w http.ResponseWriter, r *http.Request) {
+	id, err := model.NewApplicationIdFromString(mux.Vars(r)["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+mux.Vars(r)["app"], http.StatusBadRequest)
+		return
+	}
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	all, err := api.db.GetApplicationDeployments(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	deployments := all[id]
+	if len(deployments) < 2 {
+		http.Error(w, "At least two deployments are required", http.StatusNotFound)
+		return
+	}
+	find := func(deploymentId string, dflt *model.ApplicationDeployment) *model.ApplicationDeployment {
+		for _, d := range deployments {
+			if d.Id() == deploymentId {
+				return d
+			}
+		}
+		return dflt
+	}
+	q := r.URL.Query()
+	depA := find(q.Get("a"), deployments[len(deployments)-2])
+	depB := find(q.Get("b"), deployments[len(deployments)-1])
+
+	side := func(d *model.ApplicationDeployment) (views.DeploymentComparisonSide, error) {
+		res := views.DeploymentComparisonSide{Deployment: d}
+		world, err := api.loadWorld(r.Context(), project, d.StartedAt.Add(-model.DeploymentComparisonWindow), d.StartedAt.Add(model.DeploymentComparisonWindow))
+		if err != nil || world == nil {
+			return res, err
+		}
+		res.Application = world.GetApplication(id)
+		if res.Application == nil {
+			return res, nil
+		}
+		auditor.Audit(world, project)
+		res.Window = timeseries.Window{From: d.StartedAt, To: d.StartedAt.Add(model.DeploymentComparisonWindow)}
+		if res.Window.To.After(world.Ctx.To) {
+			res.Window.To = world.Ctx.To
+		}
+		return res, nil
+	}
+	a, err := side(depA)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	b, err := side(depB)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if a.Application == nil || b.Application == nil {
+		http.Error(w, "No data", http.StatusNotFound)
+		return
+	}
+	utils.WriteJson(w, views.DeploymentComparison(deployments, a, b, project.Location()))
+}
+
+func (api *Api) AppReportPDF(w http.ResponseWriter, r *http.Request) {
+	world, app, report := api.loadAppReport(w, r)
+	if report == nil {
+		return
+	}
+	writePDF(w, world, app.Id.Name, report)
+}
+
+func (api *Api) AppReportCSV(w http.ResponseWriter, r *http.Request) {
+	_, app, report := api.loadAppReport(w, r)
+	if report == nil {
+		return
+	}
+	table := reportTable(report, r.URL.Query().Get("table"))
+	if table == nil {
+		http.Error(w, "Table not found", http.StatusNotFound)
+		return
+	}
+	buf := &bytes.Buffer{}
+	if err := table.WriteCSV(buf); err != nil {
+		klog.Errorln("failed to write CSV:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.csv"`, slug(app.Id.Name), slug(string(report.Name))))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// AppReportTable returns a page of a table of the report. The table query parameter is the index of the table in the report.
+// Rows are filtered with filter=<column>:<substring>, and sorted with sort=<column> or sort=-<column> (descending),
+// both parameters can be repeated.
+func (api *Api) AppReportTable(w http.ResponseWriter, r *http.Request) {
+	_, _, report := api.loadAppReport(w, r)
+	if report == nil {
+		return
+	}
+	q := r.URL.Query()
+	table := reportTable(report, q.Get("table"))
+	if table == nil {
+		http.Error(w, "Table not found", http.StatusNotFound)
+		return
+	}
+	utils.WriteJson(w, table.Query(parseTableQuery(q)))
+}
+
+func reportTable(report *model.AuditReport, index string) *model.Table {
+	idx, err := strconv.Atoi(index)
+	if err != nil {
+		idx = 0
+	}
+	for _, widget := range report.Widgets {
+		if widget.Table == nil {
+			continue
+		}
+		if idx == 0 {
+			return widget.Table
+		}
+		idx--
+	}
+	return nil
+}
+
+func parseTableQuery(q url.Values) model.TableQuery {
+	res := model.TableQuery{Filters: map[int]string{}}
+	res.Offset, _ = strconv.Atoi(q.Get("offset"))
+	res.Limit, _ = strconv.Atoi(q.Get("limit"))
+	for _, f := range q["filter"] {
+		parts := strings.SplitN(f, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if column, err := strconv.Atoi(parts[0]); err == nil {
+			res.Filters[column] = parts[1]
+		}
+	}
+	for _, s := range q["sort"] {
+		desc := strings.HasPrefix(s, "-")
+		if column, err := strconv.Atoi(strings.TrimPrefix(s, "-")); err == nil {
+			res.Sort = append(res.Sort, model.TableSort{Column: column, Desc: desc})
+		}
+	}
+	return res
+}
+
+func (api *Api) loadAppReport(w http.ResponseWriter, r *http.Request) (*model.World, *model.Application, *model.AuditReport) {
+	vars := mux.Vars(r)
+	id, err := model.NewApplicationIdFromString(vars["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+vars["app"], http.StatusBadRequest)
+		return nil, nil, nil
+	}
+	world, project, err := api.loadWorldByRequest(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return nil, nil, nil
+	}
+	if world == nil {
+		return nil, nil, nil
+	}
+	app := world.GetApplication(id)
+	if app == nil {
+		klog.Warningln("application not found:", id)
+		http.Error(w, "Application not found", http.StatusNotFound)
+		return nil, nil, nil
+	}
+	auditor.Audit(world, project)
+	for _, rep := range app.Reports {
+		if string(rep.Name) == vars["report"] {
+			return world, app, rep
+		}
+	}
+	klog.Warningln("report not found:", vars["report"])
+	http.Error(w, "Report not found", http.StatusNotFound)
+	return nil, nil, nil
+}
+
+func (api *Api) CheckMutes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	appId, err := model.NewApplicationIdFromString(vars["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+vars["app"], http.StatusBadRequest)
+		return
+	}
+	checkId := model.CheckId(vars["check"])
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form CheckMuteForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid duration or schedule", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveCheckMute(projectId, form.Get(appId, checkId, timeseries.Now()))
+		if err != nil {
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteCheckMute(projectId, appId, checkId, vars["id"]); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	now := timeseries.Now()
+	res := model.CheckMutes{}
+	for _, m := range project.Settings.CheckMutes {
+		if m.ApplicationId == appId && m.CheckId == checkId && (m.Schedule != "" || !m.Until.Before(now)) {
+			res = append(res, m)
+		}
+	}
+	utils.WriteJson(w, res)
+}
+
+func (api *Api) Annotations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form UserAnnotationForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid time range, application or text", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveUserAnnotation(projectId, form.Get())
+		if err != nil {
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteUserAnnotation(projectId, vars["id"]); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	res := project.Settings.Annotations
+	if res == nil {
+		res = model.UserAnnotations{}
+	}
+	utils.WriteJson(w, res)
+}
+
+func (api *Api) SavedViews(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	viewId := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form SavedViewForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid name, application, report or time range", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveSavedView(projectId, form.Get(viewId))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "View not found", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteSavedView(projectId, viewId); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if viewId != "" {
+		for _, v := range project.Settings.SavedViews {
+			if v.Id == viewId {
+				utils.WriteJson(w, v)
+				return
+			}
+		}
+		http.Error(w, "View not found", http.StatusNotFound)
+		return
+	}
+	res := project.Settings.SavedViews
+	if res == nil {
+		res = []model.SavedView{}
+	}
+	utils.WriteJson(w, res)
+}
+
+func (api *Api) MaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form MaintenanceWindowForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid time range or checks", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveMaintenanceWindow(projectId, form.Get())
+		if err != nil {
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteMaintenanceWindow(projectId, vars["id"]); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	res := project.Settings.MaintenanceWindows
+	if res == nil {
+		res = model.MaintenanceWindows{}
+	}
+	utils.WriteJson(w, res)
+}
+
+// CheckDryRun evaluates the proposed thresholds of a check against the history of its values.
+func (api *Api) CheckDryRun(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	appId, err := model.NewApplicationIdFromString(vars["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+vars["app"], http.StatusBadRequest)
+		return
+	}
+	checkId := model.CheckId(vars["check"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	form := CheckDryRunForm{unit: project.CheckUnit(checkId)}
+	if err := ReadAndValidate(r, &form); err != nil {
+		klog.Warningln("bad request:", err)
+		http.Error(w, "Invalid config or time window", http.StatusBadRequest)
+		return
+	}
+	step := (form.To.Sub(form.From) / 1000).Truncate(timeseries.Minute)
+	if step < timeseries.Minute {
+		step = timeseries.Minute
+	}
+	values, err := api.db.GetCheckValues(projectId, appId, checkId, form.From, form.To, step)
+	if err != nil {
+		klog.Errorln("failed to get check values:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	ctx := timeseries.Context{From: form.From.Truncate(step), To: form.To, Step: step}
+	chart := model.NewChart(ctx, "Check value").AddSeries("value", values)
+	chart.Threshold = &model.Series{Name: "threshold", Color: "red", Data: values.WithNewValue(form.Config.Threshold)}
+	res := struct {
+		Firing []model.CheckFiring `json:"firing"`
+		Chart  *model.Chart        `json:"chart"`
+	}{
+		Firing: model.DryRunCheck(values, step, form.Config),
+		Chart:  chart,
+	}
+	if res.Firing == nil {
+		res.Firing = []model.CheckFiring{}
+	}
+	utils.WriteJson(w, res)
+}
+
+func (api *Api) CheckAnnotation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	appId, err := model.NewApplicationIdFromString(vars["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+vars["app"], http.StatusBadRequest)
+		return
+	}
+	checkId := model.CheckId(vars["check"])
+
+	if r.Method == http.MethodPost {
 		if api.readOnly {
 			return
 		}
-		var form IntegrationsForm
+		var form CheckAnnotationForm
 		if err := ReadAndValidate(r, &form); err != nil {
 			klog.Warningln("bad request:", err)
-			http.Error(w, "Invalid base url", http.StatusBadRequest)
+			http.Error(w, "Invalid runbook URL", http.StatusBadRequest)
 			return
 		}
-		if err := api.db.SaveIntegrationsBaseUrl(projectId, form.BaseUrl); err != nil {
-			klog.Errorln("failed to save:", err)
-			http.Error(w, "", http.StatusInternalServerError)
-			return
+		for _, a := range form.Get(appId, checkId) {
+			if err := api.db.SaveCheckAnnotation(projectId, a); err != nil {
+				klog.Errorln("failed to save:", err)
+				http.Error(w, "", http.StatusInternalServerError)
+				return
+			}
 		}
 		return
 	}
 
-	p, err := api.db.GetProject(projectId)
+	project, err := api.db.GetProject(projectId)
 	if err != nil {
-		klog.Errorln(err)
+		klog.Errorln("failed to get project:", err)
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
-
-	utils.WriteJson(w, views.Integrations(p))
+	var form CheckAnnotationForm
+	if a := project.Settings.CheckAnnotations.Find(model.ApplicationIdZero, checkId); a != nil {
+		form.Project = CheckAnnotationFields{RunbookUrl: a.RunbookUrl, Owner: a.Owner, Description: a.Description}
+	}
+	if a := project.Settings.CheckAnnotations.Find(appId, checkId); a != nil {
+		form.Application = CheckAnnotationFields{RunbookUrl: a.RunbookUrl, Owner: a.Owner, Description: a.Description}
+	}
+	utils.WriteJson(w, form)
 }
 
-func (api *Api) Integration(w http.ResponseWriter, r *http.Request) {
+func (api *Api) ApplicationOwner(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	projectId := db.ProjectId(vars["project"])
-	project, err := api.db.GetProject(projectId)
+	appId, err := model.NewApplicationIdFromString(vars["app"])
 	if err != nil {
-		klog.Errorln(err)
-		http.Error(w, "", http.StatusInternalServerError)
-		return
-	}
-
-	t := db.IntegrationType(vars["type"])
-	form := NewIntegrationForm(t)
-	if form == nil {
-		klog.Warningln("unknown integration type:", t)
-		http.Error(w, "", http.StatusBadRequest)
-		return
-	}
-
-	if r.Method == http.MethodGet {
-		form.Get(project, api.readOnly)
-		utils.WriteJson(w, form)
-		return
-	}
-
-	if api.readOnly {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+vars["app"], http.StatusBadRequest)
 		return
 	}
 
-	switch r.Method {
-	case http.MethodPost, http.MethodPut:
-		if err := ReadAndValidate(r, form); err != nil {
-			klog.Warningln("bad request:", err)
-			http.Error(w, "invalid data", http.StatusBadRequest)
+	if r.Method == http.MethodPost {
+		if api.readOnly {
 			return
 		}
-	}
-
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
-	defer cancel()
-	switch r.Method {
-	case http.MethodPost:
-		if err := form.Test(ctx, project); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		}
-		return
-	case http.MethodPut:
-		if err := form.Update(ctx, project, false); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		var form ApplicationOwnerForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid Slack channel", http.StatusBadRequest)
+			return
 		}
-	case http.MethodDelete:
-		if err := form.Update(ctx, project, true); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if err := api.db.SaveApplicationSetting(projectId, appId, form.Get()); err != nil {
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
 		}
-	default:
-		http.Error(w, "", http.StatusMethodNotAllowed)
-		return
-	}
-
-	if err := api.db.SaveProjectIntegration(project, t); err != nil {
-		klog.Errorln("failed to save:", err)
-		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
-}
 
-func (api *Api) Prom(w http.ResponseWriter, r *http.Request) {
-	projectId := db.ProjectId(mux.Vars(r)["project"])
-	project, err := api.db.GetProject(projectId)
+	settings, err := api.db.GetApplicationSettings(projectId, appId)
 	if err != nil {
-		klog.Errorln(err)
+		klog.Errorln("failed to get application settings:", err)
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
-	p := project.Prometheus
-	c, err := prom.NewApiClient(p.Url, p.BasicAuth, p.TlsSkipVerify, p.ExtraSelector, p.CustomHeaders)
-	if err != nil {
-		klog.Errorln(err)
-		http.Error(w, "", http.StatusInternalServerError)
-		return
+	var form ApplicationOwnerForm
+	if settings != nil && settings.Owner != nil {
+		form.ApplicationOwner = *settings.Owner
 	}
-	c.Proxy(r, w)
+	utils.WriteJson(w, form)
 }
 
-func (api *Api) App(w http.ResponseWriter, r *http.Request) {
-	id, err := model.NewApplicationIdFromString(mux.Vars(r)["app"])
+func (api *Api) CheckHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	appId, err := model.NewApplicationIdFromString(vars["app"])
 	if err != nil {
 		klog.Warningln(err)
-		http.Error(w, "invalid application id: "+mux.Vars(r)["app"], http.StatusBadRequest)
+		http.Error(w, "invalid application id: "+vars["app"], http.StatusBadRequest)
 		return
 	}
-	world, project, err := api.loadWorldByRequest(r)
+	checkId := model.CheckId(vars["check"])
+	to := timeseries.Now()
+	ctx := timeseries.Context{From: to.Add(-model.CheckValuesRetention), To: to, Step: timeseries.Hour}
+	values, err := api.db.GetCheckValues(projectId, appId, checkId, ctx.From, ctx.To, ctx.Step)
 	if err != nil {
-		klog.Errorln(err)
+		klog.Errorln("failed to get check values:", err)
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
-	if world == nil {
-		return
-	}
-	app := world.GetApplication(id)
-	if app == nil {
-		klog.Warningln("application not found:", id)
-		http.Error(w, "Application not found", http.StatusNotFound)
-		return
-	}
-	auditor.Audit(world, project)
-	utils.WriteJson(w, views.Application(world, app))
+	ctx.From = ctx.From.Truncate(ctx.Step)
+	utils.WriteJson(w, model.NewChart(ctx, "Check value history").AddSeries("value", values))
 }
 
 func (api *Api) Check(w http.ResponseWriter, r *http.Request) {
@@ -472,7 +2528,13 @@ func (api *Api) Check(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 		default:
-			var form CheckConfigForm
+			project, err := api.db.GetProject(projectId)
+			if err != nil {
+				klog.Errorln("failed to get project:", err)
+				http.Error(w, "", http.StatusInternalServerError)
+				return
+			}
+			form := CheckConfigForm{unit: project.CheckUnit(checkId)}
 			if err := ReadAndValidate(r, &form); err != nil {
 				klog.Warningln("bad request:", err)
 				http.Error(w, "", http.StatusBadRequest)
@@ -486,6 +2548,8 @@ func (api *Api) Check(w http.ResponseWriter, r *http.Request) {
 				case 1:
 					id = model.ApplicationIdZero
 				case 2:
+					id = model.NewNamespaceScopeId(appId.Namespace)
+				case 3:
 					id = appId
 				}
 				if err := api.db.SaveCheckConfig(projectId, id, checkId, cfg); err != nil {
@@ -499,6 +2563,110 @@ func (api *Api) Check(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (api *Api) CheckOverrides(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	check := model.GetCheckConfig(model.CheckId(vars["check"]))
+	if check == nil || check.Type == model.CheckTypeManual {
+		http.Error(w, "", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if api.readOnly {
+			return
+		}
+		project, err := api.db.GetProject(projectId)
+		if err != nil {
+			klog.Errorln("failed to get project:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		form := CheckOverrideForm{unit: project.CheckUnit(check.Id)}
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+		checkConfigs, err := api.db.GetCheckConfigs(projectId)
+		if err != nil {
+			klog.Errorln("failed to get check configs:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		cfg := overrideThreshold(checkConfigs, check.Id, form.Scope(), form.Threshold)
+		if err := api.db.SaveCheckConfig(projectId, form.Scope(), check.Id, cfg); err != nil {
+			klog.Errorln("failed to save check config:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		return
+	}
+
+	checkConfigs, err := api.db.GetCheckConfigs(projectId)
+	if err != nil {
+		klog.Errorln("failed to get check configs:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	type Override struct {
+		ApplicationId *model.ApplicationId `json:"application_id,omitempty"`
+		Namespace     string               `json:"namespace,omitempty"`
+		Threshold     float32              `json:"threshold"`
+	}
+	res := make([]Override, 0)
+	for id, configs := range checkConfigs.GetByCheck(check.Id) {
+		if id.IsZero() {
+			continue
+		}
+		for _, unk := range configs {
+			cfg, ok := unk.(model.CheckConfigSimple)
+			if !ok {
+				continue
+			}
+			o := Override{Threshold: cfg.Threshold}
+			if id.IsNamespaceScope() {
+				o.Namespace = id.Namespace
+			} else {
+				appId := id
+				o.ApplicationId = &appId
+			}
+			res = append(res, o)
+		}
+	}
+	key := func(o Override) string {
+		if o.ApplicationId != nil {
+			return "1" + o.ApplicationId.String()
+		}
+		return "0" + o.Namespace
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return key(res[i]) < key(res[j])
+	})
+	utils.WriteJson(w, res)
+}
+
+// overrideThreshold returns the config to be stored at the scope with only the threshold changed:
+// the rest of the settings in effect at the scope (e.g., inherited from the namespace) are kept.
+// A nil threshold resets it to the parent level's one, and if nothing else is overridden, the config is dropped.
+func overrideThreshold(configs model.CheckConfigs, checkId model.CheckId, scope model.ApplicationId, threshold *float32) *model.CheckConfigSimple {
+	cfg := configs.GetSimple(checkId, scope)
+	if threshold != nil {
+		cfg.Threshold = *threshold
+		return &cfg
+	}
+	parentScope := model.ApplicationIdZero
+	if !scope.IsNamespaceScope() {
+		parentScope = model.NewNamespaceScopeId(scope.Namespace)
+	}
+	parent := configs.GetSimple(checkId, parentScope)
+	cfg.Threshold = parent.Threshold
+	if reflect.DeepEqual(cfg, parent) {
+		return nil
+	}
+	return &cfg
+}
+
 func (api *Api) Profile(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	projectId := db.ProjectId(vars["project"])
@@ -606,9 +2774,45 @@ func (api *Api) Tracing(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJson(w, views.Tracing(r.Context(), project, app, settings, q, world))
 }
 
+func (api *Api) RawLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	appId, err := model.NewApplicationIdFromString(vars["app"])
+	if err != nil {
+		klog.Warningln(err)
+		http.Error(w, "invalid application id: "+vars["app"], http.StatusBadRequest)
+		return
+	}
+	world, project, err := api.loadWorldByRequest(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if world == nil {
+		return
+	}
+	app := world.GetApplication(appId)
+	if app == nil {
+		klog.Warningln("application not found:", appId)
+		http.Error(w, "Application not found", http.StatusNotFound)
+		return
+	}
+	var chClient *clickhouse.Client
+	if cfg := project.Settings.Integrations.Clickhouse; cfg != nil {
+		clients, err := api.clickhouse.getClients(project.Id, *cfg)
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		chClient = clients.store
+	}
+	utils.WriteJson(w, views.RawLogs(r.Context(), project, app, chClient, r.URL.Query(), world.Ctx))
+}
+
 func (api *Api) Node(w http.ResponseWriter, r *http.Request) {
 	nodeName := mux.Vars(r)["node"]
-	world, _, err := api.loadWorldByRequest(r)
+	world, project, err := api.loadWorldByRequest(r)
 	if err != nil {
 		klog.Errorln(err)
 		http.Error(w, "", http.StatusInternalServerError)
@@ -623,12 +2827,65 @@ func (api *Api) Node(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Node not found", http.StatusNotFound)
 		return
 	}
-	utils.WriteJson(w, views.Node(world, node))
+	report := views.Node(world, project, node)
+	if t := translator(r, project); t != nil {
+		report.Translate(t.T)
+	}
+	utils.WriteJson(w, report)
 }
 
-func (api *Api) loadWorld(ctx context.Context, project *db.Project, from, to timeseries.Time) (*model.World, error) {
-	cc := api.cache.GetCacheClient(project)
-	cacheTo, err := cc.GetTo()
+func (api *Api) NodeReportPDF(w http.ResponseWriter, r *http.Request) {
+	nodeName := mux.Vars(r)["node"]
+	world, project, err := api.loadWorldByRequest(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if world == nil {
+		return
+	}
+	node := world.GetNode(nodeName)
+	if node == nil {
+		klog.Warningf("node not found: %s ", nodeName)
+		http.Error(w, "Node not found", http.StatusNotFound)
+		return
+	}
+	writePDF(w, world, nodeName, views.Node(world, project, node))
+}
+
+func writePDF(w http.ResponseWriter, world *model.World, title string, report *model.AuditReport) {
+	buf := &bytes.Buffer{}
+	if err := views.ReportPDF(buf, world, title, report); err != nil {
+		klog.Errorln("failed to render PDF:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.pdf"`, slug(title), slug(string(report.Name))))
+	_, _ = w.Write(buf.Bytes())
+}
+
+func slug(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// worldRange is the actual time range of a world to be loaded. The data version is the time the metric cache is filled up to.
+type worldRange struct {
+	from        timeseries.Time
+	to          timeseries.Time
+	step        timeseries.Duration
+	dataVersion timeseries.Time
+}
+
+// getWorldRange returns the range of the world for the requested time range, or nil if there's no data for it.
+func (api *Api) getWorldRange(project *db.Project, from, to timeseries.Time) (*worldRange, error) {
+	cacheTo, err := api.cache.GetCacheClient(project).GetTo()
 	if err != nil {
 		return nil, err
 	}
@@ -646,23 +2903,52 @@ func (api *Api) loadWorld(ctx context.Context, project *db.Project, from, to tim
 		to = cacheTo
 		from = to.Add(-duration)
 	}
-	step = increaseStepForBigDurations(duration, step)
+	step = timeseries.IncreaseStepForBigDurations(duration, step)
+	return &worldRange{from: from, to: to, step: step, dataVersion: cacheTo}, nil
+}
+
+func (api *Api) loadWorld(ctx context.Context, project *db.Project, from, to timeseries.Time) (*model.World, error) {
+	rng, err := api.getWorldRange(project, from, to)
+	if err != nil || rng == nil {
+		return nil, err
+	}
+	return api.loadWorldInRange(ctx, project, rng)
+}
 
+func (api *Api) loadWorldInRange(ctx context.Context, project *db.Project, rng *worldRange) (*model.World, error) {
 	t := time.Now()
-	world, err := constructor.New(api.db, project, cc, api.pricing).LoadWorld(ctx, from, to, step, nil)
+	c := constructor.New(api.db, project, api.cache.GetCacheClient(project), api.pricing).WithMemoryLimit(api.worldMemoryLimit)
+	if cfg := project.Settings.Integrations.Clickhouse; cfg != nil && cfg.Ingest {
+		clients, err := api.clickhouse.getClients(project.Id, *cfg)
+		if err != nil {
+			return nil, err
+		}
+		c.WithQueryStats(clients.store)
+	}
+	world, err := c.LoadWorld(ctx, rng.from, rng.to, rng.step, nil)
 	klog.Infof("world loaded in %s", time.Since(t))
 	return world, err
 }
 
 func (api *Api) loadWorldByRequest(r *http.Request) (*model.World, *db.Project, error) {
+	project, from, to, err := api.getProjectAndRange(r)
+	if err != nil || project == nil {
+		return nil, nil, err
+	}
+	world, err := api.loadWorld(r.Context(), project, from, to)
+	return world, project, err
+}
+
+// getProjectAndRange returns the project and the time range requested, the project is nil if it doesn't exist.
+func (api *Api) getProjectAndRange(r *http.Request) (*db.Project, timeseries.Time, timeseries.Time, error) {
 	projectId := db.ProjectId(mux.Vars(r)["project"])
 	project, err := api.db.GetProject(projectId)
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
 			klog.Warningln("project not found:", projectId)
-			return nil, nil, nil
+			return nil, 0, 0, nil
 		}
-		return nil, nil, err
+		return nil, 0, 0, err
 	}
 
 	now := timeseries.Now()
@@ -681,30 +2967,5 @@ func (api *Api) loadWorldByRequest(r *http.Request) (*model.World, *db.Project,
 			}
 		}
 	}
-
-	world, err := api.loadWorld(r.Context(), project, from, to)
-	return world, project, err
-}
-
-func increaseStepForBigDurations(duration, step timeseries.Duration) timeseries.Duration {
-	switch {
-	case duration > 5*24*timeseries.Hour:
-		return maxDuration(step, 60*timeseries.Minute)
-	case duration > 24*timeseries.Hour:
-		return maxDuration(step, 15*timeseries.Minute)
-	case duration > 12*timeseries.Hour:
-		return maxDuration(step, 10*timeseries.Minute)
-	case duration > 6*timeseries.Hour:
-		return maxDuration(step, 5*timeseries.Minute)
-	case duration > timeseries.Hour:
-		return maxDuration(step, timeseries.Minute)
-	}
-	return step
-}
-
-func maxDuration(d1, d2 timeseries.Duration) timeseries.Duration {
-	if d1 >= d2 {
-		return d1
-	}
-	return d2
+	return project, from, to, nil
 }
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestParseTableQuery(t *testing.T) {
+	q, err := url.ParseQuery("offset=20&limit=10&filter=0:cart&filter=2:a:b&filter=x:y&filter=bad&sort=-1&sort=0&sort=x")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		model.TableQuery{
+			Offset:  20,
+			Limit:   10,
+			Filters: map[int]string{0: "cart", 2: "a:b"},
+			Sort:    []model.TableSort{{Column: 1, Desc: true}, {Column: 0}},
+		},
+		parseTableQuery(q),
+	)
+	assert.Equal(t, model.TableQuery{Filters: map[int]string{}}, parseTableQuery(url.Values{}))
+}
+
+func TestReportTable(t *testing.T) {
+	report := &model.AuditReport{}
+	first, second := model.NewTable("a"), model.NewTable("b")
+	report.Widgets = append(report.Widgets, &model.Widget{Chart: &model.Chart{}}, &model.Widget{Table: first}, &model.Widget{Table: second})
+	assert.Equal(t, first, reportTable(report, ""))
+	assert.Equal(t, first, reportTable(report, "0"))
+	assert.Equal(t, second, reportTable(report, "1"))
+	assert.Nil(t, reportTable(report, "2"))
+}
+
+func TestOverrideThreshold(t *testing.T) {
+	checkId := model.Checks.CPUNode.Id
+	appId := model.NewApplicationId("default", model.ApplicationKindDeployment, "cart")
+	nsId := model.NewNamespaceScopeId("default")
+	th := func(v float32) *float32 { return &v }
+	configs := model.CheckConfigs{
+		nsId:  {checkId: []byte(`{"threshold":70,"severity":"critical"}`)},
+		appId: {checkId: []byte(`{"threshold":90,"critical_threshold":95,"schedule":[{"from":"00:00","to":"06:00","threshold":99}]}`)},
+	}
+
+	cfg := overrideThreshold(configs, checkId, appId, th(80))
+	assert.Equal(t, float32(80), cfg.Threshold)
+	assert.Equal(t, float32(95), *cfg.CriticalThreshold)
+	assert.Len(t, cfg.Schedule, 1)
+
+	cfg = overrideThreshold(configs, checkId, appId, nil)
+	assert.Equal(t, float32(70), cfg.Threshold)
+	assert.Equal(t, float32(95), *cfg.CriticalThreshold)
+
+	other := model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog")
+	cfg = overrideThreshold(configs, checkId, other, th(75))
+	assert.Equal(t, float32(75), cfg.Threshold)
+	assert.Equal(t, model.CRITICAL, cfg.Severity)
+	assert.Nil(t, overrideThreshold(configs, checkId, other, nil))
+
+	assert.Nil(t, overrideThreshold(configs, checkId, model.NewNamespaceScopeId("prod"), nil))
+	cfg = overrideThreshold(configs, checkId, nsId, nil)
+	assert.Equal(t, model.CheckConfigSimple{Threshold: model.Checks.CPUNode.DefaultThreshold, Severity: model.CRITICAL}, *cfg)
+}
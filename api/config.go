@@ -0,0 +1,264 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// maskedRe matches the placeholders the forms replace the secrets with, e.g., <token> or http://<hidden>.
+var maskedRe = regexp.MustCompile(`^(https?://)?<[a-z_]+>$`)
+
+// maskConfigSecrets replaces the secrets of the exported projects with the placeholders the integration forms use,
+// so the document can be shared and stored in a repository. On import, the placeholders are replaced with the stored values.
+func maskConfigSecrets(cfg *db.Config) error {
+	// the forms mask the secrets of the nested structs (e.g., basic auth) in place, so a deep copy is masked
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	*cfg = db.Config{}
+	if err = json.Unmarshal(data, cfg); err != nil {
+		return err
+	}
+	for i := range cfg.Projects {
+		pc := &cfg.Projects[i]
+		p := &db.Project{Prometheus: pc.Prometheus, Settings: pc.Settings}
+		if pc.Prometheus.Configured() {
+			f := &IntegrationFormPrometheus{}
+			f.Get(p, true)
+			pc.Prometheus = f.IntegrationsPrometheus
+		}
+		integrations, err := integrationConfigs(pc.Settings.Integrations)
+		if err != nil {
+			return err
+		}
+		for t := range integrations {
+			f := NewIntegrationForm(t)
+			if f == nil {
+				continue
+			}
+			f.Get(p, true)
+			if integrations[t], err = json.Marshal(f); err != nil {
+				return err
+			}
+		}
+		if err = setIntegrationConfigs(&pc.Settings.Integrations, integrations); err != nil {
+			return err
+		}
+		pc.Settings.Webhooks = maskWebhooks(pc.Settings.Webhooks)
+		pc.Settings.Repositories = maskRepositories(pc.Settings.Repositories)
+		if pc.Settings.EmbedKey != "" {
+			pc.Settings.EmbedKey = "<secret>"
+		}
+		if pc.Settings.IngestionKey != "" {
+			pc.Settings.IngestionKey = "<secret>"
+		}
+	}
+	return nil
+}
+
+func maskWebhooks(webhooks []model.Webhook) []model.Webhook {
+	res := make([]model.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		if w.Secret != "" {
+			w.Secret = "<secret>"
+		}
+		headers := make([]utils.Header, 0, len(w.CustomHeaders))
+		for _, h := range w.CustomHeaders {
+			headers = append(headers, utils.Header{Key: h.Key, Value: "<header>"})
+		}
+		w.CustomHeaders = headers
+		res = append(res, w)
+	}
+	return res
+}
+
+func maskRepositories(repositories model.Repositories) model.Repositories {
+	res := make(model.Repositories, 0, len(repositories))
+	for _, r := range repositories {
+		if r.Token != "" {
+			r.Token = "<token>"
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// restoreMasked replaces the placeholders of the masked secrets in v with the values of the stored configuration.
+// The elements of slices are matched by their Id field if they have one (HTTP headers by their key), by their index otherwise.
+func restoreMasked(v, stored reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || stored.IsNil() {
+			return
+		}
+		restoreMasked(v.Elem(), stored.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				restoreMasked(v.Field(i), stored.Field(i))
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if s, ok := storedElem(v.Index(i), stored, i); ok {
+				restoreMasked(v.Index(i), s)
+			}
+		}
+	case reflect.String:
+		if v.CanSet() && maskedRe.MatchString(v.String()) {
+			v.SetString(stored.String())
+		}
+	}
+}
+
+// restoreMaskedItem replaces the placeholders of the masked secrets in the item posted by a form
+// with the secrets of the stored item having the same Id.
+func restoreMaskedItem[T any](item *T, stored []T) {
+	items := []T{*item}
+	restoreMasked(reflect.ValueOf(items), reflect.ValueOf(stored))
+	*item = items[0]
+}
+
+func storedElem(e, stored reflect.Value, i int) (reflect.Value, bool) {
+	if e.Type() == reflect.TypeOf(utils.Header{}) {
+		for j := 0; j < stored.Len(); j++ {
+			if stored.Index(j).FieldByName("Key").String() == e.FieldByName("Key").String() {
+				return stored.Index(j), true
+			}
+		}
+		return reflect.Value{}, false
+	}
+	if e.Kind() == reflect.Struct {
+		if id := e.FieldByName("Id"); id.IsValid() && id.Kind() == reflect.String {
+			for j := 0; j < stored.Len(); j++ {
+				if stored.Index(j).FieldByName("Id").String() == id.String() {
+					return stored.Index(j), true
+				}
+			}
+			return reflect.Value{}, false
+		}
+	}
+	if i < stored.Len() {
+		return stored.Index(i), true
+	}
+	return reflect.Value{}, false
+}
+
+// validateProjectConfig validates the sections of the imported project through the same forms the UI uses
+// and normalizes them the way the forms do.
+func validateProjectConfig(pc *db.ProjectConfig) error {
+	f := &ProjectForm{Name: pc.Name, Timezone: pc.Settings.Timezone, Language: pc.Settings.Language}
+	if !f.Valid() {
+		return fmt.Errorf("invalid project name, timezone or language")
+	}
+	if pc.Prometheus.Configured() && !pc.Prometheus.Embedded {
+		f := &IntegrationFormPrometheus{IntegrationsPrometheus: pc.Prometheus}
+		if !f.Valid() {
+			return fmt.Errorf("invalid prometheus integration")
+		}
+		pc.Prometheus = f.IntegrationsPrometheus
+	}
+	integrations, err := integrationConfigs(pc.Settings.Integrations)
+	if err != nil {
+		return err
+	}
+	for t, data := range integrations {
+		f := NewIntegrationForm(t)
+		if f == nil {
+			continue
+		}
+		if err = json.Unmarshal(data, f); err != nil || !f.Valid() {
+			return fmt.Errorf("invalid %s integration", t)
+		}
+		if integrations[t], err = json.Marshal(f); err != nil {
+			return err
+		}
+	}
+	if err = setIntegrationConfigs(&pc.Settings.Integrations, integrations); err != nil {
+		return err
+	}
+	for i, w := range pc.Settings.Webhooks {
+		f := &WebhookForm{Name: w.Name, Url: w.Url, Secret: w.Secret, CustomHeaders: w.CustomHeaders, Applications: w.Applications,
+			Checks: w.Checks, Deployments: w.Deployments, Template: w.Template, GroupBy: w.GroupBy, GroupWait: w.GroupWait}
+		if !f.Valid() {
+			return fmt.Errorf("invalid webhook %q", w.Name)
+		}
+		pc.Settings.Webhooks[i] = f.Get(w.Id)
+	}
+	for i, r := range pc.Settings.Repositories {
+		f := &RepositoryForm{Image: r.Image, Url: r.Url, Token: r.Token}
+		if !f.Valid() {
+			return fmt.Errorf("invalid repository %q", r.Image)
+		}
+		pc.Settings.Repositories[i] = f.Get(r.Id)
+	}
+	for i, c := range pc.Settings.CustomChecks {
+		f := &CustomCheckForm{Title: c.Title, Query: c.Query, Threshold: c.Threshold, Unit: c.Unit, Applications: strings.Join(c.Applications, " ")}
+		if !f.Valid() {
+			return fmt.Errorf("invalid custom check %q", c.Title)
+		}
+		pc.Settings.CustomChecks[i] = f.Get(c.Id)
+	}
+	for i, c := range pc.Settings.LogChecks {
+		f := &LogCheckForm{Title: c.Title, Regexp: c.Regexp, Threshold: c.Threshold, Applications: strings.Join(c.Applications, " ")}
+		if !f.Valid() {
+			return fmt.Errorf("invalid log check %q", c.Title)
+		}
+		pc.Settings.LogChecks[i] = f.Get(c.Id)
+	}
+	for i, c := range pc.Settings.CompositeChecks {
+		f := &CompositeCheckForm{Title: c.Title, Operator: c.Operator, Count: c.Count, Checks: c.Checks, Applications: strings.Join(c.Applications, " ")}
+		if !f.Valid() {
+			return fmt.Errorf("invalid composite check %q", c.Title)
+		}
+		pc.Settings.CompositeChecks[i] = f.Get(c.Id)
+	}
+	for _, w := range pc.Settings.MaintenanceWindows {
+		f := &MaintenanceWindowForm{From: w.From, To: w.To, Checks: w.Checks, Comment: w.Comment}
+		if !f.Valid() {
+			return fmt.Errorf("invalid maintenance window")
+		}
+	}
+	return nil
+}
+
+// integrationConfigs returns the JSON configs of the configured integrations by their types
+// (the JSON fields of db.Integrations are named after the types).
+func integrationConfigs(integrations db.Integrations) (map[db.IntegrationType]json.RawMessage, error) {
+	data, err := json.Marshal(integrations)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	res := map[db.IntegrationType]json.RawMessage{}
+	for name, v := range fields {
+		if name == "base_url" || string(v) == "null" {
+			continue
+		}
+		res[db.IntegrationType(name)] = v
+	}
+	return res, nil
+}
+
+func setIntegrationConfigs(integrations *db.Integrations, configs map[db.IntegrationType]json.RawMessage) error {
+	fields := map[string]any{"base_url": integrations.BaseUrl}
+	for t, v := range configs {
+		fields[string(t)] = v
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	*integrations = db.Integrations{}
+	return json.Unmarshal(data, integrations)
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testConfig() *db.Config {
+	return &db.Config{Projects: []db.ProjectConfig{{
+		Name: "production",
+		Prometheus: db.IntegrationsPrometheus{
+			Url:       "http://prometheus:9090",
+			BasicAuth: &utils.BasicAuth{User: "user", Password: "password"},
+		},
+		Settings: db.Settings{
+			Integrations: db.Integrations{
+				BaseUrl: "http://coroot:8080",
+				Slack:   &db.IntegrationSlack{Token: "xoxb-token", DefaultChannel: "ops", Incidents: true},
+			},
+			Webhooks: []model.Webhook{{Id: "1", Name: "hook", Url: "http://hook", Secret: "s3cret", Checks: true, GroupBy: model.WebhookGroupByNone,
+				CustomHeaders: []utils.Header{{Key: "Authorization", Value: "Bearer token"}}}},
+			Repositories: model.Repositories{{Id: "1", Image: "app", Url: "https://github.com/org/app", Token: "ghp_token"}},
+			EmbedKey:     "embed-key",
+			IngestionKey: "ingestion-key",
+		},
+	}}}
+}
+
+func TestMaskConfigSecrets(t *testing.T) {
+	stored := testConfig()
+	cfg := testConfig()
+	require.NoError(t, maskConfigSecrets(cfg))
+
+	pc := cfg.Projects[0]
+	assert.Equal(t, "http://<hidden>", pc.Prometheus.Url)
+	assert.Equal(t, "<password>", pc.Prometheus.BasicAuth.Password)
+	assert.Equal(t, "<token>", pc.Settings.Integrations.Slack.Token)
+	assert.Equal(t, "ops", pc.Settings.Integrations.Slack.DefaultChannel)
+	assert.Equal(t, "http://coroot:8080", pc.Settings.Integrations.BaseUrl)
+	assert.Equal(t, "<secret>", pc.Settings.Webhooks[0].Secret)
+	assert.Equal(t, "<header>", pc.Settings.Webhooks[0].CustomHeaders[0].Value)
+	assert.Equal(t, "<token>", pc.Settings.Repositories[0].Token)
+	assert.Equal(t, "<secret>", pc.Settings.EmbedKey)
+	assert.Equal(t, "<secret>", pc.Settings.IngestionKey)
+
+	restoreMasked(reflect.ValueOf(&cfg.Projects[0]).Elem(), reflect.ValueOf(&stored.Projects[0]).Elem())
+	assert.Equal(t, testConfig(), cfg)
+}
+
+func TestValidateProjectConfig(t *testing.T) {
+	pc := testConfig().Projects[0]
+	assert.NoError(t, validateProjectConfig(&pc))
+
+	pc = testConfig().Projects[0]
+	pc.Settings.Integrations.Slack.DefaultChannel = ""
+	assert.EqualError(t, validateProjectConfig(&pc), "invalid slack integration")
+
+	pc = testConfig().Projects[0]
+	pc.Settings.Webhooks[0].Url = "ftp://hook"
+	assert.EqualError(t, validateProjectConfig(&pc), `invalid webhook "hook"`)
+}
+
+func TestRestoreMaskedWebhook(t *testing.T) {
+	stored := testConfig().Projects[0].Settings.Webhooks
+	stored[0].CustomHeaders = append(stored[0].CustomHeaders, utils.Header{Key: "X-Api-Key", Value: "key"})
+
+	wh := maskWebhooks(stored)[0]
+	assert.Equal(t, "<secret>", wh.Secret)
+	assert.Equal(t, []utils.Header{{Key: "Authorization", Value: "<header>"}, {Key: "X-Api-Key", Value: "<header>"}}, wh.CustomHeaders)
+	assert.Equal(t, "s3cret", stored[0].Secret, "the stored webhooks must not be modified")
+
+	// the first header is removed, a new one is added
+	wh.CustomHeaders = []utils.Header{{Key: "X-Api-Key", Value: "<header>"}, {Key: "X-Team", Value: "ops"}}
+	restoreMaskedItem(&wh, stored)
+	assert.Equal(t, "s3cret", wh.Secret)
+	assert.Equal(t, []utils.Header{{Key: "X-Api-Key", Value: "key"}, {Key: "X-Team", Value: "ops"}}, wh.CustomHeaders)
+
+	wh = maskWebhooks(stored)[0]
+	wh.Secret = "new"
+	restoreMaskedItem(&wh, stored)
+	assert.Equal(t, "new", wh.Secret)
+
+	// a new webhook has nothing to be restored from
+	wh = maskWebhooks(stored)[0]
+	wh.Id = ""
+	restoreMaskedItem(&wh, stored)
+	assert.Equal(t, "<secret>", wh.Secret)
+
+	assert.Equal(t, []model.Webhook{}, maskWebhooks(nil))
+}
+
+func TestRestoreMaskedRepository(t *testing.T) {
+	stored := model.Repositories{
+		{Id: "1", Image: "app", Url: "https://github.com/org/app", Token: "ghp_token"},
+		{Id: "2", Image: "public", Url: "https://github.com/org/public"},
+	}
+
+	masked := maskRepositories(stored)
+	assert.Equal(t, "<token>", masked[0].Token)
+	assert.Equal(t, "", masked[1].Token, "empty tokens must not be masked")
+	assert.Equal(t, "ghp_token", stored[0].Token, "the stored repositories must not be modified")
+
+	repo := masked[0]
+	repo.Url = "https://github.com/org/app2"
+	restoreMaskedItem(&repo, stored)
+	assert.Equal(t, model.Repository{Id: "1", Image: "app", Url: "https://github.com/org/app2", Token: "ghp_token"}, repo)
+
+	repo = masked[0]
+	repo.Token = ""
+	restoreMaskedItem(&repo, stored)
+	assert.Equal(t, "", repo.Token, "the token can be removed")
+
+	assert.Equal(t, model.Repositories{}, maskRepositories(nil))
+}
+
+func TestConfigImportTooLarge(t *testing.T) {
+	body := "projects: []\n# " + strings.Repeat("x", configMaxSize)
+	w := httptest.NewRecorder()
+	(&Api{}).Config(w, httptest.NewRequest(http.MethodPost, "/api/config", strings.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
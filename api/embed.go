@@ -0,0 +1,207 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/coroot/coroot/api/views"
+	"github.com/coroot/coroot/auditor"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// embedToken grants read-only access to a single chart or check of an application report.
+// It's signed with the embed key of the project, resetting the key revokes all the tokens of the project.
+type embedToken struct {
+	ProjectId     db.ProjectId        `json:"p"`
+	ApplicationId string              `json:"a"`
+	Report        string              `json:"r"`
+	Widget        string              `json:"w,omitempty"`
+	Check         string              `json:"c,omitempty"`
+	Window        timeseries.Duration `json:"d"`
+	ExpiresAt     timeseries.Time     `json:"e,omitempty"`
+}
+
+// embedMaxWindow caps the time range of the embedded charts, since anyone with a link can request them.
+const embedMaxWindow = 7 * timeseries.Day
+
+var errInvalidEmbedToken = errors.New("invalid embed token")
+
+func (t embedToken) sign(key string) (string, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(embedSignature(key, payload)), nil
+}
+
+func embedSignature(key, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// parseEmbedToken decodes the token without verifying it, the signature is returned separately.
+func parseEmbedToken(s string) (*embedToken, string, []byte, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 2 {
+		return nil, "", nil, errInvalidEmbedToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, "", nil, errInvalidEmbedToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, "", nil, errInvalidEmbedToken
+	}
+	var t embedToken
+	if err = json.Unmarshal(data, &t); err != nil {
+		return nil, "", nil, errInvalidEmbedToken
+	}
+	return &t, parts[0], signature, nil
+}
+
+// EmbedLinks creates embed tokens (POST) for the charts and checks of the application reports,
+// or revokes all the tokens of the project (DELETE).
+func (api *Api) EmbedLinks(w http.ResponseWriter, r *http.Request) {
+	if api.readOnly {
+		return
+	}
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+
+	if r.Method == http.MethodDelete {
+		if err := api.db.ResetEmbedKey(projectId); err != nil {
+			klog.Errorln("failed to reset embed key:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	var form EmbedForm
+	if err := ReadAndValidate(r, &form); err != nil {
+		klog.Warningln("bad request:", err)
+		http.Error(w, "Invalid report, chart, check, window or expiration", http.StatusBadRequest)
+		return
+	}
+	key, err := api.db.GetEmbedKey(projectId)
+	if err != nil {
+		klog.Errorln("failed to get embed key:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	t := embedToken{
+		ProjectId:     projectId,
+		ApplicationId: vars["app"],
+		Report:        form.Report,
+		Widget:        form.Widget,
+		Check:         form.Check,
+		Window:        form.Window,
+	}
+	if form.TTL > 0 {
+		t.ExpiresAt = timeseries.Now().Add(form.TTL)
+	}
+	token, err := t.sign(key)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJson(w, token)
+}
+
+// Embed renders the chart or the check the token refers to for the last Window of time.
+// The rendered charts are cached the same way the application reports are.
+func (api *Api) Embed(w http.ResponseWriter, r *http.Request) {
+	t, payload, signature, err := parseEmbedToken(mux.Vars(r)["token"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	project, err := api.db.GetProject(t.ProjectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, errInvalidEmbedToken.Error(), http.StatusForbidden)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	key := project.Settings.EmbedKey
+	if key == "" || !hmac.Equal(signature, embedSignature(key, payload)) {
+		http.Error(w, errInvalidEmbedToken.Error(), http.StatusForbidden)
+		return
+	}
+	now := timeseries.Now()
+	if !t.ExpiresAt.IsZero() && t.ExpiresAt.Before(now) {
+		http.Error(w, "The embed link has expired", http.StatusForbidden)
+		return
+	}
+	appId, err := model.NewApplicationIdFromString(t.ApplicationId)
+	if err != nil {
+		http.Error(w, errInvalidEmbedToken.Error(), http.StatusForbidden)
+		return
+	}
+	window := t.Window
+	if window > embedMaxWindow { // the links created before the limit was introduced
+		window = embedMaxWindow
+	}
+	rng, err := api.getWorldRange(project, now.Add(-window), now)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if rng == nil {
+		http.Error(w, "No data", http.StatusNotFound)
+		return
+	}
+	cacheKey := api.reports.key(project.Id, appId, rng, url.Values{"embed": {t.Report, t.Widget, t.Check}})
+	if body := api.reports.get(cacheKey); body != nil {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+		return
+	}
+	world, err := api.loadWorldInRange(r.Context(), project, rng)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if world == nil {
+		http.Error(w, "No data", http.StatusNotFound)
+		return
+	}
+	app := world.GetApplication(appId)
+	if app == nil {
+		http.Error(w, "Application not found", http.StatusNotFound)
+		return
+	}
+	auditor.Audit(world, project)
+	e := views.Embed(app, t.Report, t.Widget, t.Check)
+	if e == nil {
+		http.Error(w, "Chart or check not found", http.StatusNotFound)
+		return
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	api.reports.put(cacheKey, body)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"crypto/hmac"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEmbedToken(t *testing.T) {
+	token := embedToken{ProjectId: "p", ApplicationId: "default:Deployment:app", Report: "CPU", Widget: "usage", Window: timeseries.Hour}
+	s, err := token.sign("key")
+	require.NoError(t, err)
+
+	parsed, payload, signature, err := parseEmbedToken(s)
+	require.NoError(t, err)
+	assert.Equal(t, token, *parsed)
+	assert.True(t, hmac.Equal(signature, embedSignature("key", payload)))
+	assert.False(t, hmac.Equal(signature, embedSignature("other", payload)))
+
+	for _, s := range []string{"", "x", "x.y.z", "!.!"} {
+		_, _, _, err = parseEmbedToken(s)
+		assert.ErrorIs(t, err, errInvalidEmbedToken, s)
+	}
+}
+
+func TestEmbedFormWindow(t *testing.T) {
+	f := &EmbedForm{Report: "CPU", Widget: "usage", Window: embedMaxWindow}
+	assert.True(t, f.Valid())
+	f.Window = embedMaxWindow + timeseries.Hour
+	assert.False(t, f.Valid())
+	f.Window = 0
+	assert.False(t, f.Valid())
+}
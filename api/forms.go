@@ -3,18 +3,30 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/coroot/coroot/api/views/grafana"
+	"github.com/coroot/coroot/argocd"
+	"github.com/coroot/coroot/aws"
+	"github.com/coroot/coroot/clickhouse"
 	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/gcp"
+	"github.com/coroot/coroot/i18n"
+	"github.com/coroot/coroot/logs"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/notifications"
 	"github.com/coroot/coroot/profiling"
 	"github.com/coroot/coroot/prom"
+	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/tracing"
 	"github.com/coroot/coroot/utils"
+	promModel "github.com/prometheus/common/model"
 	"net"
 	"net/http"
+	"net/mail"
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 )
 
 var (
@@ -38,13 +50,21 @@ func ReadAndValidate(r *http.Request, f Form) error {
 }
 
 type ProjectForm struct {
-	Name string `json:"name"`
+	Name     string `json:"name"`
+	Timezone string `json:"timezone"`
+	Language string `json:"language"`
 }
 
 func (f *ProjectForm) Valid() bool {
 	if !slugRe.MatchString(f.Name) {
 		return false
 	}
+	if _, err := time.LoadLocation(f.Timezone); err != nil {
+		return false
+	}
+	if f.Language != "" && !i18n.Supported(f.Language) {
+		return false
+	}
 	return true
 }
 
@@ -59,201 +79,1515 @@ func (f *ProjectStatusForm) Valid() bool {
 
 type CheckConfigForm struct {
 	Configs []*model.CheckConfigSimple `json:"configs"`
+
+	unit model.CheckUnit
 }
 
 func (f *CheckConfigForm) Valid() bool {
+	for _, c := range f.Configs {
+		if c == nil {
+			continue
+		}
+		switch c.Severity {
+		case model.UNKNOWN, model.INFO, model.WARNING, model.CRITICAL:
+		default:
+			return false
+		}
+		if a := c.Adaptive; a != nil && (a.Percentile <= 0 || a.Percentile > 100 || a.Margin < 0 || a.Window < 0) {
+			return false
+		}
+		for _, st := range c.Schedule {
+			if st.Validate() != nil {
+				return false
+			}
+		}
+		if !c.Unit.Valid() || c.ConvertUnit(f.unit) != nil {
+			return false
+		}
+		if c.Instances != nil && c.Instances.Validate() != nil {
+			return false
+		}
+	}
 	return true
 }
 
-type CheckConfigSLOAvailabilityForm struct {
-	Configs []model.CheckConfigSLOAvailability `json:"configs"`
-	Default bool                               `json:"default"`
+type CheckDryRunForm struct {
+	Config model.CheckConfigSimple `json:"config"`
+	From   timeseries.Time         `json:"from"`
+	To     timeseries.Time         `json:"to"`
+
+	unit model.CheckUnit
 }
 
-func (f *CheckConfigSLOAvailabilityForm) Valid() bool {
-	for _, c := range f.Configs {
-		if c.Custom && (c.TotalRequestsQuery == "" || c.FailedRequestsQuery == "") {
+func (f *CheckDryRunForm) Valid() bool {
+	now := timeseries.Now()
+	if f.To.IsZero() || f.To.After(now) {
+		f.To = now
+	}
+	if f.From.IsZero() {
+		f.From = f.To.Add(-timeseries.Day)
+	}
+	if !f.From.Before(f.To) || f.To.Sub(f.From) > model.CheckValuesRetention {
+		return false
+	}
+	switch f.Config.Severity {
+	case model.UNKNOWN, model.INFO, model.WARNING, model.CRITICAL:
+	default:
+		return false
+	}
+	if !f.Config.Unit.Valid() || f.Config.ConvertUnit(f.unit) != nil {
+		return false
+	}
+	return true
+}
+
+type CheckOverrideForm struct {
+	ApplicationId *model.ApplicationId `json:"application_id"`
+	Namespace     string               `json:"namespace"`
+	Threshold     *float32             `json:"threshold"`
+	Unit          model.CheckUnit      `json:"unit"`
+
+	unit model.CheckUnit
+}
+
+func (f *CheckOverrideForm) Valid() bool {
+	if (f.ApplicationId == nil) == (f.Namespace == "") {
+		return false
+	}
+	if f.ApplicationId != nil && (f.ApplicationId.IsZero() || f.ApplicationId.IsNamespaceScope()) {
+		return false
+	}
+	if f.Threshold != nil {
+		cfg := model.CheckConfigSimple{Threshold: *f.Threshold, Unit: f.Unit}
+		if !cfg.Unit.Valid() || cfg.ConvertUnit(f.unit) != nil {
 			return false
 		}
+		f.Threshold = &cfg.Threshold
 	}
 	return true
 }
 
-type CheckConfigSLOLatencyForm struct {
-	Configs []model.CheckConfigSLOLatency `json:"configs"`
-	Default bool                          `json:"default"`
+func (f *CheckOverrideForm) Scope() model.ApplicationId {
+	if f.ApplicationId != nil {
+		return *f.ApplicationId
+	}
+	return model.NewNamespaceScopeId(f.Namespace)
 }
 
-func (f *CheckConfigSLOLatencyForm) Valid() bool {
-	for _, c := range f.Configs {
-		if c.Custom && (c.HistogramQuery == "" || c.ObjectiveBucket <= 0) {
+type CheckMuteForm struct {
+	Duration timeseries.Duration `json:"duration"`
+	Schedule string              `json:"schedule"`
+	Comment  string              `json:"comment"`
+}
+
+func (f *CheckMuteForm) Valid() bool {
+	if f.Duration <= 0 {
+		return false
+	}
+	if f.Schedule != "" {
+		if f.Duration > timeseries.Day {
+			return false
+		}
+		if _, err := utils.ParseCron(f.Schedule); err != nil {
 			return false
 		}
 	}
 	return true
 }
 
-type ApplicationCategoryForm struct {
-	Name           model.ApplicationCategory `json:"name"`
-	NewName        model.ApplicationCategory `json:"new_name"`
-	CustomPatterns string                    `json:"custom_patterns"`
-	customPatterns []string
+func (f *CheckMuteForm) Get(appId model.ApplicationId, checkId model.CheckId, now timeseries.Time) model.CheckMute {
+	m := model.CheckMute{ApplicationId: appId, CheckId: checkId, Schedule: f.Schedule, Comment: f.Comment}
+	if f.Schedule == "" {
+		m.Until = now.Add(f.Duration)
+	} else {
+		m.Duration = f.Duration
+	}
+	return m
+}
 
-	NotifyOfDeployments bool `json:"notify_of_deployments"`
+type MaintenanceWindowForm struct {
+	From    timeseries.Time `json:"from"`
+	To      timeseries.Time `json:"to"`
+	Checks  []model.CheckId `json:"checks"`
+	Comment string          `json:"comment"`
 }
 
-func (f *ApplicationCategoryForm) Valid() bool {
-	if !slugRe.MatchString(string(f.NewName)) {
+func (f *MaintenanceWindowForm) Valid() bool {
+	if f.From.IsZero() || !f.From.Before(f.To) {
 		return false
 	}
-	f.customPatterns = strings.Fields(f.CustomPatterns)
-	if !utils.GlobValidate(f.customPatterns) {
+	for _, id := range f.Checks {
+		if model.GetCheckConfig(id) == nil && !strings.HasPrefix(string(id), model.CustomCheckIdPrefix) && !strings.HasPrefix(string(id), model.LogCheckIdPrefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *MaintenanceWindowForm) Get() model.MaintenanceWindow {
+	return model.MaintenanceWindow{From: f.From, To: f.To, Checks: f.Checks, Comment: strings.TrimSpace(f.Comment)}
+}
+
+type UserAnnotationForm struct {
+	ApplicationId string          `json:"application_id"`
+	From          timeseries.Time `json:"from"`
+	To            timeseries.Time `json:"to"`
+	Text          string          `json:"text"`
+}
+
+func (f *UserAnnotationForm) Valid() bool {
+	f.Text = strings.TrimSpace(f.Text)
+	if f.Text == "" || f.From.IsZero() {
 		return false
 	}
-	for _, p := range f.customPatterns {
-		if strings.Count(p, "/") != 1 || strings.Index(p, "/") < 1 {
+	if f.To.IsZero() {
+		f.To = f.From
+	}
+	if f.To.Before(f.From) {
+		return false
+	}
+	if f.ApplicationId != "" {
+		if _, err := model.NewApplicationIdFromString(f.ApplicationId); err != nil {
 			return false
 		}
 	}
 	return true
 }
 
-type ApplicationSettingsPyroscopeForm struct {
-	db.ApplicationSettingsPyroscope
+func (f *UserAnnotationForm) Get() model.UserAnnotation {
+	return model.UserAnnotation{ApplicationId: f.ApplicationId, From: f.From, To: f.To, Text: f.Text}
 }
 
-func (f *ApplicationSettingsPyroscopeForm) Valid() bool {
+type SavedViewForm struct {
+	Name          string            `json:"name"`
+	ApplicationId string            `json:"application_id"`
+	Report        string            `json:"report"`
+	From          string            `json:"from"`
+	To            string            `json:"to"`
+	ChartGroups   map[string]string `json:"chart_groups"`
+	Compare       string            `json:"compare"`
+}
+
+func (f *SavedViewForm) Valid() bool {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" || f.Report == "" {
+		return false
+	}
+	if _, err := model.NewApplicationIdFromString(f.ApplicationId); err != nil {
+		return false
+	}
+	now := timeseries.Now()
+	if f.From != "" && utils.ParseTime(now, f.From, 0).IsZero() {
+		return false
+	}
+	if f.To != "" && utils.ParseTime(now, f.To, 0).IsZero() {
+		return false
+	}
 	return true
 }
 
-type ApplicationSettingsTracingForm struct {
-	db.ApplicationSettingsTracing
+func (f *SavedViewForm) Get(id string) model.SavedView {
+	return model.SavedView{
+		Id:            id,
+		Name:          f.Name,
+		ApplicationId: f.ApplicationId,
+		Report:        f.Report,
+		From:          f.From,
+		To:            f.To,
+		ChartGroups:   f.ChartGroups,
+		Compare:       f.Compare,
+	}
 }
 
-func (f *ApplicationSettingsTracingForm) Valid() bool {
+var dashboardPanelWidths = map[string]bool{"25%": true, "33%": true, "50%": true, "66%": true, "75%": true, "100%": true}
+
+type DashboardForm struct {
+	Name   string                 `json:"name"`
+	Panels []model.DashboardPanel `json:"panels"`
+}
+
+func (f *DashboardForm) Valid() bool {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" {
+		return false
+	}
+	for i := range f.Panels {
+		p := &f.Panels[i]
+		p.Title = strings.TrimSpace(p.Title)
+		if p.Width == "" {
+			p.Width = "50%"
+		}
+		if !dashboardPanelWidths[p.Width] {
+			return false
+		}
+		if p.IsPromQL() {
+			if !prom.IsQueryValid(p.Query) {
+				return false
+			}
+			p.ApplicationId, p.Report, p.Chart = "", "", ""
+			continue
+		}
+		if _, err := model.NewApplicationIdFromString(p.ApplicationId); err != nil {
+			return false
+		}
+		if p.Report == "" || p.Chart == "" {
+			return false
+		}
+		p.Legend = ""
+	}
 	return true
 }
 
-type IntegrationsForm struct {
-	BaseUrl string `json:"base_url"`
+func (f *DashboardForm) Get(id string) model.Dashboard {
+	return model.Dashboard{Id: id, Name: f.Name, Panels: f.Panels}
 }
 
-func (f *IntegrationsForm) Valid() bool {
-	if _, err := url.Parse(f.BaseUrl); err != nil || f.BaseUrl == "" {
+type DigestScheduleForm struct {
+	Name         string              `json:"name"`
+	Period       string              `json:"period"`
+	Cron         string              `json:"cron"`
+	Slack        bool                `json:"slack"`
+	SlackChannel string              `json:"slack_channel"`
+	Teams        bool                `json:"teams"`
+	Mattermost   bool                `json:"mattermost"`
+	Content      model.DigestContent `json:"content"`
+}
+
+func (f *DigestScheduleForm) Valid() bool {
+	f.Name = strings.TrimSpace(f.Name)
+	f.Cron = strings.TrimSpace(f.Cron)
+	f.SlackChannel = strings.TrimPrefix(strings.TrimSpace(f.SlackChannel), "#")
+	if f.Name == "" {
 		return false
 	}
-	f.BaseUrl = strings.TrimRight(f.BaseUrl, "/")
+	switch f.Period {
+	case model.DigestPeriodDaily, model.DigestPeriodWeekly:
+	default:
+		return false
+	}
+	if f.Cron != "" {
+		if _, err := utils.ParseCron(f.Cron); err != nil {
+			return false
+		}
+	}
+	if !f.Slack && !f.Teams && !f.Mattermost {
+		return false
+	}
+	c := f.Content
+	return c.UnhealthyApps || c.SLO || c.Deployments || c.Regressions
+}
+
+func (f *DigestScheduleForm) Get(id string) model.DigestSchedule {
+	return model.DigestSchedule{
+		Id:           id,
+		Name:         f.Name,
+		Period:       f.Period,
+		Cron:         f.Cron,
+		Slack:        f.Slack,
+		SlackChannel: f.SlackChannel,
+		Teams:        f.Teams,
+		Mattermost:   f.Mattermost,
+		Content:      f.Content,
+	}
+}
+
+type StatusPageForm struct {
+	Name         string                        `json:"name"`
+	Description  string                        `json:"description"`
+	Applications []model.StatusPageApplication `json:"applications"`
+}
+
+func (f *StatusPageForm) Valid() bool {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" || len(f.Applications) == 0 {
+		return false
+	}
+	for i := range f.Applications {
+		a := &f.Applications[i]
+		a.Title = strings.TrimSpace(a.Title)
+		if _, err := model.NewApplicationIdFromString(a.ApplicationId); err != nil {
+			return false
+		}
+		for _, id := range a.Checks {
+			if id != model.Checks.SLOAvailability.Id && id != model.Checks.SLOLatency.Id {
+				return false
+			}
+		}
+	}
 	return true
 }
 
-type IntegrationForm interface {
-	Form
-	Get(project *db.Project, masked bool)
-	Update(ctx context.Context, project *db.Project, clear bool) error
-	Test(ctx context.Context, project *db.Project) error
+func (f *StatusPageForm) Get(id string) model.StatusPage {
+	return model.StatusPage{Id: id, Name: f.Name, Description: f.Description, Applications: f.Applications}
 }
 
-func NewIntegrationForm(t db.IntegrationType) IntegrationForm {
-	switch t {
-	case db.IntegrationTypePrometheus:
-		return &IntegrationFormPrometheus{}
-	case db.IntegrationTypePyroscope:
-		return &IntegrationFormPyroscope{}
-	case db.IntegrationTypeClickhouse:
-		return &IntegrationFormClickhouse{}
-	case db.IntegrationTypeSlack:
-		return &IntegrationFormSlack{}
-	case db.IntegrationTypeTeams:
-		return &IntegrationFormTeams{}
-	case db.IntegrationTypePagerduty:
-		return &IntegrationFormPagerduty{}
-	case db.IntegrationTypeOpsgenie:
-		return &IntegrationFormOpsgenie{}
+type EmbedForm struct {
+	Report string              `json:"report"`
+	Widget string              `json:"widget"`
+	Check  string              `json:"check"`
+	Window timeseries.Duration `json:"window"`
+	TTL    timeseries.Duration `json:"ttl"`
+}
+
+func (f *EmbedForm) Valid() bool {
+	if f.Report == "" || (f.Widget == "") == (f.Check == "") {
+		return false
+	}
+	if f.Window <= 0 || f.Window > embedMaxWindow || f.TTL < 0 {
+		return false
+	}
+	return true
+}
+
+type GraphQLForm struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+func (f *GraphQLForm) Valid() bool {
+	return strings.TrimSpace(f.Query) != ""
+}
+
+// GrafanaSearchForm is the body of the /search (SimpleJSON) and /metrics (JSON datasource plugin) requests of the Grafana datasource.
+type GrafanaSearchForm struct {
+	Target string `json:"target"`
+	Metric string `json:"metric"`
+}
+
+func (f *GrafanaSearchForm) Valid() bool {
+	return true
+}
+
+type GrafanaQueryForm struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []grafana.Target `json:"targets"`
+}
+
+func (f *GrafanaQueryForm) Valid() bool {
+	return f.Range.From.Before(f.Range.To)
+}
+
+type NotificationTemplateForm struct {
+	model.NotificationTemplate
+	Preview bool `json:"preview"`
+}
+
+func (f *NotificationTemplateForm) Valid() bool {
+	switch db.IntegrationType(f.Destination) {
+	case db.IntegrationTypeSlack, db.IntegrationTypeTeams, db.IntegrationTypeTelegram, db.IntegrationTypeDiscord,
+		db.IntegrationTypeMattermost, db.IntegrationTypeEmail:
+	default:
+		return false
+	}
+	_, _, err := f.Render("", "")
+	return err == nil
+}
+
+// Render renders the template against sample data.
+func (f *NotificationTemplateForm) Render(project, baseUrl string) (string, string, error) {
+	return notifications.RenderIncidentTemplate(f.NotificationTemplate, notifications.SampleIncidentTemplateData(project, baseUrl))
+}
+
+type WebhookForm struct {
+	Name          string               `json:"name"`
+	Url           string               `json:"url"`
+	Secret        string               `json:"secret"`
+	CustomHeaders []utils.Header       `json:"custom_headers"`
+	Applications  bool                 `json:"applications"`
+	Checks        bool                 `json:"checks"`
+	Deployments   bool                 `json:"deployments"`
+	Template      string               `json:"template"`
+	GroupBy       model.WebhookGroupBy `json:"group_by"`
+	GroupWait     int                  `json:"group_wait"`
+}
+
+func (f *WebhookForm) Valid() bool {
+	f.Name = strings.TrimSpace(f.Name)
+	f.Url = strings.TrimSpace(f.Url)
+	if f.Name == "" || (!f.Applications && !f.Checks && !f.Deployments) {
+		return false
+	}
+	if u, err := url.Parse(f.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	for _, h := range f.CustomHeaders {
+		if !h.Valid() {
+			return false
+		}
+	}
+	if strings.TrimSpace(f.Template) != "" {
+		if _, err := notifications.ParseWebhookTemplate(f.Template); err != nil {
+			return false
+		}
+	}
+	switch f.GroupBy {
+	case model.WebhookGroupByNone:
+		f.GroupWait = 0
+	case model.WebhookGroupByApplication, model.WebhookGroupByNamespace:
+		if f.GroupWait < 1 || f.GroupWait > 60 {
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (f *WebhookForm) Get(id string) model.Webhook {
+	return model.Webhook{
+		Id:            id,
+		Name:          f.Name,
+		Url:           f.Url,
+		Secret:        f.Secret,
+		CustomHeaders: f.CustomHeaders,
+		Applications:  f.Applications,
+		Checks:        f.Checks,
+		Deployments:   f.Deployments,
+		Template:      f.Template,
+		GroupBy:       f.GroupBy,
+		GroupWait:     f.GroupWait,
+	}
+}
+
+type EscalationPolicyForm struct {
+	Name     string                 `json:"name"`
+	Severity model.Status           `json:"severity"`
+	Steps    []model.EscalationStep `json:"steps"`
+}
+
+func (f *EscalationPolicyForm) Valid() bool {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" || (f.Severity != model.WARNING && f.Severity != model.CRITICAL) || len(f.Steps) == 0 {
+		return false
+	}
+	for i := range f.Steps {
+		s := &f.Steps[i]
+		if s.Delay < 1 || (i > 0 && s.Delay < f.Steps[i-1].Delay) {
+			return false
+		}
+		if !validNotificationTarget(s.Destination, &s.Target) {
+			return false
+		}
+	}
+	return true
+}
+
+// validNotificationTarget checks that the destination is a notification integration and that the target is a valid recipient for it.
+// The target is cleared for the integrations whose recipient can't be overridden.
+func validNotificationTarget(destination string, target *string) bool {
+	*target = strings.TrimSpace(*target)
+	switch db.IntegrationType(destination) {
+	case db.IntegrationTypeSlack, db.IntegrationTypeOpsgenie:
+	case db.IntegrationTypeEmail:
+		if *target != "" {
+			if _, err := mail.ParseAddressList(*target); err != nil {
+				return false
+			}
+		}
+	case db.IntegrationTypeTwilio:
+		if model.ValidatePhones(*target) != nil {
+			return false
+		}
+	case db.IntegrationTypeTeams, db.IntegrationTypeTelegram, db.IntegrationTypeDiscord, db.IntegrationTypeMattermost, db.IntegrationTypePagerduty:
+		*target = ""
+	default:
+		return false
+	}
+	return true
+}
+
+func (f *EscalationPolicyForm) Get(id string) model.EscalationPolicy {
+	return model.EscalationPolicy{
+		Id:       id,
+		Name:     f.Name,
+		Severity: f.Severity,
+		Steps:    f.Steps,
+	}
+}
+
+type NotificationRouteForm struct {
+	Name        string                      `json:"name"`
+	Namespaces  string                      `json:"namespaces"`
+	Categories  []model.ApplicationCategory `json:"categories"`
+	Teams       string                      `json:"teams"`
+	Severity    model.Status                `json:"severity"`
+	MaxSeverity model.Status                `json:"max_severity"`
+	Checks      string                      `json:"checks"`
+	Destination string                      `json:"destination"`
+	Target      string                      `json:"target"`
+
+	namespaces []string
+	teams      []string
+	checks     []string
+}
+
+func (f *NotificationRouteForm) Valid() bool {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" || !validNotificationTarget(f.Destination, &f.Target) {
+		return false
+	}
+	for _, s := range []model.Status{f.Severity, f.MaxSeverity} {
+		if s != model.UNKNOWN && s != model.WARNING && s != model.CRITICAL {
+			return false
+		}
+	}
+	if f.MaxSeverity != model.UNKNOWN && f.MaxSeverity < f.Severity {
+		return false
+	}
+	f.namespaces = strings.Fields(f.Namespaces)
+	f.teams = strings.Fields(f.Teams)
+	f.checks = strings.Fields(f.Checks)
+	return utils.GlobValidate(f.namespaces) && utils.GlobValidate(f.teams) && utils.GlobValidate(f.checks)
+}
+
+func (f *NotificationRouteForm) Get(id string) model.NotificationRoute {
+	return model.NotificationRoute{
+		Id:          id,
+		Name:        f.Name,
+		Namespaces:  f.namespaces,
+		Categories:  f.Categories,
+		Teams:       f.teams,
+		Severity:    f.Severity,
+		MaxSeverity: f.MaxSeverity,
+		Checks:      f.checks,
+		Destination: f.Destination,
+		Target:      f.Target,
+	}
+}
+
+type OnCallForm struct {
+	QuietHours model.QuietHours     `json:"quiet_hours"`
+	Schedule   model.OnCallSchedule `json:"schedule"`
+}
+
+func (f *OnCallForm) Valid() bool {
+	for i := range f.Schedule.Members {
+		m := &f.Schedule.Members[i]
+		m.Name = strings.TrimSpace(m.Name)
+		m.SlackChannel = strings.TrimSpace(m.SlackChannel)
+		m.Email = strings.TrimSpace(m.Email)
+		if m.Email != "" {
+			if _, err := mail.ParseAddressList(m.Email); err != nil {
+				return false
+			}
+		}
+	}
+	return f.QuietHours.Validate() == nil && f.Schedule.Validate() == nil
+}
+
+type NotificationLimitsForm struct {
+	model.NotificationLimits
+}
+
+func (f *NotificationLimitsForm) Valid() bool {
+	return f.Validate() == nil
+}
+
+type AcknowledgeIncidentForm struct {
+	By string `json:"by"`
+}
+
+func (f *AcknowledgeIncidentForm) Valid() bool {
+	f.By = strings.TrimSpace(f.By)
+	return true
+}
+
+type ChangeForm struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Url         string `json:"url"`
+	StartedAt   int64  `json:"started_at"`
+	FinishedAt  int64  `json:"finished_at"`
+}
+
+func (f *ChangeForm) Valid() bool {
+	f.Type = strings.TrimSpace(f.Type)
+	f.Description = strings.TrimSpace(f.Description)
+	if f.Type == "" || f.Description == "" {
+		return false
+	}
+	if f.Url != "" {
+		if u, err := url.Parse(f.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return false
+		}
+	}
+	return f.validTimestamps(timeseries.Now())
+}
+
+// validTimestamps checks that the change is not in the future and not older than model.ApplicationChangeMaxAge.
+// Zero values mean now.
+func (f *ChangeForm) validTimestamps(now timeseries.Time) bool {
+	if f.StartedAt < 0 || (f.FinishedAt != 0 && f.FinishedAt < f.StartedAt) {
+		return false
+	}
+	latest := now.Add(model.ApplicationChangeMaxClockSkew)
+	if f.StartedAt != 0 && (timeseries.Time(f.StartedAt).Before(now.Add(-model.ApplicationChangeMaxAge)) || timeseries.Time(f.StartedAt).After(latest)) {
+		return false
+	}
+	if timeseries.Time(f.FinishedAt).After(latest) {
+		return false
+	}
+	return true
+}
+
+func (f *ChangeForm) Get(appId model.ApplicationId, now timeseries.Time) *model.ApplicationDeployment {
+	d := &model.ApplicationDeployment{
+		ApplicationId: appId,
+		Name:          "change-" + utils.NanoId(8),
+		StartedAt:     timeseries.Time(f.StartedAt),
+		FinishedAt:    timeseries.Time(f.FinishedAt),
+		Details: &model.ApplicationDeploymentDetails{
+			Change: &model.ApplicationChange{Type: f.Type, Description: f.Description, Author: f.Author, Url: f.Url},
+		},
+	}
+	if d.StartedAt.IsZero() {
+		d.StartedAt = now
+	}
+	if d.FinishedAt.IsZero() {
+		d.FinishedAt = d.StartedAt
+	}
+	return d
+}
+
+type RepositoryForm struct {
+	Image string `json:"image"`
+	Url   string `json:"url"`
+	Token string `json:"token"`
+}
+
+func (f *RepositoryForm) Valid() bool {
+	f.Image = strings.TrimSpace(f.Image)
+	f.Url = strings.TrimSuffix(strings.TrimSpace(f.Url), "/")
+	if f.Image == "" || strings.Contains(strings.TrimSuffix(f.Image, "*"), "*") {
+		return false
+	}
+	if u, err := url.Parse(f.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return false
+	}
+	return true
+}
+
+func (f *RepositoryForm) Get(id string) model.Repository {
+	return model.Repository{
+		Id:    id,
+		Image: f.Image,
+		Url:   f.Url,
+		Token: f.Token,
+	}
+}
+
+type CheckAnnotationFields struct {
+	RunbookUrl  string `json:"runbook_url"`
+	Owner       string `json:"owner"`
+	Description string `json:"description"`
+}
+
+// CheckAnnotationForm contains the annotation of the check for all applications and for the given application.
+type CheckAnnotationForm struct {
+	Project     CheckAnnotationFields `json:"project"`
+	Application CheckAnnotationFields `json:"application"`
+}
+
+func (f *CheckAnnotationForm) Valid() bool {
+	for _, u := range []string{f.Project.RunbookUrl, f.Application.RunbookUrl} {
+		if u == "" {
+			continue
+		}
+		if parsed, err := url.Parse(u); err != nil || parsed.Host == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *CheckAnnotationForm) Get(appId model.ApplicationId, checkId model.CheckId) []model.CheckAnnotation {
+	return []model.CheckAnnotation{
+		{ApplicationId: model.ApplicationIdZero, CheckId: checkId, RunbookUrl: f.Project.RunbookUrl, Owner: f.Project.Owner, Description: f.Project.Description},
+		{ApplicationId: appId, CheckId: checkId, RunbookUrl: f.Application.RunbookUrl, Owner: f.Application.Owner, Description: f.Application.Description},
+	}
+}
+
+type ApplicationOwnerForm struct {
+	model.ApplicationOwner
+}
+
+func (f *ApplicationOwnerForm) Valid() bool {
+	f.Team = strings.TrimSpace(f.Team)
+	f.SlackChannel = strings.TrimSpace(f.SlackChannel)
+	f.Escalation = strings.TrimSpace(f.Escalation)
+	f.Email = strings.TrimSpace(f.Email)
+	if f.Email != "" {
+		if _, err := mail.ParseAddressList(f.Email); err != nil {
+			return false
+		}
+	}
+	return !strings.ContainsAny(f.SlackChannel, " \t")
+}
+
+func (f *ApplicationOwnerForm) Get() *model.ApplicationOwner {
+	if f.ApplicationOwner.IsEmpty() {
+		return nil
+	}
+	return &f.ApplicationOwner
+}
+
+type CheckConfigSLOAvailabilityForm struct {
+	Configs []model.CheckConfigSLOAvailability `json:"configs"`
+	Default bool                               `json:"default"`
+}
+
+func (f *CheckConfigSLOAvailabilityForm) Valid() bool {
+	for _, c := range f.Configs {
+		if c.Custom && (c.TotalRequestsQuery == "" || c.FailedRequestsQuery == "") {
+			return false
+		}
+	}
+	return true
+}
+
+type CheckConfigSLOLatencyForm struct {
+	Configs []model.CheckConfigSLOLatency `json:"configs"`
+	Default bool                          `json:"default"`
+}
+
+func (f *CheckConfigSLOLatencyForm) Valid() bool {
+	for _, c := range f.Configs {
+		if c.Custom && c.HistogramQuery == "" {
+			return false
+		}
+		if c.ObjectiveBucket <= 0 || c.ObjectivePercentage <= 0 || c.ObjectivePercentage > 100 {
+			return false
+		}
+	}
+	return true
+}
+
+type ApplicationCategoryForm struct {
+	Name           model.ApplicationCategory `json:"name"`
+	NewName        model.ApplicationCategory `json:"new_name"`
+	CustomPatterns string                    `json:"custom_patterns"`
+	customPatterns []string
+
+	NotifyOfDeployments bool `json:"notify_of_deployments"`
+}
+
+func (f *ApplicationCategoryForm) Valid() bool {
+	if !slugRe.MatchString(string(f.NewName)) {
+		return false
+	}
+	f.customPatterns = strings.Fields(f.CustomPatterns)
+	if !utils.GlobValidate(f.customPatterns) {
+		return false
+	}
+	for _, p := range f.customPatterns {
+		if strings.Count(p, "/") != 1 || strings.Index(p, "/") < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+type CustomCheckForm struct {
+	Title        string          `json:"title"`
+	Query        string          `json:"query"`
+	Threshold    float32         `json:"threshold"`
+	Unit         model.CheckUnit `json:"unit"`
+	Applications string          `json:"applications"`
+	applications []string
+}
+
+func (f *CustomCheckForm) Valid() bool {
+	f.Title = strings.TrimSpace(f.Title)
+	if f.Title == "" || f.Query == "" || !prom.IsQueryValid(f.Query) {
+		return false
+	}
+	if !f.Unit.Valid() || f.Unit.ValidateThreshold(f.Threshold) != nil {
+		return false
+	}
+	f.applications = strings.Fields(f.Applications)
+	if len(f.applications) == 0 || !utils.GlobValidate(f.applications) {
+		return false
+	}
+	for _, p := range f.applications {
+		if strings.Count(p, "/") != 1 || strings.Index(p, "/") < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *CustomCheckForm) Get(id string) model.CustomCheck {
+	return model.CustomCheck{
+		Id:           id,
+		Title:        f.Title,
+		Query:        f.Query,
+		Threshold:    f.Threshold,
+		Unit:         f.Unit,
+		Applications: f.applications,
+	}
+}
+
+type LogCheckForm struct {
+	Title        string  `json:"title"`
+	Regexp       string  `json:"regexp"`
+	Threshold    float32 `json:"threshold"`
+	Applications string  `json:"applications"`
+	applications []string
+}
+
+func (f *LogCheckForm) Valid() bool {
+	f.Title = strings.TrimSpace(f.Title)
+	if f.Title == "" || f.Regexp == "" || f.Threshold < 0 {
+		return false
+	}
+	if _, err := regexp.Compile(f.Regexp); err != nil {
+		return false
+	}
+	f.applications = strings.Fields(f.Applications)
+	if len(f.applications) == 0 || !utils.GlobValidate(f.applications) {
+		return false
+	}
+	for _, p := range f.applications {
+		if strings.Count(p, "/") != 1 || strings.Index(p, "/") < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *LogCheckForm) Get(id string) model.LogCheck {
+	return model.LogCheck{
+		Id:           id,
+		Title:        f.Title,
+		Regexp:       f.Regexp,
+		Threshold:    f.Threshold,
+		Applications: f.applications,
+	}
+}
+
+type CompositeCheckForm struct {
+	Title        string                  `json:"title"`
+	Operator     model.CompositeOperator `json:"operator"`
+	Count        int                     `json:"count"`
+	Checks       []model.CheckId         `json:"checks"`
+	Applications string                  `json:"applications"`
+	applications []string
+}
+
+func (f *CompositeCheckForm) Valid() bool {
+	f.Title = strings.TrimSpace(f.Title)
+	if f.Title == "" || len(f.Checks) == 0 {
+		return false
+	}
+	switch f.Operator {
+	case model.CompositeOperatorAnd, model.CompositeOperatorOr:
+	case model.CompositeOperatorAtLeast:
+		if f.Count < 1 || f.Count > len(f.Checks) {
+			return false
+		}
+	default:
+		return false
+	}
+	for _, id := range f.Checks {
+		if model.GetCheckConfig(id) == nil && !strings.HasPrefix(string(id), model.CustomCheckIdPrefix) {
+			return false
+		}
+	}
+	f.applications = strings.Fields(f.Applications)
+	if len(f.applications) == 0 || !utils.GlobValidate(f.applications) {
+		return false
+	}
+	for _, p := range f.applications {
+		if strings.Count(p, "/") != 1 || strings.Index(p, "/") < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *CompositeCheckForm) Get(id string) model.CompositeCheck {
+	return model.CompositeCheck{
+		Id:           id,
+		Title:        f.Title,
+		Operator:     f.Operator,
+		Count:        f.Count,
+		Checks:       f.Checks,
+		Applications: f.applications,
+	}
+}
+
+type CheckTemplateForm struct {
+	Name         string                                    `json:"name"`
+	Categories   []model.ApplicationCategory               `json:"categories"`
+	Applications string                                    `json:"applications"`
+	Checks       map[model.CheckId]model.CheckConfigSimple `json:"checks"`
+	applications []string
+}
+
+func (f *CheckTemplateForm) Valid() bool {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" || len(f.Checks) == 0 {
+		return false
+	}
+	for id := range f.Checks {
+		if cfg := model.GetCheckConfig(id); cfg == nil || cfg.Type == model.CheckTypeManual {
+			return false
+		}
+	}
+	f.applications = strings.Fields(f.Applications)
+	if len(f.applications) == 0 && len(f.Categories) == 0 {
+		return false
+	}
+	if !utils.GlobValidate(f.applications) {
+		return false
+	}
+	for _, p := range f.applications {
+		if strings.Count(p, "/") != 1 || strings.Index(p, "/") < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *CheckTemplateForm) Get(id string) model.CheckTemplate {
+	return model.CheckTemplate{
+		Id:           id,
+		Name:         f.Name,
+		Categories:   f.Categories,
+		Applications: f.applications,
+		Checks:       f.Checks,
+	}
+}
+
+type ApplicationSettingsPyroscopeForm struct {
+	db.ApplicationSettingsPyroscope
+}
+
+func (f *ApplicationSettingsPyroscopeForm) Valid() bool {
+	return true
+}
+
+type ApplicationSettingsTracingForm struct {
+	db.ApplicationSettingsTracing
+}
+
+func (f *ApplicationSettingsTracingForm) Valid() bool {
+	return true
+}
+
+type IntegrationsForm struct {
+	BaseUrl string `json:"base_url"`
+}
+
+func (f *IntegrationsForm) Valid() bool {
+	if _, err := url.Parse(f.BaseUrl); err != nil || f.BaseUrl == "" {
+		return false
+	}
+	f.BaseUrl = strings.TrimRight(f.BaseUrl, "/")
+	return true
+}
+
+type IntegrationForm interface {
+	Form
+	Get(project *db.Project, masked bool)
+	Update(ctx context.Context, project *db.Project, clear bool) error
+	Test(ctx context.Context, project *db.Project) error
+}
+
+func NewIntegrationForm(t db.IntegrationType) IntegrationForm {
+	switch t {
+	case db.IntegrationTypePrometheus:
+		return &IntegrationFormPrometheus{}
+	case db.IntegrationTypePyroscope:
+		return &IntegrationFormPyroscope{}
+	case db.IntegrationTypeClickhouse:
+		return &IntegrationFormClickhouse{}
+	case db.IntegrationTypeLoki:
+		return &IntegrationFormLoki{}
+	case db.IntegrationTypeElasticsearch:
+		return &IntegrationFormElasticsearch{}
+	case db.IntegrationTypeSlack:
+		return &IntegrationFormSlack{}
+	case db.IntegrationTypeMattermost:
+		return &IntegrationFormMattermost{}
+	case db.IntegrationTypeTeams:
+		return &IntegrationFormTeams{}
+	case db.IntegrationTypeTelegram:
+		return &IntegrationFormTelegram{}
+	case db.IntegrationTypeDiscord:
+		return &IntegrationFormDiscord{}
+	case db.IntegrationTypeEmail:
+		return &IntegrationFormEmail{}
+	case db.IntegrationTypePagerduty:
+		return &IntegrationFormPagerduty{}
+	case db.IntegrationTypeOpsgenie:
+		return &IntegrationFormOpsgenie{}
+	case db.IntegrationTypeTwilio:
+		return &IntegrationFormTwilio{}
+	case db.IntegrationTypeJira:
+		return &IntegrationFormJira{}
+	case db.IntegrationTypeServiceNow:
+		return &IntegrationFormServiceNow{}
+	case db.IntegrationTypeArgoCD:
+		return &IntegrationFormArgoCD{}
+	case db.IntegrationTypeAWS:
+		return &IntegrationFormAWS{}
+	case db.IntegrationTypeGCP:
+		return &IntegrationFormGCP{}
+	case db.IntegrationTypeAlertmanager:
+		return &IntegrationFormAlertmanager{}
+	}
+	return nil
+}
+
+type IntegrationFormPrometheus struct {
+	db.IntegrationsPrometheus
+}
+
+func (f *IntegrationFormPrometheus) Valid() bool {
+	if f.Embedded {
+		f.IntegrationsPrometheus = db.IntegrationsPrometheus{Embedded: true, RefreshInterval: f.RefreshInterval}
+		return true
+	}
+	if _, err := url.Parse(f.IntegrationsPrometheus.Url); err != nil {
+		return false
+	}
+	if !prom.IsSelectorValid(f.IntegrationsPrometheus.ExtraSelector) || !prom.IsBackendValid(f.Backend) {
+		return false
+	}
+	if f.SplitInterval < 0 || f.SplitInterval%timeseries.Hour != 0 {
+		return false
+	}
+	f.TenantId = strings.TrimSpace(f.TenantId)
+	var replicaLabels []string
+	for _, l := range f.ReplicaLabels {
+		if l = strings.TrimSpace(l); l == "" {
+			continue
+		}
+		if !promModel.LabelName(l).IsValid() {
+			return false
+		}
+		replicaLabels = append(replicaLabels, l)
+	}
+	f.ReplicaLabels = replicaLabels
+	var validHeaders []utils.Header
+	for _, h := range f.CustomHeaders {
+		if h.Valid() {
+			validHeaders = append(validHeaders, h)
+		}
+	}
+	f.CustomHeaders = validHeaders
+	return true
+}
+
+func (f *IntegrationFormPrometheus) Get(project *db.Project, masked bool) {
+	cfg := project.Prometheus
+	if !cfg.Configured() {
+		f.RefreshInterval = db.DefaultRefreshInterval
+		return
+	}
+	f.IntegrationsPrometheus = cfg
+	if masked && !cfg.Embedded {
+		f.Url = "http://<hidden>"
+		if f.BasicAuth != nil {
+			f.BasicAuth.User = "<user>"
+			f.BasicAuth.Password = "<password>"
+		}
+		for i := range f.CustomHeaders {
+			f.CustomHeaders[i].Value = "<header>"
+		}
+	}
+}
+
+func (f *IntegrationFormPrometheus) Update(ctx context.Context, project *db.Project, clear bool) error {
+	if err := f.Test(ctx, project); err != nil {
+		return err
+	}
+	project.Prometheus = f.IntegrationsPrometheus
+	prom.ResetBackendDetection()
+	return nil
+}
+
+func (f *IntegrationFormPrometheus) Test(ctx context.Context, project *db.Project) error {
+	if f.Embedded { // the agents push metrics to Coroot, so there's nothing to connect to
+		return nil
+	}
+	client, err := prom.NewApiClient(f.IntegrationsPrometheus.ClientConfig())
+	if err != nil {
+		return err
+	}
+	if err := client.Ping(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+type IntegrationFormPyroscope struct {
+	db.IntegrationPyroscope
+}
+
+func (f *IntegrationFormPyroscope) Valid() bool {
+	if _, err := url.Parse(f.Url); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormPyroscope) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Pyroscope
+	if cfg == nil {
+		return
+	}
+	f.IntegrationPyroscope = *cfg
+	if masked {
+		f.Url = "http://<hidden>"
+		if f.ApiKey != "" {
+			f.ApiKey = "<api_key>"
+		}
+		if f.BasicAuth != nil {
+			f.BasicAuth.User = "<user>"
+			f.BasicAuth.Password = "<password>"
+		}
+	}
+}
+
+func (f *IntegrationFormPyroscope) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationPyroscope
+	if clear {
+		cfg = nil
+	} else {
+		if err := f.Test(ctx, project); err != nil {
+			return err
+		}
+	}
+	project.Settings.Integrations.Pyroscope = cfg
+	return nil
+}
+
+func (f *IntegrationFormPyroscope) Test(ctx context.Context, project *db.Project) error {
+	client, err := profiling.NewPyroscope(f.Url, f.ApiKey, f.BasicAuth)
+	if err != nil {
+		return err
+	}
+	_, err = client.Metadata(ctx)
+	return err
+}
+
+type IntegrationFormLoki struct {
+	db.IntegrationLoki
+}
+
+func (f *IntegrationFormLoki) Valid() bool {
+	if u, err := url.Parse(f.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	if f.NamespaceLabel == "" {
+		f.NamespaceLabel = "namespace"
+	}
+	if f.InstanceLabel == "" {
+		f.InstanceLabel = "pod"
+	}
+	if !promModel.LabelName(f.NamespaceLabel).IsValid() || !promModel.LabelName(f.InstanceLabel).IsValid() {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormLoki) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Loki
+	if cfg == nil {
+		f.NamespaceLabel = "namespace"
+		f.InstanceLabel = "pod"
+		return
+	}
+	f.IntegrationLoki = *cfg
+	if masked {
+		f.Url = "http://<hidden>"
+		if f.TenantId != "" {
+			f.TenantId = "<tenant_id>"
+		}
+		if f.BasicAuth != nil {
+			f.BasicAuth.User = "<user>"
+			f.BasicAuth.Password = "<password>"
+		}
+	}
+}
+
+func (f *IntegrationFormLoki) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationLoki
+	if clear {
+		cfg = nil
+	} else {
+		if err := f.Test(ctx, project); err != nil {
+			return err
+		}
+	}
+	project.Settings.Integrations.Loki = cfg
+	return nil
+}
+
+func (f *IntegrationFormLoki) Test(ctx context.Context, project *db.Project) error {
+	client, err := logs.NewLoki(f.Url, f.TlsSkipVerify, f.BasicAuth, f.TenantId)
+	if err != nil {
+		return err
+	}
+	_, err = client.Labels(ctx)
+	return err
+}
+
+type IntegrationFormElasticsearch struct {
+	db.IntegrationElasticsearch
+}
+
+func (f *IntegrationFormElasticsearch) Valid() bool {
+	if u, err := url.Parse(f.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	f.ApiKey = strings.TrimSpace(f.ApiKey)
+	defaults := elasticsearchDefaults()
+	for _, field := range []struct {
+		v *string
+		d string
+	}{
+		{&f.Index, defaults.Index},
+		{&f.TimestampField, defaults.TimestampField},
+		{&f.MessageField, defaults.MessageField},
+		{&f.NamespaceField, defaults.NamespaceField},
+		{&f.InstanceField, defaults.InstanceField},
+	} {
+		if *field.v = strings.TrimSpace(*field.v); *field.v == "" {
+			*field.v = field.d
+		}
+	}
+	return true
+}
+
+func (f *IntegrationFormElasticsearch) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Elasticsearch
+	if cfg == nil {
+		f.IntegrationElasticsearch = elasticsearchDefaults()
+		return
+	}
+	f.IntegrationElasticsearch = *cfg
+	if masked {
+		f.Url = "http://<hidden>"
+		if f.ApiKey != "" {
+			f.ApiKey = "<api_key>"
+		}
+		if f.BasicAuth != nil {
+			f.BasicAuth.User = "<user>"
+			f.BasicAuth.Password = "<password>"
+		}
+	}
+}
+
+func (f *IntegrationFormElasticsearch) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationElasticsearch
+	if clear {
+		cfg = nil
+	} else {
+		if err := f.Test(ctx, project); err != nil {
+			return err
+		}
+	}
+	project.Settings.Integrations.Elasticsearch = cfg
+	return nil
+}
+
+func (f *IntegrationFormElasticsearch) Test(ctx context.Context, project *db.Project) error {
+	client, err := logs.NewElasticsearch(f.Url, f.TlsSkipVerify, f.BasicAuth, f.ApiKey)
+	if err != nil {
+		return err
+	}
+	_, err = client.Count(ctx, f.Index)
+	return err
+}
+
+// elasticsearchDefaults returns the field names of the documents shipped by Filebeat.
+func elasticsearchDefaults() db.IntegrationElasticsearch {
+	return db.IntegrationElasticsearch{
+		Index:          "filebeat-*",
+		TimestampField: "@timestamp",
+		MessageField:   "message",
+		NamespaceField: "kubernetes.namespace",
+		InstanceField:  "kubernetes.pod.name",
+	}
+}
+
+type IntegrationFormArgoCD struct {
+	db.IntegrationArgoCD
+}
+
+func (f *IntegrationFormArgoCD) Valid() bool {
+	if u, err := url.Parse(f.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	return f.Token != ""
+}
+
+func (f *IntegrationFormArgoCD) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.ArgoCD
+	if cfg == nil {
+		return
+	}
+	f.IntegrationArgoCD = *cfg
+	if masked {
+		f.Url = "http://<hidden>"
+		f.Token = "<token>"
+	}
+}
+
+func (f *IntegrationFormArgoCD) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationArgoCD
+	if clear {
+		cfg = nil
+	} else {
+		if err := f.Test(ctx, project); err != nil {
+			return err
+		}
+	}
+	project.Settings.Integrations.ArgoCD = cfg
+	return nil
+}
+
+func (f *IntegrationFormArgoCD) Test(ctx context.Context, project *db.Project) error {
+	_, err := argocd.NewClient(f.Url, f.Token, f.TlsSkipVerify).Applications(ctx)
+	return err
+}
+
+type IntegrationFormAWS struct {
+	db.IntegrationAWS
+}
+
+func (f *IntegrationFormAWS) Valid() bool {
+	if f.Region == "" {
+		return false
+	}
+	if f.AccessKeyId != "" && f.SecretAccessKey == "" {
+		return false
+	}
+	return utils.GlobValidate(f.RdsInstances)
+}
+
+func (f *IntegrationFormAWS) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.AWS
+	if cfg == nil {
+		return
+	}
+	f.IntegrationAWS = *cfg
+	if masked {
+		f.AccessKeyId = "<hidden>"
+		f.SecretAccessKey = "<secret>"
+		f.RoleArn = "<hidden>"
+	}
+}
+
+func (f *IntegrationFormAWS) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationAWS
+	if clear {
+		cfg = nil
+	} else {
+		if err := f.Test(ctx, project); err != nil {
+			return err
+		}
+	}
+	project.Settings.Integrations.AWS = cfg
+	return nil
+}
+
+func (f *IntegrationFormAWS) Test(ctx context.Context, project *db.Project) error {
+	if !project.Prometheus.Embedded {
+		return errors.New("the metrics of AWS resources are stored in the embedded TSDB, but the project isn't configured to use it")
 	}
-	return nil
+	client, err := aws.NewClient(f.ClientConfig())
+	if err != nil {
+		return err
+	}
+	if _, err = client.Instances(ctx, f.RdsInstances); err != nil {
+		return err
+	}
+	if f.ApplicationTag != "" {
+		_, err = client.Resources(ctx, f.ApplicationTag)
+	}
+	return err
 }
 
-type IntegrationFormPrometheus struct {
-	db.IntegrationsPrometheus
+type IntegrationFormGCP struct {
+	db.IntegrationGCP
 }
 
-func (f *IntegrationFormPrometheus) Valid() bool {
-	if _, err := url.Parse(f.IntegrationsPrometheus.Url); err != nil {
-		return false
-	}
-	if !prom.IsSelectorValid(f.IntegrationsPrometheus.ExtraSelector) {
+func (f *IntegrationFormGCP) Valid() bool {
+	if f.ProjectId == "" {
 		return false
 	}
-	var validHeaders []utils.Header
-	for _, h := range f.CustomHeaders {
-		if h.Valid() {
-			validHeaders = append(validHeaders, h)
-		}
-	}
-	f.CustomHeaders = validHeaders
-	return true
+	return utils.GlobValidate(f.CloudSqlInstances) && utils.GlobValidate(f.MemorystoreInstances)
 }
 
-func (f *IntegrationFormPrometheus) Get(project *db.Project, masked bool) {
-	cfg := project.Prometheus
-	if cfg.Url == "" {
-		f.RefreshInterval = db.DefaultRefreshInterval
+func (f *IntegrationFormGCP) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.GCP
+	if cfg == nil {
 		return
 	}
-	f.IntegrationsPrometheus = cfg
+	f.IntegrationGCP = *cfg
 	if masked {
-		f.Url = "http://<hidden>"
-		if f.BasicAuth != nil {
-			f.BasicAuth.User = "<user>"
-			f.BasicAuth.Password = "<password>"
-		}
-		for i := range f.CustomHeaders {
-			f.CustomHeaders[i].Value = "<header>"
-		}
+		f.ServiceAccountKey = "<secret>"
 	}
 }
 
-func (f *IntegrationFormPrometheus) Update(ctx context.Context, project *db.Project, clear bool) error {
-	if err := f.Test(ctx, project); err != nil {
-		return err
+func (f *IntegrationFormGCP) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationGCP
+	if clear {
+		cfg = nil
+	} else {
+		if err := f.Test(ctx, project); err != nil {
+			return err
+		}
 	}
-	project.Prometheus = f.IntegrationsPrometheus
+	project.Settings.Integrations.GCP = cfg
 	return nil
 }
 
-func (f *IntegrationFormPrometheus) Test(ctx context.Context, project *db.Project) error {
-	client, err := prom.NewApiClient(f.Url, f.BasicAuth, f.TlsSkipVerify, f.ExtraSelector, f.CustomHeaders)
+func (f *IntegrationFormGCP) Test(ctx context.Context, project *db.Project) error {
+	if !project.Prometheus.Embedded {
+		return errors.New("the metrics of Cloud SQL and Memorystore instances are stored in the embedded TSDB, but the project isn't configured to use it")
+	}
+	client, err := gcp.NewClient(f.ClientConfig())
 	if err != nil {
 		return err
 	}
-	if err := client.Ping(ctx); err != nil {
+	if _, err = client.CloudSqlInstances(ctx, f.CloudSqlInstances); err != nil {
 		return err
 	}
-	return nil
+	_, err = client.MemorystoreInstances(ctx, f.MemorystoreInstances)
+	return err
 }
 
-type IntegrationFormPyroscope struct {
-	db.IntegrationPyroscope
+type IntegrationFormAlertmanager struct {
+	db.IntegrationAlertmanager
 }
 
-func (f *IntegrationFormPyroscope) Valid() bool {
-	if _, err := url.Parse(f.Url); err != nil {
+func (f *IntegrationFormAlertmanager) Valid() bool {
+	if u, err := url.Parse(f.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
 		return false
 	}
+	if f.BasicAuth != nil && f.BasicAuth.User == "" {
+		f.BasicAuth = nil
+	}
 	return true
 }
 
-func (f *IntegrationFormPyroscope) Get(project *db.Project, masked bool) {
-	cfg := project.Settings.Integrations.Pyroscope
+func (f *IntegrationFormAlertmanager) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Alertmanager
 	if cfg == nil {
 		return
 	}
-	f.IntegrationPyroscope = *cfg
+	f.IntegrationAlertmanager = *cfg
 	if masked {
 		f.Url = "http://<hidden>"
-		if f.ApiKey != "" {
-			f.ApiKey = "<api_key>"
-		}
 		if f.BasicAuth != nil {
 			f.BasicAuth.User = "<user>"
 			f.BasicAuth.Password = "<password>"
@@ -261,8 +1595,8 @@ func (f *IntegrationFormPyroscope) Get(project *db.Project, masked bool) {
 	}
 }
 
-func (f *IntegrationFormPyroscope) Update(ctx context.Context, project *db.Project, clear bool) error {
-	cfg := &f.IntegrationPyroscope
+func (f *IntegrationFormAlertmanager) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationAlertmanager
 	if clear {
 		cfg = nil
 	} else {
@@ -270,17 +1604,13 @@ func (f *IntegrationFormPyroscope) Update(ctx context.Context, project *db.Proje
 			return err
 		}
 	}
-	project.Settings.Integrations.Pyroscope = cfg
+	project.Settings.Integrations.Alertmanager = cfg
 	return nil
 }
 
-func (f *IntegrationFormPyroscope) Test(ctx context.Context, project *db.Project) error {
-	client, err := profiling.NewPyroscope(f.Url, f.ApiKey, f.BasicAuth)
-	if err != nil {
-		return err
-	}
-	_, err = client.Metadata(ctx)
-	return err
+// Test sends an empty list of alerts to check that Alertmanager is reachable.
+func (f *IntegrationFormAlertmanager) Test(ctx context.Context, project *db.Project) error {
+	return notifications.SendAlertmanagerAlerts(ctx, f.IntegrationAlertmanager, []*notifications.AlertmanagerAlert{})
 }
 
 type IntegrationFormClickhouse struct {
@@ -291,6 +1621,9 @@ func (f *IntegrationFormClickhouse) Valid() bool {
 	if _, _, err := net.SplitHostPort(f.Addr); err != nil {
 		return false
 	}
+	if f.IngestTTL < 0 || f.LogsTTL < 0 || f.QueryStatsTTL < 0 {
+		return false
+	}
 	return true
 }
 
@@ -323,6 +1656,24 @@ func (f *IntegrationFormClickhouse) Update(ctx context.Context, project *db.Proj
 		if err := f.Test(ctx, project); err != nil {
 			return err
 		}
+		if cfg.Ingest {
+			client, err := tracing.NewClickhouseClient(f.Protocol, f.Addr, f.TlsEnable, f.TlsSkipVerify, f.Auth, f.Database, f.TracesTable)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			if err = client.CreateTables(ctx, cfg.IngestTTL.ToStandard()); err != nil {
+				return fmt.Errorf("failed to create the tables: %w", err)
+			}
+			store, err := clickhouse.NewClient(cfg.ClientConfig())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			if err = store.CreateTables(ctx, cfg.Retention()); err != nil {
+				return fmt.Errorf("failed to create the tables: %w", err)
+			}
+		}
 	}
 	project.Settings.Integrations.Clickhouse = cfg
 	return nil
@@ -337,6 +1688,9 @@ func (f *IntegrationFormClickhouse) Test(ctx context.Context, project *db.Projec
 	if err != nil {
 		return err
 	}
+	if f.Ingest { // the tables are created upon saving
+		return nil
+	}
 	_, err = client.GetServiceNames(ctx)
 	return err
 }
@@ -362,6 +1716,9 @@ func (f *IntegrationFormSlack) Get(project *db.Project, masked bool) {
 	f.IntegrationSlack = *cfg
 	if masked {
 		f.Token = "<token>"
+		if f.SigningSecret != "" {
+			f.SigningSecret = "<signing_secret>"
+		}
 	}
 }
 
@@ -378,6 +1735,44 @@ func (f *IntegrationFormSlack) Test(ctx context.Context, project *db.Project) er
 	return notifications.NewSlack(f.Token, f.DefaultChannel).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testNotification(project))
 }
 
+type IntegrationFormMattermost struct {
+	db.IntegrationMattermost
+}
+
+func (f *IntegrationFormMattermost) Valid() bool {
+	f.Channel = strings.TrimSpace(f.Channel)
+	if f.WebhookUrl == "" {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormMattermost) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Mattermost
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		return
+	}
+	f.IntegrationMattermost = *cfg
+	if masked {
+		f.WebhookUrl = "<webhook_url>"
+	}
+}
+
+func (f *IntegrationFormMattermost) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationMattermost
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Mattermost = cfg
+	return nil
+}
+
+func (f *IntegrationFormMattermost) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewMattermost(f.WebhookUrl, f.Channel).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testNotification(project))
+}
+
 type IntegrationFormTeams struct {
 	db.IntegrationTeams
 }
@@ -415,6 +1810,127 @@ func (f *IntegrationFormTeams) Test(ctx context.Context, project *db.Project) er
 	return notifications.NewTeams(f.WebhookUrl).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testNotification(project))
 }
 
+type IntegrationFormTelegram struct {
+	db.IntegrationTelegram
+}
+
+func (f *IntegrationFormTelegram) Valid() bool {
+	if f.BotToken == "" || f.ChatId == "" {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormTelegram) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Telegram
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		return
+	}
+	f.IntegrationTelegram = *cfg
+	if masked {
+		f.BotToken = "<bot_token>"
+	}
+}
+
+func (f *IntegrationFormTelegram) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationTelegram
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Telegram = cfg
+	return nil
+}
+
+func (f *IntegrationFormTelegram) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewTelegram(f.BotToken, f.ChatId).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testNotification(project))
+}
+
+type IntegrationFormDiscord struct {
+	db.IntegrationDiscord
+}
+
+func (f *IntegrationFormDiscord) Valid() bool {
+	if f.WebhookUrl == "" {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormDiscord) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Discord
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		return
+	}
+	f.IntegrationDiscord = *cfg
+	if masked {
+		f.WebhookUrl = "<webhook_url>"
+	}
+}
+
+func (f *IntegrationFormDiscord) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationDiscord
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Discord = cfg
+	return nil
+}
+
+func (f *IntegrationFormDiscord) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewDiscord(f.WebhookUrl).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testNotification(project))
+}
+
+type IntegrationFormEmail struct {
+	db.IntegrationEmail
+}
+
+func (f *IntegrationFormEmail) Valid() bool {
+	f.Host = strings.TrimSpace(f.Host)
+	f.From = strings.TrimSpace(f.From)
+	f.To = strings.TrimSpace(f.To)
+	if f.Host == "" || f.Port <= 0 || f.Port > 65535 {
+		return false
+	}
+	if _, err := mail.ParseAddress(f.From); err != nil {
+		return false
+	}
+	if _, err := mail.ParseAddressList(f.To); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormEmail) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Email
+	if cfg == nil {
+		f.Port = 587
+		f.Incidents = true
+		f.Deployments = true
+		return
+	}
+	f.IntegrationEmail = *cfg
+	if masked {
+		f.Password = "<password>"
+	}
+}
+
+func (f *IntegrationFormEmail) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationEmail
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Email = cfg
+	return nil
+}
+
+func (f *IntegrationFormEmail) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewEmail(f.IntegrationEmail).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testNotification(project))
+}
+
 type IntegrationFormPagerduty struct {
 	db.IntegrationPagerduty
 }
@@ -487,6 +2003,140 @@ func (f *IntegrationFormOpsgenie) Test(ctx context.Context, project *db.Project)
 	return notifications.NewOpsgenie(f.ApiKey, f.EUInstance).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testNotification(project))
 }
 
+type IntegrationFormTwilio struct {
+	db.IntegrationTwilio
+}
+
+func (f *IntegrationFormTwilio) Valid() bool {
+	f.From = strings.TrimSpace(f.From)
+	f.To = strings.TrimSpace(f.To)
+	if f.AccountSid == "" || f.AuthToken == "" || f.From == "" || f.RateLimit < 1 {
+		return false
+	}
+	from := model.ParsePhones(f.From)
+	if len(from) != 1 || model.ValidatePhones(f.From) != nil || model.ValidatePhones(f.To) != nil {
+		return false
+	}
+	f.From = from[0]
+	return true
+}
+
+func (f *IntegrationFormTwilio) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Twilio
+	if cfg == nil {
+		f.RateLimit = 5
+		f.Incidents = true
+		return
+	}
+	f.IntegrationTwilio = *cfg
+	if masked {
+		f.AuthToken = "<auth_token>"
+	}
+}
+
+func (f *IntegrationFormTwilio) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationTwilio
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Twilio = cfg
+	return nil
+}
+
+func (f *IntegrationFormTwilio) Test(ctx context.Context, project *db.Project) error {
+	n := testNotification(project)
+	n.Status = model.CRITICAL // only critical incidents are sent
+	return notifications.NewTwilio(f.IntegrationTwilio).SendIncident(ctx, project.Settings.Integrations.BaseUrl, n)
+}
+
+type IntegrationFormJira struct {
+	db.IntegrationJira
+}
+
+func (f *IntegrationFormJira) Valid() bool {
+	if u, err := url.Parse(f.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	f.ProjectKey = strings.TrimSpace(f.ProjectKey)
+	f.IssueType = strings.TrimSpace(f.IssueType)
+	if f.Email == "" || f.ApiToken == "" || f.ProjectKey == "" {
+		return false
+	}
+	return validTicketThreshold(f.Threshold)
+}
+
+func (f *IntegrationFormJira) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Jira
+	if cfg == nil {
+		f.IssueType = "Task"
+		f.Threshold = 15
+		f.Incidents = true
+		return
+	}
+	f.IntegrationJira = *cfg
+	if masked {
+		f.ApiToken = "<api_token>"
+	}
+}
+
+func (f *IntegrationFormJira) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationJira
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Jira = cfg
+	return nil
+}
+
+func (f *IntegrationFormJira) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewJira(f.IntegrationJira).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testNotification(project))
+}
+
+type IntegrationFormServiceNow struct {
+	db.IntegrationServiceNow
+}
+
+func (f *IntegrationFormServiceNow) Valid() bool {
+	if u, err := url.Parse(f.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	if f.Username == "" || f.Password == "" {
+		return false
+	}
+	return validTicketThreshold(f.Threshold)
+}
+
+func (f *IntegrationFormServiceNow) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.ServiceNow
+	if cfg == nil {
+		f.Threshold = 15
+		f.Incidents = true
+		return
+	}
+	f.IntegrationServiceNow = *cfg
+	if masked {
+		f.Password = "<password>"
+	}
+}
+
+func (f *IntegrationFormServiceNow) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationServiceNow
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.ServiceNow = cfg
+	return nil
+}
+
+func (f *IntegrationFormServiceNow) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewServiceNow(f.IntegrationServiceNow).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testNotification(project))
+}
+
+// validTicketThreshold checks that an incident must stay critical for 1 minute to 1 day before a ticket is opened.
+func validTicketThreshold(minutes int) bool {
+	return minutes >= 1 && minutes <= 24*60
+}
+
 func testNotification(project *db.Project) *db.IncidentNotification {
 	return &db.IncidentNotification{
 		ProjectId:     project.Id,
@@ -495,7 +2145,7 @@ func testNotification(project *db.Project) *db.IncidentNotification {
 		Status:        model.INFO,
 		Details: &db.IncidentNotificationDetails{
 			Reports: []db.IncidentNotificationDetailsReport{
-				{Name: model.AuditReportSLO, Check: model.Checks.SLOLatency.Title, Message: "error budget burn rate is 20x within 1 hour"},
+				{Name: model.AuditReportSLO, Check: model.Checks.SLOLatency.Title, Status: model.CRITICAL, Message: "error budget burn rate is 20x within 1 hour"},
 				{Name: model.AuditReportNetwork, Check: model.Checks.NetworkRTT.Title, Message: "high network latency to 2 upstream services"},
 			},
 		},
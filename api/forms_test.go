@@ -0,0 +1,66 @@
+package api
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChangeFormTimestamps(t *testing.T) {
+	now := timeseries.Time(1700000000)
+	for _, c := range []struct {
+		name       string
+		startedAt  timeseries.Time
+		finishedAt timeseries.Time
+		valid      bool
+	}{
+		{name: "now", valid: true},
+		{name: "recent", startedAt: now.Add(-timeseries.Hour), finishedAt: now, valid: true},
+		{name: "clock skew", startedAt: now.Add(timeseries.Minute), valid: true},
+		{name: "in the future", startedAt: now.Add(timeseries.Hour), valid: false},
+		{name: "finished in the future", startedAt: now, finishedAt: now.Add(timeseries.Hour), valid: false},
+		{name: "too old", startedAt: now.Add(-model.ApplicationChangeMaxAge - timeseries.Hour), valid: false},
+		{name: "finished before started", startedAt: now, finishedAt: now.Add(-timeseries.Minute), valid: false},
+		{name: "negative", startedAt: -1, valid: false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			f := ChangeForm{Type: "feature_flag", Description: "enable checkout v2", StartedAt: int64(c.startedAt), FinishedAt: int64(c.finishedAt)}
+			assert.Equal(t, c.valid, f.validTimestamps(now))
+		})
+	}
+}
+
+func TestChangeFormGet(t *testing.T) {
+	appId := model.NewApplicationId("default", model.ApplicationKindDeployment, "cart")
+	now := timeseries.Time(1700000000)
+
+	f := ChangeForm{Type: "migration", Description: "add index"}
+	d := f.Get(appId, now)
+	assert.Equal(t, now, d.StartedAt)
+	assert.Equal(t, now, d.FinishedAt)
+	assert.Equal(t, &model.ApplicationChange{Type: "migration", Description: "add index"}, d.Details.Change)
+
+	f = ChangeForm{Type: "migration", Description: "add index", StartedAt: int64(now.Add(-timeseries.Hour))}
+	d = f.Get(appId, now)
+	assert.Equal(t, now.Add(-timeseries.Hour), d.StartedAt)
+	assert.Equal(t, d.StartedAt, d.FinishedAt)
+}
+
+func TestCheckOverrideForm(t *testing.T) {
+	appId := model.NewApplicationId("default", model.ApplicationKindDeployment, "cart")
+	th := func(v float32) *float32 { return &v }
+
+	f := CheckOverrideForm{ApplicationId: &appId, Threshold: th(500), Unit: model.CheckUnitMillisecond, unit: model.CheckUnitSecond}
+	assert.True(t, f.Valid())
+	assert.Equal(t, float32(0.5), *f.Threshold)
+
+	f = CheckOverrideForm{Namespace: "default", Threshold: th(-1), unit: model.CheckUnitPercent}
+	assert.False(t, f.Valid())
+
+	f = CheckOverrideForm{Namespace: "default", Threshold: th(1), Unit: model.CheckUnitByte, unit: model.CheckUnitSecond}
+	assert.False(t, f.Valid())
+
+	f = CheckOverrideForm{Namespace: "default", unit: model.CheckUnitPercent}
+	assert.True(t, f.Valid())
+}
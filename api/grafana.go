@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/api/views"
+	"github.com/coroot/coroot/auditor"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+	"net/http"
+	"net/url"
+)
+
+// grafanaMaxRange caps the time range of the datasource queries, since every query loads and audits a world.
+const grafanaMaxRange = 7 * timeseries.Day
+
+// Grafana implements a Grafana JSON datasource, the URL of the datasource is /api/project/<project>/grafana.
+// The root path is used by Grafana to test the datasource, /search and /metrics return the targets, and /query returns the series.
+func (api *Api) Grafana(w http.ResponseWriter, r *http.Request) {
+	var search GrafanaSearchForm
+	var query GrafanaQueryForm
+	var form Form
+	method := mux.Vars(r)["method"]
+	switch method {
+	case "":
+		w.WriteHeader(http.StatusOK)
+		return
+	case "search", "metrics":
+		form = &search
+	case "query":
+		form = &query
+	default:
+		http.Error(w, "Unknown method", http.StatusNotFound)
+		return
+	}
+	if err := ReadAndValidate(r, form); err != nil {
+		klog.Warningln("bad request:", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	project, from, to, err := api.getProjectAndRange(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+	if method == "query" {
+		from, to = timeseries.Time(query.Range.From.Unix()), timeseries.Time(query.Range.To.Unix())
+	}
+	if to.Sub(from) > grafanaMaxRange {
+		from = to.Add(-grafanaMaxRange)
+	}
+	rng, err := api.getWorldRange(project, from, to)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if rng == nil {
+		http.Error(w, "No data", http.StatusNotFound)
+		return
+	}
+	// Grafana refreshes the dashboards periodically, so the responses are cached the same way the application reports are
+	params, err := json.Marshal(form)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	cacheKey := api.reports.key(project.Id, model.ApplicationId{}, rng, url.Values{"grafana": {method, string(params)}})
+	if body := api.reports.get(cacheKey); body != nil {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+		return
+	}
+	world, err := api.loadWorldInRange(r.Context(), project, rng)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if world == nil {
+		http.Error(w, "No data", http.StatusNotFound)
+		return
+	}
+	auditor.Audit(world, project)
+
+	var res any
+	switch method {
+	case "search":
+		res = views.GrafanaTargets(world, search.Target)
+	case "metrics":
+		res = views.GrafanaMetrics(world, search.Metric)
+	case "query":
+		res = views.GrafanaQuery(world, query.Targets)
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	api.reports.put(cacheKey, body)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/utils"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+	"net/http"
+	"strings"
+)
+
+const (
+	ingestionKeyHeader = "X-API-Key"
+
+	// maxWebhookEventSize limits the size of the events pushed by CI systems and GitOps tools.
+	maxWebhookEventSize = 5 << 20
+)
+
+var (
+	errInvalidIngestionKey = errors.New("invalid ingestion key")
+	errInvalidSignature    = errors.New("invalid signature")
+)
+
+// IngestionKey returns the key the agents and the OpenTelemetry exporters must push the data to the project with (GET),
+// or replaces it with a new one (DELETE).
+func (api *Api) IngestionKey(w http.ResponseWriter, r *http.Request) {
+	if api.readOnly {
+		return
+	}
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	if r.Method == http.MethodDelete {
+		if err := api.db.ResetIngestionKey(projectId); err != nil {
+			klog.Errorln("failed to reset ingestion key:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	key, err := api.db.GetIngestionKey(projectId)
+	if err != nil {
+		klog.Errorln("failed to get ingestion key:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJson(w, struct {
+		Key    string `json:"key"`
+		Header string `json:"header"`
+	}{Key: key, Header: ingestionKeyHeader})
+}
+
+// checkIngestionKey reports whether the request carries the ingestion key of the project
+// in the X-API-Key header or as a bearer token (e.g., the authorization of the Prometheus remote-write config).
+func checkIngestionKey(r *http.Request, project *db.Project) error {
+	key := r.Header.Get(ingestionKeyHeader)
+	if key == "" {
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			key = strings.TrimPrefix(auth, "Bearer ")
+		}
+	}
+	expected := project.Settings.IngestionKey
+	if expected == "" || subtle.ConstantTimeCompare([]byte(key), []byte(expected)) != 1 {
+		return errInvalidIngestionKey
+	}
+	return nil
+}
+
+// checkIngestionSignature reports whether the header of the request contains the signature of the payload
+// in the sha256=<hex> format (as GitHub webhooks and the generic-hmac provider of Flux send it)
+// calculated with HMAC-SHA256 keyed with the ingestion key of the project.
+func checkIngestionSignature(r *http.Request, header string, payload []byte, project *db.Project) error {
+	key := project.Settings.IngestionKey
+	if key == "" {
+		return errInvalidIngestionKey
+	}
+	value := r.Header.Get(header)
+	if !strings.HasPrefix(value, "sha256=") {
+		return errInvalidSignature
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(value, "sha256="))
+	if err != nil {
+		return errInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// checkFluxEvent authenticates an event of the Flux notification-controller: the generic-hmac provider signs
+// the payload and sends the signature in the X-Signature header, the generic one can send the ingestion key
+// in the headers configured in the secret of the provider.
+func checkFluxEvent(r *http.Request, payload []byte, project *db.Project) error {
+	if r.Header.Get("X-Signature") != "" {
+		return checkIngestionSignature(r, "X-Signature", payload, project)
+	}
+	return checkIngestionKey(r, project)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"github.com/coroot/coroot/db"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckIngestionKey(t *testing.T) {
+	p := &db.Project{Id: "p"}
+	check := func(header, value string) error {
+		r := httptest.NewRequest("POST", "/api/project/p/remote_write", nil)
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		return checkIngestionKey(r, p)
+	}
+
+	assert.Error(t, check("", ""), "the key hasn't been created yet")
+	assert.Error(t, check("X-API-Key", ""))
+
+	p.Settings.IngestionKey = "key"
+	assert.NoError(t, check("X-API-Key", "key"))
+	assert.NoError(t, check("Authorization", "Bearer key"))
+	assert.Error(t, check("", ""))
+	assert.Error(t, check("X-API-Key", "other"))
+	assert.Error(t, check("Authorization", "Basic key"))
+}
+
+func TestCheckIngestionSignature(t *testing.T) {
+	p := &db.Project{Id: "p"}
+	payload := []byte(`{"action":"completed"}`)
+	check := func(signature string) error {
+		r := httptest.NewRequest("POST", "/api/v1/project/p/ci/github", nil)
+		if signature != "" {
+			r.Header.Set("X-Hub-Signature-256", signature)
+		}
+		return checkIngestionSignature(r, "X-Hub-Signature-256", payload, p)
+	}
+	// echo -n '{"action":"completed"}' | openssl dgst -sha256 -hmac key
+	const valid = "sha256=4448bc3c892c2ba2a146cc624ffb50b7b0c35fb442eb2a13d21f023d1f97de97"
+
+	assert.Error(t, check(valid), "the key hasn't been created yet")
+
+	p.Settings.IngestionKey = "key"
+	assert.NoError(t, check(valid))
+	assert.Error(t, check(""))
+	assert.Error(t, check("sha256="))
+	assert.Error(t, check("sha256=zz"))
+	assert.Error(t, check(valid[len("sha256="):]), "the prefix is required")
+	assert.Error(t, check("sha1="+valid[len("sha256="):]))
+	payload = []byte(`{"action":"requested"}`)
+	assert.Error(t, check(valid), "the payload has been modified")
+}
+
+func TestCheckFluxEvent(t *testing.T) {
+	p := &db.Project{Id: "p"}
+	p.Settings.IngestionKey = "key"
+	payload := []byte(`{"severity":"info"}`)
+	check := func(headers map[string]string) error {
+		r := httptest.NewRequest("POST", "/api/v1/project/p/flux", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		return checkFluxEvent(r, payload, p)
+	}
+	// echo -n '{"severity":"info"}' | openssl dgst -sha256 -hmac key
+	const signature = "sha256=8621d047a8488fa0b299211e8b5e7d2a426849f0ed076650d6d1d984732e44cb"
+
+	assert.NoError(t, check(map[string]string{"X-Signature": signature}))
+	assert.NoError(t, check(map[string]string{"X-API-Key": "key"}))
+	assert.Error(t, check(nil))
+	assert.Error(t, check(map[string]string{"X-Signature": "sha256=00"}))
+	assert.Error(t, check(map[string]string{"X-Signature": "sha256=00", "X-API-Key": "key"}), "an invalid signature must not be ignored")
+	assert.Error(t, check(map[string]string{"X-API-Key": "other"}))
+}
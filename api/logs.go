@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"github.com/coroot/coroot/clickhouse"
+	"github.com/coroot/coroot/db"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+	"net/http"
+)
+
+// OtlpLogs implements the OTLP/HTTP logs receiver (binary protobuf encoding).
+// An OTLP exporter should be pointed at /api/project/<project> as it appends /v1/logs to the endpoint.
+func (api *Api) OtlpLogs(w http.ResponseWriter, r *http.Request) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			klog.Warningln("project not found:", projectId)
+			http.Error(w, "Project not found.", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if err = checkIngestionKey(r, project); err != nil {
+		klog.Warningf("%s: OTLP request rejected: %s", projectId, err)
+		http.Error(w, "Invalid ingestion key.", http.StatusUnauthorized)
+		return
+	}
+	cfg := project.Settings.Integrations.Clickhouse
+	if cfg == nil || !cfg.Ingest {
+		http.Error(w, "Log ingestion isn't enabled for the project.", http.StatusNotFound)
+		return
+	}
+	data, status, err := readOtlpRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	records, err := clickhouse.DecodeOtlpLogs(data)
+	if err != nil {
+		klog.Warningln("invalid OTLP request:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	clients, err := api.clickhouse.getClients(projectId, *cfg)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if err = clients.store.InsertLogs(r.Context(), records); err != nil {
+		klog.Errorln("failed to insert log records:", err)
+		http.Error(w, "", http.StatusServiceUnavailable) // retryable according to the OTLP specification
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK) // an empty ExportLogsServiceResponse means all the records were accepted
+}
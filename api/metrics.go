@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/otlp"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/prometheus/prompb"
+	"k8s.io/klog"
+	"net/http"
+)
+
+// OtlpMetricsGrpcProjectHeader passes the project to the OTLP/gRPC receiver, as the path of a gRPC method can't be changed.
+const OtlpMetricsGrpcProjectHeader = "X-Coroot-Project"
+
+// OtlpMetrics implements the OTLP/HTTP metrics receiver (binary protobuf encoding).
+// An OTLP exporter should be pointed at /api/project/<project> as it appends /v1/metrics to the endpoint.
+func (api *Api) OtlpMetrics(w http.ResponseWriter, r *http.Request) {
+	data, status, err := readOtlpRequest(r)
+	if err == nil {
+		status, err = api.ingestOtlpMetrics(r, db.ProjectId(mux.Vars(r)["project"]), data)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK) // an empty ExportMetricsServiceResponse means all the data points were accepted
+}
+
+// OtlpMetricsGrpc implements the Export method of the OTLP/gRPC MetricsService. It's served over h2c on the main listener.
+func (api *Api) OtlpMetricsGrpc(w http.ResponseWriter, r *http.Request) {
+	status := http.StatusBadRequest
+	data, err := otlp.ReadGrpcRequest(r, otlpMaxRequestSize)
+	if errors.Is(err, otlp.ErrTooLarge) {
+		status = http.StatusRequestEntityTooLarge
+	}
+	if err == nil {
+		status, err = api.ingestOtlpMetrics(r, db.ProjectId(r.Header.Get(OtlpMetricsGrpcProjectHeader)), data)
+	}
+	if err != nil {
+		otlp.WriteGrpcResponse(w, otlp.GrpcCodeFromHttpStatus(status), err.Error())
+		return
+	}
+	otlp.WriteGrpcResponse(w, otlp.GrpcOK, "")
+}
+
+// ingestOtlpMetrics stores the metrics in the embedded TSDB, so they can be used in custom checks and dashboards
+// the same way as the metrics pushed via Prometheus remote-write.
+func (api *Api) ingestOtlpMetrics(r *http.Request, projectId db.ProjectId, data []byte) (int, error) {
+	if api.tsdb == nil {
+		return http.StatusNotFound, errors.New("the embedded TSDB is disabled")
+	}
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			klog.Warningln("project not found:", projectId)
+			return http.StatusNotFound, errors.New("project not found")
+		}
+		klog.Errorln(err)
+		return http.StatusInternalServerError, errors.New("internal error")
+	}
+	if err = checkIngestionKey(r, project); err != nil {
+		klog.Warningf("%s: OTLP request rejected: %s", projectId, err)
+		return http.StatusUnauthorized, err
+	}
+	if !project.Prometheus.Embedded {
+		return http.StatusNotFound, errors.New("the project isn't configured to use the embedded TSDB")
+	}
+	series, err := otlp.DecodeMetrics(data)
+	if err != nil {
+		klog.Warningln("invalid OTLP request:", err)
+		return http.StatusBadRequest, err
+	}
+	if err = api.tsdb.Write(r.Context(), projectId, &prompb.WriteRequest{Timeseries: series}); err != nil {
+		klog.Errorln(err)
+		return http.StatusServiceUnavailable, errors.New("failed to store the metrics")
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	reportCacheTTL        = 10 * time.Minute
+	reportCacheMaxEntries = 1000
+)
+
+// reportCache memoizes the rendered application reports. Entries are keyed by the project, the application,
+// the time range, and the data version (the time the metric cache is filled up to), so new metrics invalidate them.
+// Any configuration change, made through the API or in the background (e.g., learned adaptive thresholds),
+// bumps the config version, which invalidates all the entries.
+type reportCache struct {
+	lock    sync.Mutex
+	entries map[string]*reportCacheEntry
+
+	configVersion uint64
+}
+
+type reportCacheEntry struct {
+	body      []byte
+	createdAt time.Time
+}
+
+func newReportCache() *reportCache {
+	return &reportCache{entries: map[string]*reportCacheEntry{}}
+}
+
+func (c *reportCache) key(projectId db.ProjectId, appId model.ApplicationId, rng *worldRange, q url.Values) string {
+	var params []string
+	for k, vs := range q {
+		switch k {
+		case "from", "to", "incident":
+			continue
+		}
+		for _, v := range vs {
+			params = append(params, k+"="+v)
+		}
+	}
+	sort.Strings(params)
+	return fmt.Sprintf("%s|%s|%d|%d|%d|%d|%d|%v",
+		projectId, appId, rng.from, rng.to, rng.step, rng.dataVersion, atomic.LoadUint64(&c.configVersion), params)
+}
+
+func (c *reportCache) get(key string) []byte {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e := c.entries[key]
+	if e == nil || time.Since(e.createdAt) > reportCacheTTL {
+		return nil
+	}
+	return e.body
+}
+
+func (c *reportCache) put(key string, body []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	var oldestKey string
+	var oldest time.Time
+	for k, e := range c.entries {
+		if now.Sub(e.createdAt) > reportCacheTTL {
+			delete(c.entries, k)
+			continue
+		}
+		if oldestKey == "" || e.createdAt.Before(oldest) {
+			oldestKey, oldest = k, e.createdAt
+		}
+	}
+	if len(c.entries) >= reportCacheMaxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+	c.entries[key] = &reportCacheEntry{body: body, createdAt: now}
+}
+
+func (c *reportCache) invalidate() {
+	atomic.AddUint64(&c.configVersion, 1)
+}
+
+// InvalidateReports invalidates the cached reports once the configuration is changed outside the API.
+func (api *Api) InvalidateReports() {
+	api.reports.invalidate()
+}
+
+// InvalidateReportCache is a middleware invalidating the cached reports on any request that can change the configuration.
+// The cache is invalidated once the request is handled: otherwise, a report rendered concurrently with the old configuration
+// could be cached as the one of the new config version.
+// GraphQL and Grafana datasource queries are sent with POST requests, but don't change anything.
+func (api *Api) InvalidateReportCache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		switch {
+		case r.Method == http.MethodGet, r.Method == http.MethodHead, r.Method == http.MethodOptions:
+		case strings.HasSuffix(r.URL.Path, "/graphql"), strings.Contains(r.URL.Path, "/grafana/"):
+		case strings.HasSuffix(r.URL.Path, "/remote_write"), strings.HasSuffix(r.URL.Path, "/v1/traces"), strings.HasSuffix(r.URL.Path, "/v1/metrics"), strings.HasSuffix(r.URL.Path, "/v1/logs"): // data ingestion
+		default:
+			api.reports.invalidate()
+		}
+	})
+}
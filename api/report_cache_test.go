@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestInvalidateReportCache(t *testing.T) {
+	api := &Api{reports: newReportCache()}
+	var seen uint64
+	h := api.InvalidateReportCache(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = atomic.LoadUint64(&api.reports.configVersion)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/project/p1/app/a/check/c/config", nil))
+	assert.Equal(t, uint64(0), seen) // the handler sees the previous version
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&api.reports.configVersion))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/project/p1/app/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/project/p1/graphql", nil))
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&api.reports.configVersion))
+
+	api.InvalidateReports()
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&api.reports.configVersion))
+}
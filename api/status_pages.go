@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"github.com/coroot/coroot/api/views"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+	"net/http"
+)
+
+func (api *Api) StatusPages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	id := vars["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		if api.readOnly {
+			return
+		}
+		var form StatusPageForm
+		if err := ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid name or applications", http.StatusBadRequest)
+			return
+		}
+		id, err := api.db.SaveStatusPage(projectId, form.Get(id))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusNotFound)
+				return
+			}
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJson(w, id)
+		return
+	case http.MethodDelete:
+		if api.readOnly {
+			return
+		}
+		if err := api.db.DeleteStatusPage(projectId, id); err != nil {
+			klog.Errorln("failed to delete:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	p, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	pages := p.Settings.StatusPages
+	if pages == nil {
+		pages = []model.StatusPage{}
+	}
+	utils.WriteJson(w, pages)
+}
+
+// StatusPage renders a public status page. It doesn't load the world, the statuses and the uptime history
+// are derived from the stored results of the SLO checks.
+func (api *Api) StatusPage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	project, err := api.db.GetProject(db.ProjectId(vars["project"]))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Status page not found", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	var page *model.StatusPage
+	for i := range project.Settings.StatusPages {
+		if project.Settings.StatusPages[i].Id == vars["id"] {
+			page = &project.Settings.StatusPages[i]
+		}
+	}
+	if page == nil {
+		http.Error(w, "Status page not found", http.StatusNotFound)
+		return
+	}
+
+	now := timeseries.Now()
+	from := now.Add(-model.StatusPageHistory).Truncate(timeseries.Day)
+	incidents, err := api.db.GetApplicationIncidents(project.Id, from, now)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	sources := make([]views.StatusPageSource, 0, len(page.Applications))
+	for _, a := range page.Applications {
+		appId, err := model.NewApplicationIdFromString(a.ApplicationId)
+		if err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		failures, status, err := api.db.GetCheckFailures(project.Id, appId, a.CheckIds(), from, now, timeseries.Day)
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		sources = append(sources, views.StatusPageSource{Application: a, Failures: failures, Status: status, Incidents: incidents[appId]})
+	}
+	utils.WriteJson(w, views.StatusPage(*page, sources, now))
+}
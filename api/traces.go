@@ -0,0 +1,136 @@
+package api
+
+import (
+	"compress/gzip"
+	"errors"
+	"github.com/coroot/coroot/clickhouse"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/otlp"
+	"github.com/coroot/coroot/tracing"
+	"github.com/gorilla/mux"
+	"io"
+	"k8s.io/klog"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const otlpMaxRequestSize = 16 << 20
+
+// clickhouseIngester keeps the ClickHouse clients of each project, so the connections are reused across the ingestion
+// and read requests (query stats, logs).
+// The clients are replaced once the project's ClickHouse integration changes.
+type clickhouseIngester struct {
+	lock    sync.Mutex
+	clients map[db.ProjectId]*clickhouseIngesterClients
+}
+
+type clickhouseIngesterClients struct {
+	cfg    db.IntegrationClickhouse
+	traces *tracing.ClickhouseClient
+	store  *clickhouse.Client
+}
+
+func newClickhouseIngester() *clickhouseIngester {
+	return &clickhouseIngester{clients: map[db.ProjectId]*clickhouseIngesterClients{}}
+}
+
+func (i *clickhouseIngester) getClients(projectId db.ProjectId, cfg db.IntegrationClickhouse) (*clickhouseIngesterClients, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if c := i.clients[projectId]; c != nil {
+		if c.cfg == cfg {
+			return c, nil
+		}
+		_ = c.traces.Close()
+		_ = c.store.Close()
+		delete(i.clients, projectId)
+	}
+	traces, err := tracing.NewClickhouseClient(cfg.Protocol, cfg.Addr, cfg.TlsEnable, cfg.TlsSkipVerify, cfg.Auth, cfg.Database, cfg.TracesTable)
+	if err != nil {
+		return nil, err
+	}
+	store, err := clickhouse.NewClient(cfg.ClientConfig())
+	if err != nil {
+		_ = traces.Close()
+		return nil, err
+	}
+	c := &clickhouseIngesterClients{cfg: cfg, traces: traces, store: store}
+	i.clients[projectId] = c
+	return c, nil
+}
+
+// readOtlpRequest reads the body of an OTLP/HTTP request (binary protobuf encoding, optionally gzip-compressed).
+func readOtlpRequest(r *http.Request) ([]byte, int, error) {
+	if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/x-protobuf") {
+		return nil, http.StatusUnsupportedMediaType, errors.New("only the binary protobuf encoding is supported")
+	}
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		defer gz.Close()
+		body = gz
+	}
+	data, err := io.ReadAll(io.LimitReader(body, otlpMaxRequestSize+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if len(data) > otlpMaxRequestSize {
+		return nil, http.StatusRequestEntityTooLarge, otlp.ErrTooLarge
+	}
+	return data, http.StatusOK, nil
+}
+
+// OtlpTraces implements the OTLP/HTTP traces receiver (binary protobuf encoding).
+// An OTLP exporter should be pointed at /api/project/<project> as it appends /v1/traces to the endpoint.
+func (api *Api) OtlpTraces(w http.ResponseWriter, r *http.Request) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			klog.Warningln("project not found:", projectId)
+			http.Error(w, "Project not found.", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if err = checkIngestionKey(r, project); err != nil {
+		klog.Warningf("%s: OTLP request rejected: %s", projectId, err)
+		http.Error(w, "Invalid ingestion key.", http.StatusUnauthorized)
+		return
+	}
+	cfg := project.Settings.Integrations.Clickhouse
+	if cfg == nil || !cfg.Ingest {
+		http.Error(w, "Trace ingestion isn't enabled for the project.", http.StatusNotFound)
+		return
+	}
+	data, status, err := readOtlpRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	spans, err := tracing.DecodeOtlpTraces(data)
+	if err != nil {
+		klog.Warningln("invalid OTLP request:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	clients, err := api.clickhouse.getClients(projectId, *cfg)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if err = clients.traces.InsertSpans(r.Context(), spans); err != nil {
+		klog.Errorln("failed to insert spans:", err)
+		http.Error(w, "", http.StatusServiceUnavailable) // retryable according to the OTLP specification
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK) // an empty ExportTraceServiceResponse means all the spans were accepted
+}
@@ -8,8 +8,9 @@ import (
 )
 
 type View struct {
-	AppMap  *AppMap              `json:"app_map"`
-	Reports []*model.AuditReport `json:"reports"`
+	AppMap  *AppMap                 `json:"app_map"`
+	Owner   *model.ApplicationOwner `json:"owner,omitempty"`
+	Reports []*model.AuditReport    `json:"reports"`
 }
 
 type AppMap struct {
@@ -128,17 +129,9 @@ func Render(world *model.World, app *model.Application) *View {
 		return appMap.Dependencies[i].Id.Name < appMap.Dependencies[j].Id.Name
 	})
 
-	if len(app.Incidents) > 0 {
-		annotations := model.IncidentsToAnnotations(app.Incidents, world.Ctx)
-		for _, r := range app.Reports {
-			for _, w := range r.Widgets {
-				w.AddAnnotation(annotations...)
-			}
-		}
-	}
-
 	v := &View{
 		AppMap:  appMap,
+		Owner:   app.Owner,
 		Reports: app.Reports,
 	}
 	return v
@@ -21,6 +21,7 @@ type Check struct {
 
 type Application struct {
 	Id        model.ApplicationId `json:"id"`
+	Namespace string              `json:"namespace,omitempty"`
 	Threshold float32             `json:"threshold"`
 	Details   string              `json:"details"`
 }
@@ -34,7 +35,8 @@ func Render(configs model.CheckConfigs) *View {
 	v.addReport(model.AuditReportCPU, cs.CPUNode, cs.CPUContainer)
 	v.addReport(model.AuditReportMemory, cs.MemoryOOM)
 	v.addReport(model.AuditReportStorage, cs.StorageIO, cs.StorageSpace)
-	v.addReport(model.AuditReportNetwork, cs.NetworkRTT)
+	v.addReport(model.AuditReportNetwork, cs.NetworkRTT, cs.NetworkRTTDegradation, cs.NetworkConntrack, cs.NetworkEphemeralPorts, cs.TlsCertificateExpiry)
+	v.addReport(model.AuditReportDNS, cs.DNSLatency, cs.DNSErrors)
 	v.addReport(model.AuditReportLogs, cs.LogErrors)
 	v.addReport(model.AuditReportPostgres, cs.PostgresAvailability, cs.PostgresLatency, cs.PostgresErrors)
 	v.addReport(model.AuditReportRedis, cs.RedisAvailability, cs.RedisLatency)
@@ -61,10 +63,12 @@ func (v *View) addReport(name model.AuditReportName, checks ...model.CheckConfig
 						t := cfg.Threshold
 						ch.ProjectThreshold = &t
 					} else {
-						ch.ApplicationOverrides = append(ch.ApplicationOverrides, Application{
-							Id:        appId,
-							Threshold: cfg.Threshold,
-						})
+						o := Application{Id: appId, Threshold: cfg.Threshold}
+						if appId.IsNamespaceScope() {
+							o.Namespace = appId.Namespace
+							o.Details = "all applications in the namespace"
+						}
+						ch.ApplicationOverrides = append(ch.ApplicationOverrides, o)
 					}
 				case []model.CheckConfigSLOAvailability:
 					for _, c := range cfg {
@@ -0,0 +1,62 @@
+package dashboard
+
+import (
+	"context"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/prom"
+	"k8s.io/klog"
+)
+
+type View struct {
+	Dashboard model.Dashboard `json:"dashboard"`
+	Widgets   []*model.Widget `json:"widgets"`
+}
+
+func Render(ctx context.Context, promClient prom.Client, w *model.World, d model.Dashboard) *View {
+	v := &View{Dashboard: d, Widgets: []*model.Widget{}}
+	for _, p := range d.Panels {
+		var chart *model.Chart
+		if p.IsPromQL() {
+			chart = promQLChart(ctx, promClient, w, p)
+		} else {
+			chart = reportChart(w, p)
+		}
+		if p.Title != "" {
+			chart.Title = p.Title
+		}
+		v.Widgets = append(v.Widgets, &model.Widget{Chart: chart, Width: p.Width})
+	}
+	return v
+}
+
+func reportChart(w *model.World, p model.DashboardPanel) *model.Chart {
+	title := p.Chart
+	if id, err := model.NewApplicationIdFromString(p.ApplicationId); err == nil {
+		if app := w.GetApplication(id); app != nil {
+			title = id.Name + ": " + p.Chart
+			for _, r := range app.Reports {
+				if r.Name != p.Report {
+					continue
+				}
+				if ch := r.FindChart(p.Chart); ch != nil {
+					ch.Title = title
+					return ch
+				}
+			}
+		}
+	}
+	return model.NewChart(w.Ctx, title)
+}
+
+func promQLChart(ctx context.Context, promClient prom.Client, w *model.World, p model.DashboardPanel) *model.Chart {
+	chart := model.NewChart(w.Ctx, p.Query)
+	metrics, err := promClient.QueryRange(ctx, p.Query, w.Ctx.From, w.Ctx.To, w.Ctx.Step)
+	if err != nil {
+		klog.Warningln("failed to query:", err)
+		return chart
+	}
+	for _, mv := range metrics {
+		chart.AddSeries(p.SeriesName(mv.Labels), mv.Values)
+	}
+	return chart
+}
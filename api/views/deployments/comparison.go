@@ -0,0 +1,60 @@
+package deployments
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"time"
+)
+
+var comparedReports = []model.AuditReportName{
+	model.AuditReportSLO,
+	model.AuditReportInstances,
+	model.AuditReportCPU,
+	model.AuditReportMemory,
+	model.AuditReportLogs,
+}
+
+// Comparison renders the windows around two deployments of an application side by side:
+// the reports of B are overlaid with the data of A shifted in time.
+type Comparison struct {
+	Deployments []Deployment         `json:"deployments"`
+	A           string               `json:"a"`
+	B           string               `json:"b"`
+	Changes     *model.Table         `json:"changes"`
+	Metrics     *model.Table         `json:"metrics"`
+	Reports     []*model.AuditReport `json:"reports"`
+}
+
+type Deployment struct {
+	Id        string          `json:"id"`
+	Version   string          `json:"version"`
+	StartedAt timeseries.Time `json:"started_at"`
+}
+
+type Side struct {
+	Deployment  *model.ApplicationDeployment
+	Application *model.Application
+	Window      timeseries.Window
+}
+
+func Render(deployments []*model.ApplicationDeployment, a, b Side, loc *time.Location) *Comparison {
+	res := &Comparison{
+		A:       a.Deployment.Id(),
+		B:       b.Deployment.Id(),
+		Changes: model.CompareDeploymentChanges(a.Deployment, b.Deployment, loc),
+		Metrics: model.CompareDeploymentMetrics(a.Application, b.Application, a.Window, b.Window),
+	}
+	for i := len(deployments) - 1; i >= 0; i-- {
+		d := deployments[i]
+		res.Deployments = append(res.Deployments, Deployment{Id: d.Id(), Version: d.Version(), StartedAt: d.StartedAt})
+	}
+	model.CompareReports(b.Application.Reports, a.Application.Reports, b.Deployment.StartedAt.Sub(a.Deployment.StartedAt))
+	for _, name := range comparedReports {
+		for _, r := range b.Application.Reports {
+			if r.Name == name {
+				res.Reports = append(res.Reports, r)
+			}
+		}
+	}
+	return res
+}
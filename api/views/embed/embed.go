@@ -0,0 +1,55 @@
+package embed
+
+import (
+	"github.com/coroot/coroot/model"
+)
+
+// Embed is a chart (or a chart group) or a check status of an application report, rendered for embedding into external pages.
+type Embed struct {
+	ApplicationId model.ApplicationId   `json:"application_id"`
+	Report        model.AuditReportName `json:"report"`
+	Widget        *model.Widget         `json:"widget,omitempty"`
+	Check         *model.Check          `json:"check,omitempty"`
+}
+
+// Render returns nil if there's no report, widget, or check with the given name (id for checks).
+func Render(app *model.Application, report, widget, check string) *Embed {
+	for _, r := range app.Reports {
+		if string(r.Name) != report {
+			continue
+		}
+		e := &Embed{ApplicationId: app.Id, Report: r.Name}
+		switch {
+		case check != "":
+			for _, ch := range r.Checks {
+				if string(ch.Id) == check {
+					e.Check = ch
+					return e
+				}
+			}
+		case widget != "":
+			for _, w := range r.Widgets {
+				if WidgetTitle(w) == widget {
+					w.Width = "100%"
+					e.Widget = w
+					return e
+				}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// WidgetTitle returns the title of an embeddable widget, or an empty string if the widget can't be embedded.
+func WidgetTitle(w *model.Widget) string {
+	switch {
+	case w.Chart != nil:
+		return w.Chart.Title
+	case w.ChartGroup != nil:
+		return w.ChartGroup.Title
+	case w.Heatmap != nil:
+		return w.Heatmap.Title
+	}
+	return ""
+}
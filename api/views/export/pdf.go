@@ -0,0 +1,330 @@
+package export
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/coroot/coroot/utils/pdf"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	margin       = 36
+	contentWidth = pdf.PageWidth - 2*margin
+	chartHeight  = 130
+)
+
+var (
+	palette = []pdf.Color{
+		pdf.RGB(33, 150, 243), pdf.RGB(76, 175, 80), pdf.RGB(255, 152, 0), pdf.RGB(156, 39, 176), pdf.RGB(244, 67, 54),
+		pdf.RGB(0, 188, 212), pdf.RGB(121, 85, 72), pdf.RGB(205, 220, 57), pdf.RGB(96, 125, 139), pdf.RGB(233, 30, 99),
+	}
+	namedColors = map[string]pdf.Color{
+		"black":      pdf.Black,
+		"grey":       pdf.Grey,
+		"red":        pdf.RGB(244, 67, 54),
+		"green":      pdf.RGB(76, 175, 80),
+		"blue":       pdf.RGB(33, 150, 243),
+		"light-blue": pdf.RGB(3, 169, 244),
+		"orange":     pdf.RGB(255, 152, 0),
+		"amber":      pdf.RGB(255, 193, 7),
+		"yellow":     pdf.RGB(255, 235, 59),
+		"purple":     pdf.RGB(156, 39, 176),
+		"cyan":       pdf.RGB(0, 188, 212),
+	}
+	statusColors = map[model.Status]pdf.Color{
+		model.OK:       pdf.RGB(35, 209, 96),
+		model.WARNING:  pdf.RGB(255, 221, 87),
+		model.CRITICAL: pdf.RGB(244, 64, 52),
+	}
+	gridColor = pdf.RGB(224, 224, 224)
+)
+
+// ReportPDF renders the charts, tables, and check statuses of an audit report to a PDF document.
+func ReportPDF(w io.Writer, title string, report *model.AuditReport, ctx timeseries.Context) error {
+	r := &renderer{doc: pdf.New(), ctx: ctx}
+	r.newPage()
+
+	r.doc.Text(margin, r.y+16, 16, true, pdf.Black, fmt.Sprintf("%s: %s", title, report.Name))
+	r.y += 24
+	r.doc.Text(margin, r.y+10, 9, false, pdf.Grey, fmt.Sprintf("%s - %s UTC",
+		ctx.From.ToStandard().Format("2006-01-02 15:04"), ctx.To.ToStandard().Format("2006-01-02 15:04")))
+	r.y += 24
+
+	for _, ch := range report.Checks {
+		r.check(ch)
+	}
+	if len(report.Checks) > 0 {
+		r.y += 10
+	}
+
+	for _, widget := range report.Widgets {
+		switch {
+		case widget.Chart != nil:
+			r.chart(widget.Chart.Title, widget.Chart)
+		case widget.ChartGroup != nil:
+			for _, ch := range widget.ChartGroup.Charts {
+				r.chart(widget.ChartGroup.ChartTitle(ch), ch)
+			}
+		case widget.Table != nil:
+			r.table(widget.Table)
+		}
+	}
+	_, err := r.doc.WriteTo(w)
+	return err
+}
+
+type renderer struct {
+	doc *pdf.Document
+	ctx timeseries.Context
+	y   float64
+}
+
+func (r *renderer) newPage() {
+	r.doc.AddPage()
+	r.y = margin
+}
+
+func (r *renderer) ensure(height float64) {
+	if r.y+height > pdf.PageHeight-margin {
+		r.newPage()
+	}
+}
+
+func (r *renderer) check(ch *model.Check) {
+	r.ensure(16)
+	color, ok := statusColors[ch.Status]
+	if !ok {
+		color = pdf.Grey
+	}
+	r.doc.Rect(margin, r.y+2, 8, 8, color, true)
+	msg := ch.Message
+	if ch.Status == model.OK {
+		msg = "ok"
+	}
+	switch {
+	case ch.Muted:
+		msg += " (muted)"
+	case ch.Maintenance:
+		msg += " (maintenance)"
+	case ch.SuppressedBy != "":
+		msg += " (suppressed)"
+	}
+	r.doc.Text(margin+14, r.y+9, 10, true, pdf.Black, ch.Title)
+	r.doc.Text(margin+14+pdf.TextWidth(ch.Title, 10)+8, r.y+9, 10, false, pdf.Black, msg)
+	r.y += 16
+}
+
+func (r *renderer) chart(title string, chart *model.Chart) {
+	series := chart.Series.Get()
+	if len(series) == 0 {
+		return
+	}
+	if chart.IsHeatmap {
+		r.heatmap(title, series)
+		return
+	}
+	r.ensure(chartHeight + 50)
+	r.doc.Text(margin, r.y+10, 10, true, pdf.Black, title)
+	r.y += 16
+
+	data := make([]*timeseries.TimeSeries, len(series))
+	stacked := timeseries.NewAggregate(timeseries.NanSum)
+	for i, s := range series {
+		data[i] = s.Data.Get()
+		if chart.IsStacked {
+			data[i] = stacked.Add(data[i]).Get()
+		}
+	}
+	var threshold *timeseries.TimeSeries
+	if chart.Threshold != nil {
+		threshold = chart.Threshold.Data.Get()
+	}
+
+	lo, hi := float32(0), float32(math.Inf(-1))
+	for _, ts := range append(data, threshold) {
+		if ts.IsEmpty() {
+			continue
+		}
+		if v := ts.Reduce(timeseries.Max); !timeseries.IsNaN(v) && v > hi {
+			hi = v
+		}
+		if v := ts.Reduce(timeseries.Min); !timeseries.IsNaN(v) && v < lo {
+			lo = v
+		}
+	}
+	if hi <= lo {
+		hi = lo + 1
+	}
+
+	const labelsWidth = 50
+	x0, y0 := float64(margin+labelsWidth), r.y
+	w, h := float64(contentWidth-labelsWidth), float64(chartHeight)
+	for i := 0; i <= 4; i++ {
+		y := y0 + h*float64(i)/4
+		r.doc.Line(x0, y, x0+w, y, 0.5, gridColor)
+		v := hi - (hi-lo)*float32(i)/4
+		r.doc.Text(margin, y+3, 7, false, pdf.Grey, utils.FormatFloat(v))
+	}
+	r.doc.Text(x0, y0+h+10, 7, false, pdf.Grey, r.ctx.From.ToStandard().Format("Jan 2 15:04"))
+	to := r.ctx.To.ToStandard().Format("Jan 2 15:04")
+	r.doc.Text(x0+w-pdf.TextWidth(to, 7), y0+h+10, 7, false, pdf.Grey, to)
+
+	for _, a := range chart.Annotations {
+		x1, x2 := r.x(a.X1, x0, w), r.x(a.X2, x0, w)
+		r.doc.Line(x1, y0, x1, y0+h, 0.5, pdf.Grey)
+		if x2 > x1 {
+			r.doc.Line(x2, y0, x2, y0+h, 0.5, pdf.Grey)
+		}
+	}
+
+	plot := func(ts *timeseries.TimeSeries, color pdf.Color, width float64) {
+		var points [][]float64
+		iter := ts.Iter()
+		for iter.Next() {
+			t, v := iter.Value()
+			if timeseries.IsNaN(v) {
+				points = append(points, nil)
+				continue
+			}
+			points = append(points, []float64{r.x(t, x0, w), y0 + h - h*float64((v-lo)/(hi-lo))})
+		}
+		r.doc.Polyline(points, width, color)
+	}
+	colors := make([]pdf.Color, len(series))
+	for i, s := range series {
+		colors[i] = seriesColor(s.Color, i+chart.ColorShift)
+		plot(data[i], colors[i], 1)
+	}
+	if !threshold.IsEmpty() {
+		plot(threshold, pdf.Black, 0.7)
+	}
+	r.y += h + 16
+
+	x := x0
+	for i, s := range series {
+		width := pdf.TextWidth(s.Name, 8) + 20
+		if x+width > margin+contentWidth {
+			x = x0
+			r.y += 11
+		}
+		r.doc.Line(x, r.y+5, x+10, r.y+5, 2, colors[i])
+		r.doc.Text(x+13, r.y+8, 8, false, pdf.Black, s.Name)
+		x += width
+	}
+	r.y += 20
+}
+
+func (r *renderer) heatmap(title string, series []*model.Series) {
+	r.ensure(chartHeight + 40)
+	r.doc.Text(margin, r.y+10, 10, true, pdf.Black, title)
+	r.y += 16
+
+	max := float32(0)
+	for _, s := range series {
+		if v := s.Data.Reduce(timeseries.Max); !timeseries.IsNaN(v) && v > max {
+			max = v
+		}
+	}
+	const labelsWidth = 50
+	x0, y0 := float64(margin+labelsWidth), r.y
+	w, h := float64(contentWidth-labelsWidth), float64(chartHeight)
+	rowHeight := h / float64(len(series))
+	for i, s := range series {
+		y := y0 + h - rowHeight*float64(i+1)
+		r.doc.Text(margin, y+rowHeight/2+3, 7, false, pdf.Grey, truncate(s.Name, labelsWidth, 7))
+		data := s.Data.Get()
+		cellWidth := w / float64(data.Len())
+		iter := data.Iter()
+		for iter.Next() {
+			t, v := iter.Value()
+			if timeseries.IsNaN(v) || v <= 0 || max <= 0 {
+				continue
+			}
+			r.doc.Rect(r.x(t, x0, w)-cellWidth/2, y, cellWidth, rowHeight-0.5, heatmapColor(v/max), true)
+		}
+	}
+	r.doc.Text(x0, y0+h+10, 7, false, pdf.Grey, r.ctx.From.ToStandard().Format("Jan 2 15:04"))
+	to := r.ctx.To.ToStandard().Format("Jan 2 15:04")
+	r.doc.Text(x0+w-pdf.TextWidth(to, 7), y0+h+10, 7, false, pdf.Grey, to)
+	r.y += h + 30
+}
+
+// heatmapColor interpolates between light and dark blue according to the intensity (0..1).
+func heatmapColor(intensity float32) pdf.Color {
+	lerp := func(from, to float32) uint8 {
+		return uint8(from + (to-from)*intensity)
+	}
+	return pdf.RGB(lerp(191, 0), lerp(231, 99), lerp(255, 153))
+}
+
+func (r *renderer) x(t timeseries.Time, x0, w float64) float64 {
+	f := float64(t.Sub(r.ctx.From)) / float64(r.ctx.To.Sub(r.ctx.From))
+	return x0 + w*math.Max(0, math.Min(1, f))
+}
+
+func (r *renderer) table(t *model.Table) {
+	if len(t.Rows) == 0 || len(t.Header) == 0 {
+		return
+	}
+	const rowHeight = 14
+	colWidth := float64(contentWidth) / float64(len(t.Header))
+	header := func() {
+		for i, h := range t.Header {
+			r.doc.Text(margin+float64(i)*colWidth, r.y+10, 9, true, pdf.Black, truncate(h, colWidth, 9))
+		}
+		r.y += rowHeight
+		r.doc.Line(margin, r.y-2, margin+contentWidth, r.y-2, 0.5, pdf.Grey)
+	}
+	r.ensure(rowHeight * 3)
+	header()
+	for _, row := range t.Rows {
+		if r.y+rowHeight > pdf.PageHeight-margin {
+			r.newPage()
+			header()
+		}
+		for i, c := range row.Cells {
+			if i >= len(t.Header) || c == nil {
+				continue
+			}
+			r.doc.Text(margin+float64(i)*colWidth, r.y+10, 8, false, pdf.Black, truncate(cellText(c), colWidth, 8))
+		}
+		r.y += rowHeight
+	}
+	r.y += 14
+}
+
+func cellText(c *model.TableCell) string {
+	parts := []string{c.Text()}
+	if c.Unit != "" {
+		parts[0] += " " + c.Unit
+	}
+	parts = append(parts, c.Tags...)
+	return strings.Join(parts, ", ")
+}
+
+func truncate(text string, width, size float64) string {
+	width -= 6
+	if pdf.TextWidth(text, size) <= width {
+		return text
+	}
+	runes := []rune(text)
+	n := int(width / pdf.TextWidth("x", size))
+	if n >= len(runes) {
+		return text
+	}
+	if n <= 3 {
+		return ""
+	}
+	return string(runes[:n-3]) + "..."
+}
+
+func seriesColor(name string, i int) pdf.Color {
+	if c, ok := namedColors[name]; ok {
+		return c
+	}
+	return palette[i%len(palette)]
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestReportPDF(t *testing.T) {
+	ctx := timeseries.Context{From: 0, To: 3600, Step: 60}
+	app := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"))
+	report := model.NewAuditReport(app, ctx, nil, model.AuditReportCPU)
+	report.CreateCheck(model.Checks.CPUNode).SetStatus(model.WARNING, "high CPU utilization")
+	report.GetOrCreateChart("CPU usage, cores").AddSeries("cart-1", timeseries.NewWithData(0, 60, []float32{0.1, 0.2, timeseries.NaN, 0.4}))
+	table := report.GetOrCreateTable("Container", "Usage")
+	for i := 0; i < 100; i++ {
+		table.AddRow(model.NewTableCell(fmt.Sprintf("container-%02d", i)), model.NewTableCell("0.1").SetUnit("cores"))
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ReportPDF(buf, "cart (shop)", report, ctx))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	assert.Contains(t, out, `(cart \(shop\): CPU) Tj`)
+	assert.Contains(t, out, "(CPU usage, cores) Tj")
+	assert.Contains(t, out, "(container-99) Tj")
+
+	m := regexp.MustCompile(`/Count (\d+)`).FindStringSubmatch(out)
+	require.Len(t, m, 2)
+	assert.NotEqual(t, "1", m[1], "the long table must be split into pages")
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 100, 9))
+	long := strings.Repeat("x", 100)
+	res := truncate(long, 100, 9)
+	assert.True(t, strings.HasSuffix(res, "..."))
+	assert.Less(t, len(res), len(long))
+	assert.Equal(t, "", truncate(long, 10, 9))
+}
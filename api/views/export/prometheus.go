@@ -0,0 +1,275 @@
+package export
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/prom"
+	"github.com/coroot/coroot/timeseries"
+	prommodel "github.com/prometheus/common/model"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrometheusRules is a Prometheus rule file with the alerting rules equivalent to the effective check configuration,
+// so that Alertmanager can remain the single source of alerts. Only the checks computed from the raw metrics can be exported:
+// the project-wide thresholds of the infrastructure checks, the custom checks, and the burn rates of the custom SLOs.
+type PrometheusRules struct {
+	Groups []PrometheusRuleGroup `json:"groups"`
+
+	// Skipped describes the configured checks that can't be expressed as Prometheus rules.
+	Skipped []string `json:"-"`
+}
+
+type PrometheusRuleGroup struct {
+	Name  string           `json:"name"`
+	Rules []PrometheusRule `json:"rules"`
+}
+
+type PrometheusRule struct {
+	Record      string            `json:"record,omitempty"`
+	Alert       string            `json:"alert,omitempty"`
+	Expr        string            `json:"expr"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type prometheusCheckExpr struct {
+	value string
+	op    string
+}
+
+// prometheusCheckExprs are the values the infrastructure checks compare to their thresholds, built from the node-agent and exporter metrics.
+var prometheusCheckExprs = map[model.CheckId]prometheusCheckExpr{
+	model.Checks.CPUNode.Id: {
+		value: `sum(rate(node_resources_cpu_usage_seconds_total{mode!="idle"}[$RANGE])) without(mode) / sum(rate(node_resources_cpu_usage_seconds_total[$RANGE])) without(mode) * 100`,
+		op:    ">",
+	},
+	model.Checks.CPUContainer.Id: {
+		value: `rate(container_resources_cpu_usage_seconds_total[$RANGE]) / container_resources_cpu_limit_cores * 100`,
+		op:    ">",
+	},
+	model.Checks.MemoryOOM.Id:            {value: `increase(container_oom_kills_total[$RANGE])`, op: ">"},
+	model.Checks.InstanceRestarts.Id:     {value: `increase(container_restarts_total[$RANGE])`, op: ">"},
+	model.Checks.StorageIO.Id:            {value: `rate(node_resources_disk_io_time_seconds_total[$RANGE]) * 100`, op: ">"},
+	model.Checks.StorageSpace.Id:         {value: `container_resources_disk_used_bytes / container_resources_disk_size_bytes * 100`, op: ">"},
+	model.Checks.NetworkRTT.Id:           {value: `container_net_latency_seconds`, op: ">"},
+	model.Checks.NetworkConntrack.Id:     {value: `node_nf_conntrack_entries / node_nf_conntrack_entries_limit * 100`, op: ">"},
+	model.Checks.TlsCertificateExpiry.Id: {value: `probe_ssl_earliest_cert_expiry - time()`, op: "<"},
+	model.Checks.DNSLatency.Id:           {value: `rate(container_dns_requests_duration_seconds_total_sum[$RANGE]) / rate(container_dns_requests_duration_seconds_total_count[$RANGE])`, op: ">"},
+	model.Checks.RedisAvailability.Id:    {value: `count(redis_up == 0)`, op: ">"},
+	model.Checks.PostgresAvailability.Id: {value: `count(pg_up == 0)`, op: ">"},
+	model.Checks.JvmSafepointTime.Id:     {value: `rate(container_jvm_safepoint_time_seconds[$RANGE])`, op: ">"},
+	model.Checks.PostgresConnections.Id:  {value: `sum by(instance)(pg_connections) / on(instance) pg_setting{name="max_connections"} * 100`, op: ">"},
+}
+
+// RenderPrometheusRules converts the check configs of a project to Prometheus rules.
+// The step is the refresh interval of the project, $RANGE in the queries covers three steps as in Coroot itself.
+func RenderPrometheusRules(checkConfigs model.CheckConfigs, customChecks []model.CustomCheck, step timeseries.Duration) *PrometheusRules {
+	res := &PrometheusRules{Groups: []PrometheusRuleGroup{}}
+	skip := func(format string, a ...any) {
+		res.Skipped = append(res.Skipped, fmt.Sprintf(format, a...))
+	}
+
+	unsupported := map[model.CheckId]bool{}
+	for _, appConfigs := range checkConfigs {
+		for checkId := range appConfigs {
+			switch checkId {
+			case model.Checks.SLOAvailability.Id, model.Checks.SLOLatency.Id:
+			default:
+				if _, ok := prometheusCheckExprs[checkId]; !ok && model.GetCheckConfig(checkId) != nil {
+					unsupported[checkId] = true
+				}
+			}
+		}
+	}
+	for checkId := range unsupported {
+		skip("%s: the check is computed from the Coroot model and has no Prometheus equivalent", checkId)
+	}
+
+	infra := PrometheusRuleGroup{Name: "coroot-checks"}
+	ids := make([]string, 0, len(prometheusCheckExprs))
+	for id := range prometheusCheckExprs {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		checkId := model.CheckId(id)
+		def := model.GetCheckConfig(checkId)
+		e := prometheusCheckExprs[checkId]
+		overrides := 0
+		for appId := range checkConfigs.GetByCheck(checkId) {
+			if !appId.IsZero() {
+				overrides++
+			}
+		}
+		if overrides > 0 {
+			skip("%s: %d application/namespace-level override(s) are not exported, the project-wide threshold is used", checkId, overrides)
+		}
+		cfg := checkConfigs.GetSimple(checkId, model.ApplicationIdZero)
+		if cfg.Adaptive != nil || len(cfg.Schedule) > 0 {
+			skip("%s: adaptive and scheduled thresholds are not exported, the static threshold is used", checkId)
+		}
+		if cfg.Instances != nil {
+			skip("%s: the instance selector is not exported", checkId)
+		}
+		severity := model.WARNING
+		if cfg.Severity > model.UNKNOWN {
+			severity = cfg.Severity
+		}
+		value := prom.ExpandRange(e.value, step)
+		infra.Rules = append(infra.Rules, checkRule(def, value, e.op, cfg.Threshold, severity))
+		if cfg.CriticalThreshold != nil {
+			infra.Rules = append(infra.Rules, checkRule(def, value, e.op, *cfg.CriticalThreshold, model.CRITICAL))
+		}
+	}
+	if len(infra.Rules) > 0 {
+		res.Groups = append(res.Groups, infra)
+	}
+
+	custom := PrometheusRuleGroup{Name: "coroot-custom-checks"}
+	for _, c := range customChecks {
+		r := PrometheusRule{
+			Alert:  alertName(c.Title),
+			Expr:   fmt.Sprintf("(%s) > %s", prom.ExpandRange(c.Query, step), formatThreshold(c.Threshold)),
+			Labels: map[string]string{"severity": model.WARNING.String(), "coroot_check": string(c.CheckId())},
+			Annotations: map[string]string{
+				"summary":     c.Title,
+				"description": "the value > " + c.Unit.FormatValue(c.Threshold),
+			},
+		}
+		if len(c.Applications) > 0 {
+			r.Annotations["applications"] = strings.Join(c.Applications, ", ")
+		}
+		custom.Rules = append(custom.Rules, r)
+	}
+	if len(custom.Rules) > 0 {
+		res.Groups = append(res.Groups, custom)
+	}
+
+	var appIds []model.ApplicationId
+	for appId := range checkConfigs {
+		if !appId.IsZero() && !appId.IsNamespaceScope() {
+			appIds = append(appIds, appId)
+		}
+	}
+	sort.Slice(appIds, func(i, j int) bool {
+		return appIds[i].String() < appIds[j].String()
+	})
+	for _, appId := range appIds {
+		if cfg, isDefault := checkConfigs.GetAvailability(appId); !isDefault {
+			if !cfg.Custom {
+				skip("%s: the availability SLO is based on the Coroot-internal metrics", appId)
+			} else {
+				res.Groups = append(res.Groups, sloGroup(appId, "availability", cfg.ObjectivePercentage, func(w string) string {
+					return fmt.Sprintf("sum(rate(%s[%s])) / sum(rate(%s[%s]))", cfg.FailedRequestsQuery, w, cfg.TotalRequestsQuery, w)
+				}))
+			}
+		}
+		if cfg, isDefault := checkConfigs.GetLatency(appId, ""); !isDefault {
+			if !cfg.Custom {
+				skip("%s: the latency SLO is based on the Coroot-internal metrics", appId)
+				continue
+			}
+			fast, err := prom.AddLabelMatcher(cfg.HistogramQuery, "le", strconv.FormatFloat(float64(cfg.ObjectiveBucket), 'g', -1, 32))
+			if err != nil {
+				skip("%s: invalid histogram query: %s", appId, err)
+				continue
+			}
+			total, err := prom.AddLabelMatcher(cfg.HistogramQuery, "le", "+Inf")
+			if err != nil {
+				skip("%s: invalid histogram query: %s", appId, err)
+				continue
+			}
+			res.Groups = append(res.Groups, sloGroup(appId, "latency", cfg.ObjectivePercentage, func(w string) string {
+				return fmt.Sprintf("1 - sum(rate(%s[%s])) / sum(rate(%s[%s]))", fast, w, total, w)
+			}))
+		}
+	}
+	sort.Strings(res.Skipped)
+	return res
+}
+
+func checkRule(def *model.CheckConfig, value, op string, threshold float32, severity model.Status) PrometheusRule {
+	return PrometheusRule{
+		Alert:  string(def.Id),
+		Expr:   fmt.Sprintf("%s %s %s", value, op, formatThreshold(threshold)),
+		Labels: map[string]string{"severity": severity.String(), "coroot_check": string(def.Id)},
+		Annotations: map[string]string{
+			"summary":     def.Title,
+			"description": strings.Replace(def.ConditionFormatTemplate, "<threshold>", def.Unit.FormatValue(threshold), 1),
+		},
+	}
+}
+
+// sloGroup returns the multiwindow, multi-burn-rate alerts of an SLO (as described in the Google SRE Workbook)
+// with the same windows and burn rate thresholds as the SLOFastBurn and SLOSlowBurn checks.
+func sloGroup(appId model.ApplicationId, slo string, objectivePercentage float32, errorRatio func(window string) string) PrometheusRuleGroup {
+	g := PrometheusRuleGroup{Name: fmt.Sprintf("coroot-slo-%s-%s", slo, appId)}
+	labels := map[string]string{"application": appId.String(), "slo": slo}
+	record := func(w timeseries.Duration) string {
+		return "coroot:slo_error_ratio:rate" + formatWindow(w)
+	}
+	selector := fmt.Sprintf(`{application=%q, slo=%q}`, appId.String(), slo)
+	seen := map[timeseries.Duration]bool{}
+	for _, r := range model.AlertRules {
+		for _, w := range []timeseries.Duration{r.LongWindow, r.ShortWindow} {
+			if seen[w] {
+				continue
+			}
+			seen[w] = true
+			g.Rules = append(g.Rules, PrometheusRule{Record: record(w), Expr: errorRatio(formatWindow(w)), Labels: labels})
+		}
+	}
+	objective, _ := strconv.ParseFloat(formatThreshold(objectivePercentage), 64)
+	budget := strconv.FormatFloat((100-objective)/100, 'g', 10, 64)
+	for _, r := range model.AlertRules {
+		check := model.Checks.SLOSlowBurn
+		if r.Severity == model.CRITICAL {
+			check = model.Checks.SLOFastBurn
+		}
+		g.Rules = append(g.Rules, PrometheusRule{
+			Alert: string(check.Id),
+			Expr: fmt.Sprintf("%s%s > (%s * %s) and %s%s > (%s * %s)",
+				record(r.LongWindow), selector, formatThreshold(r.BurnRateThreshold), budget,
+				record(r.ShortWindow), selector, formatThreshold(r.BurnRateThreshold), budget,
+			),
+			Labels: map[string]string{"severity": r.Severity.String(), "coroot_check": string(check.Id), "application": appId.String(), "slo": slo},
+			Annotations: map[string]string{
+				"summary":     fmt.Sprintf("%s: %s SLO", appId.Name, slo),
+				"description": fmt.Sprintf("the error budget burn rate > %sx within %s", formatThreshold(r.BurnRateThreshold), formatWindow(r.LongWindow)),
+			},
+		})
+	}
+	return g
+}
+
+func formatWindow(d timeseries.Duration) string {
+	return prommodel.Duration(d.ToStandard()).String()
+}
+
+func formatThreshold(v float32) string {
+	return strconv.FormatFloat(float64(v), 'g', -1, 32)
+}
+
+// alertName converts a title to a valid alert name (a Prometheus metric name).
+func alertName(title string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range title {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9' && b.Len() > 0:
+			if upper {
+				r = []rune(strings.ToUpper(string(r)))[0]
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	if b.Len() == 0 {
+		return "CustomCheck"
+	}
+	return b.String()
+}
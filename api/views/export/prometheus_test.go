@@ -0,0 +1,72 @@
+package export
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRenderPrometheusRules(t *testing.T) {
+	app := model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog")
+	raw := func(v any) json.RawMessage {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return data
+	}
+	critical := float32(95)
+	checkConfigs := model.CheckConfigs{
+		model.ApplicationIdZero: {
+			model.Checks.CPUNode.Id:    raw(model.CheckConfigSimple{Threshold: 70, CriticalThreshold: &critical}),
+			model.Checks.MemoryLeak.Id: raw(model.CheckConfigSimple{Threshold: 20}),
+		},
+		app: {
+			model.Checks.CPUNode.Id: raw(model.CheckConfigSimple{Threshold: 90}),
+			model.Checks.SLOLatency.Id: raw([]model.CheckConfigSLOLatency{{
+				Custom: true, HistogramQuery: `http_request_duration_seconds_bucket{job="catalog"}`, ObjectiveBucket: 0.25, ObjectivePercentage: 99.9,
+			}}),
+		},
+	}
+	customChecks := []model.CustomCheck{{Id: "1", Title: "queue size", Query: `sum(rate(queue_messages_total[$RANGE]))`, Threshold: 100}}
+
+	rules := RenderPrometheusRules(checkConfigs, customChecks, 30)
+	assert.Equal(t, []string{
+		"CPUNode: 1 application/namespace-level override(s) are not exported, the project-wide threshold is used",
+		"MemoryLeak: the check is computed from the Coroot model and has no Prometheus equivalent",
+	}, rules.Skipped)
+	require.Len(t, rules.Groups, 3)
+
+	var cpu []PrometheusRule
+	for _, r := range rules.Groups[0].Rules {
+		if r.Alert == "CPUNode" {
+			cpu = append(cpu, r)
+		}
+	}
+	require.Len(t, cpu, 2)
+	assert.Equal(t, `sum(rate(node_resources_cpu_usage_seconds_total{mode!="idle"}[90s])) without(mode) / sum(rate(node_resources_cpu_usage_seconds_total[90s])) without(mode) * 100 > 70`, cpu[0].Expr)
+	assert.Equal(t, "warning", cpu[0].Labels["severity"])
+	assert.Equal(t, "the CPU usage of a node > 70%", cpu[0].Annotations["description"])
+	assert.Equal(t, "critical", cpu[1].Labels["severity"])
+
+	assert.Equal(t, PrometheusRule{
+		Alert:       "QueueSize",
+		Expr:        `(sum(rate(queue_messages_total[90s]))) > 100`,
+		Labels:      map[string]string{"severity": "warning", "coroot_check": "custom:1"},
+		Annotations: map[string]string{"summary": "queue size", "description": "the value > 100"},
+	}, rules.Groups[1].Rules[0])
+
+	slo := rules.Groups[2]
+	assert.Equal(t, "coroot-slo-latency-default:Deployment:catalog", slo.Name)
+	assert.Equal(t, "coroot:slo_error_ratio:rate1h", slo.Rules[0].Record)
+	assert.Equal(t,
+		`1 - sum(rate(http_request_duration_seconds_bucket{job="catalog",le="0.25"}[1h])) / sum(rate(http_request_duration_seconds_bucket{job="catalog",le="+Inf"}[1h]))`,
+		slo.Rules[0].Expr,
+	)
+	fastBurn := slo.Rules[7]
+	assert.Equal(t, "SLOFastBurn", fastBurn.Alert)
+	assert.Equal(t,
+		`coroot:slo_error_ratio:rate1h{application="default:Deployment:catalog", slo="latency"} > (14.4 * 0.001) and coroot:slo_error_ratio:rate5m{application="default:Deployment:catalog", slo="latency"} > (14.4 * 0.001)`,
+		fastBurn.Expr,
+	)
+}
@@ -0,0 +1,289 @@
+package export
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+// ApiVersion is the version of the external reports API (GET /api/v1/project/{project}/app/{app}/reports).
+// The structures below are the contract of the API: fields can be added, but never renamed, removed, or changed
+// in meaning. They're intentionally decoupled from the internal model, which changes freely.
+const ApiVersion = "v1"
+
+// ReportsV1 contains the audit reports of an application for the requested time range.
+// Timestamps are Unix timestamps in seconds, durations are in seconds.
+type ReportsV1 struct {
+	Version     string          `json:"version"`
+	Application ApplicationV1   `json:"application"`
+	From        int64           `json:"from"`
+	To          int64           `json:"to"`
+	Step        int64           `json:"step"`
+	Reports     []AuditReportV1 `json:"reports"`
+}
+
+type ApplicationV1 struct {
+	Id        string `json:"id"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+}
+
+// AuditReportV1 is an audit report. Status is one of: unknown, ok, info, warning, critical.
+type AuditReportV1 struct {
+	Name   string    `json:"name"`
+	Status string    `json:"status"`
+	Checks []CheckV1 `json:"checks"`
+	Charts []ChartV1 `json:"charts"`
+	Tables []TableV1 `json:"tables"`
+}
+
+// CheckV1 is the result of a check. Unit is one of: percent, second, millisecond, byte, or empty.
+type CheckV1 struct {
+	Id          string  `json:"id"`
+	Title       string  `json:"title"`
+	Status      string  `json:"status"`
+	Message     string  `json:"message"`
+	Threshold   float32 `json:"threshold"`
+	Unit        string  `json:"unit"`
+	Muted       bool    `json:"muted"`
+	Maintenance bool    `json:"maintenance"`
+	Suppressed  bool    `json:"suppressed"`
+}
+
+// ChartV1 is a chart. For charts from a group (e.g., RTT per upstream service), Group is the item the chart refers to.
+// Heatmap charts contain a series per histogram bucket, from the lowest to the highest.
+type ChartV1 struct {
+	Title       string         `json:"title"`
+	Group       string         `json:"group,omitempty"`
+	Stacked     bool           `json:"stacked"`
+	Heatmap     bool           `json:"heatmap"`
+	Series      []SeriesV1     `json:"series"`
+	Threshold   *SeriesV1      `json:"threshold,omitempty"`
+	Annotations []AnnotationV1 `json:"annotations"`
+}
+
+// SeriesV1 is a time series. Values[i] is the value at From + i*Step, null means no data.
+// If only summaries are requested, Values is empty and only Stats is set.
+type SeriesV1 struct {
+	Name   string        `json:"name"`
+	From   int64         `json:"from"`
+	Step   int64         `json:"step"`
+	Values []*float32    `json:"values"`
+	Stats  SeriesStatsV1 `json:"stats"`
+}
+
+// SeriesStatsV1 summarizes the values of a series, null means no data.
+type SeriesStatsV1 struct {
+	Min  *float32 `json:"min"`
+	Max  *float32 `json:"max"`
+	Avg  *float32 `json:"avg"`
+	Last *float32 `json:"last"`
+}
+
+type AnnotationV1 struct {
+	Name string `json:"name"`
+	From int64  `json:"from"`
+	To   int64  `json:"to"`
+}
+
+// TableV1 is a table with cells rendered as text.
+type TableV1 struct {
+	Header []string   `json:"header"`
+	Rows   [][]string `json:"rows"`
+}
+
+// RenderV1 converts the audit reports of the application to the v1 API representation.
+// If reportName is not empty, only the report with that name is returned.
+// If summaryOnly is set, the series contain only the stats without the values.
+func RenderV1(w *model.World, app *model.Application, reportName string, summaryOnly bool) *ReportsV1 {
+	res := &ReportsV1{
+		Version: ApiVersion,
+		Application: ApplicationV1{
+			Id:        app.Id.String(),
+			Namespace: app.Id.Namespace,
+			Kind:      string(app.Id.Kind),
+			Name:      app.Id.Name,
+			Status:    app.Status.String(),
+		},
+		From:    int64(w.Ctx.From),
+		To:      int64(w.Ctx.To),
+		Step:    int64(w.Ctx.Step),
+		Reports: []AuditReportV1{},
+	}
+	for _, r := range app.Reports {
+		if reportName != "" && string(r.Name) != reportName {
+			continue
+		}
+		res.Reports = append(res.Reports, reportV1(r, summaryOnly))
+	}
+	return res
+}
+
+func reportV1(r *model.AuditReport, summaryOnly bool) AuditReportV1 {
+	res := AuditReportV1{
+		Name:   string(r.Name),
+		Status: r.Status.String(),
+		Checks: []CheckV1{},
+		Charts: []ChartV1{},
+		Tables: []TableV1{},
+	}
+	for _, ch := range r.Checks {
+		res.Checks = append(res.Checks, CheckV1{
+			Id:          string(ch.Id),
+			Title:       ch.Title,
+			Status:      ch.Status.String(),
+			Message:     ch.Message,
+			Threshold:   ch.Threshold,
+			Unit:        string(ch.Unit),
+			Muted:       ch.Muted,
+			Maintenance: ch.Maintenance,
+			Suppressed:  ch.SuppressedBy != "",
+		})
+	}
+	for _, w := range r.Widgets {
+		switch {
+		case w.Chart != nil:
+			if !w.Chart.IsEmpty() {
+				res.Charts = append(res.Charts, chartV1(w.Chart, "", summaryOnly))
+			}
+		case w.ChartGroup != nil:
+			for _, ch := range w.ChartGroup.Charts {
+				if !ch.IsEmpty() {
+					res.Charts = append(res.Charts, chartV1(ch, w.ChartGroup.ChartTitle(ch), summaryOnly))
+				}
+			}
+		case w.Table != nil:
+			t := TableV1{Header: w.Table.Header, Rows: [][]string{}}
+			for _, row := range w.Table.Rows {
+				cells := make([]string, 0, len(row.Cells))
+				for _, c := range row.Cells {
+					if c == nil {
+						cells = append(cells, "")
+						continue
+					}
+					cells = append(cells, cellText(c))
+				}
+				t.Rows = append(t.Rows, cells)
+			}
+			res.Tables = append(res.Tables, t)
+		}
+	}
+	return res
+}
+
+func chartV1(chart *model.Chart, groupTitle string, summaryOnly bool) ChartV1 {
+	res := ChartV1{
+		Title:       chart.Title,
+		Stacked:     chart.IsStacked,
+		Heatmap:     chart.IsHeatmap,
+		Series:      []SeriesV1{},
+		Annotations: []AnnotationV1{},
+	}
+	if groupTitle != "" {
+		res.Title, res.Group = groupTitle, chart.Title
+	}
+	for _, s := range chart.Series.Get() {
+		res.Series = append(res.Series, seriesV1(s.Name, s.Data.Get(), summaryOnly))
+	}
+	if chart.Threshold != nil {
+		t := seriesV1(chart.Threshold.Name, chart.Threshold.Data.Get(), summaryOnly)
+		res.Threshold = &t
+	}
+	for _, a := range chart.Annotations {
+		res.Annotations = append(res.Annotations, AnnotationV1{Name: a.Name, From: int64(a.X1), To: int64(a.X2)})
+	}
+	return res
+}
+
+func seriesV1(name string, ts *timeseries.TimeSeries, summaryOnly bool) SeriesV1 {
+	stats := model.NewSeriesStats(ts)
+	res := SeriesV1{
+		Name:   name,
+		Values: []*float32{},
+		Stats:  SeriesStatsV1{Min: stats.Min, Max: stats.Max, Avg: stats.Avg, Last: stats.Last},
+	}
+	iter := ts.Iter()
+	first := true
+	var prev timeseries.Time
+	for iter.Next() {
+		t, v := iter.Value()
+		if first {
+			res.From = int64(t)
+			first = false
+		} else if res.Step == 0 {
+			res.Step = int64(t.Sub(prev))
+		}
+		prev = t
+		if summaryOnly {
+			continue
+		}
+		if timeseries.IsNaN(v) || timeseries.IsInf(v, 0) {
+			res.Values = append(res.Values, nil)
+			continue
+		}
+		res.Values = append(res.Values, &v)
+	}
+	return res
+}
+
+// DeploymentVerdictV1 is the verdict on the latest deployment of an application
+// (GET /api/v1/project/{project}/app/{app}/deployment/verdict) or on the given one
+// (GET /api/v1/project/{project}/app/{app}/deployments/{id}/verdict). Verdict is one of: pass, fail, inconclusive.
+// State is one of: pending, in-progress, stuck, cancelled, deployed, summary, rollback-recommended.
+type DeploymentVerdictV1 struct {
+	Version     string   `json:"version"`
+	Application string   `json:"application"`
+	Deployment  string   `json:"deployment"`
+	StartedAt   int64    `json:"started_at"`
+	State       string   `json:"state"`
+	Verdict     string   `json:"verdict"`
+	Reasons     []string `json:"reasons"`
+}
+
+func RenderDeploymentVerdictV1(app *model.Application, ds model.ApplicationDeploymentStatus) *DeploymentVerdictV1 {
+	verdict, reasons := ds.Verdict()
+	res := &DeploymentVerdictV1{
+		Version:     ApiVersion,
+		Application: app.Id.String(),
+		Deployment:  ds.Deployment.Name,
+		StartedAt:   int64(ds.Deployment.StartedAt),
+		State:       deploymentStateV1(ds.State),
+		Verdict:     string(verdict),
+		Reasons:     reasons,
+	}
+	if res.Reasons == nil {
+		res.Reasons = []string{}
+	}
+	return res
+}
+
+// RenderPendingDeploymentVerdictV1 renders the verdict on a deployment that hasn't been discovered yet.
+func RenderPendingDeploymentVerdictV1(app *model.Application, deploymentId string) *DeploymentVerdictV1 {
+	return &DeploymentVerdictV1{
+		Version:     ApiVersion,
+		Application: app.Id.String(),
+		Deployment:  deploymentId,
+		State:       "pending",
+		Verdict:     string(model.DeploymentVerdictInconclusive),
+		Reasons:     []string{"The deployment hasn't been discovered yet"},
+	}
+}
+
+func deploymentStateV1(s model.ApplicationDeploymentState) string {
+	switch s {
+	case model.ApplicationDeploymentStateInProgress:
+		return "in-progress"
+	case model.ApplicationDeploymentStateStuck:
+		return "stuck"
+	case model.ApplicationDeploymentStateCancelled:
+		return "cancelled"
+	case model.ApplicationDeploymentStateDeployed:
+		return "deployed"
+	case model.ApplicationDeploymentStateSummary:
+		return "summary"
+	case model.ApplicationDeploymentStateRollbackRecommended:
+		return "rollback-recommended"
+	}
+	return "started"
+}
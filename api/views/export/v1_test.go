@@ -0,0 +1,123 @@
+package export
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRenderV1(t *testing.T) {
+	w := model.NewWorld(0, 240, 60)
+	app := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"))
+	app.Status = model.WARNING
+
+	cpu := model.NewAuditReport(app, w.Ctx, nil, model.AuditReportCPU)
+	cpu.CreateCheck(model.Checks.CPUNode).SetStatus(model.WARNING, "high CPU utilization")
+	cpu.GetOrCreateChart("CPU usage, cores").
+		AddSeries("cart-1", timeseries.NewWithData(0, 60, []float32{1, timeseries.NaN, 3, 2})).
+		AddAnnotation(model.Annotation{Name: "deployment", X1: 60, X2: 120})
+	cpu.GetOrCreateChart("empty")
+	cpu.GetOrCreateChartGroup("RTT to <selector>, seconds").GetOrCreateChart(w.Ctx, "db").
+		AddSeries("rtt", timeseries.NewWithData(0, 60, []float32{0.1, 0.1, 0.1, 0.1}))
+	cpu.GetOrCreateTable("Container", "Usage").AddRow(model.NewTableCell("cart-1"), nil)
+	memory := model.NewAuditReport(app, w.Ctx, nil, model.AuditReportMemory)
+	app.Reports = []*model.AuditReport{cpu, memory}
+
+	res := RenderV1(w, app, "", false)
+	assert.Equal(t, ApiVersion, res.Version)
+	assert.Equal(t, ApplicationV1{Id: app.Id.String(), Namespace: "shop", Kind: "Deployment", Name: "cart", Status: "warning"}, res.Application)
+	assert.Equal(t, int64(60), res.Step)
+	require.Len(t, res.Reports, 2)
+
+	r := res.Reports[0]
+	assert.Equal(t, string(model.AuditReportCPU), r.Name)
+	require.Len(t, r.Checks, 1)
+	assert.Equal(t, "high CPU utilization", r.Checks[0].Message)
+	require.Len(t, r.Charts, 2, "empty charts must be skipped")
+	ch := r.Charts[0]
+	assert.Equal(t, "CPU usage, cores", ch.Title)
+	assert.Equal(t, []AnnotationV1{{Name: "deployment", From: 60, To: 120}}, ch.Annotations)
+	require.Len(t, ch.Series, 1)
+	s := ch.Series[0]
+	assert.Equal(t, int64(0), s.From)
+	assert.Equal(t, int64(60), s.Step)
+	require.Len(t, s.Values, 4)
+	assert.Nil(t, s.Values[1])
+	assert.Equal(t, float32(3), *s.Values[2])
+	assert.Equal(t, float32(3), *s.Stats.Max)
+	assert.Equal(t, float32(2), *s.Stats.Last)
+	assert.Equal(t, "RTT to db, seconds", r.Charts[1].Title)
+	assert.Equal(t, "db", r.Charts[1].Group)
+	assert.Equal(t, []TableV1{{Header: []string{"Container", "Usage"}, Rows: [][]string{{"cart-1", ""}}}}, r.Tables)
+
+	// empty lists are rendered as [], not null
+	data, err := json.Marshal(res.Reports[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Memory","status":"unknown","checks":[],"charts":[],"tables":[]}`, string(data))
+
+	res = RenderV1(w, app, string(model.AuditReportMemory), true)
+	require.Len(t, res.Reports, 1)
+	assert.Equal(t, string(model.AuditReportMemory), res.Reports[0].Name)
+
+	res = RenderV1(w, app, string(model.AuditReportCPU), true)
+	require.Len(t, res.Reports, 1)
+	s = res.Reports[0].Charts[0].Series[0]
+	assert.Empty(t, s.Values)
+	assert.Equal(t, int64(60), s.Step)
+	assert.Equal(t, float32(2), *s.Stats.Avg)
+
+	assert.Empty(t, RenderV1(w, app, "unknown", false).Reports)
+}
+
+func TestRenderDeploymentVerdictV1(t *testing.T) {
+	app := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"))
+	d := &model.ApplicationDeployment{ApplicationId: app.Id, Name: "cart-5d8f7c9b4", StartedAt: 1700000000}
+
+	for _, c := range []struct {
+		name    string
+		status  model.ApplicationDeploymentStatus
+		state   string
+		verdict string
+		reasons []string
+	}{
+		{
+			name:    "no canary data",
+			status:  model.ApplicationDeploymentStatus{State: model.ApplicationDeploymentStateInProgress, Deployment: d},
+			state:   "in-progress",
+			verdict: "inconclusive",
+			reasons: []string{"Not enough data to compare the new version with the previous one"},
+		},
+		{
+			name:    "stuck",
+			status:  model.ApplicationDeploymentStatus{State: model.ApplicationDeploymentStateStuck, Message: "stuck for 10m", Deployment: d},
+			state:   "stuck",
+			verdict: "fail",
+			reasons: []string{"stuck for 10m"},
+		},
+		{
+			name:    "summary",
+			status:  model.ApplicationDeploymentStatus{State: model.ApplicationDeploymentStateSummary, Deployment: d},
+			state:   "summary",
+			verdict: "pass",
+			reasons: []string{},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			v := RenderDeploymentVerdictV1(app, c.status)
+			assert.Equal(t, ApiVersion, v.Version)
+			assert.Equal(t, app.Id.String(), v.Application)
+			assert.Equal(t, "cart-5d8f7c9b4", v.Deployment)
+			assert.Equal(t, int64(1700000000), v.StartedAt)
+			assert.Equal(t, c.state, v.State)
+			assert.Equal(t, c.verdict, v.Verdict)
+			assert.Equal(t, c.reasons, v.Reasons)
+		})
+	}
+
+	v := RenderPendingDeploymentVerdictV1(app, "cart-7b6c5d4e3")
+	assert.Equal(t, "pending", v.State)
+	assert.Equal(t, "inconclusive", v.Verdict)
+}
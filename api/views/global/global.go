@@ -0,0 +1,105 @@
+package global
+
+import (
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"sort"
+)
+
+type View struct {
+	Projects     []Project      `json:"projects"`
+	Applications []*Application `json:"applications"`
+}
+
+type Project struct {
+	Id   db.ProjectId `json:"id"`
+	Name string       `json:"name"`
+}
+
+// Application is a service matched across the projects. Key is the name of the application, or the value of the label
+// used for matching. Instances contains the application from each project it's found in.
+type Application struct {
+	Key       string                `json:"key"`
+	Status    model.Status          `json:"status"`
+	Instances []*ProjectApplication `json:"instances"`
+}
+
+type ProjectApplication struct {
+	ProjectId  db.ProjectId        `json:"project_id"`
+	Id         model.ApplicationId `json:"id"`
+	Status     model.Status        `json:"status"`
+	Indicators []model.Indicator   `json:"indicators"`
+}
+
+// Summary contains the applications of a project with the keys they are matched by.
+// It doesn't depend on the other projects, so it can be cached per project.
+type Summary struct {
+	Project      Project              `json:"project"`
+	Applications []SummaryApplication `json:"applications"`
+}
+
+type SummaryApplication struct {
+	Key string `json:"key"`
+	ProjectApplication
+}
+
+// Summarize returns the summary of an audited world. If label is empty, applications are matched by name,
+// otherwise by the value of the label (applications without the label are skipped).
+func Summarize(project *db.Project, world *model.World, label string) *Summary {
+	s := &Summary{Project: Project{Id: project.Id, Name: project.Name}, Applications: []SummaryApplication{}}
+	for _, app := range world.Applications {
+		key := app.Id.Name
+		if label != "" {
+			key = app.Labels()[label]
+		}
+		if key == "" {
+			continue
+		}
+		s.Applications = append(s.Applications, SummaryApplication{
+			Key: key,
+			ProjectApplication: ProjectApplication{
+				ProjectId:  project.Id,
+				Id:         app.Id,
+				Status:     app.Status,
+				Indicators: model.CalcIndicators(app),
+			},
+		})
+	}
+	return s
+}
+
+// Render merges the applications of the projects by their keys.
+func Render(summaries []*Summary) *View {
+	v := &View{Projects: []Project{}, Applications: []*Application{}}
+	byKey := map[string]*Application{}
+	for _, s := range summaries {
+		v.Projects = append(v.Projects, s.Project)
+		for i := range s.Applications {
+			app := &s.Applications[i]
+			a := byKey[app.Key]
+			if a == nil {
+				a = &Application{Key: app.Key}
+				byKey[app.Key] = a
+				v.Applications = append(v.Applications, a)
+			}
+			if app.Status > a.Status {
+				a.Status = app.Status
+			}
+			a.Instances = append(a.Instances, &app.ProjectApplication)
+		}
+	}
+	sort.Slice(v.Projects, func(i, j int) bool {
+		return v.Projects[i].Name < v.Projects[j].Name
+	})
+	sort.Slice(v.Applications, func(i, j int) bool {
+		a, b := v.Applications[i], v.Applications[j]
+		if len(a.Instances) > 1 != (len(b.Instances) > 1) {
+			return len(a.Instances) > 1
+		}
+		if a.Status != b.Status {
+			return a.Status > b.Status
+		}
+		return a.Key < b.Key
+	})
+	return v
+}
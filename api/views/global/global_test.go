@@ -0,0 +1,45 @@
+package global
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	world := func(apps ...string) *model.World {
+		w := model.NewWorld(0, 0, 0)
+		for _, name := range apps {
+			w.Applications = append(w.Applications, model.NewApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, name)))
+		}
+		return w
+	}
+	prodWorld, stagingWorld := world("cart", "orders"), world("cart")
+	prodWorld.Applications[0].Status = model.WARNING
+	stagingWorld.Applications[0].Status = model.CRITICAL
+	prod := Summarize(&db.Project{Id: "p1", Name: "prod"}, prodWorld, "")
+	staging := Summarize(&db.Project{Id: "p2", Name: "staging"}, stagingWorld, "")
+
+	// summaries are cached as JSON
+	data, err := json.Marshal(prod)
+	require.NoError(t, err)
+	var cached Summary
+	require.NoError(t, json.Unmarshal(data, &cached))
+
+	v := Render([]*Summary{staging, &cached})
+	assert.Equal(t, []Project{{Id: "p1", Name: "prod"}, {Id: "p2", Name: "staging"}}, v.Projects)
+	require.Len(t, v.Applications, 2)
+	assert.Equal(t, "cart", v.Applications[0].Key)
+	assert.Equal(t, model.CRITICAL, v.Applications[0].Status)
+	require.Len(t, v.Applications[0].Instances, 2)
+	assert.Equal(t, db.ProjectId("p2"), v.Applications[0].Instances[0].ProjectId)
+	assert.Equal(t, db.ProjectId("p1"), v.Applications[0].Instances[1].ProjectId)
+	assert.Equal(t, model.WARNING, v.Applications[0].Instances[1].Status)
+	assert.Equal(t, "orders", v.Applications[1].Key)
+
+	// applications without the label are skipped
+	assert.Empty(t, Summarize(&db.Project{Id: "p1"}, world("cart"), "team").Applications)
+}
@@ -0,0 +1,227 @@
+// Package grafana implements a Grafana JSON datasource (the SimpleJSON protocol, also supported by the JSON datasource plugin),
+// so that existing dashboards can chart the data computed by Coroot alongside the raw metrics.
+//
+// A target refers to the data of an application:
+//
+//	<app id> / check / <check id>            the value of the check (a single point at the end of the time range)
+//	<app id> / slo / <availability|latency>  the error budget burn rate of the SLO
+//	<app id> / <report> / <chart title>      the series of the chart
+package grafana
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"sort"
+	"strings"
+)
+
+const (
+	separator = " / "
+
+	maxTargets = 1000
+)
+
+type Target struct {
+	Target string `json:"target"`
+	RefId  string `json:"refId"`
+	Hide   bool   `json:"hide"`
+}
+
+// Series is a time series in the SimpleJSON format: each datapoint is [value, timestamp in milliseconds], the value is null if there's no data.
+type Series struct {
+	Target     string   `json:"target"`
+	RefId      string   `json:"refId,omitempty"`
+	Datapoints [][2]any `json:"datapoints"`
+}
+
+type Metric struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Targets returns the targets containing the search string (at most maxTargets, sorted).
+func Targets(w *model.World, search string) []string {
+	search = strings.ToLower(search)
+	var res []string
+	add := func(parts ...string) {
+		t := strings.Join(parts, separator)
+		if search == "" || strings.Contains(strings.ToLower(t), search) {
+			res = append(res, t)
+		}
+	}
+	for _, app := range w.Applications {
+		id := app.Id.String()
+		for _, r := range app.Reports {
+			for _, ch := range r.Checks {
+				if _, ok := ch.Value(); ok {
+					add(id, "check", string(ch.Id))
+				}
+			}
+			for _, ch := range charts(r) {
+				add(id, string(r.Name), ch.title)
+			}
+		}
+		if len(app.AvailabilitySLIs) > 0 {
+			add(id, "slo", "availability")
+		}
+		if len(app.LatencySLIs) > 0 {
+			add(id, "slo", "latency")
+		}
+	}
+	sort.Strings(res)
+	if len(res) > maxTargets {
+		res = res[:maxTargets]
+	}
+	return res
+}
+
+// Query returns the series of the targets, the unknown targets are skipped.
+func Query(w *model.World, targets []Target) []Series {
+	res := []Series{}
+	for _, t := range targets {
+		if t.Hide {
+			continue
+		}
+		parts := strings.SplitN(t.Target, separator, 3)
+		if len(parts) < 3 {
+			continue
+		}
+		id, err := model.NewApplicationIdFromString(parts[0])
+		if err != nil {
+			continue
+		}
+		app := w.GetApplication(id)
+		if app == nil {
+			continue
+		}
+		add := func(name string, ts *timeseries.TimeSeries) {
+			res = append(res, Series{Target: name, RefId: t.RefId, Datapoints: datapoints(ts)})
+		}
+		switch kind, name := parts[1], parts[2]; kind {
+		case "check":
+			if ch := findCheck(app, model.CheckId(name)); ch != nil {
+				if v, ok := ch.Value(); ok {
+					res = append(res, Series{Target: t.Target, RefId: t.RefId, Datapoints: [][2]any{point(w.Ctx.To, v)}})
+				}
+			}
+		case "slo":
+			if ts := burnRate(app, name); ts != nil {
+				add(t.Target, ts)
+			}
+		default:
+			for _, r := range app.Reports {
+				if string(r.Name) != kind {
+					continue
+				}
+				for _, ch := range charts(r) {
+					if ch.title != name {
+						continue
+					}
+					for _, s := range ch.chart.Series.Get() {
+						add(app.Id.Name+": "+s.Name, s.Data.Get())
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+type chart struct {
+	title string
+	chart *model.Chart
+}
+
+func charts(r *model.AuditReport) []chart {
+	var res []chart
+	for _, w := range r.Widgets {
+		switch {
+		case w.Chart != nil:
+			if !w.Chart.IsEmpty() {
+				res = append(res, chart{title: w.Chart.Title, chart: w.Chart})
+			}
+		case w.ChartGroup != nil:
+			for _, ch := range w.ChartGroup.Charts {
+				if !ch.IsEmpty() {
+					res = append(res, chart{title: w.ChartGroup.ChartTitle(ch), chart: ch})
+				}
+			}
+		}
+	}
+	return res
+}
+
+func findCheck(app *model.Application, id model.CheckId) *model.Check {
+	for _, r := range app.Reports {
+		for _, ch := range r.Checks {
+			if ch.Id == id {
+				return ch
+			}
+		}
+	}
+	return nil
+}
+
+// burnRate returns the error budget burn rate of the SLO at each point: the ratio of the bad requests to the total
+// divided by the ratio allowed by the objective (1 means the budget is consumed exactly within the compliance period).
+func burnRate(app *model.Application, slo string) *timeseries.TimeSeries {
+	var bad, total *timeseries.TimeSeries
+	var objectivePercentage float32
+	switch slo {
+	case "availability":
+		if len(app.AvailabilitySLIs) == 0 {
+			return nil
+		}
+		sli := app.AvailabilitySLIs[0]
+		total, bad = sli.GetTotalAndFailed(false)
+		objectivePercentage = sli.Config.ObjectivePercentage
+	case "latency":
+		if len(app.LatencySLIs) == 0 {
+			return nil
+		}
+		sli := app.LatencySLIs[0]
+		var fast *timeseries.TimeSeries
+		total, fast = sli.GetTotalAndFast(false)
+		bad = timeseries.Sub(total, fast)
+		objectivePercentage = sli.Config.ObjectivePercentage
+	default:
+		return nil
+	}
+	if total.IsEmpty() {
+		return nil
+	}
+	objective := 1 - objectivePercentage/100
+	return timeseries.Aggregate2(bad, total, func(b, t float32) float32 {
+		if t == 0 {
+			return 0
+		}
+		return b / t / objective
+	})
+}
+
+func datapoints(ts *timeseries.TimeSeries) [][2]any {
+	res := [][2]any{}
+	iter := ts.Iter()
+	for iter.Next() {
+		t, v := iter.Value()
+		res = append(res, point(t, v))
+	}
+	return res
+}
+
+func point(t timeseries.Time, v float32) [2]any {
+	ms := int64(t) * 1000
+	if timeseries.IsNaN(v) || timeseries.IsInf(v, 0) {
+		return [2]any{nil, ms}
+	}
+	return [2]any{v, ms}
+}
+
+// Metrics returns the targets in the format of the /metrics method of the JSON datasource plugin.
+func Metrics(w *model.World, search string) []Metric {
+	res := []Metric{}
+	for _, t := range Targets(w, search) {
+		res = append(res, Metric{Label: t, Value: t})
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+package grafana
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	w := model.NewWorld(0, 120, 60)
+	app := w.GetOrCreateApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog"))
+	app.AvailabilitySLIs = append(app.AvailabilitySLIs, &model.AvailabilitySLI{
+		Config:         model.CheckConfigSLOAvailability{ObjectivePercentage: 75},
+		TotalRequests:  timeseries.NewWithData(0, 60, []float32{100, 100, 0}),
+		FailedRequests: timeseries.NewWithData(0, 60, []float32{25, 50, timeseries.NaN}),
+	})
+	report := model.NewAuditReport(app, w.Ctx, nil, model.AuditReportSLO)
+	report.GetOrCreateChart("Requests, per second").AddSeries("total", app.AvailabilitySLIs[0].TotalRequests)
+	report.CreateCheck(model.Checks.SLOErrorBudget).SetValue(42)
+	app.Reports = append(app.Reports, report)
+
+	id := app.Id.String()
+	assert.Equal(t, []string{
+		id + " / SLO / Requests, per second",
+		id + " / check / SLOErrorBudget",
+		id + " / slo / availability",
+	}, Targets(w, ""))
+	assert.Equal(t, []string{id + " / slo / availability"}, Targets(w, "AVAIL"))
+
+	res := Query(w, []Target{
+		{Target: id + " / slo / availability", RefId: "A"},
+		{Target: id + " / check / SLOErrorBudget", RefId: "B"},
+		{Target: id + " / SLO / Requests, per second", RefId: "C"},
+		{Target: id + " / SLO / unknown", RefId: "D"},
+		{Target: "invalid", RefId: "E"},
+	})
+	data, err := json.Marshal(res)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"target": "`+id+` / slo / availability", "refId": "A", "datapoints": [[1, 0], [2, 60000], [0, 120000]]},
+		{"target": "`+id+` / check / SLOErrorBudget", "refId": "B", "datapoints": [[42, 120000]]},
+		{"target": "catalog: total", "refId": "C", "datapoints": [[100, 0], [100, 60000], [0, 120000]]}
+	]`, string(data))
+}
@@ -0,0 +1,167 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/model"
+)
+
+type objectType struct {
+	name   string
+	fields map[string]*fieldDef
+}
+
+// fieldDef describes a field of an object type. If typ is nil, the field is a scalar.
+// List fields resolve to []any.
+type fieldDef struct {
+	typ     *objectType
+	resolve func(src any, args arguments) any
+}
+
+type arguments map[string]any
+
+func (a arguments) string(name string) string {
+	s, _ := a[name].(string)
+	return s
+}
+
+func (a arguments) int(name string, def int) int {
+	if n, ok := a[name].(float64); ok {
+		return int(n)
+	}
+	return def
+}
+
+// object is the result of a selection set, it's marshaled to a JSON object with the keys in the order of the selections.
+type object []objectField
+
+type objectField struct {
+	key   string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type executor struct {
+	variables map[string]any
+}
+
+func (e *executor) object(selections []*field, typ *objectType, src any) (object, error) {
+	res := make(object, 0, len(selections))
+	for _, f := range selections {
+		if f.name == "__typename" {
+			res = append(res, objectField{key: f.key(), value: typ.name})
+			continue
+		}
+		def := typ.fields[f.name]
+		if def == nil {
+			return nil, fmt.Errorf("unknown field %q of type %s", f.name, typ.name)
+		}
+		args := arguments{}
+		for name, v := range f.args {
+			args[name] = e.value(v)
+		}
+		v, err := e.field(f, def, def.resolve(src, args))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, objectField{key: f.key(), value: v})
+	}
+	return res, nil
+}
+
+func (e *executor) field(f *field, def *fieldDef, v any) (any, error) {
+	if def.typ == nil {
+		if f.selections != nil {
+			return nil, fmt.Errorf("field %q is a scalar and can't have a selection set", f.name)
+		}
+		return v, nil
+	}
+	if f.selections == nil {
+		return nil, fmt.Errorf("field %q of type %s must have a selection set", f.name, def.typ.name)
+	}
+	if list, ok := v.([]any); ok {
+		res := make([]any, 0, len(list))
+		for _, item := range list {
+			o, err := e.item(f, def.typ, item)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, o)
+		}
+		return res, nil
+	}
+	return e.item(f, def.typ, v)
+}
+
+func (e *executor) item(f *field, typ *objectType, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return e.object(f.selections, typ, v)
+}
+
+func (e *executor) value(v value) any {
+	switch {
+	case v.variable != "":
+		return e.variables[v.variable]
+	case v.isList:
+		res := make([]any, 0, len(v.list))
+		for _, item := range v.list {
+			res = append(res, e.value(item))
+		}
+		return res
+	}
+	return v.literal
+}
+
+type Response struct {
+	Data   object  `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+type Error struct {
+	Message string `json:"message"`
+}
+
+// Execute runs a GraphQL query against the world. The world must be audited to have the statuses and the checks.
+func Execute(w *model.World, query string, variables map[string]any) *Response {
+	op, err := parse(query)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	e := &executor{variables: map[string]any{}}
+	for name, v := range op.variableDefaults {
+		e.variables[name] = v
+	}
+	for name, v := range variables {
+		e.variables[name] = v
+	}
+	data, err := e.object(op.selections, queryType, w)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	return &Response{Data: data}
+}
@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	w := model.NewWorld(0, 0, 0)
+	app := w.GetOrCreateApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog"))
+	app.Status = model.WARNING
+	app.GetOrCreateInstance("catalog-1", nil)
+	w.GetOrCreateApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "front")).Status = model.OK
+
+	query := `query Apps($status: String = "ok") {
+		# applications with problems
+		apps: applications(status: $status, namespace: "default") { name status instances { name } }
+	}`
+	res := Execute(w, query, map[string]any{"status": "warning"})
+	assert.Empty(t, res.Errors)
+	data, err := json.Marshal(res)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data": {"apps": [{"name": "catalog", "status": "warning", "instances": [{"name": "catalog-1"}]}]}}`, string(data))
+
+	res = Execute(w, `{ applications { __typename id } }`, nil)
+	assert.Empty(t, res.Errors)
+	data, _ = json.Marshal(res)
+	assert.Contains(t, string(data), `"__typename":"Application"`)
+
+	for _, q := range []string{`{ applications }`, `{ applications { name { x } } }`, `{ unknown }`, `mutation { x }`, `{ applications { ...f } }`, `{ applications { name }`} {
+		assert.NotEmpty(t, Execute(w, q, nil).Errors, q)
+	}
+}
@@ -0,0 +1,351 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The parser supports a subset of the GraphQL query language: a single query operation with variables,
+// aliases, arguments, and nested selection sets. Fragments, directives, and mutations are not supported.
+
+type field struct {
+	alias      string
+	name       string
+	args       map[string]value
+	selections []*field
+}
+
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type value struct {
+	variable string
+	literal  any
+	list     []value
+	isList   bool
+}
+
+type operation struct {
+	variableDefaults map[string]any
+	selections       []*field
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenString
+	tokenNumber
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(src string) ([]token, error) {
+	var res []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r) || r == ',' || r == '\ufeff':
+			i++
+		case r == '#':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case strings.ContainsRune("{}()[]:$!=@", r):
+			res = append(res, token{kind: tokenPunct, text: string(r), pos: i})
+			i++
+		case r == '.':
+			return nil, fmt.Errorf("fragments are not supported (position %d)", i)
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(rs) && (rs[i] == '_' || unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i])) {
+				i++
+			}
+			res = append(res, token{kind: tokenName, text: string(rs[start:i]), pos: start})
+		case r == '-' || unicode.IsDigit(r):
+			start := i
+			i++
+			for i < len(rs) && (unicode.IsDigit(rs[i]) || strings.ContainsRune(".eE+-", rs[i])) {
+				i++
+			}
+			res = append(res, token{kind: tokenNumber, text: string(rs[start:i]), pos: start})
+		case r == '"':
+			start := i
+			i++
+			var sb strings.Builder
+			for ; i < len(rs) && rs[i] != '"'; i++ {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					i++
+					switch rs[i] {
+					case 'n':
+						sb.WriteRune('\n')
+					case 't':
+						sb.WriteRune('\t')
+					default:
+						sb.WriteRune(rs[i])
+					}
+					continue
+				}
+				sb.WriteRune(rs[i])
+			}
+			if i >= len(rs) {
+				return nil, fmt.Errorf("unterminated string (position %d)", start)
+			}
+			i++
+			res = append(res, token{kind: tokenString, text: sb.String(), pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q (position %d)", r, i)
+		}
+	}
+	return append(res, token{kind: tokenEOF, pos: len(rs)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(src string) (*operation, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	op := &operation{variableDefaults: map[string]any{}}
+	if t := p.peek(); t.kind == tokenName {
+		switch t.text {
+		case "query":
+			p.next()
+		case "mutation", "subscription":
+			return nil, fmt.Errorf("%s operations are not supported", t.text)
+		case "fragment":
+			return nil, fmt.Errorf("fragments are not supported")
+		default:
+			return nil, p.unexpected()
+		}
+		if p.peek().kind == tokenName {
+			p.next()
+		}
+		if p.is("(") {
+			if err := p.variableDefinitions(op); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if op.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("only a single operation is supported (position %d)", p.peek().pos)
+	}
+	return op, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) is(punct string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.text == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(punct) {
+		return p.unexpected()
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if t.kind != tokenName {
+		return "", p.unexpected()
+	}
+	p.next()
+	return t.text, nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of query")
+	}
+	return fmt.Errorf("unexpected %q (position %d)", t.text, t.pos)
+}
+
+func (p *parser) variableDefinitions(op *operation) error {
+	p.next()
+	for !p.is(")") {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err = p.expect(":"); err != nil {
+			return err
+		}
+		if err = p.skipType(); err != nil {
+			return err
+		}
+		if p.is("=") {
+			p.next()
+			v, err := p.value()
+			if err != nil {
+				return err
+			}
+			op.variableDefaults[name] = v.literal
+		}
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) skipType() error {
+	if p.is("[") {
+		p.next()
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.is("!") {
+		p.next()
+	}
+	return nil
+}
+
+func (p *parser) selectionSet() ([]*field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var res []*field
+	for !p.is("}") {
+		if p.is("@") {
+			return nil, fmt.Errorf("directives are not supported (position %d)", p.peek().pos)
+		}
+		f := &field{}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if p.is(":") {
+			p.next()
+			f.alias = name
+			if name, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		f.name = name
+		if p.is("(") {
+			if f.args, err = p.arguments(); err != nil {
+				return nil, err
+			}
+		}
+		if p.is("{") {
+			if f.selections, err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, f)
+	}
+	p.next()
+	if len(res) == 0 {
+		return nil, fmt.Errorf("empty selection set")
+	}
+	return res, nil
+}
+
+func (p *parser) arguments() (map[string]value, error) {
+	p.next()
+	res := map[string]value{}
+	for !p.is(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		if res[name], err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+	p.next()
+	return res, nil
+}
+
+func (p *parser) value() (value, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenPunct && t.text == "$":
+		p.next()
+		name, err := p.name()
+		return value{variable: name}, err
+	case t.kind == tokenPunct && t.text == "[":
+		p.next()
+		v := value{isList: true}
+		for !p.is("]") {
+			item, err := p.value()
+			if err != nil {
+				return v, err
+			}
+			v.list = append(v.list, item)
+		}
+		p.next()
+		return v, nil
+	case t.kind == tokenString:
+		p.next()
+		return value{literal: t.text}, nil
+	case t.kind == tokenNumber:
+		p.next()
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return value{literal: float64(n)}, nil
+		}
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return value{}, fmt.Errorf("invalid number %q (position %d)", t.text, t.pos)
+		}
+		return value{literal: n}, nil
+	case t.kind == tokenName:
+		p.next()
+		switch t.text {
+		case "true":
+			return value{literal: true}, nil
+		case "false":
+			return value{literal: false}, nil
+		case "null":
+			return value{}, nil
+		}
+		return value{literal: t.text}, nil
+	}
+	return value{}, p.unexpected()
+}
@@ -0,0 +1,198 @@
+package graphql
+
+import (
+	"github.com/coroot/coroot/model"
+)
+
+// The schema:
+//
+//	type Query {
+//	  applications(id: String, namespace: String, kind: String, name: String, category: String, status: String, limit: Int): [Application]
+//	  application(id: String!): Application
+//	}
+//	type Application {
+//	  id: String, namespace: String, kind: String, name: String, category: String, status: String
+//	  instances: [Instance], reports(name: String): [Report], checks(status: String): [Check], deployments: [Deployment]
+//	}
+//	type Instance { name: String, node: String, up: Boolean, failed: Boolean, obsolete: Boolean }
+//	type Report { name: String, status: String, checks(status: String): [Check] }
+//	type Check {
+//	  id: String, title: String, report: String, status: String, message: String, items: [String]
+//	  threshold: Float, unit: String, value: Float, muted: Boolean, suppressed_by: String
+//	}
+//	type Deployment { name: String, version: String, started_at: Int, finished_at: Int, status: String, message: String, summary: [String] }
+//
+// The status arguments filter out the items with lower statuses (e.g., status: "warning" returns the failed checks).
+
+type application struct {
+	w   *model.World
+	app *model.Application
+}
+
+type check struct {
+	report model.AuditReportName
+	ch     *model.Check
+}
+
+var (
+	queryType       = &objectType{name: "Query"}
+	applicationType = &objectType{name: "Application"}
+	instanceType    = &objectType{name: "Instance"}
+	reportType      = &objectType{name: "Report"}
+	checkType       = &objectType{name: "Check"}
+	deploymentType  = &objectType{name: "Deployment"}
+)
+
+func init() {
+	queryType.fields = map[string]*fieldDef{
+		"applications": {typ: applicationType, resolve: func(src any, args arguments) any {
+			w := src.(*model.World)
+			limit := args.int("limit", 0)
+			min := minStatus(args)
+			res := []any{}
+			for _, app := range w.Applications {
+				if limit > 0 && len(res) >= limit {
+					break
+				}
+				if !matches(args.string("id"), app.Id.String()) || !matches(args.string("namespace"), app.Id.Namespace) ||
+					!matches(args.string("kind"), string(app.Id.Kind)) || !matches(args.string("name"), app.Id.Name) ||
+					!matches(args.string("category"), string(app.Category)) || app.Status < min {
+					continue
+				}
+				res = append(res, &application{w: w, app: app})
+			}
+			return res
+		}},
+		"application": {typ: applicationType, resolve: func(src any, args arguments) any {
+			w := src.(*model.World)
+			id, err := model.NewApplicationIdFromString(args.string("id"))
+			if err != nil {
+				return nil
+			}
+			app := w.GetApplication(id)
+			if app == nil {
+				return nil
+			}
+			return &application{w: w, app: app}
+		}},
+	}
+
+	applicationType.fields = map[string]*fieldDef{
+		"id":        scalar(func(a *application) any { return a.app.Id.String() }),
+		"namespace": scalar(func(a *application) any { return a.app.Id.Namespace }),
+		"kind":      scalar(func(a *application) any { return string(a.app.Id.Kind) }),
+		"name":      scalar(func(a *application) any { return a.app.Id.Name }),
+		"category":  scalar(func(a *application) any { return string(a.app.Category) }),
+		"status":    scalar(func(a *application) any { return a.app.Status.String() }),
+		"instances": {typ: instanceType, resolve: func(src any, args arguments) any {
+			res := []any{}
+			for _, i := range src.(*application).app.Instances {
+				res = append(res, i)
+			}
+			return res
+		}},
+		"reports": {typ: reportType, resolve: func(src any, args arguments) any {
+			res := []any{}
+			for _, r := range src.(*application).app.Reports {
+				if matches(args.string("name"), string(r.Name)) {
+					res = append(res, r)
+				}
+			}
+			return res
+		}},
+		"checks": {typ: checkType, resolve: func(src any, args arguments) any {
+			res := []any{}
+			for _, r := range src.(*application).app.Reports {
+				res = append(res, checks(r, minStatus(args))...)
+			}
+			return res
+		}},
+		"deployments": {typ: deploymentType, resolve: func(src any, args arguments) any {
+			a := src.(*application)
+			res := []any{}
+			for _, ds := range model.CalcApplicationDeploymentStatuses(a.app, a.w.CheckConfigs, a.w.Ctx.To) {
+				res = append(res, ds)
+			}
+			return res
+		}},
+	}
+
+	instanceType.fields = map[string]*fieldDef{
+		"name":     scalar(func(i *model.Instance) any { return i.Name }),
+		"node":     scalar(func(i *model.Instance) any { return i.NodeName() }),
+		"up":       scalar(func(i *model.Instance) any { return i.IsUp() }),
+		"failed":   scalar(func(i *model.Instance) any { return i.IsFailed() }),
+		"obsolete": scalar(func(i *model.Instance) any { return i.IsObsolete() }),
+	}
+
+	reportType.fields = map[string]*fieldDef{
+		"name":   scalar(func(r *model.AuditReport) any { return string(r.Name) }),
+		"status": scalar(func(r *model.AuditReport) any { return r.Status.String() }),
+		"checks": {typ: checkType, resolve: func(src any, args arguments) any {
+			return checks(src.(*model.AuditReport), minStatus(args))
+		}},
+	}
+
+	checkType.fields = map[string]*fieldDef{
+		"id":            scalar(func(c *check) any { return string(c.ch.Id) }),
+		"title":         scalar(func(c *check) any { return c.ch.Title }),
+		"report":        scalar(func(c *check) any { return string(c.report) }),
+		"status":        scalar(func(c *check) any { return c.ch.Status.String() }),
+		"message":       scalar(func(c *check) any { return c.ch.Message }),
+		"items":         scalar(func(c *check) any { return c.ch.Items() }),
+		"threshold":     scalar(func(c *check) any { return c.ch.Threshold }),
+		"unit":          scalar(func(c *check) any { return string(c.ch.Unit) }),
+		"muted":         scalar(func(c *check) any { return c.ch.Muted }),
+		"suppressed_by": scalar(func(c *check) any { return c.ch.SuppressedBy }),
+		"value": scalar(func(c *check) any {
+			if v, ok := c.ch.Value(); ok {
+				return v
+			}
+			return nil
+		}),
+	}
+
+	deploymentType.fields = map[string]*fieldDef{
+		"name":        scalar(func(ds model.ApplicationDeploymentStatus) any { return ds.Deployment.Name }),
+		"version":     scalar(func(ds model.ApplicationDeploymentStatus) any { return ds.Deployment.Version() }),
+		"started_at":  scalar(func(ds model.ApplicationDeploymentStatus) any { return ds.Deployment.StartedAt }),
+		"finished_at": scalar(func(ds model.ApplicationDeploymentStatus) any { return ds.Deployment.FinishedAt }),
+		"status":      scalar(func(ds model.ApplicationDeploymentStatus) any { return ds.Status.String() }),
+		"message":     scalar(func(ds model.ApplicationDeploymentStatus) any { return ds.Message }),
+		"summary": scalar(func(ds model.ApplicationDeploymentStatus) any {
+			res := []string{}
+			for _, s := range ds.Summary {
+				res = append(res, s.Message)
+			}
+			return res
+		}),
+	}
+}
+
+func scalar[T any](f func(T) any) *fieldDef {
+	return &fieldDef{resolve: func(src any, _ arguments) any { return f(src.(T)) }}
+}
+
+func checks(r *model.AuditReport, min model.Status) []any {
+	res := []any{}
+	for _, ch := range r.Checks {
+		if ch.Status >= min {
+			res = append(res, &check{report: r.Name, ch: ch})
+		}
+	}
+	return res
+}
+
+func matches(filter, v string) bool {
+	return filter == "" || filter == v
+}
+
+func minStatus(args arguments) model.Status {
+	s := args.string("status")
+	for _, status := range []model.Status{model.OK, model.INFO, model.WARNING, model.CRITICAL} {
+		if s == status.String() {
+			return status
+		}
+	}
+	return model.UNKNOWN
+}
@@ -0,0 +1,206 @@
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/clickhouse"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/logs"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/coroot/logparser"
+	"k8s.io/klog"
+	"net/url"
+	"strings"
+)
+
+const (
+	limit = 100
+)
+
+type View struct {
+	Status  model.Status `json:"status"`
+	Message string       `json:"message"`
+	Query   string       `json:"query"`
+	Entries []Entry      `json:"entries"`
+	Limit   int          `json:"limit"`
+}
+
+type Entry struct {
+	Timestamp int64             `json:"timestamp"`
+	Instance  string            `json:"instance"`
+	Line      string            `json:"line"`
+	Labels    map[string]string `json:"labels"`
+}
+
+// Render retrieves the log lines matching the `logs` argument (<instance>:<pattern hash>:<from>-<to>).
+// If the pattern isn't set, the lines matching any of the error patterns of the instances are returned.
+// The lines are retrieved from Loki or Elasticsearch if either is configured, otherwise from the logs table of ClickHouse.
+func Render(ctx context.Context, project *db.Project, app *model.Application, chClient *clickhouse.Client, q url.Values, wCtx timeseries.Context) *View {
+	integrations := project.Settings.Integrations
+	if !integrations.RawLogsAvailable() {
+		return nil
+	}
+
+	parts := strings.Split(q.Get("logs")+"::", ":")
+	instanceName, hash, tsRange := parts[0], parts[1], parts[2]
+	parts = strings.Split(tsRange+"-", "-")
+	from := utils.ParseTime(wCtx.To, parts[0], wCtx.From)
+	to := utils.ParseTime(wCtx.To, parts[1], wCtx.To)
+
+	v := &View{Limit: limit}
+
+	var pattern *logparser.Pattern
+	if hash != "" {
+		for _, i := range app.Instances {
+			if p := i.LogPatterns[hash]; p != nil {
+				pattern = p.Pattern
+				break
+			}
+		}
+		if pattern == nil {
+			v.Status = model.UNKNOWN
+			v.Message = "Log pattern not found."
+			return v
+		}
+	}
+
+	var instances []string
+	var patterns []*logparser.Pattern
+	var hashes []string
+	for _, i := range app.Instances {
+		if instanceName != "" && i.Name != instanceName {
+			continue
+		}
+		instances = append(instances, strings.SplitN(i.Name, "@", 2)[0]) // <container>@<node> for non-Kubernetes applications
+		for h, p := range i.LogPatterns {
+			switch {
+			case pattern != nil:
+				if !p.Pattern.WeakEqual(pattern) { // the same way the patterns are merged in the Logs report
+					continue
+				}
+			case p.Level != model.LogLevelError && p.Level != model.LogLevelCritical:
+				continue
+			}
+			patterns = append(patterns, p.Pattern)
+			hashes = append(hashes, h)
+		}
+	}
+	if len(instances) == 0 {
+		v.Status = model.UNKNOWN
+		v.Message = fmt.Sprintf("Instance not found: %s", instanceName)
+		return v
+	}
+	if len(patterns) == 0 {
+		v.Status = model.OK
+		v.Message = "No matching log patterns within the time range."
+		return v
+	}
+
+	if cfg := integrations.Loki; cfg != nil {
+		queryLoki(ctx, v, cfg, app.Id.Namespace, instances, patterns, from, to)
+		return v
+	}
+	if cfg := integrations.Elasticsearch; cfg != nil {
+		queryElasticsearch(ctx, v, cfg, app.Id.Namespace, instances, patterns, from, to)
+		return v
+	}
+	filter := clickhouse.LogsFilter{
+		Namespace:     app.Id.Namespace,
+		Pods:          instances,
+		Services:      instances,
+		PatternHashes: utils.NewStringSet(hashes...).Items(),
+		From:          from,
+		To:            to,
+	}
+	if instanceName == "" {
+		filter.Services = append(filter.Services, app.Id.Name)
+	}
+	queryClickhouse(ctx, v, chClient, filter)
+	return v
+}
+
+func queryLoki(ctx context.Context, v *View, cfg *db.IntegrationLoki, namespace string, instances []string, patterns []*logparser.Pattern, from, to timeseries.Time) {
+	v.Query = logs.StreamSelector(cfg.NamespaceLabel, cfg.InstanceLabel, namespace, instances) + " " + logs.PatternFilter(patterns...)
+
+	client, err := logs.NewLoki(cfg.Url, cfg.TlsSkipVerify, cfg.BasicAuth, cfg.TenantId)
+	if err != nil {
+		klog.Errorln(err)
+		v.Status = model.WARNING
+		v.Message = fmt.Sprintf("Loki error: %s", err)
+		return
+	}
+	entries, err := client.QueryRange(ctx, v.Query, from, to, limit)
+	if err != nil {
+		klog.Errorln(err)
+		v.Status = model.WARNING
+		v.Message = fmt.Sprintf("Loki error: %s", err)
+		return
+	}
+	v.Status = model.OK
+	for _, e := range entries {
+		v.Entries = append(v.Entries, Entry{
+			Timestamp: e.Timestamp.UnixMilli(),
+			Instance:  e.Labels[cfg.InstanceLabel],
+			Line:      e.Line,
+			Labels:    e.Labels,
+		})
+	}
+}
+
+func queryElasticsearch(ctx context.Context, v *View, cfg *db.IntegrationElasticsearch, namespace string, instances []string, patterns []*logparser.Pattern, from, to timeseries.Time) {
+	fields := cfg.Fields()
+	query := logs.ElasticsearchQuery(fields, namespace, instances, patterns, from, to)
+	if data, err := json.Marshal(query); err == nil {
+		v.Query = string(data)
+	}
+
+	client, err := logs.NewElasticsearch(cfg.Url, cfg.TlsSkipVerify, cfg.BasicAuth, cfg.ApiKey)
+	if err != nil {
+		klog.Errorln(err)
+		v.Status = model.WARNING
+		v.Message = fmt.Sprintf("Elasticsearch error: %s", err)
+		return
+	}
+	entries, err := client.Search(ctx, cfg.Index, fields, query, limit)
+	if err != nil {
+		klog.Errorln(err)
+		v.Status = model.WARNING
+		v.Message = fmt.Sprintf("Elasticsearch error: %s", err)
+		return
+	}
+	v.Status = model.OK
+	for _, e := range entries {
+		v.Entries = append(v.Entries, Entry{
+			Timestamp: e.Timestamp.UnixMilli(),
+			Instance:  e.Labels[cfg.InstanceField],
+			Line:      e.Line,
+			Labels:    e.Labels,
+		})
+	}
+}
+
+func queryClickhouse(ctx context.Context, v *View, client *clickhouse.Client, filter clickhouse.LogsFilter) {
+	records, err := client.GetLogs(ctx, filter, limit)
+	if err != nil {
+		klog.Errorln(err)
+		v.Status = model.WARNING
+		v.Message = fmt.Sprintf("Clickhouse error: %s", err)
+		return
+	}
+	v.Status = model.OK
+	for _, r := range records {
+		instance := r.ResourceAttributes["k8s.pod.name"]
+		if instance == "" {
+			instance = r.ServiceName
+		}
+		v.Entries = append(v.Entries, Entry{
+			Timestamp: r.Timestamp.UnixMilli(),
+			Instance:  instance,
+			Line:      r.Body,
+			Labels:    r.LogAttributes,
+		})
+	}
+}
@@ -2,9 +2,10 @@ package node
 
 import (
 	"github.com/coroot/coroot/auditor"
+	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
 )
 
-func Render(w *model.World, node *model.Node) *model.AuditReport {
-	return auditor.AuditNode(w, node)
+func Render(w *model.World, p *db.Project, node *model.Node) *model.AuditReport {
+	return auditor.AuditNode(w, p, node)
 }
@@ -0,0 +1,56 @@
+package overview
+
+import (
+	"github.com/coroot/coroot/model"
+)
+
+const (
+	coverageHintNodeAgent = "Install coroot-node-agent on the node (as a DaemonSet in Kubernetes): https://coroot.com/docs/metric-exporters/node-agent/overview"
+	coverageHintPgAgent   = "Run coroot-pg-agent for the instance: https://coroot.com/docs/metric-exporters/pg-agent/overview"
+	coverageHintRedis     = "Run redis_exporter for the instance: https://github.com/oliver006/redis_exporter"
+	coverageHintJvm       = "Make sure the JVM is started without -XX:-UsePerfData and its /tmp/hsperfdata_* files are accessible to coroot-node-agent"
+)
+
+// renderCoverage lists the components lacking the expected telemetry.
+func renderCoverage(w *model.World) *model.Table {
+	table := model.NewTable("Component", "Type", "Missing telemetry", "How to fix")
+	for _, n := range w.Nodes {
+		if n.Name.Value() != "" || n.K8sName.Value() == "" {
+			continue
+		}
+		name := n.K8sName.Value()
+		node := model.NewTableCell(name)
+		node.Link = model.NewRouterLink(name).SetRoute("node").SetParam("name", name)
+		table.AddRow(node, model.NewTableCell("node"), coverageGap("node metrics"), model.NewTableCell(coverageHintNodeAgent))
+	}
+
+	for _, app := range w.Applications {
+		for _, i := range app.Instances {
+			if i.IsObsolete() {
+				continue
+			}
+			var gap, hint, typ string
+			types := i.ApplicationTypes()
+			switch {
+			case i.Pod != nil && i.Node == nil:
+				typ, gap, hint = "container", "container metrics", coverageHintNodeAgent
+			case types[model.ApplicationTypePostgres] && i.Postgres == nil:
+				typ, gap, hint = string(model.ApplicationTypePostgres), "Postgres metrics", coverageHintPgAgent
+			case (types[model.ApplicationTypeRedis] || types[model.ApplicationTypeKeyDB]) && i.Redis == nil:
+				typ, gap, hint = string(model.ApplicationTypeRedis), "Redis metrics", coverageHintRedis
+			case i.Jvm != nil && !i.Jvm.IsUp():
+				typ, gap, hint = "jvm", "JVM metrics", coverageHintJvm
+			default:
+				continue
+			}
+			instance := model.NewTableCell(app.Id.Name + ": " + i.Name)
+			instance.Link = model.NewRouterLink(app.Id.Name).SetRoute("application").SetParam("id", app.Id).SetParam("report", model.AuditReportInstances)
+			table.AddRow(instance, model.NewTableCell(typ), coverageGap(gap), model.NewTableCell(hint))
+		}
+	}
+	return table
+}
+
+func coverageGap(msg string) *model.TableCell {
+	return model.NewTableCell().SetStatus(model.WARNING, msg)
+}
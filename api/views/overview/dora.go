@@ -0,0 +1,227 @@
+package overview
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"sort"
+)
+
+const (
+	day  = timeseries.Day
+	week = 7 * timeseries.Day
+)
+
+// Dora contains the DORA metrics of the project: deployment frequency, lead time for changes (if the commits
+// of the deployments are known, see model.Repository), change failure rate, and time to restore service.
+type Dora struct {
+	Summary      *model.Table   `json:"summary"`
+	Charts       []*model.Chart `json:"charts"`
+	Applications *model.Table   `json:"applications"`
+}
+
+type doraStats struct {
+	deployments int
+	failed      int
+	leadTimes   []timeseries.Duration
+	restoreTime []timeseries.Duration
+}
+
+func (s *doraStats) leadTime() timeseries.Duration {
+	return meanDuration(s.leadTimes)
+}
+
+func (s *doraStats) mttr() timeseries.Duration {
+	return meanDuration(s.restoreTime)
+}
+
+func (s *doraStats) failureRate() float32 {
+	if s.deployments == 0 {
+		return 0
+	}
+	return float32(s.failed) * 100 / float32(s.deployments)
+}
+
+func renderDora(w *model.World) *Dora {
+	from, to := w.Ctx.From, w.Ctx.To
+	step := timeseries.Hour
+	if to.Sub(from) >= 2*day {
+		step = day
+	}
+	ctx := timeseries.Context{From: from.Truncate(step), To: to, Step: step}
+	points := int(ctx.To.Sub(ctx.From)/step) + 1
+	deployments := timeseries.New(ctx.From, points, step)
+	failed := timeseries.New(ctx.From, points, step)
+	leadTime := timeseries.New(ctx.From, points, step)
+	restoreTime := timeseries.New(ctx.From, points, step)
+	perBucket := map[timeseries.Time]*doraStats{}
+	bucket := func(t timeseries.Time) *doraStats {
+		t = t.Truncate(step)
+		s := perBucket[t]
+		if s == nil {
+			s = &doraStats{}
+			perBucket[t] = s
+		}
+		return s
+	}
+
+	total := &doraStats{}
+	apps := model.NewTable("Application", "Deployments", "Lead time", "Change failure rate", "Time to restore")
+	for _, app := range w.Applications {
+		s := &doraStats{}
+		for _, ds := range model.CalcApplicationDeploymentStatuses(app, w.CheckConfigs, to) {
+			d := ds.Deployment
+			if d.StartedAt.Before(from) || d.StartedAt.After(to) {
+				continue
+			}
+			b := bucket(d.StartedAt)
+			for _, st := range []*doraStats{s, total, b} {
+				st.deployments++
+			}
+			if isFailedDeployment(ds) {
+				for _, st := range []*doraStats{s, total, b} {
+					st.failed++
+				}
+			}
+			if d.Details != nil {
+				for _, c := range d.Details.Commits {
+					if c.Time.IsZero() || c.Time.After(d.StartedAt) {
+						continue
+					}
+					lt := d.StartedAt.Sub(c.Time)
+					for _, st := range []*doraStats{s, total, b} {
+						st.leadTimes = append(st.leadTimes, lt)
+					}
+				}
+			}
+		}
+		for _, i := range app.Incidents {
+			if !i.Resolved() || i.ResolvedAt.Before(from) || i.ResolvedAt.After(to) {
+				continue
+			}
+			rt := i.ResolvedAt.Sub(i.OpenedAt)
+			for _, st := range []*doraStats{s, total, bucket(i.ResolvedAt)} {
+				st.restoreTime = append(st.restoreTime, rt)
+			}
+		}
+		if s.deployments == 0 && len(s.restoreTime) == 0 {
+			continue
+		}
+		name := model.NewTableCell(app.Id.Name)
+		name.Link = model.NewRouterLink(app.Id.Name).SetRoute("application").SetParam("id", app.Id).SetParam("report", model.AuditReportDeployments)
+		apps.AddRow(
+			name,
+			model.NewTableCell(fmt.Sprint(s.deployments)),
+			durationCell(s.leadTime()),
+			failureRateCell(s),
+			durationCell(s.mttr()),
+		)
+	}
+	sort.Slice(apps.Rows, func(i, j int) bool {
+		return apps.Rows[i].Cells[0].Value < apps.Rows[j].Cells[0].Value
+	})
+
+	for t, s := range perBucket {
+		deployments.Set(t, float32(s.deployments))
+		if s.deployments > 0 {
+			failed.Set(t, s.failureRate())
+		}
+		if lt := s.leadTime(); lt > 0 {
+			leadTime.Set(t, float32(lt)/float32(timeseries.Hour))
+		}
+		if rt := s.mttr(); rt > 0 {
+			restoreTime.Set(t, float32(rt)/float32(timeseries.Hour))
+		}
+	}
+
+	res := &Dora{Applications: apps}
+	period := "per hour"
+	if step == day {
+		period = "per day"
+	}
+	res.Charts = append(res.Charts,
+		model.NewChart(ctx, "Deployment frequency, "+period).Column().AddSeries("deployments", deployments, "blue"),
+		model.NewChart(ctx, "Lead time for changes, hours").Column().AddSeries("lead time", leadTime, "purple"),
+		model.NewChart(ctx, "Change failure rate, %").Column().AddSeries("failed deployments", failed, "red"),
+		model.NewChart(ctx, "Time to restore service, hours").Column().AddSeries("time to restore", restoreTime, "orange"),
+	)
+
+	days := float32(to.Sub(from)) / float32(day)
+	summary := model.NewTable("Metric", "Value", "Performance")
+	frequency := float32(total.deployments) / days
+	summary.AddRow(
+		model.NewTableCell("Deployment frequency"),
+		model.NewTableCell(fmt.Sprintf("%d (%s per day)", total.deployments, utils.FormatFloat(frequency))),
+		performanceCell(total.deployments > 0, frequency >= 1, frequency*7 >= 1, frequency*30 >= 1),
+	)
+	lt := total.leadTime()
+	summary.AddRow(
+		model.NewTableCell("Lead time for changes"),
+		durationCell(lt),
+		performanceCell(lt > 0, lt < day, lt < week, lt < timeseries.Month),
+	)
+	cfr := total.failureRate()
+	summary.AddRow(
+		model.NewTableCell("Change failure rate"),
+		failureRateCell(total),
+		performanceCell(total.deployments > 0, cfr <= 5, cfr <= 10, cfr <= 15),
+	)
+	mttr := total.mttr()
+	summary.AddRow(
+		model.NewTableCell("Time to restore service"),
+		durationCell(mttr),
+		performanceCell(mttr > 0, mttr < timeseries.Hour, mttr < day, mttr < week),
+	)
+	res.Summary = summary
+	return res
+}
+
+// isFailedDeployment reports whether the deployment caused a regression or failed to roll out.
+func isFailedDeployment(ds model.ApplicationDeploymentStatus) bool {
+	switch ds.State {
+	case model.ApplicationDeploymentStateSummary, model.ApplicationDeploymentStateRollbackRecommended, model.ApplicationDeploymentStateStuck:
+		return ds.Status >= model.CRITICAL
+	}
+	return false
+}
+
+// performanceCell renders the performance level according to the DORA State of DevOps report.
+func performanceCell(known, elite, high, medium bool) *model.TableCell {
+	switch {
+	case !known:
+		return model.NewTableCell().SetStub("no data")
+	case elite:
+		return model.NewTableCell().SetStatus(model.OK, "elite")
+	case high:
+		return model.NewTableCell().SetStatus(model.OK, "high")
+	case medium:
+		return model.NewTableCell().SetStatus(model.WARNING, "medium")
+	}
+	return model.NewTableCell().SetStatus(model.CRITICAL, "low")
+}
+
+func durationCell(d timeseries.Duration) *model.TableCell {
+	if d == 0 {
+		return model.NewTableCell().SetStub("—")
+	}
+	return model.NewTableCell(utils.FormatDuration(d, 2))
+}
+
+func failureRateCell(s *doraStats) *model.TableCell {
+	if s.deployments == 0 {
+		return model.NewTableCell().SetStub("—")
+	}
+	return model.NewTableCell(fmt.Sprintf("%s (%d of %d)", utils.FormatPercentage(s.failureRate()), s.failed, s.deployments))
+}
+
+func meanDuration(ds []timeseries.Duration) timeseries.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	var sum timeseries.Duration
+	for _, d := range ds {
+		sum += d
+	}
+	return sum / timeseries.Duration(len(ds))
+}
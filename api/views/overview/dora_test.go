@@ -0,0 +1,87 @@
+package overview
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRenderDora(t *testing.T) {
+	from := timeseries.Time(1700006400) // 2023-11-15 00:00 UTC
+	to := from.Add(week)
+	w := model.NewWorld(from, to, timeseries.Minute)
+
+	cart := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "cart"))
+	cart.Deployments = []*model.ApplicationDeployment{
+		{ApplicationId: cart.Id, Name: "cart-0", StartedAt: from.Add(-day), FinishedAt: from.Add(-day)}, // before the range
+		{
+			ApplicationId: cart.Id, Name: "cart-1", StartedAt: from.Add(timeseries.Hour), FinishedAt: from.Add(2 * timeseries.Hour),
+			Details: &model.ApplicationDeploymentDetails{Commits: []model.ApplicationDeploymentCommit{
+				{Sha: "b", Time: from},
+				{Sha: "a", Time: from.Add(-2 * timeseries.Hour)},
+				{Sha: "unknown"},
+			}},
+		},
+		{ApplicationId: cart.Id, Name: "cart-2", StartedAt: from.Add(2*day + timeseries.Hour)}, // stuck
+	}
+	cart.Incidents = []*model.ApplicationIncident{
+		{Key: "1", OpenedAt: from.Add(3 * day), ResolvedAt: from.Add(3*day + 2*timeseries.Hour)},
+		{Key: "2", OpenedAt: from.Add(4 * day)}, // not resolved
+	}
+	orders := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "orders"))
+	w.Applications = []*model.Application{orders, cart}
+
+	v := renderDora(w)
+
+	require.Len(t, v.Applications.Rows, 1, "applications without deployments and incidents are skipped")
+	var row []string
+	for _, c := range v.Applications.Rows[0].Cells {
+		row = append(row, c.Value)
+	}
+	assert.Equal(t, []string{"cart", "2", "2 hours", "50% (1 of 2)", "2 hours"}, row)
+
+	summary := map[string][2]string{}
+	for _, r := range v.Summary.Rows {
+		summary[r.Cells[0].Value] = [2]string{r.Cells[1].Value, r.Cells[2].Value}
+	}
+	assert.Equal(t, map[string][2]string{
+		"Deployment frequency":    {"2 (0.3 per day)", "high"},
+		"Lead time for changes":   {"2 hours", "elite"},
+		"Change failure rate":     {"50% (1 of 2)", "low"},
+		"Time to restore service": {"2 hours", "high"},
+	}, summary)
+
+	require.Len(t, v.Charts, 4)
+	assert.Equal(t, "Deployment frequency, per day", v.Charts[0].Title)
+	assert.Equal(t, "TimeSeries(1700006400, 8, 86400, [1 . 1 0 . . . .])", v.Charts[0].Series.Get()[0].Data.Get().String())
+	assert.Equal(t, "TimeSeries(1700006400, 8, 86400, [2 . . . . . . .])", v.Charts[1].Series.Get()[0].Data.Get().String())
+	assert.Equal(t, "TimeSeries(1700006400, 8, 86400, [0 . 100 . . . . .])", v.Charts[2].Series.Get()[0].Data.Get().String())
+	assert.Equal(t, "TimeSeries(1700006400, 8, 86400, [. . . 2 . . . .])", v.Charts[3].Series.Get()[0].Data.Get().String())
+}
+
+func TestRenderDoraNoData(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(timeseries.Hour), timeseries.Minute)
+	v := renderDora(w)
+	assert.Empty(t, v.Applications.Rows)
+	assert.Equal(t, "Deployment frequency, per hour", v.Charts[0].Title)
+	for _, r := range v.Summary.Rows {
+		assert.Equal(t, "no data", r.Cells[2].Value, r.Cells[0].Value)
+	}
+}
+
+func TestPerformanceCell(t *testing.T) {
+	assert.Equal(t, "elite", performanceCell(true, true, true, true).Value)
+	assert.Equal(t, "high", performanceCell(true, false, true, true).Value)
+	assert.Equal(t, "medium", performanceCell(true, false, false, true).Value)
+	c := performanceCell(true, false, false, false)
+	assert.Equal(t, "low", c.Value)
+	assert.Equal(t, model.CRITICAL, *c.Status)
+	assert.True(t, performanceCell(false, true, true, true).IsStub)
+}
+
+func TestMeanDuration(t *testing.T) {
+	assert.Equal(t, timeseries.Duration(0), meanDuration(nil))
+	assert.Equal(t, 2*timeseries.Hour, meanDuration([]timeseries.Duration{timeseries.Hour, 3 * timeseries.Hour}))
+}
@@ -9,11 +9,14 @@ type View struct {
 	Applications []*Application `json:"applications"`
 	Costs        *Costs         `json:"costs"`
 	Nodes        *model.Table   `json:"nodes"`
+	Rollups      []*Rollup      `json:"rollups"`
+	Coverage     *model.Table   `json:"coverage"`
+	Dora         *Dora          `json:"dora"`
 }
 
 func Render(w *model.World, view string) *View {
 	v := &View{
-		Views: []string{"applications", "nodes"},
+		Views: []string{"applications", "namespaces", "categories", "nodes", "coverage", "dora"},
 	}
 	for _, n := range w.Nodes {
 		if n.Price != nil {
@@ -25,8 +28,16 @@ func Render(w *model.World, view string) *View {
 	switch view {
 	case "applications":
 		v.Applications = renderApplications(w)
+	case "namespaces":
+		v.Rollups = renderRollups(w, func(app *model.Application) string { return app.Id.Namespace })
+	case "categories":
+		v.Rollups = renderRollups(w, func(app *model.Application) string { return string(app.Category) })
 	case "nodes":
 		v.Nodes = renderNodes(w)
+	case "coverage":
+		v.Coverage = renderCoverage(w)
+	case "dora":
+		v.Dora = renderDora(w)
 	case "costs":
 		v.Costs = renderCosts(w)
 	}
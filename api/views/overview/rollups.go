@@ -0,0 +1,131 @@
+package overview
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"sort"
+)
+
+// Rollup aggregates the applications of a namespace or a category.
+type Rollup struct {
+	Name         string       `json:"name"`
+	Status       model.Status `json:"status"`
+	Requests     string       `json:"requests"`
+	Errors       string       `json:"errors"`
+	SLOBurn      string       `json:"slo_burn"`
+	Applications *model.Table `json:"applications"`
+}
+
+type rollupMember struct {
+	app      *model.Application
+	requests float32
+	errors   float32
+	burn     float32
+}
+
+func renderRollups(w *model.World, key func(app *model.Application) string) []*Rollup {
+	members := map[string][]*rollupMember{}
+	for _, app := range w.Applications {
+		m := &rollupMember{app: app, requests: timeseries.NaN, errors: timeseries.NaN, burn: timeseries.NaN}
+		if len(app.AvailabilitySLIs) > 0 {
+			total, failed := app.AvailabilitySLIs[0].GetTotalAndFailed(false)
+			m.requests, m.errors = total.Last(), failed.Last()
+		}
+		if ch := findCheck(app, model.AuditReportSLO, model.Checks.SLOErrorBudget.Id); ch != nil && ch.Status != model.UNKNOWN {
+			if v, ok := ch.Value(); ok {
+				m.burn = v
+			}
+		}
+		k := key(app)
+		members[k] = append(members[k], m)
+	}
+
+	var res []*Rollup
+	for name, ms := range members {
+		sort.Slice(ms, func(i, j int) bool {
+			if ms[i].app.Status != ms[j].app.Status {
+				return ms[i].app.Status > ms[j].app.Status
+			}
+			return ms[i].app.Id.Name < ms[j].app.Id.Name
+		})
+		r := &Rollup{
+			Name:         name,
+			Applications: model.NewTable("Application", "Status", "Requests", "Errors", "Error budget").SetSorted(true),
+		}
+		var requests, errors float32
+		var worst *rollupMember
+		for _, m := range ms {
+			if m.app.Status > r.Status {
+				r.Status = m.app.Status
+			}
+			if !timeseries.IsNaN(m.requests) {
+				requests += m.requests
+			}
+			if !timeseries.IsNaN(m.errors) {
+				errors += m.errors
+			}
+			if !timeseries.IsNaN(m.burn) && (worst == nil || m.burn > worst.burn) {
+				worst = m
+			}
+			r.Applications.AddRow(rollupRow(m)...)
+		}
+		if requests > 0 {
+			r.Requests = utils.FormatFloat(requests) + "/s"
+			r.Errors = utils.FormatPercentage(errors / requests * 100)
+		}
+		if worst != nil {
+			r.SLOBurn = fmt.Sprintf("%.0f%% (%s)", worst.burn, worst.app.Id.Name)
+		}
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Status != res[j].Status {
+			return res[i].Status > res[j].Status
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func rollupRow(m *rollupMember) []*model.TableCell {
+	app := model.NewTableCell(m.app.Id.Name).UpdateStatus(m.app.Status)
+	app.Link = model.NewRouterLink(m.app.Id.Name).SetRoute("application").SetParam("id", m.app.Id)
+
+	var messages []string
+	for _, i := range model.CalcIndicators(m.app) {
+		if i.Status >= model.WARNING {
+			messages = append(messages, i.Message)
+		}
+	}
+	status := model.NewTableCell(messages...)
+
+	requests := model.NewTableCell().SetUnit("/s")
+	if m.requests > 0 {
+		requests.SetValue(utils.FormatFloat(m.requests))
+	}
+	errors := model.NewTableCell().SetUnit("/s")
+	if m.errors > 0 {
+		errors.SetValue(utils.FormatFloat(m.errors))
+	}
+	burn := model.NewTableCell()
+	if !timeseries.IsNaN(m.burn) {
+		burn.SetValue(fmt.Sprintf("%.0f%%", m.burn)).SetUnit("projected")
+	}
+	return []*model.TableCell{app, status, requests, errors, burn}
+}
+
+func findCheck(app *model.Application, report model.AuditReportName, id model.CheckId) *model.Check {
+	for _, r := range app.Reports {
+		if r.Name != report {
+			continue
+		}
+		for _, ch := range r.Checks {
+			if ch.Id == id {
+				return ch
+			}
+		}
+	}
+	return nil
+}
@@ -78,7 +78,7 @@ func Render(ctx context.Context, project *db.Project, app *model.Application, ap
 		return v.Applications[i].Name < v.Applications[j].Name
 	})
 
-	pods := getPods(app)
+	pods := getPods(app, q.Get("instance"))
 	profiles := apps[pyroscopeApplication]
 	if disabled {
 		profiles = nil
@@ -129,7 +129,7 @@ func Render(ctx context.Context, project *db.Project, app *model.Application, ap
 		return v
 	}
 
-	cpuChart, memoryChart := getCharts(app, wCtx)
+	cpuChart, memoryChart := getCharts(app, wCtx, project.Settings.MaintenanceWindows)
 	switch profile.Type {
 	case profiling.TypeCPU:
 		v.Chart = cpuChart
@@ -173,9 +173,12 @@ func match(profiles []profiling.ProfileMeta, typ profiling.Type, name string) (p
 	return matched[0], true
 }
 
-func getPods(app *model.Application) []string {
+func getPods(app *model.Application, instance string) []string {
 	pods := make([]string, 0, len(app.Instances))
 	for _, i := range app.Instances {
+		if instance != "" && i.Name != instance {
+			continue
+		}
 		if i.Pod != nil {
 			pods = append(pods, i.Name)
 		}
@@ -183,8 +186,9 @@ func getPods(app *model.Application) []string {
 	return pods
 }
 
-func getCharts(app *model.Application, ctx timeseries.Context) (*model.Chart, *model.Chart) {
+func getCharts(app *model.Application, ctx timeseries.Context, windows model.MaintenanceWindows) (*model.Chart, *model.Chart) {
 	events := model.EventsToAnnotations(app.Events, ctx)
+	events = append(events, windows.Annotations(ctx)...)
 	incidents := model.IncidentsToAnnotations(app.Incidents, ctx)
 	cpuChart := model.NewChart(ctx, "CPU usage by instance, cores").Stacked().AddAnnotation(events...).AddAnnotation(incidents...)
 	memoryChart := model.NewChart(ctx, "Memory (RSS) usage by instance, bytes").Stacked().AddAnnotation(events...).AddAnnotation(incidents...)
@@ -31,12 +31,21 @@ type ApplicationExporter struct {
 	Applications map[model.ApplicationId]bool `json:"applications"`
 }
 
+type Memory struct {
+	Status  model.Status `json:"status"`
+	Message string       `json:"message"`
+	Series  int          `json:"series"`
+	Bytes   int64        `json:"bytes"`
+	Limit   int64        `json:"limit"`
+}
+
 type Status struct {
 	Status           model.Status      `json:"status"`
 	Error            string            `json:"error"`
 	Prometheus       Prometheus        `json:"prometheus"`
 	NodeAgent        NodeAgent         `json:"node_agent"`
 	KubeStateMetrics *KubeStateMetrics `json:"kube_state_metrics"`
+	Memory           *Memory           `json:"memory"`
 
 	ApplicationExporters map[model.ApplicationType]ApplicationExporter `json:"application_exporters"`
 }
@@ -56,7 +65,7 @@ func RenderStatus(p *db.Project, cacheStatus *cache.Status, w *model.World) *Sta
 	res.Prometheus.Status = model.OK
 	res.Prometheus.Message = "ok"
 	switch {
-	case p.Prometheus.Url == "":
+	case !p.Prometheus.Configured():
 		res.Prometheus.Status = model.WARNING
 		res.Prometheus.Message = "Prometheus is not configured"
 		res.Prometheus.Action = "configure"
@@ -103,6 +112,10 @@ func RenderStatus(p *db.Project, cacheStatus *cache.Status, w *model.World) *Sta
 			res.Status = model.WARNING
 		}
 	}
+	res.Memory = renderMemory(w)
+	if res.Memory.Status > res.Status {
+		res.Status = res.Memory.Status
+	}
 	for _, app := range w.Applications {
 		for appType, ok := range app.InstrumentationStatus() {
 			ex := res.ApplicationExporters[appType]
@@ -127,3 +140,18 @@ func RenderStatus(p *db.Project, cacheStatus *cache.Status, w *model.World) *Sta
 
 	return res
 }
+
+func renderMemory(w *model.World) *Memory {
+	mu := w.MemoryUsage
+	res := &Memory{Status: model.OK, Series: mu.Series, Bytes: mu.Bytes, Limit: mu.Limit}
+	value, unit := utils.FormatBytes(float32(mu.Bytes))
+	res.Message = fmt.Sprintf("%d series, %s%s", mu.Series, value, unit)
+	if mu.Degraded() {
+		res.Status = model.INFO
+		res.Message += fmt.Sprintf(
+			" (the memory limit has been exceeded, data is loaded with a %s step instead of %s)",
+			utils.FormatDuration(w.Ctx.Step, 1), utils.FormatDuration(mu.RequestedStep, 1),
+		)
+	}
+	return res
+}
@@ -0,0 +1,93 @@
+package statuspage
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"sort"
+)
+
+// Source is the data of an application published on a status page.
+type Source struct {
+	Application model.StatusPageApplication
+	Failures    *timeseries.TimeSeries // the share of failed evaluations of the SLO checks per day
+	Status      model.Status
+	Incidents   []*model.ApplicationIncident
+}
+
+type View struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Status       model.Status    `json:"status"`
+	UpdatedAt    timeseries.Time `json:"updated_at"`
+	Applications []Application   `json:"applications"`
+	Incidents    []Incident      `json:"incidents"`
+}
+
+type Application struct {
+	Title   string       `json:"title"`
+	Status  model.Status `json:"status"`
+	Uptime  *float32     `json:"uptime"`
+	History []Day        `json:"history"`
+}
+
+// Day is the uptime percentage of a day, null if there were no evaluations.
+type Day struct {
+	Date   timeseries.Time `json:"date"`
+	Uptime *float32        `json:"uptime"`
+}
+
+type Incident struct {
+	Application string          `json:"application"`
+	OpenedAt    timeseries.Time `json:"opened_at"`
+	ResolvedAt  timeseries.Time `json:"resolved_at"`
+	Severity    model.Status    `json:"severity"`
+}
+
+func Render(page model.StatusPage, sources []Source, now timeseries.Time) *View {
+	v := &View{
+		Name:         page.Name,
+		Description:  page.Description,
+		Status:       model.UNKNOWN,
+		UpdatedAt:    now,
+		Applications: []Application{},
+		Incidents:    []Incident{},
+	}
+	for _, s := range sources {
+		title := s.Application.Title
+		if title == "" {
+			if id, err := model.NewApplicationIdFromString(s.Application.ApplicationId); err == nil {
+				title = id.Name
+			}
+		}
+		app := Application{Title: title, Status: s.Status, History: []Day{}}
+		var sum float32
+		var days int
+		iter := s.Failures.Iter()
+		for iter.Next() {
+			t, f := iter.Value()
+			d := Day{Date: t}
+			if !timeseries.IsNaN(f) {
+				uptime := 100 * (1 - f)
+				d.Uptime = &uptime
+				sum += uptime
+				days++
+			}
+			app.History = append(app.History, d)
+		}
+		if days > 0 {
+			uptime := sum / float32(days)
+			app.Uptime = &uptime
+		}
+		if app.Status > v.Status {
+			v.Status = app.Status
+		}
+		v.Applications = append(v.Applications, app)
+		for _, i := range s.Incidents {
+			v.Incidents = append(v.Incidents, Incident{Application: title, OpenedAt: i.OpenedAt, ResolvedAt: i.ResolvedAt, Severity: i.Severity})
+		}
+	}
+	sort.Slice(v.Incidents, func(i, j int) bool {
+		return v.Incidents[i].OpenedAt.After(v.Incidents[j].OpenedAt)
+	})
+	return v
+}
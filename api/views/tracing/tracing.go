@@ -42,6 +42,7 @@ type Service struct {
 
 type Span struct {
 	Service    string            `json:"service"`
+	Instance   string            `json:"instance"`
 	TraceId    string            `json:"trace_id"`
 	Id         string            `json:"id"`
 	ParentId   string            `json:"parent_id"`
@@ -94,6 +95,7 @@ func Render(ctx context.Context, project *db.Project, app *model.Application, ap
 		sli := app.LatencySLIs[0]
 		if len(sli.Histogram) > 0 {
 			events := model.EventsToAnnotations(app.Events, w.Ctx)
+			events = append(events, project.Settings.MaintenanceWindows.Annotations(w.Ctx)...)
 			incidents := model.IncidentsToAnnotations(app.Incidents, w.Ctx)
 			v.Heatmap = model.NewHeatmap(w.Ctx, "Latency & Errors heatmap, requests per second").AddAnnotation(events...).AddAnnotation(incidents...)
 			for _, h := range model.HistogramSeries(sli.Histogram, sli.Config.ObjectiveBucket, sli.Config.ObjectivePercentage) {
@@ -233,6 +235,7 @@ func Render(ctx context.Context, project *db.Project, app *model.Application, ap
 	for _, s := range spans {
 		ss := Span{
 			Service:    getService(typ, s, app),
+			Instance:   getInstance(typ, s, app),
 			TraceId:    s.TraceId,
 			Id:         s.SpanId,
 			ParentId:   s.ParentSpanId,
@@ -267,6 +270,36 @@ func getService(typ tracing.Type, s *tracing.Span, app *model.Application) strin
 	return ""
 }
 
+// getInstance links a span to an instance of the application using the resource attributes set by the SDK,
+// or the container ID in the case of the spans produced by the eBPF tracer.
+func getInstance(typ tracing.Type, s *tracing.Span, app *model.Application) string {
+	switch typ {
+	case tracing.TypeOtel:
+		for _, attr := range []string{"k8s.pod.name", "service.instance.id", "host.name"} {
+			name := s.ResourceAttributes[attr]
+			if name == "" {
+				continue
+			}
+			for _, i := range app.Instances {
+				if i.Name == name {
+					return i.Name
+				}
+			}
+		}
+	case tracing.TypeOtelEbpf:
+		if id := s.Attributes["container.id"]; id != "" {
+			for _, i := range app.Instances {
+				for _, c := range i.Containers {
+					if c.Id == id {
+						return i.Name
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
 func getStatus(s *tracing.Span) Status {
 	res := Status{Message: "OK"}
 	if s.StatusCode == "STATUS_CODE_ERROR" {
@@ -5,18 +5,31 @@ import (
 	"github.com/coroot/coroot/api/views/application"
 	"github.com/coroot/coroot/api/views/categories"
 	"github.com/coroot/coroot/api/views/configs"
+	"github.com/coroot/coroot/api/views/dashboard"
+	"github.com/coroot/coroot/api/views/deployments"
+	"github.com/coroot/coroot/api/views/embed"
+	"github.com/coroot/coroot/api/views/export"
+	"github.com/coroot/coroot/api/views/global"
+	"github.com/coroot/coroot/api/views/grafana"
+	"github.com/coroot/coroot/api/views/graphql"
 	"github.com/coroot/coroot/api/views/integrations"
+	"github.com/coroot/coroot/api/views/logs"
 	"github.com/coroot/coroot/api/views/node"
 	"github.com/coroot/coroot/api/views/overview"
 	"github.com/coroot/coroot/api/views/profile"
 	"github.com/coroot/coroot/api/views/project"
 	"github.com/coroot/coroot/api/views/search"
+	"github.com/coroot/coroot/api/views/statuspage"
 	"github.com/coroot/coroot/api/views/tracing"
 	"github.com/coroot/coroot/cache"
+	"github.com/coroot/coroot/clickhouse"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/prom"
 	"github.com/coroot/coroot/timeseries"
+	"io"
 	"net/url"
+	"time"
 )
 
 func Status(p *db.Project, cacheStatus *cache.Status, w *model.World) *project.Status {
@@ -27,10 +40,38 @@ func Overview(w *model.World, view string) *overview.View {
 	return overview.Render(w, view)
 }
 
+type StatusPageSource = statuspage.Source
+
+func StatusPage(page model.StatusPage, sources []StatusPageSource, now timeseries.Time) *statuspage.View {
+	return statuspage.Render(page, sources, now)
+}
+
+func Embed(app *model.Application, report, widget, check string) *embed.Embed {
+	return embed.Render(app, report, widget, check)
+}
+
+func GraphQL(w *model.World, query string, variables map[string]any) *graphql.Response {
+	return graphql.Execute(w, query, variables)
+}
+
+func GlobalSummary(p *db.Project, w *model.World, label string) *global.Summary {
+	return global.Summarize(p, w, label)
+}
+
+func Global(summaries []*global.Summary) *global.View {
+	return global.Render(summaries)
+}
+
 func Application(w *model.World, app *model.Application) *application.View {
 	return application.Render(w, app)
 }
 
+type DeploymentComparisonSide = deployments.Side
+
+func DeploymentComparison(all []*model.ApplicationDeployment, a, b DeploymentComparisonSide, loc *time.Location) *deployments.Comparison {
+	return deployments.Render(all, a, b, loc)
+}
+
 func Profile(ctx context.Context, project *db.Project, app *model.Application, appSettings *db.ApplicationSettings, q url.Values, wCtx timeseries.Context) *profile.View {
 	return profile.Render(ctx, project, app, appSettings, q, wCtx)
 }
@@ -39,8 +80,48 @@ func Tracing(ctx context.Context, project *db.Project, app *model.Application, a
 	return tracing.Render(ctx, project, app, appSettings, q, w)
 }
 
-func Node(w *model.World, n *model.Node) *model.AuditReport {
-	return node.Render(w, n)
+func RawLogs(ctx context.Context, project *db.Project, app *model.Application, chClient *clickhouse.Client, q url.Values, wCtx timeseries.Context) *logs.View {
+	return logs.Render(ctx, project, app, chClient, q, wCtx)
+}
+
+func GrafanaTargets(w *model.World, search string) []string {
+	return grafana.Targets(w, search)
+}
+
+func GrafanaMetrics(w *model.World, search string) []grafana.Metric {
+	return grafana.Metrics(w, search)
+}
+
+func GrafanaQuery(w *model.World, targets []grafana.Target) []grafana.Series {
+	return grafana.Query(w, targets)
+}
+
+func Node(w *model.World, p *db.Project, n *model.Node) *model.AuditReport {
+	return node.Render(w, p, n)
+}
+
+func ReportPDF(dst io.Writer, w *model.World, title string, report *model.AuditReport) error {
+	return export.ReportPDF(dst, title, report, w.Ctx)
+}
+
+func PrometheusRules(checkConfigs model.CheckConfigs, customChecks []model.CustomCheck, step timeseries.Duration) *export.PrometheusRules {
+	return export.RenderPrometheusRules(checkConfigs, customChecks, step)
+}
+
+func ReportsV1(w *model.World, app *model.Application, report string, summaryOnly bool) *export.ReportsV1 {
+	return export.RenderV1(w, app, report, summaryOnly)
+}
+
+func DeploymentVerdictV1(app *model.Application, ds model.ApplicationDeploymentStatus) *export.DeploymentVerdictV1 {
+	return export.RenderDeploymentVerdictV1(app, ds)
+}
+
+func PendingDeploymentVerdictV1(app *model.Application, deploymentId string) *export.DeploymentVerdictV1 {
+	return export.RenderPendingDeploymentVerdictV1(app, deploymentId)
+}
+
+func Dashboard(ctx context.Context, promClient prom.Client, w *model.World, d model.Dashboard) *dashboard.View {
+	return dashboard.Render(ctx, promClient, w, d)
 }
 
 func Search(w *model.World) *search.View {
@@ -0,0 +1,185 @@
+package argocd
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+
+	// applicationFields are the fields of the applications the API responds with, so the manifests, the specs
+	// and the health of the resources aren't transferred on every listing.
+	applicationFields = "items.metadata.name,items.status.resources,items.status.history,items.status.operationState"
+)
+
+var (
+	secureClient   = &http.Client{}
+	insecureClient = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+)
+
+// Client is a client of the ArgoCD API authenticated with an API token (e.g., of a local account with the apiKey capability).
+type Client struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func NewClient(address, token string, tlsSkipVerify bool) *Client {
+	c := &Client{url: address, token: token, client: secureClient}
+	if tlsSkipVerify {
+		c.client = insecureClient
+	}
+	return c
+}
+
+// Application is an ArgoCD Application with the resources it manages and the history of its sync operations.
+type Application struct {
+	Name      string
+	Resources []Resource
+	History   []Sync
+}
+
+type Resource struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// Sync is a sync operation. InitiatedBy is the name of the user who triggered the sync, empty for automated syncs.
+type Sync struct {
+	Revision    string
+	StartedAt   timeseries.Time
+	FinishedAt  timeseries.Time
+	Status      string
+	InitiatedBy string
+	Automated   bool
+}
+
+// RevisionMetadata describes a Git commit.
+type RevisionMetadata struct {
+	Author  string `json:"author"`
+	Message string `json:"message"`
+}
+
+type initiatedBy struct {
+	Username  string `json:"username"`
+	Automated bool   `json:"automated"`
+}
+
+type application struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Status struct {
+		Resources []struct {
+			Kind      string `json:"kind"`
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		} `json:"resources"`
+		History []struct {
+			Revision        string      `json:"revision"`
+			DeployStartedAt *time.Time  `json:"deployStartedAt"`
+			DeployedAt      time.Time   `json:"deployedAt"`
+			InitiatedBy     initiatedBy `json:"initiatedBy"`
+		} `json:"history"`
+		OperationState *struct {
+			Phase     string     `json:"phase"`
+			StartedAt time.Time  `json:"startedAt"`
+			Finished  *time.Time `json:"finishedAt"`
+			Operation struct {
+				InitiatedBy initiatedBy `json:"initiatedBy"`
+			} `json:"operation"`
+			SyncResult *struct {
+				Revision string `json:"revision"`
+			} `json:"syncResult"`
+		} `json:"operationState"`
+	} `json:"status"`
+}
+
+// Applications returns the applications with the history of successful syncs and the current (or last) sync operation,
+// which can be in progress or failed.
+func (c *Client) Applications(ctx context.Context) ([]Application, error) {
+	var list struct {
+		Items []application `json:"items"`
+	}
+	if err := c.getJson(ctx, "/api/v1/applications", url.Values{"fields": {applicationFields}}, &list); err != nil {
+		return nil, err
+	}
+	res := make([]Application, 0, len(list.Items))
+	for _, a := range list.Items {
+		app := Application{Name: a.Metadata.Name}
+		for _, r := range a.Status.Resources {
+			app.Resources = append(app.Resources, Resource{Kind: r.Kind, Namespace: r.Namespace, Name: r.Name})
+		}
+		for _, h := range a.Status.History {
+			s := Sync{
+				Revision:    h.Revision,
+				StartedAt:   timeseries.Time(h.DeployedAt.Unix()),
+				FinishedAt:  timeseries.Time(h.DeployedAt.Unix()),
+				Status:      "Succeeded",
+				InitiatedBy: h.InitiatedBy.Username,
+				Automated:   h.InitiatedBy.Automated,
+			}
+			if h.DeployStartedAt != nil {
+				s.StartedAt = timeseries.Time(h.DeployStartedAt.Unix())
+			}
+			app.History = append(app.History, s)
+		}
+		if op := a.Status.OperationState; op != nil && op.SyncResult != nil && op.Phase != "Succeeded" {
+			s := Sync{
+				Revision:    op.SyncResult.Revision,
+				StartedAt:   timeseries.Time(op.StartedAt.Unix()),
+				Status:      op.Phase,
+				InitiatedBy: op.Operation.InitiatedBy.Username,
+				Automated:   op.Operation.InitiatedBy.Automated,
+			}
+			if op.Finished != nil {
+				s.FinishedAt = timeseries.Time(op.Finished.Unix())
+			}
+			app.History = append(app.History, s)
+		}
+		res = append(res, app)
+	}
+	return res, nil
+}
+
+func (c *Client) RevisionMetadata(ctx context.Context, app, revision string) (*RevisionMetadata, error) {
+	var res RevisionMetadata
+	if err := c.getJson(ctx, path.Join("/api/v1/applications", app, "revisions", revision, "metadata"), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) getJson(ctx context.Context, uri string, params url.Values, dest any) error {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return err
+	}
+	u.Path = path.Join(u.Path, uri)
+	u.RawQuery = params.Encode()
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("argocd: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
@@ -0,0 +1,36 @@
+package argocd
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApplications(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/argocd/api/v1/applications", r.URL.Path)
+		assert.Equal(t, applicationFields, r.URL.Query().Get("fields"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"items": [{
+			"metadata": {"name": "app"},
+			"status": {
+				"resources": [{"kind": "Deployment", "namespace": "default", "name": "web"}],
+				"history": [{"revision": "abc", "deployStartedAt": "2024-01-01T00:00:00Z", "deployedAt": "2024-01-01T00:01:00Z", "initiatedBy": {"automated": true}}],
+				"operationState": {"phase": "Running", "startedAt": "2024-01-01T01:00:00Z", "operation": {"initiatedBy": {"username": "admin"}}, "syncResult": {"revision": "def"}}
+			}
+		}]}`))
+	}))
+	defer srv.Close()
+
+	apps, err := NewClient(srv.URL+"/argocd", "token", false).Applications(context.Background())
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, "app", apps[0].Name)
+	assert.Equal(t, []Resource{{Kind: "Deployment", Namespace: "default", Name: "web"}}, apps[0].Resources)
+	require.Len(t, apps[0].History, 2)
+	assert.Equal(t, Sync{Revision: "abc", StartedAt: 1704067200, FinishedAt: 1704067260, Status: "Succeeded", Automated: true}, apps[0].History[0])
+	assert.Equal(t, Sync{Revision: "def", StartedAt: 1704070800, Status: "Running", InitiatedBy: "admin"}, apps[0].History[1])
+}
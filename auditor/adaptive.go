@@ -0,0 +1,40 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+var AdaptiveThresholdChecks = []model.CheckId{
+	model.Checks.CPUNode.Id,
+	model.Checks.PostgresLatency.Id,
+	model.Checks.RedisLatency.Id,
+	model.Checks.JvmSafepointTime.Id,
+}
+
+// AdaptiveThresholdMetrics returns the metrics whose history is used to learn the threshold of the check.
+// Only the checks comparing a single metric with the threshold are supported.
+func AdaptiveThresholdMetrics(app *model.Application, checkId model.CheckId) []*timeseries.TimeSeries {
+	var res []*timeseries.TimeSeries
+	for _, i := range app.Instances {
+		switch checkId {
+		case model.Checks.CPUNode.Id:
+			if i.Node != nil {
+				res = append(res, i.Node.CpuUsagePercent)
+			}
+		case model.Checks.PostgresLatency.Id:
+			if i.Postgres != nil {
+				res = append(res, i.Postgres.Avg)
+			}
+		case model.Checks.RedisLatency.Id:
+			if i.Redis != nil {
+				res = append(res, redisLatency(i.Redis))
+			}
+		case model.Checks.JvmSafepointTime.Id:
+			if i.Jvm != nil {
+				res = append(res, i.Jvm.SafepointTime)
+			}
+		}
+	}
+	return res
+}
@@ -3,7 +3,16 @@ package auditor
 import (
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+	"runtime"
+	"runtime/debug"
 	"sort"
+	"sync"
+)
+
+const (
+	maxAuditWorkers = 8
 )
 
 type appAuditor struct {
@@ -11,48 +20,95 @@ type appAuditor struct {
 	p       *db.Project
 	app     *model.Application
 	reports []*model.AuditReport
+	reused  *auditResult
+
+	rttDegradations map[model.ApplicationId][]model.Annotation
 }
 
 func Audit(w *model.World, p *db.Project) {
-	ncs := nodeConsumersByNode{}
+	audit(w, p, nil)
+}
 
+// audit audits the applications of the world, except for the ones in reused, which get the results of a previous audit.
+func audit(w *model.World, p *db.Project, reused map[model.ApplicationId]*auditResult) {
+	ncs := &nodeConsumersByNode{byNode: map[string]*nodeConsumers{}}
+
+	auditors := make([]*appAuditor, 0, len(w.Applications))
+	jobs := make(chan *appAuditor)
+	workers := runtime.NumCPU()
+	if workers > maxAuditWorkers {
+		workers = maxAuditWorkers
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for a := range jobs {
+				a.audit(ncs)
+			}
+		}()
+	}
 	for _, app := range w.Applications {
 		a := &appAuditor{
 			w:   w,
 			p:   p,
 			app: app,
 		}
-		a.slo()
-		a.instances()
-		a.cpu(ncs)
-		a.memory(ncs)
-		a.storage()
-		a.network()
-		a.postgres()
-		a.redis()
-		a.jvm()
-		a.logs()
-		a.deployments()
+		auditors = append(auditors, a)
+		if r := reused[app.Id]; r != nil {
+			a.reused = r
+			a.reports = r.reports
+			// the mutes and the maintenance windows may have started or expired since the previous audit
+			for _, r := range a.reports {
+				for _, ch := range r.Checks {
+					ch.Unsilence()
+					a.silence(ch)
+				}
+			}
+			continue
+		}
+		jobs <- a
+	}
+	close(jobs)
+	wg.Wait()
+
+	suppress(auditors)
+
+	downstreamRttDegradations := map[model.ApplicationId][]model.Annotation{}
+	for _, a := range auditors {
+		for upstreamId, annotations := range a.rttDegradations {
+			downstreamRttDegradations[upstreamId] = append(downstreamRttDegradations[upstreamId], annotations...)
+		}
+	}
+
+	for _, a := range auditors {
+		app := a.app
+		if a.reused != nil {
+			for _, r := range a.reused.reports {
+				a.updateStatus(r)
+				app.Reports = append(app.Reports, r)
+			}
+			continue
+		}
+		a.composite()
 
 		for _, r := range a.reports {
 			widgets := a.enrichWidgets(r.Widgets, app.Events)
+			if r.Name == model.AuditReportSLO {
+				for _, w := range widgets {
+					w.AddAnnotation(downstreamRttDegradations[app.Id]...)
+				}
+			}
 			sort.SliceStable(widgets, func(i, j int) bool {
 				return widgets[i].Table != nil
 			})
 			r.Widgets = widgets
 
 			for _, ch := range r.Checks {
-				ch.Calc()
-				if ch.Status > r.Status {
-					r.Status = ch.Status
-				}
-			}
-			switch r.Name {
-			case model.AuditReportPostgres, model.AuditReportRedis, model.AuditReportInstances, model.AuditReportSLO:
-				if app.Status < r.Status {
-					app.Status = r.Status
-				}
+				ch.Annotation = p.Settings.CheckAnnotations.Get(app.Id, ch.Id)
 			}
+			a.updateStatus(r)
 			app.Reports = append(app.Reports, r)
 		}
 
@@ -63,6 +119,98 @@ func Audit(w *model.World, p *db.Project) {
 			app.AddReport(model.AuditReportTracing, &model.Widget{Tracing: &model.Tracing{ApplicationId: app.Id}, Width: "100%"})
 		}
 	}
+
+	for _, a := range auditors {
+		if a.reused != nil {
+			continue
+		}
+		for _, r := range a.reports {
+			for _, widget := range r.Widgets {
+				if widget.Topology != nil {
+					widget.Topology.UpdateStatuses(w)
+				}
+			}
+		}
+	}
+}
+
+// audit runs the auditors of the application. It's called concurrently for different applications,
+// so the auditors must not modify anything but the reports of the application.
+// A panic in an auditor is logged, and the other auditors keep running.
+func (a *appAuditor) audit(ncs *nodeConsumersByNode) {
+	a.run("slo", a.slo)
+	a.run("instances", a.instances)
+	a.run("cpu", func() { a.cpu(ncs) })
+	a.run("memory", func() { a.memory(ncs) })
+	a.run("storage", a.storage)
+	a.run("network", a.network)
+	a.run("dependencies", a.dependencies)
+	a.run("conntrack", a.conntrack)
+	a.run("tls", a.tls)
+	a.run("dns", a.dns)
+	a.run("postgres", a.postgres)
+	a.run("redis", a.redis)
+	a.run("jvm", a.jvm)
+	a.run("aws", a.aws)
+	a.run("logs", a.logs)
+	a.run("deployments", a.deployments)
+	a.run("custom", a.custom)
+	a.run("log checks", a.logChecks)
+
+	for _, r := range a.reports {
+		for _, ch := range r.Checks {
+			ch.Calc()
+			a.silence(ch)
+		}
+	}
+}
+
+func (a *appAuditor) run(name string, f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			klog.Errorf("%s auditor failed for %s: %v\n%s", name, a.app.Id, r, debug.Stack())
+		}
+	}()
+	f()
+}
+
+func (a *appAuditor) silence(ch *model.Check) {
+	if a.p.Settings.CheckMutes.IsMuted(a.app.Id, ch.Id, a.w.Ctx.To, a.p.Location()) {
+		ch.Muted = true
+	}
+	if a.p.Settings.MaintenanceWindows.Find(ch.Id, a.w.Ctx.To) != nil {
+		ch.SetMaintenance()
+	}
+}
+
+// updateStatus calculates the status of the report from the statuses of its checks (the silenced ones are considered OK)
+// and propagates it to the status of the application.
+func (a *appAuditor) updateStatus(r *model.AuditReport) {
+	r.Status = model.UNKNOWN
+	for _, ch := range r.Checks {
+		status := ch.Status
+		if ch.Silenced() && status > model.OK {
+			status = model.OK
+		}
+		if status > r.Status {
+			r.Status = status
+		}
+	}
+	switch r.Name {
+	case model.AuditReportPostgres, model.AuditReportRedis, model.AuditReportInstances, model.AuditReportSLO, model.AuditReportCustom:
+		if a.app.Status < r.Status {
+			a.app.Status = r.Status
+		}
+	}
+}
+
+func (a *appAuditor) getOrAddReport(name model.AuditReportName) *model.AuditReport {
+	for _, r := range a.reports {
+		if r.Name == name {
+			return r
+		}
+	}
+	return a.addReport(name)
 }
 
 func (a *appAuditor) addReport(name model.AuditReportName) *model.AuditReport {
@@ -73,6 +221,8 @@ func (a *appAuditor) addReport(name model.AuditReportName) *model.AuditReport {
 
 func (a *appAuditor) enrichWidgets(widgets []*model.Widget, events []*model.ApplicationEvent) []*model.Widget {
 	annotations := model.EventsToAnnotations(events, a.w.Ctx)
+	annotations = append(annotations, model.IncidentsToAnnotations(a.app.Incidents, a.w.Ctx)...)
+	annotations = append(annotations, projectAnnotations(a.p, a.app.Id, a.w.Ctx)...)
 	var res []*model.Widget
 	for _, w := range widgets {
 		if w.Chart != nil {
@@ -98,3 +248,9 @@ func (a *appAuditor) enrichWidgets(widgets []*model.Widget, events []*model.Appl
 	}
 	return res
 }
+
+// projectAnnotations returns the maintenance windows and the user annotations to be shown on the charts of the application.
+func projectAnnotations(p *db.Project, appId model.ApplicationId, ctx timeseries.Context) []model.Annotation {
+	annotations := p.Settings.MaintenanceWindows.Annotations(ctx)
+	return append(annotations, p.Settings.Annotations.Annotations(appId, ctx)...)
+}
@@ -0,0 +1,97 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"sort"
+)
+
+func (a *appAuditor) aws() {
+	if a.app.Aws == nil {
+		return
+	}
+	report := a.addReport(model.AuditReportAWS)
+
+	for _, name := range sortedKeys(a.app.Aws.TargetGroups) {
+		tg := a.app.Aws.TargetGroups[name]
+		report.GetOrCreateChart("Target group requests, per second").AddSeries(name, tg.Requests)
+		report.GetOrCreateChart("Target group errors (5xx), per second").AddSeries(name, tg.Errors)
+		report.GetOrCreateChart("Target group response time, seconds").AddSeries(name, tg.ResponseTime)
+		report.
+			GetOrCreateChartInGroup("Targets of <selector>", name).
+			Stacked().
+			AddSeries("healthy", tg.HealthyTargets, "green").
+			AddSeries("unhealthy", tg.UnhealthyTargets, "red")
+
+		status := model.NewTableCell().SetStatus(model.OK, "ok")
+		if unhealthy := lastNotNull(tg.UnhealthyTargets); unhealthy > 0 {
+			status.SetStatus(model.WARNING, utils.FormatFloat(unhealthy)+" unhealthy targets")
+		}
+		cell := model.NewTableCell(name)
+		if tg.LoadBalancer != "" {
+			cell.AddTag("load balancer: %s", tg.LoadBalancer)
+		}
+		report.GetOrCreateTable("Target group", "Status", "Healthy targets", "Requests", "Errors").AddRow(
+			cell,
+			status,
+			model.NewTableCell(utils.FormatFloat(lastNotNull(tg.HealthyTargets))),
+			model.NewTableCell(utils.FormatFloat(lastNotNull(tg.Requests))).SetUnit("/s"),
+			model.NewTableCell(utils.FormatFloat(lastNotNull(tg.Errors))).SetUnit("/s"),
+		)
+	}
+
+	for _, name := range sortedKeys(a.app.Aws.SqsQueues) {
+		q := a.app.Aws.SqsQueues[name]
+		report.GetOrCreateChart("SQS messages visible").AddSeries(name, q.MessagesVisible)
+		report.GetOrCreateChart("SQS age of the oldest message, seconds").AddSeries(name, q.OldestMessageAge)
+		report.
+			GetOrCreateChartInGroup("SQS messages of <selector>, per second", name).
+			AddSeries("sent", q.MessagesSent, "blue").
+			AddSeries("deleted", q.MessagesDeleted, "green")
+
+		report.GetOrCreateTable("Queue", "Messages visible", "Oldest message age").AddRow(
+			model.NewTableCell(name),
+			model.NewTableCell(utils.FormatFloat(lastNotNull(q.MessagesVisible))),
+			model.NewTableCell(utils.FormatFloat(lastNotNull(q.OldestMessageAge))).SetUnit("s"),
+		)
+	}
+
+	for _, name := range sortedKeys(a.app.Aws.LambdaFunctions) {
+		f := a.app.Aws.LambdaFunctions[name]
+		report.GetOrCreateChart("Lambda invocations, per second").AddSeries(name, f.Invocations)
+		report.GetOrCreateChart("Lambda errors, per second").AddSeries(name, f.Errors)
+		report.GetOrCreateChart("Lambda throttles, per second").AddSeries(name, f.Throttles)
+		report.GetOrCreateChart("Lambda duration, seconds").AddSeries(name, f.Duration)
+
+		status := model.NewTableCell().SetStatus(model.OK, "ok")
+		switch {
+		case lastNotNull(f.Errors) > 0:
+			status.SetStatus(model.WARNING, "errors")
+		case lastNotNull(f.Throttles) > 0:
+			status.SetStatus(model.WARNING, "throttled")
+		}
+		report.GetOrCreateTable("Function", "Status", "Invocations", "Errors", "Duration").AddRow(
+			model.NewTableCell(name),
+			status,
+			model.NewTableCell(utils.FormatFloat(lastNotNull(f.Invocations))).SetUnit("/s"),
+			model.NewTableCell(utils.FormatFloat(lastNotNull(f.Errors))).SetUnit("/s"),
+			model.NewTableCell(utils.FormatFloat(lastNotNull(f.Duration)*1000)).SetUnit("ms"),
+		)
+	}
+}
+
+// lastNotNull returns the last known value, since CloudWatch publishes the metrics of managed services with a delay.
+func lastNotNull(ts *timeseries.TimeSeries) float32 {
+	_, v := ts.LastNotNull()
+	return v
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
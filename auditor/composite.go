@@ -0,0 +1,26 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"strings"
+)
+
+// composite must be called after all the other checks have been calculated.
+func (a *appAuditor) composite() {
+	checks := map[model.CheckId]*model.Check{}
+	for _, r := range a.reports {
+		for _, ch := range r.Checks {
+			checks[ch.Id] = ch
+		}
+	}
+	for _, cc := range a.p.Settings.CompositeChecks {
+		if !cc.Matches(a.app.Id) {
+			continue
+		}
+		check := a.getOrAddReport(model.AuditReportCustom).CreateCheck(cc.Config())
+		if status, failed := cc.Evaluate(checks); len(failed) > 0 {
+			check.SetStatus(status, "failing checks: %s", strings.Join(failed, ", "))
+		}
+		a.silence(check)
+	}
+}
@@ -0,0 +1,75 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+const (
+	// ephemeralPortRange is the size of the default Linux local port range (32768-60999).
+	// The range configured on the nodes isn't exported by the agent.
+	ephemeralPortRange = 60999 - 32768 + 1
+
+	// timeWaitSeconds is how long a closed connection keeps its local port in the TIME_WAIT state on Linux.
+	timeWaitSeconds = 60
+)
+
+func (a *appAuditor) conntrack() {
+	report := a.getOrAddReport(model.AuditReportNetwork)
+	var conntrackCheck, portsCheck *model.Check
+
+	seenNodes := map[string]bool{}
+	for _, i := range a.app.Instances {
+		connects := timeseries.NewAggregate(timeseries.NanSum)
+		ports := timeseries.NewAggregate(timeseries.Max)
+		for _, u := range i.Upstreams {
+			connects.Add(u.Connects)
+			// A local port is unique per destination, so the ports are exhausted to a particular upstream:
+			// they're taken by the active connections and the closed ones still in TIME_WAIT.
+			used := timeseries.NewAggregate(timeseries.NanSum).Add(
+				u.Active,
+				u.Connects.Map(func(t timeseries.Time, v float32) float32 { return v * timeWaitSeconds }),
+			)
+			ports.Add(used.Get())
+		}
+		if !connects.IsEmpty() {
+			report.GetOrCreateChart("New TCP connections, per second").AddSeries(i.Name, connects)
+		}
+		if !ports.IsEmpty() {
+			usage := ports.Get().Map(func(t timeseries.Time, v float32) float32 {
+				return v / ephemeralPortRange * 100
+			})
+			report.GetOrCreateChart("Ephemeral ports used to an upstream (estimated), %").AddSeries(i.Name, usage)
+			if portsCheck == nil {
+				portsCheck = report.CreateCheck(model.Checks.NetworkEphemeralPorts)
+			}
+			if portsCheck.Matches(i) {
+				last := usage.Last()
+				portsCheck.Observe(last)
+				if last > portsCheck.Threshold {
+					portsCheck.AddItem(i.Name)
+				}
+			}
+		}
+
+		node := i.Node
+		if node == nil || seenNodes[node.Name.Value()] || node.ConntrackEntries.IsEmpty() || node.ConntrackLimit.IsEmpty() {
+			continue
+		}
+		seenNodes[node.Name.Value()] = true
+		usage := timeseries.Div(node.ConntrackEntries, node.ConntrackLimit).Map(func(t timeseries.Time, v float32) float32 {
+			return v * 100
+		})
+		report.GetOrCreateChart("Conntrack table utilization, %").AddSeries(node.Name.Value(), usage)
+		if conntrackCheck == nil {
+			conntrackCheck = report.CreateCheck(model.Checks.NetworkConntrack)
+		}
+		if conntrackCheck.Matches(i) {
+			last := usage.Last()
+			conntrackCheck.Observe(last)
+			if last > conntrackCheck.Threshold {
+				conntrackCheck.AddItem(node.Name.Value())
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestConntrack(t *testing.T) {
+	w := model.NewWorld(0, 180, 60)
+	app := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"))
+	i := app.GetOrCreateInstance("cart-1", nil)
+	w.Applications = []*model.Application{app}
+
+	a := &appAuditor{w: w, app: app}
+	a.conntrack()
+	require.Len(t, a.reports, 1)
+	assert.Empty(t, a.reports[0].Checks, "no data")
+	assert.Empty(t, a.reports[0].Widgets)
+
+	i.Upstreams = append(i.Upstreams, &model.Connection{
+		Instance: i,
+		Active:   timeseries.NewWithData(0, 60, []float32{100, 1000, 5000}),
+		Connects: timeseries.NewWithData(0, 60, []float32{10, 100, 300}),
+	})
+	a = &appAuditor{w: w, app: app}
+	a.conntrack()
+	require.Len(t, a.reports, 1)
+	require.Len(t, a.reports[0].Checks, 1)
+	ch := a.reports[0].Checks[0]
+	assert.Equal(t, model.Checks.NetworkEphemeralPorts.Id, ch.Id)
+	ch.Calc()
+	assert.Equal(t, model.WARNING, ch.Status) // (5000 + 300*60) / 28232 = 81%
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/coroot/coroot/timeseries"
 )
 
-func (a *appAuditor) cpu(ncs nodeConsumersByNode) {
+func (a *appAuditor) cpu(ncs *nodeConsumersByNode) {
 	report := a.addReport(model.AuditReportCPU)
 	relevantNodes := map[string]*model.Node{}
 	nodeCpuCheck := report.CreateCheck(model.Checks.CPUNode)
@@ -14,6 +14,7 @@ func (a *appAuditor) cpu(ncs nodeConsumersByNode) {
 	seenContainers, seenRelatedNodes := false, false
 	limitByContainer := map[string]*timeseries.Aggregate{}
 	cpuChartTitle := "CPU usage of container <selector>, cores"
+	hottestInstance, hottestUsage := "", float32(0)
 
 	for _, i := range a.app.Instances {
 		for _, c := range i.Containers {
@@ -28,10 +29,17 @@ func (a *appAuditor) cpu(ncs nodeConsumersByNode) {
 			report.GetOrCreateChartInGroup("CPU delay of container <selector>, seconds/second", c.Name).AddSeries(i.Name, c.CpuDelay)
 			report.GetOrCreateChartInGroup("Throttled time of container <selector>, seconds/second", c.Name).AddSeries(i.Name, c.ThrottledTime)
 
+			if !containerCpuCheck.Matches(i) {
+				continue
+			}
 			usage := c.CpuUsage.Last() / c.CpuLimit.Last()
+			containerCpuCheck.Observe(usage)
 			if usage > containerCpuCheck.Threshold {
 				usageChart.Feature()
 				containerCpuCheck.AddItem("%s@%s", c.Name, i.Name)
+				if usage > hottestUsage {
+					hottestInstance, hottestUsage = i.Name, usage
+				}
 			}
 		}
 		if node := i.Node; i.Node != nil {
@@ -51,9 +59,13 @@ func (a *appAuditor) cpu(ncs nodeConsumersByNode) {
 					SetThreshold("total", node.CpuCapacity).
 					AddMany(ncs.get(node).cpu, 5, timeseries.Max)
 
-				if i.Node.CpuUsagePercent.Last() > nodeCpuCheck.Threshold {
-					consumersChart.Feature()
-					nodeCpuCheck.AddItem(i.Node.Name.Value())
+				if nodeCpuCheck.Matches(i) {
+					last := i.Node.CpuUsagePercent.Last()
+					nodeCpuCheck.Observe(last)
+					if last > nodeCpuCheck.Threshold {
+						consumersChart.Feature()
+						nodeCpuCheck.AddItem(i.Node.Name.Value())
+					}
 				}
 			}
 		}
@@ -66,6 +78,11 @@ func (a *appAuditor) cpu(ncs nodeConsumersByNode) {
 		for _, ch := range report.GetOrCreateChartGroup(cpuChartTitle).Charts {
 			ch.DrillDownLink = model.NewRouterLink("profile").SetParam("report", model.AuditReportProfiling).SetArg("profile", profiling.TypeCPU)
 		}
+		title := "CPU profile"
+		if hottestInstance != "" {
+			title += " of " + hottestInstance
+		}
+		report.AddFlameGraph(title, profiling.TypeCPU, hottestInstance, 0, 0)
 	}
 
 	if !seenContainers {
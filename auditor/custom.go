@@ -0,0 +1,77 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"net"
+)
+
+func (a *appAuditor) custom() {
+	var report *model.AuditReport
+	for _, cc := range a.w.CustomChecks {
+		if !cc.Check.Matches(a.app.Id) {
+			continue
+		}
+		if report == nil {
+			report = a.getOrAddReport(model.AuditReportCustom)
+		}
+		check := report.CreateCheck(cc.Check.Config())
+		chart := report.GetOrCreateChart(cc.Check.Title)
+		for _, mv := range cc.Values {
+			if !customCheckSeriesMatches(a.app, mv.Labels) {
+				continue
+			}
+			name := mv.Labels.String()
+			chart.AddSeries(name, mv.Values)
+			last := mv.Values.Last()
+			check.Observe(last)
+			if last > check.Threshold {
+				chart.Feature()
+				check.AddItem(name)
+			}
+		}
+	}
+}
+
+// customCheckSeriesMatches reports whether the series returned by the query of a custom check belongs to the application.
+// The series are attributed by their container_id, pod (and namespace), or instance (the scrape target) labels.
+// The series having none of them (e.g., the results of aggregations) apply to every application the check matches.
+func customCheckSeriesMatches(app *model.Application, labels model.Labels) bool {
+	containerId, pod, target := labels["container_id"], labels["pod"], labels["instance"]
+	if containerId == "" && pod == "" && target == "" {
+		return true
+	}
+	if ns := labels["namespace"]; pod != "" && ns != "" && ns != app.Id.Namespace {
+		return false
+	}
+	var targetIp, targetPort string
+	if target != "" {
+		if ip, port, err := net.SplitHostPort(target); err == nil {
+			targetIp, targetPort = ip, port
+		} else {
+			targetIp = target
+		}
+	}
+	for _, i := range app.Instances {
+		if pod != "" && i.Name == pod {
+			return true
+		}
+		if containerId != "" {
+			for _, c := range i.Containers {
+				if c.Id == containerId {
+					return true
+				}
+			}
+		}
+		if targetIp != "" {
+			if i.Name == targetIp {
+				return true
+			}
+			for l := range i.TcpListens {
+				if l.IP == targetIp && (targetPort == "" || l.Port == targetPort) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
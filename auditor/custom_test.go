@@ -0,0 +1,63 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCustomCheckSeriesMatches(t *testing.T) {
+	app := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"))
+	i := app.GetOrCreateInstance("cart-7d9f-x2x4", nil)
+	i.Containers["cart"] = &model.Container{Id: "/k8s/shop/cart-7d9f-x2x4/cart", Name: "cart"}
+	i.TcpListens[model.Listen{IP: "10.0.0.5", Port: "8080"}] = true
+
+	for _, c := range []struct {
+		labels   model.Labels
+		expected bool
+	}{
+		{model.Labels{}, true},
+		{model.Labels{"job": "cart"}, true},
+		{model.Labels{"container_id": "/k8s/shop/cart-7d9f-x2x4/cart"}, true},
+		{model.Labels{"container_id": "/k8s/shop/catalog-5b6c-a1b2/catalog"}, false},
+		{model.Labels{"pod": "cart-7d9f-x2x4", "namespace": "shop"}, true},
+		{model.Labels{"pod": "cart-7d9f-x2x4", "namespace": "staging"}, false},
+		{model.Labels{"pod": "catalog-5b6c-a1b2"}, false},
+		{model.Labels{"instance": "10.0.0.5:8080"}, true},
+		{model.Labels{"instance": "10.0.0.5:9090"}, false},
+		{model.Labels{"instance": "10.0.0.6:8080"}, false},
+		{model.Labels{"instance": "cart-7d9f-x2x4"}, true},
+	} {
+		assert.Equal(t, c.expected, customCheckSeriesMatches(app, c.labels), c.labels.String())
+	}
+}
+
+func TestCustomCheck(t *testing.T) {
+	w := model.NewWorld(0, 3600, 60)
+	cart := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"))
+	cart.GetOrCreateInstance("cart-1", nil)
+	catalog := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "catalog"))
+	catalog.GetOrCreateInstance("catalog-1", nil)
+	w.Applications = []*model.Application{cart, catalog}
+	w.CustomChecks = []*model.CustomCheckValues{{
+		Check: model.CustomCheck{Id: "queue", Title: "Queue length", Threshold: 10, Applications: []string{"shop/*"}},
+		Values: []model.MetricValues{
+			{Labels: model.Labels{"pod": "cart-1"}, Values: timeseries.NewWithData(0, 60, []float32{100})},
+			{Labels: model.Labels{"pod": "catalog-1"}, Values: timeseries.NewWithData(0, 60, []float32{1})},
+		},
+	}}
+	for _, app := range w.Applications {
+		a := &appAuditor{w: w, app: app}
+		a.custom()
+		require.Len(t, a.reports, 1)
+		ch := a.reports[0].Checks[0]
+		ch.Calc()
+		if app == cart {
+			assert.Equal(t, model.WARNING, ch.Status)
+		} else {
+			assert.Equal(t, model.OK, ch.Status)
+		}
+	}
+}
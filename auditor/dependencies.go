@@ -0,0 +1,52 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) dependencies() {
+	topology := model.NewTopology(a.app)
+	if topology.IsEmpty() {
+		return
+	}
+	report := a.addReport(model.AuditReportDependencies)
+	report.AddTopology(topology)
+
+	table := report.GetOrCreateTable("Application", "Direction", "Requests", "Latency", "Errors", "RTT")
+	for _, l := range topology.Clients {
+		table.AddRow(topologyRow(l, "client")...)
+	}
+	for _, l := range topology.Dependencies {
+		table.AddRow(topologyRow(l, "dependency")...)
+	}
+
+	for _, l := range topology.Dependencies {
+		report.GetOrCreateChartInGroup("Requests to <selector>, per second", l.Node.Id.Name).AddSeries("requests", l.Requests)
+		report.GetOrCreateChartInGroup("Latency of requests to <selector>, seconds", l.Node.Id.Name).AddSeries("latency", l.Latency)
+		report.GetOrCreateChartInGroup("Errors of requests to <selector>, per second", l.Node.Id.Name).AddSeries("errors", l.Errors, "black")
+	}
+}
+
+func topologyRow(l *model.TopologyLink, direction string) []*model.TableCell {
+	app := model.NewTableCell(l.Node.Id.Name).UpdateStatus(l.Status)
+	app.Link = model.NewRouterLink(l.Node.Id.Name).SetRoute("application").SetParam("id", l.Node.Id)
+
+	requests := model.NewTableCell().SetUnit("/s")
+	if last := l.Requests.Last(); last > 0 {
+		requests.SetValue(utils.FormatFloat(last))
+	}
+	latency := model.NewTableCell().SetUnit("ms")
+	if last := l.Latency.Last(); last > 0 {
+		latency.SetValue(utils.FormatFloat(last * 1000))
+	}
+	errors := model.NewTableCell().SetUnit("/s")
+	if last := l.Errors.Last(); last > 0 {
+		errors.SetValue(utils.FormatFloat(last))
+	}
+	rtt := model.NewTableCell().SetUnit("ms")
+	if last := l.Rtt.Last(); last > 0 {
+		rtt.SetValue(utils.FormatFloat(last * 1000))
+	}
+	return []*model.TableCell{app, model.NewTableCell(direction), requests, latency, errors, rtt}
+}
@@ -1,6 +1,7 @@
 package auditor
 
 import (
+	"fmt"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
@@ -14,18 +15,66 @@ func (a *appAuditor) deployments() {
 	deploymentStatusCheck := report.CreateCheck(model.Checks.DeploymentStatus)
 
 	now := timeseries.Now()
-	table := report.GetOrCreateTable("Deployment", "Active", "Summary").SetSorted(true)
 	statuses := model.CalcApplicationDeploymentStatuses(a.app, a.w.CheckConfigs, now)
+	header := []string{"Deployment", "Active", "Summary", "Risk"}
+	withBuilds := false
+	for _, ds := range statuses {
+		if d := ds.Deployment.Details; d != nil && d.Pipeline != nil {
+			withBuilds = true
+			header = append(header, "Build")
+			break
+		}
+	}
+	table := report.GetOrCreateTable(header...).SetSorted(true)
+	a.p.Settings.MaintenanceWindows.ApplyToDeployments(statuses)
+	if n := len(statuses); n > 0 {
+		statuses[n-1].CalcCanary(a.app, a.w.Ctx.To)
+	}
 	for i := len(statuses) - 1; i >= 0; i-- {
 		ds := statuses[i]
 		startedAt := utils.FormatDuration(now.Sub(ds.Deployment.StartedAt), 1)
 		from, to := ds.Deployment.StartedAt.Add(-30*timeseries.Minute), ds.Deployment.StartedAt.Add(30*timeseries.Minute)
 		version := model.NewTableCell().SetStatus(ds.Status, ds.Deployment.Version()).AddTag(startedAt + " ago")
 		version.Link = model.NewRouterLink(ds.Deployment.Version()).SetParam("report", model.AuditReportInstances).SetArg("from", from).SetArg("to", to)
+		if d := ds.Deployment.Details; ds.Deployment.IsChange() {
+			version.AddTag(d.Change.Author)
+			version.SetUrl(d.Change.Url)
+		} else if ds.Deployment.IsConfigOnly() && d.Sync != nil {
+			version.AddTag(d.Sync.Application)
+		} else if d != nil && d.Sync != nil {
+			if trigger := d.Sync.Trigger(); trigger != "" {
+				version.AddTag("%s by %s", d.Sync.ShortRevision(), trigger)
+			} else {
+				version.AddTag(d.Sync.ShortRevision())
+			}
+		}
+		if d := ds.Deployment.Details; d != nil && d.Helm != nil && d.Sync == nil {
+			version.AddTag("release %s", d.Helm.Release)
+			if d.Helm.ValuesHash != "" {
+				version.AddTag("values %s", d.Helm.ValuesHash)
+			}
+		}
+		repo, rev := a.p.Settings.Repositories.Revision(ds.Deployment)
+		if repo != nil {
+			version.SetUrl(repo.CommitUrl(rev))
+			if i > 0 {
+				if prevRepo, prevRev := a.p.Settings.Repositories.Revision(statuses[i-1].Deployment); prevRepo != nil && prevRepo.Id == repo.Id && prevRev != rev {
+					version.SetUrl(repo.CompareUrl(prevRev, rev))
+				}
+			}
+		}
 		active := model.NewTableCell(utils.FormatDuration(ds.Lifetime, 1)).SetShortValue(utils.FormatDurationShort(ds.Lifetime, 1))
 
 		summary := model.NewTableCell()
 		switch ds.State {
+		case model.ApplicationDeploymentStateRollbackRecommended:
+			summary.DeploymentSummaries = append(summary.DeploymentSummaries, model.ApplicationDeploymentSummary{
+				Report:  model.AuditReportSLO,
+				Ok:      false,
+				Message: ds.Message,
+				Time:    ds.Deployment.StartedAt,
+			})
+			summary.DeploymentSummaries = append(summary.DeploymentSummaries, ds.Summary...)
 		case model.ApplicationDeploymentStateSummary:
 			if len(ds.Summary) > 0 {
 				summary.DeploymentSummaries = ds.Summary
@@ -34,7 +83,10 @@ func (a *appAuditor) deployments() {
 			}
 		case model.ApplicationDeploymentStateDeployed:
 			version.UpdateStatus(model.UNKNOWN)
-			if i == len(statuses)-1 {
+			if ds.Canary != nil {
+				version.UpdateStatus(ds.Canary.Status)
+				summary.DeploymentSummaries = ds.Canary.Summary
+			} else if i == len(statuses)-1 {
 				summary.SetStub("Collecting data...")
 			} else {
 				summary.SetStub("Not enough data due to the lifetime < %s", utils.FormatDuration(model.ApplicationDeploymentMinLifetime, 1))
@@ -47,10 +99,91 @@ func (a *appAuditor) deployments() {
 				Message: ds.Message,
 				Time:    ds.Deployment.StartedAt,
 			})
-		case model.ApplicationDeploymentStateInProgress, model.ApplicationDeploymentStateCancelled:
+			if ds.Canary != nil {
+				summary.DeploymentSummaries = append(summary.DeploymentSummaries, ds.Canary.Summary...)
+			}
+		case model.ApplicationDeploymentStateInProgress:
+			if ds.Canary != nil {
+				if ds.Canary.Status > ds.Status {
+					version.UpdateStatus(ds.Canary.Status)
+				}
+				summary.DeploymentSummaries = ds.Canary.Summary
+			} else {
+				summary.SetStub(ds.Message)
+			}
+		case model.ApplicationDeploymentStateCancelled:
 			summary.SetStub(ds.Message)
 		}
 
-		table.AddRow(version, active, summary).SetId(ds.Deployment.Id())
+		if d := ds.Deployment.Details; d != nil && d.SchemaChanges != nil && d.SchemaChanges.Count > 0 {
+			summary.DeploymentSummaries = append(summary.DeploymentSummaries, model.ApplicationDeploymentSummary{
+				Report:  model.AuditReportPostgres,
+				Ok:      true, // informational, the impact of the changes is reflected by the other summaries
+				Message: d.SchemaChanges.String(),
+				Time:    ds.Deployment.StartedAt,
+			})
+		}
+
+		if d := ds.Deployment.Details; d != nil && repo != nil {
+			for _, c := range d.Commits {
+				c.Url = repo.CommitUrl(c.Sha)
+				summary.DeploymentCommits = append(summary.DeploymentCommits, c)
+			}
+		}
+
+		risk := model.CalcApplicationDeploymentRisk(ds.Deployment, previousRollout(statuses[:i]), a.p.Location())
+		riskCell := model.NewTableCell().SetStatus(risk.Status(), fmt.Sprintf("%s (%d)", risk.Level(), risk.Score))
+		for _, f := range risk.Factors {
+			riskCell.AddTag("%s", f)
+		}
+
+		cells := []*model.TableCell{version, active, summary, riskCell}
+		if withBuilds {
+			cells = append(cells, pipelineCell(ds.Deployment))
+		}
+		table.AddRow(cells...).SetId(ds.Deployment.Id())
+	}
+}
+
+func previousRollout(statuses []model.ApplicationDeploymentStatus) *model.ApplicationDeployment {
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if d := statuses[i].Deployment; !d.IsChange() && !d.IsConfigOnly() && !d.IsTrafficShift() {
+			return d
+		}
+	}
+	return nil
+}
+
+func pipelineCell(d *model.ApplicationDeployment) *model.TableCell {
+	if d.Details == nil || d.Details.Pipeline == nil {
+		return model.NewTableCell()
+	}
+	p := d.Details.Pipeline
+	status := model.OK
+	switch p.Status {
+	case model.CIPipelineStatusSuccess:
+	case model.CIPipelineStatusFailure:
+		status = model.CRITICAL
+	default:
+		status = model.WARNING
+	}
+	value := p.Status
+	if duration := p.Duration(); duration > 0 {
+		value = utils.FormatDuration(duration, 2)
+	}
+	c := model.NewTableCell().SetStatus(status, value).SetUrl(p.Url)
+	if p.Name != "" {
+		c.AddTag(p.Name)
+	}
+	if t := p.Tests; t != nil {
+		if t.Failed > 0 {
+			c.AddTag("tests: %d passed, %d failed", t.Passed, t.Failed)
+		} else {
+			c.AddTag("tests: %d passed", t.Passed)
+		}
+	}
+	if pr := p.PullRequest; pr != nil {
+		c.AddTag("PR #%d", pr.Number)
 	}
+	return c
 }
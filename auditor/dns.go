@@ -0,0 +1,109 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"strings"
+)
+
+func (a *appAuditor) dns() {
+	seen := false
+	for _, i := range a.app.Instances {
+		for _, c := range i.Containers {
+			if len(c.DNSRequests) > 0 {
+				seen = true
+			}
+		}
+	}
+	if !seen {
+		return
+	}
+
+	report := a.addReport(model.AuditReportDNS)
+	latencyCheck := report.CreateCheck(model.Checks.DNSLatency)
+	errorsCheck := report.CreateCheck(model.Checks.DNSErrors)
+
+	byStatus := map[string]*timeseries.Aggregate{}
+	total := timeseries.NewAggregate(timeseries.NanSum)
+	failed := timeseries.NewAggregate(timeseries.NanSum)
+	failedByDomain := map[model.DNSRequest]*timeseries.Aggregate{}
+
+	for _, i := range a.app.Instances {
+		latency := timeseries.NewAggregate(timeseries.Max)
+		for _, c := range i.Containers {
+			latency.Add(c.DNSRequestsLatency)
+			for r, statuses := range c.DNSRequests {
+				for status, ts := range statuses {
+					agg := byStatus[status]
+					if agg == nil {
+						agg = timeseries.NewAggregate(timeseries.NanSum)
+						byStatus[status] = agg
+					}
+					agg.Add(ts)
+					total.Add(ts)
+					if dnsFailed(status) {
+						failed.Add(ts)
+						fa := failedByDomain[r]
+						if fa == nil {
+							fa = timeseries.NewAggregate(timeseries.NanSum)
+							failedByDomain[r] = fa
+						}
+						fa.Add(ts)
+					}
+				}
+			}
+		}
+		l := latency.Get()
+		report.GetOrCreateChart("DNS latency, seconds").AddSeries(i.Name, l)
+		if latencyCheck.Matches(i) {
+			last := l.Last()
+			latencyCheck.Observe(last)
+			if last > latencyCheck.Threshold {
+				latencyCheck.AddItem(i.Name)
+			}
+		}
+	}
+
+	requestsChart := report.GetOrCreateChart("DNS requests by status, per second").Stacked()
+	for status, agg := range byStatus {
+		requestsChart.AddSeries(status, agg, dnsStatusColor(status))
+	}
+
+	if t, f := total.Get().Reduce(timeseries.NanSum), failed.Get().Reduce(timeseries.NanSum); t > 0 && !timeseries.IsNaN(f) {
+		errorsCheck.SetValue(f / t * 100)
+	}
+
+	table := report.GetOrCreateTable("Domain", "Type", "Failed requests").SetSorted(true)
+	for r, agg := range failedByDomain {
+		rate := agg.Get().Last()
+		if timeseries.IsNaN(rate) || rate == 0 {
+			continue
+		}
+		table.AddRow(
+			model.NewTableCell(r.Domain),
+			model.NewTableCell(r.Type),
+			model.NewTableCell(utils.FormatFloat(rate)).SetUnit("/s"),
+		)
+	}
+}
+
+func dnsFailed(status string) bool {
+	switch strings.ToLower(status) {
+	case "nxdomain", "servfail":
+		return true
+	}
+	return false
+}
+
+func dnsStatusColor(status string) string {
+	switch strings.ToLower(status) {
+	case "ok":
+		return "green"
+	case "nxdomain":
+		return "orange"
+	case "servfail":
+		return "red"
+	}
+	return "grey"
+}
@@ -0,0 +1,246 @@
+package auditor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"hash"
+	"hash/fnv"
+	"k8s.io/klog"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const fullAuditInterval = 5 * time.Minute
+
+// Incremental audits the worlds of the projects periodically loaded for the same sliding window (e.g., by the watchers).
+// It re-audits only the applications whose inputs (metrics, events, deployments, incidents) changed since the previous run,
+// as well as their upstream and downstream applications. The other applications get the reports of the previous run.
+// Since the reports are reused as is, their charts may refer to the previous window, so it suits only the consumers
+// of the check results and statuses. The checks of the reused reports are silenced again according to the current mutes
+// and maintenance windows. The time series are compared by all their values within the window, as the checks
+// (e.g., SLO burn rates, OOMs, restarts) evaluate the whole window: as the window slides, the fingerprint of an application
+// stays the same only while its metrics are steady (e.g., idle or unused applications). A full audit is performed
+// on any configuration change and every fullAuditInterval.
+type Incremental struct {
+	lock     sync.Mutex
+	projects map[db.ProjectId]*incrementalState
+}
+
+type incrementalState struct {
+	config      uint64
+	fullAuditAt time.Time
+	apps        map[model.ApplicationId]*auditResult
+}
+
+type auditResult struct {
+	fingerprint uint64
+	reports     []*model.AuditReport
+}
+
+func NewIncremental() *Incremental {
+	return &Incremental{projects: map[db.ProjectId]*incrementalState{}}
+}
+
+func (inc *Incremental) Audit(w *model.World, p *db.Project) {
+	inc.lock.Lock()
+	defer inc.lock.Unlock()
+
+	config := configFingerprint(w, p)
+	h := &fingerprinter{nodes: map[*model.Node]uint64{}}
+	fingerprints := make(map[model.ApplicationId]uint64, len(w.Applications))
+	for _, app := range w.Applications {
+		fingerprints[app.Id] = h.application(app)
+	}
+
+	prev := inc.projects[p.Id]
+	var reused map[model.ApplicationId]*auditResult
+	if prev != nil && prev.config == config && time.Since(prev.fullAuditAt) < fullAuditInterval {
+		reused = reusableResults(w, prev.apps, fingerprints)
+	}
+
+	audit(w, p, reused)
+
+	state := &incrementalState{config: config, fullAuditAt: time.Now(), apps: make(map[model.ApplicationId]*auditResult, len(w.Applications))}
+	if len(reused) > 0 {
+		state.fullAuditAt = prev.fullAuditAt
+	}
+	for _, app := range w.Applications {
+		state.apps[app.Id] = &auditResult{fingerprint: fingerprints[app.Id], reports: app.Reports}
+	}
+	inc.projects[p.Id] = state
+	klog.Infof("%s: audited %d of %d apps", p.Id, len(w.Applications)-len(reused), len(w.Applications))
+}
+
+func reusableResults(w *model.World, prev map[model.ApplicationId]*auditResult, fingerprints map[model.ApplicationId]uint64) map[model.ApplicationId]*auditResult {
+	changed := map[model.ApplicationId]bool{}
+	for id, fp := range fingerprints {
+		if r := prev[id]; r == nil || r.fingerprint != fp {
+			changed[id] = true
+		}
+	}
+	dirty := map[model.ApplicationId]bool{}
+	for _, app := range w.Applications {
+		if changed[app.Id] {
+			dirty[app.Id] = true
+		}
+		for _, upstreamId := range upstreamApps(app) {
+			if changed[app.Id] {
+				dirty[upstreamId] = true
+			}
+			if changed[upstreamId] {
+				dirty[app.Id] = true
+			}
+		}
+	}
+	res := map[model.ApplicationId]*auditResult{}
+	for _, app := range w.Applications {
+		if !dirty[app.Id] {
+			res[app.Id] = prev[app.Id]
+		}
+	}
+	return res
+}
+
+func configFingerprint(w *model.World, p *db.Project) uint64 {
+	d := fnv.New64a()
+	writeUint64(d, uint64(w.Ctx.Step))
+	for _, v := range []any{p.Settings, p.Prometheus, w.CheckConfigs} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			klog.Warningln(err)
+			writeUint64(d, uint64(time.Now().UnixNano()))
+			continue
+		}
+		_, _ = d.Write(data)
+	}
+	return d.Sum64()
+}
+
+var (
+	timeSeriesType = reflect.TypeOf(&timeseries.TimeSeries{})
+
+	fingerprintSkippedFields = map[reflect.Type]map[string]bool{
+		reflect.TypeOf(model.Application{}): {"Status": true, "Reports": true},
+	}
+)
+
+// fingerprinter hashes the inputs of an application: the values of its time series,
+// as well as the other data of the application, its instances, and the nodes they're running on.
+// The instances of the other applications are referred to by their names.
+type fingerprinter struct {
+	root    model.ApplicationId
+	visited map[uintptr]bool
+	nodes   map[*model.Node]uint64
+}
+
+func (f *fingerprinter) application(app *model.Application) uint64 {
+	f.root = app.Id
+	f.visited = map[uintptr]bool{}
+	d := fnv.New64a()
+	f.value(d, reflect.ValueOf(app).Elem())
+	return d.Sum64()
+}
+
+func (f *fingerprinter) node(n *model.Node) uint64 {
+	if fp, ok := f.nodes[n]; ok {
+		return fp
+	}
+	root, visited := f.root, f.visited
+	f.root, f.visited = model.ApplicationId{}, map[uintptr]bool{}
+	d := fnv.New64a()
+	f.value(d, reflect.ValueOf(n).Elem())
+	f.root, f.visited = root, visited
+	f.nodes[n] = d.Sum64()
+	return f.nodes[n]
+}
+
+func (f *fingerprinter) value(d hash.Hash64, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			writeUint64(d, 0)
+			return
+		}
+		if v.Type() == timeSeriesType {
+			iter := v.Interface().(*timeseries.TimeSeries).Iter()
+			for iter.Next() {
+				_, val := iter.Value()
+				writeUint64(d, uint64(math.Float32bits(val)))
+			}
+			return
+		}
+		switch p := v.Interface().(type) {
+		case *model.Application:
+			_, _ = d.Write([]byte(p.Id.String()))
+			return
+		case *model.Instance:
+			if p.OwnerId != f.root {
+				_, _ = d.Write([]byte(p.OwnerId.String() + "/" + p.Name))
+				return
+			}
+		case *model.Node:
+			writeUint64(d, f.node(p))
+			return
+		}
+		if f.visited[v.Pointer()] {
+			return
+		}
+		f.visited[v.Pointer()] = true
+		f.value(d, v.Elem())
+	case reflect.Interface:
+		if !v.IsNil() {
+			f.value(d, v.Elem())
+		}
+	case reflect.Struct:
+		t := v.Type()
+		skipped := fingerprintSkippedFields[t]
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || skipped[field.Name] {
+				continue
+			}
+			f.value(d, v.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		writeUint64(d, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			f.value(d, v.Index(i))
+		}
+	case reflect.Map:
+		writeUint64(d, uint64(v.Len()))
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			e := fnv.New64a()
+			f.value(e, iter.Key())
+			f.value(e, iter.Value())
+			sum += e.Sum64()
+		}
+		writeUint64(d, sum)
+	case reflect.String:
+		_, _ = d.Write([]byte(v.String()))
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint64(d, 1)
+		} else {
+			writeUint64(d, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(d, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(d, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint64(d, math.Float64bits(v.Float()))
+	}
+}
+
+func writeUint64(d hash.Hash64, v uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	_, _ = d.Write(buf[:])
+}
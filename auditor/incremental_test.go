@@ -0,0 +1,62 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	step := timeseries.Minute
+	fingerprint := func(from timeseries.Time, data ...float32) uint64 {
+		app := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "app"))
+		i := app.GetOrCreateInstance("app-1", nil)
+		i.LogMessagesByLevel[model.LogLevelError] = timeseries.NewWithData(from, step, data)
+		f := &fingerprinter{nodes: map[*model.Node]uint64{}}
+		return f.application(app)
+	}
+	nan := timeseries.NaN
+
+	fp := fingerprint(0, 5, 7, 10)
+	assert.Equal(t, fp, fingerprint(timeseries.Time(step), 5, 7, 10), "the window has moved, the values are the same")
+	assert.NotEqual(t, fp, fingerprint(timeseries.Time(step), 7, 10, 10), "the spike is leaving the window")
+	assert.NotEqual(t, fp, fingerprint(0, 5, 7, 10.01), "the latest value is slightly different")
+	assert.NotEqual(t, fp, fingerprint(0, 5, 7, 10, nan))
+	assert.NotEqual(t, fp, fingerprint(0, nan, nan, nan))
+	assert.Equal(t, fingerprint(0, nan, nan, nan), fingerprint(timeseries.Time(step), nan, nan, nan), "idle")
+}
+
+func TestAuditReusedResultsAreSilenced(t *testing.T) {
+	w := model.NewWorld(0, 3600, timeseries.Minute)
+	app := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "app"))
+	w.Applications = append(w.Applications, app)
+	r := model.NewAuditReport(app, w.Ctx, w.CheckConfigs, model.AuditReportInstances)
+	ch := r.CreateCheck(model.Checks.InstanceAvailability)
+	ch.SetStatus(model.CRITICAL, "no instances")
+	reused := map[model.ApplicationId]*auditResult{app.Id: {reports: []*model.AuditReport{r}}}
+
+	p := &db.Project{Id: "p"}
+	p.Settings.MaintenanceWindows = model.MaintenanceWindows{{From: 3000, To: 4000}}
+	audit(w, p, reused)
+	assert.Equal(t, model.INFO, ch.Status)
+	assert.True(t, ch.Maintenance)
+	assert.Equal(t, model.INFO, app.Status)
+
+	app.Status, app.Reports = model.UNKNOWN, nil
+	p.Settings.MaintenanceWindows = nil
+	p.Settings.CheckMutes = model.CheckMutes{{ApplicationId: app.Id, CheckId: ch.Id, Until: 4000}}
+	audit(w, p, reused)
+	assert.Equal(t, model.CRITICAL, ch.Status)
+	assert.False(t, ch.Maintenance)
+	assert.True(t, ch.Muted)
+	assert.Equal(t, model.OK, app.Status)
+
+	app.Status, app.Reports = model.UNKNOWN, nil
+	p.Settings.CheckMutes = nil
+	audit(w, p, reused)
+	assert.False(t, ch.Muted)
+	assert.Equal(t, model.CRITICAL, app.Status)
+	assert.Len(t, app.Reports, 1)
+}
@@ -32,6 +32,15 @@ func (a *appAuditor) instances() {
 			default:
 				status.SetStatus(model.OK, i.Rds.Status.Value())
 			}
+		} else if i.Gcp != nil {
+			switch {
+			case timeseries.IsNaN(i.Gcp.LifeSpan.Last()):
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			case !i.Gcp.IsAvailable():
+				status.SetStatus(model.WARNING, i.Gcp.Status.Value())
+			default:
+				status.SetStatus(model.OK, i.Gcp.Status.Value())
+			}
 		} else if i.Pod == nil {
 			if i.IsUp() {
 				status.SetStatus(model.OK, "ok")
@@ -101,7 +110,9 @@ func (a *appAuditor) instances() {
 		restartsCount := int64(0)
 		for _, c := range i.Containers {
 			if r := c.Restarts.Reduce(timeseries.NanSum); !timeseries.IsNaN(r) {
-				restarts.Inc(int64(r))
+				if restarts.Matches(i) {
+					restarts.Inc(int64(r))
+				}
 				restartsCount += int64(r)
 			}
 		}
@@ -143,7 +154,7 @@ func (a *appAuditor) instances() {
 		}
 	}
 
-	if a.app.Id.Kind == model.ApplicationKindExternalService {
+	if a.app.Id.Kind == model.ApplicationKindExternalService || a.app.Id.Kind == model.ApplicationKindAws {
 		availability.SetStatus(model.UNKNOWN, "no data")
 		restarts.SetStatus(model.UNKNOWN, "no data")
 	}
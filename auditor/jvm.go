@@ -32,15 +32,21 @@ func (a *appAuditor) jvm() {
 		}
 		status := model.NewTableCell().SetStatus(model.OK, "up")
 		if !i.Jvm.IsUp() {
-			availability.AddItem(i.Name)
+			if availability.Matches(i) {
+				availability.AddItem(i.Name)
+			}
 			status.SetStatus(model.WARNING, "down (no metrics)")
 		}
 		report.GetOrCreateTable("Instance", "Status").AddRow(
 			model.NewTableCell(i.Name).AddTag("java: %s", i.Jvm.JavaVersion.Value()),
 			status,
 		)
-		if i.Jvm.SafepointTime.Last() > safepointTime.Threshold {
-			safepointTime.AddItem(i.Name)
+		if safepointTime.Matches(i) {
+			last := i.Jvm.SafepointTime.Last()
+			safepointTime.Observe(last)
+			if last > safepointTime.Threshold {
+				safepointTime.AddItem(i.Name)
+			}
 		}
 	}
 }
@@ -0,0 +1,46 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func (a *appAuditor) logChecks() {
+	var report *model.AuditReport
+	step := float32(a.w.Ctx.Step) / 60
+	for i := range a.p.Settings.LogChecks {
+		lc := &a.p.Settings.LogChecks[i]
+		if !lc.Matches(a.app.Id) {
+			continue
+		}
+		if report == nil {
+			report = a.getOrAddReport(model.AuditReportLogs)
+		}
+		check := report.CreateCheck(lc.Config())
+		chart := report.GetOrCreateChart(lc.Title + ", messages per minute")
+		total := timeseries.NewAggregate(timeseries.NanSum)
+		for _, instance := range a.app.Instances {
+			byInstance := timeseries.NewAggregate(timeseries.NanSum)
+			for _, p := range instance.LogPatterns {
+				if lc.MatchesMessage(p.Sample) {
+					byInstance.Add(p.Sum)
+				}
+			}
+			perMinute := byInstance.Get().Map(func(t timeseries.Time, v float32) float32 {
+				return v / step
+			})
+			if !perMinute.IsEmpty() {
+				chart.AddSeries(instance.Name, perMinute)
+				total.Add(perMinute)
+			}
+		}
+		last := total.Get().Last()
+		if timeseries.IsNaN(last) {
+			last = 0
+		}
+		check.SetValue(last)
+		if last > check.Threshold {
+			chart.Feature()
+		}
+	}
+}
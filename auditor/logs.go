@@ -31,7 +31,9 @@ func (a *appAuditor) logs() {
 		Title: fmt.Sprintf("Repeated patters from the <var>%s</var>'s log", a.app.Id.Name),
 	}
 	totalEvents := uint64(0)
+	rawLogs := a.p.Settings.Integrations.RawLogsAvailable()
 
+	checkedErrors := timeseries.NewAggregate(timeseries.NanSum)
 	for _, instance := range a.app.Instances {
 		if len(instance.Containers) > 0 {
 			seenContainers = true
@@ -43,6 +45,9 @@ func (a *appAuditor) logs() {
 				byLevel[level] = ts
 			}
 			ts.Add(samples)
+			if (level == model.LogLevelError || level == model.LogLevelCritical) && check.Matches(instance) {
+				checkedErrors.Add(samples)
+			}
 		}
 		for hash, p := range instance.LogPatterns {
 			switch p.Level {
@@ -71,6 +76,9 @@ func (a *appAuditor) logs() {
 						Color:     logLevelColors[p.Level],
 						Instances: model.NewChart(a.w.Ctx, "Events by instance").Column(),
 					}
+					if rawLogs {
+						pattern.DrillDownLink = rawLogsLink("", hash)
+					}
 					byHash[hash] = pattern
 					patterns.Patterns = append(patterns.Patterns, pattern)
 				}
@@ -90,13 +98,11 @@ func (a *appAuditor) logs() {
 		p.Sum = sumByHash[h].Get()
 	}
 	eventsBySeverity := model.NewChart(a.w.Ctx, "Events by severity").Column()
+	if v := checkedErrors.Get().Reduce(timeseries.NanSum); v > 0 {
+		check.Inc(int64(v))
+	}
 	for _, l := range logLevels {
 		ts := byLevel[l].Get()
-		if l == model.LogLevelError || l == model.LogLevelCritical {
-			if v := ts.Reduce(timeseries.NanSum); v > 0 {
-				check.Inc(int64(v))
-			}
-		}
 		eventsBySeverity.AddSeries(strings.ToUpper(string(l)), ts, logLevelColors[l])
 	}
 	sort.Slice(patterns.Patterns, func(i, j int) bool {
@@ -107,8 +113,19 @@ func (a *appAuditor) logs() {
 	}
 	report.Widgets = append(report.Widgets, &model.Widget{Chart: eventsBySeverity, Width: "100%"})
 	report.Widgets = append(report.Widgets, &model.Widget{LogPatterns: patterns, Width: "100%"})
+	if rawLogs {
+		report.Widgets = append(report.Widgets, &model.Widget{RawLogs: &model.RawLogs{ApplicationId: a.app.Id}, Width: "100%"})
+	}
 
 	if !seenContainers {
 		check.SetStatus(model.UNKNOWN, "no data")
 	}
 }
+
+// rawLogsLink opens the log lines of the instance (or of all the instances if empty) matching the pattern
+// (or any of the error patterns if empty) in the Logs report. The time range of a clicked column is appended by the UI.
+func rawLogsLink(instance, patternHash string) *model.RouterLink {
+	return model.NewRouterLink("raw logs").
+		SetParam("report", model.AuditReportLogs).
+		SetArg("logs", instance+":"+patternHash+":")
+}
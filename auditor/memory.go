@@ -6,7 +6,7 @@ import (
 	"github.com/coroot/coroot/timeseries"
 )
 
-func (a *appAuditor) memory(ncs nodeConsumersByNode) {
+func (a *appAuditor) memory(ncs *nodeConsumersByNode) {
 	report := a.addReport(model.AuditReportMemory)
 	relevantNodes := map[string]*model.Node{}
 
@@ -29,6 +29,9 @@ func (a *appAuditor) memory(ncs nodeConsumersByNode) {
 			l.Add(c.MemoryLimit)
 			report.GetOrCreateChartInGroup(memoryUsageChartTitle, c.Name).AddSeries(i.Name, c.MemoryRss)
 			oom.Add(c.OOMKills)
+			if !leakCheck.Matches(i) {
+				continue
+			}
 			if lr := timeseries.NewLinearRegression(c.MemoryRss); lr != nil {
 				if v := (lr.Calc(now) - lr.Calc(now.Add(-timeseries.Hour))) / 1024 / 1024; !timeseries.IsNaN(v) {
 					leak += v
@@ -38,7 +41,7 @@ func (a *appAuditor) memory(ncs nodeConsumersByNode) {
 		oomTs := oom.Get()
 		report.GetOrCreateChart("Out of memory events").Column().AddSeries(i.Name, oomTs)
 
-		if ooms := oomTs.Reduce(timeseries.NanSum); ooms > 0 {
+		if ooms := oomTs.Reduce(timeseries.NanSum); ooms > 0 && oomCheck.Matches(i) {
 			oomCheck.Inc(int64(ooms))
 		}
 		if node := i.Node; node != nil {
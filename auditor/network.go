@@ -11,6 +11,8 @@ type netSummary struct {
 	rttMax   *timeseries.Aggregate
 	rttSum   *timeseries.Aggregate
 	rttCount *timeseries.Aggregate
+
+	checked map[*model.Check]*netSummary // the RTT from the instances the checks apply to
 }
 
 func newNetSummary() *netSummary {
@@ -29,11 +31,37 @@ func (s *netSummary) addRtt(rtt *timeseries.TimeSeries) {
 	s.rttCount.Add(rtt.Map(timeseries.Defined))
 }
 
+func (s *netSummary) avgRtt() *timeseries.TimeSeries {
+	return timeseries.Div(s.rttSum.Get(), s.rttCount.Get())
+}
+
+func (s *netSummary) addCheckedRtt(ch *model.Check, rtt *timeseries.TimeSeries) {
+	if s.checked == nil {
+		s.checked = map[*model.Check]*netSummary{}
+	}
+	cs := s.checked[ch]
+	if cs == nil {
+		cs = newNetSummary()
+		s.checked[ch] = cs
+	}
+	cs.addRtt(rtt)
+}
+
+// lastCheckedRtt returns the last average RTT from the instances the check applies to.
+func (s *netSummary) lastCheckedRtt(ch *model.Check) (float32, bool) {
+	cs := s.checked[ch]
+	if cs == nil {
+		return 0, false
+	}
+	return cs.avgRtt().Last(), true
+}
+
 func (a *appAuditor) network() {
 	report := a.addReport(model.AuditReportNetwork)
 	upstreams := map[model.ApplicationId]*netSummary{}
 
 	rttCheck := report.CreateCheck(model.Checks.NetworkRTT)
+	degradationCheck := report.CreateCheck(model.Checks.NetworkRTTDegradation)
 	seenConnections := false
 	for _, instance := range a.app.Instances {
 		for _, u := range instance.Upstreams {
@@ -56,6 +84,11 @@ func (a *appAuditor) network() {
 			}
 			if u.Rtt != nil {
 				summary.addRtt(u.Rtt)
+				for _, ch := range []*model.Check{rttCheck, degradationCheck} {
+					if ch.Matches(instance) {
+						summary.addCheckedRtt(ch, u.Rtt)
+					}
+				}
 			}
 			if instance.IsObsolete() || u.IsObsolete() {
 				linkStatus = model.UNKNOWN
@@ -74,16 +107,36 @@ func (a *appAuditor) network() {
 		}
 	}
 	for appId, summary := range upstreams {
-		avg := timeseries.Div(summary.rttSum.Get(), summary.rttCount.Get())
-		if avg.Last() > rttCheck.Threshold {
-			rttCheck.AddItem(appId.Name)
+		avg := summary.avgRtt()
+		if last, ok := summary.lastCheckedRtt(rttCheck); ok {
+			rttCheck.Observe(last)
+			if last > rttCheck.Threshold {
+				rttCheck.AddItem(appId.Name)
+			}
 		}
-		report.GetOrCreateChartInGroup("Network round-trip time to <selector>, seconds", appId.Name).
+		chart := report.GetOrCreateChartInGroup("Network round-trip time to <selector>, seconds", appId.Name).
 			AddSeries("min", summary.rttMin).
 			AddSeries("avg", avg).
 			AddSeries("max", summary.rttMax)
+		if baseline, ok := a.app.UpstreamRttBaselines[appId]; ok && baseline > 0 {
+			chart.SetThreshold("baseline", avg.WithNewValue(baseline))
+			if last, ok := summary.lastCheckedRtt(degradationCheck); ok && !timeseries.IsNaN(last) {
+				degradationCheck.Observe((last - baseline) / baseline * 100)
+				if model.RttDegraded(last, baseline, degradationCheck.Threshold) {
+					degradationCheck.AddItem(appId.Name)
+				}
+			}
+			if annotations := model.RttDegradationAnnotations(avg, baseline, degradationCheck.Threshold); len(annotations) > 0 {
+				chart.AddAnnotation(annotations...)
+				if a.rttDegradations == nil {
+					a.rttDegradations = map[model.ApplicationId][]model.Annotation{}
+				}
+				a.rttDegradations[appId] = append(a.rttDegradations[appId], annotations...)
+			}
+		}
 	}
 	if !seenConnections {
 		rttCheck.SetStatus(model.UNKNOWN, "no data")
+		degradationCheck.SetStatus(model.UNKNOWN, "no data")
 	}
 }
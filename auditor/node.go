@@ -1,11 +1,12 @@
 package auditor
 
 import (
+	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
 )
 
-func AuditNode(w *model.World, node *model.Node) *model.AuditReport {
+func AuditNode(w *model.World, p *db.Project, node *model.Node) *model.AuditReport {
 	id := model.NewApplicationId("", model.ApplicationKindNode, node.Name.Value())
 	report := model.NewAuditReport(model.NewApplication(id), w.Ctx, nil, model.AuditReportNode)
 
@@ -43,6 +44,16 @@ func AuditNode(w *model.World, node *model.Node) *model.AuditReport {
 			AddSeries("out", i.TxBytes.Map(func(t timeseries.Time, v float32) float32 { return v * 8 }), "blue")
 	}
 
+	if !node.ConntrackEntries.IsEmpty() {
+		report.GetOrCreateChart("Conntrack table, entries").
+			AddSeries("entries", node.ConntrackEntries).
+			SetThreshold("limit", node.ConntrackLimit)
+	}
+
+	annotations := projectAnnotations(p, model.ApplicationIdZero, w.Ctx)
+	for _, widget := range report.Widgets {
+		widget.AddAnnotation(annotations...)
+	}
 	return report
 }
 
@@ -49,8 +49,12 @@ func (a *appAuditor) postgres() {
 			GetOrCreateChartInGroup("Postgres query latency <selector>, seconds", "overview").
 			Feature().
 			AddSeries(i.Name, i.Postgres.Avg)
-		if i.Postgres.Avg.Last() > latencyCheck.Threshold {
-			latencyCheck.AddItem(i.Name)
+		if latencyCheck.Matches(i) {
+			last := i.Postgres.Avg.Last()
+			latencyCheck.Observe(last)
+			if last > latencyCheck.Threshold {
+				latencyCheck.AddItem(i.Name)
+			}
 		}
 		report.
 			GetOrCreateChartInGroup("Postgres query latency <selector>, seconds", i.Name).
@@ -95,7 +99,9 @@ func (a *appAuditor) postgres() {
 		}
 		status := model.NewTableCell().SetStatus(model.OK, "up")
 		if !i.Postgres.IsUp() {
-			availabilityCheck.AddItem(i.Name)
+			if availabilityCheck.Matches(i) {
+				availabilityCheck.AddItem(i.Name)
+			}
 			status.SetStatus(model.WARNING, "down (no metrics)")
 		}
 		errorsCell := model.NewTableCell()
@@ -103,7 +109,7 @@ func (a *appAuditor) postgres() {
 			errorsCheck.Inc(int64(total))
 			errorsCell.SetValue(fmt.Sprintf("%.0f", total))
 		}
-		lagCell := checkReplicationLag(i.Name, primaryLsnTs, lag, role, replicationCheck)
+		lagCell := checkReplicationLag(i, primaryLsnTs, lag, role, replicationCheck)
 		report.
 			GetOrCreateTable("Instance", "Role", "Status", "Queries", "Latency", "Errors", "Replication lag").
 			AddRow(
@@ -116,6 +122,23 @@ func (a *appAuditor) postgres() {
 				lagCell,
 			)
 	}
+
+	if a.p.Settings.Integrations.RawLogsAvailable() {
+		for _, ch := range report.GetOrCreateChartGroup("Errors <selector>").Charts {
+			instance := ch.Title
+			if instance == "overview" {
+				instance = ""
+			}
+			ch.DrillDownLink = rawLogsLink(instance, "")
+		}
+	}
+
+	if len(a.app.LatencySLIs) > 0 {
+		report.
+			GetOrCreateChart("Query latency heatmap, queries per second").
+			Heatmap().
+			AddHistogram(a.app.LatencySLIs[0].Histogram)
+	}
 }
 
 func errorsByPattern(instance *model.Instance) map[string]model.SeriesData {
@@ -133,7 +156,7 @@ func errorsByPattern(instance *model.Instance) map[string]model.SeriesData {
 	return res
 }
 
-func checkReplicationLag(instanceName string, primaryLsn, lag *timeseries.TimeSeries, role model.ClusterRole, check *model.Check) *model.TableCell {
+func checkReplicationLag(instance *model.Instance, primaryLsn, lag *timeseries.TimeSeries, role model.ClusterRole, check *model.Check) *model.TableCell {
 	res := &model.TableCell{}
 	if primaryLsn.IsEmpty() {
 		return res
@@ -165,8 +188,8 @@ func checkReplicationLag(instanceName string, primaryLsn, lag *timeseries.TimeSe
 	if tPast.IsZero() {
 		greaterThanWorldWindow = ">"
 	}
-	if lagTime > timeseries.Duration(check.Threshold) {
-		check.AddItem(instanceName)
+	if check.Matches(instance) && check.Exceeds(float32(lagTime/timeseries.Second), model.CheckUnitSecond) {
+		check.AddItem(instance.Name)
 	}
 	res.Value, res.Unit = utils.FormatBytes(last)
 	if lagTime > 0 {
@@ -215,8 +238,10 @@ func pgConnections(report *model.AuditReport, instance *model.Instance, connecti
 			total += last
 		}
 	}
-	if max := instance.Postgres.Settings["max_connections"].Samples.Last(); max > 0 && total > 0 {
-		if total/max*100 > connectionsCheck.Threshold {
+	if max := instance.Postgres.Settings["max_connections"].Samples.Last(); max > 0 && total > 0 && connectionsCheck.Matches(instance) {
+		usage := total / max * 100
+		connectionsCheck.Observe(usage)
+		if connectionsCheck.Exceeds(usage, model.CheckUnitPercent) {
 			connectionsCheck.AddItem(instance.Name)
 		}
 	}
@@ -264,23 +289,41 @@ func pgLocks(report *model.AuditReport, instance *model.Instance) {
 }
 
 func pgQueries(report *model.AuditReport, instance *model.Instance) {
-	totalTime := map[string]model.SeriesData{}
-	ioTime := map[string]model.SeriesData{}
+	const allDatabases = "all databases"
+	totalTime := map[string]map[string]model.SeriesData{}
+	ioTime := map[string]map[string]model.SeriesData{}
 	for k, stat := range instance.Postgres.PerQuery {
 		q := k.String()
-		totalTime[q] = stat.TotalTime
-		ioTime[q] = stat.IoTime
+		for _, db := range []string{allDatabases, k.Db} {
+			if db == "" {
+				continue
+			}
+			if totalTime[db] == nil {
+				totalTime[db] = map[string]model.SeriesData{}
+				ioTime[db] = map[string]model.SeriesData{}
+			}
+			totalTime[db][q] = stat.TotalTime
+			ioTime[db][q] = stat.IoTime
+		}
+	}
+	for _, g := range []struct {
+		title string
+		byDb  map[string]map[string]model.SeriesData
+	}{
+		{title: "Queries by total time on <selector>, query seconds/second", byDb: totalTime},
+		{title: "Queries by I/O time on <selector>, query seconds/second", byDb: ioTime},
+	} {
+		report.GetOrCreateChartGroup(g.title).
+			WithSelectors("instance", "database").
+			WithDefault(model.SelectChart("", allDatabases))
+		for db, queries := range g.byDb {
+			report.
+				GetOrCreateChartInGroupByPath(g.title, instance.Name, db).
+				Stacked().
+				Sorted().
+				AddMany(queries, 5, timeseries.NanSum)
+		}
 	}
-	report.
-		GetOrCreateChartInGroup("Queries by total time on <selector>, query seconds/second", instance.Name).
-		Stacked().
-		Sorted().
-		AddMany(totalTime, 5, timeseries.NanSum)
-	report.
-		GetOrCreateChartInGroup("Queries by I/O time on <selector>, query seconds/second", instance.Name).
-		Stacked().
-		Sorted().
-		AddMany(ioTime, 5, timeseries.NanSum)
 }
 
 func sumQueries(byDB map[string]*timeseries.TimeSeries) *timeseries.TimeSeries {
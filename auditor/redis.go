@@ -17,15 +17,7 @@ func (a *appAuditor) redis() {
 		if i.Redis == nil {
 			continue
 		}
-		total := timeseries.NewAggregate(timeseries.NanSum)
-		calls := timeseries.NewAggregate(timeseries.NanSum)
-		for cmd, t := range i.Redis.CallsTime {
-			if c, ok := i.Redis.Calls[cmd]; ok {
-				total.Add(t)
-				calls.Add(c)
-			}
-		}
-		avg := timeseries.Div(total.Get(), calls.Get())
+		avg := redisLatency(i.Redis)
 		report.
 			GetOrCreateChart("Redis latency, seconds").
 			AddSeries(i.Name, avg)
@@ -36,7 +28,9 @@ func (a *appAuditor) redis() {
 
 		status := model.NewTableCell().SetStatus(model.OK, "up")
 		if !i.Redis.IsUp() {
-			availability.AddItem(i.Name)
+			if availability.Matches(i) {
+				availability.AddItem(i.Name)
+			}
 			status.SetStatus(model.WARNING, "down (no metrics)")
 		}
 		roleCell := model.NewTableCell(i.Redis.Role.Value())
@@ -57,8 +51,12 @@ func (a *appAuditor) redis() {
 			Sorted().
 			AddMany(byCmd, 5, timeseries.NanSum)
 
-		if avg.Last() > latency.Threshold {
-			latency.AddItem(i.Name)
+		if latency.Matches(i) {
+			last := avg.Last()
+			latency.Observe(last)
+			if last > latency.Threshold {
+				latency.AddItem(i.Name)
+			}
 		}
 		report.GetOrCreateTable("Instance", "Role", "Status").AddRow(
 			model.NewTableCell(i.Name).AddTag("version: %s", i.Redis.Version.Value()),
@@ -67,3 +65,15 @@ func (a *appAuditor) redis() {
 		)
 	}
 }
+
+func redisLatency(r *model.Redis) *timeseries.TimeSeries {
+	total := timeseries.NewAggregate(timeseries.NanSum)
+	calls := timeseries.NewAggregate(timeseries.NanSum)
+	for cmd, t := range r.CallsTime {
+		if c, ok := r.Calls[cmd]; ok {
+			total.Add(t)
+			calls.Add(c)
+		}
+	}
+	return timeseries.Div(total.Get(), calls.Get())
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/profiling"
 	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
 	"strings"
@@ -12,16 +13,116 @@ import (
 func (a *appAuditor) slo() {
 	report := a.addReport(model.AuditReportSLO)
 	requestsChart(a.app, report, a.p)
-	availability(a.w.Ctx, a.app, report)
-	latency(a.w.Ctx, a.app, report)
+	av := availability(a.w.Ctx, a.app, report)
+	lat := latency(a.w.Ctx, a.app, report)
+	burnRates(a.w.Ctx, report, av, lat)
+	errorBudgets(a.w.Ctx, a.app, report, av, lat)
 	clientRequests(a.app, report)
+
+	if a.p.Settings.Integrations.Pyroscope != nil {
+		for _, ch := range report.Checks {
+			if ch.Id == model.Checks.SLOLatency.Id && ch.Status >= model.WARNING {
+				from := a.w.Ctx.To.Add(-timeseries.Hour)
+				if from.Before(a.w.Ctx.From) {
+					from = a.w.Ctx.From
+				}
+				report.AddFlameGraph("CPU profile over the last hour", profiling.TypeCPU, "", from, a.w.Ctx.To)
+			}
+		}
+	}
+
+	if a.p.Settings.Integrations.Clickhouse != nil && len(a.app.LatencySLIs) > 0 {
+		for _, ch := range report.Checks {
+			if ch.Id == model.Checks.SLOLatency.Id && ch.Status >= model.WARNING {
+				from := a.w.Ctx.To.Add(-timeseries.Hour)
+				if from.Before(a.w.Ctx.From) {
+					from = a.w.Ctx.From
+				}
+				// the same selection as the one made on the heatmap: the requests slower than the objective over the last hour
+				trace := fmt.Sprintf("::%d-%d:%s-inf:", int64(from)*1000, int64(a.w.Ctx.To)*1000, fmt.Sprint(a.app.LatencySLIs[0].Config.ObjectiveBucket))
+				ch.DrillDownLink = model.NewRouterLink("slow requests traces").SetParam("report", model.AuditReportTracing).SetArg("trace", trace)
+			}
+		}
+	}
+}
+
+type sloData struct {
+	name                string
+	bad, total          *timeseries.TimeSeries
+	badRaw, totalRaw    *timeseries.TimeSeries
+	objectivePercentage float32
+}
+
+func burnRates(ctx timeseries.Context, report *model.AuditReport, slos ...*sloData) {
+	fast := report.CreateCheck(model.Checks.SLOFastBurn)
+	slow := report.CreateCheck(model.Checks.SLOSlowBurn)
+	seen := false
+	for _, s := range slos {
+		if s == nil {
+			continue
+		}
+		seen = true
+		report.GetOrCreateChart("Error budget remaining, %").AddSeries(s.name, model.ErrorBudgetRemaining(s.bad, s.total, s.objectivePercentage))
+		for _, c := range []struct {
+			check *model.Check
+			rules []model.AlertRule
+		}{{fast, model.FastBurnAlertRules}, {slow, model.SlowBurnAlertRules}} {
+			br := model.CheckBurnRatesWithRules(c.rules, ctx.To, s.badRaw, s.totalRaw, s.objectivePercentage)
+			if br.Severity > model.OK && br.Severity > c.check.Status {
+				c.check.SetStatus(br.Severity, "%s: %s", s.name, br.FormatSLOStatus())
+			}
+		}
+	}
+	if !seen {
+		fast.SetStatus(model.UNKNOWN, "no data")
+		slow.SetStatus(model.UNKNOWN, "no data")
+	}
+}
+
+func errorBudgets(ctx timeseries.Context, app *model.Application, report *model.AuditReport, slos ...*sloData) {
+	check := report.CreateCheck(model.Checks.SLOErrorBudget)
+	var table *model.Table
+	projected := timeseries.NaN
+	for _, s := range slos {
+		if s == nil {
+			continue
+		}
+		eb := model.NewErrorBudget(s.name, s.objectivePercentage, app.ErrorBudgetLedger, ctx.To)
+		if eb == nil {
+			continue
+		}
+		if table == nil {
+			table = report.GetOrCreateTable("SLO", "Objective", "Budget consumed", "Projected exhaustion")
+		}
+		p := eb.Projected()
+		if timeseries.IsNaN(projected) || p > projected {
+			projected = p
+		}
+		consumed := model.NewTableCell(fmt.Sprintf("%.0f%%", eb.ConsumedPercentage()))
+		exhaustion := model.NewTableCell("-")
+		if t := eb.Exhaustion(); !t.IsZero() && t.Before(eb.To) {
+			exhaustion.SetValue(t.ToStandard().UTC().Format("Jan 2, 15:04 UTC"))
+			exhaustion.UpdateStatus(check.ValueStatus(p))
+		}
+		table.AddRow(
+			model.NewTableCell(s.name),
+			model.NewTableCell(utils.FormatPercentage(s.objectivePercentage)),
+			consumed,
+			exhaustion,
+		)
+	}
+	if timeseries.IsNaN(projected) {
+		check.SetStatus(model.UNKNOWN, "no data")
+		return
+	}
+	check.SetValue(projected)
 }
 
-func availability(ctx timeseries.Context, app *model.Application, report *model.AuditReport) {
+func availability(ctx timeseries.Context, app *model.Application, report *model.AuditReport) *sloData {
 	check := report.CreateCheck(model.Checks.SLOAvailability)
 	if len(app.AvailabilitySLIs) == 0 {
 		check.SetStatus(model.UNKNOWN, "not configured")
-		return
+		return nil
 	}
 	sli := app.AvailabilitySLIs[0]
 
@@ -49,29 +150,33 @@ func availability(ctx timeseries.Context, app *model.Application, report *model.
 
 	if sli.TotalRequestsRaw.IsEmpty() {
 		check.SetStatus(model.UNKNOWN, "no data")
-		return
+		return nil
 	}
 	if model.DataIsMissing(sli.TotalRequestsRaw) {
 		check.SetStatus(model.WARNING, "no data")
-		return
+		return nil
 	}
 
-	failedRaw := sli.FailedRequestsRaw
-	if failedRaw.IsEmpty() {
-		failedRaw = sli.TotalRequestsRaw.WithNewValue(0)
-	} else {
-		failedRaw = failedRaw.Map(timeseries.NanToZero)
-	}
+	_, failedRaw := sli.GetTotalAndFailed(true)
 	if br := model.CheckBurnRates(ctx.To, failedRaw, sli.TotalRequestsRaw, sli.Config.ObjectivePercentage); br.Severity > model.UNKNOWN {
 		check.SetStatus(br.Severity, br.FormatSLOStatus())
 	}
+	_, failed := sli.GetTotalAndFailed(false)
+	return &sloData{
+		name:                model.ErrorBudgetAvailability,
+		bad:                 failed,
+		total:               sli.TotalRequests,
+		badRaw:              failedRaw,
+		totalRaw:            sli.TotalRequestsRaw,
+		objectivePercentage: sli.Config.ObjectivePercentage,
+	}
 }
 
-func latency(ctx timeseries.Context, app *model.Application, report *model.AuditReport) {
+func latency(ctx timeseries.Context, app *model.Application, report *model.AuditReport) *sloData {
 	check := report.CreateCheck(model.Checks.SLOLatency)
 	if len(app.LatencySLIs) == 0 {
 		check.SetStatus(model.UNKNOWN, "not configured")
-		return
+		return nil
 	}
 	sli := app.LatencySLIs[0]
 
@@ -97,11 +202,11 @@ func latency(ctx timeseries.Context, app *model.Application, report *model.Audit
 	totalRaw, fastRaw := sli.GetTotalAndFast(true)
 	if totalRaw.IsEmpty() {
 		check.SetStatus(model.UNKNOWN, "no data")
-		return
+		return nil
 	}
 	if model.DataIsMissing(totalRaw) {
 		check.SetStatus(model.WARNING, "no data")
-		return
+		return nil
 	}
 
 	if fastRaw.IsEmpty() {
@@ -113,6 +218,17 @@ func latency(ctx timeseries.Context, app *model.Application, report *model.Audit
 	if br := model.CheckBurnRates(ctx.To, slowRaw, totalRaw, sli.Config.ObjectivePercentage); br.Severity > model.UNKNOWN {
 		check.SetStatus(br.Severity, br.FormatSLOStatus())
 	}
+	if fast.IsEmpty() {
+		fast = total.WithNewValue(0)
+	}
+	return &sloData{
+		name:                model.ErrorBudgetLatency,
+		bad:                 timeseries.Sub(total, fast.Map(timeseries.NanToZero)),
+		total:               total,
+		badRaw:              slowRaw,
+		totalRaw:            totalRaw,
+		objectivePercentage: sli.Config.ObjectivePercentage,
+	}
 }
 
 func requestsChart(app *model.Application, report *model.AuditReport, p *db.Project) {
@@ -0,0 +1,63 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestErrorBudgets(t *testing.T) {
+	now := timeseries.Time(time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC).Unix())
+	from, _ := model.ErrorBudgetPeriod(now)
+	ledger := func(slo string, badPercentage float32) []model.ErrorBudgetRecord {
+		var res []model.ErrorBudgetRecord
+		for h := from; h.Before(now); h = h.Add(timeseries.Hour) {
+			res = append(res, model.ErrorBudgetRecord{SLO: slo, Time: h, Total: 1000, Bad: 1000 * badPercentage / 100})
+		}
+		return res
+	}
+
+	for _, c := range []struct {
+		name       string
+		ledger     []model.ErrorBudgetRecord
+		status     model.Status
+		rows       int
+		consumed   string
+		exhaustion bool
+	}{
+		{name: "no data", status: model.UNKNOWN},
+		{name: "ledger of another SLO", ledger: ledger(model.ErrorBudgetLatency, 5), status: model.UNKNOWN},
+		{name: "within budget", ledger: ledger(model.ErrorBudgetAvailability, 0.1), status: model.OK, rows: 1, consumed: "5%"},
+		{name: "on pace to be exhausted", ledger: ledger(model.ErrorBudgetAvailability, 1.5), status: model.WARNING, rows: 1, consumed: "73%", exhaustion: true},
+		{name: "exhausted", ledger: ledger(model.ErrorBudgetAvailability, 3), status: model.WARNING, rows: 1, consumed: "145%", exhaustion: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := timeseries.Context{From: now.Add(-timeseries.Hour), To: now, Step: timeseries.Minute}
+			app := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"))
+			app.ErrorBudgetLedger = c.ledger
+			report := model.NewAuditReport(app, ctx, nil, model.AuditReportSLO)
+
+			errorBudgets(ctx, app, report, nil, &sloData{name: model.ErrorBudgetAvailability, objectivePercentage: 99})
+
+			require.Len(t, report.Checks, 1)
+			ch := report.Checks[0]
+			ch.Calc()
+			assert.Equal(t, c.status, ch.Status)
+			if c.rows == 0 {
+				assert.Empty(t, report.Widgets)
+				return
+			}
+			require.Len(t, report.Widgets, 1)
+			table := report.Widgets[0].Table
+			require.NotNil(t, table)
+			require.Len(t, table.Rows, c.rows)
+			row := table.Rows[0]
+			assert.Equal(t, model.ErrorBudgetAvailability, row.Cells[0].Text())
+			assert.Equal(t, c.consumed, row.Cells[2].Text())
+			assert.Equal(t, c.exhaustion, row.Cells[3].Text() != "-", row.Cells[3].Text())
+		})
+	}
+}
@@ -25,8 +25,12 @@ func (a *appAuditor) storage() {
 					report.GetOrCreateChartInGroup("I/O utilization <selector>, %", v.MountPoint).
 						AddSeries(i.Name, d.IOUtilizationPercent)
 
-					if d.IOUtilizationPercent.Last() > ioCheck.Threshold {
-						ioCheck.AddItem("%s:%s", i.Name, v.MountPoint)
+					if ioCheck.Matches(i) {
+						last := d.IOUtilizationPercent.Last()
+						ioCheck.Observe(last)
+						if last > ioCheck.Threshold {
+							ioCheck.AddItem("%s:%s", i.Name, v.MountPoint)
+						}
 					}
 
 					report.GetOrCreateChartInGroup("IOPS <selector>", fullName).
@@ -45,6 +49,9 @@ func (a *appAuditor) storage() {
 					ioPercent := model.NewTableCell()
 					if last := d.IOUtilizationPercent.Last(); !timeseries.IsNaN(last) {
 						ioPercent.SetValue(fmt.Sprintf("%.0f%%", last))
+						if status := ioCheck.ValueStatus(last); status > model.OK {
+							ioPercent.UpdateStatus(status)
+						}
 					}
 					space := model.NewTableCell()
 					capacity := v.CapacityBytes.Last()
@@ -57,8 +64,14 @@ func (a *appAuditor) storage() {
 							humanize.Bytes(uint64(usage)),
 							humanize.Bytes(uint64(capacity))),
 						)
-						if percentage > spaceCheck.Threshold {
-							spaceCheck.AddItem("%s:%s", i.Name, v.MountPoint)
+						if spaceCheck.Matches(i) {
+							spaceCheck.Observe(percentage)
+							if percentage > spaceCheck.Threshold {
+								spaceCheck.AddItem("%s:%s", i.Name, v.MountPoint)
+							}
+						}
+						if status := spaceCheck.ValueStatus(percentage); status > model.OK {
+							space.UpdateStatus(status)
 						}
 					}
 					report.GetOrCreateTable("Volume", "Latency", "I/O", "Space", "Device").AddRow(
@@ -0,0 +1,67 @@
+package auditor
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/model"
+)
+
+// suppress marks the failed checks caused by failures of the upstream applications as suppressed,
+// so that only the root cause affects the statuses and notifications.
+func suppress(auditors []*appAuditor) {
+	failed := map[model.ApplicationId]map[model.CheckId]*model.Check{}
+	for _, a := range auditors {
+		for _, r := range a.reports {
+			for _, ch := range r.Checks {
+				if ch.Status < model.WARNING || ch.Muted {
+					continue
+				}
+				if failed[a.app.Id] == nil {
+					failed[a.app.Id] = map[model.CheckId]*model.Check{}
+				}
+				failed[a.app.Id][ch.Id] = ch
+			}
+		}
+	}
+	if len(failed) == 0 {
+		return
+	}
+	for _, a := range auditors {
+		upstreams := upstreamApps(a.app)
+		if len(upstreams) == 0 {
+			continue
+		}
+		for _, r := range a.reports {
+			for _, ch := range r.Checks {
+				if ch.Status < model.WARNING || ch.Muted {
+					continue
+				}
+			causes:
+				for _, causeId := range model.CheckCauses(ch.Id) {
+					for _, upstreamId := range upstreams {
+						if cause := failed[upstreamId][causeId]; cause != nil {
+							ch.SuppressedBy = fmt.Sprintf("%s / %s", upstreamId.Name, cause.Title)
+							break causes
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func upstreamApps(app *model.Application) []model.ApplicationId {
+	seen := map[model.ApplicationId]bool{}
+	var res []model.ApplicationId
+	for _, i := range app.Instances {
+		for _, u := range i.Upstreams {
+			if u.RemoteInstance == nil || u.RemoteInstance.OwnerId == app.Id || u.IsObsolete() {
+				continue
+			}
+			if id := u.RemoteInstance.OwnerId; !seen[id] {
+				seen[id] = true
+				res = append(res, id)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+package auditor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) tls() {
+	seen := false
+	for _, i := range a.app.Instances {
+		if len(i.TlsCertificates) > 0 {
+			seen = true
+			break
+		}
+	}
+	if !seen {
+		return
+	}
+	report := a.getOrAddReport(model.AuditReportNetwork)
+	check := report.CreateCheck(model.Checks.TlsCertificateExpiry)
+	table := report.GetOrCreateTable("TLS certificate", "Direction", "Expires in").SetSorted(true)
+	now := timeseries.Now()
+	expiries := map[model.TlsEndpoint]*timeseries.Aggregate{}
+	checked := map[model.TlsEndpoint]bool{}
+	for _, i := range a.app.Instances {
+		for e, ts := range i.TlsCertificates {
+			agg := expiries[e]
+			if agg == nil {
+				agg = timeseries.NewAggregate(timeseries.Min)
+				expiries[e] = agg
+			}
+			agg.Add(ts)
+			if check.Matches(i) {
+				checked[e] = true
+			}
+		}
+	}
+	for e, agg := range expiries {
+		ts := agg.Get()
+		if expiresAt := ts.Last(); checked[e] && !timeseries.IsNaN(expiresAt) && timeseries.Time(expiresAt).Sub(now) < timeseries.Duration(check.Threshold) {
+			check.AddItem(e.Endpoint)
+		}
+		report.GetOrCreateChart("Time until TLS certificate expiry, days").AddSeries(e.Endpoint, ts.Map(func(t timeseries.Time, v float32) float32 {
+			return (v - float32(t)) / float32(timeseries.Day)
+		}))
+		direction := "server"
+		if e.Client {
+			direction = "client"
+		}
+		expiresIn := model.NewTableCell()
+		if expiresAt := ts.Last(); !timeseries.IsNaN(expiresAt) {
+			d := timeseries.Time(expiresAt).Sub(now)
+			if d > 0 {
+				expiresIn.SetValue(utils.FormatDuration(d, 1))
+			} else {
+				expiresIn.SetValue("expired")
+			}
+			if d < timeseries.Duration(check.Threshold) {
+				expiresIn.UpdateStatus(model.WARNING)
+			}
+		}
+		table.AddRow(model.NewTableCell(e.Endpoint), model.NewTableCell(direction), expiresIn)
+	}
+}
@@ -3,6 +3,7 @@ package auditor
 import (
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
+	"sync"
 )
 
 type nodeConsumers struct {
@@ -31,13 +32,18 @@ func getNodeConsumers(node *model.Node) *nodeConsumers {
 	return nc
 }
 
-type nodeConsumersByNode map[string]*nodeConsumers
+type nodeConsumersByNode struct {
+	lock   sync.Mutex
+	byNode map[string]*nodeConsumers
+}
 
-func (m nodeConsumersByNode) get(node *model.Node) *nodeConsumers {
-	ncs := m[node.Name.Value()]
+func (m *nodeConsumersByNode) get(node *model.Node) *nodeConsumers {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ncs := m.byNode[node.Name.Value()]
 	if ncs == nil {
 		ncs = getNodeConsumers(node)
-		m[node.Name.Value()] = ncs
+		m.byNode[node.Name.Value()] = ncs
 	}
 	return ncs
 }
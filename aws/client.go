@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/pi"
+	rdsapi "github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/coroot/coroot/utils"
+	"strings"
+)
+
+// Config defines how to access the AWS API. If the access key isn't set, the default credential chain is used
+// (environment variables, the shared credentials file, the IAM role of the EC2 instance or the EKS service account).
+type Config struct {
+	Region          string
+	AccessKeyId     string
+	SecretAccessKey string
+	RoleArn         string
+}
+
+type Client struct {
+	region     string
+	rds        *rdsapi.RDS
+	cloudwatch *cloudwatch.CloudWatch
+	logs       *cloudwatchlogs.CloudWatchLogs
+	pi         *pi.PI
+	tagging    *resourcegroupstaggingapi.ResourceGroupsTaggingAPI
+	elbv2      *elbv2.ELBV2
+}
+
+func NewClient(cfg Config) (*Client, error) {
+	awsCfg := sdk.NewConfig().WithRegion(cfg.Region)
+	if cfg.AccessKeyId != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(cfg.AccessKeyId, cfg.SecretAccessKey, ""))
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RoleArn != "" {
+		sess = sess.Copy(sdk.NewConfig().WithCredentials(stscreds.NewCredentials(sess, cfg.RoleArn)))
+	}
+	return &Client{
+		region:     cfg.Region,
+		rds:        rdsapi.New(sess),
+		cloudwatch: cloudwatch.New(sess),
+		logs:       cloudwatchlogs.New(sess),
+		pi:         pi.New(sess),
+		tagging:    resourcegroupstaggingapi.New(sess),
+		elbv2:      elbv2.New(sess),
+	}, nil
+}
+
+// Instance is an RDS instance or an Aurora cluster member.
+type Instance struct {
+	Identifier       string
+	ResourceId       string
+	Engine           string
+	EngineVersion    string
+	InstanceClass    string
+	StorageType      string
+	AvailabilityZone string
+	MultiAz          bool
+	Address          string
+	Port             int64
+	Status           string
+	AllocatedStorage int64 // GiB
+	Iops             int64
+
+	EnhancedMonitoring  bool
+	PerformanceInsights bool
+}
+
+// Id is the value of the rds_instance_id label the metrics of the instance are stored with.
+func (i Instance) Id(region string) string {
+	return region + "/" + i.Identifier
+}
+
+// IsPostgres reports whether the engine is PostgreSQL or Aurora PostgreSQL.
+func (i Instance) IsPostgres() bool {
+	return strings.Contains(i.Engine, "postgres")
+}
+
+// IsMysql reports whether the engine is MySQL, MariaDB or Aurora MySQL.
+func (i Instance) IsMysql() bool {
+	return strings.Contains(i.Engine, "mysql") || i.Engine == "mariadb" || i.Engine == "aurora"
+}
+
+// Instances returns the PostgreSQL and MySQL instances of the region whose identifiers match the patterns (all if no patterns are given).
+func (c *Client) Instances(ctx context.Context, patterns []string) ([]Instance, error) {
+	var res []Instance
+	err := c.rds.DescribeDBInstancesPagesWithContext(ctx, &rdsapi.DescribeDBInstancesInput{}, func(out *rdsapi.DescribeDBInstancesOutput, last bool) bool {
+		for _, db := range out.DBInstances {
+			i := Instance{
+				Identifier:          sdk.StringValue(db.DBInstanceIdentifier),
+				ResourceId:          sdk.StringValue(db.DbiResourceId),
+				Engine:              sdk.StringValue(db.Engine),
+				EngineVersion:       sdk.StringValue(db.EngineVersion),
+				InstanceClass:       sdk.StringValue(db.DBInstanceClass),
+				StorageType:         sdk.StringValue(db.StorageType),
+				AvailabilityZone:    sdk.StringValue(db.AvailabilityZone),
+				MultiAz:             sdk.BoolValue(db.MultiAZ),
+				Status:              sdk.StringValue(db.DBInstanceStatus),
+				AllocatedStorage:    sdk.Int64Value(db.AllocatedStorage),
+				Iops:                sdk.Int64Value(db.Iops),
+				EnhancedMonitoring:  sdk.Int64Value(db.MonitoringInterval) > 0,
+				PerformanceInsights: sdk.BoolValue(db.PerformanceInsightsEnabled),
+			}
+			if db.Endpoint != nil {
+				i.Address = sdk.StringValue(db.Endpoint.Address)
+				i.Port = sdk.Int64Value(db.Endpoint.Port)
+			}
+			if !i.IsPostgres() && !i.IsMysql() {
+				continue
+			}
+			if len(patterns) > 0 && !utils.GlobMatch(i.Identifier, patterns) {
+				continue
+			}
+			res = append(res, i)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe RDS instances: %w", err)
+	}
+	return res, nil
+}
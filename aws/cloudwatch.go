@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"context"
+	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"strings"
+	"time"
+)
+
+const cloudwatchPeriod = time.Minute
+
+// cloudwatchMetrics is used for the instances without Enhanced Monitoring: CloudWatch has no per-mode CPU usage and no disk latency,
+// so only the total CPU usage, the free memory and storage, the IOPS and the network throughput are available.
+func (c *Client) cloudwatchMetrics(ctx context.Context, s *series, i Instance, now time.Time) error {
+	names := []string{"CPUUtilization", "FreeableMemory", "FreeStorageSpace", "ReadIOPS", "WriteIOPS", "NetworkReceiveThroughput", "NetworkTransmitThroughput"}
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime: sdk.Time(now.Add(-5 * cloudwatchPeriod)),
+		EndTime:   sdk.Time(now),
+		ScanBy:    sdk.String(cloudwatch.ScanByTimestampDescending),
+	}
+	for _, name := range names {
+		input.MetricDataQueries = append(input.MetricDataQueries, &cloudwatch.MetricDataQuery{
+			Id: sdk.String(strings.ToLower(name)), // ids must start with a lowercase letter
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: &cloudwatch.Metric{
+					Namespace:  sdk.String("AWS/RDS"),
+					MetricName: sdk.String(name),
+					Dimensions: []*cloudwatch.Dimension{{Name: sdk.String("DBInstanceIdentifier"), Value: sdk.String(i.Identifier)}},
+				},
+				Period: sdk.Int64(int64(cloudwatchPeriod.Seconds())),
+				Stat:   sdk.String(cloudwatch.StatisticAverage),
+			},
+		})
+	}
+	out, err := c.cloudwatch.GetMetricDataWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+	last := map[string]float64{}
+	for _, r := range out.MetricDataResults {
+		if len(r.Values) > 0 && r.Values[0] != nil {
+			last[sdk.StringValue(r.Id)] = *r.Values[0] // the latest datapoint goes first
+		}
+	}
+	if v, ok := last["cpuutilization"]; ok {
+		s.add("aws_rds_cpu_usage_percent", v, "mode", "total")
+	}
+	if v, ok := last["freeablememory"]; ok {
+		s.add("aws_rds_memory_free_bytes", v)
+	}
+	if v, ok := last["freestoragespace"]; ok && i.AllocatedStorage > 0 {
+		total := float64(i.AllocatedStorage) * (1 << 30)
+		s.add("aws_rds_fs_total_bytes", total, "mount_point", "/rdsdbdata")
+		s.add("aws_rds_fs_used_bytes", total-v, "mount_point", "/rdsdbdata")
+	}
+	if v, ok := last["readiops"]; ok {
+		s.add("aws_rds_io_ops_per_second", v, "device", "rdsdev", "operation", "read")
+	}
+	if v, ok := last["writeiops"]; ok {
+		s.add("aws_rds_io_ops_per_second", v, "device", "rdsdev", "operation", "write")
+	}
+	if v, ok := last["networkreceivethroughput"]; ok {
+		s.add("aws_rds_net_rx_bytes_per_second", v, "interface", "eth0")
+	}
+	if v, ok := last["networktransmitthroughput"]; ok {
+		s.add("aws_rds_net_tx_bytes_per_second", v, "interface", "eth0")
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/prometheus/prometheus/prompb"
+	"strings"
+	"time"
+)
+
+const (
+	// cloudwatchMaxQueries is the max number of queries in a GetMetricData request.
+	cloudwatchMaxQueries = 500
+
+	// cloudwatchPublishDelay is the time after which the datapoints of managed services are considered final.
+	// CloudWatch keeps updating the values of the recent periods as the late data arrives, while the TSDB
+	// doesn't accept a new value for a timestamp it already has, so the recent periods aren't collected.
+	cloudwatchPublishDelay = 5 * time.Minute
+)
+
+type resourceMetric struct {
+	series string
+	metric string
+	stat   string
+	scale  float64 // e.g., 1/60 to convert the sum per minute to a rate per second
+}
+
+var (
+	perMinute = 1. / 60
+
+	albTargetGroupMetrics = []resourceMetric{
+		{series: "aws_target_group_requests_per_second", metric: "RequestCount", stat: cloudwatch.StatisticSum, scale: perMinute},
+		{series: "aws_target_group_errors_per_second", metric: "HTTPCode_Target_5XX_Count", stat: cloudwatch.StatisticSum, scale: perMinute},
+		{series: "aws_target_group_response_time_seconds", metric: "TargetResponseTime", stat: cloudwatch.StatisticAverage, scale: 1},
+		{series: "aws_target_group_healthy_targets", metric: "HealthyHostCount", stat: cloudwatch.StatisticMinimum, scale: 1},
+		{series: "aws_target_group_unhealthy_targets", metric: "UnHealthyHostCount", stat: cloudwatch.StatisticMaximum, scale: 1},
+	}
+	nlbTargetGroupMetrics = []resourceMetric{
+		{series: "aws_target_group_healthy_targets", metric: "HealthyHostCount", stat: cloudwatch.StatisticMinimum, scale: 1},
+		{series: "aws_target_group_unhealthy_targets", metric: "UnHealthyHostCount", stat: cloudwatch.StatisticMaximum, scale: 1},
+	}
+	sqsQueueMetrics = []resourceMetric{
+		{series: "aws_sqs_messages_visible", metric: "ApproximateNumberOfMessagesVisible", stat: cloudwatch.StatisticMaximum, scale: 1},
+		{series: "aws_sqs_oldest_message_age_seconds", metric: "ApproximateAgeOfOldestMessage", stat: cloudwatch.StatisticMaximum, scale: 1},
+		{series: "aws_sqs_messages_sent_per_second", metric: "NumberOfMessagesSent", stat: cloudwatch.StatisticSum, scale: perMinute},
+		{series: "aws_sqs_messages_deleted_per_second", metric: "NumberOfMessagesDeleted", stat: cloudwatch.StatisticSum, scale: perMinute},
+	}
+	lambdaFunctionMetrics = []resourceMetric{
+		{series: "aws_lambda_invocations_per_second", metric: "Invocations", stat: cloudwatch.StatisticSum, scale: perMinute},
+		{series: "aws_lambda_errors_per_second", metric: "Errors", stat: cloudwatch.StatisticSum, scale: perMinute},
+		{series: "aws_lambda_throttles_per_second", metric: "Throttles", stat: cloudwatch.StatisticSum, scale: perMinute},
+		{series: "aws_lambda_duration_seconds", metric: "Duration", stat: cloudwatch.StatisticAverage, scale: 0.001},
+	}
+)
+
+type resourceQuery struct {
+	metric resourceMetric
+	labels []prompb.Label
+	query  *cloudwatch.MetricDataQuery
+}
+
+// resourceQueries returns the CloudWatch queries of the resource metrics, the series are labeled with the application and the resource name.
+func resourceQueries(r Resource) []resourceQuery {
+	var namespace string
+	var metrics []resourceMetric
+	var dimensions []*cloudwatch.Dimension
+	labels := []prompb.Label{{Name: "application", Value: r.Application}}
+	dimension := func(name, value string) {
+		dimensions = append(dimensions, &cloudwatch.Dimension{Name: sdk.String(name), Value: sdk.String(value)})
+	}
+	switch r.Type {
+	case ResourceTypeTargetGroup:
+		if r.LoadBalancer == "" { // the target group isn't attached to a load balancer
+			return nil
+		}
+		namespace, metrics = "AWS/ApplicationELB", albTargetGroupMetrics
+		if strings.HasPrefix(r.LoadBalancer, "net/") {
+			namespace, metrics = "AWS/NetworkELB", nlbTargetGroupMetrics
+		}
+		dimension("TargetGroup", r.Dimension)
+		dimension("LoadBalancer", r.LoadBalancer)
+		labels = append(labels, prompb.Label{Name: "target_group", Value: r.Name}, prompb.Label{Name: "load_balancer", Value: segment(r.LoadBalancer, 1)})
+	case ResourceTypeSqsQueue:
+		namespace, metrics = "AWS/SQS", sqsQueueMetrics
+		dimension("QueueName", r.Dimension)
+		labels = append(labels, prompb.Label{Name: "queue", Value: r.Name})
+	case ResourceTypeLambdaFunction:
+		namespace, metrics = "AWS/Lambda", lambdaFunctionMetrics
+		dimension("FunctionName", r.Dimension)
+		labels = append(labels, prompb.Label{Name: "function", Value: r.Name})
+	default:
+		return nil
+	}
+	res := make([]resourceQuery, 0, len(metrics))
+	for _, m := range metrics {
+		res = append(res, resourceQuery{
+			metric: m,
+			labels: append([]prompb.Label{{Name: "__name__", Value: m.series}}, labels...),
+			query: &cloudwatch.MetricDataQuery{
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: &cloudwatch.Metric{Namespace: sdk.String(namespace), MetricName: sdk.String(m.metric), Dimensions: dimensions},
+					Period: sdk.Int64(int64(cloudwatchPeriod.Seconds())),
+					Stat:   sdk.String(m.stat),
+				},
+			},
+		})
+	}
+	return res
+}
+
+// CollectResources returns the CloudWatch metrics of the resources for the window ending cloudwatchPublishDelay ago.
+func (c *Client) CollectResources(ctx context.Context, resources []Resource, window time.Duration) ([]prompb.TimeSeries, error) {
+	var queries []resourceQuery
+	for _, r := range resources {
+		queries = append(queries, resourceQueries(r)...)
+	}
+	from, to := resourcesRange(time.Now(), window)
+	var res []prompb.TimeSeries
+	for start := 0; start < len(queries); start += cloudwatchMaxQueries {
+		end := start + cloudwatchMaxQueries
+		if end > len(queries) {
+			end = len(queries)
+		}
+		batch := queries[start:end]
+		input := &cloudwatch.GetMetricDataInput{
+			StartTime: sdk.Time(from),
+			EndTime:   sdk.Time(to),
+			ScanBy:    sdk.String(cloudwatch.ScanByTimestampAscending),
+		}
+		for i, q := range batch {
+			q.query.Id = sdk.String(fmt.Sprintf("q%d", i))
+			input.MetricDataQueries = append(input.MetricDataQueries, q.query)
+		}
+		err := c.cloudwatch.GetMetricDataPagesWithContext(ctx, input, func(out *cloudwatch.GetMetricDataOutput, last bool) bool {
+			for _, r := range out.MetricDataResults {
+				var i int
+				if _, err := fmt.Sscanf(sdk.StringValue(r.Id), "q%d", &i); err != nil || i >= len(batch) {
+					continue
+				}
+				if ts := resourceSeries(batch[i], r, to); len(ts.Samples) > 0 {
+					res = append(res, ts)
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cloudwatch metrics: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// resourcesRange returns the time range of the complete periods within the window, excluding the ones
+// CloudWatch may still update.
+func resourcesRange(now time.Time, window time.Duration) (time.Time, time.Time) {
+	to := now.Add(-cloudwatchPublishDelay).Truncate(cloudwatchPeriod)
+	return to.Add(-window), to
+}
+
+// resourceSeries converts the datapoints of the query to a series, the datapoints at or after the end of the range are skipped.
+func resourceSeries(q resourceQuery, r *cloudwatch.MetricDataResult, to time.Time) prompb.TimeSeries {
+	ts := prompb.TimeSeries{Labels: q.labels}
+	for j, t := range r.Timestamps {
+		if j < len(r.Values) && t != nil && r.Values[j] != nil && t.Before(to) {
+			ts.Samples = append(ts.Samples, prompb.Sample{Timestamp: t.UnixMilli(), Value: *r.Values[j] * q.metric.scale})
+		}
+	}
+	return ts
+}
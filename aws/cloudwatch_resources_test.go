@@ -0,0 +1,49 @@
+package aws
+
+import (
+	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestResourcesRange(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 30, 42, 0, time.UTC)
+	from, to := resourcesRange(now, 10*time.Minute)
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 25, 0, 0, time.UTC), to)
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 15, 0, 0, time.UTC), from)
+}
+
+func TestResourceQueries(t *testing.T) {
+	assert.Nil(t, resourceQueries(Resource{Type: ResourceTypeTargetGroup, Name: "tg"}), "not attached to a load balancer")
+
+	qs := resourceQueries(Resource{Type: ResourceTypeTargetGroup, Application: "default:Deployment:app", Name: "tg",
+		Dimension: "targetgroup/tg/1", LoadBalancer: "net/nlb/2"})
+	require.Len(t, qs, len(nlbTargetGroupMetrics))
+	assert.Equal(t, "AWS/NetworkELB", *qs[0].query.MetricStat.Metric.Namespace)
+	assert.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "aws_target_group_healthy_targets"},
+		{Name: "application", Value: "default:Deployment:app"},
+		{Name: "target_group", Value: "tg"},
+		{Name: "load_balancer", Value: "nlb"},
+	}, qs[0].labels)
+
+	qs = resourceQueries(Resource{Type: ResourceTypeSqsQueue, Name: "orders", Dimension: "orders"})
+	require.Len(t, qs, len(sqsQueueMetrics))
+	assert.Equal(t, "QueueName", *qs[0].query.MetricStat.Metric.Dimensions[0].Name)
+}
+
+func TestResourceSeries(t *testing.T) {
+	to := time.Date(2024, 1, 1, 12, 25, 0, 0, time.UTC)
+	q := resourceQuery{metric: resourceMetric{scale: perMinute}, labels: []prompb.Label{{Name: "__name__", Value: "m"}}}
+	r := &cloudwatch.MetricDataResult{
+		Timestamps: []*time.Time{sdk.Time(to.Add(-2 * time.Minute)), sdk.Time(to.Add(-time.Minute)), sdk.Time(to), nil},
+		Values:     []*float64{sdk.Float64(60), nil, sdk.Float64(120), sdk.Float64(1)},
+	}
+	ts := resourceSeries(q, r, to)
+	assert.Equal(t, q.labels, ts.Labels)
+	assert.Equal(t, []prompb.Sample{{Timestamp: to.Add(-2 * time.Minute).UnixMilli(), Value: 1}}, ts.Samples)
+}
@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+// enhancedMonitoringLogGroup is the CloudWatch Logs group RDS writes the OS metrics to, the log stream is named after the resource id.
+const enhancedMonitoringLogGroup = "RDSOSMetrics"
+
+// osMetrics is an Enhanced Monitoring record, memory and file system sizes are in KiB, the disk await is in milliseconds.
+type osMetrics struct {
+	NumVCPUs       float64            `json:"numVCPUs"`
+	CpuUtilization map[string]float64 `json:"cpuUtilization"`
+	Memory         struct {
+		Total  float64 `json:"total"`
+		Cached float64 `json:"cached"`
+		Free   float64 `json:"free"`
+	} `json:"memory"`
+	FileSys []struct {
+		MountPoint string  `json:"mountPoint"`
+		Total      float64 `json:"total"`
+		Used       float64 `json:"used"`
+	} `json:"fileSys"`
+	DiskIO []struct {
+		Device     string  `json:"device"`
+		Await      float64 `json:"await"`
+		Util       float64 `json:"util"`
+		ReadIOsPS  float64 `json:"readIOsPS"`
+		WriteIOsPS float64 `json:"writeIOsPS"`
+	} `json:"diskIO"`
+	Network []struct {
+		Interface string  `json:"interface"`
+		Rx        float64 `json:"rx"`
+		Tx        float64 `json:"tx"`
+	} `json:"network"`
+}
+
+func (c *Client) enhancedMonitoring(ctx context.Context, s *series, i Instance) error {
+	out, err := c.logs.GetLogEventsWithContext(ctx, &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  sdk.String(enhancedMonitoringLogGroup),
+		LogStreamName: sdk.String(i.ResourceId),
+		StartFromHead: sdk.Bool(false),
+		Limit:         sdk.Int64(1),
+	})
+	if err != nil {
+		return err
+	}
+	if len(out.Events) == 0 {
+		return fmt.Errorf("no events in the %s log stream", i.ResourceId)
+	}
+	var m osMetrics
+	if err = json.Unmarshal([]byte(sdk.StringValue(out.Events[len(out.Events)-1].Message)), &m); err != nil {
+		return err
+	}
+	m.add(s)
+	return nil
+}
+
+func (m *osMetrics) add(s *series) {
+	if m.NumVCPUs > 0 {
+		s.add("aws_rds_cpu_cores", m.NumVCPUs)
+	}
+	for mode, v := range m.CpuUtilization {
+		if mode == "idle" || mode == "total" {
+			continue
+		}
+		s.add("aws_rds_cpu_usage_percent", v, "mode", mode)
+	}
+	if m.Memory.Total > 0 {
+		s.add("aws_rds_memory_total_bytes", m.Memory.Total*1024)
+		s.add("aws_rds_memory_cached_bytes", m.Memory.Cached*1024)
+		s.add("aws_rds_memory_free_bytes", m.Memory.Free*1024)
+	}
+	for _, fs := range m.FileSys {
+		s.add("aws_rds_fs_total_bytes", fs.Total*1024, "mount_point", fs.MountPoint)
+		s.add("aws_rds_fs_used_bytes", fs.Used*1024, "mount_point", fs.MountPoint)
+	}
+	for _, d := range m.DiskIO {
+		if d.Device == "" { // Aurora reports the cluster storage I/O without a device
+			continue
+		}
+		s.add("aws_rds_io_await_seconds", d.Await/1000, "device", d.Device)
+		s.add("aws_rds_io_util_percent", d.Util, "device", d.Device)
+		s.add("aws_rds_io_ops_per_second", d.ReadIOsPS, "device", d.Device, "operation", "read")
+		s.add("aws_rds_io_ops_per_second", d.WriteIOsPS, "device", d.Device, "operation", "write")
+	}
+	for _, n := range m.Network {
+		s.add("aws_rds_net_rx_bytes_per_second", n.Rx, "interface", n.Interface)
+		s.add("aws_rds_net_tx_bytes_per_second", n.Tx, "interface", n.Interface)
+	}
+}
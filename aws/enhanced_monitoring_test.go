@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOsMetrics(t *testing.T) {
+	record := `{
+		"engine": "POSTGRES", "instanceID": "db-1", "numVCPUs": 2,
+		"cpuUtilization": {"user": 20.5, "system": 4.5, "idle": 75, "total": 25},
+		"memory": {"total": 4096, "cached": 1024, "free": 512},
+		"fileSys": [{"mountPoint": "/rdsdbdata", "total": 2048, "used": 1024}],
+		"diskIO": [{"device": "rdsdev", "await": 2.5, "util": 10, "readIOsPS": 5, "writeIOsPS": 7}, {"readThroughput": 100}],
+		"network": [{"interface": "eth0", "rx": 1000, "tx": 2000}]
+	}`
+	var m osMetrics
+	require.NoError(t, json.Unmarshal([]byte(record), &m))
+	s := newSeries(time.Unix(60, 0), map[string]string{"rds_instance_id": "us-east-1/db-1"})
+	m.add(s)
+
+	var res []string
+	for _, ts := range s.res {
+		res = append(res, format(ts))
+	}
+	sort.Strings(res)
+	assert.Equal(t, []string{
+		`aws_rds_cpu_cores{rds_instance_id="us-east-1/db-1"} 2 @60000`,
+		`aws_rds_cpu_usage_percent{rds_instance_id="us-east-1/db-1",mode="system"} 4.5 @60000`,
+		`aws_rds_cpu_usage_percent{rds_instance_id="us-east-1/db-1",mode="user"} 20.5 @60000`,
+		`aws_rds_fs_total_bytes{rds_instance_id="us-east-1/db-1",mount_point="/rdsdbdata"} 2.097152e+06 @60000`,
+		`aws_rds_fs_used_bytes{rds_instance_id="us-east-1/db-1",mount_point="/rdsdbdata"} 1.048576e+06 @60000`,
+		`aws_rds_io_await_seconds{rds_instance_id="us-east-1/db-1",device="rdsdev"} 0.0025 @60000`,
+		`aws_rds_io_ops_per_second{rds_instance_id="us-east-1/db-1",device="rdsdev",operation="read"} 5 @60000`,
+		`aws_rds_io_ops_per_second{rds_instance_id="us-east-1/db-1",device="rdsdev",operation="write"} 7 @60000`,
+		`aws_rds_io_util_percent{rds_instance_id="us-east-1/db-1",device="rdsdev"} 10 @60000`,
+		`aws_rds_memory_cached_bytes{rds_instance_id="us-east-1/db-1"} 1.048576e+06 @60000`,
+		`aws_rds_memory_free_bytes{rds_instance_id="us-east-1/db-1"} 524288 @60000`,
+		`aws_rds_memory_total_bytes{rds_instance_id="us-east-1/db-1"} 4.194304e+06 @60000`,
+		`aws_rds_net_rx_bytes_per_second{rds_instance_id="us-east-1/db-1",interface="eth0"} 1000 @60000`,
+		`aws_rds_net_tx_bytes_per_second{rds_instance_id="us-east-1/db-1",interface="eth0"} 2000 @60000`,
+	}, res)
+}
+
+func format(ts prompb.TimeSeries) string {
+	var name string
+	var labels []string
+	for _, l := range ts.Labels {
+		if l.Name == "__name__" {
+			name = l.Value
+			continue
+		}
+		labels = append(labels, l.Name+`="`+l.Value+`"`)
+	}
+	s := ts.Samples[0]
+	return fmt.Sprintf("%s{%s} %g @%d", name, strings.Join(labels, ","), s.Value, s.Timestamp)
+}
@@ -0,0 +1,115 @@
+package aws
+
+import (
+	"context"
+	"github.com/prometheus/prometheus/prompb"
+	"k8s.io/klog"
+	"net"
+	"strconv"
+	"time"
+)
+
+// connectTimeout is the timeout of the connectivity check of an instance.
+const connectTimeout = 5 * time.Second
+
+// series accumulates the samples in the format of coroot-aws-agent, so they're handled by the same code as the metrics
+// of the agent: aws_rds_* for the instance itself, pg_* for the PostgreSQL auditor.
+type series struct {
+	ts     int64
+	labels map[string]string
+	res    []prompb.TimeSeries
+}
+
+func newSeries(t time.Time, labels map[string]string) *series {
+	return &series{ts: t.UnixMilli(), labels: labels}
+}
+
+func (s *series) add(name string, value float64, labels ...string) {
+	ts := prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: "__name__", Value: name}},
+		Samples: []prompb.Sample{{Timestamp: s.ts, Value: value}},
+	}
+	for k, v := range s.labels {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: k, Value: v})
+	}
+	for i := 0; i+1 < len(labels); i += 2 {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: labels[i], Value: labels[i+1]})
+	}
+	s.res = append(s.res, ts)
+}
+
+// Collect returns the current metrics of the instances: the instance info and status, the OS metrics from Enhanced Monitoring
+// (or the basic CloudWatch metrics if it's disabled), and the top queries by DB load from Performance Insights.
+// The errors of the optional sources are logged, so that an instance still gets the metrics available.
+func (c *Client) Collect(ctx context.Context, instances []Instance, performanceInsights bool) []prompb.TimeSeries {
+	now := time.Now()
+	var res []prompb.TimeSeries
+	for _, i := range instances {
+		s := newSeries(now, map[string]string{"rds_instance_id": i.Id(c.region)})
+		c.instanceInfo(ctx, s, i)
+		if i.EnhancedMonitoring {
+			if err := c.enhancedMonitoring(ctx, s, i); err != nil {
+				klog.Warningf("%s: failed to get enhanced monitoring metrics: %s", i.Identifier, err)
+			}
+		} else if err := c.cloudwatchMetrics(ctx, s, i, now); err != nil {
+			klog.Warningf("%s: failed to get cloudwatch metrics: %s", i.Identifier, err)
+		}
+		if performanceInsights && i.PerformanceInsights && i.IsPostgres() {
+			if err := c.performanceInsights(ctx, s, i, now); err != nil {
+				klog.Warningf("%s: failed to get performance insights: %s", i.Identifier, err)
+			}
+		}
+		res = append(res, s.res...)
+	}
+	return res
+}
+
+func (c *Client) instanceInfo(ctx context.Context, s *series, i Instance) {
+	var ip string
+	if i.Address != "" {
+		if ips, err := net.DefaultResolver.LookupIP(ctx, "ip4", i.Address); err != nil {
+			klog.Warningf("%s: failed to resolve %s: %s", i.Identifier, i.Address, err)
+		} else if len(ips) > 0 {
+			ip = ips[0].String()
+		}
+	}
+	s.add("aws_rds_info", 1,
+		"ipv4", ip,
+		"port", strconv.FormatInt(i.Port, 10),
+		"engine", i.Engine,
+		"engine_version", i.EngineVersion,
+		"instance_type", i.InstanceClass,
+		"storage_type", i.StorageType,
+		"region", c.region,
+		"availability_zone", i.AvailabilityZone,
+		"multi_az", strconv.FormatBool(i.MultiAz),
+	)
+	s.add("aws_rds_status", 1, "status", i.Status)
+	if i.AllocatedStorage > 0 {
+		s.add("aws_rds_allocated_storage_gibibytes", float64(i.AllocatedStorage))
+	}
+	if i.Iops > 0 {
+		s.add("aws_rds_storage_provisioned_iops", float64(i.Iops))
+	}
+	if i.IsPostgres() {
+		up := 0.
+		if ip != "" && reachable(ctx, net.JoinHostPort(ip, strconv.FormatInt(i.Port, 10))) {
+			up = 1
+		}
+		s.add("pg_up", up)
+		s.add("pg_info", 1, "server_version", i.EngineVersion)
+	}
+}
+
+// reachable reports whether a TCP connection to the address can be established. Unlike the instance status,
+// it reflects whether the database is actually available to the applications (e.g., during a failover or a network issue).
+func reachable(ctx context.Context, addr string) bool {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
@@ -0,0 +1,19 @@
+package aws
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+)
+
+func TestReachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	assert.True(t, reachable(context.Background(), addr))
+
+	require.NoError(t, l.Close())
+	assert.False(t, reachable(context.Background(), addr))
+}
@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"context"
+	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pi"
+	"time"
+)
+
+const (
+	piPeriod     = time.Minute
+	piTopQueries = 20
+)
+
+// performanceInsights converts the DB load of the top statements to pg_top_query_time_per_second:
+// the average number of active sessions running a statement is the time spent executing it per second.
+func (c *Client) performanceInsights(ctx context.Context, s *series, i Instance, now time.Time) error {
+	out, err := c.pi.GetResourceMetricsWithContext(ctx, &pi.GetResourceMetricsInput{
+		ServiceType: sdk.String(pi.ServiceTypeRds),
+		Identifier:  sdk.String(i.ResourceId),
+		StartTime:   sdk.Time(now.Add(-5 * piPeriod)),
+		EndTime:     sdk.Time(now),
+		MetricQueries: []*pi.MetricQuery{{
+			Metric:  sdk.String("db.load.avg"),
+			GroupBy: &pi.DimensionGroup{Group: sdk.String("db.sql_tokenized"), Limit: sdk.Int64(piTopQueries)},
+		}},
+		PeriodInSeconds: sdk.Int64(int64(piPeriod.Seconds())),
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range out.MetricList {
+		if m.Key == nil || len(m.DataPoints) == 0 {
+			continue
+		}
+		query := sdk.StringValue(m.Key.Dimensions["db.sql_tokenized.statement"])
+		if query == "" {
+			continue
+		}
+		last := m.DataPoints[len(m.DataPoints)-1]
+		if last.Value == nil {
+			continue
+		}
+		s.add("pg_top_query_time_per_second", *last.Value, "db", "", "user", "", "query", query)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"strings"
+)
+
+type ResourceType string
+
+const (
+	ResourceTypeTargetGroup    ResourceType = "elasticloadbalancing:targetgroup"
+	ResourceTypeSqsQueue       ResourceType = "sqs"
+	ResourceTypeLambdaFunction ResourceType = "lambda:function"
+)
+
+// Resource is a managed component tagged with the application it belongs to.
+type Resource struct {
+	Type        ResourceType
+	Name        string
+	Application string // the value of the application tag
+
+	// Dimension is the CloudWatch dimension value identifying the resource, e.g., targetgroup/my-tg/73e2d6bc24d8a067 or the queue name.
+	Dimension string
+	// LoadBalancer is the dimension value of the load balancer of a target group, e.g., app/my-alb/50dc6c495c0c9188.
+	LoadBalancer string
+
+	arn string
+}
+
+// Resources returns the target groups, SQS queues and Lambda functions having the tag.
+func (c *Client) Resources(ctx context.Context, tag string) ([]Resource, error) {
+	input := &resourcegroupstaggingapi.GetResourcesInput{
+		TagFilters: []*resourcegroupstaggingapi.TagFilter{{Key: sdk.String(tag)}},
+		ResourceTypeFilters: sdk.StringSlice([]string{
+			string(ResourceTypeTargetGroup), string(ResourceTypeSqsQueue), string(ResourceTypeLambdaFunction),
+		}),
+	}
+	var res []Resource
+	targetGroups := false
+	err := c.tagging.GetResourcesPagesWithContext(ctx, input, func(out *resourcegroupstaggingapi.GetResourcesOutput, last bool) bool {
+		for _, m := range out.ResourceTagMappingList {
+			var app string
+			for _, t := range m.Tags {
+				if sdk.StringValue(t.Key) == tag {
+					app = sdk.StringValue(t.Value)
+				}
+			}
+			if app == "" {
+				continue
+			}
+			if r := parseArn(sdk.StringValue(m.ResourceARN)); r != nil {
+				r.Application = app
+				res = append(res, *r)
+				targetGroups = targetGroups || r.Type == ResourceTypeTargetGroup
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tagged resources: %w", err)
+	}
+	if !targetGroups {
+		return res, nil
+	}
+	loadBalancers := map[string]string{}
+	err = c.elbv2.DescribeTargetGroupsPagesWithContext(ctx, &elbv2.DescribeTargetGroupsInput{}, func(out *elbv2.DescribeTargetGroupsOutput, last bool) bool {
+		for _, tg := range out.TargetGroups {
+			if len(tg.LoadBalancerArns) > 0 { // CloudWatch reports the metrics per target group and load balancer pair, the first one is used
+				if r := parseArn(sdk.StringValue(tg.LoadBalancerArns[0])); r != nil {
+					loadBalancers[sdk.StringValue(tg.TargetGroupArn)] = r.Dimension
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe target groups: %w", err)
+	}
+	for i := range res {
+		if res[i].Type == ResourceTypeTargetGroup {
+			res[i].LoadBalancer = loadBalancers[res[i].arn]
+		}
+	}
+	return res, nil
+}
+
+// parseArn parses the ARNs of the supported resources and load balancers:
+//
+//	arn:aws:elasticloadbalancing:<region>:<account>:targetgroup/<name>/<id>
+//	arn:aws:elasticloadbalancing:<region>:<account>:loadbalancer/<app|net>/<name>/<id>
+//	arn:aws:sqs:<region>:<account>:<name>
+//	arn:aws:lambda:<region>:<account>:function:<name>
+func parseArn(arn string) *Resource {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return nil
+	}
+	service, resource := parts[2], parts[5]
+	r := &Resource{arn: arn}
+	switch {
+	case service == "elasticloadbalancing" && strings.HasPrefix(resource, "targetgroup/"):
+		r.Type = ResourceTypeTargetGroup
+		r.Dimension = resource
+		r.Name = segment(resource, 1)
+	case service == "elasticloadbalancing" && strings.HasPrefix(resource, "loadbalancer/"):
+		r.Dimension = strings.TrimPrefix(resource, "loadbalancer/")
+		r.Name = segment(r.Dimension, 1)
+	case service == "sqs":
+		r.Type = ResourceTypeSqsQueue
+		r.Name = resource
+		r.Dimension = resource
+	case service == "lambda" && strings.HasPrefix(resource, "function:"):
+		r.Type = ResourceTypeLambdaFunction
+		r.Name = strings.SplitN(strings.TrimPrefix(resource, "function:"), ":", 2)[0]
+		r.Dimension = r.Name
+	default:
+		return nil
+	}
+	if r.Name == "" {
+		return nil
+	}
+	return r
+}
+
+func segment(s string, i int) string {
+	parts := strings.Split(s, "/")
+	if i < len(parts) {
+		return parts[i]
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package aws
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseArn(t *testing.T) {
+	r := parseArn("arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/my-tg/73e2d6bc24d8a067")
+	assert.Equal(t, ResourceTypeTargetGroup, r.Type)
+	assert.Equal(t, "my-tg", r.Name)
+	assert.Equal(t, "targetgroup/my-tg/73e2d6bc24d8a067", r.Dimension)
+
+	r = parseArn("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/50dc6c495c0c9188")
+	assert.Equal(t, "app/my-alb/50dc6c495c0c9188", r.Dimension)
+	assert.Equal(t, "my-alb", r.Name)
+
+	r = parseArn("arn:aws:sqs:us-east-1:123456789012:orders")
+	assert.Equal(t, ResourceTypeSqsQueue, r.Type)
+	assert.Equal(t, "orders", r.Dimension)
+
+	r = parseArn("arn:aws:lambda:us-east-1:123456789012:function:resize:prod")
+	assert.Equal(t, ResourceTypeLambdaFunction, r.Type)
+	assert.Equal(t, "resize", r.Dimension)
+
+	assert.Nil(t, parseArn("arn:aws:s3:::bucket"))
+	assert.Nil(t, parseArn("invalid"))
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/coroot/coroot/cache/chunk"
 	"github.com/coroot/coroot/constructor"
@@ -80,13 +81,21 @@ func (c *Client) GetStatus() (*Status, error) {
 }
 
 func (c *Cache) getPromClient(p *db.Project) prom.Client {
-	client, err := prom.NewApiClient(p.Prometheus.Url, p.Prometheus.BasicAuth, p.Prometheus.TlsSkipVerify, p.Prometheus.ExtraSelector, p.Prometheus.CustomHeaders)
+	if p.Prometheus.Embedded {
+		if c.cfg.TSDB == nil {
+			return NewErrorClient(errEmbeddedTSDBDisabled)
+		}
+		return c.cfg.TSDB.Client(p.Id)
+	}
+	client, err := prom.NewApiClient(p.Prometheus.ClientConfig())
 	if err != nil {
 		return NewErrorClient(err)
 	}
 	return client
 }
 
+var errEmbeddedTSDBDisabled = errors.New("the embedded TSDB is disabled, run Coroot with --embedded-tsdb")
+
 type ErrorClient struct {
 	err error
 }
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/tsdb"
 	"time"
 )
 
@@ -9,6 +10,7 @@ type Config struct {
 	Path       string
 	GC         *GcConfig
 	Compaction *CompactionConfig
+	TSDB       *tsdb.Storage // nil if the embedded TSDB is disabled
 }
 
 type GcConfig struct {
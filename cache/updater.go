@@ -35,7 +35,7 @@ func (c *Cache) updater() {
 		}
 		ids := map[db.ProjectId]bool{}
 		for _, project := range projects {
-			if project.Prometheus.Url == "" {
+			if !project.Prometheus.Configured() {
 				continue
 			}
 			ids[project.Id] = true
@@ -90,6 +90,12 @@ func (c *Cache) updaterWorker(projects *sync.Map, projectId db.ProjectId) {
 				queries = append(queries, latencyCfg.Histogram())
 			}
 		}
+		for _, cc := range project.Settings.CustomChecks {
+			queries = append(queries, cc.Query)
+		}
+		for _, d := range project.Settings.Dashboards {
+			queries = append(queries, d.Queries()...)
+		}
 
 		var recordingRules []string
 		for q := range constructor.RecordingRules {
@@ -0,0 +1,170 @@
+package ci
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderGitHub  = "github"
+	ProviderJenkins = "jenkins"
+	ProviderGeneric = "generic"
+)
+
+// Parse parses a webhook payload of the provider. It returns nil if the payload isn't a completed pipeline run.
+func Parse(provider string, data []byte) (*model.CIPipeline, error) {
+	switch provider {
+	case ProviderGitHub:
+		return ParseGitHubWorkflowRun(data)
+	case ProviderJenkins:
+		return ParseJenkinsBuild(data)
+	case ProviderGeneric:
+		return ParseGeneric(data)
+	}
+	return nil, fmt.Errorf("unknown CI provider: %s", provider)
+}
+
+// ParseGeneric parses a completed pipeline run in the model.CIPipeline format, which can be sent from any CI system
+// (e.g., by a step of a GitHub Actions workflow to report test results).
+func ParseGeneric(data []byte) (*model.CIPipeline, error) {
+	var p model.CIPipeline
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if p.Id == "" || p.Sha == "" || p.FinishedAt.IsZero() {
+		return nil, fmt.Errorf("id, sha and finished_at are required")
+	}
+	p.Provider = ProviderGeneric
+	return &p, nil
+}
+
+// ParseGitHubWorkflowRun parses a workflow_run event sent by a GitHub webhook.
+// It returns nil if the run isn't completed yet.
+func ParseGitHubWorkflowRun(data []byte) (*model.CIPipeline, error) {
+	var e struct {
+		Action      string `json:"action"`
+		WorkflowRun struct {
+			Id           int64     `json:"id"`
+			Name         string    `json:"name"`
+			HtmlUrl      string    `json:"html_url"`
+			Conclusion   string    `json:"conclusion"`
+			HeadSha      string    `json:"head_sha"`
+			HeadBranch   string    `json:"head_branch"`
+			RunStartedAt time.Time `json:"run_started_at"`
+			UpdatedAt    time.Time `json:"updated_at"`
+			PullRequests []struct {
+				Number int `json:"number"`
+			} `json:"pull_requests"`
+		} `json:"workflow_run"`
+		Repository struct {
+			HtmlUrl string `json:"html_url"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e.Action != "completed" {
+		return nil, nil
+	}
+	r := e.WorkflowRun
+	if r.Id == 0 {
+		return nil, fmt.Errorf("invalid workflow run")
+	}
+	p := &model.CIPipeline{
+		Provider:   ProviderGitHub,
+		Id:         strconv.FormatInt(r.Id, 10),
+		Name:       r.Name,
+		Url:        r.HtmlUrl,
+		Status:     status(r.Conclusion),
+		Sha:        r.HeadSha,
+		Branch:     r.HeadBranch,
+		StartedAt:  toTime(r.RunStartedAt),
+		FinishedAt: toTime(r.UpdatedAt),
+	}
+	if len(r.PullRequests) > 0 {
+		number := r.PullRequests[0].Number
+		p.PullRequest = &model.CIPullRequest{Number: number}
+		if e.Repository.HtmlUrl != "" {
+			p.PullRequest.Url = fmt.Sprintf("%s/pull/%d", e.Repository.HtmlUrl, number)
+		}
+	}
+	return p, nil
+}
+
+// ParseJenkinsBuild parses a build notification sent by the Jenkins Notification plugin (the JSON format).
+// It returns nil if the build isn't completed yet.
+func ParseJenkinsBuild(data []byte) (*model.CIPipeline, error) {
+	var e struct {
+		Name  string `json:"name"`
+		Build struct {
+			FullUrl   string `json:"full_url"`
+			Number    int    `json:"number"`
+			Phase     string `json:"phase"`
+			Status    string `json:"status"`
+			Timestamp int64  `json:"timestamp"`
+			Duration  int64  `json:"duration"`
+			Scm       struct {
+				Branch string `json:"branch"`
+				Commit string `json:"commit"`
+			} `json:"scm"`
+			TestSummary *struct {
+				Failed  int `json:"failed"`
+				Passed  int `json:"passed"`
+				Skipped int `json:"skipped"`
+			} `json:"test_summary"`
+		} `json:"build"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	b := e.Build
+	if b.Phase != "COMPLETED" && b.Phase != "FINALIZED" {
+		return nil, nil
+	}
+	if e.Name == "" || b.Number == 0 {
+		return nil, fmt.Errorf("invalid build")
+	}
+	p := &model.CIPipeline{
+		Provider: ProviderJenkins,
+		Id:       fmt.Sprintf("%s#%d", e.Name, b.Number),
+		Name:     e.Name,
+		Url:      b.FullUrl,
+		Status:   status(b.Status),
+		Sha:      b.Scm.Commit,
+		Branch:   strings.TrimPrefix(b.Scm.Branch, "origin/"),
+	}
+	if b.Timestamp > 0 {
+		p.StartedAt = timeseries.Time(b.Timestamp / 1000)
+		p.FinishedAt = timeseries.Time((b.Timestamp + b.Duration) / 1000)
+	}
+	if t := b.TestSummary; t != nil {
+		p.Tests = &model.CITestResults{Passed: t.Passed, Failed: t.Failed, Skipped: t.Skipped}
+	}
+	// multibranch pipelines name the branches of pull requests PR-<number>
+	if n, err := strconv.Atoi(strings.TrimPrefix(p.Branch, "PR-")); err == nil && strings.HasPrefix(p.Branch, "PR-") {
+		p.PullRequest = &model.CIPullRequest{Number: n}
+	}
+	return p, nil
+}
+
+func status(s string) string {
+	switch strings.ToLower(s) {
+	case "success":
+		return model.CIPipelineStatusSuccess
+	case "failure", "timed_out":
+		return model.CIPipelineStatusFailure
+	}
+	return strings.ToLower(s)
+}
+
+func toTime(t time.Time) timeseries.Time {
+	if t.IsZero() {
+		return 0
+	}
+	return timeseries.Time(t.Unix())
+}
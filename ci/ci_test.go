@@ -0,0 +1,92 @@
+package ci
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseGitHubWorkflowRun(t *testing.T) {
+	p, err := Parse(ProviderGitHub, []byte(`{
+		"action": "completed",
+		"workflow_run": {
+			"id": 123, "name": "CI", "html_url": "https://github.com/org/app/actions/runs/123", "conclusion": "timed_out",
+			"head_sha": "0a1b2c3d4e5f", "head_branch": "fix",
+			"run_started_at": "2023-11-14T22:13:20Z", "updated_at": "2023-11-14T22:23:20Z",
+			"pull_requests": [{"number": 42}]
+		},
+		"repository": {"html_url": "https://github.com/org/app"}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, &model.CIPipeline{
+		Provider:    ProviderGitHub,
+		Id:          "123",
+		Name:        "CI",
+		Url:         "https://github.com/org/app/actions/runs/123",
+		Status:      model.CIPipelineStatusFailure,
+		Sha:         "0a1b2c3d4e5f",
+		Branch:      "fix",
+		StartedAt:   1700000000,
+		FinishedAt:  1700000600,
+		PullRequest: &model.CIPullRequest{Number: 42, Url: "https://github.com/org/app/pull/42"},
+	}, p)
+
+	p, err = Parse(ProviderGitHub, []byte(`{"action": "in_progress", "workflow_run": {"id": 123}}`))
+	assert.NoError(t, err)
+	assert.Nil(t, p, "runs in progress are skipped")
+
+	_, err = Parse(ProviderGitHub, []byte(`{"action": "completed", "workflow_run": {}}`))
+	assert.Error(t, err)
+}
+
+func TestParseJenkinsBuild(t *testing.T) {
+	p, err := Parse(ProviderJenkins, []byte(`{
+		"name": "app",
+		"build": {
+			"full_url": "https://jenkins/job/app/7/", "number": 7, "phase": "COMPLETED", "status": "SUCCESS",
+			"timestamp": 1700000000000, "duration": 60000,
+			"scm": {"branch": "origin/PR-42", "commit": "0a1b2c3d4e5f"},
+			"test_summary": {"passed": 10, "failed": 1, "skipped": 2}
+		}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, &model.CIPipeline{
+		Provider:    ProviderJenkins,
+		Id:          "app#7",
+		Name:        "app",
+		Url:         "https://jenkins/job/app/7/",
+		Status:      model.CIPipelineStatusSuccess,
+		Sha:         "0a1b2c3d4e5f",
+		Branch:      "PR-42",
+		StartedAt:   1700000000,
+		FinishedAt:  1700000060,
+		Tests:       &model.CITestResults{Passed: 10, Failed: 1, Skipped: 2},
+		PullRequest: &model.CIPullRequest{Number: 42},
+	}, p)
+
+	p, err = Parse(ProviderJenkins, []byte(`{"name": "app", "build": {"number": 7, "phase": "STARTED"}}`))
+	assert.NoError(t, err)
+	assert.Nil(t, p, "builds in progress are skipped")
+
+	_, err = Parse(ProviderJenkins, []byte(`{"build": {"number": 7, "phase": "COMPLETED"}}`))
+	assert.Error(t, err)
+}
+
+func TestParseGeneric(t *testing.T) {
+	p, err := Parse(ProviderGeneric, []byte(`{"provider": "gitlab", "id": "1", "sha": "0a1b2c3", "status": "success", "started_at": 1700000000000, "finished_at": 1700000060000}`))
+	require.NoError(t, err)
+	assert.Equal(t, ProviderGeneric, p.Provider)
+	assert.Equal(t, "1", p.Id)
+	assert.Equal(t, int64(60), int64(p.Duration()))
+
+	_, err = Parse(ProviderGeneric, []byte(`{"id": "1", "sha": "0a1b2c3", "status": "success"}`))
+	assert.EqualError(t, err, "id, sha and finished_at are required", "runs in progress must not be recorded")
+	_, err = Parse(ProviderGeneric, []byte(`{"sha": "0a1b2c3", "finished_at": 1700000060000}`))
+	assert.Error(t, err)
+	_, err = Parse(ProviderGeneric, []byte(`[]`))
+	assert.Error(t, err)
+
+	_, err = Parse("gitlab", []byte(`{}`))
+	assert.EqualError(t, err, "unknown CI provider: gitlab")
+}
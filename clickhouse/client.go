@@ -0,0 +1,144 @@
+// Package clickhouse stores the high-cardinality data (log records, per-query statistics) in ClickHouse,
+// which scales much better for such data than Prometheus labels do.
+package clickhouse
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	chgo "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/coroot/coroot/utils"
+	"time"
+)
+
+type ClientConfig struct {
+	Protocol      string
+	Addr          string
+	TlsEnable     bool
+	TlsSkipVerify bool
+	Auth          utils.BasicAuth
+	Database      string
+}
+
+// Connect opens a connection to the ClickHouse server, it's shared by the tracing and the storage clients.
+func Connect(cfg ClientConfig) (chgo.Conn, error) {
+	opts := &chgo.Options{
+		Addr: []string{cfg.Addr},
+		Auth: chgo.Auth{
+			Database: cfg.Database,
+			Username: cfg.Auth.User,
+			Password: cfg.Auth.Password,
+		},
+		Compression: &chgo.Compression{Method: chgo.CompressionLZ4},
+		DialTimeout: 10 * time.Second,
+	}
+	switch cfg.Protocol {
+	case "native":
+		opts.Protocol = chgo.Native
+	case "http":
+		opts.Protocol = chgo.HTTP
+	default:
+		return nil, fmt.Errorf("unknown protocol: %s", cfg.Protocol)
+	}
+	if cfg.TlsEnable {
+		opts.TLS = &tls.Config{
+			InsecureSkipVerify: cfg.TlsSkipVerify,
+		}
+	}
+	return chgo.Open(opts)
+}
+
+// Retention is how long the data is kept, zero means forever.
+type Retention struct {
+	Logs       time.Duration
+	QueryStats time.Duration
+}
+
+type Client struct {
+	conn chgo.Conn
+}
+
+func NewClient(cfg ClientConfig) (*Client, error) {
+	conn, err := Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	return c.conn.Ping(ctx)
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// CreateTables creates the tables if they don't exist and applies the retention to them.
+// The logs table is compatible with the one of the OpenTelemetry Collector ClickHouse exporter,
+// so the PatternHash column is added to an existing table if needed.
+func (c *Client) CreateTables(ctx context.Context, retention Retention) error {
+	for _, q := range []string{
+		fmt.Sprintf(createLogsTable, LogsTable),
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS PatternHash String CODEC(ZSTD(1))", LogsTable),
+		fmt.Sprintf(createQueryStatsTable, QueryStatsTable),
+	} {
+		if err := c.conn.Exec(ctx, q); err != nil {
+			return err
+		}
+	}
+	for table, ttl := range map[string]time.Duration{LogsTable: retention.Logs, QueryStatsTable: retention.QueryStats} {
+		if ttl <= 0 {
+			continue
+		}
+		q := fmt.Sprintf("ALTER TABLE %s MODIFY TTL toDateTime(Timestamp) + toIntervalSecond(%d)", table, int64(ttl.Seconds()))
+		if err := c.conn.Exec(ctx, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	LogsTable       = "otel_logs"
+	QueryStatsTable = "coroot_query_stats"
+
+	createLogsTable = `
+CREATE TABLE IF NOT EXISTS %s (
+	Timestamp DateTime64(9) CODEC(Delta, ZSTD(1)),
+	TraceId String CODEC(ZSTD(1)),
+	SpanId String CODEC(ZSTD(1)),
+	TraceFlags UInt32 CODEC(ZSTD(1)),
+	SeverityText LowCardinality(String) CODEC(ZSTD(1)),
+	SeverityNumber Int32 CODEC(ZSTD(1)),
+	ServiceName LowCardinality(String) CODEC(ZSTD(1)),
+	Body String CODEC(ZSTD(1)),
+	ResourceAttributes Map(LowCardinality(String), String) CODEC(ZSTD(1)),
+	LogAttributes Map(LowCardinality(String), String) CODEC(ZSTD(1)),
+	PatternHash String CODEC(ZSTD(1)),
+	INDEX idx_trace_id TraceId TYPE bloom_filter(0.001) GRANULARITY 1,
+	INDEX idx_res_attr_key mapKeys(ResourceAttributes) TYPE bloom_filter(0.01) GRANULARITY 1,
+	INDEX idx_res_attr_value mapValues(ResourceAttributes) TYPE bloom_filter(0.01) GRANULARITY 1,
+	INDEX idx_log_attr_key mapKeys(LogAttributes) TYPE bloom_filter(0.01) GRANULARITY 1,
+	INDEX idx_log_attr_value mapValues(LogAttributes) TYPE bloom_filter(0.01) GRANULARITY 1,
+	INDEX idx_pattern_hash PatternHash TYPE bloom_filter(0.01) GRANULARITY 1,
+	INDEX idx_body Body TYPE tokenbf_v1(32768, 3, 0) GRANULARITY 1
+) ENGINE MergeTree()
+PARTITION BY toDate(Timestamp)
+ORDER BY (ServiceName, SeverityText, toUnixTimestamp(Timestamp), TraceId)
+SETTINGS index_granularity=8192, ttl_only_drop_parts = 1`
+
+	createQueryStatsTable = `
+CREATE TABLE IF NOT EXISTS %s (
+	Timestamp DateTime64(3) CODEC(Delta, ZSTD(1)),
+	MetricName LowCardinality(String) CODEC(ZSTD(1)),
+	Db LowCardinality(String) CODEC(ZSTD(1)),
+	User LowCardinality(String) CODEC(ZSTD(1)),
+	Query String CODEC(ZSTD(1)),
+	Labels Map(LowCardinality(String), String) CODEC(ZSTD(1)),
+	Value Float64 CODEC(ZSTD(1))
+) ENGINE MergeTree()
+PARTITION BY toDate(Timestamp)
+ORDER BY (MetricName, Db, Query, toUnixTimestamp(Timestamp))
+SETTINGS index_granularity=8192, ttl_only_drop_parts = 1`
+)
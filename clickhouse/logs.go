@@ -0,0 +1,105 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	chgo "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/coroot/coroot/timeseries"
+	"strings"
+	"time"
+)
+
+type LogRecord struct {
+	Timestamp          time.Time
+	TraceId            string
+	SpanId             string
+	TraceFlags         uint32
+	SeverityText       string
+	SeverityNumber     int32
+	ServiceName        string
+	Body               string
+	ResourceAttributes map[string]string
+	LogAttributes      map[string]string
+	PatternHash        string
+}
+
+// LogsFilter selects the log records of an application. Kubernetes applications are selected by the namespace
+// and the pod names (the k8s.* resource attributes), other applications are selected by the service names.
+type LogsFilter struct {
+	Namespace     string
+	Pods          []string
+	Services      []string
+	PatternHashes []string
+	From          timeseries.Time
+	To            timeseries.Time
+}
+
+func (c *Client) InsertLogs(ctx context.Context, records []*LogRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	batch, err := c.conn.PrepareBatch(ctx, "INSERT INTO "+LogsTable+" ("+logsColumns+")")
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		err = batch.Append(
+			r.Timestamp, r.TraceId, r.SpanId, r.TraceFlags, r.SeverityText, r.SeverityNumber, r.ServiceName, r.Body,
+			r.ResourceAttributes, r.LogAttributes, r.PatternHash,
+		)
+		if err != nil {
+			_ = batch.Abort()
+			return err
+		}
+	}
+	return batch.Send()
+}
+
+// GetLogs returns the latest (at most limit) log records matching the filter.
+func (c *Client) GetLogs(ctx context.Context, filter LogsFilter, limit int) ([]*LogRecord, error) {
+	filters := []string{"Timestamp BETWEEN @from AND @to"}
+	args := []any{
+		chgo.DateNamed("from", filter.From.ToStandard(), chgo.NanoSeconds),
+		chgo.DateNamed("to", filter.To.ToStandard(), chgo.NanoSeconds),
+	}
+	var apps []string
+	if filter.Namespace != "" && len(filter.Pods) > 0 {
+		apps = append(apps, "(ResourceAttributes['k8s.namespace.name'] = @namespace AND ResourceAttributes['k8s.pod.name'] IN (@pods))")
+		args = append(args, chgo.Named("namespace", filter.Namespace), chgo.Named("pods", filter.Pods))
+	}
+	if len(filter.Services) > 0 {
+		apps = append(apps, "ServiceName IN (@services)")
+		args = append(args, chgo.Named("services", filter.Services))
+	}
+	if len(apps) == 0 {
+		return nil, nil
+	}
+	filters = append(filters, "("+strings.Join(apps, " OR ")+")")
+	if len(filter.PatternHashes) > 0 {
+		filters = append(filters, "PatternHash IN (@hashes)")
+		args = append(args, chgo.Named("hashes", filter.PatternHashes))
+	}
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY Timestamp DESC LIMIT %d", logsColumns, LogsTable, strings.Join(filters, " AND "), limit)
+	rows, err := c.conn.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var res []*LogRecord
+	for rows.Next() {
+		var r LogRecord
+		err = rows.Scan(
+			&r.Timestamp, &r.TraceId, &r.SpanId, &r.TraceFlags, &r.SeverityText, &r.SeverityNumber, &r.ServiceName, &r.Body,
+			&r.ResourceAttributes, &r.LogAttributes, &r.PatternHash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &r)
+	}
+	return res, rows.Err()
+}
+
+const logsColumns = "Timestamp, TraceId, SpanId, TraceFlags, SeverityText, SeverityNumber, ServiceName, Body, ResourceAttributes, LogAttributes, PatternHash"
@@ -0,0 +1,129 @@
+package clickhouse
+
+import (
+	"encoding/hex"
+	"github.com/coroot/coroot/otlp"
+	"github.com/coroot/logparser"
+	"google.golang.org/protobuf/encoding/protowire"
+	"time"
+)
+
+// DecodeOtlpLogs decodes a protobuf-encoded OTLP ExportLogsServiceRequest
+// (see opentelemetry-proto/opentelemetry/proto/logs/v1/logs.proto for the field numbers).
+// The records are labeled with the hash of their pattern, the same way the node agent does it,
+// so they can be matched with the log patterns of the applications.
+func DecodeOtlpLogs(data []byte) ([]*LogRecord, error) {
+	var records []*LogRecord
+	err := otlp.ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		if num == 1 && typ == protowire.BytesType {
+			return decodeResourceLogs(b, &records)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func decodeResourceLogs(data []byte, records *[]*LogRecord) error {
+	resource := map[string]string{}
+	var scopeLogs [][]byte
+	err := otlp.ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			return otlp.DecodeAttributes(b, 1, resource)
+		case 2:
+			scopeLogs = append(scopeLogs, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, sl := range scopeLogs {
+		err = otlp.ForEachField(sl, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+			if num != 2 || typ != protowire.BytesType {
+				return nil
+			}
+			r := &LogRecord{
+				ServiceName:        resource["service.name"],
+				ResourceAttributes: resource,
+				LogAttributes:      map[string]string{},
+			}
+			if err := decodeLogRecord(b, r); err != nil {
+				return err
+			}
+			*records = append(*records, r)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeLogRecord(data []byte, r *LogRecord) error {
+	var ts, observedTs uint64
+	err := otlp.ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			ts = v
+		case 2:
+			r.SeverityNumber = int32(v)
+		case 3:
+			r.SeverityText = string(b)
+		case 5:
+			var err error
+			r.Body, err = otlp.DecodeValue(b)
+			return err
+		case 6:
+			return otlp.DecodeKeyValue(b, r.LogAttributes)
+		case 8:
+			r.TraceFlags = uint32(v)
+		case 9:
+			r.TraceId = hex.EncodeToString(b)
+		case 10:
+			r.SpanId = hex.EncodeToString(b)
+		case 11:
+			observedTs = v
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if ts == 0 {
+		ts = observedTs
+	}
+	r.Timestamp = time.Unix(0, int64(ts))
+	if r.SeverityText == "" {
+		r.SeverityText = severityText(r.SeverityNumber)
+	}
+	r.PatternHash = logparser.NewPattern(r.Body).Hash()
+	return nil
+}
+
+// severityText returns the short name of the severity range the number belongs to (see the OpenTelemetry logs data model).
+func severityText(number int32) string {
+	switch {
+	case number <= 0:
+		return ""
+	case number <= 4:
+		return "TRACE"
+	case number <= 8:
+		return "DEBUG"
+	case number <= 12:
+		return "INFO"
+	case number <= 16:
+		return "WARN"
+	case number <= 20:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
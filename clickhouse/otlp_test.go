@@ -0,0 +1,63 @@
+package clickhouse
+
+import (
+	"github.com/coroot/coroot/utils/prototest"
+	"github.com/coroot/logparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"testing"
+	"time"
+)
+
+func stringValue(v string) []byte {
+	return prototest.Message(prototest.Bytes(1, []byte(v)))
+}
+
+func attr(num protowire.Number, key, value string) prototest.Field {
+	return prototest.Bytes(num, prototest.Message(prototest.Bytes(1, []byte(key)), prototest.Bytes(2, stringValue(value))))
+}
+
+func TestDecodeOtlpLogs(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	body := "failed to connect to 10.0.0.1:5432: connection refused"
+
+	resource := prototest.Message(attr(1, "service.name", "api"), attr(1, "k8s.pod.name", "api-7c9d-xk2p"))
+	record1 := prototest.Message(
+		prototest.Fixed64(1, uint64(ts.UnixNano())),
+		prototest.Varint(2, 17),
+		prototest.Bytes(5, stringValue(body)),
+		attr(6, "code.function", "connect"),
+		prototest.Bytes(9, []byte{0x01, 0x02}),
+	)
+	record2 := prototest.Message(
+		prototest.Varint(2, 9),
+		prototest.Bytes(3, []byte("Information")),
+		prototest.Bytes(5, prototest.Message(prototest.Varint(3, 42))), // int_value
+		prototest.Fixed64(11, uint64(ts.UnixNano())),
+	)
+	data := prototest.Message(prototest.Bytes(1, prototest.Message(
+		prototest.Bytes(1, resource),
+		prototest.Bytes(2, prototest.Message(prototest.Bytes(2, record1), prototest.Bytes(2, record2))),
+	)))
+
+	records, err := DecodeOtlpLogs(data)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	r := records[0]
+	assert.True(t, ts.Equal(r.Timestamp))
+	assert.Equal(t, "api", r.ServiceName)
+	assert.Equal(t, "api-7c9d-xk2p", r.ResourceAttributes["k8s.pod.name"])
+	assert.Equal(t, map[string]string{"code.function": "connect"}, r.LogAttributes)
+	assert.Equal(t, "ERROR", r.SeverityText)
+	assert.Equal(t, int32(17), r.SeverityNumber)
+	assert.Equal(t, body, r.Body)
+	assert.Equal(t, "0102", r.TraceId)
+	assert.Equal(t, logparser.NewPattern(body).Hash(), r.PatternHash)
+
+	r = records[1]
+	assert.True(t, ts.Equal(r.Timestamp)) // the observed timestamp is used if the timestamp isn't set
+	assert.Equal(t, "Information", r.SeverityText)
+	assert.Equal(t, "42", r.Body)
+}
@@ -0,0 +1,140 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	chgo "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/prometheus/prometheus/prompb"
+	"time"
+)
+
+// QuerySample is a value of a per-query metric (pg_top_query_*) reported by pg-agent.
+// Every query fingerprint produces its own Prometheus series, so these metrics are stored in ClickHouse
+// where the number of distinct queries doesn't matter.
+type QuerySample struct {
+	Timestamp  time.Time
+	MetricName string
+	Db         string
+	User       string
+	Query      string
+	Labels     map[string]string
+	Value      float64
+}
+
+var queryStatsMetrics = map[string]bool{
+	"pg_top_query_calls_per_second":   true,
+	"pg_top_query_time_per_second":    true,
+	"pg_top_query_io_time_per_second": true,
+}
+
+// ExtractQueryStats removes the series of the per-query metrics from a remote-write request and returns their samples,
+// so they don't reach the embedded TSDB.
+func ExtractQueryStats(req *prompb.WriteRequest) []QuerySample {
+	var res []QuerySample
+	rest := req.Timeseries[:0]
+	for _, ts := range req.Timeseries {
+		var name, dbName, user, query string
+		labels := map[string]string{}
+		for _, l := range ts.Labels {
+			switch l.Name {
+			case "__name__":
+				name = l.Value
+			case "db":
+				dbName = l.Value
+			case "user":
+				user = l.Value
+			case "query":
+				query = l.Value
+			default:
+				labels[l.Name] = l.Value
+			}
+		}
+		if !queryStatsMetrics[name] {
+			rest = append(rest, ts)
+			continue
+		}
+		for _, s := range ts.Samples {
+			res = append(res, QuerySample{
+				Timestamp:  time.UnixMilli(s.Timestamp),
+				MetricName: name,
+				Db:         dbName,
+				User:       user,
+				Query:      query,
+				Labels:     labels,
+				Value:      s.Value,
+			})
+		}
+	}
+	req.Timeseries = rest
+	return res
+}
+
+func (c *Client) InsertQueryStats(ctx context.Context, samples []QuerySample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	batch, err := c.conn.PrepareBatch(ctx, "INSERT INTO "+QueryStatsTable+" (Timestamp, MetricName, Db, User, Query, Labels, Value)")
+	if err != nil {
+		return err
+	}
+	for _, s := range samples {
+		if err = batch.Append(s.Timestamp, s.MetricName, s.Db, s.User, s.Query, s.Labels, s.Value); err != nil {
+			_ = batch.Abort()
+			return err
+		}
+	}
+	return batch.Send()
+}
+
+// GetQueryStats returns the per-query metrics aggregated with the step as the series named after the metrics,
+// labeled the same way the metrics pushed by the agents are.
+func (c *Client) GetQueryStats(ctx context.Context, from, to timeseries.Time, step timeseries.Duration) (map[string][]model.MetricValues, error) {
+	q := fmt.Sprintf(`
+		SELECT MetricName, Db, User, Query, Labels, toUnixTimestamp(toStartOfInterval(Timestamp, INTERVAL %d SECOND)) AS t, avg(Value)
+		FROM %s
+		WHERE Timestamp BETWEEN @from AND @to
+		GROUP BY MetricName, Db, User, Query, Labels, t`, step, QueryStatsTable)
+	rows, err := c.conn.Query(ctx, q,
+		chgo.DateNamed("from", from.ToStandard(), chgo.Seconds),
+		chgo.DateNamed("to", to.ToStandard(), chgo.Seconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	series := map[string]map[string]*model.MetricValues{}
+	for rows.Next() {
+		var name, dbName, user, query string
+		var labels map[string]string
+		var t uint32
+		var v float64
+		if err = rows.Scan(&name, &dbName, &user, &query, &labels, &t, &v); err != nil {
+			return nil, err
+		}
+		ls := model.Labels{"db": dbName, "user": user, "query": query}
+		for k, v := range labels {
+			ls[k] = v
+		}
+		key := ls.String()
+		if series[name] == nil {
+			series[name] = map[string]*model.MetricValues{}
+		}
+		mv := series[name][key]
+		if mv == nil {
+			mv = &model.MetricValues{Labels: ls, Values: timeseries.New(from, int(to.Sub(from)/step)+1, step)}
+			series[name][key] = mv
+		}
+		mv.Values.Set(timeseries.Time(t), float32(v))
+	}
+	res := make(map[string][]model.MetricValues, len(series))
+	for name, byKey := range series {
+		for _, mv := range byKey {
+			res[name] = append(res[name], *mv)
+		}
+	}
+	return res, rows.Err()
+}
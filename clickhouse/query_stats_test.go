@@ -0,0 +1,36 @@
+package clickhouse
+
+import (
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExtractQueryStats(t *testing.T) {
+	req := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "pg_up"}, {Name: "instance", Value: "pg:5432"}},
+			Samples: []prompb.Sample{{Timestamp: 1000, Value: 1}},
+		},
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "pg_top_query_calls_per_second"},
+				{Name: "db", Value: "shop"}, {Name: "user", Value: "app"}, {Name: "query", Value: "select 1"},
+				{Name: "instance", Value: "pg:5432"},
+			},
+			Samples: []prompb.Sample{{Timestamp: 1000, Value: 2}, {Timestamp: 2000, Value: 3}},
+		},
+	}}
+	samples := ExtractQueryStats(req)
+	require.Len(t, req.Timeseries, 1)
+	assert.Equal(t, "pg_up", req.Timeseries[0].Labels[0].Value)
+	require.Len(t, samples, 2)
+	assert.Equal(t, "pg_top_query_calls_per_second", samples[0].MetricName)
+	assert.Equal(t, "shop", samples[0].Db)
+	assert.Equal(t, "app", samples[0].User)
+	assert.Equal(t, "select 1", samples[0].Query)
+	assert.Equal(t, map[string]string{"instance": "pg:5432"}, samples[0].Labels)
+	assert.Equal(t, int64(2000), samples[1].Timestamp.UnixMilli())
+	assert.Equal(t, 3.0, samples[1].Value)
+}
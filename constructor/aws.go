@@ -0,0 +1,111 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"strings"
+)
+
+// loadAwsResources attaches the CloudWatch metrics of target groups, SQS queues and Lambda functions to the applications
+// referenced by the application tag of the resources: an application id (<namespace>:<kind>:<name>) or <namespace>/<name>.
+// If there's no such application, a standalone one of the AWS kind is created.
+func loadAwsResources(w *model.World, metrics map[string][]model.MetricValues) {
+	apps := map[string]*model.Application{}
+	for queryName := range QUERIES {
+		if !strings.HasPrefix(queryName, "aws_target_group_") && !strings.HasPrefix(queryName, "aws_sqs_") && !strings.HasPrefix(queryName, "aws_lambda_") {
+			continue
+		}
+		for _, m := range metrics[queryName] {
+			tag := m.Labels["application"]
+			if tag == "" {
+				continue
+			}
+			app := apps[tag]
+			if app == nil {
+				app = awsApplication(w, tag)
+				if app == nil {
+					continue
+				}
+				apps[tag] = app
+			}
+			if app.Aws == nil {
+				app.Aws = model.NewAwsResources()
+			}
+			switch {
+			case strings.HasPrefix(queryName, "aws_target_group_"):
+				name := m.Labels["target_group"]
+				tg := app.Aws.TargetGroups[name]
+				if tg == nil {
+					tg = &model.AwsTargetGroup{LoadBalancer: m.Labels["load_balancer"]}
+					app.Aws.TargetGroups[name] = tg
+				}
+				switch queryName {
+				case "aws_target_group_requests":
+					tg.Requests = merge(tg.Requests, m.Values, timeseries.Any)
+				case "aws_target_group_errors":
+					tg.Errors = merge(tg.Errors, m.Values, timeseries.Any)
+				case "aws_target_group_response_time":
+					tg.ResponseTime = merge(tg.ResponseTime, m.Values, timeseries.Any)
+				case "aws_target_group_healthy_targets":
+					tg.HealthyTargets = merge(tg.HealthyTargets, m.Values, timeseries.Any)
+				case "aws_target_group_unhealthy_targets":
+					tg.UnhealthyTargets = merge(tg.UnhealthyTargets, m.Values, timeseries.Any)
+				}
+			case strings.HasPrefix(queryName, "aws_sqs_"):
+				name := m.Labels["queue"]
+				q := app.Aws.SqsQueues[name]
+				if q == nil {
+					q = &model.AwsSqsQueue{}
+					app.Aws.SqsQueues[name] = q
+				}
+				switch queryName {
+				case "aws_sqs_messages_visible":
+					q.MessagesVisible = merge(q.MessagesVisible, m.Values, timeseries.Any)
+				case "aws_sqs_oldest_message_age":
+					q.OldestMessageAge = merge(q.OldestMessageAge, m.Values, timeseries.Any)
+				case "aws_sqs_messages_sent":
+					q.MessagesSent = merge(q.MessagesSent, m.Values, timeseries.Any)
+				case "aws_sqs_messages_deleted":
+					q.MessagesDeleted = merge(q.MessagesDeleted, m.Values, timeseries.Any)
+				}
+			case strings.HasPrefix(queryName, "aws_lambda_"):
+				name := m.Labels["function"]
+				f := app.Aws.LambdaFunctions[name]
+				if f == nil {
+					f = &model.AwsLambdaFunction{}
+					app.Aws.LambdaFunctions[name] = f
+				}
+				switch queryName {
+				case "aws_lambda_invocations":
+					f.Invocations = merge(f.Invocations, m.Values, timeseries.Any)
+				case "aws_lambda_errors":
+					f.Errors = merge(f.Errors, m.Values, timeseries.Any)
+				case "aws_lambda_throttles":
+					f.Throttles = merge(f.Throttles, m.Values, timeseries.Any)
+				case "aws_lambda_duration":
+					f.Duration = merge(f.Duration, m.Values, timeseries.Any)
+				}
+			}
+		}
+	}
+}
+
+func awsApplication(w *model.World, tag string) *model.Application {
+	if strings.Contains(tag, ":") {
+		id, err := model.NewApplicationIdFromString(tag)
+		if err != nil {
+			return nil
+		}
+		return w.GetOrCreateApplication(id)
+	}
+	ns, name := "", tag
+	if parts := strings.SplitN(tag, "/", 2); len(parts) == 2 {
+		ns, name = parts[0], parts[1]
+	}
+	for _, app := range w.Applications {
+		if app.Id.Name == name && (ns == "" || app.Id.Namespace == ns) {
+			return app
+		}
+	}
+	return w.GetOrCreateApplication(model.NewApplicationId(ns, model.ApplicationKindAws, name))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/coroot/coroot/clickhouse"
 	cloud_pricing "github.com/coroot/coroot/cloud-pricing"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
@@ -18,6 +19,8 @@ import (
 
 var (
 	ErrUnknownQuery = errors.New("unknown query")
+
+	errMemoryLimitExceeded = errors.New("world memory limit exceeded")
 )
 
 type Option int
@@ -33,6 +36,9 @@ type Constructor struct {
 	prom    prom.Client
 	pricing *cloud_pricing.Manager
 	options map[Option]bool
+
+	queryStats  *clickhouse.Client
+	memoryLimit int64
 }
 
 func New(db *db.DB, project *db.Project, prom prom.Client, pricing *cloud_pricing.Manager, options ...Option) *Constructor {
@@ -43,6 +49,20 @@ func New(db *db.DB, project *db.Project, prom prom.Client, pricing *cloud_pricin
 	return c
 }
 
+// WithQueryStats makes the constructor load the per-query Postgres metrics from ClickHouse,
+// where they are stored instead of the metric storage (see clickhouse.ExtractQueryStats).
+func (c *Constructor) WithQueryStats(client *clickhouse.Client) *Constructor {
+	c.queryStats = client
+	return c
+}
+
+// WithMemoryLimit caps the memory occupied by the time series of the world (0 means no limit).
+// If the limit is exceeded, the world is reloaded with a coarser step.
+func (c *Constructor) WithMemoryLimit(limit int64) *Constructor {
+	c.memoryLimit = limit
+	return c
+}
+
 type QueryStats struct {
 	MetricsCount int     `json:"metrics_count"`
 	QueryTime    float32 `json:"query_time"`
@@ -83,32 +103,71 @@ func (c *Constructor) LoadWorld(ctx context.Context, from, to timeseries.Time, s
 	}
 
 	var metrics map[string][]model.MetricValues
-	prof.stage("query", func() {
-		metrics, err = c.queryCache(ctx, from, to, step, w.CheckConfigs, prof.Queries)
-	})
-	if err != nil {
-		if !errors.Is(err, ErrUnknownQuery) {
-			return nil, err
+	requestedStep := step
+	for {
+		limit := c.memoryLimit
+		if 2*step > to.Sub(from) { // the step can't be increased anymore
+			limit = 0
 		}
-		klog.Warningln(err)
+		prof.stage("query", func() {
+			metrics, err = c.queryCache(ctx, from, to, step, w.CheckConfigs, prof.Queries, limit)
+		})
+		if errors.Is(err, errMemoryLimitExceeded) {
+			klog.Warningf(
+				"%s: world memory usage exceeds the limit of %d bytes, reloading with step %s",
+				c.project.Id, c.memoryLimit, (2 * step).ToStandard(),
+			)
+			metrics = nil
+			step = 2 * step
+			from, to = from.Truncate(step), to.Truncate(step)
+			w.Ctx = timeseries.Context{From: from, To: to, Step: step}
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, ErrUnknownQuery) {
+				return nil, err
+			}
+			klog.Warningln(err)
+		}
+		break
+	}
+	w.MemoryUsage = model.CalcMemoryUsage(metrics)
+	w.MemoryUsage.Limit = c.memoryLimit
+	if step != requestedStep {
+		w.MemoryUsage.RequestedStep = requestedStep
+	}
+	if c.queryStats != nil && metrics != nil {
+		prof.stage("query_stats", func() { c.loadQueryStats(ctx, w, metrics) })
 	}
 
 	pjs := promJobStatuses{}
 	nodesByMachineId := map[string]*model.Node{}
 	rdsInstancesById := map[string]*model.Instance{}
+	gcpInstancesById := map[string]*model.Instance{}
 
 	// order is important
 	prof.stage("load_job_statuses", func() { loadPromJobStatuses(metrics, pjs) })
 	prof.stage("load_nodes", func() { c.loadNodes(w, metrics, nodesByMachineId) })
 	prof.stage("load_k8s_metadata", func() { loadKubernetesMetadata(w, metrics) })
 	prof.stage("load_rds", func() { loadRds(w, metrics, pjs, rdsInstancesById) })
+	prof.stage("load_gcp", func() { loadGcp(w, metrics, gcpInstancesById) })
 	prof.stage("load_containers", func() { loadContainers(w, metrics, pjs, nodesByMachineId) })
-	prof.stage("enrich_instances", func() { enrichInstances(w, metrics, rdsInstancesById) })
+	prof.stage("enrich_instances", func() { enrichInstances(w, metrics, rdsInstancesById, gcpInstancesById) })
 	prof.stage("join_db_cluster", func() { joinDBClusterComponents(w) })
+	prof.stage("load_tls_certificates", func() { loadTlsCertificates(w, metrics) })
+	prof.stage("load_aws_resources", func() { loadAwsResources(w, metrics) })
 	prof.stage("calc_app_categories", func() { c.calcApplicationCategories(w) })
+	prof.stage("apply_check_templates", func() { c.applyCheckTemplates(w) })
+	prof.stage("apply_adaptive_thresholds", func() { c.applyAdaptiveThresholds(w) })
+	prof.stage("apply_threshold_schedules", func() { w.CheckConfigs.ApplySchedules(w.Ctx.To.ToStandard().In(c.project.Location())) })
 	prof.stage("load_sli", func() { c.loadSLIs(w, metrics) })
+	prof.stage("load_custom_checks", func() { c.loadCustomChecks(w, metrics) })
 	prof.stage("load_app_deployments", func() { c.loadApplicationDeployments(w) })
 	prof.stage("load_app_incidents", func() { c.loadApplicationIncidents(w) })
+	prof.stage("load_check_trends", func() { c.loadCheckTrends(w) })
+	prof.stage("load_error_budgets", func() { c.loadErrorBudgets(w) })
+	prof.stage("load_app_owners", func() { c.loadApplicationOwners(w) })
+	prof.stage("load_rtt_baselines", func() { c.loadNetworkRttBaselines(w) })
 	prof.stage("calc_app_events", func() { calcAppEvents(w) })
 
 	klog.Infof("got %d nodes, %d services, %d applications", len(w.Nodes), len(w.Services), len(w.Applications))
@@ -122,7 +181,9 @@ type cacheQuery struct {
 	statsName string
 }
 
-func (c *Constructor) queryCache(ctx context.Context, from, to timeseries.Time, step timeseries.Duration, checkConfigs model.CheckConfigs, stats map[string]QueryStats) (map[string][]model.MetricValues, error) {
+// queryCache loads the metrics of the world. If the series loaded occupy more than memoryLimit bytes (0 means no limit),
+// the remaining queries are canceled and errMemoryLimitExceeded is returned, so the memory isn't allocated in the first place.
+func (c *Constructor) queryCache(ctx context.Context, from, to timeseries.Time, step timeseries.Duration, checkConfigs model.CheckConfigs, stats map[string]QueryStats, memoryLimit int64) (map[string][]model.MetricValues, error) {
 	queries := map[string]cacheQuery{}
 	rawFrom := to.Add(-model.MaxAlertRuleWindow)
 	rawStep := c.project.Prometheus.RefreshInterval
@@ -158,9 +219,17 @@ func (c *Constructor) queryCache(ctx context.Context, from, to timeseries.Time,
 		}
 	}
 
+	for _, cc := range c.project.Settings.CustomChecks {
+		addQuery(qCustomCheck+"/"+cc.Id, qCustomCheck, cc.Query, false)
+	}
+
 	res := make(map[string][]model.MetricValues, len(queries))
 	var lock sync.Mutex
 	var lastErr error
+	var memoryUsage int64
+	exceeded := false
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	wg := sync.WaitGroup{}
 	now := time.Now()
 	for name, query := range queries {
@@ -183,11 +252,21 @@ func (c *Constructor) queryCache(ctx context.Context, from, to timeseries.Time,
 				return
 			}
 			lock.Lock()
+			defer lock.Unlock()
 			res[name] = metrics
-			lock.Unlock()
+			if memoryLimit > 0 {
+				memoryUsage += model.CalcMemoryUsage(map[string][]model.MetricValues{name: metrics}).Bytes
+				if memoryUsage > memoryLimit && !exceeded {
+					exceeded = true
+					cancel()
+				}
+			}
 		}(name, query)
 	}
 	wg.Wait()
+	if exceeded {
+		return nil, errMemoryLimitExceeded
+	}
 	return res, lastErr
 }
 
@@ -197,6 +276,27 @@ func (c *Constructor) calcApplicationCategories(w *model.World) {
 	}
 }
 
+func (c *Constructor) applyCheckTemplates(w *model.World) {
+	if len(c.project.Settings.CheckTemplates) == 0 {
+		return
+	}
+	if w.CheckConfigs == nil {
+		w.CheckConfigs = model.CheckConfigs{}
+	}
+	for _, app := range w.Applications {
+		w.CheckConfigs.ApplyTemplates(app.Id, app.Category, c.project.Settings.CheckTemplates)
+	}
+}
+
+func (c *Constructor) applyAdaptiveThresholds(w *model.World) {
+	thresholds, err := c.db.GetCheckThresholds(c.project.Id)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	w.CheckConfigs.ApplyAdaptiveThresholds(thresholds)
+}
+
 func (c *Constructor) loadApplicationDeployments(w *model.World) {
 	byApp, err := c.db.GetApplicationDeployments(c.project.Id)
 	if err != nil {
@@ -227,6 +327,84 @@ func (c *Constructor) loadApplicationIncidents(w *model.World) {
 	}
 }
 
+func (c *Constructor) loadCheckTrends(w *model.World) {
+	byApp, err := c.db.GetCheckTrends(c.project.Id, w.Ctx.To)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	titles := map[model.CheckId]string{}
+	for _, cc := range c.project.Settings.CustomChecks {
+		titles[cc.CheckId()] = cc.Title
+	}
+	for _, lc := range c.project.Settings.LogChecks {
+		titles[lc.CheckId()] = lc.Title
+	}
+	for id, trends := range byApp {
+		app := w.GetApplication(id)
+		if app == nil {
+			continue
+		}
+		for _, t := range trends {
+			if cfg := model.GetCheckConfig(t.CheckId); cfg != nil {
+				t.Title = cfg.Title
+			} else if t.Title = titles[t.CheckId]; t.Title == "" {
+				continue
+			}
+			app.CheckTrends = append(app.CheckTrends, t)
+		}
+	}
+}
+
+// loadApplicationOwners sets the application ownership: the one configured via the API takes precedence over the pod annotations.
+func (c *Constructor) loadApplicationOwners(w *model.World) {
+	for _, app := range w.Applications {
+		for _, i := range app.Instances {
+			if i.Pod != nil && i.Pod.Owner != nil {
+				app.Owner = i.Pod.Owner
+				break
+			}
+		}
+	}
+	owners, err := c.db.GetApplicationOwners(c.project.Id)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	for id, owner := range owners {
+		if app := w.GetApplication(id); app != nil {
+			app.Owner = owner
+		}
+	}
+}
+
+func (c *Constructor) loadNetworkRttBaselines(w *model.World) {
+	byApp, err := c.db.GetNetworkRttBaselines(c.project.Id, w.Ctx.To)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	for id, baselines := range byApp {
+		if app := w.GetApplication(id); app != nil {
+			app.UpstreamRttBaselines = baselines
+		}
+	}
+}
+
+func (c *Constructor) loadErrorBudgets(w *model.World) {
+	from, _ := model.ErrorBudgetPeriod(w.Ctx.To)
+	byApp, err := c.db.GetErrorBudgetRecords(c.project.Id, from)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	for id, records := range byApp {
+		if app := w.GetApplication(id); app != nil {
+			app.ErrorBudgetLedger = records
+		}
+	}
+}
+
 type promJob struct {
 	job      string
 	instance string
@@ -248,7 +426,7 @@ type podId struct {
 	name, ns string
 }
 
-func enrichInstances(w *model.World, metrics map[string][]model.MetricValues, rdsInstancesById map[string]*model.Instance) {
+func enrichInstances(w *model.World, metrics map[string][]model.MetricValues, rdsInstancesById, gcpInstancesById map[string]*model.Instance) {
 	instancesByListen := map[model.Listen]*model.Instance{}
 	instancesByPod := map[podId]*model.Instance{}
 	for _, app := range w.Applications {
@@ -266,10 +444,10 @@ func enrichInstances(w *model.World, metrics map[string][]model.MetricValues, rd
 		for _, m := range metrics[queryName] {
 			switch {
 			case strings.HasPrefix(queryName, "pg_"):
-				instance := findInstance(instancesByPod, instancesByListen, rdsInstancesById, m.Labels, model.ApplicationTypePostgres)
+				instance := findInstance(instancesByPod, instancesByListen, rdsInstancesById, gcpInstancesById, m.Labels, model.ApplicationTypePostgres)
 				postgres(instance, queryName, m)
 			case strings.HasPrefix(queryName, "redis_"):
-				instance := findInstance(instancesByPod, instancesByListen, rdsInstancesById, m.Labels, model.ApplicationTypeRedis, model.ApplicationTypeKeyDB)
+				instance := findInstance(instancesByPod, instancesByListen, rdsInstancesById, gcpInstancesById, m.Labels, model.ApplicationTypeRedis, model.ApplicationTypeKeyDB)
 				redis(instance, queryName, m)
 			}
 		}
@@ -330,10 +508,15 @@ func guessNamespace(ls model.Labels) string {
 	return ""
 }
 
-func findInstance(instancesByPod map[podId]*model.Instance, instancesByListen map[model.Listen]*model.Instance, rdsInstancesById map[string]*model.Instance, ls model.Labels, applicationTypes ...model.ApplicationType) *model.Instance {
+func findInstance(instancesByPod map[podId]*model.Instance, instancesByListen map[model.Listen]*model.Instance, rdsInstancesById, gcpInstancesById map[string]*model.Instance, ls model.Labels, applicationTypes ...model.ApplicationType) *model.Instance {
 	if rdsId := ls["rds_instance_id"]; rdsId != "" {
 		return rdsInstancesById[rdsId]
 	}
+	for _, l := range []string{"cloudsql_instance_id", "memorystore_instance_id"} {
+		if gcpId := ls[l]; gcpId != "" {
+			return gcpInstancesById[gcpId]
+		}
+	}
 	if host, port, err := net.SplitHostPort(ls["instance"]); err == nil {
 		if ip := net.ParseIP(host); ip != nil && !ip.IsLoopback() {
 			var instance *model.Instance
@@ -0,0 +1,48 @@
+package constructor
+
+import (
+	"context"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// fakeProm returns a single series for every query.
+type fakeProm struct{}
+
+func (p *fakeProm) QueryRange(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration) ([]model.MetricValues, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ts := timeseries.New(from, int(to.Sub(from)/step), step)
+	ts.Set(from, 1)
+	return []model.MetricValues{{Labels: model.Labels{}, Values: ts}}, nil
+}
+
+func (p *fakeProm) Ping(ctx context.Context) error {
+	return nil
+}
+
+func TestQueryCacheMemoryLimit(t *testing.T) {
+	project := &db.Project{Id: "p"}
+	project.Prometheus.RefreshInterval = 30
+	to := timeseries.Time(10000 * 30)
+	from := to.Add(-timeseries.Hour)
+
+	p := &fakeProm{}
+	metrics, err := New(nil, project, p, nil).queryCache(context.Background(), from, to, 30, nil, nil, 0)
+	require.NoError(t, err)
+	usage := model.CalcMemoryUsage(metrics)
+	assert.Greater(t, usage.Bytes, int64(0))
+
+	metrics, err = New(nil, project, p, nil).queryCache(context.Background(), from, to, 30, nil, nil, usage.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, usage, model.CalcMemoryUsage(metrics))
+
+	metrics, err = New(nil, project, p, nil).queryCache(context.Background(), from, to, 30, nil, nil, usage.Bytes/2)
+	assert.ErrorIs(t, err, errMemoryLimitExceeded)
+	assert.Nil(t, metrics)
+}
@@ -137,6 +137,15 @@ func loadContainers(w *model.World, metrics map[string][]model.MetricValues, pjs
 					}
 					c.RequestsHistogram[protocol][float32(le)] = merge(c.RequestsHistogram[protocol][float32(le)], m.Values, timeseries.NanSum)
 				}
+			case "container_dns_requests_total":
+				r := model.DNSRequest{Type: m.Labels["request_type"], Domain: m.Labels["domain"]}
+				if container.DNSRequests[r] == nil {
+					container.DNSRequests[r] = map[string]*timeseries.TimeSeries{}
+				}
+				status := m.Labels["status"]
+				container.DNSRequests[r][status] = merge(container.DNSRequests[r][status], m.Values, timeseries.NanSum)
+			case "container_dns_requests_latency":
+				container.DNSRequestsLatency = merge(container.DNSRequestsLatency, m.Values, timeseries.Any)
 			case "container_cpu_limit":
 				container.CpuLimit = merge(container.CpuLimit, m.Values, timeseries.Any)
 			case "container_cpu_usage":
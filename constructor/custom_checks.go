@@ -0,0 +1,14 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+)
+
+func (c *Constructor) loadCustomChecks(w *model.World, metrics map[string][]model.MetricValues) {
+	for _, cc := range c.project.Settings.CustomChecks {
+		w.CustomChecks = append(w.CustomChecks, &model.CustomCheckValues{
+			Check:  cc,
+			Values: metrics[qCustomCheck+"/"+cc.Id],
+		})
+	}
+}
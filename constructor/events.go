@@ -15,10 +15,17 @@ func calcAppEvents(w *model.World) {
 			if d.StartedAt.Before(w.Ctx.From) || d.StartedAt.After(w.Ctx.To) {
 				continue
 			}
+			typ := model.ApplicationEventTypeRollout
+			switch {
+			case d.IsChange():
+				typ = model.ApplicationEventTypeChange
+			case d.IsTrafficShift():
+				typ = model.ApplicationEventTypeTrafficShift
+			}
 			events = append(events, &model.ApplicationEvent{
 				Start:   d.StartedAt,
 				End:     d.StartedAt,
-				Type:    model.ApplicationEventTypeRollout,
+				Type:    typ,
 				Details: d.Version(),
 			})
 		}
@@ -0,0 +1,139 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"strconv"
+	"strings"
+)
+
+func loadGcp(w *model.World, metrics map[string][]model.MetricValues, gcpInstancesById map[string]*model.Instance) {
+	for queryName := range QUERIES {
+		var kind model.ApplicationKind
+		var idLabel string
+		switch {
+		case strings.HasPrefix(queryName, "gcp_cloudsql_"):
+			kind, idLabel = model.ApplicationKindCloudSql, "cloudsql_instance_id"
+		case strings.HasPrefix(queryName, "gcp_memorystore_"):
+			kind, idLabel = model.ApplicationKindMemorystore, "memorystore_instance_id"
+		default:
+			continue
+		}
+		for _, m := range metrics[queryName] {
+			gcpId := m.Labels[idLabel]
+			if gcpId == "" {
+				continue
+			}
+			instance := gcpInstancesById[gcpId]
+			if instance == nil {
+				parts := strings.Split(gcpId, "/")
+				name := parts[len(parts)-1]
+				if len(parts) < 2 || name == "" {
+					continue
+				}
+				id := model.NewApplicationId("", kind, name)
+				instance = w.GetOrCreateApplication(id).GetOrCreateInstance(name, nil)
+				gcpInstancesById[gcpId] = instance
+			}
+			if instance.Gcp == nil {
+				instance.Gcp = &model.Gcp{}
+			}
+			if instance.Node == nil {
+				nodeName := strings.ToLower(string(kind)) + ":" + instance.Name
+				instance.Node = model.NewNode(nodeName)
+				instance.Node.Name.Update(m.Values, nodeName)
+				instance.Node.CloudProvider.Update(m.Values, "gcp")
+				instance.Node.Instances = append(instance.Node.Instances, instance)
+				w.Nodes = append(w.Nodes, instance.Node)
+			}
+			switch queryName {
+			case "gcp_cloudsql_info", "gcp_memorystore_info":
+				if ip := m.Labels["ipv4"]; ip != "" {
+					instance.TcpListens[model.Listen{IP: ip, Port: m.Labels["port"]}] = true
+				}
+				instance.Node.Region.Update(m.Values, m.Labels["region"])
+				instance.Node.AvailabilityZone.Update(m.Values, m.Labels["zone"])
+				instance.Node.InstanceType.Update(m.Values, m.Labels["tier"])
+				if queryName == "gcp_memorystore_info" {
+					instance.Gcp.Engine.Update(m.Values, "redis")
+					instance.Gcp.EngineVersion.Update(m.Values, m.Labels["redis_version"])
+				} else {
+					instance.Gcp.Engine.Update(m.Values, m.Labels["engine"])
+					instance.Gcp.EngineVersion.Update(m.Values, m.Labels["engine_version"])
+					instance.Gcp.HighlyAvailable, _ = strconv.ParseBool(m.Labels["highly_available"])
+				}
+			case "gcp_cloudsql_status", "gcp_memorystore_status":
+				instance.Gcp.LifeSpan = merge(instance.Gcp.LifeSpan, m.Values, timeseries.Any)
+				instance.Gcp.Status.Update(m.Values, m.Labels["status"])
+			case "gcp_cloudsql_cpu_cores":
+				instance.Node.CpuCapacity = merge(instance.Node.CpuCapacity, m.Values, timeseries.Any)
+			case "gcp_cloudsql_cpu_usage_percent":
+				instance.Node.CpuUsagePercent = merge(instance.Node.CpuUsagePercent, m.Values, timeseries.NanSum)
+				mode := m.Labels["mode"]
+				instance.Node.CpuUsageByMode[mode] = merge(instance.Node.CpuUsageByMode[mode], m.Values, timeseries.Any)
+			case "gcp_cloudsql_memory_total_bytes", "gcp_memorystore_memory_total_bytes":
+				instance.Node.MemoryTotalBytes = merge(instance.Node.MemoryTotalBytes, m.Values, timeseries.Any)
+			case "gcp_cloudsql_memory_cached_bytes":
+				instance.Node.MemoryCachedBytes = merge(instance.Node.MemoryCachedBytes, m.Values, timeseries.Any)
+				instance.Node.MemoryAvailableBytes = merge(instance.Node.MemoryAvailableBytes, m.Values, timeseries.NanSum)
+			case "gcp_cloudsql_memory_free_bytes", "gcp_memorystore_memory_free_bytes":
+				instance.Node.MemoryFreeBytes = merge(instance.Node.MemoryFreeBytes, m.Values, timeseries.Any)
+				instance.Node.MemoryAvailableBytes = merge(instance.Node.MemoryAvailableBytes, m.Values, timeseries.NanSum)
+			case "gcp_cloudsql_disk_total_bytes", "gcp_cloudsql_disk_used_bytes", "gcp_cloudsql_disk_ops_per_second":
+				if len(instance.Volumes) == 0 { // the Cloud SQL instance has only one data disk
+					instance.Volumes = append(instance.Volumes, &model.Volume{MountPoint: "/data"})
+				}
+				volume := instance.Volumes[0]
+				switch queryName {
+				case "gcp_cloudsql_disk_total_bytes":
+					volume.CapacityBytes = merge(volume.CapacityBytes, m.Values, timeseries.Any)
+				case "gcp_cloudsql_disk_used_bytes":
+					volume.UsedBytes = merge(volume.UsedBytes, m.Values, timeseries.Any)
+				case "gcp_cloudsql_disk_ops_per_second":
+					const device = "data"
+					volume.Device.Update(m.Values, device)
+					stat := instance.Node.Disks[device]
+					if stat == nil {
+						stat = &model.DiskStats{}
+						instance.Node.Disks[device] = stat
+					}
+					switch m.Labels["operation"] {
+					case "read":
+						stat.ReadOps = merge(stat.ReadOps, m.Values, timeseries.Any)
+					case "write":
+						stat.WriteOps = merge(stat.WriteOps, m.Values, timeseries.Any)
+					}
+				}
+			case "gcp_cloudsql_net_rx_bytes_per_second", "gcp_cloudsql_net_tx_bytes_per_second",
+				"gcp_memorystore_net_rx_bytes_per_second", "gcp_memorystore_net_tx_bytes_per_second":
+				const name = "eth0"
+				var stat *model.InterfaceStats
+				for _, s := range instance.Node.NetInterfaces {
+					if s.Name == name {
+						stat = s
+					}
+				}
+				if stat == nil {
+					stat = &model.InterfaceStats{Name: name}
+					instance.Node.NetInterfaces = append(instance.Node.NetInterfaces, stat)
+				}
+				if strings.HasSuffix(queryName, "_rx_bytes_per_second") {
+					stat.RxBytes = merge(stat.RxBytes, m.Values, timeseries.Any)
+				} else {
+					stat.TxBytes = merge(stat.TxBytes, m.Values, timeseries.Any)
+				}
+			case "gcp_memorystore_commands_per_second", "gcp_memorystore_commands_duration_seconds_per_second":
+				// Cloud Monitoring provides the rates rather than the counters of redis_exporter, so they're set directly
+				if instance.Redis == nil {
+					instance.Redis = model.NewRedis()
+				}
+				cmd := m.Labels["cmd"]
+				if queryName == "gcp_memorystore_commands_per_second" {
+					instance.Redis.Calls[cmd] = merge(instance.Redis.Calls[cmd], m.Values, timeseries.Any)
+				} else {
+					instance.Redis.CallsTime[cmd] = merge(instance.Redis.CallsTime[cmd], m.Values, timeseries.Any)
+				}
+			}
+		}
+	}
+}
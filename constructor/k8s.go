@@ -6,6 +6,7 @@ import (
 	"github.com/coroot/coroot/timeseries"
 	"k8s.io/klog"
 	"net"
+	"sort"
 	"strings"
 )
 
@@ -13,6 +14,7 @@ func loadKubernetesMetadata(w *model.World, metrics map[string][]model.MetricVal
 	loadServices(w, metrics["kube_service_info"])
 	pods := podInfo(w, metrics["kube_pod_info"])
 	podLabels(metrics["kube_pod_labels"], pods)
+	podAnnotations(metrics["kube_pod_annotations"], pods)
 
 	for queryName := range QUERIES {
 		switch {
@@ -25,6 +27,7 @@ func loadKubernetesMetadata(w *model.World, metrics map[string][]model.MetricVal
 		}
 	}
 	loadApplications(w, metrics)
+	loadHelmReleases(w, metrics["kube_helm_release_age"])
 }
 
 func loadServices(w *model.World, metrics []model.MetricValues) {
@@ -70,6 +73,14 @@ func loadApplications(w *model.World, metrics map[string][]model.MetricValues) {
 			switch queryName {
 			case "kube_deployment_spec_replicas", "kube_statefulset_replicas", "kube_daemonset_status_desired_number_scheduled":
 				app.DesiredInstances = merge(app.DesiredInstances, m.Values, timeseries.Any)
+			case "kube_statefulset_status_update_revision":
+				if app.UpdateRevisions == nil {
+					app.UpdateRevisions = map[string]*timeseries.TimeSeries{}
+				}
+				r := m.Labels["revision"]
+				app.UpdateRevisions[r] = merge(app.UpdateRevisions[r], m.Values, timeseries.Any)
+			case "kube_statefulset_status_replicas_updated":
+				app.UpdatedInstances = merge(app.UpdatedInstances, m.Values, timeseries.Any)
 			}
 		}
 	}
@@ -130,6 +141,19 @@ func podLabels(metrics []model.MetricValues, pods map[string]*model.Instance) {
 			klog.Warningln("unknown pod:", uid, m.Labels["pod"], m.Labels["namespace"])
 			continue
 		}
+		if instance.Pod != nil {
+			instance.Pod.Helm = model.NewHelmReleaseFromLabels(m.Labels)
+			switch instance.OwnerId.Kind {
+			case model.ApplicationKindStatefulSet, model.ApplicationKindDaemonSet:
+				// pods of StatefulSets keep their names, the revision of the current pod is used
+				if hash := m.Labels["label_controller_revision_hash"]; hash != "" && (instance.Pod.ControllerRevision == "" || !timeseries.IsNaN(m.Values.Last())) {
+					if prefix := instance.OwnerId.Name + "-"; !strings.HasPrefix(hash, prefix) {
+						hash = prefix + hash
+					}
+					instance.Pod.ControllerRevision = hash
+				}
+			}
+		}
 		cluster, role := "", ""
 		switch {
 		case m.Labels["label_postgres_operator_crunchydata_com_cluster"] != "":
@@ -156,6 +180,21 @@ func podLabels(metrics []model.MetricValues, pods map[string]*model.Instance) {
 	}
 }
 
+func podAnnotations(metrics []model.MetricValues, pods map[string]*model.Instance) {
+	for _, m := range metrics {
+		instance := pods[m.Labels["uid"]]
+		if instance == nil {
+			continue
+		}
+		if owner := model.NewApplicationOwnerFromAnnotations(m.Labels); owner != nil {
+			instance.Pod.Owner = owner
+		}
+		if instance.Pod.Helm != nil {
+			instance.Pod.Helm.ValuesHash = model.HelmValuesHash(m.Labels)
+		}
+	}
+}
+
 func podStatus(queryName string, metrics []model.MetricValues, pods map[string]*model.Instance) {
 	for _, m := range metrics {
 		uid := m.Labels["uid"]
@@ -244,3 +283,35 @@ func podContainer(queryName string, metrics []model.MetricValues, pods map[strin
 		}
 	}
 }
+
+func loadHelmReleases(w *model.World, metrics []model.MetricValues) {
+	type key struct{ ns, release string }
+	revisions := map[key][]model.HelmReleaseRevision{}
+	for _, m := range metrics {
+		release, revision := model.ParseHelmReleaseSecret(m.Labels["secret"])
+		if release == "" {
+			continue
+		}
+		t, age := m.Values.LastNotNull()
+		if t.IsZero() || timeseries.IsNaN(age) {
+			continue
+		}
+		k := key{ns: m.Labels["namespace"], release: release}
+		revisions[k] = append(revisions[k], model.HelmReleaseRevision{Revision: revision, CreatedAt: t.Add(-timeseries.Duration(age))})
+	}
+	if len(revisions) == 0 {
+		return
+	}
+	for _, app := range w.Applications {
+		for _, i := range app.Instances {
+			if i.Pod == nil || i.Pod.Helm == nil {
+				continue
+			}
+			if rs := revisions[key{ns: app.Id.Namespace, release: i.Pod.Helm.Name}]; rs != nil {
+				sort.Slice(rs, func(i, j int) bool { return rs[i].Revision < rs[j].Revision })
+				app.HelmRevisions = rs
+				break
+			}
+		}
+	}
+}
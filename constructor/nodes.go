@@ -46,6 +46,9 @@ func (c *Constructor) loadNodes(w *model.World, metrics map[string][]model.Metri
 			continue
 		}
 		for _, m := range metrics[queryName] {
+			if nodeExporterMetric(w, queryName, m) {
+				continue
+			}
 			node := nodesByMachineId[m.Labels["machine_id"]]
 			if node == nil {
 				continue
@@ -154,3 +157,29 @@ func nodeInterface(node *model.Node, queryName string, m model.MetricValues) {
 		stat.TxBytes = merge(stat.TxBytes, m.Values, timeseries.Any)
 	}
 }
+
+// nodeExporterMetric loads the metric of node_exporter to the node with the same hostname
+// and reports whether the query is one of node_exporter's.
+func nodeExporterMetric(w *model.World, queryName string, m model.MetricValues) bool {
+	switch queryName {
+	case "node_conntrack_entries", "node_conntrack_limit":
+	default:
+		return false
+	}
+	name := m.Labels["nodename"]
+	if name == "" {
+		return true
+	}
+	for _, node := range w.Nodes {
+		if node.Name.Value() != name {
+			continue
+		}
+		switch queryName {
+		case "node_conntrack_entries":
+			node.ConntrackEntries = merge(node.ConntrackEntries, m.Values, timeseries.Any)
+		case "node_conntrack_limit":
+			node.ConntrackLimit = merge(node.ConntrackLimit, m.Values, timeseries.Any)
+		}
+	}
+	return true
+}
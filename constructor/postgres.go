@@ -1,10 +1,24 @@
 package constructor
 
 import (
+	"context"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
 )
 
+// loadQueryStats adds the per-query metrics stored in ClickHouse to the metrics loaded from Prometheus.
+func (c *Constructor) loadQueryStats(ctx context.Context, w *model.World, metrics map[string][]model.MetricValues) {
+	stats, err := c.queryStats.GetQueryStats(ctx, w.Ctx.From, w.Ctx.To, w.Ctx.Step)
+	if err != nil {
+		klog.Errorln("failed to load query stats:", err)
+		return
+	}
+	for name, mvs := range stats {
+		metrics[name] = append(metrics[name], mvs...)
+	}
+}
+
 func postgres(instance *model.Instance, queryName string, m model.MetricValues) {
 	if instance == nil {
 		return
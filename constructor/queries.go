@@ -10,6 +10,7 @@ import (
 
 const (
 	qApplicationCustomSLI                  = "application_custom_sli"
+	qCustomCheck                           = "custom_check"
 	qRecordingRuleInboundRequestsTotal     = "rr_application_inbound_requests_total"
 	qRecordingRuleInboundRequestsHistogram = "rr_application_inbound_requests_histogram"
 )
@@ -41,15 +42,26 @@ var QUERIES = map[string]string{
 	"node_net_rx_bytes":           `rate(node_net_received_bytes_total[$RANGE])`,
 	"node_net_tx_bytes":           `rate(node_net_transmitted_bytes_total[$RANGE])`,
 
+	// The agent doesn't export the conntrack metrics, they're collected by node_exporter (the conntrack collector).
+	// Its metrics don't have the machine_id label, so the nodes are matched by the hostname (see nodeExporterMetric).
+	"node_conntrack_entries": `node_nf_conntrack_entries * on(instance) group_left(nodename) node_uname_info`,
+	"node_conntrack_limit":   `node_nf_conntrack_entries_limit * on(instance) group_left(nodename) node_uname_info`,
+
+	// The agent doesn't inspect TLS handshakes, the certificates are checked by blackbox_exporter (see loadTlsCertificates).
+	"tls_certificate_expiry": `probe_ssl_earliest_cert_expiry`,
+
 	"kube_node_info":    `kube_node_info`,
 	"kube_service_info": `kube_service_info`,
 
 	"kube_pod_info":             `kube_pod_info`,
 	"kube_pod_labels":           `kube_pod_labels`,
+	"kube_pod_annotations":      `kube_pod_annotations`,
 	"kube_pod_status_phase":     `kube_pod_status_phase`,
 	"kube_pod_status_ready":     `kube_pod_status_ready{condition="true"}`,
 	"kube_pod_status_scheduled": `kube_pod_status_scheduled{condition="true"} > 0`,
 
+	"kube_helm_release_age": `time() - kube_secret_created{secret=~"sh[.]helm[.]release[.]v1[.].+"}`,
+
 	"container_info":                        `container_info`,
 	"container_net_latency":                 `container_net_latency_seconds`,
 	"container_net_tcp_successful_connects": `rate(container_net_tcp_successful_connects_total[$RANGE])`,
@@ -94,6 +106,8 @@ var QUERIES = map[string]string{
 	"container_cassandra_queries_latency":   `rate(container_cassandra_queries_duration_seconds_total_sum [$RANGE]) / rate(container_cassandra_queries_duration_seconds_total_count [$RANGE])`,
 	"container_cassandra_queries_histogram": `rate(container_cassandra_queries_duration_seconds_total_bucket[$RANGE])`,
 	"container_rabbitmq_messages":           `rate(container_rabbitmq_messages_total[$RANGE])`,
+	"container_dns_requests_total":          `rate(container_dns_requests_total[$RANGE])`,
+	"container_dns_requests_latency":        `rate(container_dns_requests_duration_seconds_total_sum[$RANGE]) / rate(container_dns_requests_duration_seconds_total_count[$RANGE])`,
 
 	"kube_pod_init_container_info":                     `kube_pod_init_container_info`,
 	"kube_pod_container_resource_requests":             `kube_pod_container_resource_requests`,
@@ -107,6 +121,8 @@ var QUERIES = map[string]string{
 	"kube_deployment_spec_replicas":                    `kube_deployment_spec_replicas`,
 	"kube_daemonset_status_desired_number_scheduled":   `kube_daemonset_status_desired_number_scheduled`,
 	"kube_statefulset_replicas":                        `kube_statefulset_replicas`,
+	"kube_statefulset_status_update_revision":          `kube_statefulset_status_update_revision`,
+	"kube_statefulset_status_replicas_updated":         `kube_statefulset_status_replicas_updated`,
 
 	"aws_rds_info":                        `aws_rds_info`,
 	"aws_rds_status":                      `aws_rds_status`,
@@ -126,6 +142,41 @@ var QUERIES = map[string]string{
 	"aws_rds_net_rx_bytes_per_second":     `aws_rds_net_rx_bytes_per_second`,
 	"aws_rds_net_tx_bytes_per_second":     `aws_rds_net_tx_bytes_per_second`,
 
+	"gcp_cloudsql_info":                                    `gcp_cloudsql_info`,
+	"gcp_cloudsql_status":                                  `gcp_cloudsql_status`,
+	"gcp_cloudsql_cpu_cores":                               `gcp_cloudsql_cpu_cores`,
+	"gcp_cloudsql_cpu_usage_percent":                       `gcp_cloudsql_cpu_usage_percent`,
+	"gcp_cloudsql_memory_total_bytes":                      `gcp_cloudsql_memory_total_bytes`,
+	"gcp_cloudsql_memory_cached_bytes":                     `gcp_cloudsql_memory_cached_bytes`,
+	"gcp_cloudsql_memory_free_bytes":                       `gcp_cloudsql_memory_free_bytes`,
+	"gcp_cloudsql_disk_total_bytes":                        `gcp_cloudsql_disk_total_bytes`,
+	"gcp_cloudsql_disk_used_bytes":                         `gcp_cloudsql_disk_used_bytes`,
+	"gcp_cloudsql_disk_ops_per_second":                     `gcp_cloudsql_disk_ops_per_second`,
+	"gcp_cloudsql_net_rx_bytes_per_second":                 `gcp_cloudsql_net_rx_bytes_per_second`,
+	"gcp_cloudsql_net_tx_bytes_per_second":                 `gcp_cloudsql_net_tx_bytes_per_second`,
+	"gcp_memorystore_info":                                 `gcp_memorystore_info`,
+	"gcp_memorystore_status":                               `gcp_memorystore_status`,
+	"gcp_memorystore_memory_total_bytes":                   `gcp_memorystore_memory_total_bytes`,
+	"gcp_memorystore_memory_free_bytes":                    `gcp_memorystore_memory_free_bytes`,
+	"gcp_memorystore_net_rx_bytes_per_second":              `gcp_memorystore_net_rx_bytes_per_second`,
+	"gcp_memorystore_net_tx_bytes_per_second":              `gcp_memorystore_net_tx_bytes_per_second`,
+	"gcp_memorystore_commands_per_second":                  `gcp_memorystore_commands_per_second`,
+	"gcp_memorystore_commands_duration_seconds_per_second": `gcp_memorystore_commands_duration_seconds_per_second`,
+
+	"aws_target_group_requests":          `aws_target_group_requests_per_second`,
+	"aws_target_group_errors":            `aws_target_group_errors_per_second`,
+	"aws_target_group_response_time":     `aws_target_group_response_time_seconds`,
+	"aws_target_group_healthy_targets":   `aws_target_group_healthy_targets`,
+	"aws_target_group_unhealthy_targets": `aws_target_group_unhealthy_targets`,
+	"aws_sqs_messages_visible":           `aws_sqs_messages_visible`,
+	"aws_sqs_oldest_message_age":         `aws_sqs_oldest_message_age_seconds`,
+	"aws_sqs_messages_sent":              `aws_sqs_messages_sent_per_second`,
+	"aws_sqs_messages_deleted":           `aws_sqs_messages_deleted_per_second`,
+	"aws_lambda_invocations":             `aws_lambda_invocations_per_second`,
+	"aws_lambda_errors":                  `aws_lambda_errors_per_second`,
+	"aws_lambda_throttles":               `aws_lambda_throttles_per_second`,
+	"aws_lambda_duration":                `aws_lambda_duration_seconds`,
+
 	"pg_connections":                  `pg_connections{db!="postgres"}`,
 	"pg_up":                           `pg_up`,
 	"pg_info":                         `pg_info`,
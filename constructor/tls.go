@@ -0,0 +1,67 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// loadTlsCertificates attributes the certificates probed by blackbox_exporter (probe_ssl_earliest_cert_expiry)
+// to the instances listening on the probed endpoints and to the ones connecting to them.
+// The target of a probe is matched by the IP address or by the name of a Kubernetes service
+// (e.g., https://cart.shop.svc:8443), the port defaults to 443.
+func loadTlsCertificates(w *model.World, metrics map[string][]model.MetricValues) {
+	series := metrics["tls_certificate_expiry"]
+	if len(series) == 0 {
+		return
+	}
+	serviceIPs := map[string]string{}
+	for _, s := range w.Services {
+		if s.ClusterIP != "" {
+			serviceIPs[s.Name+"."+s.Namespace] = s.ClusterIP
+		}
+	}
+	for _, m := range series {
+		target := m.Labels["instance"]
+		ip, port := tlsProbeAddress(target, serviceIPs)
+		if ip == "" {
+			continue
+		}
+		for _, app := range w.Applications {
+			for _, i := range app.Instances {
+				if i.TcpListens[model.Listen{IP: ip, Port: port}] || i.TcpListens[model.Listen{IP: ip, Port: port, Proxied: true}] {
+					e := model.TlsEndpoint{Endpoint: target}
+					i.TlsCertificates[e] = merge(i.TlsCertificates[e], m.Values, timeseries.Min)
+				}
+				for _, u := range i.Upstreams {
+					if (u.ActualRemoteIP == ip && u.ActualRemotePort == port) || (u.ServiceRemoteIP == ip && u.ServiceRemotePort == port) {
+						e := model.TlsEndpoint{Endpoint: target, Client: true}
+						i.TlsCertificates[e] = merge(i.TlsCertificates[e], m.Values, timeseries.Min)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func tlsProbeAddress(target string, serviceIPs map[string]string) (string, string) {
+	if !strings.Contains(target, "://") {
+		target = "tcp://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", ""
+	}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "443"
+	}
+	if net.ParseIP(host) != nil {
+		return host, port
+	}
+	host = strings.TrimSuffix(strings.TrimSuffix(host, "."), ".cluster.local")
+	return serviceIPs[strings.TrimSuffix(host, ".svc")], port
+}
@@ -0,0 +1,42 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadTlsCertificates(t *testing.T) {
+	w := model.NewWorld(0, 180, 60)
+	w.Services = []*model.Service{{Name: "cart", Namespace: "shop", ClusterIP: "10.96.0.10"}}
+	cart := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"))
+	server := cart.GetOrCreateInstance("cart-1", nil)
+	server.TcpListens[model.Listen{IP: "10.0.0.5", Port: "8443"}] = true
+	frontend := model.NewApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "frontend"))
+	client := frontend.GetOrCreateInstance("frontend-1", nil)
+	client.AddUpstreamConnection("10.0.0.5", "8443", "10.96.0.10", "443", "frontend")
+	w.Applications = []*model.Application{cart, frontend}
+
+	expiry := timeseries.NewWithData(0, 60, []float32{1e6, 1e6, 1e6})
+	load := func(target string) {
+		for _, i := range []*model.Instance{server, client} {
+			i.TlsCertificates = map[model.TlsEndpoint]*timeseries.TimeSeries{}
+		}
+		loadTlsCertificates(w, map[string][]model.MetricValues{
+			"tls_certificate_expiry": {{Labels: model.Labels{"instance": target}, Values: expiry}},
+		})
+	}
+
+	load("https://10.0.0.5:8443/health")
+	assert.NotNil(t, server.TlsCertificates[model.TlsEndpoint{Endpoint: "https://10.0.0.5:8443/health"}])
+	assert.NotNil(t, client.TlsCertificates[model.TlsEndpoint{Endpoint: "https://10.0.0.5:8443/health", Client: true}])
+
+	load("https://cart.shop.svc.cluster.local")
+	assert.Empty(t, server.TlsCertificates)
+	assert.NotNil(t, client.TlsCertificates[model.TlsEndpoint{Endpoint: "https://cart.shop.svc.cluster.local", Client: true}])
+
+	load("example.com:443")
+	assert.Empty(t, server.TlsCertificates)
+	assert.Empty(t, client.TlsCertificates)
+}
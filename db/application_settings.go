@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"github.com/coroot/coroot/model"
+	"k8s.io/klog"
 )
 
 type ApplicationSettings struct {
 	Pyroscope *ApplicationSettingsPyroscope `json:"pyroscope,omitempty"`
 	Tracing   *ApplicationSettingsTracing   `json:"tracing,omitempty"`
+	Owner     *model.ApplicationOwner       `json:"owner,omitempty"`
 }
 
 func (s *ApplicationSettings) Migrate(m *Migrator) error {
@@ -46,6 +48,34 @@ func (db *DB) GetApplicationSettings(projectId ProjectId, appId model.Applicatio
 	return res, nil
 }
 
+// GetApplicationOwners returns the ownership of the applications configured via the API.
+func (db *DB) GetApplicationOwners(projectId ProjectId) (map[model.ApplicationId]*model.ApplicationOwner, error) {
+	rows, err := db.db.Query("SELECT application_id, settings FROM application_settings WHERE project_id = $1", projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	res := map[model.ApplicationId]*model.ApplicationOwner{}
+	var settings sql.NullString
+	for rows.Next() {
+		var appId model.ApplicationId
+		if err := rows.Scan(&appId, &settings); err != nil {
+			return nil, err
+		}
+		var as *ApplicationSettings
+		if err := unmarshal(settings.String, &as); err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		if as != nil && !as.Owner.IsEmpty() {
+			res[appId] = as.Owner
+		}
+	}
+	return res, nil
+}
+
 func (db *DB) SaveApplicationSetting(projectId ProjectId, appId model.ApplicationId, s any) error {
 	as, err := db.GetApplicationSettings(projectId, appId)
 	if err != nil {
@@ -61,6 +91,8 @@ func (db *DB) SaveApplicationSetting(projectId ProjectId, appId model.Applicatio
 		as.Pyroscope = v
 	case *ApplicationSettingsTracing:
 		as.Tracing = v
+	case *model.ApplicationOwner:
+		as.Owner = v
 	default:
 		return fmt.Errorf("unsupported type: %T", s)
 	}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+// CheckThreshold stores the thresholds learned for the checks configured in the adaptive mode.
+type CheckThreshold struct{}
+
+func (ct *CheckThreshold) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS check_threshold (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		application_id TEXT NOT NULL,
+		check_id TEXT NOT NULL,
+		threshold REAL NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (project_id, application_id, check_id)
+	)`)
+}
+
+func (db *DB) GetCheckThresholds(projectId ProjectId) (map[model.ApplicationId]map[model.CheckId]float32, error) {
+	rows, err := db.db.Query("SELECT application_id, check_id, threshold FROM check_threshold WHERE project_id = $1", projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	res := map[model.ApplicationId]map[model.CheckId]float32{}
+	var appIdStr string
+	var checkId model.CheckId
+	var threshold float32
+	for rows.Next() {
+		if err := rows.Scan(&appIdStr, &checkId, &threshold); err != nil {
+			return nil, err
+		}
+		appId, err := model.NewApplicationIdFromString(appIdStr)
+		if err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		if res[appId] == nil {
+			res[appId] = map[model.CheckId]float32{}
+		}
+		res[appId][checkId] = threshold
+	}
+	return res, nil
+}
+
+func (db *DB) SaveCheckThreshold(projectId ProjectId, appId model.ApplicationId, checkId model.CheckId, threshold float32, now timeseries.Time) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	_, err = tx.Exec(
+		"DELETE FROM check_threshold WHERE project_id = $1 AND application_id = $2 AND check_id = $3",
+		projectId, appId.String(), checkId)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO check_threshold (project_id, application_id, check_id, threshold, updated_at) VALUES ($1, $2, $3, $4, $5)",
+		projectId, appId.String(), checkId, threshold, now)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+	"strings"
+)
+
+// CheckValue stores the history of the check values computed by each evaluation.
+type CheckValue struct{}
+
+func (cv *CheckValue) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS check_value (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		application_id TEXT NOT NULL,
+		check_id TEXT NOT NULL,
+		report TEXT NOT NULL,
+		ts INTEGER NOT NULL,
+		value REAL NOT NULL,
+		status INTEGER NOT NULL,
+		PRIMARY KEY (project_id, application_id, check_id, ts)
+	);
+	CREATE INDEX IF NOT EXISTS check_value_ts ON check_value (project_id, ts);
+`)
+}
+
+func (db *DB) SaveCheckValues(projectId ProjectId, now timeseries.Time, values []model.CheckValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	for _, v := range values {
+		_, err = tx.Exec(
+			"INSERT INTO check_value (project_id, application_id, check_id, report, ts, value, status) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			projectId, v.ApplicationId.String(), v.CheckId, v.Report, now, v.Value, v.Status)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (db *DB) DeleteCheckValues(projectId ProjectId, before timeseries.Time) error {
+	_, err := db.db.Exec("DELETE FROM check_value WHERE project_id = $1 AND ts < $2", projectId, before)
+	return err
+}
+
+// GetCheckValues returns the worst (max) check values aggregated with the given step.
+func (db *DB) GetCheckValues(projectId ProjectId, appId model.ApplicationId, checkId model.CheckId, from, to timeseries.Time, step timeseries.Duration) (*timeseries.TimeSeries, error) {
+	rows, err := db.db.Query(
+		"SELECT ts - ts % $1, MAX(value) FROM check_value WHERE project_id = $2 AND application_id = $3 AND check_id = $4 AND ts >= $5 AND ts <= $6 GROUP BY 1",
+		step, projectId, appId.String(), checkId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	from = from.Truncate(step)
+	res := timeseries.New(from, int(to.Sub(from)/step)+1, step)
+	var t timeseries.Time
+	var v float32
+	for rows.Next() {
+		if err := rows.Scan(&t, &v); err != nil {
+			return nil, err
+		}
+		res.Set(t, v)
+	}
+	return res, nil
+}
+
+// GetCheckFailures returns the share of the evaluations (per step) in which any of the checks failed (status >= WARNING),
+// and the worst status of the checks at the last evaluation.
+func (db *DB) GetCheckFailures(projectId ProjectId, appId model.ApplicationId, checkIds []model.CheckId, from, to timeseries.Time, step timeseries.Duration) (*timeseries.TimeSeries, model.Status, error) {
+	args := []any{projectId, appId.String(), from, to}
+	placeholders := make([]string, 0, len(checkIds))
+	for _, id := range checkIds {
+		args = append(args, id)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	rows, err := db.db.Query(
+		"SELECT ts, MAX(status) FROM check_value WHERE project_id = $1 AND application_id = $2 AND ts >= $3 AND ts <= $4 AND check_id IN ("+
+			strings.Join(placeholders, ", ")+") GROUP BY ts ORDER BY ts",
+		args...)
+	if err != nil {
+		return nil, model.UNKNOWN, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	failed := map[timeseries.Time]int{}
+	total := map[timeseries.Time]int{}
+	last := model.UNKNOWN
+	var t timeseries.Time
+	var status model.Status
+	for rows.Next() {
+		if err := rows.Scan(&t, &status); err != nil {
+			return nil, model.UNKNOWN, err
+		}
+		bucket := t.Truncate(step)
+		if status >= model.WARNING {
+			failed[bucket]++
+		}
+		total[bucket]++
+		last = status
+	}
+	from = from.Truncate(step)
+	res := timeseries.New(from, int(to.Sub(from)/step)+1, step)
+	for bucket, n := range total {
+		res.Set(bucket, float32(failed[bucket])/float32(n))
+	}
+	return res, last, nil
+}
+
+// GetCheckTrends compares the average check values over the most recent period with the average values over the rest of the trend window.
+func (db *DB) GetCheckTrends(projectId ProjectId, now timeseries.Time) (map[model.ApplicationId][]model.CheckTrend, error) {
+	rows, err := db.db.Query(
+		"SELECT application_id, check_id, report, AVG(CASE WHEN ts < $1 THEN value END), AVG(CASE WHEN ts >= $1 THEN value END) FROM check_value WHERE project_id = $2 AND ts >= $3 GROUP BY application_id, check_id, report",
+		now.Add(-model.CheckTrendRecent), projectId, now.Add(-model.CheckTrendWindow))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	res := map[model.ApplicationId][]model.CheckTrend{}
+	var appIdStr string
+	var checkId model.CheckId
+	var report model.AuditReportName
+	var before, after sql.NullFloat64
+	for rows.Next() {
+		if err := rows.Scan(&appIdStr, &checkId, &report, &before, &after); err != nil {
+			return nil, err
+		}
+		if !before.Valid || !after.Valid {
+			continue
+		}
+		appId, err := model.NewApplicationIdFromString(appIdStr)
+		if err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		res[appId] = append(res[appId], model.NewCheckTrend(checkId, report, float32(before.Float64), float32(after.Float64)))
+	}
+	return res, nil
+}
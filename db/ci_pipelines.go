@@ -0,0 +1,78 @@
+package db
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+// CIPipeline stores the CI pipeline runs reported through webhooks until they're linked to deployments.
+type CIPipeline struct{}
+
+func (p *CIPipeline) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS ci_pipeline (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		provider TEXT NOT NULL,
+		id TEXT NOT NULL,
+		finished_at INTEGER NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (project_id, provider, id)
+	);
+	CREATE INDEX IF NOT EXISTS ci_pipeline_finished_at ON ci_pipeline (project_id, finished_at);
+`)
+}
+
+func (db *DB) SaveCIPipeline(projectId ProjectId, p *model.CIPipeline) error {
+	data, err := marshal(p)
+	if err != nil {
+		return err
+	}
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err = tx.Exec("DELETE FROM ci_pipeline WHERE project_id = $1 AND provider = $2 AND id = $3", projectId, p.Provider, p.Id); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO ci_pipeline (project_id, provider, id, finished_at, data) VALUES ($1, $2, $3, $4, $5)",
+		projectId, p.Provider, p.Id, p.FinishedAt, data)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetCIPipelines returns the pipeline runs finished after the given time, the most recent first.
+func (db *DB) GetCIPipelines(projectId ProjectId, from timeseries.Time) ([]*model.CIPipeline, error) {
+	rows, err := db.db.Query("SELECT data FROM ci_pipeline WHERE project_id = $1 AND finished_at >= $2 ORDER BY finished_at DESC", projectId, from)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var res []*model.CIPipeline
+	var data string
+	for rows.Next() {
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var p *model.CIPipeline
+		if err := unmarshal(data, &p); err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+func (db *DB) DeleteCIPipelines(projectId ProjectId, before timeseries.Time) error {
+	_, err := db.db.Exec("DELETE FROM ci_pipeline WHERE project_id = $1 AND finished_at < $2", projectId, before)
+	return err
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/model"
+)
+
+// Config is a configuration-as-code document containing the settings of all projects.
+type Config struct {
+	Projects []ProjectConfig `json:"projects"`
+}
+
+type ProjectConfig struct {
+	Name         string                 `json:"name"`
+	Prometheus   IntegrationsPrometheus `json:"prometheus"`
+	Settings     Settings               `json:"settings"`
+	CheckConfigs model.CheckConfigs     `json:"check_configs"`
+}
+
+func (db *DB) ExportConfig() (*Config, error) {
+	projects, err := db.GetProjects()
+	if err != nil {
+		return nil, err
+	}
+	res := &Config{Projects: []ProjectConfig{}}
+	for _, p := range projects {
+		checkConfigs, err := db.GetCheckConfigs(p.Id)
+		if err != nil {
+			return nil, err
+		}
+		res.Projects = append(res.Projects, ProjectConfig{
+			Name:         p.Name,
+			Prometheus:   p.Prometheus,
+			Settings:     p.Settings,
+			CheckConfigs: checkConfigs,
+		})
+	}
+	return res, nil
+}
+
+// ImportConfig applies the document: projects are matched by name and created if they don't exist,
+// their settings and check configs are replaced entirely, so importing the same document again changes nothing.
+func (db *DB) ImportConfig(cfg *Config) error {
+	names, err := db.GetProjectNames()
+	if err != nil {
+		return err
+	}
+	ids := map[string]ProjectId{}
+	for id, name := range names {
+		ids[name] = id
+	}
+	for _, pc := range cfg.Projects {
+		id := ids[pc.Name]
+		if id == "" {
+			if id, err = db.SaveProject(Project{Name: pc.Name}); err != nil {
+				return err
+			}
+		}
+		if err := db.importProjectConfig(id, pc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) importProjectConfig(id ProjectId, pc ProjectConfig) error {
+	p := Project{Id: id, Prometheus: pc.Prometheus, Settings: pc.Settings}
+	p.applyDefaults()
+	prometheus, err := json.Marshal(p.Prometheus)
+	if err != nil {
+		return err
+	}
+	settings, err := json.Marshal(p.Settings)
+	if err != nil {
+		return err
+	}
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err := tx.Exec("UPDATE project SET prometheus = $1, settings = $2 WHERE id = $3", string(prometheus), string(settings), id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM check_configs WHERE project_id = $1", id); err != nil {
+		return err
+	}
+	for appId, configs := range pc.CheckConfigs {
+		data, err := json.Marshal(configs)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO check_configs (project_id, application_id, configs) VALUES ($1, $2, $3)", id, appId.String(), string(data)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -52,8 +52,14 @@ func Open(dataDir string, pgConnString string) (*DB, error) {
 		&CheckConfigs{},
 		&Incident{},
 		&IncidentNotification{},
+		&NotificationDelivery{},
 		&ApplicationDeployment{},
 		&ApplicationSettings{},
+		&CheckThreshold{},
+		&CheckValue{},
+		&ErrorBudgetRecord{},
+		&NetworkRtt{},
+		&CIPipeline{},
 	)
 	if err != nil {
 		return nil, err
@@ -123,7 +129,7 @@ func (m *Migrator) Exec(query string, args ...any) error {
 func (m *Migrator) AddColumnIfNotExists(table, column, dataType string) error {
 	switch m.typ {
 	case TypeSqlite:
-		rows, err := m.db.Query("SELECT name FROM pragma_table_info('project');")
+		rows, err := m.db.Query("SELECT name FROM pragma_table_info($1);", table)
 		if err != nil {
 			return nil
 		}
@@ -77,6 +77,25 @@ func (db *DB) SaveApplicationDeployment(projectId ProjectId, d *model.Applicatio
 	return err
 }
 
+// AddApplicationDeployment inserts a deployment recorded through the API. Unlike SaveApplicationDeployment,
+// it doesn't merge the deployment with an existing one started at the same time, but returns ErrConflict.
+func (db *DB) AddApplicationDeployment(projectId ProjectId, d *model.ApplicationDeployment) error {
+	if d.StartedAt.IsZero() {
+		return fmt.Errorf("invalid deployment")
+	}
+	details, err := marshal(d.Details)
+	if err != nil {
+		return err
+	}
+	_, err = db.db.Exec(
+		"INSERT INTO application_deployment (project_id, application_id, name, started_at, finished_at, details) VALUES ($1, $2, $3, $4, $5, $6)",
+		projectId, d.ApplicationId, d.Name, d.StartedAt, d.FinishedAt, details)
+	if db.IsUniqueViolationError(err) {
+		return ErrConflict
+	}
+	return err
+}
+
 func (db *DB) GetApplicationDeployments(projectId ProjectId) (map[model.ApplicationId][]*model.ApplicationDeployment, error) {
 	q := `
 		WITH p AS (
@@ -134,6 +153,24 @@ func (db *DB) SaveApplicationDeploymentMetricsSnapshot(projectId ProjectId, d *m
 	return err
 }
 
+func (db *DB) SaveApplicationDeploymentDetails(projectId ProjectId, d *model.ApplicationDeployment) error {
+	data, err := marshal(d.Details)
+	if err != nil {
+		return err
+	}
+	_, err = db.db.Exec(
+		"UPDATE application_deployment SET details = $1 WHERE project_id = $2 AND application_id = $3 AND started_at = $4",
+		data, projectId, d.ApplicationId, d.StartedAt)
+	return err
+}
+
+func (db *DB) DeleteApplicationDeployment(projectId ProjectId, d *model.ApplicationDeployment) error {
+	_, err := db.db.Exec(
+		"DELETE FROM application_deployment WHERE project_id = $1 AND application_id = $2 AND started_at = $3",
+		projectId, d.ApplicationId, d.StartedAt)
+	return err
+}
+
 func (db *DB) SaveApplicationDeploymentNotifications(projectId ProjectId, d *model.ApplicationDeployment) error {
 	data, err := marshal(d.Notifications)
 	if err != nil {
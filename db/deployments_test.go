@@ -0,0 +1,39 @@
+package db
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAddApplicationDeployment(t *testing.T) {
+	database, err := Open(t.TempDir(), "")
+	require.NoError(t, err)
+	projectId, err := database.SaveProject(Project{Name: "test"})
+	require.NoError(t, err)
+	appId := model.NewApplicationId("default", model.ApplicationKindDeployment, "cart")
+
+	rs := &model.ApplicationDeployment{ApplicationId: appId, Name: "cart-5d8f7c9b4", StartedAt: 1700000000}
+	require.NoError(t, database.SaveApplicationDeployment(projectId, rs))
+
+	change := &model.ApplicationDeployment{
+		ApplicationId: appId, Name: "change-1", StartedAt: 1700000000, FinishedAt: 1700000000,
+		Details: &model.ApplicationDeploymentDetails{Change: &model.ApplicationChange{Type: "migration", Description: "add index"}},
+	}
+	assert.ErrorIs(t, database.AddApplicationDeployment(projectId, change), ErrConflict)
+
+	change.StartedAt, change.FinishedAt = 1700000060, 1700000060
+	require.NoError(t, database.AddApplicationDeployment(projectId, change))
+	assert.ErrorIs(t, database.AddApplicationDeployment(projectId, change), ErrConflict)
+	assert.Error(t, database.AddApplicationDeployment(projectId, &model.ApplicationDeployment{ApplicationId: appId, Name: "change-2"}))
+
+	deployments, err := database.GetApplicationDeployments(projectId)
+	require.NoError(t, err)
+	require.Len(t, deployments[appId], 2)
+	assert.Equal(t, "cart-5d8f7c9b4", deployments[appId][0].Name)
+	assert.Equal(t, timeseries.Time(0), deployments[appId][0].FinishedAt, "the existing deployment must not be modified")
+	assert.Equal(t, "change-1", deployments[appId][1].Name)
+	assert.Equal(t, "add index", deployments[appId][1].Details.Change.Description)
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+// ErrorBudgetRecord stores the error budget ledger: hourly numbers of bad and total requests for each SLO.
+type ErrorBudgetRecord struct{}
+
+func (r *ErrorBudgetRecord) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS error_budget (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		application_id TEXT NOT NULL,
+		slo TEXT NOT NULL,
+		ts INTEGER NOT NULL,
+		bad REAL NOT NULL,
+		total REAL NOT NULL,
+		PRIMARY KEY (project_id, application_id, slo, ts)
+	)`)
+}
+
+type errorBudgetKey struct {
+	appId string
+	slo   string
+}
+
+// SaveErrorBudgetRecords saves the records starting from the latest stored hour (which might have been incomplete),
+// the older records are left untouched.
+func (db *DB) SaveErrorBudgetRecords(projectId ProjectId, records []model.ErrorBudgetRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	rows, err := db.db.Query("SELECT application_id, slo, MAX(ts) FROM error_budget WHERE project_id = $1 GROUP BY application_id, slo", projectId)
+	if err != nil {
+		return err
+	}
+	latest := map[errorBudgetKey]timeseries.Time{}
+	for rows.Next() {
+		var k errorBudgetKey
+		var t timeseries.Time
+		if err := rows.Scan(&k.appId, &k.slo, &t); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		latest[k] = t
+	}
+	_ = rows.Close()
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	for _, r := range records {
+		appId := r.ApplicationId.String()
+		if r.Time.Before(latest[errorBudgetKey{appId: appId, slo: r.SLO}]) {
+			continue
+		}
+		_, err = tx.Exec(
+			"DELETE FROM error_budget WHERE project_id = $1 AND application_id = $2 AND slo = $3 AND ts = $4",
+			projectId, appId, r.SLO, r.Time)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			"INSERT INTO error_budget (project_id, application_id, slo, ts, bad, total) VALUES ($1, $2, $3, $4, $5, $6)",
+			projectId, appId, r.SLO, r.Time, r.Bad, r.Total)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (db *DB) DeleteErrorBudgetRecords(projectId ProjectId, before timeseries.Time) error {
+	_, err := db.db.Exec("DELETE FROM error_budget WHERE project_id = $1 AND ts < $2", projectId, before)
+	return err
+}
+
+func (db *DB) GetErrorBudgetRecords(projectId ProjectId, from timeseries.Time) (map[model.ApplicationId][]model.ErrorBudgetRecord, error) {
+	rows, err := db.db.Query("SELECT application_id, slo, ts, bad, total FROM error_budget WHERE project_id = $1 AND ts >= $2", projectId, from)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	res := map[model.ApplicationId][]model.ErrorBudgetRecord{}
+	var appIdStr string
+	for rows.Next() {
+		var r model.ErrorBudgetRecord
+		if err := rows.Scan(&appIdStr, &r.SLO, &r.Time, &r.Bad, &r.Total); err != nil {
+			return nil, err
+		}
+		appId, err := model.NewApplicationIdFromString(appIdStr)
+		if err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		r.ApplicationId = appId
+		res[appId] = append(res[appId], r)
+	}
+	return res, nil
+}
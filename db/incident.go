@@ -12,7 +12,7 @@ import (
 type Incident model.ApplicationIncident
 
 func (i *Incident) Migrate(m *Migrator) error {
-	return m.Exec(`
+	if err := m.Exec(`
 	CREATE TABLE IF NOT EXISTS incident (
 		project_id TEXT NOT NULL REFERENCES project(id),
 		application_id TEXT NOT NULL,
@@ -23,7 +23,16 @@ func (i *Incident) Migrate(m *Migrator) error {
 		PRIMARY KEY (project_id, application_id, opened_at)
 	);
 	CREATE UNIQUE INDEX IF NOT EXISTS incident_key ON incident (project_id, key);
-`)
+`); err != nil {
+		return err
+	}
+	if err := m.AddColumnIfNotExists("incident", "acknowledged_at", "INT NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := m.AddColumnIfNotExists("incident", "acknowledged_by", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return m.AddColumnIfNotExists("incident", "escalation_step", "INT NOT NULL DEFAULT 0")
 }
 
 type IncidentNotification struct {
@@ -36,10 +45,17 @@ type IncidentNotification struct {
 	SentAt        timeseries.Time
 	ExternalKey   string
 	Details       *IncidentNotificationDetails
+	Attempts      int
+	NextAttemptAt timeseries.Time
+	DroppedAt     timeseries.Time
+
+	// Title and Text are rendered from the notification template of the destination, if any, right before sending (not persisted).
+	Title string
+	Text  string
 }
 
 func (n *IncidentNotification) Migrate(m *Migrator) error {
-	return m.Exec(`
+	if err := m.Exec(`
 	CREATE TABLE IF NOT EXISTS incident_notification (
 		project_id TEXT NOT NULL REFERENCES project(id),
 		application_id TEXT NOT NULL,
@@ -51,30 +67,73 @@ func (n *IncidentNotification) Migrate(m *Migrator) error {
 		external_key TEXT NOT NULL DEFAULT '',
 		details TEXT
 	);
-`)
+`); err != nil {
+		return err
+	}
+	if err := m.AddColumnIfNotExists("incident_notification", "attempts", "INT NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := m.AddColumnIfNotExists("incident_notification", "next_attempt_at", "INT NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	return m.AddColumnIfNotExists("incident_notification", "dropped_at", "INT NOT NULL DEFAULT 0")
 }
 
 type IncidentNotificationDetails struct {
-	Reports []IncidentNotificationDetailsReport `json:"reports"`
+	Reports    []IncidentNotificationDetailsReport    `json:"reports"`
+	Owner      *model.ApplicationOwner                `json:"owner,omitempty"`
+	Deployment *IncidentNotificationDetailsDeployment `json:"deployment,omitempty"`
+	Chart      *IncidentNotificationDetailsChart      `json:"chart,omitempty"`
+	OnCall     string                                 `json:"on_call,omitempty"`
+	Escalation bool                                   `json:"escalation,omitempty"`
+	Routed     bool                                   `json:"routed,omitempty"` // the destination is targeted by a route or an escalation policy, but incident notifications are disabled for it
+}
+
+// IncidentNotificationDetailsChart is a snapshot of the failing SLI to be rendered as an image by messengers.
+type IncidentNotificationDetailsChart struct {
+	Title     string             `json:"title"`
+	Values    []timeseries.Value `json:"values"`
+	Threshold float32            `json:"threshold"`
+}
+
+// IncidentNotificationDetailsDeployment is the deployment of the application that started shortly before the incident.
+type IncidentNotificationDetailsDeployment struct {
+	Id        string          `json:"id"`
+	Version   string          `json:"version"`
+	StartedAt timeseries.Time `json:"started_at"`
 }
 
 type IncidentNotificationDetailsReport struct {
-	Name    model.AuditReportName `json:"name"`
-	Check   string                `json:"check"`
-	Message string                `json:"message"`
+	Name       model.AuditReportName  `json:"name"`
+	CheckId    model.CheckId          `json:"check_id,omitempty"`
+	Check      string                 `json:"check"`
+	Status     model.Status           `json:"status"`
+	Message    string                 `json:"message"`
+	Annotation *model.CheckAnnotation `json:"annotation,omitempty"`
+	Items      []string               `json:"items,omitempty"`
 }
 
 func (db *DB) GetIncidentByKey(projectId ProjectId, key string) (*model.ApplicationIncident, error) {
 	i := &model.ApplicationIncident{Key: key}
 	err := db.db.QueryRow(
-		"SELECT opened_at, resolved_at, severity FROM incident WHERE project_id = $1 AND key = $2 LIMIT 1",
-		projectId, key).Scan(&i.OpenedAt, &i.ResolvedAt, &i.Severity)
+		"SELECT opened_at, resolved_at, severity, acknowledged_at, acknowledged_by, escalation_step FROM incident WHERE project_id = $1 AND key = $2 LIMIT 1",
+		projectId, key).Scan(&i.OpenedAt, &i.ResolvedAt, &i.Severity, &i.AcknowledgedAt, &i.AcknowledgedBy, &i.EscalationStep)
 	return i, err
 }
 
+// GetIncidentApplicationId returns the application the incident belongs to.
+func (db *DB) GetIncidentApplicationId(projectId ProjectId, key string) (model.ApplicationId, error) {
+	var appId model.ApplicationId
+	err := db.db.QueryRow("SELECT application_id FROM incident WHERE project_id = $1 AND key = $2 LIMIT 1", projectId, key).Scan(&appId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return appId, ErrNotFound
+	}
+	return appId, err
+}
+
 func (db *DB) GetApplicationIncidents(projectId ProjectId, from, to timeseries.Time) (map[model.ApplicationId][]*model.ApplicationIncident, error) {
 	rows, err := db.db.Query(
-		"SELECT application_id, key, opened_at, resolved_at, severity FROM incident WHERE project_id = $1 AND opened_at <= $2 AND (resolved_at = 0 OR resolved_at >= $3)",
+		"SELECT application_id, key, opened_at, resolved_at, severity, acknowledged_at, acknowledged_by, escalation_step FROM incident WHERE project_id = $1 AND opened_at <= $2 AND (resolved_at = 0 OR resolved_at >= $3)",
 		projectId, to, from)
 	if err != nil {
 		return nil, err
@@ -86,7 +145,7 @@ func (db *DB) GetApplicationIncidents(projectId ProjectId, from, to timeseries.T
 	var appId model.ApplicationId
 	for rows.Next() {
 		var i model.ApplicationIncident
-		if err := rows.Scan(&appId, &i.Key, &i.OpenedAt, &i.ResolvedAt, &i.Severity); err != nil {
+		if err := rows.Scan(&appId, &i.Key, &i.OpenedAt, &i.ResolvedAt, &i.Severity, &i.AcknowledgedAt, &i.AcknowledgedBy, &i.EscalationStep); err != nil {
 			return nil, err
 		}
 		res[appId] = append(res[appId], &i)
@@ -98,8 +157,8 @@ func (db *DB) CreateOrUpdateIncident(projectId ProjectId, appId model.Applicatio
 	appIdStr := appId.String()
 	var last model.ApplicationIncident
 	err := db.db.QueryRow(
-		"SELECT key, opened_at, resolved_at, severity FROM incident WHERE project_id = $1 AND application_id = $2 ORDER BY opened_at DESC LIMIT 1",
-		projectId, appIdStr).Scan(&last.Key, &last.OpenedAt, &last.ResolvedAt, &last.Severity)
+		"SELECT key, opened_at, resolved_at, severity, acknowledged_at, acknowledged_by, escalation_step FROM incident WHERE project_id = $1 AND application_id = $2 ORDER BY opened_at DESC LIMIT 1",
+		projectId, appIdStr).Scan(&last.Key, &last.OpenedAt, &last.ResolvedAt, &last.Severity, &last.AcknowledgedAt, &last.AcknowledgedBy, &last.EscalationStep)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, err
 	}
@@ -123,10 +182,11 @@ func (db *DB) CreateOrUpdateIncident(projectId ProjectId, appId model.Applicatio
 		return &last, err
 	}
 
-	if severity != last.Severity { // update severity
+	if severity != last.Severity { // update severity, the escalation policy of the new severity starts over
 		last.Severity = severity
+		last.EscalationStep = 0
 		_, err := db.db.Exec(
-			"UPDATE incident SET severity = $1 WHERE project_id = $2 AND application_id = $3 AND opened_at = $4",
+			"UPDATE incident SET severity = $1, escalation_step = 0 WHERE project_id = $2 AND application_id = $3 AND opened_at = $4",
 			last.Severity, projectId, appIdStr, last.OpenedAt)
 		return &last, err
 	}
@@ -134,6 +194,27 @@ func (db *DB) CreateOrUpdateIncident(projectId ProjectId, appId model.Applicatio
 	return nil, nil
 }
 
+func (db *DB) AcknowledgeIncident(projectId ProjectId, key string, by string, now timeseries.Time) error {
+	res, err := db.db.Exec(
+		"UPDATE incident SET acknowledged_at = $1, acknowledged_by = $2 WHERE project_id = $3 AND key = $4 AND acknowledged_at = 0",
+		now, by, projectId, key)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		if _, err = db.GetIncidentByKey(projectId, key); errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (db *DB) SetIncidentEscalationStep(projectId ProjectId, key string, step int) error {
+	_, err := db.db.Exec("UPDATE incident SET escalation_step = $1 WHERE project_id = $2 AND key = $3", step, projectId, key)
+	return err
+}
+
 func (db *DB) PutIncidentNotification(n IncidentNotification) {
 	details, err := marshal(n.Details)
 	if err != nil {
@@ -157,11 +238,30 @@ func (db *DB) UpdateIncidentNotification(n IncidentNotification) error {
 	return err
 }
 
+// UpdateIncidentNotificationAttempts saves the number of failed attempts to send the notification and the time of the next one.
+// The external key is saved as well, since a partially failed attempt may have set it (e.g., the Twilio recipients already notified).
+func (db *DB) UpdateIncidentNotificationAttempts(n IncidentNotification) error {
+	_, err := db.db.Exec(
+		"UPDATE incident_notification SET attempts = $1, next_attempt_at = $2, external_key = $3 WHERE project_id = $4 AND application_id = $5 AND incident_key = $6 AND timestamp = $7 AND destination = $8",
+		n.Attempts, n.NextAttemptAt, n.ExternalKey, n.ProjectId, n.ApplicationId, n.IncidentKey, n.Timestamp, n.Destination,
+	)
+	return err
+}
+
+// DropIncidentNotification marks the notification as dropped (e.g., by the rate limiter), so it's neither sent nor retried.
+func (db *DB) DropIncidentNotification(n IncidentNotification) error {
+	_, err := db.db.Exec(
+		"UPDATE incident_notification SET dropped_at = $1 WHERE project_id = $2 AND application_id = $3 AND incident_key = $4 AND timestamp = $5 AND destination = $6",
+		n.DroppedAt, n.ProjectId, n.ApplicationId, n.IncidentKey, n.Timestamp, n.Destination,
+	)
+	return err
+}
+
 func (db *DB) GetNotSentIncidentNotifications(from timeseries.Time) ([]IncidentNotification, error) {
 	rows, err := db.db.Query(`
-		SELECT project_id, application_id, incident_key, status, destination, timestamp, external_key, details 
+		SELECT project_id, application_id, incident_key, status, destination, timestamp, external_key, details, attempts, next_attempt_at 
 		FROM incident_notification 
-		WHERE timestamp >= $1 AND sent_at = 0 
+		WHERE timestamp >= $1 AND sent_at = 0 AND dropped_at = 0 
 		ORDER BY project_id, application_id, incident_key, timestamp
 	`, from)
 	if err != nil {
@@ -174,7 +274,7 @@ func (db *DB) GetNotSentIncidentNotifications(from timeseries.Time) ([]IncidentN
 	var details sql.NullString
 	for rows.Next() {
 		var n IncidentNotification
-		if err := rows.Scan(&n.ProjectId, &n.ApplicationId, &n.IncidentKey, &n.Status, &n.Destination, &n.Timestamp, &n.ExternalKey, &details); err != nil {
+		if err := rows.Scan(&n.ProjectId, &n.ApplicationId, &n.IncidentKey, &n.Status, &n.Destination, &n.Timestamp, &n.ExternalKey, &details, &n.Attempts, &n.NextAttemptAt); err != nil {
 			return nil, err
 		}
 		if details.String != "" {
@@ -218,6 +318,28 @@ func (db *DB) GetPreviousIncidentNotifications(n IncidentNotification) ([]Incide
 	return res, nil
 }
 
+// GetIncidentNotificationDestinations returns the integrations the incident has been sent to, including the escalations.
+func (db *DB) GetIncidentNotificationDestinations(projectId ProjectId, appId model.ApplicationId, incidentKey string) ([]IntegrationType, error) {
+	rows, err := db.db.Query(
+		"SELECT DISTINCT destination FROM incident_notification WHERE project_id = $1 AND application_id = $2 AND incident_key = $3",
+		projectId, appId, incidentKey)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var res []IntegrationType
+	var destination IntegrationType
+	for rows.Next() {
+		if err := rows.Scan(&destination); err != nil {
+			return nil, err
+		}
+		res = append(res, destination)
+	}
+	return res, nil
+}
+
 func (db *DB) GetSentIncidentNotificationsStat(from timeseries.Time) map[IntegrationType]int {
 	rows, err := db.db.Query("SELECT destination, count(*) FROM incident_notification WHERE timestamp >= $1 AND sent_at > 0 GROUP BY destination", from)
 	if err != nil {
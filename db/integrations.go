@@ -2,6 +2,12 @@ package db
 
 import (
 	"fmt"
+	"github.com/coroot/coroot/aws"
+	"github.com/coroot/coroot/clickhouse"
+	"github.com/coroot/coroot/gcp"
+	"github.com/coroot/coroot/logs"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/prom"
 	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
 )
@@ -9,13 +15,26 @@ import (
 type IntegrationType string
 
 const (
-	IntegrationTypePrometheus IntegrationType = "prometheus"
-	IntegrationTypePyroscope  IntegrationType = "pyroscope"
-	IntegrationTypeClickhouse IntegrationType = "clickhouse"
-	IntegrationTypeSlack      IntegrationType = "slack"
-	IntegrationTypePagerduty  IntegrationType = "pagerduty"
-	IntegrationTypeTeams      IntegrationType = "teams"
-	IntegrationTypeOpsgenie   IntegrationType = "opsgenie"
+	IntegrationTypePrometheus    IntegrationType = "prometheus"
+	IntegrationTypePyroscope     IntegrationType = "pyroscope"
+	IntegrationTypeClickhouse    IntegrationType = "clickhouse"
+	IntegrationTypeLoki          IntegrationType = "loki"
+	IntegrationTypeElasticsearch IntegrationType = "elasticsearch"
+	IntegrationTypeSlack         IntegrationType = "slack"
+	IntegrationTypePagerduty     IntegrationType = "pagerduty"
+	IntegrationTypeTeams         IntegrationType = "teams"
+	IntegrationTypeOpsgenie      IntegrationType = "opsgenie"
+	IntegrationTypeTelegram      IntegrationType = "telegram"
+	IntegrationTypeDiscord       IntegrationType = "discord"
+	IntegrationTypeMattermost    IntegrationType = "mattermost"
+	IntegrationTypeEmail         IntegrationType = "email"
+	IntegrationTypeArgoCD        IntegrationType = "argocd"
+	IntegrationTypeAWS           IntegrationType = "aws"
+	IntegrationTypeGCP           IntegrationType = "gcp"
+	IntegrationTypeAlertmanager  IntegrationType = "alertmanager"
+	IntegrationTypeJira          IntegrationType = "jira"
+	IntegrationTypeServiceNow    IntegrationType = "servicenow"
+	IntegrationTypeTwilio        IntegrationType = "twilio"
 )
 
 type Integrations struct {
@@ -25,9 +44,28 @@ type Integrations struct {
 	Pagerduty *IntegrationPagerduty `json:"pagerduty,omitempty"`
 	Teams     *IntegrationTeams     `json:"teams,omitempty"`
 	Opsgenie  *IntegrationOpsgenie  `json:"opsgenie,omitempty"`
+	Telegram  *IntegrationTelegram  `json:"telegram,omitempty"`
+	Discord   *IntegrationDiscord   `json:"discord,omitempty"`
 
-	Pyroscope  *IntegrationPyroscope  `json:"pyroscope,omitempty"`
-	Clickhouse *IntegrationClickhouse `json:"clickhouse,omitempty"`
+	Mattermost *IntegrationMattermost `json:"mattermost,omitempty"`
+	Email      *IntegrationEmail      `json:"email,omitempty"`
+
+	Jira       *IntegrationJira       `json:"jira,omitempty"`
+	ServiceNow *IntegrationServiceNow `json:"servicenow,omitempty"`
+
+	Twilio *IntegrationTwilio `json:"twilio,omitempty"`
+
+	Pyroscope     *IntegrationPyroscope     `json:"pyroscope,omitempty"`
+	Clickhouse    *IntegrationClickhouse    `json:"clickhouse,omitempty"`
+	Loki          *IntegrationLoki          `json:"loki,omitempty"`
+	Elasticsearch *IntegrationElasticsearch `json:"elasticsearch,omitempty"`
+
+	ArgoCD *IntegrationArgoCD `json:"argocd,omitempty"`
+
+	AWS *IntegrationAWS `json:"aws,omitempty"`
+	GCP *IntegrationGCP `json:"gcp,omitempty"`
+
+	Alertmanager *IntegrationAlertmanager `json:"alertmanager,omitempty"`
 }
 
 type IntegrationInfo struct {
@@ -51,6 +89,17 @@ func (integrations Integrations) GetInfo() []IntegrationInfo {
 	}
 	res = append(res, i)
 
+	i = IntegrationInfo{Type: IntegrationTypeMattermost, Title: "Mattermost"}
+	if cfg := integrations.Mattermost; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		if cfg.Channel != "" {
+			i.Details = fmt.Sprintf("channel: %s", cfg.Channel)
+		}
+	}
+	res = append(res, i)
+
 	i = IntegrationInfo{Type: IntegrationTypeTeams, Title: "MS Teams"}
 	if cfg := integrations.Teams; cfg != nil {
 		i.Configured = true
@@ -59,6 +108,32 @@ func (integrations Integrations) GetInfo() []IntegrationInfo {
 	}
 	res = append(res, i)
 
+	i = IntegrationInfo{Type: IntegrationTypeTelegram, Title: "Telegram"}
+	if cfg := integrations.Telegram; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		i.Details = fmt.Sprintf("chat: %s", cfg.ChatId)
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeDiscord, Title: "Discord"}
+	if cfg := integrations.Discord; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeEmail, Title: "Email"}
+	if cfg := integrations.Email; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		i.Details = fmt.Sprintf("smtp: %s:%d", cfg.Host, cfg.Port)
+	}
+	res = append(res, i)
+
 	i = IntegrationInfo{Type: IntegrationTypePagerduty, Title: "Pagerduty"}
 	if cfg := integrations.Pagerduty; cfg != nil {
 		i.Configured = true
@@ -78,9 +153,57 @@ func (integrations Integrations) GetInfo() []IntegrationInfo {
 	}
 	res = append(res, i)
 
+	i = IntegrationInfo{Type: IntegrationTypeTwilio, Title: "Twilio (SMS/voice)"}
+	if cfg := integrations.Twilio; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Details = "critical incidents only"
+		if cfg.Fallback {
+			i.Details += ", if chat notifications fail"
+		}
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeJira, Title: "Jira"}
+	if cfg := integrations.Jira; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Details = fmt.Sprintf("project: %s, critical for %dm", cfg.ProjectKey, cfg.Threshold)
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeServiceNow, Title: "ServiceNow"}
+	if cfg := integrations.ServiceNow; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Details = fmt.Sprintf("critical for %dm", cfg.Threshold)
+	}
+	res = append(res, i)
+
 	return res
 }
 
+// TicketThreshold returns how long an incident must stay critical before a ticket is opened in the integration,
+// or 0 if the integration doesn't open tickets.
+func (integrations Integrations) TicketThreshold(t IntegrationType) timeseries.Duration {
+	switch t {
+	case IntegrationTypeJira:
+		if cfg := integrations.Jira; cfg != nil {
+			return timeseries.Duration(cfg.Threshold) * timeseries.Minute
+		}
+	case IntegrationTypeServiceNow:
+		if cfg := integrations.ServiceNow; cfg != nil {
+			return timeseries.Duration(cfg.Threshold) * timeseries.Minute
+		}
+	}
+	return 0
+}
+
+// RawLogsAvailable reports whether the log lines can be retrieved from Loki, Elasticsearch or the ClickHouse logs table.
+func (integrations Integrations) RawLogsAvailable() bool {
+	return integrations.Loki != nil || integrations.Elasticsearch != nil || (integrations.Clickhouse != nil && integrations.Clickhouse.Ingest)
+}
+
 type IntegrationsPrometheus struct {
 	Url             string              `json:"url"`
 	RefreshInterval timeseries.Duration `json:"refresh_interval"`
@@ -88,6 +211,30 @@ type IntegrationsPrometheus struct {
 	BasicAuth       *utils.BasicAuth    `json:"basic_auth"`
 	ExtraSelector   string              `json:"extra_selector"`
 	CustomHeaders   []utils.Header      `json:"custom_headers"`
+	Backend         string              `json:"backend"` // auto-detected if empty, see prom.Backend*
+	TenantId        string              `json:"tenant_id"`
+	ReplicaLabels   []string            `json:"replica_labels"`
+	SplitInterval   timeseries.Duration `json:"split_interval"`
+	Embedded        bool                `json:"embedded"` // the metrics are pushed by the agents to the embedded TSDB
+}
+
+func (p IntegrationsPrometheus) Configured() bool {
+	return p.Url != "" || p.Embedded
+}
+
+func (p IntegrationsPrometheus) ClientConfig() prom.ClientConfig {
+	return prom.ClientConfig{
+		Url:            p.Url,
+		BasicAuth:      p.BasicAuth,
+		TlsSkipVerify:  p.TlsSkipVerify,
+		ExtraSelector:  p.ExtraSelector,
+		CustomHeaders:  p.CustomHeaders,
+		Backend:        p.Backend,
+		TenantId:       p.TenantId,
+		ReplicaLabels:  p.ReplicaLabels,
+		SplitInterval:  p.SplitInterval,
+		ScrapeInterval: p.RefreshInterval,
+	}
 }
 
 type IntegrationPyroscope struct {
@@ -105,22 +252,170 @@ type IntegrationClickhouse struct {
 	TlsEnable     bool            `json:"tls_enable"`
 	TlsSkipVerify bool            `json:"tls_skip_verify"`
 	TracesTable   string          `json:"traces_table"`
+
+	// Ingest enables the OTLP receivers that store spans and log records in ClickHouse (the tables are created if they don't exist).
+	// The per-query statistics pushed via remote-write are stored there as well.
+	Ingest        bool                `json:"ingest"`
+	IngestTTL     timeseries.Duration `json:"ingest_ttl"` // the retention of the spans
+	LogsTTL       timeseries.Duration `json:"logs_ttl"`
+	QueryStatsTTL timeseries.Duration `json:"query_stats_ttl"`
 }
 
+func (cfg IntegrationClickhouse) ClientConfig() clickhouse.ClientConfig {
+	return clickhouse.ClientConfig{
+		Protocol:      cfg.Protocol,
+		Addr:          cfg.Addr,
+		TlsEnable:     cfg.TlsEnable,
+		TlsSkipVerify: cfg.TlsSkipVerify,
+		Auth:          cfg.Auth,
+		Database:      cfg.Database,
+	}
+}
+
+func (cfg IntegrationClickhouse) Retention() clickhouse.Retention {
+	return clickhouse.Retention{Logs: cfg.LogsTTL.ToStandard(), QueryStats: cfg.QueryStatsTTL.ToStandard()}
+}
+
+// IntegrationLoki is used to drill down from log patterns and error charts to the raw log lines.
+// The streams of an application are selected by the namespace and the instance (pod) labels.
+type IntegrationLoki struct {
+	Url            string           `json:"url"`
+	TlsSkipVerify  bool             `json:"tls_skip_verify"`
+	BasicAuth      *utils.BasicAuth `json:"basic_auth,omitempty"`
+	TenantId       string           `json:"tenant_id"`
+	NamespaceLabel string           `json:"namespace_label"`
+	InstanceLabel  string           `json:"instance_label"`
+}
+
+// IntegrationElasticsearch is an alternative to IntegrationLoki for the logs stored in Elasticsearch or OpenSearch.
+// The documents of an application are selected by the namespace and the instance (pod) fields.
+type IntegrationElasticsearch struct {
+	Url            string           `json:"url"`
+	TlsSkipVerify  bool             `json:"tls_skip_verify"`
+	BasicAuth      *utils.BasicAuth `json:"basic_auth,omitempty"`
+	ApiKey         string           `json:"api_key"`
+	Index          string           `json:"index"` // an index pattern, e.g., logs-*
+	TimestampField string           `json:"timestamp_field"`
+	MessageField   string           `json:"message_field"`
+	NamespaceField string           `json:"namespace_field"`
+	InstanceField  string           `json:"instance_field"`
+}
+
+func (cfg IntegrationElasticsearch) Fields() logs.ElasticsearchFields {
+	return logs.ElasticsearchFields{
+		Timestamp: cfg.TimestampField,
+		Message:   cfg.MessageField,
+		Namespace: cfg.NamespaceField,
+		Instance:  cfg.InstanceField,
+	}
+}
+
+// IntegrationArgoCD is used to enrich deployments with the revisions and the authors of ArgoCD sync operations.
+type IntegrationArgoCD struct {
+	Url           string `json:"url"`
+	Token         string `json:"token"`
+	TlsSkipVerify bool   `json:"tls_skip_verify"`
+}
+
+// IntegrationAWS is used to discover RDS and Aurora instances, as well as the target groups, SQS queues and Lambda functions
+// tagged with the applications they belong to, and collect their metrics to the embedded TSDB.
+type IntegrationAWS struct {
+	Region              string   `json:"region"`
+	AccessKeyId         string   `json:"access_key_id"`
+	SecretAccessKey     string   `json:"secret_access_key"`
+	RoleArn             string   `json:"role_arn"`
+	RdsInstances        []string `json:"rds_instances"` // glob patterns of the instance identifiers, all instances if empty
+	PerformanceInsights bool     `json:"performance_insights"`
+	ApplicationTag      string   `json:"application_tag"` // the tag mapping resources to applications, the resources aren't polled if empty
+}
+
+func (cfg IntegrationAWS) ClientConfig() aws.Config {
+	return aws.Config{
+		Region:          cfg.Region,
+		AccessKeyId:     cfg.AccessKeyId,
+		SecretAccessKey: cfg.SecretAccessKey,
+		RoleArn:         cfg.RoleArn,
+	}
+}
+
+// IntegrationGCP is used to discover Cloud SQL and Memorystore instances and collect their metrics to the embedded TSDB.
+type IntegrationGCP struct {
+	ProjectId            string   `json:"project_id"`
+	ServiceAccountKey    string   `json:"service_account_key"`
+	CloudSqlInstances    []string `json:"cloudsql_instances"`    // glob patterns of the instance names, all instances if empty
+	MemorystoreInstances []string `json:"memorystore_instances"` // glob patterns of the instance names, all instances if empty
+}
+
+func (cfg IntegrationGCP) ClientConfig() gcp.Config {
+	return gcp.Config{
+		ProjectId:         cfg.ProjectId,
+		ServiceAccountKey: cfg.ServiceAccountKey,
+	}
+}
+
+// IntegrationAlertmanager is an external Alertmanager the failing checks are forwarded to.
+type IntegrationAlertmanager struct {
+	Url           string           `json:"url"`
+	TlsSkipVerify bool             `json:"tls_skip_verify"`
+	BasicAuth     *utils.BasicAuth `json:"basic_auth,omitempty"`
+}
+
+// IntegrationSlack posts notifications on behalf of a Slack app. If SigningSecret is set, incident messages have buttons
+// to acknowledge the incident and silence the failing checks, the app's interactivity requests are verified with the secret.
 type IntegrationSlack struct {
 	Token          string `json:"token"`
 	DefaultChannel string `json:"default_channel"`
+	SigningSecret  string `json:"signing_secret"`
 	Enabled        bool   `json:"enabled"` // deprecated: use Incidents and Deployments
 	Incidents      bool   `json:"incidents"`
 	Deployments    bool   `json:"deployments"`
 }
 
+// IntegrationMattermost uses an incoming webhook. If Channel is empty, messages are posted to the webhook's default channel.
+type IntegrationMattermost struct {
+	WebhookUrl  string `json:"webhook_url"`
+	Channel     string `json:"channel"`
+	Incidents   bool   `json:"incidents"`
+	Deployments bool   `json:"deployments"`
+}
+
 type IntegrationTeams struct {
 	WebhookUrl  string `json:"webhook_url"`
 	Incidents   bool   `json:"incidents"`
 	Deployments bool   `json:"deployments"`
 }
 
+type IntegrationTelegram struct {
+	BotToken    string `json:"bot_token"`
+	ChatId      string `json:"chat_id"`
+	Incidents   bool   `json:"incidents"`
+	Deployments bool   `json:"deployments"`
+}
+
+type IntegrationDiscord struct {
+	WebhookUrl  string `json:"webhook_url"`
+	Incidents   bool   `json:"incidents"`
+	Deployments bool   `json:"deployments"`
+}
+
+// IntegrationEmail sends emails through an SMTP server. If TLS is false, STARTTLS is used if the server supports it.
+// To is a comma-separated list of the default recipients, incidents are sent to the application owner's addresses if they're set.
+type IntegrationEmail struct {
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	TLS         bool   `json:"tls"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Incidents   bool   `json:"incidents"`
+	Deployments bool   `json:"deployments"`
+}
+
+func (cfg IntegrationEmail) Recipients() []string {
+	return model.SplitEmails(cfg.To)
+}
+
 type IntegrationPagerduty struct {
 	IntegrationKey string `json:"integration_key"`
 	Incidents      bool   `json:"incidents"`
@@ -132,6 +427,47 @@ type IntegrationOpsgenie struct {
 	Incidents  bool   `json:"incidents"`
 }
 
+// IntegrationTwilio sends SMS (and places voice calls if Voice is set) of the critical incidents.
+// To is a list of the default phone numbers (see model.ParsePhones), incidents are sent to the application owner's numbers if they're set.
+// Each number receives no more than RateLimit messages per hour. If Fallback is set, an incident is sent only
+// if its notification to a chat-based integration (Slack, Teams, Telegram, Discord, or Mattermost) fails.
+type IntegrationTwilio struct {
+	AccountSid string `json:"account_sid"`
+	AuthToken  string `json:"auth_token"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Voice      bool   `json:"voice"`
+	RateLimit  int    `json:"rate_limit"`
+	Fallback   bool   `json:"fallback"`
+	Incidents  bool   `json:"incidents"`
+}
+
+func (cfg IntegrationTwilio) Recipients() []string {
+	return model.ParsePhones(cfg.To)
+}
+
+// IntegrationJira opens an issue for the incidents that stay critical for longer than Threshold minutes.
+// Once the incident is resolved, the issue is commented on and transitioned to the first available "done" status.
+type IntegrationJira struct {
+	Url        string `json:"url"`
+	Email      string `json:"email"`
+	ApiToken   string `json:"api_token"`
+	ProjectKey string `json:"project_key"`
+	IssueType  string `json:"issue_type"`
+	Threshold  int    `json:"threshold"`
+	Incidents  bool   `json:"incidents"`
+}
+
+// IntegrationServiceNow opens an incident for the incidents that stay critical for longer than Threshold minutes
+// and resolves it once the Coroot incident is resolved.
+type IntegrationServiceNow struct {
+	Url       string `json:"url"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	Threshold int    `json:"threshold"`
+	Incidents bool   `json:"incidents"`
+}
+
 func (db *DB) SaveIntegrationsBaseUrl(id ProjectId, baseUrl string) error {
 	p, err := db.GetProject(id)
 	if err != nil {
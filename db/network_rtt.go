@@ -0,0 +1,87 @@
+package db
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+// NetworkRtt stores the history of the RTT between applications and their upstreams.
+type NetworkRtt struct{}
+
+func (nr *NetworkRtt) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS network_rtt (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		application_id TEXT NOT NULL,
+		upstream_id TEXT NOT NULL,
+		ts INTEGER NOT NULL,
+		rtt REAL NOT NULL,
+		PRIMARY KEY (project_id, application_id, upstream_id, ts)
+	);
+	CREATE INDEX IF NOT EXISTS network_rtt_ts ON network_rtt (project_id, ts);
+`)
+}
+
+func (db *DB) SaveNetworkRtts(projectId ProjectId, now timeseries.Time, rtts []model.NetworkRtt) error {
+	if len(rtts) == 0 {
+		return nil
+	}
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	for _, r := range rtts {
+		_, err = tx.Exec(
+			"INSERT INTO network_rtt (project_id, application_id, upstream_id, ts, rtt) VALUES ($1, $2, $3, $4, $5)",
+			projectId, r.ApplicationId.String(), r.UpstreamId.String(), now, r.Rtt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (db *DB) DeleteNetworkRtts(projectId ProjectId, before timeseries.Time) error {
+	_, err := db.db.Exec("DELETE FROM network_rtt WHERE project_id = $1 AND ts < $2", projectId, before)
+	return err
+}
+
+// GetNetworkRttBaselines returns the average RTT to each upstream over the baseline window excluding the most recent period.
+func (db *DB) GetNetworkRttBaselines(projectId ProjectId, now timeseries.Time) (map[model.ApplicationId]map[model.ApplicationId]float32, error) {
+	rows, err := db.db.Query(
+		"SELECT application_id, upstream_id, AVG(rtt) FROM network_rtt WHERE project_id = $1 AND ts >= $2 AND ts < $3 GROUP BY application_id, upstream_id",
+		projectId, now.Add(-model.NetworkRttBaselineWindow), now.Add(-model.NetworkRttBaselineRecent))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	res := map[model.ApplicationId]map[model.ApplicationId]float32{}
+	var appIdStr, upstreamIdStr string
+	var rtt float32
+	for rows.Next() {
+		if err := rows.Scan(&appIdStr, &upstreamIdStr, &rtt); err != nil {
+			return nil, err
+		}
+		appId, err := model.NewApplicationIdFromString(appIdStr)
+		if err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		upstreamId, err := model.NewApplicationIdFromString(upstreamIdStr)
+		if err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		if res[appId] == nil {
+			res[appId] = map[model.ApplicationId]float32{}
+		}
+		res[appId][upstreamId] = rtt
+	}
+	return res, nil
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+const NotificationDeliveryRetention = 7 * timeseries.Day
+
+type NotificationKind string
+
+const (
+	NotificationKindIncident   NotificationKind = "incident"
+	NotificationKindDeployment NotificationKind = "deployment"
+	NotificationKindDigest     NotificationKind = "digest"
+	NotificationKindWebhook    NotificationKind = "webhook"
+)
+
+// NotificationDelivery is an attempt to send a notification. Destination is the integration type or the name of the webhook,
+// Subject identifies the notification within the application (e.g., the incident key or the deployment version).
+// Error is empty if the notification has been delivered.
+//
+// The log is also the queue of the webhook events: an event is queued as an attempt 0, and a failed attempt
+// to send it keeps the payload along with the time of the next attempt (NextAttemptAt), until the event is claimed
+// for the next attempt. EventId is the same for all attempts to send the event, the ids are ordered by the time
+// the events were queued.
+type NotificationDelivery struct {
+	ProjectId     ProjectId        `json:"-"`
+	Timestamp     timeseries.Time  `json:"timestamp"`
+	Kind          NotificationKind `json:"kind"`
+	Destination   string           `json:"destination"`
+	ApplicationId string           `json:"application_id"`
+	Subject       string           `json:"subject"`
+	Attempt       int              `json:"attempt"`
+	DurationMs    int64            `json:"duration_ms"`
+	Error         string           `json:"error"`
+	NextAttemptAt timeseries.Time  `json:"next_attempt_at,omitempty"`
+
+	WebhookId string `json:"-"`
+	EventId   string `json:"-"`
+	Payload   string `json:"-"`
+}
+
+func (d *NotificationDelivery) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS notification_delivery (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		ts INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		destination TEXT NOT NULL,
+		application_id TEXT NOT NULL DEFAULT '',
+		subject TEXT NOT NULL DEFAULT '',
+		attempt INTEGER NOT NULL DEFAULT 1,
+		duration_ms INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		next_attempt_at INTEGER NOT NULL DEFAULT 0,
+		webhook_id TEXT NOT NULL DEFAULT '',
+		event_id TEXT NOT NULL DEFAULT '',
+		payload TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS notification_delivery_ts ON notification_delivery (project_id, ts);
+	CREATE INDEX IF NOT EXISTS notification_delivery_next_attempt_at ON notification_delivery (project_id, next_attempt_at);
+`)
+}
+
+// LogNotificationDelivery saves the attempt. The errors are only logged since they must not affect the delivery itself.
+func (db *DB) LogNotificationDelivery(d NotificationDelivery) {
+	if len(d.Error) > 1024 {
+		d.Error = d.Error[:1024]
+	}
+	_, err := db.db.Exec(
+		"INSERT INTO notification_delivery (project_id, ts, kind, destination, application_id, subject, attempt, duration_ms, error, next_attempt_at, webhook_id, event_id, payload) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)",
+		d.ProjectId, d.Timestamp, d.Kind, d.Destination, d.ApplicationId, d.Subject, d.Attempt, d.DurationMs, d.Error, d.NextAttemptAt, d.WebhookId, d.EventId, d.Payload)
+	if err != nil {
+		klog.Errorln("failed to log notification delivery:", err)
+	}
+}
+
+// GetPendingWebhookDeliveries returns the queued webhook events and the failed ones waiting for the next attempt,
+// in the order they were queued.
+func (db *DB) GetPendingWebhookDeliveries(projectId ProjectId) ([]NotificationDelivery, error) {
+	rows, err := db.db.Query(
+		"SELECT ts, destination, application_id, subject, attempt, error, next_attempt_at, webhook_id, event_id, payload FROM notification_delivery WHERE project_id = $1 AND kind = $2 AND next_attempt_at > 0 ORDER BY event_id",
+		projectId, NotificationKindWebhook)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var res []NotificationDelivery
+	for rows.Next() {
+		d := NotificationDelivery{ProjectId: projectId, Kind: NotificationKindWebhook}
+		if err := rows.Scan(&d.Timestamp, &d.Destination, &d.ApplicationId, &d.Subject, &d.Attempt, &d.Error, &d.NextAttemptAt, &d.WebhookId, &d.EventId, &d.Payload); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+// ClaimWebhookDelivery takes the pending webhook event for the next attempt: the queued event is removed from the log,
+// and the failed attempt is no longer pending. It returns false if the event has already been claimed.
+func (db *DB) ClaimWebhookDelivery(projectId ProjectId, eventId string) (bool, error) {
+	res, err := db.db.Exec("DELETE FROM notification_delivery WHERE project_id = $1 AND event_id = $2 AND attempt = 0", projectId, eventId)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	res, err = db.db.Exec("UPDATE notification_delivery SET next_attempt_at = 0 WHERE project_id = $1 AND event_id = $2 AND next_attempt_at > 0", projectId, eventId)
+	if err != nil {
+		return false, err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return deleted+updated > 0, nil
+}
+
+// GetNotificationDeliveries returns the latest attempts (not the queued events), failed only if requested.
+func (db *DB) GetNotificationDeliveries(projectId ProjectId, from timeseries.Time, failedOnly bool, limit int) ([]NotificationDelivery, error) {
+	q := "SELECT ts, kind, destination, application_id, subject, attempt, duration_ms, error, next_attempt_at FROM notification_delivery WHERE project_id = $1 AND ts >= $2 AND attempt > 0"
+	if failedOnly {
+		q += " AND error != ''"
+	}
+	q += " ORDER BY ts DESC LIMIT $3"
+	rows, err := db.db.Query(q, projectId, from, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var res []NotificationDelivery
+	for rows.Next() {
+		d := NotificationDelivery{ProjectId: projectId}
+		if err := rows.Scan(&d.Timestamp, &d.Kind, &d.Destination, &d.ApplicationId, &d.Subject, &d.Attempt, &d.DurationMs, &d.Error, &d.NextAttemptAt); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+func (db *DB) DeleteNotificationDeliveries(projectId ProjectId, before timeseries.Time) error {
+	_, err := db.db.Exec("DELETE FROM notification_delivery WHERE project_id = $1 AND ts < $2", projectId, before)
+	return err
+}
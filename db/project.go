@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
 	"strings"
+	"time"
 )
 
 const (
@@ -28,6 +31,30 @@ type Settings struct {
 	ApplicationCategories       map[model.ApplicationCategory][]string                    `json:"application_categories"`
 	ApplicationCategorySettings map[model.ApplicationCategory]ApplicationCategorySettings `json:"application_category_settings"`
 	Integrations                Integrations                                              `json:"integrations"`
+	CustomChecks                []model.CustomCheck                                       `json:"custom_checks"`
+	CheckMutes                  model.CheckMutes                                          `json:"check_mutes"`
+	CompositeChecks             []model.CompositeCheck                                    `json:"composite_checks"`
+	CheckTemplates              []model.CheckTemplate                                     `json:"check_templates"`
+	CheckAnnotations            model.CheckAnnotations                                    `json:"check_annotations"`
+	LogChecks                   []model.LogCheck                                          `json:"log_checks"`
+	Timezone                    string                                                    `json:"timezone"`
+	Language                    string                                                    `json:"language"`
+	MaintenanceWindows          model.MaintenanceWindows                                  `json:"maintenance_windows"`
+	Dashboards                  []model.Dashboard                                         `json:"dashboards"`
+	DigestSchedules             []model.DigestSchedule                                    `json:"digest_schedules"`
+	Annotations                 model.UserAnnotations                                     `json:"annotations"`
+	SavedViews                  []model.SavedView                                         `json:"saved_views"`
+	Webhooks                    []model.Webhook                                           `json:"webhooks"`
+	EscalationPolicies          []model.EscalationPolicy                                  `json:"escalation_policies"`
+	NotificationRoutes          []model.NotificationRoute                                 `json:"notification_routes"`
+	QuietHours                  model.QuietHours                                          `json:"quiet_hours"`
+	OnCall                      model.OnCallSchedule                                      `json:"on_call"`
+	NotificationTemplates       model.NotificationTemplates                               `json:"notification_templates"`
+	NotificationLimits          model.NotificationLimits                                  `json:"notification_limits"`
+	EmbedKey                    string                                                    `json:"embed_key"`
+	IngestionKey                string                                                    `json:"ingestion_key"`
+	StatusPages                 []model.StatusPage                                        `json:"status_pages"`
+	Repositories                model.Repositories                                        `json:"repositories"`
 }
 
 type ApplicationCategorySettings struct {
@@ -50,6 +77,42 @@ func (p *Project) Migrate(m *Migrator) error {
 	return nil
 }
 
+// Location returns the project timezone (UTC if not set).
+func (p *Project) Location() *time.Location {
+	if p.Settings.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Settings.Timezone)
+	if err != nil {
+		klog.Warningln("invalid timezone:", err)
+		return time.UTC
+	}
+	return loc
+}
+
+func (db *DB) SaveProjectLocale(id ProjectId, timezone, language string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	p.Settings.Timezone = timezone
+	p.Settings.Language = language
+	return db.saveProjectSettings(p)
+}
+
+// CheckUnit returns the unit of the check's thresholds.
+func (p *Project) CheckUnit(id model.CheckId) model.CheckUnit {
+	if cfg := model.GetCheckConfig(id); cfg != nil {
+		return cfg.Unit
+	}
+	for _, cc := range p.Settings.CustomChecks {
+		if cc.CheckId() == id {
+			return cc.Unit
+		}
+	}
+	return ""
+}
+
 func (p *Project) applyDefaults() {
 	if p.Prometheus.RefreshInterval == 0 {
 		p.Prometheus.RefreshInterval = DefaultRefreshInterval
@@ -187,6 +250,18 @@ func (db *DB) DeleteProject(id ProjectId) error {
 	if _, err := tx.Exec("DELETE FROM application_settings WHERE project_id = $1", id); err != nil {
 		return err
 	}
+	if _, err := tx.Exec("DELETE FROM check_threshold WHERE project_id = $1", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM check_value WHERE project_id = $1", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM error_budget WHERE project_id = $1", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM network_rtt WHERE project_id = $1", id); err != nil {
+		return err
+	}
 	if _, err := tx.Exec("DELETE FROM project WHERE id = $1", id); err != nil {
 		return err
 	}
@@ -280,3 +355,661 @@ func (db *DB) SaveProjectIntegration(p *Project, typ IntegrationType) error {
 	_, err = db.db.Exec("UPDATE project SET settings = $1 WHERE id = $2", string(settings), p.Id)
 	return err
 }
+
+func (db *DB) SaveCustomCheck(id ProjectId, cc model.CustomCheck) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if cc.Id == "" {
+		cc.Id = utils.NanoId(8)
+		p.Settings.CustomChecks = append(p.Settings.CustomChecks, cc)
+		return cc.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.CustomChecks {
+		if p.Settings.CustomChecks[i].Id == cc.Id {
+			p.Settings.CustomChecks[i] = cc
+			return cc.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteCustomCheck(id ProjectId, checkId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var checks []model.CustomCheck
+	for _, cc := range p.Settings.CustomChecks {
+		if cc.Id != checkId {
+			checks = append(checks, cc)
+		}
+	}
+	p.Settings.CustomChecks = checks
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveDashboard(id ProjectId, d model.Dashboard) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if d.Id == "" {
+		d.Id = utils.NanoId(8)
+		p.Settings.Dashboards = append(p.Settings.Dashboards, d)
+		return d.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.Dashboards {
+		if p.Settings.Dashboards[i].Id == d.Id {
+			p.Settings.Dashboards[i] = d
+			return d.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteDashboard(id ProjectId, dashboardId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var dashboards []model.Dashboard
+	for _, d := range p.Settings.Dashboards {
+		if d.Id != dashboardId {
+			dashboards = append(dashboards, d)
+		}
+	}
+	p.Settings.Dashboards = dashboards
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveDigestSchedule(id ProjectId, ds model.DigestSchedule) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if ds.Id == "" {
+		ds.Id = utils.NanoId(8)
+		p.Settings.DigestSchedules = append(p.Settings.DigestSchedules, ds)
+		return ds.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.DigestSchedules {
+		if p.Settings.DigestSchedules[i].Id == ds.Id {
+			ds.SentAt = p.Settings.DigestSchedules[i].SentAt
+			p.Settings.DigestSchedules[i] = ds
+			return ds.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteDigestSchedule(id ProjectId, scheduleId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var schedules []model.DigestSchedule
+	for _, ds := range p.Settings.DigestSchedules {
+		if ds.Id != scheduleId {
+			schedules = append(schedules, ds)
+		}
+	}
+	p.Settings.DigestSchedules = schedules
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveDigestSentAt(id ProjectId, scheduleId string, t timeseries.Time) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	for i := range p.Settings.DigestSchedules {
+		if p.Settings.DigestSchedules[i].Id == scheduleId {
+			p.Settings.DigestSchedules[i].SentAt = t
+			return db.saveProjectSettings(p)
+		}
+	}
+	return ErrNotFound
+}
+
+func (db *DB) SaveWebhook(id ProjectId, wh model.Webhook) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if wh.Id == "" {
+		wh.Id = utils.NanoId(8)
+		p.Settings.Webhooks = append(p.Settings.Webhooks, wh)
+		return wh.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.Webhooks {
+		if p.Settings.Webhooks[i].Id == wh.Id {
+			p.Settings.Webhooks[i] = wh
+			return wh.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteWebhook(id ProjectId, webhookId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var webhooks []model.Webhook
+	for _, wh := range p.Settings.Webhooks {
+		if wh.Id != webhookId {
+			webhooks = append(webhooks, wh)
+		}
+	}
+	p.Settings.Webhooks = webhooks
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveEscalationPolicy(id ProjectId, policy model.EscalationPolicy) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if policy.Id == "" {
+		policy.Id = utils.NanoId(8)
+		p.Settings.EscalationPolicies = append(p.Settings.EscalationPolicies, policy)
+		return policy.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.EscalationPolicies {
+		if p.Settings.EscalationPolicies[i].Id == policy.Id {
+			p.Settings.EscalationPolicies[i] = policy
+			return policy.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteEscalationPolicy(id ProjectId, policyId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var policies []model.EscalationPolicy
+	for _, policy := range p.Settings.EscalationPolicies {
+		if policy.Id != policyId {
+			policies = append(policies, policy)
+		}
+	}
+	p.Settings.EscalationPolicies = policies
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveNotificationRoute(id ProjectId, route model.NotificationRoute) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if route.Id == "" {
+		route.Id = utils.NanoId(8)
+		p.Settings.NotificationRoutes = append(p.Settings.NotificationRoutes, route)
+		return route.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.NotificationRoutes {
+		if p.Settings.NotificationRoutes[i].Id == route.Id {
+			p.Settings.NotificationRoutes[i] = route
+			return route.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteNotificationRoute(id ProjectId, routeId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var routes []model.NotificationRoute
+	for _, r := range p.Settings.NotificationRoutes {
+		if r.Id != routeId {
+			routes = append(routes, r)
+		}
+	}
+	p.Settings.NotificationRoutes = routes
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveOnCallSettings(id ProjectId, quietHours model.QuietHours, onCall model.OnCallSchedule) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	p.Settings.QuietHours = quietHours
+	p.Settings.OnCall = onCall
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveNotificationLimits(id ProjectId, limits model.NotificationLimits) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	p.Settings.NotificationLimits = limits
+	return db.saveProjectSettings(p)
+}
+
+// SaveNotificationTemplate replaces the template of the destination, an empty template restores the default notifications.
+func (db *DB) SaveNotificationTemplate(id ProjectId, t model.NotificationTemplate) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var templates model.NotificationTemplates
+	for _, tt := range p.Settings.NotificationTemplates {
+		if tt.Destination != t.Destination {
+			templates = append(templates, tt)
+		}
+	}
+	if !t.IsEmpty() {
+		templates = append(templates, t)
+	}
+	p.Settings.NotificationTemplates = templates
+	return db.saveProjectSettings(p)
+}
+
+// QuietHoursActive reports whether the quiet hours of the project cover the given time.
+func (p *Project) QuietHoursActive(t timeseries.Time) bool {
+	return p.Settings.QuietHours.Active(t.ToStandard().In(p.Location()))
+}
+
+func (db *DB) SaveRepository(id ProjectId, repo model.Repository) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if repo.Id == "" {
+		repo.Id = utils.NanoId(8)
+		p.Settings.Repositories = append(p.Settings.Repositories, repo)
+		return repo.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.Repositories {
+		if p.Settings.Repositories[i].Id == repo.Id {
+			p.Settings.Repositories[i] = repo
+			return repo.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteRepository(id ProjectId, repoId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var repos model.Repositories
+	for _, r := range p.Settings.Repositories {
+		if r.Id != repoId {
+			repos = append(repos, r)
+		}
+	}
+	p.Settings.Repositories = repos
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveStatusPage(id ProjectId, sp model.StatusPage) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if sp.Id == "" {
+		sp.Id = utils.NanoId(8)
+		p.Settings.StatusPages = append(p.Settings.StatusPages, sp)
+		return sp.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.StatusPages {
+		if p.Settings.StatusPages[i].Id == sp.Id {
+			p.Settings.StatusPages[i] = sp
+			return sp.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteStatusPage(id ProjectId, pageId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var pages []model.StatusPage
+	for _, sp := range p.Settings.StatusPages {
+		if sp.Id != pageId {
+			pages = append(pages, sp)
+		}
+	}
+	p.Settings.StatusPages = pages
+	return db.saveProjectSettings(p)
+}
+
+// GetEmbedKey returns the key signing the embed links of the project, creating it if necessary.
+func (db *DB) GetEmbedKey(id ProjectId) (string, error) {
+	return db.getOrCreateKey(id, func(s *Settings) *string { return &s.EmbedKey })
+}
+
+// GetIngestionKey returns the key authenticating the telemetry data pushed to the project, creating it if necessary.
+func (db *DB) GetIngestionKey(id ProjectId) (string, error) {
+	return db.getOrCreateKey(id, func(s *Settings) *string { return &s.IngestionKey })
+}
+
+// ResetIngestionKey replaces the ingestion key of the project, so the data pushed with the previous one is rejected.
+func (db *DB) ResetIngestionKey(id ProjectId) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	p.Settings.IngestionKey = utils.NanoId(32)
+	return db.saveProjectSettings(p)
+}
+
+// getOrCreateKey returns the key of the project settings referred to by key, generating it if it's empty.
+// The settings are replaced only if they haven't changed since they were read, so the concurrent callers get the same key.
+func (db *DB) getOrCreateKey(id ProjectId, key func(s *Settings) *string) (string, error) {
+	for {
+		var settings sql.NullString
+		if err := db.db.QueryRow("SELECT settings FROM project WHERE id = $1", id).Scan(&settings); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return "", ErrNotFound
+			}
+			return "", err
+		}
+		var s Settings
+		if settings.Valid {
+			if err := json.Unmarshal([]byte(settings.String), &s); err != nil {
+				return "", err
+			}
+		}
+		k := key(&s)
+		if *k != "" {
+			return *k, nil
+		}
+		*k = utils.NanoId(32)
+		data, err := json.Marshal(s)
+		if err != nil {
+			return "", err
+		}
+		var res sql.Result
+		if settings.Valid {
+			res, err = db.db.Exec("UPDATE project SET settings = $1 WHERE id = $2 AND settings = $3", string(data), id, settings.String)
+		} else {
+			res, err = db.db.Exec("UPDATE project SET settings = $1 WHERE id = $2 AND settings IS NULL", string(data), id)
+		}
+		if err != nil {
+			return "", err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return "", err
+		} else if n > 0 {
+			return *k, nil
+		}
+	}
+}
+
+// ResetEmbedKey revokes all the embed links of the project.
+func (db *DB) ResetEmbedKey(id ProjectId) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	p.Settings.EmbedKey = ""
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveLogCheck(id ProjectId, lc model.LogCheck) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if lc.Id == "" {
+		lc.Id = utils.NanoId(8)
+		p.Settings.LogChecks = append(p.Settings.LogChecks, lc)
+		return lc.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.LogChecks {
+		if p.Settings.LogChecks[i].Id == lc.Id {
+			p.Settings.LogChecks[i] = lc
+			return lc.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteLogCheck(id ProjectId, checkId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var checks []model.LogCheck
+	for _, lc := range p.Settings.LogChecks {
+		if lc.Id != checkId {
+			checks = append(checks, lc)
+		}
+	}
+	p.Settings.LogChecks = checks
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveCheckMute(id ProjectId, m model.CheckMute) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	m.Id = utils.NanoId(8)
+	now := timeseries.Now()
+	mutes := model.CheckMutes{m}
+	for _, mm := range p.Settings.CheckMutes {
+		if mm.Schedule == "" && mm.Until.Before(now) { // expired
+			continue
+		}
+		mutes = append(mutes, mm)
+	}
+	p.Settings.CheckMutes = mutes
+	return m.Id, db.saveProjectSettings(p)
+}
+
+// DeleteCheckMute deletes the mute only if it belongs to the given check of the application.
+func (db *DB) DeleteCheckMute(id ProjectId, appId model.ApplicationId, checkId model.CheckId, muteId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var mutes model.CheckMutes
+	for _, m := range p.Settings.CheckMutes {
+		if m.Id != muteId || m.ApplicationId != appId || m.CheckId != checkId {
+			mutes = append(mutes, m)
+		}
+	}
+	p.Settings.CheckMutes = mutes
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveMaintenanceWindow(id ProjectId, mw model.MaintenanceWindow) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	mw.Id = utils.NanoId(8)
+	now := timeseries.Now()
+	windows := model.MaintenanceWindows{mw}
+	for _, w := range p.Settings.MaintenanceWindows {
+		if w.To.Before(now.Add(-model.MaintenanceWindowRetention)) {
+			continue
+		}
+		windows = append(windows, w)
+	}
+	p.Settings.MaintenanceWindows = windows
+	return mw.Id, db.saveProjectSettings(p)
+}
+
+func (db *DB) DeleteMaintenanceWindow(id ProjectId, windowId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var windows model.MaintenanceWindows
+	for _, w := range p.Settings.MaintenanceWindows {
+		if w.Id != windowId {
+			windows = append(windows, w)
+		}
+	}
+	p.Settings.MaintenanceWindows = windows
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveUserAnnotation(id ProjectId, a model.UserAnnotation) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	a.Id = utils.NanoId(8)
+	now := timeseries.Now()
+	annotations := model.UserAnnotations{a}
+	for _, aa := range p.Settings.Annotations {
+		if aa.To.Before(now.Add(-model.UserAnnotationRetention)) {
+			continue
+		}
+		annotations = append(annotations, aa)
+	}
+	p.Settings.Annotations = annotations
+	return a.Id, db.saveProjectSettings(p)
+}
+
+func (db *DB) DeleteUserAnnotation(id ProjectId, annotationId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var annotations model.UserAnnotations
+	for _, a := range p.Settings.Annotations {
+		if a.Id != annotationId {
+			annotations = append(annotations, a)
+		}
+	}
+	p.Settings.Annotations = annotations
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveSavedView(id ProjectId, v model.SavedView) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if v.Id == "" {
+		v.Id = utils.NanoId(8)
+		p.Settings.SavedViews = append(p.Settings.SavedViews, v)
+		return v.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.SavedViews {
+		if p.Settings.SavedViews[i].Id == v.Id {
+			p.Settings.SavedViews[i] = v
+			return v.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteSavedView(id ProjectId, viewId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var views []model.SavedView
+	for _, v := range p.Settings.SavedViews {
+		if v.Id != viewId {
+			views = append(views, v)
+		}
+	}
+	p.Settings.SavedViews = views
+	return db.saveProjectSettings(p)
+}
+
+// SaveCheckAnnotation replaces the annotation of the check, an empty annotation is removed.
+func (db *DB) SaveCheckAnnotation(id ProjectId, a model.CheckAnnotation) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var annotations model.CheckAnnotations
+	for _, aa := range p.Settings.CheckAnnotations {
+		if aa.ApplicationId != a.ApplicationId || aa.CheckId != a.CheckId {
+			annotations = append(annotations, aa)
+		}
+	}
+	if !a.IsEmpty() {
+		annotations = append(annotations, a)
+	}
+	p.Settings.CheckAnnotations = annotations
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveCompositeCheck(id ProjectId, cc model.CompositeCheck) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if cc.Id == "" {
+		cc.Id = utils.NanoId(8)
+		p.Settings.CompositeChecks = append(p.Settings.CompositeChecks, cc)
+		return cc.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.CompositeChecks {
+		if p.Settings.CompositeChecks[i].Id == cc.Id {
+			p.Settings.CompositeChecks[i] = cc
+			return cc.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteCompositeCheck(id ProjectId, checkId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var checks []model.CompositeCheck
+	for _, cc := range p.Settings.CompositeChecks {
+		if cc.Id != checkId {
+			checks = append(checks, cc)
+		}
+	}
+	p.Settings.CompositeChecks = checks
+	return db.saveProjectSettings(p)
+}
+
+func (db *DB) SaveCheckTemplate(id ProjectId, t model.CheckTemplate) (string, error) {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return "", err
+	}
+	if t.Id == "" {
+		t.Id = utils.NanoId(8)
+		p.Settings.CheckTemplates = append(p.Settings.CheckTemplates, t)
+		return t.Id, db.saveProjectSettings(p)
+	}
+	for i := range p.Settings.CheckTemplates {
+		if p.Settings.CheckTemplates[i].Id == t.Id {
+			p.Settings.CheckTemplates[i] = t
+			return t.Id, db.saveProjectSettings(p)
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (db *DB) DeleteCheckTemplate(id ProjectId, templateId string) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+	var templates []model.CheckTemplate
+	for _, t := range p.Settings.CheckTemplates {
+		if t.Id != templateId {
+			templates = append(templates, t)
+		}
+	}
+	p.Settings.CheckTemplates = templates
+	return db.saveProjectSettings(p)
+}
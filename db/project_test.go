@@ -0,0 +1,82 @@
+package db
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetEmbedKey(t *testing.T) {
+	db, err := Open(t.TempDir(), "")
+	require.NoError(t, err)
+	id, err := db.SaveProject(Project{Name: "test"})
+	require.NoError(t, err)
+
+	keys := make([]string, 10)
+	var wg sync.WaitGroup
+	for i := range keys {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			k, err := db.GetEmbedKey(id)
+			assert.NoError(t, err)
+			keys[i] = k
+		}(i)
+	}
+	wg.Wait()
+	for _, k := range keys {
+		assert.NotEmpty(t, k)
+		assert.Equal(t, keys[0], k, "all the concurrent callers must get the same key")
+	}
+
+	require.NoError(t, db.ResetEmbedKey(id))
+	k, err := db.GetEmbedKey(id)
+	require.NoError(t, err)
+	assert.NotEqual(t, keys[0], k)
+
+	_, err = db.GetEmbedKey("unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCheckMutes(t *testing.T) {
+	db, err := Open(t.TempDir(), "")
+	require.NoError(t, err)
+	id, err := db.SaveProject(Project{Name: "test"})
+	require.NoError(t, err)
+
+	cart := model.NewApplicationId("default", model.ApplicationKindDeployment, "cart")
+	orders := model.NewApplicationId("default", model.ApplicationKindDeployment, "orders")
+	muteId, err := db.SaveCheckMute(id, model.CheckMute{ApplicationId: cart, CheckId: model.Checks.CPUNode.Id, Schedule: "0 2 * * *", Duration: timeseries.Hour})
+	require.NoError(t, err)
+
+	p, err := db.GetProject(id)
+	require.NoError(t, err)
+	require.Len(t, p.Settings.CheckMutes, 1)
+	at := func(h, m int) timeseries.Time {
+		return timeseries.Time(time.Date(2024, 1, 10, h, m, 0, 0, time.UTC).Unix())
+	}
+	assert.True(t, p.Settings.CheckMutes.IsMuted(cart, model.Checks.CPUNode.Id, at(2, 30), p.Location()))
+	assert.False(t, p.Settings.CheckMutes.IsMuted(cart, model.Checks.CPUNode.Id, at(3, 30), p.Location()))
+
+	// the schedule is evaluated in the project timezone: 02:00 in Berlin is 01:00 UTC in winter
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	assert.True(t, p.Settings.CheckMutes.IsMuted(cart, model.Checks.CPUNode.Id, at(1, 30), berlin))
+	assert.False(t, p.Settings.CheckMutes.IsMuted(cart, model.Checks.CPUNode.Id, at(2, 30), berlin))
+
+	// the mute belongs to another application or check
+	require.NoError(t, db.DeleteCheckMute(id, orders, model.Checks.CPUNode.Id, muteId))
+	require.NoError(t, db.DeleteCheckMute(id, cart, model.Checks.MemoryOOM.Id, muteId))
+	p, err = db.GetProject(id)
+	require.NoError(t, err)
+	assert.Len(t, p.Settings.CheckMutes, 1)
+
+	require.NoError(t, db.DeleteCheckMute(id, cart, model.Checks.CPUNode.Id, muteId))
+	p, err = db.GetProject(id)
+	require.NoError(t, err)
+	assert.Empty(t, p.Settings.CheckMutes)
+}
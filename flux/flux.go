@@ -0,0 +1,93 @@
+package flux
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	KindKustomization = "Kustomization"
+	KindHelmRelease   = "HelmRelease"
+)
+
+var workloadKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+	"CronJob":     true,
+}
+
+// Event is an event sent by the Flux notification-controller through a provider of the generic type.
+type Event struct {
+	InvolvedObject      Object            `json:"involvedObject"`
+	Severity            string            `json:"severity"`
+	Timestamp           time.Time         `json:"timestamp"`
+	Message             string            `json:"message"`
+	Reason              string            `json:"reason"`
+	Metadata            map[string]string `json:"metadata"`
+	ReportingController string            `json:"reportingController"`
+}
+
+type Object struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func (o Object) String() string {
+	return o.Kind + "/" + o.Namespace + "/" + o.Name
+}
+
+// IsRollout reports whether the event is about changes applied to the cluster:
+// a Kustomization change set or a successful Helm install or upgrade.
+func (e *Event) IsRollout() bool {
+	if e.Severity != "info" || e.Revision() == "" {
+		return false
+	}
+	switch e.InvolvedObject.Kind {
+	case KindKustomization:
+		return len(e.ChangedWorkloads()) > 0
+	case KindHelmRelease:
+		return e.Reason == "InstallSucceeded" || e.Reason == "UpgradeSucceeded"
+	}
+	return false
+}
+
+// Revision returns the source revision: a commit SHA for Git sources or a chart version for Helm releases.
+// The metadata key is prefixed with the API group of the object in recent Flux versions
+// (e.g., kustomize.toolkit.fluxcd.io/revision).
+func (e *Event) Revision() string {
+	var rev string
+	for k, v := range e.Metadata {
+		if k == "revision" || strings.HasSuffix(k, "/revision") {
+			rev = v
+			break
+		}
+	}
+	// main@sha1:<sha> (Flux >= 2.0) or main/<sha>
+	if i := strings.LastIndex(rev, ":"); i >= 0 {
+		return rev[i+1:]
+	}
+	if i := strings.LastIndex(rev, "/"); i >= 0 {
+		return rev[i+1:]
+	}
+	return rev
+}
+
+// ChangedWorkloads returns the workloads created or configured by a Kustomization reconciliation.
+// The kustomize-controller lists the change set in the event message: one "Kind/namespace/name action" per line.
+func (e *Event) ChangedWorkloads() []Object {
+	var res []Object
+	for _, line := range strings.Split(e.Message, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 || (parts[1] != "created" && parts[1] != "configured") {
+			continue
+		}
+		ref := strings.Split(parts[0], "/")
+		if len(ref) != 3 || !workloadKinds[ref[0]] {
+			continue
+		}
+		res = append(res, Object{Kind: ref[0], Namespace: ref[1], Name: ref[2]})
+	}
+	return res
+}
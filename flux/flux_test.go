@@ -0,0 +1,62 @@
+package flux
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestKustomizationEvent(t *testing.T) {
+	var e Event
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"involvedObject": {"kind": "Kustomization", "namespace": "flux-system", "name": "apps"},
+		"severity": "info",
+		"timestamp": "2023-11-14T22:13:20Z",
+		"message": "Deployment/default/cart configured\nConfigMap/default/cart-config configured\nStatefulSet/default/db created\nDeployment/default/orders unchanged",
+		"reason": "ReconciliationSucceeded",
+		"metadata": {"kustomize.toolkit.fluxcd.io/revision": "main@sha1:0a1b2c3d4e5f"}
+	}`), &e))
+	assert.Equal(t, "0a1b2c3d4e5f", e.Revision())
+	assert.Equal(t, []Object{
+		{Kind: "Deployment", Namespace: "default", Name: "cart"},
+		{Kind: "StatefulSet", Namespace: "default", Name: "db"},
+	}, e.ChangedWorkloads())
+	assert.True(t, e.IsRollout())
+
+	e.Message = "ConfigMap/default/cart-config configured"
+	assert.False(t, e.IsRollout(), "no workloads have been changed")
+
+	e.Severity = "error"
+	assert.False(t, e.IsRollout())
+}
+
+func TestHelmReleaseEvent(t *testing.T) {
+	e := Event{
+		InvolvedObject: Object{Kind: KindHelmRelease, Namespace: "default", Name: "cart"},
+		Severity:       "info",
+		Reason:         "UpgradeSucceeded",
+		Metadata:       map[string]string{"revision": "1.2.3"},
+	}
+	assert.True(t, e.IsRollout())
+	assert.Equal(t, "1.2.3", e.Revision())
+
+	e.Reason = "UpgradeFailed"
+	assert.False(t, e.IsRollout())
+
+	e.Reason = "InstallSucceeded"
+	e.Metadata = nil
+	assert.False(t, e.IsRollout(), "the revision is unknown")
+}
+
+func TestRevision(t *testing.T) {
+	for rev, expected := range map[string]string{
+		"main@sha1:0a1b2c3": "0a1b2c3",
+		"main/0a1b2c3":      "0a1b2c3",
+		"0a1b2c3":           "0a1b2c3",
+		"":                  "",
+	} {
+		e := Event{Metadata: map[string]string{"revision": rev}}
+		assert.Equal(t, expected, e.Revision(), rev)
+	}
+}
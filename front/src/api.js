@@ -53,6 +53,10 @@ export default class Api {
 
     get(url, args, cb) {
         const params = {...args, ...this.router.currentRoute.query}
+        const lang = storage.local('language');
+        if (lang && lang !== 'project') {
+            params.lang = lang;
+        }
         this.request({method: 'get', url, params}, cb);
     }
 
@@ -72,6 +76,10 @@ export default class Api {
         this.get(`projects`, {}, cb);
     }
 
+    getGlobal(label, cb) {
+        this.get(`global`, {label}, cb);
+    }
+
     getProject(projectId, cb) {
         this.get(`project/${projectId || ''}`, {}, cb);
     }
@@ -133,7 +141,15 @@ export default class Api {
     }
 
     getApplication(appId, cb) {
-        this.get(this.projectPath(`app/${appId}`), {}, cb);
+        this.get(this.projectPath(`app/${appId}`), {table_limit: 100}, cb);
+    }
+
+    compareDeployments(appId, a, b, cb) {
+        this.get(this.projectPath(`app/${appId}/deployments/compare`), {a: a || '', b: b || ''}, cb);
+    }
+
+    getAppReportTable(appId, report, table, params, cb) {
+        this.get(this.projectPath(`app/${appId}/report/${report}/table`), {...params, table}, cb);
     }
 
     getCheckConfig(appId, checkId, cb) {
@@ -148,6 +164,10 @@ export default class Api {
         this.get(this.projectPath(`app/${appId}/profile`), {profile}, cb);
     }
 
+    getFlameGraph(appId, profile, instance, cb) {
+        this.get(this.projectPath(`app/${appId}/profile`), {profile, instance}, cb);
+    }
+
     saveProfileSettings(appId, form, cb) {
         this.post(this.projectPath(`app/${appId}/profile`), form, cb);
     }
@@ -156,10 +176,178 @@ export default class Api {
         this.get(this.projectPath(`app/${appId}/tracing`), {trace}, cb);
     }
 
+    getRawLogs(appId, logs, cb) {
+        this.get(this.projectPath(`app/${appId}/logs`), {logs}, cb);
+    }
+
     saveTracingSettings(appId, form, cb) {
         this.post(this.projectPath(`app/${appId}/tracing`), form, cb);
     }
 
+    getDashboards(cb) {
+        this.get(this.projectPath(`dashboards`), {}, cb);
+    }
+
+    getDashboard(id, cb) {
+        this.get(this.projectPath(`dashboards/${id}`), {}, cb);
+    }
+
+    saveDashboard(id, form, cb) {
+        this.post(this.projectPath(`dashboards${id ? '/'+id : ''}`), form, cb);
+    }
+
+    deleteDashboard(id, cb) {
+        this.del(this.projectPath(`dashboards/${id}`), cb);
+    }
+
+    getSavedViews(cb) {
+        this.get(this.projectPath(`views`), {}, cb);
+    }
+
+    getSavedView(id, cb) {
+        this.get(this.projectPath(`views/${id}`), {}, cb);
+    }
+
+    saveSavedView(id, form, cb) {
+        this.post(this.projectPath(`views${id ? '/'+id : ''}`), form, cb);
+    }
+
+    deleteSavedView(id, cb) {
+        this.del(this.projectPath(`views/${id}`), cb);
+    }
+
+    getDigests(cb) {
+        this.get(this.projectPath(`digests`), {}, cb);
+    }
+
+    saveDigest(id, form, cb) {
+        this.post(this.projectPath(`digests${id ? '/'+id : ''}`), form, cb);
+    }
+
+    deleteDigest(id, cb) {
+        this.del(this.projectPath(`digests/${id}`), cb);
+    }
+
+    createEmbedLink(appId, form, cb) {
+        this.post(this.projectPath(`app/${appId}/embed`), form, cb);
+    }
+
+    revokeEmbedLinks(cb) {
+        this.del(this.projectPath(`embed`), cb);
+    }
+
+    getIngestionKey(cb) {
+        this.get(this.projectPath(`ingestion_key`), {}, cb);
+    }
+
+    resetIngestionKey(cb) {
+        this.del(this.projectPath(`ingestion_key`), cb);
+    }
+
+    getEmbed(token, cb) {
+        this.get(`embed/${token}`, {}, cb);
+    }
+
+    getStatusPages(cb) {
+        this.get(this.projectPath(`status_pages`), {}, cb);
+    }
+
+    saveStatusPage(id, form, cb) {
+        this.post(this.projectPath(`status_pages${id ? '/'+id : ''}`), form, cb);
+    }
+
+    deleteStatusPage(id, cb) {
+        this.del(this.projectPath(`status_pages/${id}`), cb);
+    }
+
+    getPublicStatusPage(projectId, id, cb) {
+        this.get(`status/${projectId}/${id}`, {}, cb);
+    }
+
+    getWebhooks(cb) {
+        this.get(this.projectPath(`webhooks`), {}, cb);
+    }
+
+    saveWebhook(id, form, cb) {
+        this.post(this.projectPath(`webhooks${id ? '/'+id : ''}`), form, cb);
+    }
+
+    deleteWebhook(id, cb) {
+        this.del(this.projectPath(`webhooks/${id}`), cb);
+    }
+
+    getEscalationPolicies(cb) {
+        this.get(this.projectPath(`escalation_policies`), {}, cb);
+    }
+
+    saveEscalationPolicy(id, form, cb) {
+        this.post(this.projectPath(`escalation_policies${id ? '/'+id : ''}`), form, cb);
+    }
+
+    deleteEscalationPolicy(id, cb) {
+        this.del(this.projectPath(`escalation_policies/${id}`), cb);
+    }
+
+    getNotificationRoutes(cb) {
+        this.get(this.projectPath(`notification_routes`), {}, cb);
+    }
+
+    saveNotificationRoute(id, form, cb) {
+        this.post(this.projectPath(`notification_routes${id ? '/'+id : ''}`), form, cb);
+    }
+
+    deleteNotificationRoute(id, cb) {
+        this.del(this.projectPath(`notification_routes/${id}`), cb);
+    }
+
+    getOnCall(cb) {
+        this.get(this.projectPath(`on_call`), {}, cb);
+    }
+
+    saveOnCall(form, cb) {
+        this.post(this.projectPath(`on_call`), form, cb);
+    }
+
+    getNotificationLimits(cb) {
+        this.get(this.projectPath(`notification_limits`), {}, cb);
+    }
+
+    saveNotificationLimits(form, cb) {
+        this.post(this.projectPath(`notification_limits`), form, cb);
+    }
+
+    getNotificationTemplates(cb) {
+        this.get(this.projectPath(`notification_templates`), {}, cb);
+    }
+
+    saveNotificationTemplate(form, cb) {
+        this.post(this.projectPath(`notification_templates`), form, cb);
+    }
+
+    getNotificationDeliveries(failed, cb) {
+        this.get(this.projectPath(`notification_deliveries`), {failed}, cb);
+    }
+
+    getIncidentAck(key, cb) {
+        this.get(this.projectPath(`incident/${key}/ack`), {}, cb);
+    }
+
+    acknowledgeIncident(key, form, cb) {
+        this.post(this.projectPath(`incident/${key}/ack`), form, cb);
+    }
+
+    getRepositories(cb) {
+        this.get(this.projectPath(`repositories`), {}, cb);
+    }
+
+    saveRepository(id, form, cb) {
+        this.post(this.projectPath(`repositories${id ? '/'+id : ''}`), form, cb);
+    }
+
+    deleteRepository(id, cb) {
+        this.del(this.projectPath(`repositories/${id}`), cb);
+    }
+
     getNode(nodeName, cb) {
         this.get(this.projectPath(`node/${nodeName}`), {}, cb);
     }
@@ -168,6 +356,27 @@ export default class Api {
         this.get(this.projectPath(`search`), {}, cb);
     }
 
+    getAppReportPDFUrl(appId, report) {
+        return this.exportUrl(this.projectPath(`app/${appId}/report/${report}/export/pdf`));
+    }
+
+    getAppReportCSVUrl(appId, report, table) {
+        return this.exportUrl(this.projectPath(`app/${appId}/report/${report}/export/csv`), {table});
+    }
+
+    getNodePDFUrl(nodeName) {
+        return this.exportUrl(this.projectPath(`node/${nodeName}/export/pdf`));
+    }
+
+    exportUrl(path, args) {
+        const {from, to} = this.router.currentRoute.query;
+        const params = new URLSearchParams(args);
+        from && params.set('from', from);
+        to && params.set('to', to);
+        const q = params.toString();
+        return this.basePath + 'api/' + path + (q ? '?' + q : '');
+    }
+
     getPromPath() {
         return this.basePath + 'api/' + this.projectPath('prom');
     }
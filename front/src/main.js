@@ -15,7 +15,13 @@ import Project from "@/views/Project";
 import Overview from "@/views/Overview";
 import Application from "@/views/Application";
 import Node from "@/views/Node";
+import Dashboards from "@/views/Dashboards";
 import Welcome from "@/views/Welcome";
+import Global from "@/views/Global";
+import SavedView from "@/views/SavedView";
+import Embed from "@/views/Embed";
+import StatusPage from "@/views/StatusPage";
+import DeploymentComparison from "@/views/DeploymentComparison";
 
 Vue.config.productionTip = false;
 
@@ -28,10 +34,16 @@ const router = new VueRouter({
     routes: [
         {path: '/p/new/:tab?', name: 'project_new', component: Project},
         {path: '/p/:projectId/settings/:tab?', name: 'project_settings', component: Project, props: true, meta: {stats: {param: 'tab'}}},
+        {path: '/p/:projectId/dashboards/:id?', name: 'dashboards', component: Dashboards, props: true},
+        {path: '/p/:projectId/v/:viewId', name: 'saved_view', component: SavedView, props: true},
         {path: '/p/:projectId/:view?', name: 'overview', component: Overview, props: true, meta: {stats: {param: 'view'}}},
+        {path: '/p/:projectId/app/:id/deployments/compare', name: 'deployment_comparison', component: DeploymentComparison, props: true},
         {path: '/p/:projectId/app/:id/:report?', name: 'application', component: Application, props: true, meta: {stats: {param: 'report'}}},
         {path: '/p/:projectId/node/:name', name: 'node', component: Node, props: true},
         {path: '/welcome', name: 'welcome', component: Welcome},
+        {path: '/global', name: 'global', component: Global},
+        {path: '/embed/:token', name: 'embed', component: Embed, props: true, meta: {embed: true}},
+        {path: '/status/:projectId/:id', name: 'status_page', component: StatusPage, props: true, meta: {embed: true}},
         {path: '/', name: 'index', component: App},
         {path: '*', redirect: {name: 'index'}},
     ],
@@ -0,0 +1,28 @@
+// The notification integrations that incidents can be routed or escalated to.
+// `target` describes the recipient that can override the default one of the integration.
+export const destinations = [
+    {text: 'Slack', value: 'slack', target: 'channel'},
+    {text: 'Microsoft Teams', value: 'teams'},
+    {text: 'Telegram', value: 'telegram'},
+    {text: 'Discord', value: 'discord'},
+    {text: 'Mattermost', value: 'mattermost'},
+    {text: 'Email', value: 'email', target: 'email addresses'},
+    {text: 'PagerDuty', value: 'pagerduty'},
+    {text: 'Opsgenie', value: 'opsgenie', target: 'escalation'},
+    {text: 'Twilio (SMS/voice)', value: 'twilio', target: 'phone numbers'},
+];
+
+export function destinationName(value) {
+    const d = destinations.find((d) => d.value === value);
+    return d ? d.text : value;
+}
+
+export function hasTarget(value) {
+    const d = destinations.find((d) => d.value === value);
+    return !!(d && d.target);
+}
+
+export function targetLabel(value) {
+    const d = destinations.find((d) => d.value === value);
+    return d && d.target ? d.target + ' (optional)' : 'no target';
+}
@@ -0,0 +1,132 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	scope          = "https://www.googleapis.com/auth/cloud-platform"
+	metadataServer = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// Config defines how to access the GCP APIs. If the service account key isn't set, the credentials of the service account
+// attached to the GCE instance or the GKE workload (Workload Identity) are obtained from the metadata server.
+type Config struct {
+	ProjectId         string
+	ServiceAccountKey string // the JSON key of the service account
+}
+
+type Client struct {
+	project string
+	http    *http.Client
+}
+
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.ProjectId == "" {
+		return nil, errors.New("project id is required")
+	}
+	var ts oauth2.TokenSource
+	if cfg.ServiceAccountKey != "" {
+		var key struct {
+			Type         string `json:"type"`
+			ClientEmail  string `json:"client_email"`
+			PrivateKey   string `json:"private_key"`
+			PrivateKeyId string `json:"private_key_id"`
+			TokenUri     string `json:"token_uri"`
+		}
+		if err := json.Unmarshal([]byte(cfg.ServiceAccountKey), &key); err != nil {
+			return nil, fmt.Errorf("invalid service account key: %w", err)
+		}
+		if key.Type != "service_account" {
+			return nil, fmt.Errorf("invalid service account key: unsupported type %q", key.Type)
+		}
+		jc := &jwt.Config{
+			Email:        key.ClientEmail,
+			PrivateKey:   []byte(key.PrivateKey),
+			PrivateKeyID: key.PrivateKeyId,
+			TokenURL:     key.TokenUri,
+			Scopes:       []string{scope},
+		}
+		if jc.TokenURL == "" {
+			jc.TokenURL = "https://oauth2.googleapis.com/token"
+		}
+		ts = jc.TokenSource(context.Background())
+	} else {
+		ts = oauth2.ReuseTokenSource(nil, metadataTokenSource{})
+	}
+	return &Client{
+		project: cfg.ProjectId,
+		http:    &http.Client{Transport: &oauth2.Transport{Source: ts}},
+	}, nil
+}
+
+// get requests a GCP API and decodes the JSON response into dest.
+func (c *Client) get(ctx context.Context, u string, params url.Values, dest any) error {
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(body, &e) == nil && e.Error.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, e.Error.Message)
+		}
+		return errors.New(resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+type metadataTokenSource struct{}
+
+func (metadataTokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataServer, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a token from the metadata server: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get a token from the metadata server: %s", resp.Status)
+	}
+	var t struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		TokenType   string `json:"token_type"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{
+		AccessToken: t.AccessToken,
+		TokenType:   t.TokenType,
+		Expiry:      time.Now().Add(time.Duration(t.ExpiresIn) * time.Second),
+	}, nil
+}
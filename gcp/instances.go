@@ -0,0 +1,194 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"github.com/coroot/coroot/utils"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CloudSqlInstance is a Cloud SQL for PostgreSQL or MySQL instance.
+type CloudSqlInstance struct {
+	Name            string
+	DatabaseVersion string // e.g., POSTGRES_15 or MYSQL_8_0
+	Region          string
+	Zone            string
+	Tier            string
+	DiskType        string
+	DiskSizeGb      int64
+	HighlyAvailable bool
+	State           string
+	Address         string // the private IP if the instance has one, since the applications of a VPC connect to it
+}
+
+// Id is the value of the cloudsql_instance_id label the metrics of the instance are stored with.
+func (i CloudSqlInstance) Id(project string) string {
+	return project + "/" + i.Name
+}
+
+func (i CloudSqlInstance) IsPostgres() bool {
+	return strings.HasPrefix(i.DatabaseVersion, "POSTGRES")
+}
+
+func (i CloudSqlInstance) IsMysql() bool {
+	return strings.HasPrefix(i.DatabaseVersion, "MYSQL")
+}
+
+// Engine returns the engine and its version, e.g., mysql and 8.0 for MYSQL_8_0.
+func (i CloudSqlInstance) Engine() (string, string) {
+	engine, version, _ := strings.Cut(strings.ToLower(i.DatabaseVersion), "_")
+	return engine, strings.ReplaceAll(version, "_", ".")
+}
+
+func (i CloudSqlInstance) Port() int {
+	if i.IsPostgres() {
+		return 5432
+	}
+	return 3306
+}
+
+// CloudSqlInstances returns the PostgreSQL and MySQL instances of the project whose names match the patterns (all if no patterns are given).
+func (c *Client) CloudSqlInstances(ctx context.Context, patterns []string) ([]CloudSqlInstance, error) {
+	var res []CloudSqlInstance
+	params := url.Values{}
+	for {
+		var out struct {
+			Items []struct {
+				Name            string `json:"name"`
+				DatabaseVersion string `json:"databaseVersion"`
+				Region          string `json:"region"`
+				GceZone         string `json:"gceZone"`
+				State           string `json:"state"`
+				Settings        struct {
+					Tier             string `json:"tier"`
+					DataDiskType     string `json:"dataDiskType"`
+					DataDiskSizeGb   string `json:"dataDiskSizeGb"`
+					AvailabilityType string `json:"availabilityType"`
+				} `json:"settings"`
+				IpAddresses []struct {
+					Type      string `json:"type"`
+					IpAddress string `json:"ipAddress"`
+				} `json:"ipAddresses"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.get(ctx, "https://sqladmin.googleapis.com/v1/projects/"+url.PathEscape(c.project)+"/instances", params, &out); err != nil {
+			return nil, fmt.Errorf("failed to list Cloud SQL instances: %w", err)
+		}
+		for _, item := range out.Items {
+			i := CloudSqlInstance{
+				Name:            item.Name,
+				DatabaseVersion: item.DatabaseVersion,
+				Region:          item.Region,
+				Zone:            item.GceZone,
+				Tier:            item.Settings.Tier,
+				DiskType:        item.Settings.DataDiskType,
+				HighlyAvailable: item.Settings.AvailabilityType == "REGIONAL",
+				State:           item.State,
+			}
+			i.DiskSizeGb, _ = strconv.ParseInt(item.Settings.DataDiskSizeGb, 10, 64)
+			for _, a := range item.IpAddresses {
+				switch a.Type {
+				case "PRIVATE":
+					i.Address = a.IpAddress
+				case "PRIMARY":
+					if i.Address == "" {
+						i.Address = a.IpAddress
+					}
+				}
+			}
+			if !i.IsPostgres() && !i.IsMysql() {
+				continue
+			}
+			if len(patterns) > 0 && !utils.GlobMatch(i.Name, patterns) {
+				continue
+			}
+			res = append(res, i)
+		}
+		if out.NextPageToken == "" {
+			break
+		}
+		params.Set("pageToken", out.NextPageToken)
+	}
+	return res, nil
+}
+
+// MemorystoreInstance is a Memorystore for Redis instance.
+type MemorystoreInstance struct {
+	Name         string
+	Region       string
+	Zone         string
+	Tier         string // BASIC or STANDARD_HA
+	RedisVersion string // e.g., REDIS_7_0
+	MemorySizeGb int64
+	State        string
+	Host         string
+	Port         int64
+}
+
+// Id is the value of the memorystore_instance_id label the metrics of the instance are stored with.
+func (i MemorystoreInstance) Id(project string) string {
+	return project + "/" + i.Region + "/" + i.Name
+}
+
+// resourceName is the full name of the instance, it's also the instance_id label of its Cloud Monitoring metrics.
+func (i MemorystoreInstance) resourceName(project string) string {
+	return "projects/" + project + "/locations/" + i.Region + "/instances/" + i.Name
+}
+
+// Version returns the Redis version, e.g., 7.0 for REDIS_7_0.
+func (i MemorystoreInstance) Version() string {
+	return strings.ReplaceAll(strings.TrimPrefix(i.RedisVersion, "REDIS_"), "_", ".")
+}
+
+// MemorystoreInstances returns the Redis instances of the project whose names match the patterns (all if no patterns are given).
+func (c *Client) MemorystoreInstances(ctx context.Context, patterns []string) ([]MemorystoreInstance, error) {
+	var res []MemorystoreInstance
+	params := url.Values{}
+	for {
+		var out struct {
+			Instances []struct {
+				Name         string `json:"name"` // projects/<project>/locations/<region>/instances/<name>
+				LocationId   string `json:"locationId"`
+				Tier         string `json:"tier"`
+				RedisVersion string `json:"redisVersion"`
+				MemorySizeGb int64  `json:"memorySizeGb"`
+				State        string `json:"state"`
+				Host         string `json:"host"`
+				Port         int64  `json:"port"`
+			} `json:"instances"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.get(ctx, "https://redis.googleapis.com/v1/projects/"+url.PathEscape(c.project)+"/locations/-/instances", params, &out); err != nil {
+			return nil, fmt.Errorf("failed to list Memorystore instances: %w", err)
+		}
+		for _, item := range out.Instances {
+			parts := strings.Split(item.Name, "/")
+			if len(parts) != 6 {
+				continue
+			}
+			i := MemorystoreInstance{
+				Name:         parts[5],
+				Region:       parts[3],
+				Zone:         item.LocationId,
+				Tier:         item.Tier,
+				RedisVersion: item.RedisVersion,
+				MemorySizeGb: item.MemorySizeGb,
+				State:        item.State,
+				Host:         item.Host,
+				Port:         item.Port,
+			}
+			if len(patterns) > 0 && !utils.GlobMatch(i.Name, patterns) {
+				continue
+			}
+			res = append(res, i)
+		}
+		if out.NextPageToken == "" {
+			break
+		}
+		params.Set("pageToken", out.NextPageToken)
+	}
+	return res, nil
+}
@@ -0,0 +1,262 @@
+package gcp
+
+import (
+	"context"
+	"github.com/prometheus/prometheus/prompb"
+	"k8s.io/klog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// series accumulates the samples in the same format as the metrics of the AWS integration: gcp_* for the instance itself,
+// pg_* and redis_* for the PostgreSQL and Redis auditors.
+type series struct {
+	ts     int64
+	labels map[string]string
+	res    []prompb.TimeSeries
+}
+
+func newSeries(t time.Time, labels map[string]string) *series {
+	return &series{ts: t.UnixMilli(), labels: labels}
+}
+
+func (s *series) add(name string, value float64, labels ...string) {
+	ts := prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: "__name__", Value: name}},
+		Samples: []prompb.Sample{{Timestamp: s.ts, Value: value}},
+	}
+	for k, v := range s.labels {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: k, Value: v})
+	}
+	for i := 0; i+1 < len(labels); i += 2 {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: labels[i], Value: labels[i+1]})
+	}
+	s.res = append(s.res, ts)
+}
+
+// monitoringMetric is a Cloud Monitoring metric type whose latest values are accumulated by the instance under the key,
+// broken down by up to two metric labels. The values of the same key are summed, e.g., the calls of the Memorystore nodes.
+type monitoringMetric struct {
+	metric      string
+	aligner     string
+	key         string
+	labels      []string
+	primaryOnly bool // skip the values of the replica nodes of Memorystore instances, e.g., to not count the memory twice
+}
+
+type valueKey struct {
+	name   string
+	label1 string
+	label2 string
+}
+
+type values map[valueKey]float64
+
+func (vs values) get(name string) (float64, bool) {
+	v, ok := vs[valueKey{name: name}]
+	return v, ok
+}
+
+var (
+	cloudSqlMetrics = []monitoringMetric{
+		{metric: "cloudsql.googleapis.com/database/up", aligner: alignMean, key: "up"},
+		{metric: "cloudsql.googleapis.com/database/cpu/reserved_cores", aligner: alignMean, key: "cpu_cores"},
+		{metric: "cloudsql.googleapis.com/database/cpu/utilization", aligner: alignMean, key: "cpu_utilization"},
+		{metric: "cloudsql.googleapis.com/database/memory/quota", aligner: alignMean, key: "memory_quota"},
+		{metric: "cloudsql.googleapis.com/database/memory/total_usage", aligner: alignMean, key: "memory_total_usage"}, // including the page cache
+		{metric: "cloudsql.googleapis.com/database/memory/usage", aligner: alignMean, key: "memory_usage", primaryOnly: true},
+		{metric: "cloudsql.googleapis.com/database/disk/quota", aligner: alignMean, key: "disk_quota"},
+		{metric: "cloudsql.googleapis.com/database/disk/bytes_used", aligner: alignMean, key: "disk_used"},
+		{metric: "cloudsql.googleapis.com/database/disk/read_ops_count", aligner: alignRate, key: "disk_read_ops"},
+		{metric: "cloudsql.googleapis.com/database/disk/write_ops_count", aligner: alignRate, key: "disk_write_ops"},
+		{metric: "cloudsql.googleapis.com/database/network/received_bytes_count", aligner: alignRate, key: "network_rx"},
+		{metric: "cloudsql.googleapis.com/database/network/sent_bytes_count", aligner: alignRate, key: "network_tx"},
+		{metric: "cloudsql.googleapis.com/database/postgresql/num_backends_by_state", aligner: alignMean, key: "pg_backends", labels: []string{"database", "state"}},
+	}
+	memorystoreMetrics = []monitoringMetric{
+		{metric: "redis.googleapis.com/stats/memory/maxmemory", aligner: alignMean, key: "memory_max", primaryOnly: true},
+		{metric: "redis.googleapis.com/stats/memory/usage", aligner: alignMean, key: "memory_usage"},
+		{metric: "redis.googleapis.com/stats/network_traffic", aligner: alignRate, key: "network", labels: []string{"direction"}, primaryOnly: true},
+		{metric: "redis.googleapis.com/commands/calls", aligner: alignRate, key: "calls", labels: []string{"cmd"}},
+		{metric: "redis.googleapis.com/commands/total_time", aligner: alignRate, key: "calls_time", labels: []string{"cmd"}}, // microseconds
+	}
+)
+
+// Collect returns the current metrics of the instances: the instance info and status from the Cloud SQL Admin and Memorystore APIs,
+// and the resource usage and the database metrics from Cloud Monitoring. The errors of Cloud Monitoring are logged,
+// so that an instance still gets the metrics available.
+func (c *Client) Collect(ctx context.Context, sqlInstances []CloudSqlInstance, redisInstances []MemorystoreInstance) []prompb.TimeSeries {
+	now := time.Now()
+	var res []prompb.TimeSeries
+
+	if len(sqlInstances) > 0 {
+		byDatabaseId := map[string]values{}
+		for _, i := range sqlInstances {
+			byDatabaseId[c.project+":"+i.Name] = values{}
+		}
+		c.monitoringValues(ctx, cloudSqlMetrics, "database_id", byDatabaseId, now)
+		for _, i := range sqlInstances {
+			s := newSeries(now, map[string]string{"cloudsql_instance_id": i.Id(c.project)})
+			cloudSqlInstanceMetrics(s, i, byDatabaseId[c.project+":"+i.Name])
+			res = append(res, s.res...)
+		}
+	}
+
+	if len(redisInstances) > 0 {
+		byInstanceId := map[string]values{}
+		for _, i := range redisInstances {
+			byInstanceId[i.resourceName(c.project)] = values{}
+		}
+		c.monitoringValues(ctx, memorystoreMetrics, "instance_id", byInstanceId, now)
+		for _, i := range redisInstances {
+			s := newSeries(now, map[string]string{"memorystore_instance_id": i.Id(c.project)})
+			memorystoreInstanceMetrics(s, i, byInstanceId[i.resourceName(c.project)])
+			res = append(res, s.res...)
+		}
+	}
+	return res
+}
+
+// monitoringValues accumulates the latest values of the metrics by the instance identified by the resource label.
+func (c *Client) monitoringValues(ctx context.Context, metrics []monitoringMetric, resourceLabel string, byInstance map[string]values, now time.Time) {
+	for _, m := range metrics {
+		points, err := c.latestPoints(ctx, m.metric, m.aligner, now)
+		if err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		for _, p := range points {
+			vs := byInstance[p.resource[resourceLabel]]
+			if vs == nil || (m.primaryOnly && p.metric["role"] == "replica") {
+				continue
+			}
+			k := valueKey{name: m.key}
+			if len(m.labels) > 0 {
+				k.label1 = p.metric[m.labels[0]]
+			}
+			if len(m.labels) > 1 {
+				k.label2 = p.metric[m.labels[1]]
+			}
+			vs[k] += p.value
+		}
+	}
+}
+
+func cloudSqlInstanceMetrics(s *series, i CloudSqlInstance, vs values) {
+	engine, version := i.Engine()
+	s.add("gcp_cloudsql_info", 1,
+		"ipv4", i.Address,
+		"port", strconv.Itoa(i.Port()),
+		"engine", engine,
+		"engine_version", version,
+		"tier", i.Tier,
+		"disk_type", i.DiskType,
+		"region", i.Region,
+		"zone", i.Zone,
+		"highly_available", strconv.FormatBool(i.HighlyAvailable),
+	)
+	s.add("gcp_cloudsql_status", 1, "status", i.State)
+
+	if v, ok := vs.get("cpu_cores"); ok {
+		s.add("gcp_cloudsql_cpu_cores", v)
+	}
+	if v, ok := vs.get("cpu_utilization"); ok {
+		s.add("gcp_cloudsql_cpu_usage_percent", v*100, "mode", "total")
+	}
+	if quota, ok := vs.get("memory_quota"); ok {
+		s.add("gcp_cloudsql_memory_total_bytes", quota)
+		if total, ok := vs.get("memory_total_usage"); ok {
+			s.add("gcp_cloudsql_memory_free_bytes", quota-total)
+			if usage, ok := vs.get("memory_usage"); ok {
+				s.add("gcp_cloudsql_memory_cached_bytes", total-usage)
+			}
+		}
+	}
+	quota, ok := vs.get("disk_quota")
+	if !ok && i.DiskSizeGb > 0 {
+		quota, ok = float64(i.DiskSizeGb)*(1<<30), true
+	}
+	if ok {
+		s.add("gcp_cloudsql_disk_total_bytes", quota)
+	}
+	if v, ok := vs.get("disk_used"); ok {
+		s.add("gcp_cloudsql_disk_used_bytes", v)
+	}
+	if v, ok := vs.get("disk_read_ops"); ok {
+		s.add("gcp_cloudsql_disk_ops_per_second", v, "operation", "read")
+	}
+	if v, ok := vs.get("disk_write_ops"); ok {
+		s.add("gcp_cloudsql_disk_ops_per_second", v, "operation", "write")
+	}
+	if v, ok := vs.get("network_rx"); ok {
+		s.add("gcp_cloudsql_net_rx_bytes_per_second", v)
+	}
+	if v, ok := vs.get("network_tx"); ok {
+		s.add("gcp_cloudsql_net_tx_bytes_per_second", v)
+	}
+
+	if !i.IsPostgres() {
+		return
+	}
+	up, ok := vs.get("up")
+	if !ok {
+		up = 0
+		if i.State == "RUNNABLE" {
+			up = 1
+		}
+	}
+	s.add("pg_up", up)
+	s.add("pg_info", 1, "server_version", version)
+	for k, v := range vs {
+		if k.name == "pg_backends" {
+			s.add("pg_connections", v, "db", k.label1, "user", "", "state", strings.ReplaceAll(k.label2, "_", " "))
+		}
+	}
+}
+
+func memorystoreInstanceMetrics(s *series, i MemorystoreInstance, vs values) {
+	var ip string
+	if addr := net.ParseIP(i.Host); addr != nil && addr.To4() != nil {
+		ip = addr.String()
+	}
+	s.add("gcp_memorystore_info", 1,
+		"ipv4", ip,
+		"port", strconv.FormatInt(i.Port, 10),
+		"redis_version", i.Version(),
+		"tier", i.Tier,
+		"region", i.Region,
+		"zone", i.Zone,
+	)
+	s.add("gcp_memorystore_status", 1, "status", i.State)
+
+	if maxmemory, ok := vs.get("memory_max"); ok {
+		s.add("gcp_memorystore_memory_total_bytes", maxmemory)
+		if usage, ok := vs.get("memory_usage"); ok {
+			s.add("gcp_memorystore_memory_free_bytes", maxmemory-usage)
+		}
+	}
+	for k, v := range vs {
+		switch k.name {
+		case "network":
+			switch k.label1 {
+			case "in":
+				s.add("gcp_memorystore_net_rx_bytes_per_second", v)
+			case "out":
+				s.add("gcp_memorystore_net_tx_bytes_per_second", v)
+			}
+		case "calls":
+			s.add("gcp_memorystore_commands_per_second", v, "cmd", k.label1)
+		case "calls_time":
+			s.add("gcp_memorystore_commands_duration_seconds_per_second", v/1e6, "cmd", k.label1)
+		}
+	}
+
+	up := 0.
+	if i.State == "READY" {
+		up = 1
+	}
+	s.add("redis_up", up)
+	s.add("redis_instance_info", 1, "redis_version", i.Version(), "role", "master")
+}
@@ -0,0 +1,80 @@
+package gcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeSeriesLatest(t *testing.T) {
+	var ts timeSeries
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"metric": {"labels": {"database": "orders", "state": "idle_in_transaction"}},
+		"resource": {"labels": {"database_id": "my-project:db-1"}},
+		"points": [{"value": {"int64Value": "12"}}, {"value": {"int64Value": "10"}}]
+	}`), &ts))
+	v, ok := ts.latest()
+	assert.True(t, ok)
+	assert.Equal(t, 12., v)
+
+	ts = timeSeries{}
+	require.NoError(t, json.Unmarshal([]byte(`{"points": [{"value": {"doubleValue": 0.25}}]}`), &ts))
+	v, ok = ts.latest()
+	assert.True(t, ok)
+	assert.Equal(t, 0.25, v)
+
+	ts = timeSeries{}
+	_, ok = ts.latest()
+	assert.False(t, ok)
+}
+
+func TestCloudSqlInstanceMetrics(t *testing.T) {
+	i := CloudSqlInstance{Name: "db-1", DatabaseVersion: "POSTGRES_15", Region: "us-central1", Zone: "us-central1-a", Tier: "db-custom-2-7680", State: "RUNNABLE", Address: "10.0.0.5"}
+	vs := values{
+		{name: "up"}:                 1,
+		{name: "cpu_utilization"}:    0.25,
+		{name: "memory_quota"}:       1000,
+		{name: "memory_total_usage"}: 800,
+		{name: "memory_usage"}:       300,
+		{name: "pg_backends", label1: "orders", label2: "idle_in_transaction"}: 2,
+	}
+	s := newSeries(time.Unix(60, 0), map[string]string{"cloudsql_instance_id": i.Id("my-project")})
+	cloudSqlInstanceMetrics(s, i, vs)
+
+	var res []string
+	for _, ts := range s.res {
+		res = append(res, format(ts))
+	}
+	sort.Strings(res)
+	assert.Equal(t, []string{
+		`gcp_cloudsql_cpu_usage_percent{cloudsql_instance_id="my-project/db-1",mode="total"} 25 @60000`,
+		`gcp_cloudsql_info{cloudsql_instance_id="my-project/db-1",ipv4="10.0.0.5",port="5432",engine="postgres",engine_version="15",tier="db-custom-2-7680",disk_type="",region="us-central1",zone="us-central1-a",highly_available="false"} 1 @60000`,
+		`gcp_cloudsql_memory_cached_bytes{cloudsql_instance_id="my-project/db-1"} 500 @60000`,
+		`gcp_cloudsql_memory_free_bytes{cloudsql_instance_id="my-project/db-1"} 200 @60000`,
+		`gcp_cloudsql_memory_total_bytes{cloudsql_instance_id="my-project/db-1"} 1000 @60000`,
+		`gcp_cloudsql_status{cloudsql_instance_id="my-project/db-1",status="RUNNABLE"} 1 @60000`,
+		`pg_connections{cloudsql_instance_id="my-project/db-1",db="orders",user="",state="idle in transaction"} 2 @60000`,
+		`pg_info{cloudsql_instance_id="my-project/db-1",server_version="15"} 1 @60000`,
+		`pg_up{cloudsql_instance_id="my-project/db-1"} 1 @60000`,
+	}, res)
+}
+
+func format(ts prompb.TimeSeries) string {
+	var name string
+	var labels []string
+	for _, l := range ts.Labels {
+		if l.Name == "__name__" {
+			name = l.Value
+			continue
+		}
+		labels = append(labels, l.Name+`="`+l.Value+`"`)
+	}
+	s := ts.Samples[0]
+	return fmt.Sprintf("%s{%s} %g @%d", name, strings.Join(labels, ","), s.Value, s.Timestamp)
+}
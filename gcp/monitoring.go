@@ -0,0 +1,99 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// monitoringWindow covers the delay the metrics of the managed services are written to Cloud Monitoring with (up to a few minutes).
+	monitoringWindow = 10 * time.Minute
+	alignmentPeriod  = time.Minute
+
+	alignMean = "ALIGN_MEAN" // for GAUGE metrics
+	alignRate = "ALIGN_RATE" // for DELTA and CUMULATIVE metrics, the value is per second
+)
+
+type point struct {
+	resource map[string]string
+	metric   map[string]string
+	value    float64
+}
+
+type timeSeries struct {
+	Metric struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"metric"`
+	Resource struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"resource"`
+	Points []struct {
+		Value typedValue `json:"value"`
+	} `json:"points"`
+}
+
+type typedValue struct {
+	DoubleValue *float64 `json:"doubleValue"`
+	Int64Value  *string  `json:"int64Value"` // int64 values are encoded as strings
+	BoolValue   *bool    `json:"boolValue"`
+}
+
+func (v typedValue) float() (float64, bool) {
+	switch {
+	case v.DoubleValue != nil:
+		return *v.DoubleValue, true
+	case v.Int64Value != nil:
+		f, err := strconv.ParseFloat(*v.Int64Value, 64)
+		return f, err == nil
+	case v.BoolValue != nil:
+		if *v.BoolValue {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// latest returns the latest value of the series, Cloud Monitoring returns the points in reverse time order.
+func (ts timeSeries) latest() (float64, bool) {
+	for _, p := range ts.Points {
+		if v, ok := p.Value.float(); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// latestPoints returns the latest aligned value of each series of the metric type in the project.
+func (c *Client) latestPoints(ctx context.Context, metricType, aligner string, now time.Time) ([]point, error) {
+	params := url.Values{
+		"filter":                       {fmt.Sprintf(`metric.type = "%s"`, metricType)},
+		"interval.startTime":           {now.Add(-monitoringWindow).UTC().Format(time.RFC3339)},
+		"interval.endTime":             {now.UTC().Format(time.RFC3339)},
+		"aggregation.alignmentPeriod":  {fmt.Sprintf("%ds", int(alignmentPeriod.Seconds()))},
+		"aggregation.perSeriesAligner": {aligner},
+	}
+	var res []point
+	for {
+		var out struct {
+			TimeSeries    []timeSeries `json:"timeSeries"`
+			NextPageToken string       `json:"nextPageToken"`
+		}
+		if err := c.get(ctx, "https://monitoring.googleapis.com/v3/projects/"+url.PathEscape(c.project)+"/timeSeries", params, &out); err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", metricType, err)
+		}
+		for _, ts := range out.TimeSeries {
+			if v, ok := ts.latest(); ok {
+				res = append(res, point{resource: ts.Resource.Labels, metric: ts.Metric.Labels, value: v})
+			}
+		}
+		if out.NextPageToken == "" {
+			break
+		}
+		params.Set("pageToken", out.NextPageToken)
+	}
+	return res, nil
+}
@@ -0,0 +1,94 @@
+package i18n
+
+var de = map[string]string{
+	// reports
+	"SLO":          "SLO",
+	"Instances":    "Instanzen",
+	"CPU":          "CPU",
+	"Memory":       "Speicher",
+	"Storage":      "Datenträger",
+	"Network":      "Netzwerk",
+	"Dependencies": "Abhängigkeiten",
+	"DNS":          "DNS",
+	"Logs":         "Logs",
+	"Postgres":     "Postgres",
+	"Redis":        "Redis",
+	"JVM":          "JVM",
+	"AWS":          "AWS",
+	"Node":         "Knoten",
+	"Deployments":  "Deployments",
+	"Profiling":    "Profiling",
+	"Tracing":      "Tracing",
+	"Custom":       "Benutzerdefiniert",
+
+	// checks
+	"Availability":                  "Verfügbarkeit",
+	"Latency":                       "Latenz",
+	"Fast burn":                     "Schneller Verbrauch",
+	"Slow burn":                     "Langsamer Verbrauch",
+	"Error budget":                  "Fehlerbudget",
+	"Node CPU utilization":          "CPU-Auslastung des Knotens",
+	"Container CPU utilization":     "CPU-Auslastung des Containers",
+	"Out of Memory":                 "Speichermangel (OOM)",
+	"Memory leak":                   "Speicherleck",
+	"Disk I/O":                      "Datenträger-I/O",
+	"Disk space":                    "Speicherplatz",
+	"Network round-trip time (RTT)": "Netzwerk-Umlaufzeit (RTT)",
+	"Network RTT degradation":       "Verschlechterung der Netzwerk-RTT",
+	"Conntrack table utilization":   "Auslastung der Conntrack-Tabelle",
+	"Ephemeral ports":               "Ephemere Ports",
+	"TLS certificate expiry":        "Ablauf von TLS-Zertifikaten",
+	"DNS latency":                   "DNS-Latenz",
+	"DNS errors":                    "DNS-Fehler",
+	"Instance availability":         "Verfügbarkeit der Instanzen",
+	"Restarts":                      "Neustarts",
+	"Deployment status":             "Deployment-Status",
+	"Redis availability":            "Redis-Verfügbarkeit",
+	"Redis latency":                 "Redis-Latenz",
+	"Postgres availability":         "Postgres-Verfügbarkeit",
+	"Postgres latency":              "Postgres-Latenz",
+	"Postgres errors":               "Postgres-Fehler",
+	"Postgres replication lag":      "Postgres-Replikationsverzögerung",
+	"Postgres connections":          "Postgres-Verbindungen",
+	"Errors":                        "Fehler",
+	"JVM availability":              "JVM-Verfügbarkeit",
+	"JVM safepoints":                "JVM-Safepoints",
+
+	// table headers
+	"Application":          "Anwendung",
+	"Direction":            "Richtung",
+	"Requests":             "Anfragen",
+	"RTT":                  "RTT",
+	"Client":               "Client",
+	"Deployment":           "Deployment",
+	"Active":               "Aktiv",
+	"Summary":              "Zusammenfassung",
+	"Domain":               "Domain",
+	"Type":                 "Typ",
+	"Failed requests":      "Fehlgeschlagene Anfragen",
+	"Instance":             "Instanz",
+	"Role":                 "Rolle",
+	"Status":               "Status",
+	"Queries":              "Abfragen",
+	"Replication lag":      "Replikationsverzögerung",
+	"IP":                   "IP",
+	"Objective":            "Ziel",
+	"Budget consumed":      "Verbrauchtes Budget",
+	"Projected exhaustion": "Voraussichtliche Erschöpfung",
+	"TLS certificate":      "TLS-Zertifikat",
+	"Expires in":           "Läuft ab in",
+	"Volume":               "Volume",
+	"I/O":                  "I/O",
+	"Space":                "Speicherplatz",
+	"Device":               "Gerät",
+
+	// units
+	"/s":        "/s",
+	"ms":        "ms",
+	"projected": "prognostiziert",
+
+	// stubs
+	"No notable changes":                       "Keine nennenswerten Änderungen",
+	"Collecting data...":                       "Daten werden gesammelt...",
+	"Not enough data due to the lifetime < %s": "Nicht genügend Daten, da die Laufzeit < %s",
+}
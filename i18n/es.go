@@ -0,0 +1,94 @@
+package i18n
+
+var es = map[string]string{
+	// reports
+	"SLO":          "SLO",
+	"Instances":    "Instancias",
+	"CPU":          "CPU",
+	"Memory":       "Memoria",
+	"Storage":      "Almacenamiento",
+	"Network":      "Red",
+	"Dependencies": "Dependencias",
+	"DNS":          "DNS",
+	"Logs":         "Registros",
+	"Postgres":     "Postgres",
+	"Redis":        "Redis",
+	"JVM":          "JVM",
+	"AWS":          "AWS",
+	"Node":         "Nodo",
+	"Deployments":  "Despliegues",
+	"Profiling":    "Perfilado",
+	"Tracing":      "Trazas",
+	"Custom":       "Personalizado",
+
+	// checks
+	"Availability":                  "Disponibilidad",
+	"Latency":                       "Latencia",
+	"Fast burn":                     "Consumo rápido",
+	"Slow burn":                     "Consumo lento",
+	"Error budget":                  "Presupuesto de errores",
+	"Node CPU utilization":          "Uso de CPU del nodo",
+	"Container CPU utilization":     "Uso de CPU del contenedor",
+	"Out of Memory":                 "Memoria agotada (OOM)",
+	"Memory leak":                   "Fuga de memoria",
+	"Disk I/O":                      "E/S de disco",
+	"Disk space":                    "Espacio en disco",
+	"Network round-trip time (RTT)": "Tiempo de ida y vuelta de red (RTT)",
+	"Network RTT degradation":       "Degradación del RTT de red",
+	"Conntrack table utilization":   "Uso de la tabla conntrack",
+	"Ephemeral ports":               "Puertos efímeros",
+	"TLS certificate expiry":        "Caducidad de certificados TLS",
+	"DNS latency":                   "Latencia de DNS",
+	"DNS errors":                    "Errores de DNS",
+	"Instance availability":         "Disponibilidad de instancias",
+	"Restarts":                      "Reinicios",
+	"Deployment status":             "Estado del despliegue",
+	"Redis availability":            "Disponibilidad de Redis",
+	"Redis latency":                 "Latencia de Redis",
+	"Postgres availability":         "Disponibilidad de Postgres",
+	"Postgres latency":              "Latencia de Postgres",
+	"Postgres errors":               "Errores de Postgres",
+	"Postgres replication lag":      "Retraso de replicación de Postgres",
+	"Postgres connections":          "Conexiones de Postgres",
+	"Errors":                        "Errores",
+	"JVM availability":              "Disponibilidad de la JVM",
+	"JVM safepoints":                "Safepoints de la JVM",
+
+	// table headers
+	"Application":          "Aplicación",
+	"Direction":            "Dirección",
+	"Requests":             "Solicitudes",
+	"RTT":                  "RTT",
+	"Client":               "Cliente",
+	"Deployment":           "Despliegue",
+	"Active":               "Activo",
+	"Summary":              "Resumen",
+	"Domain":               "Dominio",
+	"Type":                 "Tipo",
+	"Failed requests":      "Solicitudes fallidas",
+	"Instance":             "Instancia",
+	"Role":                 "Rol",
+	"Status":               "Estado",
+	"Queries":              "Consultas",
+	"Replication lag":      "Retraso de replicación",
+	"IP":                   "IP",
+	"Objective":            "Objetivo",
+	"Budget consumed":      "Presupuesto consumido",
+	"Projected exhaustion": "Agotamiento previsto",
+	"TLS certificate":      "Certificado TLS",
+	"Expires in":           "Caduca en",
+	"Volume":               "Volumen",
+	"I/O":                  "E/S",
+	"Space":                "Espacio",
+	"Device":               "Dispositivo",
+
+	// units
+	"/s":        "/s",
+	"ms":        "ms",
+	"projected": "previsto",
+
+	// stubs
+	"No notable changes":                       "Sin cambios destacables",
+	"Collecting data...":                       "Recopilando datos...",
+	"Not enough data due to the lifetime < %s": "Datos insuficientes: tiempo de vida < %s",
+}
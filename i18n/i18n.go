@@ -0,0 +1,54 @@
+package i18n
+
+// Language is a UI language. English is the source language of all strings, so it has no catalog.
+type Language struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+const Default = "en"
+
+var Languages = []Language{
+	{Code: "en", Name: "English"},
+	{Code: "de", Name: "Deutsch"},
+	{Code: "es", Name: "Español"},
+}
+
+var catalogs = map[string]map[string]string{
+	"de": de,
+	"es": es,
+}
+
+func Supported(lang string) bool {
+	for _, l := range Languages {
+		if l.Code == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Translator translates report titles, check names, table headers, units, and stub messages.
+// A nil Translator returns strings as is.
+type Translator struct {
+	messages map[string]string
+}
+
+// Get returns the translator for the language, or nil if the language is English or not supported.
+func Get(lang string) *Translator {
+	messages := catalogs[lang]
+	if messages == nil {
+		return nil
+	}
+	return &Translator{messages: messages}
+}
+
+func (t *Translator) T(s string) string {
+	if t == nil || s == "" {
+		return s
+	}
+	if res, ok := t.messages[s]; ok {
+		return res
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package logs
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/coroot/logparser"
+	"strings"
+)
+
+// ElasticsearchFields are the names of the document fields containing the timestamp, the message,
+// the namespace and the instance (pod) name, e.g., @timestamp, message, kubernetes.namespace, kubernetes.pod.name.
+type ElasticsearchFields struct {
+	Timestamp string
+	Message   string
+	Namespace string
+	Instance  string
+}
+
+// ElasticsearchQuery returns a query DSL bool query selecting the documents of the instances within the time range
+// that match any of the patterns. A pattern is turned into an ordered intervals query, so a document matches
+// if its message contains the words of the pattern in the same order (with any gaps between them).
+func ElasticsearchQuery(fields ElasticsearchFields, namespace string, instances []string, patterns []*logparser.Pattern, from, to timeseries.Time) map[string]any {
+	filter := []any{
+		map[string]any{"range": map[string]any{
+			fields.Timestamp: map[string]any{"gte": from.ToStandard().UnixMilli(), "lte": to.ToStandard().UnixMilli(), "format": "epoch_millis"},
+		}},
+	}
+	if namespace != "" && fields.Namespace != "" {
+		filter = append(filter, map[string]any{"term": map[string]any{fields.Namespace: namespace}})
+	}
+	if instances = utils.NewStringSet(instances...).Items(); len(instances) > 0 {
+		filter = append(filter, map[string]any{"terms": map[string]any{fields.Instance: instances}})
+	}
+	var should []any
+	seen := map[string]bool{}
+	for _, p := range patterns {
+		words := strings.Fields(p.String())
+		if len(words) == 0 {
+			continue
+		}
+		if len(words) > maxPatternWords {
+			words = words[:maxPatternWords]
+		}
+		q := strings.Join(words, " ")
+		if seen[q] {
+			continue
+		}
+		seen[q] = true
+		should = append(should, map[string]any{"intervals": map[string]any{
+			fields.Message: map[string]any{"match": map[string]any{"query": q, "ordered": true, "max_gaps": -1}},
+		}})
+	}
+	b := map[string]any{"filter": filter}
+	if len(should) > 0 {
+		b["should"] = should
+		b["minimum_should_match"] = 1
+	}
+	return map[string]any{"bool": b}
+}
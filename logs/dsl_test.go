@@ -0,0 +1,47 @@
+package logs
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/logparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestElasticsearchQuery(t *testing.T) {
+	fields := ElasticsearchFields{Timestamp: "@timestamp", Message: "message", Namespace: "kubernetes.namespace", Instance: "kubernetes.pod.name"}
+	sample := `2023-02-02 10:00:00.123 UTC [42] ERROR:  duplicate key value violates unique constraint "users_pkey"`
+	q := ElasticsearchQuery(fields, "db", []string{"pg-1", "pg-0", "pg-1"}, []*logparser.Pattern{logparser.NewPattern(sample), logparser.NewPattern(sample)}, 1000, 1060)
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool": {
+		"filter": [
+			{"range": {"@timestamp": {"gte": 1000000, "lte": 1060000, "format": "epoch_millis"}}},
+			{"term": {"kubernetes.namespace": "db"}},
+			{"terms": {"kubernetes.pod.name": ["pg-0", "pg-1"]}}
+		],
+		"should": [
+			{"intervals": {"message": {"match": {"query": "UTC ERROR duplicate key value violates unique constraint", "ordered": true, "max_gaps": -1}}}}
+		],
+		"minimum_should_match": 1
+	}}`, string(data))
+
+	data, err = json.Marshal(ElasticsearchQuery(fields, "", nil, nil, 0, timeseries.Time(60)))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool": {"filter": [{"range": {"@timestamp": {"gte": 0, "lte": 60000, "format": "epoch_millis"}}}]}}`, string(data))
+}
+
+func TestSourceField(t *testing.T) {
+	src := map[string]any{
+		"message":              "hello",
+		"kubernetes":           map[string]any{"pod": map[string]any{"name": "pg-0"}},
+		"kubernetes.namespace": "db",
+		"code":                 42.0,
+	}
+	assert.Equal(t, "hello", sourceField(src, "message"))
+	assert.Equal(t, "pg-0", sourceField(src, "kubernetes.pod.name"))
+	assert.Equal(t, "db", sourceField(src, "kubernetes.namespace"))
+	assert.Equal(t, "42", sourceField(src, "code"))
+	assert.Equal(t, "", sourceField(src, "kubernetes.container.name"))
+}
@@ -0,0 +1,158 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Elasticsearch is a client of the search API, it's compatible with OpenSearch as well.
+type Elasticsearch struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewElasticsearch(address string, tlsSkipVerify bool, basicAuth *utils.BasicAuth, apiKey string) (*Elasticsearch, error) {
+	address, err := basicAuth.AddTo(address)
+	if err != nil {
+		return nil, err
+	}
+	c := &Elasticsearch{
+		url:    strings.TrimRight(address, "/"),
+		apiKey: apiKey,
+		client: http.DefaultClient,
+	}
+	if tlsSkipVerify {
+		c.client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	}
+	return c, nil
+}
+
+// Count returns the number of documents in the indices matching the pattern, it's used to check the settings.
+func (c *Elasticsearch) Count(ctx context.Context, index string) (int64, error) {
+	var res struct {
+		Count int64 `json:"count"`
+	}
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_count", nil, &res)
+	return res.Count, err
+}
+
+// Search returns the latest (at most limit) documents matching the query DSL query.
+// The message, the namespace and the instance fields of the documents are returned as the line and the labels of the entries.
+func (c *Elasticsearch) Search(ctx context.Context, index string, fields ElasticsearchFields, query map[string]any, limit int) ([]Entry, error) {
+	body := map[string]any{
+		"size":             limit,
+		"query":            query,
+		"sort":             []any{map[string]any{fields.Timestamp: map[string]any{"order": "desc"}}},
+		"track_total_hits": false,
+	}
+	var res struct {
+		Hits struct {
+			Hits []struct {
+				Source map[string]any `json:"_source"`
+				Sort   []any          `json:"sort"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	if err := c.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", body, &res); err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, h := range res.Hits.Hits {
+		e := Entry{Labels: map[string]string{}}
+		if len(h.Sort) > 0 { // the sort value of a date field is the timestamp in milliseconds regardless of the format of the field
+			if ms, ok := h.Sort[0].(float64); ok {
+				e.Timestamp = time.UnixMilli(int64(ms))
+			}
+		}
+		e.Line = sourceField(h.Source, fields.Message)
+		for _, f := range []string{fields.Namespace, fields.Instance} {
+			if v := sourceField(h.Source, f); v != "" {
+				e.Labels[f] = v
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (c *Elasticsearch) do(ctx context.Context, method, path string, body any, res any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+c.apiKey)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(msg, &e) == nil && e.Error.Reason != "" {
+			return fmt.Errorf("%s: %s: %s", resp.Status, e.Error.Type, e.Error.Reason)
+		}
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+// sourceField returns the value of a field of a document, the name of a nested field can be either a key of the document
+// (e.g., "kubernetes.pod.name" as is) or a path to the field in the nested objects.
+func sourceField(src map[string]any, name string) string {
+	if v, ok := src[name]; ok {
+		return fieldValue(v)
+	}
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	if nested, ok := src[parts[0]].(map[string]any); ok {
+		return sourceField(nested, parts[1])
+	}
+	return ""
+}
+
+func fieldValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
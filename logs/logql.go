@@ -0,0 +1,63 @@
+package logs
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/utils"
+	"github.com/coroot/logparser"
+	"regexp"
+	"strings"
+)
+
+// maxPatternWords limits the length of the generated regexps, the first words are selective enough
+// while the long patterns (e.g., of stack traces) would make Loki match the lines for too long.
+const maxPatternWords = 10
+
+// StreamSelector returns a LogQL stream selector of the instances (pods for Kubernetes applications).
+func StreamSelector(namespaceLabel, instanceLabel, namespace string, instances []string) string {
+	instances = utils.NewStringSet(instances...).Items()
+	var matchers []string
+	if namespace != "" && namespaceLabel != "" {
+		matchers = append(matchers, fmt.Sprintf(`%s=%q`, namespaceLabel, namespace))
+	}
+	switch len(instances) {
+	case 0:
+	case 1:
+		matchers = append(matchers, fmt.Sprintf(`%s=%q`, instanceLabel, instances[0]))
+	default:
+		quoted := make([]string, 0, len(instances))
+		for _, i := range instances {
+			quoted = append(quoted, regexp.QuoteMeta(i))
+		}
+		matchers = append(matchers, fmt.Sprintf(`%s=~%q`, instanceLabel, strings.Join(quoted, "|")))
+	}
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+// PatternFilter returns a LogQL line filter matching the lines of any of the patterns.
+// A pattern consists of the words of the message with the variable parts (numbers, ids, quoted strings) removed,
+// so a line matches if it contains the words in the same order.
+func PatternFilter(patterns ...*logparser.Pattern) string {
+	var alternatives []string
+	seen := map[string]bool{}
+	for _, p := range patterns {
+		words := strings.Fields(p.String())
+		if len(words) == 0 {
+			continue
+		}
+		if len(words) > maxPatternWords {
+			words = words[:maxPatternWords]
+		}
+		for i, w := range words {
+			words[i] = regexp.QuoteMeta(w)
+		}
+		re := strings.Join(words, ".*?")
+		if !seen[re] {
+			seen[re] = true
+			alternatives = append(alternatives, re)
+		}
+	}
+	if len(alternatives) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("|~ %q", "(?s)"+strings.Join(alternatives, "|"))
+}
@@ -0,0 +1,26 @@
+package logs
+
+import (
+	"github.com/coroot/logparser"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestStreamSelector(t *testing.T) {
+	assert.Equal(t, `{namespace="db", pod="pg-0"}`, StreamSelector("namespace", "pod", "db", []string{"pg-0"}))
+	assert.Equal(t, `{namespace="db", pod=~"pg-0|pg-1"}`, StreamSelector("namespace", "pod", "db", []string{"pg-1", "pg-0", "pg-1"}))
+	assert.Equal(t, `{pod=~"pg\\.1|pg\\.2"}`, StreamSelector("namespace", "pod", "", []string{"pg.1", "pg.2"}))
+}
+
+func TestPatternFilter(t *testing.T) {
+	assert.Equal(t, "", PatternFilter())
+
+	sample := `2023-02-02 10:00:00.123 UTC [42] ERROR:  duplicate key value violates unique constraint "users_pkey"`
+	f := PatternFilter(logparser.NewPattern(sample), logparser.NewPattern(sample))
+	assert.Equal(t, `|~ "(?s)UTC.*?ERROR.*?duplicate.*?key.*?value.*?violates.*?unique.*?constraint"`, f)
+
+	re := regexp.MustCompile("(?s)UTC.*?ERROR.*?duplicate.*?key.*?value.*?violates.*?unique.*?constraint")
+	assert.True(t, re.MatchString(`2023-02-03 11:00:00.456 UTC [7] ERROR:  duplicate key value violates unique constraint "orders_pkey"`))
+	assert.False(t, re.MatchString(`2023-02-03 11:00:00.456 UTC [7] ERROR:  deadlock detected`))
+}
@@ -0,0 +1,134 @@
+package logs
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	queryTimeout = 30 * time.Second
+)
+
+type Entry struct {
+	Timestamp time.Time
+	Labels    map[string]string
+	Line      string
+}
+
+type Loki struct {
+	url      string
+	tenantId string
+	client   *http.Client
+}
+
+func NewLoki(address string, tlsSkipVerify bool, basicAuth *utils.BasicAuth, tenantId string) (*Loki, error) {
+	address, err := basicAuth.AddTo(address)
+	if err != nil {
+		return nil, err
+	}
+	c := &Loki{
+		url:      strings.TrimRight(address, "/"),
+		tenantId: tenantId,
+		client:   http.DefaultClient,
+	}
+	if tlsSkipVerify {
+		c.client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	}
+	return c, nil
+}
+
+func (c *Loki) Labels(ctx context.Context) ([]string, error) {
+	var res []string
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	err := c.get(ctx, "/loki/api/v1/labels", nil, &res)
+	return res, err
+}
+
+// QueryRange returns the latest (at most limit) log entries matching the LogQL query within the time range.
+func (c *Loki) QueryRange(ctx context.Context, query string, from, to timeseries.Time, limit int) ([]Entry, error) {
+	args := url.Values{
+		"query":     {query},
+		"start":     {strconv.FormatInt(int64(from)*1e9, 10)},
+		"end":       {strconv.FormatInt(int64(to)*1e9, 10)},
+		"limit":     {strconv.Itoa(limit)},
+		"direction": {"backward"},
+	}
+	var data struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"result"`
+	}
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	if err := c.get(ctx, "/loki/api/v1/query_range", args, &data); err != nil {
+		return nil, err
+	}
+	if data.ResultType != "streams" {
+		return nil, fmt.Errorf("unexpected result type: %s", data.ResultType)
+	}
+	var res []Entry
+	for _, s := range data.Result {
+		for _, v := range s.Values {
+			ns, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp: %s", v[0])
+			}
+			res = append(res, Entry{Timestamp: time.Unix(0, ns), Labels: s.Stream, Line: v[1]})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Timestamp.After(res[j].Timestamp)
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (c *Loki) get(ctx context.Context, path string, args url.Values, res any) error {
+	u := c.url + path
+	if len(args) > 0 {
+		u += "?" + args.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if c.tenantId != "" {
+		req.Header.Set("X-Scope-OrgID", c.tenantId)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var r struct {
+		Status string          `json:"status"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return err
+	}
+	if r.Status != "success" {
+		return fmt.Errorf("unexpected status: %s", r.Status)
+	}
+	return json.Unmarshal(r.Data, res)
+}
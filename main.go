@@ -2,19 +2,29 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"github.com/coroot/coroot/api"
 	"github.com/coroot/coroot/cache"
 	"github.com/coroot/coroot/cloud-pricing"
 	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/i18n"
 	"github.com/coroot/coroot/notifications"
 	"github.com/coroot/coroot/prom"
 	"github.com/coroot/coroot/stats"
 	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/tsdb"
 	"github.com/coroot/coroot/utils"
+	"github.com/coroot/coroot/watchers/cloudwatch"
 	"github.com/coroot/coroot/watchers/deployments"
+	"github.com/coroot/coroot/watchers/digests"
+	"github.com/coroot/coroot/watchers/gcp"
 	"github.com/coroot/coroot/watchers/incidents"
+	"github.com/coroot/coroot/watchers/rds"
+	"github.com/coroot/coroot/watchers/thresholds"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"gopkg.in/alecthomas/kingpin.v2"
 	"k8s.io/klog"
 	"net/http"
@@ -41,7 +51,9 @@ func main() {
 	bootstrapRefreshInterval := kingpin.Flag("bootstrap-refresh-interval", "refresh interval for the project created upon bootstrap").Envar("BOOTSTRAP_REFRESH_INTERVAL").Duration()
 	bootstrapPrometheusExtraSelector := kingpin.Flag("bootstrap-prometheus-extra-selector", "Prometheus extra selector for the project created upon bootstrap").Envar("BOOTSTRAP_PROMETHEUS_EXTRA_SELECTOR").String()
 	sloCheckInterval := kingpin.Flag("slo-check-interval", "how often to check SLO compliance").Envar("SLO_CHECK_INTERVAL").Default("1m").Duration()
+	adaptiveThresholdsInterval := kingpin.Flag("adaptive-thresholds-interval", "how often to recalculate adaptive check thresholds").Envar("ADAPTIVE_THRESHOLDS_INTERVAL").Default("1h").Duration()
 	deploymentsWatchInterval := kingpin.Flag("deployments-watch-interval", "how often to check new deployments").Envar("DEPLOYMENTS_WATCH_INTERVAL").Default("1m").Duration()
+	digestsCheckInterval := kingpin.Flag("digests-check-interval", "how often to check whether scheduled digests are due").Envar("DIGESTS_CHECK_INTERVAL").Default("1m").Duration()
 	doNotCheckForUpdates := kingpin.Flag("do-not-check-for-updates", "don't check for new versions").Envar("DO_NOT_CHECK_FOR_UPDATES").Bool()
 	bootstrapPyroscopeUrl := kingpin.Flag("bootstrap-pyroscope-url", "if set, Coroot will add a Pyroscope integration for the default project").Envar("BOOTSTRAP_PYROSCOPE_URL").String()
 	bootstrapClickhouseAddr := kingpin.Flag("bootstrap-clickhouse-address", "if set, Coroot will add a Clickhouse integration for the default project").Envar("BOOTSTRAP_CLICKHOUSE_ADDRESS").String()
@@ -49,6 +61,13 @@ func main() {
 	bootstrapClickhousePassword := kingpin.Flag("bootstrap-clickhouse-password", "Clickhouse password").Envar("BOOTSTRAP_CLICKHOUSE_PASSWORD").String()
 	bootstrapClickhouseDatabase := kingpin.Flag("bootstrap-clickhouse-database", "Clickhouse database").Envar("BOOTSTRAP_CLICKHOUSE_DATABASE").Default("default").String()
 	bootstrapClickhouseTracesTable := kingpin.Flag("bootstrap-clickhouse-traces-table", "Clickhouse traces table").Envar("BOOTSTRAP_CLICKHOUSE_TRACES_TABLE").Default("otel_traces").String()
+	configFile := kingpin.Flag("config", "path to a YAML document with the configuration of projects to import at startup").Envar("CONFIG").String()
+	embeddedTsdb := kingpin.Flag("embedded-tsdb", "enable the embedded TSDB the agents can push metrics to using the Prometheus remote-write protocol").Envar("EMBEDDED_TSDB").Bool()
+	embeddedTsdbRetention := kingpin.Flag("embedded-tsdb-retention", "how long to keep the metrics in the embedded TSDB").Envar("EMBEDDED_TSDB_RETENTION").Default("168h").Duration()
+	rdsCollectInterval := kingpin.Flag("rds-collect-interval", "how often to collect the metrics of RDS instances for the projects with the AWS integration (requires the embedded TSDB)").Envar("RDS_COLLECT_INTERVAL").Default("1m").Duration()
+	gcpCollectInterval := kingpin.Flag("gcp-collect-interval", "how often to collect the metrics of Cloud SQL and Memorystore instances for the projects with the GCP integration (requires the embedded TSDB)").Envar("GCP_COLLECT_INTERVAL").Default("1m").Duration()
+	cloudwatchPollInterval := kingpin.Flag("cloudwatch-poll-interval", "how often to poll the CloudWatch metrics of the AWS resources tagged with applications (requires the embedded TSDB)").Envar("CLOUDWATCH_POLL_INTERVAL").Default("1m").Duration()
+	worldMemoryLimit := kingpin.Flag("world-memory-limit", "max memory for the time series of a single project world, e.g. 512MB (0 means no limit); if exceeded, data is loaded with a coarser step").Envar("WORLD_MEMORY_LIMIT").Default("0").Bytes()
 
 	kingpin.Version(version)
 	kingpin.Parse()
@@ -64,10 +83,13 @@ func main() {
 		klog.Exitln(err)
 	}
 
+	importConfig(database, *configFile)
 	bootstrapPrometheus(database, *bootstrapPrometheusUrl, *bootstrapRefreshInterval, *bootstrapPrometheusExtraSelector)
 	bootstrapPyroscope(database, *bootstrapPyroscopeUrl)
 	bootstrapClickhouse(database, *bootstrapClickhouseAddr, *bootstrapClickhouseUser, *bootstrapClickhousePassword, *bootstrapClickhouseDatabase, *bootstrapClickhouseTracesTable)
 
+	memoryLimit := int64(*worldMemoryLimit)
+
 	cacheConfig := cache.Config{
 		Path: path.Join(*dataDir, "cache"),
 		GC: &cache.GcConfig{
@@ -75,6 +97,14 @@ func main() {
 			Interval: *cacheGcInterval,
 		},
 	}
+	var storage *tsdb.Storage
+	if *embeddedTsdb {
+		storage, err = tsdb.NewStorage(tsdb.Config{Path: path.Join(*dataDir, "tsdb"), Retention: *embeddedTsdbRetention})
+		if err != nil {
+			klog.Exitln(err)
+		}
+		cacheConfig.TSDB = storage
+	}
 	promCache, err := cache.NewCache(cacheConfig, database)
 	if err != nil {
 		klog.Exitln(err)
@@ -89,45 +119,133 @@ func main() {
 
 	var statsCollector *stats.Collector
 	if !*disableStats {
-		statsCollector = stats.NewCollector(instanceUuid, version, database, promCache, pricing)
+		statsCollector = stats.NewCollector(instanceUuid, version, database, promCache, pricing, memoryLimit)
 	}
 
+	a := api.NewApi(promCache, database, pricing, storage, *readOnly, memoryLimit)
+
 	notifier := notifications.NewIncidentNotifier(database)
 
 	if *sloCheckInterval > 0 {
-		incidents.NewWatcher(database, promCache, notifier).Start(*sloCheckInterval)
+		incidents.NewWatcher(database, promCache, notifier, notifications.NewWebhookNotifier(database), notifications.NewAlertmanagerNotifier(), memoryLimit).Start(*sloCheckInterval)
 	}
 
 	if *deploymentsWatchInterval > 0 {
-		deployments.NewWatcher(database, promCache, pricing).Start(*deploymentsWatchInterval)
+		deployments.NewWatcher(database, promCache, pricing, memoryLimit).Start(*deploymentsWatchInterval)
 	}
 
-	a := api.NewApi(promCache, database, pricing, *readOnly)
+	if *digestsCheckInterval > 0 {
+		digests.NewWatcher(database, promCache, pricing, memoryLimit).Start(*digestsCheckInterval)
+	}
+
+	if *adaptiveThresholdsInterval > 0 {
+		thresholds.NewWatcher(database, promCache, memoryLimit, a.InvalidateReports).Start(*adaptiveThresholdsInterval)
+	}
+
+	if *rdsCollectInterval > 0 {
+		rds.NewWatcher(database, storage).Start(*rdsCollectInterval)
+	}
+	if *gcpCollectInterval > 0 {
+		gcp.NewWatcher(database, storage).Start(*gcpCollectInterval)
+	}
+	if *cloudwatchPollInterval > 0 {
+		cloudwatch.NewWatcher(database, storage).Start(*cloudwatchPollInterval)
+	}
 
 	router := mux.NewRouter()
 	router.PathPrefix("/debug/pprof/").Handler(http.DefaultServeMux)
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet)
+	router.HandleFunc("/opentelemetry.proto.collector.metrics.v1.MetricsService/Export", a.OtlpMetricsGrpc).Methods(http.MethodPost)
 
 	r := router
 	cleanUrlBasePath(urlBasePath)
 	if *urlBasePath != "/" {
 		r = router.PathPrefix(strings.TrimRight(*urlBasePath, "/")).Subrouter()
 	}
+	r.Use(a.InvalidateReportCache)
 	r.HandleFunc("/api/projects", a.Projects).Methods(http.MethodGet)
+	r.HandleFunc("/api/global", a.Global).Methods(http.MethodGet)
+	r.HandleFunc("/api/config", a.Config).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/", a.Project).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}", a.Project).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
 	r.HandleFunc("/api/project/{project}/status", a.Status).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/overview/{view}", a.Overview).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/search", a.Search).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/graphql", a.GraphQL).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/grafana/", a.Grafana).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/grafana/{method}", a.Grafana).Methods(http.MethodPost)
+	r.HandleFunc("/api/project/{project}/embed", a.EmbedLinks).Methods(http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/app/{app}/embed", a.EmbedLinks).Methods(http.MethodPost)
+	r.HandleFunc("/api/embed/{token}", a.Embed).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/status_pages", a.StatusPages).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/status_pages/{id}", a.StatusPages).Methods(http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/status/{project}/{id}", a.StatusPage).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/configs", a.Configs).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/check/{check}/overrides", a.CheckOverrides).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/categories", a.Categories).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/custom_checks", a.CustomChecks).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/custom_checks/{id}", a.CustomChecks).Methods(http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/log_checks", a.LogChecks).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/log_checks/{id}", a.LogChecks).Methods(http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/composite_checks", a.CompositeChecks).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/composite_checks/{id}", a.CompositeChecks).Methods(http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/check_templates", a.CheckTemplates).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/check_templates/{id}", a.CheckTemplates).Methods(http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/maintenance_windows", a.MaintenanceWindows).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/maintenance_windows/{id}", a.MaintenanceWindows).Methods(http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/annotations", a.Annotations).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/annotations/{id}", a.Annotations).Methods(http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/views", a.SavedViews).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/views/{id}", a.SavedViews).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/dashboards", a.Dashboards).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/dashboards/{id}", a.Dashboards).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/digests", a.Digests).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/digests/{id}", a.Digests).Methods(http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/webhooks", a.Webhooks).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/webhooks/{id}", a.Webhooks).Methods(http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/notification_templates", a.NotificationTemplates).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/escalation_policies", a.EscalationPolicies).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/escalation_policies/{id}", a.EscalationPolicies).Methods(http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/notification_routes", a.NotificationRoutes).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/notification_routes/{id}", a.NotificationRoutes).Methods(http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/on_call", a.OnCall).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/notification_limits", a.NotificationLimits).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/notification_deliveries", a.NotificationDeliveries).Methods(http.MethodGet)
+	r.HandleFunc("/api/slack/interactions", a.SlackInteractions).Methods(http.MethodPost)
+	r.HandleFunc("/api/project/{project}/incident/{incident}/ack", a.AcknowledgeIncident).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/repositories", a.Repositories).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/repositories/{id}", a.Repositories).Methods(http.MethodPost, http.MethodDelete)
 	r.HandleFunc("/api/project/{project}/integrations", a.Integrations).Methods(http.MethodGet, http.MethodPut)
 	r.HandleFunc("/api/project/{project}/integrations/{type}", a.Integration).Methods(http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/app/{app}", a.App).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/app/{app}/report/{report}/export/pdf", a.AppReportPDF).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/app/{app}/report/{report}/export/csv", a.AppReportCSV).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/app/{app}/report/{report}/table", a.AppReportTable).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/app/{app}/deployments/compare", a.CompareDeployments).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/app/{app}/owner", a.ApplicationOwner).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/app/{app}/check/{check}/config", a.Check).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/app/{app}/check/{check}/mutes", a.CheckMutes).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/app/{app}/check/{check}/mutes/{id}", a.CheckMutes).Methods(http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/app/{app}/check/{check}/history", a.CheckHistory).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/app/{app}/check/{check}/dry_run", a.CheckDryRun).Methods(http.MethodPost)
+	r.HandleFunc("/api/project/{project}/app/{app}/check/{check}/annotation", a.CheckAnnotation).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/app/{app}/profile", a.Profile).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/app/{app}/tracing", a.Tracing).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/app/{app}/logs", a.RawLogs).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/node/{node}", a.Node).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/project/{project}/app/{app}/reports", a.ReportsV1).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/project/{project}/app/{app}/deployment/verdict", a.DeploymentVerdictV1).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/project/{project}/app/{app}/deployments/{id}/verdict", a.DeploymentVerdictByIdV1).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/project/{project}/app/{app}/changes", a.ChangeV1).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/project/{project}/prometheus/rules", a.PrometheusRules).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/project/{project}/flux", a.FluxEventV1).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/project/{project}/ci/{provider}", a.CIEventV1).Methods(http.MethodPost)
+	r.HandleFunc("/api/project/{project}/node/{node}/export/pdf", a.NodeReportPDF).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/ingestion_key", a.IngestionKey).Methods(http.MethodGet, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/remote_write", a.RemoteWrite).Methods(http.MethodPost)
+	r.HandleFunc("/api/project/{project}/v1/traces", a.OtlpTraces).Methods(http.MethodPost)
+	r.HandleFunc("/api/project/{project}/v1/metrics", a.OtlpMetrics).Methods(http.MethodPost)
+	r.HandleFunc("/api/project/{project}/v1/logs", a.OtlpLogs).Methods(http.MethodPost)
 	r.PathPrefix("/api/project/{project}/prom").HandlerFunc(a.Prom)
 
 	r.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
@@ -144,7 +262,7 @@ func main() {
 	router.PathPrefix("").Handler(http.RedirectHandler(*urlBasePath, http.StatusMovedPermanently))
 
 	klog.Infoln("listening on", *listen)
-	klog.Fatalln(http.ListenAndServe(*listen, router))
+	klog.Fatalln(http.ListenAndServe(*listen, h2c.NewHandler(router, &http2.Server{}))) // h2c is required by the OTLP/gRPC receiver
 }
 
 type Options struct {
@@ -152,6 +270,7 @@ type Options struct {
 	Version         string
 	Uuid            string
 	CheckForUpdates bool
+	Languages       string
 }
 
 func readIndexHtml(basePath, version, instanceUuid string, checkForUpdates bool) []byte {
@@ -159,12 +278,17 @@ func readIndexHtml(basePath, version, instanceUuid string, checkForUpdates bool)
 	if err != nil {
 		klog.Exitln(err)
 	}
+	languages, err := json.Marshal(i18n.Languages)
+	if err != nil {
+		klog.Exitln(err)
+	}
 	buf := bytes.Buffer{}
 	err = tpl.Execute(&buf, Options{
 		BasePath:        basePath,
 		Version:         version,
 		Uuid:            instanceUuid,
 		CheckForUpdates: checkForUpdates,
+		Languages:       string(languages),
 	})
 	if err != nil {
 		klog.Exitln(err)
@@ -230,6 +354,24 @@ func bootstrapPrometheus(database *db.DB, url string, refreshInterval time.Durat
 	}
 }
 
+func importConfig(database *db.DB, path string) {
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		klog.Exitln(err)
+	}
+	var cfg db.Config
+	if err := utils.UnmarshalYaml(data, &cfg); err != nil {
+		klog.Exitln("invalid config:", err)
+	}
+	if err := database.ImportConfig(&cfg); err != nil {
+		klog.Exitln(err)
+	}
+	klog.Infof("imported the configuration of %d projects from %s", len(cfg.Projects), path)
+}
+
 func bootstrapPyroscope(database *db.DB, url string) {
 	if url == "" {
 		return
@@ -21,6 +21,9 @@ var (
 		{LongWindow: 3 * timeseries.Day, ShortWindow: 6 * timeseries.Hour, BurnRateThreshold: 1, Severity: WARNING},
 	}
 	MaxAlertRuleWindow timeseries.Duration
+
+	FastBurnAlertRules []AlertRule
+	SlowBurnAlertRules []AlertRule
 )
 
 func init() {
@@ -29,6 +32,11 @@ func init() {
 		if r.ShortWindow > r.LongWindow {
 			panic("invalid rule")
 		}
+		if r.Severity == CRITICAL {
+			FastBurnAlertRules = append(FastBurnAlertRules, r)
+		} else {
+			SlowBurnAlertRules = append(SlowBurnAlertRules, r)
+		}
 		if r.LongWindow > MaxAlertRuleWindow {
 			MaxAlertRuleWindow = r.LongWindow
 		}
@@ -47,6 +55,10 @@ func (br BurnRate) FormatSLOStatus() string {
 }
 
 func CheckBurnRates(now timeseries.Time, bad, total *timeseries.TimeSeries, objectivePercentage float32) BurnRate {
+	return CheckBurnRatesWithRules(AlertRules, now, bad, total, objectivePercentage)
+}
+
+func CheckBurnRatesWithRules(rules []AlertRule, now timeseries.Time, bad, total *timeseries.TimeSeries, objectivePercentage float32) BurnRate {
 	if bad.IsEmpty() || total.IsEmpty() {
 		return BurnRate{Severity: UNKNOWN}
 	}
@@ -63,7 +75,7 @@ func CheckBurnRates(now timeseries.Time, bad, total *timeseries.TimeSeries, obje
 	}
 
 	first := BurnRate{}
-	for _, r := range AlertRules {
+	for _, r := range rules {
 		from := now.Add(-r.LongWindow)
 		br := sumFrom(bad, from) / sumFrom(total, from) / objective
 		if timeseries.IsNaN(br) {
@@ -89,3 +101,22 @@ func CheckBurnRates(now timeseries.Time, bad, total *timeseries.TimeSeries, obje
 	first.Severity = OK
 	return first
 }
+
+// ErrorBudgetRemaining returns the percentage of the error budget remaining at each point of the series
+// assuming that the budget is calculated from the beginning of the series.
+func ErrorBudgetRemaining(bad, total *timeseries.TimeSeries, objectivePercentage float32) *timeseries.TimeSeries {
+	objective := 1 - objectivePercentage/100
+	var badSum, totalSum float32
+	return timeseries.Aggregate2(bad, total, func(b, t float32) float32 {
+		if !timeseries.IsNaN(t) {
+			totalSum += t
+			if !timeseries.IsNaN(b) {
+				badSum += b
+			}
+		}
+		if totalSum == 0 {
+			return timeseries.NaN
+		}
+		return 100 - badSum/totalSum/objective*100
+	})
+}
@@ -18,12 +18,28 @@ type Application struct {
 
 	DesiredInstances *timeseries.TimeSeries
 
+	// UpdateRevisions and UpdatedInstances describe the rollouts of StatefulSets, whose pods keep their names,
+	// so the revisions can't be told apart by the lifespans of the pods.
+	UpdateRevisions  map[string]*timeseries.TimeSeries
+	UpdatedInstances *timeseries.TimeSeries
+
 	LatencySLIs      []*LatencySLI
 	AvailabilitySLIs []*AvailabilitySLI
 
 	Events      []*ApplicationEvent
 	Deployments []*ApplicationDeployment
 	Incidents   []*ApplicationIncident
+	CheckTrends []CheckTrend
+
+	ErrorBudgetLedger []ErrorBudgetRecord
+
+	Owner *ApplicationOwner
+
+	HelmRevisions []HelmReleaseRevision
+
+	UpstreamRttBaselines map[ApplicationId]float32
+
+	Aws *AwsResources
 
 	Status  Status
 	Reports []*AuditReport
@@ -82,6 +98,10 @@ func (app *Application) Labels() Labels {
 	switch app.Id.Kind {
 	case ApplicationKindRds:
 		res["db"] = fmt.Sprintf(`%s (RDS)`, app.Instances[0].Rds.Engine.Value())
+	case ApplicationKindCloudSql:
+		res["db"] = fmt.Sprintf(`%s (Cloud SQL)`, app.Instances[0].Gcp.Engine.Value())
+	case ApplicationKindMemorystore:
+		res["db"] = "redis (Memorystore)"
 	case ApplicationKindUnknown:
 		res["instances"] = strconv.Itoa(len(app.Instances))
 	case ApplicationKindExternalService:
@@ -203,6 +223,19 @@ func (app *Application) GetClientsConnections() map[ApplicationId][]*Connection
 	return res
 }
 
+func (app *Application) GetUpstreamsConnections() map[ApplicationId][]*Connection {
+	res := map[ApplicationId][]*Connection{}
+	for _, i := range app.Instances {
+		for _, u := range i.Upstreams {
+			if u.RemoteInstance == nil || u.RemoteInstance.OwnerId == app.Id {
+				continue
+			}
+			res[u.RemoteInstance.OwnerId] = append(res[u.RemoteInstance.OwnerId], u)
+		}
+	}
+	return res
+}
+
 func (app *Application) AddReport(name AuditReportName, widgets ...*Widget) {
 	app.Reports = append(app.Reports, &AuditReport{Name: name, Widgets: widgets})
 }
@@ -16,6 +16,9 @@ const (
 	ApplicationDeploymentMetricsSnapshotShift  = 10 * timeseries.Minute
 	ApplicationDeploymentMetricsSnapshotWindow = 20 * timeseries.Minute
 	ApplicationDeploymentMinLifetime           = ApplicationDeploymentMetricsSnapshotShift + ApplicationDeploymentMetricsSnapshotWindow
+
+	// ApplicationDeploymentSyncTolerance is the maximum time between a GitOps sync and the rollout it caused.
+	ApplicationDeploymentSyncTolerance = 5 * timeseries.Minute
 )
 
 type ApplicationDeploymentState int
@@ -27,6 +30,36 @@ const (
 	ApplicationDeploymentStateCancelled
 	ApplicationDeploymentStateDeployed
 	ApplicationDeploymentStateSummary
+	ApplicationDeploymentStateRollbackRecommended
+)
+
+func (s ApplicationDeploymentState) String() string {
+	switch s {
+	case ApplicationDeploymentStateStarted:
+		return "started"
+	case ApplicationDeploymentStateInProgress:
+		return "in progress"
+	case ApplicationDeploymentStateStuck:
+		return "stuck"
+	case ApplicationDeploymentStateCancelled:
+		return "cancelled"
+	case ApplicationDeploymentStateDeployed:
+		return "deployed"
+	case ApplicationDeploymentStateSummary:
+		return "summary"
+	case ApplicationDeploymentStateRollbackRecommended:
+		return "rollback recommended"
+	}
+	return "unknown"
+}
+
+// DeploymentVerdict tells CD systems (e.g., Argo Rollouts or Flagger) whether to proceed with a rollout.
+type DeploymentVerdict string
+
+const (
+	DeploymentVerdictPass         DeploymentVerdict = "pass"
+	DeploymentVerdictFail         DeploymentVerdict = "fail"
+	DeploymentVerdictInconclusive DeploymentVerdict = "inconclusive"
 )
 
 type ApplicationDeployment struct {
@@ -51,6 +84,18 @@ func (d *ApplicationDeployment) Id() string {
 }
 
 func (d *ApplicationDeployment) Version() string {
+	if d.IsChange() {
+		return d.Details.Change.Type + ": " + d.Details.Change.Description
+	}
+	if d.IsTrafficShift() {
+		return d.Details.TrafficShift.String()
+	}
+	if d.IsConfigOnly() {
+		if d.Details.Sync != nil {
+			return "config: " + d.Details.Sync.ShortRevision()
+		}
+		return "helm: " + d.Details.Helm.String()
+	}
 	res := d.Hash()
 	if d.Details != nil && len(d.Details.ContainerImages) > 0 {
 		var images []string
@@ -59,11 +104,160 @@ func (d *ApplicationDeployment) Version() string {
 		}
 		res += ": " + strings.Join(images, ", ")
 	}
+	if d.Details != nil && d.Details.Helm != nil {
+		res += " (helm: " + d.Details.Helm.String() + ")"
+	}
 	return res
 }
 
+// IsConfigOnly reports whether the deployment was recorded from a GitOps sync or a Helm release upgrade
+// that didn't roll out a new ReplicaSet.
+func (d *ApplicationDeployment) IsConfigOnly() bool {
+	return d.Details != nil && d.Details.ConfigOnly && (d.Details.Sync != nil || d.Details.Helm != nil)
+}
+
+// IsTrafficShift reports whether the deployment is a shift of traffic between variants of a service
+// (e.g., a blue/green cutover) rather than a rollout.
+func (d *ApplicationDeployment) IsTrafficShift() bool {
+	return d.Details != nil && d.Details.TrafficShift != nil
+}
+
+// IsChange reports whether the deployment is a change recorded through the API rather than a rollout.
+func (d *ApplicationDeployment) IsChange() bool {
+	return d.Details != nil && d.Details.Change != nil
+}
+
+// FindApplicationDeployment returns the deployment started within ApplicationDeploymentSyncTolerance of the given time.
+func FindApplicationDeployment(deployments []*ApplicationDeployment, t timeseries.Time) *ApplicationDeployment {
+	for _, d := range deployments {
+		if !d.StartedAt.Before(t.Add(-ApplicationDeploymentSyncTolerance)) && !d.StartedAt.After(t.Add(ApplicationDeploymentSyncTolerance)) {
+			return d
+		}
+	}
+	return nil
+}
+
 type ApplicationDeploymentDetails struct {
-	ContainerImages []string `json:"container_images"`
+	ContainerImages []string                   `json:"container_images"`
+	Sync            *ApplicationDeploymentSync `json:"sync,omitempty"`
+	ConfigOnly      bool                       `json:"config_only,omitempty"`
+	Change          *ApplicationChange         `json:"change,omitempty"`
+	Helm            *ApplicationDeploymentHelm `json:"helm,omitempty"`
+	Pipeline        *CIPipeline                `json:"pipeline,omitempty"`
+	TrafficShift    *ApplicationTrafficShift   `json:"traffic_shift,omitempty"`
+	// SchemaChanges are the schema changes applied around the deployment (nil if not calculated yet).
+	SchemaChanges *ApplicationDeploymentSchemaChanges `json:"schema_changes,omitempty"`
+	// Limits are the resource limits of the containers by name.
+	Limits map[string]*ApplicationDeploymentLimits `json:"limits,omitempty"`
+	// Commits are the commits between the previous and the current versions, newest first (nil if not fetched yet).
+	Commits []ApplicationDeploymentCommit `json:"commits"`
+}
+
+const (
+	// ApplicationChangeMaxAge is how far back a change can be recorded, older changes can't be compared
+	// with the previous versions since the metrics of that time may be gone.
+	ApplicationChangeMaxAge = 30 * timeseries.Day
+	// ApplicationChangeMaxClockSkew is how far in the future a change can be recorded.
+	ApplicationChangeMaxClockSkew = 5 * timeseries.Minute
+)
+
+// ApplicationChange is a change recorded through the API (e.g., a feature flag flip, a DB migration, or an infrastructure change).
+// It's treated like a deployment: shown in the Deployments report and on the charts, and compared with the previous version.
+type ApplicationChange struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Author      string `json:"author,omitempty"`
+	Url         string `json:"url,omitempty"`
+}
+
+// ApplicationTrafficShift is a change in the share of the traffic of a service received by the application
+// compared with the other variants of the service (see the deployments watcher).
+type ApplicationTrafficShift struct {
+	From        ApplicationId `json:"from"`
+	ShareBefore float32       `json:"share_before"`
+	ShareAfter  float32       `json:"share_after"`
+}
+
+// IsCutover reports whether all the traffic was switched to the application at once, as in blue/green deployments.
+func (s *ApplicationTrafficShift) IsCutover() bool {
+	return s.ShareBefore <= 0.1 && s.ShareAfter >= 0.9
+}
+
+func (s *ApplicationTrafficShift) String() string {
+	if s.IsCutover() {
+		return "cutover from " + s.From.Name
+	}
+	return fmt.Sprintf("traffic: %.0f%% → %.0f%%", s.ShareBefore*100, s.ShareAfter*100)
+}
+
+type ApplicationDeploymentLimits struct {
+	Cpu    float32 `json:"cpu,omitempty"`
+	Memory float32 `json:"memory,omitempty"`
+}
+
+type ApplicationDeploymentHelm struct {
+	Release    string `json:"release"`
+	Chart      string `json:"chart,omitempty"`
+	Revision   int    `json:"revision,omitempty"`
+	ValuesHash string `json:"values_hash,omitempty"`
+}
+
+func (h *ApplicationDeploymentHelm) String() string {
+	res := h.Chart
+	if res == "" {
+		res = h.Release
+	}
+	if h.Revision > 0 {
+		res += " r" + strconv.Itoa(h.Revision)
+	}
+	return res
+}
+
+type ApplicationDeploymentCommit struct {
+	Sha     string          `json:"sha"`
+	Message string          `json:"message"`
+	Author  string          `json:"author"`
+	Time    timeseries.Time `json:"time,omitempty"`
+	Url     string          `json:"url,omitempty"`
+}
+
+// ApplicationDeploymentSync is the GitOps sync operation (e.g., of an ArgoCD Application or a Flux Kustomization)
+// that caused the deployment.
+// Author and Message describe the commit, InitiatedBy is the user who triggered the sync (empty for automated syncs).
+type ApplicationDeploymentSync struct {
+	Application string `json:"application"`
+	Revision    string `json:"revision"`
+	Status      string `json:"status"`
+	InitiatedBy string `json:"initiated_by"`
+	Automated   bool   `json:"automated"`
+	Author      string `json:"author"`
+	Message     string `json:"message"`
+}
+
+// ShortRevision abbreviates commit SHAs; other revisions (e.g., Helm chart versions) are returned as is.
+func (s *ApplicationDeploymentSync) ShortRevision() string {
+	if len(s.Revision) >= 40 {
+		return s.Revision[:7]
+	}
+	return s.Revision
+}
+
+// Trigger returns who or what triggered the sync.
+func (s *ApplicationDeploymentSync) Trigger() string {
+	switch {
+	case s.InitiatedBy != "":
+		return s.InitiatedBy
+	case s.Automated:
+		return "auto-sync"
+	}
+	return s.Author
+}
+
+func (c ApplicationDeploymentCommit) ShortSha() string {
+	if len(c.Sha) > 7 {
+		return c.Sha[:7]
+	}
+	return c.Sha
 }
 
 type MetricsSnapshot struct {
@@ -80,6 +274,10 @@ type MetricsSnapshot struct {
 	OOMKills    int64   `json:"oom_kills"`
 	LogErrors   int64   `json:"log_errors"`
 	LogWarnings int64   `json:"log_warnings"`
+
+	// The stats of the per-step values compared with the previous deployment (absent in the snapshots taken by earlier versions).
+	CPUUsagePerRequest  *timeseries.WindowStats `json:"cpu_usage_per_request,omitempty"`
+	LogErrorsPerRequest *timeseries.WindowStats `json:"log_errors_per_request,omitempty"`
 }
 
 type ApplicationDeploymentNotifications struct {
@@ -92,6 +290,21 @@ type ApplicationDeploymentNotifications struct {
 	Teams struct {
 		State ApplicationDeploymentState `json:"state"`
 	} `json:"teams"`
+	Telegram struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"telegram"`
+	Discord struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"discord"`
+	Mattermost struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"mattermost"`
+	Email struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"email"`
+	Webhooks struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"webhooks"`
 }
 
 type ApplicationDeploymentSummary struct {
@@ -114,6 +327,7 @@ type ApplicationDeploymentStatus struct {
 	Message    string
 	Lifetime   timeseries.Duration
 	Summary    []ApplicationDeploymentSummary
+	Canary     *CanaryAnalysis
 	Deployment *ApplicationDeployment
 }
 
@@ -140,10 +354,30 @@ func CalcApplicationDeploymentStatuses(app *Application, checkConfigs CheckConfi
 				}
 			}
 			s.Summary, s.Status = CalcApplicationDeploymentSummary(app, checkConfigs, d.StartedAt, d.MetricsSnapshot, prev)
+			if last && prev != nil && s.Status >= CRITICAL {
+				// rolling back makes sense only if the previous version met the objectives
+				if _, prevStatus := CalcApplicationDeploymentSummary(app, checkConfigs, d.StartedAt, prev, nil); prevStatus < CRITICAL {
+					s.State = ApplicationDeploymentStateRollbackRecommended
+					s.Message = "Rolling back to the previous version is recommended"
+					if d.IsTrafficShift() {
+						s.Message = fmt.Sprintf("Shifting the traffic back to %s is recommended", d.Details.TrafficShift.From.Name)
+					}
+				}
+			}
+			if last {
+				for _, t := range app.CheckTrends {
+					if t.Degrading() {
+						s.Summary = append(s.Summary, ApplicationDeploymentSummary{Report: t.Report, Message: t.String(), Time: d.StartedAt})
+					}
+				}
+			}
 		case !d.FinishedAt.IsZero():
 			s.Status = OK
 			s.State = ApplicationDeploymentStateDeployed
 			s.Message = "The service has been successfully deployed"
+			if d.IsTrafficShift() {
+				s.Message = "The traffic has been shifted"
+			}
 		case !last:
 			s.Status = WARNING
 			s.State = ApplicationDeploymentStateCancelled
@@ -152,21 +386,96 @@ func CalcApplicationDeploymentStatuses(app *Application, checkConfigs CheckConfi
 			s.Status = CRITICAL
 			s.State = ApplicationDeploymentStateStuck
 			s.Message = fmt.Sprintf("The rollout has been in progress for over %s", utils.FormatDuration(durationThreshold, 1))
+			if progress := rolloutProgress(app, d); progress != "" {
+				s.Message += ": " + progress
+			}
 		default:
 			s.Status = WARNING
 			s.State = ApplicationDeploymentStateInProgress
 			s.Message = "The rollout is in progress"
+			if progress := rolloutProgress(app, d); progress != "" {
+				s.Message += ": " + progress
+			}
 		}
 		res = append(res, s)
 	}
 	return res
 }
 
+// rolloutProgress describes how many pods (or nodes, for DaemonSets) run the revision of the deployment.
+// Partitioned rollouts of StatefulSets look like stuck ones, the progress tells which pods haven't been updated.
+func rolloutProgress(app *Application, d *ApplicationDeployment) string {
+	updated, total := 0, 0
+	var pending []string
+	for _, i := range app.Instances {
+		if i.Pod == nil || i.Pod.Revision() == "" || i.IsObsolete() {
+			continue
+		}
+		total++
+		if i.Pod.Revision() == d.Name {
+			updated++
+			continue
+		}
+		name := i.Name
+		if app.Id.Kind == ApplicationKindDaemonSet && i.NodeName() != "" {
+			name = i.NodeName()
+		}
+		pending = append(pending, name)
+	}
+	if total == 0 {
+		return ""
+	}
+	unit := "pods"
+	if app.Id.Kind == ApplicationKindDaemonSet {
+		unit = "nodes"
+	}
+	res := fmt.Sprintf("%d of %d %s updated", updated, total, unit)
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		if len(pending) > 3 {
+			pending = append(pending[:3], "...")
+		}
+		res += fmt.Sprintf(" (pending: %s)", strings.Join(pending, ", "))
+	}
+	return res
+}
+
+// Verdict returns the verdict on the deployment and the reasons for it.
+// A rollout fails if a rollback is recommended, the rollout is stuck, or the canary analysis has failed.
+func (s ApplicationDeploymentStatus) Verdict() (DeploymentVerdict, []string) {
+	failed := func(summary []ApplicationDeploymentSummary) []string {
+		var res []string
+		for _, i := range summary {
+			if !i.Ok {
+				res = append(res, i.Message)
+			}
+		}
+		return res
+	}
+	switch s.State {
+	case ApplicationDeploymentStateRollbackRecommended:
+		return DeploymentVerdictFail, append([]string{s.Message}, failed(s.Summary)...)
+	case ApplicationDeploymentStateStuck:
+		return DeploymentVerdictFail, []string{s.Message}
+	case ApplicationDeploymentStateSummary:
+		return DeploymentVerdictPass, nil
+	case ApplicationDeploymentStateCancelled:
+		return DeploymentVerdictInconclusive, []string{s.Message}
+	}
+	if s.Canary == nil {
+		return DeploymentVerdictInconclusive, []string{"Not enough data to compare the new version with the previous one"}
+	}
+	if s.Canary.Status >= CRITICAL {
+		return DeploymentVerdictFail, failed(s.Canary.Summary)
+	}
+	return DeploymentVerdictPass, nil
+}
+
 func CalcApplicationDeploymentSummary(app *Application, checkConfigs CheckConfigs, t timeseries.Time, curr, prev *MetricsSnapshot) ([]ApplicationDeploymentSummary, Status) {
 	availabilityCfg, _ := checkConfigs.GetAvailability(app.Id)
 	latencyCfg, _ := checkConfigs.GetLatency(app.Id, app.Category)
 	memoryLeakThreshold := int64(checkConfigs.GetSimple(Checks.MemoryLeak.Id, app.Id).Threshold * 1024 * 1024)
-	significantPercentageDifference := 5.0
+	significantPercentageDifference := float32(5)
 
 	status := OK
 	var res []ApplicationDeploymentSummary
@@ -212,11 +521,9 @@ func CalcApplicationDeploymentSummary(app *Application, checkConfigs CheckConfig
 	}
 
 	// CPU
-	if prev != nil && curr.Requests > 0 && prev.Requests > 0 && curr.CPUUsage > 0 && prev.CPUUsage > 0 {
-		perRequestCurr := curr.CPUUsage / float32(curr.Requests)
-		perRequestPrev := prev.CPUUsage / float32(prev.Requests)
-		diffPercent := (perRequestCurr - perRequestPrev) * 100 / perRequestPrev
-		if math.Abs(float64(diffPercent)) > significantPercentageDifference {
+	if prev != nil {
+		diff := diffPerRequest(prev.CPUUsagePerRequest, curr.CPUUsagePerRequest, prev.CPUUsage, curr.CPUUsage, prev.Requests, curr.Requests, significantPercentageDifference)
+		if diff.Significant {
 			var totalPrice, count float32
 			for _, i := range app.Instances {
 				if i.Node == nil || i.Node.Price == nil {
@@ -229,10 +536,10 @@ func CalcApplicationDeploymentSummary(app *Application, checkConfigs CheckConfig
 			var costs string
 			if totalPrice > 0 {
 				prevAvgCpuUsage := prev.CPUUsage / float32(ApplicationDeploymentMetricsSnapshotWindow)
-				diffCosts := prevAvgCpuUsage * avgPricePerCpu * diffPercent / 100
+				diffCosts := prevAvgCpuUsage * avgPricePerCpu * diff.Change / 100
 				costs = fmt.Sprintf(" (%s/mo)", utils.FormatMoney(diffCosts*float32(timeseries.Month)))
 			}
-			add(AuditReportCPU, diffPercent < 0, "CPU usage: %+.f%%%s compared to the previous deployment", diffPercent, costs)
+			add(AuditReportCPU, diff.Change < 0, "CPU usage: %s%s compared to the previous deployment", diff, costs)
 		}
 	}
 
@@ -262,17 +569,15 @@ func CalcApplicationDeploymentSummary(app *Application, checkConfigs CheckConfig
 			if curr.LogErrors == 0 {
 				add(AuditReportLogs, true, "Logs: there are no more errors in the logs")
 			} else {
-				perRequestCurr := float32(curr.LogErrors) / float32(curr.Requests)
-				perRequestPrev := float32(prev.LogErrors) / float32(prev.Requests)
-				diff := (perRequestCurr - perRequestPrev) * 100 / perRequestPrev
-				if math.Abs(float64(diff)) > significantPercentageDifference {
+				diff := diffPerRequest(prev.LogErrorsPerRequest, curr.LogErrorsPerRequest, float32(prev.LogErrors), float32(curr.LogErrors), prev.Requests, curr.Requests, significantPercentageDifference)
+				if diff.Significant {
 					ok := false
 					verb := "increased"
-					if diff < 0 {
+					if diff.Change < 0 {
 						ok = true
 						verb = "decreased"
 					}
-					add(AuditReportLogs, ok, "Logs: the number of errors in the logs has %s by %d%%", verb, int(math.Abs(float64(diff))))
+					add(AuditReportLogs, ok, "Logs: the number of errors in the logs has %s by %d%%", verb, int(math.Abs(float64(diff.Change))))
 				}
 			}
 		}
@@ -284,6 +589,18 @@ func CalcApplicationDeploymentSummary(app *Application, checkConfigs CheckConfig
 	return res, status
 }
 
+// diffPerRequest compares the per-request values of two deployments: the stats of their per-step values
+// if both snapshots have them, otherwise the ratio of the totals.
+func diffPerRequest(prev, curr *timeseries.WindowStats, prevTotal, currTotal float32, prevRequests, currRequests int64, minChange float32) timeseries.Diff {
+	if prev != nil && curr != nil {
+		return timeseries.DiffStats(*prev, *curr, minChange)
+	}
+	if prevRequests <= 0 || currRequests <= 0 || prevTotal <= 0 || currTotal <= 0 {
+		return timeseries.Diff{Before: timeseries.NaN, After: timeseries.NaN, Change: timeseries.NaN}
+	}
+	return timeseries.Ratio(prevTotal/float32(prevRequests), currTotal/float32(currRequests), minChange)
+}
+
 type histogramBucket struct {
 	le    float32
 	count int64
@@ -305,13 +622,19 @@ func getFastRequestsCount(histogram map[string]int64, objectiveBucket float32) f
 	sort.Slice(buckets, func(i, j int) bool {
 		return buckets[i].le < buckets[j].le
 	})
-	res := timeseries.NaN
+	var lower histogramBucket
 	for _, b := range buckets {
 		if b.le <= objectiveBucket {
-			res = float32(b.count)
+			lower = b
 			continue
 		}
-		break
+		if timeseries.IsInf(b.le, 1) {
+			break
+		}
+		return interpolateFast(lower.le, float32(lower.count), b.le, float32(b.count), objectiveBucket)
 	}
-	return res
+	if lower.le == 0 {
+		return timeseries.NaN
+	}
+	return float32(lower.count)
 }
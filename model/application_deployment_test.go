@@ -0,0 +1,36 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCalcApplicationDeploymentSummaryPerRequest(t *testing.T) {
+	app := NewApplication(NewApplicationId("default", ApplicationKindDeployment, "cart"))
+	snapshot := func(cpu, logErrors timeseries.WindowStats) *MetricsSnapshot {
+		return &MetricsSnapshot{Requests: 1000, CPUUsage: 10, LogErrors: 10, CPUUsagePerRequest: &cpu, LogErrorsPerRequest: &logErrors}
+	}
+	prev := snapshot(timeseries.WindowStats{Mean: 0.01, StdDev: 0.001, Count: 30}, timeseries.WindowStats{Mean: 0.01, StdDev: 0.001, Count: 30})
+
+	curr := snapshot(timeseries.WindowStats{Mean: 0.013, StdDev: 0.001, Count: 30}, timeseries.WindowStats{Mean: 0.005, StdDev: 0.001, Count: 30})
+	summary, _ := CalcApplicationDeploymentSummary(app, CheckConfigs{}, 0, curr, prev)
+	var messages []string
+	for _, s := range summary {
+		messages = append(messages, s.Message)
+	}
+	assert.Equal(t, []string{
+		"CPU usage: +30% compared to the previous deployment",
+		"Logs: the number of errors in the logs has decreased by 50%",
+	}, messages)
+
+	noisy := snapshot(timeseries.WindowStats{Mean: 0.013, StdDev: 0.02, Count: 30}, timeseries.WindowStats{Mean: 0.005, StdDev: 0.02, Count: 30})
+	summary, _ = CalcApplicationDeploymentSummary(app, CheckConfigs{}, 0, noisy, prev)
+	assert.Empty(t, summary, "the changes are within the noise")
+
+	legacy := &MetricsSnapshot{Requests: 1000, CPUUsage: 13, LogErrors: 10}
+	summary, _ = CalcApplicationDeploymentSummary(app, CheckConfigs{}, 0, legacy, &MetricsSnapshot{Requests: 1000, CPUUsage: 10, LogErrors: 10})
+	if assert.Len(t, summary, 1) {
+		assert.Equal(t, "CPU usage: +30% compared to the previous deployment", summary[0].Message, "the snapshots without stats are compared by the totals")
+	}
+}
@@ -12,6 +12,8 @@ const (
 	ApplicationEventTypeRollout
 	ApplicationEventTypeInstanceDown
 	ApplicationEventTypeInstanceUp
+	ApplicationEventTypeChange
+	ApplicationEventTypeTrafficShift
 )
 
 type ApplicationEvent struct {
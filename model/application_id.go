@@ -42,6 +42,12 @@ func NewApplicationId(ns string, kind ApplicationKind, name string) ApplicationI
 	return ApplicationId{Namespace: ns, Kind: kind, Name: name}
 }
 
+// NewNamespaceScopeId returns an id matching all applications of the namespace.
+// It is used to store namespace-level check configs.
+func NewNamespaceScopeId(ns string) ApplicationId {
+	return ApplicationId{Namespace: ns}
+}
+
 func NewApplicationIdFromString(src string) (ApplicationId, error) {
 	parts := strings.SplitN(src, ":", 3)
 	if len(parts) < 3 {
@@ -54,6 +60,10 @@ func (a ApplicationId) IsZero() bool {
 	return a == ApplicationIdZero
 }
 
+func (a ApplicationId) IsNamespaceScope() bool {
+	return a.Namespace != "" && a.Kind == "" && a.Name == ""
+}
+
 func (a ApplicationId) String() string {
 	return fmt.Sprintf("%s:%s:%s", a.Namespace, a.Kind, a.Name)
 }
@@ -7,8 +7,16 @@ type ApplicationIncident struct {
 	OpenedAt   timeseries.Time
 	ResolvedAt timeseries.Time
 	Severity   Status
+
+	AcknowledgedAt timeseries.Time
+	AcknowledgedBy string
+	EscalationStep int
 }
 
 func (i *ApplicationIncident) Resolved() bool {
 	return !i.ResolvedAt.IsZero()
 }
+
+func (i *ApplicationIncident) Acknowledged() bool {
+	return !i.AcknowledgedAt.IsZero()
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	ApplicationOwnerAnnotationTeam         = "coroot.com/team"
+	ApplicationOwnerAnnotationSlackChannel = "coroot.com/slack-channel"
+	ApplicationOwnerAnnotationEscalation   = "coroot.com/escalation"
+	ApplicationOwnerAnnotationEmail        = "coroot.com/email"
+	ApplicationOwnerAnnotationPhone        = "coroot.com/phone"
+)
+
+var phoneNumberRe = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// ApplicationOwner describes the team responsible for an application and how to reach it.
+// Escalation is the name of the Opsgenie escalation the alerts are routed to.
+// Email is a comma-separated list of the addresses the email notifications are sent to.
+// Phone is a comma-separated list of the numbers (in the E.164 format) the SMS and voice notifications are sent to.
+type ApplicationOwner struct {
+	Team         string `json:"team"`
+	SlackChannel string `json:"slack_channel"`
+	Escalation   string `json:"escalation"`
+	Email        string `json:"email,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+}
+
+// NewApplicationOwnerFromAnnotations reads the ownership from the Kubernetes annotations
+// exported by kube-state-metrics as labels (e.g., annotation_coroot_com_team).
+func NewApplicationOwnerFromAnnotations(labels Labels) *ApplicationOwner {
+	get := func(annotation string) string {
+		name := "annotation_" + strings.NewReplacer(".", "_", "/", "_", "-", "_").Replace(annotation)
+		return strings.TrimSpace(labels[name])
+	}
+	o := &ApplicationOwner{
+		Team:         get(ApplicationOwnerAnnotationTeam),
+		SlackChannel: get(ApplicationOwnerAnnotationSlackChannel),
+		Escalation:   get(ApplicationOwnerAnnotationEscalation),
+		Email:        get(ApplicationOwnerAnnotationEmail),
+		Phone:        get(ApplicationOwnerAnnotationPhone),
+	}
+	if o.IsEmpty() {
+		return nil
+	}
+	return o
+}
+
+func (o *ApplicationOwner) IsEmpty() bool {
+	return o == nil || (o.Team == "" && o.SlackChannel == "" && o.Escalation == "" && o.Email == "" && o.Phone == "")
+}
+
+// WithTarget returns a copy of the owner with the recipient of the notification integration (slack, opsgenie, email, or twilio) replaced by target.
+func (o *ApplicationOwner) WithTarget(destination, target string) *ApplicationOwner {
+	if target == "" {
+		return o
+	}
+	res := ApplicationOwner{}
+	if o != nil {
+		res = *o
+	}
+	switch destination {
+	case "slack":
+		res.SlackChannel = target
+	case "opsgenie":
+		res.Escalation = target
+	case "email":
+		res.Email = target
+	case "twilio":
+		res.Phone = target
+	}
+	return &res
+}
+
+func (o *ApplicationOwner) Emails() []string {
+	if o == nil {
+		return nil
+	}
+	return SplitEmails(o.Email)
+}
+
+func (o *ApplicationOwner) Phones() []string {
+	if o == nil {
+		return nil
+	}
+	return ParsePhones(o.Phone)
+}
+
+// ValidatePhones checks that s is a list of phone numbers in the E.164 format (e.g., +14155552671).
+func ValidatePhones(s string) error {
+	for _, p := range ParsePhones(s) {
+		if !phoneNumberRe.MatchString(p) {
+			return fmt.Errorf("invalid phone number: %s", p)
+		}
+	}
+	return nil
+}
+
+// ParsePhones splits a list of phone numbers separated by commas, semicolons, or new lines.
+// The formatting characters (spaces, dashes, dots, and parentheses) are removed from the numbers,
+// e.g., "+1 (415) 555-2671" becomes "+14155552671", and the duplicates are skipped.
+func ParsePhones(s string) []string {
+	var res []string
+	seen := map[string]bool{}
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == '\n' || r == '\r' }) {
+		p = strings.Map(func(r rune) rune {
+			switch r {
+			case ' ', '\t', '-', '.', '(', ')':
+				return -1
+			}
+			return r
+		}, p)
+		if p != "" && !seen[p] {
+			seen[p] = true
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// SplitEmails splits a comma-separated list of email addresses.
+func SplitEmails(s string) []string {
+	var res []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			res = append(res, e)
+		}
+	}
+	return res
+}
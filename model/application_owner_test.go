@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApplicationOwnerIsEmpty(t *testing.T) {
+	for _, c := range []struct {
+		owner *ApplicationOwner
+		empty bool
+	}{
+		{owner: nil, empty: true},
+		{owner: &ApplicationOwner{}, empty: true},
+		{owner: &ApplicationOwner{Team: "payments"}, empty: false},
+		{owner: &ApplicationOwner{SlackChannel: "#payments"}, empty: false},
+		{owner: &ApplicationOwner{Escalation: "payments"}, empty: false},
+		{owner: &ApplicationOwner{Email: "oncall@example.com"}, empty: false},
+		{owner: &ApplicationOwner{Phone: "+15551234567"}, empty: false},
+	} {
+		assert.Equal(t, c.empty, c.owner.IsEmpty(), "%+v", c.owner)
+	}
+}
+
+func TestParsePhones(t *testing.T) {
+	assert.Nil(t, ParsePhones(""))
+	assert.Equal(t, []string{"+14155552671", "+442071838750"}, ParsePhones("+1 (415) 555-2671, +44 20.7183.8750"))
+	assert.Equal(t, []string{"+14155552671", "+14155552672"}, ParsePhones("+14155552671;\n+14155552672\r\n+1-415-555-2671,"))
+	assert.NoError(t, ValidatePhones("+1 415 555 2671; +14155552672"))
+	assert.Error(t, ValidatePhones("+14155552671, 4155552672"))
+	assert.Error(t, ValidatePhones("+1415555267x"))
+}
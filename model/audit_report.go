@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
 	"strings"
@@ -9,20 +10,24 @@ import (
 type AuditReportName string
 
 const (
-	AuditReportSLO         AuditReportName = "SLO"
-	AuditReportInstances   AuditReportName = "Instances"
-	AuditReportCPU         AuditReportName = "CPU"
-	AuditReportMemory      AuditReportName = "Memory"
-	AuditReportStorage     AuditReportName = "Storage"
-	AuditReportNetwork     AuditReportName = "Network"
-	AuditReportLogs        AuditReportName = "Logs"
-	AuditReportPostgres    AuditReportName = "Postgres"
-	AuditReportRedis       AuditReportName = "Redis"
-	AuditReportJvm         AuditReportName = "JVM"
-	AuditReportNode        AuditReportName = "Node"
-	AuditReportDeployments AuditReportName = "Deployments"
-	AuditReportProfiling   AuditReportName = "Profiling"
-	AuditReportTracing     AuditReportName = "Tracing"
+	AuditReportSLO          AuditReportName = "SLO"
+	AuditReportInstances    AuditReportName = "Instances"
+	AuditReportCPU          AuditReportName = "CPU"
+	AuditReportMemory       AuditReportName = "Memory"
+	AuditReportStorage      AuditReportName = "Storage"
+	AuditReportNetwork      AuditReportName = "Network"
+	AuditReportDependencies AuditReportName = "Dependencies"
+	AuditReportDNS          AuditReportName = "DNS"
+	AuditReportLogs         AuditReportName = "Logs"
+	AuditReportPostgres     AuditReportName = "Postgres"
+	AuditReportRedis        AuditReportName = "Redis"
+	AuditReportJvm          AuditReportName = "JVM"
+	AuditReportNode         AuditReportName = "Node"
+	AuditReportDeployments  AuditReportName = "Deployments"
+	AuditReportProfiling    AuditReportName = "Profiling"
+	AuditReportTracing      AuditReportName = "Tracing"
+	AuditReportCustom       AuditReportName = "Custom"
+	AuditReportAWS          AuditReportName = "AWS"
 )
 
 type AuditReport struct {
@@ -31,6 +36,7 @@ type AuditReport struct {
 	checkConfigs CheckConfigs
 
 	Name    AuditReportName `json:"name"`
+	Title   string          `json:"title,omitempty"`
 	Status  Status          `json:"status"`
 	Widgets []*Widget       `json:"widgets"`
 	Checks  []*Check        `json:"checks"`
@@ -57,6 +63,27 @@ func (c *AuditReport) GetOrCreateChartInGroup(title string, chartTitle string) *
 	return c.GetOrCreateChartGroup(title).GetOrCreateChart(c.ctx, chartTitle)
 }
 
+func (c *AuditReport) GetOrCreateChartInGroupByPath(title string, path ...string) *Chart {
+	return c.GetOrCreateChartGroup(title).GetOrCreateChartByPath(c.ctx, path...)
+}
+
+// FindChart returns the chart with the given title. Charts from groups are found by their full titles.
+func (c *AuditReport) FindChart(title string) *Chart {
+	for _, w := range c.Widgets {
+		if w.Chart != nil && w.Chart.Title == title {
+			return w.Chart
+		}
+		if cg := w.ChartGroup; cg != nil {
+			for _, ch := range cg.Charts {
+				if cg.ChartTitle(ch) == title {
+					return ch
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (c *AuditReport) GetOrCreateChart(title string) *Chart {
 	for _, w := range c.Widgets {
 		if ch := w.Chart; ch != nil {
@@ -83,6 +110,12 @@ func (c *AuditReport) GetOrCreateHeatmap(title string) *Heatmap {
 	return h
 }
 
+func (c *AuditReport) AddFlameGraph(title, profile, instance string, from, to timeseries.Time) *FlameGraph {
+	fg := &FlameGraph{ApplicationId: c.app.Id, Title: title, Profile: profile, Instance: instance, From: from, To: to}
+	c.Widgets = append(c.Widgets, &Widget{FlameGraph: fg, Width: "100%"})
+	return fg
+}
+
 func (c *AuditReport) GetOrCreateDependencyMap() *DependencyMap {
 	for _, w := range c.Widgets {
 		if w.DependencyMap != nil {
@@ -94,6 +127,10 @@ func (c *AuditReport) GetOrCreateDependencyMap() *DependencyMap {
 	return dm
 }
 
+func (c *AuditReport) AddTopology(t *Topology) {
+	c.Widgets = append(c.Widgets, &Widget{Topology: t, Width: "100%"})
+}
+
 func (c *AuditReport) GetOrCreateTable(header ...string) *Table {
 	for _, w := range c.Widgets {
 		if t := w.Table; t != nil {
@@ -131,8 +168,48 @@ func (c *AuditReport) CreateCheck(cfg CheckConfig) *Check {
 			1,
 		)
 	default:
-		ch.Threshold = c.checkConfigs.GetSimple(cfg.Id, c.app.Id).Threshold
+		if GetCheckConfig(cfg.Id) == nil { // user-defined check
+			ch.Threshold = cfg.DefaultThreshold
+			break
+		}
+		simple := c.checkConfigs.GetSimple(cfg.Id, c.app.Id)
+		ch.Threshold = simple.Threshold
+		ch.CriticalThreshold = simple.CriticalThreshold
+		ch.Severity = simple.Severity
+		ch.instances = simple.Instances
 	}
 	c.Checks = append(c.Checks, ch)
 	return ch
 }
+
+// Translate replaces the report title, check titles, table headers, units, and stub messages
+// with the strings returned by t. Stubs are translated by their format, so the arguments are preserved.
+func (c *AuditReport) Translate(t func(string) string) {
+	if title := t(string(c.Name)); title != string(c.Name) {
+		c.Title = title
+	}
+	for _, ch := range c.Checks {
+		ch.Title = t(ch.Title)
+	}
+	for _, w := range c.Widgets {
+		if w.Table == nil {
+			continue
+		}
+		for i, h := range w.Table.Header {
+			w.Table.Header[i] = t(h)
+		}
+		for _, r := range w.Table.Rows {
+			for _, cell := range r.Cells {
+				if cell == nil {
+					continue
+				}
+				if cell.Unit != "" {
+					cell.Unit = t(cell.Unit)
+				}
+				if cell.IsStub && cell.stubFormat != "" {
+					cell.Value = fmt.Sprintf(t(cell.stubFormat), cell.stubArgs...)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import "github.com/coroot/coroot/timeseries"
+
+// AwsResources are the managed AWS components of an application (mapped via tags), monitored using CloudWatch metrics.
+type AwsResources struct {
+	TargetGroups    map[string]*AwsTargetGroup
+	SqsQueues       map[string]*AwsSqsQueue
+	LambdaFunctions map[string]*AwsLambdaFunction
+}
+
+func NewAwsResources() *AwsResources {
+	return &AwsResources{
+		TargetGroups:    map[string]*AwsTargetGroup{},
+		SqsQueues:       map[string]*AwsSqsQueue{},
+		LambdaFunctions: map[string]*AwsLambdaFunction{},
+	}
+}
+
+type AwsTargetGroup struct {
+	LoadBalancer     string
+	Requests         *timeseries.TimeSeries
+	Errors           *timeseries.TimeSeries
+	ResponseTime     *timeseries.TimeSeries
+	HealthyTargets   *timeseries.TimeSeries
+	UnhealthyTargets *timeseries.TimeSeries
+}
+
+type AwsSqsQueue struct {
+	MessagesVisible  *timeseries.TimeSeries
+	OldestMessageAge *timeseries.TimeSeries
+	MessagesSent     *timeseries.TimeSeries
+	MessagesDeleted  *timeseries.TimeSeries
+}
+
+type AwsLambdaFunction struct {
+	Invocations *timeseries.TimeSeries
+	Errors      *timeseries.TimeSeries
+	Throttles   *timeseries.TimeSeries
+	Duration    *timeseries.TimeSeries
+}
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/dustin/go-humanize/english"
+)
+
+const (
+	// CanaryWindow is the window over which the instances of the new ReplicaSet are compared
+	// to the instances of the old one during a rollout.
+	CanaryWindow = 10 * timeseries.Minute
+
+	canaryMinWindow          = 2 * timeseries.Minute
+	canarySignificantChange  = float32(20) // percent
+	canaryErrorRateTolerance = float32(1)  // percentage points
+)
+
+// CanaryAnalysis is the result of comparing the instances of a deployment being rolled out (canary)
+// to the instances of the previous ReplicaSets running at the same time (baseline).
+type CanaryAnalysis struct {
+	Canary   int
+	Baseline int
+	Status   Status
+	Summary  []ApplicationDeploymentSummary
+}
+
+type canaryGroup struct {
+	instances []*Instance
+
+	requests  float32
+	errorRate float32
+	latency   float32
+	cpu       float32
+	restarts  float32
+	logErrors float32
+}
+
+// CalcCanaryAnalysis compares the latency, error rate, CPU usage, restarts, and log errors of the instances
+// of the deployment and of the other ReplicaSets (or controller revisions) within the window.
+// It returns nil if either group had no running instances within the window or the window is too short.
+func CalcCanaryAnalysis(app *Application, d *ApplicationDeployment, w timeseries.Window) *CanaryAnalysis {
+	if w.To.Sub(w.From) < canaryMinWindow {
+		return nil
+	}
+	canary, baseline := &canaryGroup{}, &canaryGroup{}
+	for _, i := range app.Instances {
+		if i.Pod == nil || i.Pod.Revision() == "" || nanToZero(w.Sum(i.UpAndRunning())) == 0 {
+			continue
+		}
+		if i.Pod.Revision() == d.Name {
+			canary.instances = append(canary.instances, i)
+		} else {
+			baseline.instances = append(baseline.instances, i)
+		}
+	}
+	if len(canary.instances) == 0 || len(baseline.instances) == 0 {
+		return nil
+	}
+	connections := instanceConnections(app)
+	canary.calc(connections, w)
+	baseline.calc(connections, w)
+
+	res := &CanaryAnalysis{Canary: len(canary.instances), Baseline: len(baseline.instances), Status: OK}
+	var items []ApplicationDeploymentSummary
+	add := func(r AuditReportName, status Status, format string, a ...any) {
+		items = append(items, ApplicationDeploymentSummary{Report: r, Ok: status == OK, Message: fmt.Sprintf(format, a...), Time: w.To})
+		if status > res.Status {
+			res.Status = status
+		}
+	}
+
+	if canary.requests > 0 && baseline.requests > 0 {
+		if canary.errorRate-baseline.errorRate > canaryErrorRateTolerance {
+			add(AuditReportSLO, CRITICAL, "Errors: %s of requests failed (%s on the previous version)",
+				utils.FormatPercentage(canary.errorRate), utils.FormatPercentage(baseline.errorRate))
+		}
+		if diff := timeseries.Ratio(baseline.latency, canary.latency, canarySignificantChange); diff.Significant && diff.Change > 0 {
+			add(AuditReportSLO, CRITICAL, "Latency: %sms on average, %s compared to the previous version",
+				utils.FormatFloat(canary.latency*1000), diff)
+		}
+	}
+	if diff := timeseries.Ratio(baseline.cpu, canary.cpu, canarySignificantChange); diff.Significant && diff.Change > 0 {
+		add(AuditReportCPU, WARNING, "CPU usage per instance: %s compared to the previous version", diff)
+	}
+	if canary.restarts > 0 {
+		add(AuditReportInstances, CRITICAL, "Crash: new containers have been restarted %s", english.Plural(int(canary.restarts), "time", ""))
+	}
+	if canary.logErrors > 0 {
+		if diff := timeseries.Ratio(baseline.logErrors, canary.logErrors, canarySignificantChange); baseline.logErrors == 0 || (diff.Significant && diff.Change > 0) {
+			add(AuditReportLogs, CRITICAL, "Logs: new instances write more errors than the previous version")
+		}
+	}
+
+	verdict := "passed"
+	if res.Status >= CRITICAL {
+		verdict = "failed"
+	}
+	res.Summary = append(res.Summary, ApplicationDeploymentSummary{
+		Report:  AuditReportInstances,
+		Ok:      res.Status == OK,
+		Message: fmt.Sprintf("Canary %s: %d new vs %d old instances over the last %s", verdict, res.Canary, res.Baseline, utils.FormatDuration(w.To.Sub(w.From), 1)),
+		Time:    w.To,
+	})
+	res.Summary = append(res.Summary, items...)
+	return res
+}
+
+// CalcCanary runs the canary analysis of the deployment if it's being rolled out or has been rolled out recently,
+// so that the outcome is known before the metrics snapshot is taken (ApplicationDeploymentMinLifetime).
+// The instances are compared within the last CanaryWindow of the rollout before the given time.
+func (s *ApplicationDeploymentStatus) CalcCanary(app *Application, to timeseries.Time) {
+	switch s.State {
+	case ApplicationDeploymentStateInProgress, ApplicationDeploymentStateStuck, ApplicationDeploymentStateDeployed:
+	default:
+		return
+	}
+	d := s.Deployment
+	w := timeseries.Window{To: to}
+	if !d.FinishedAt.IsZero() && d.FinishedAt.Before(w.To) {
+		w.To = d.FinishedAt
+	}
+	w.From = w.To.Add(-CanaryWindow)
+	if d.StartedAt.After(w.From) {
+		w.From = d.StartedAt
+	}
+	s.Canary = CalcCanaryAnalysis(app, d, w)
+}
+
+func (g *canaryGroup) calc(connections map[*Instance][]*Connection, w timeseries.Window) {
+	var conns []*Connection
+	cpu := timeseries.NewAggregate(timeseries.NanSum)
+	restarts := timeseries.NewAggregate(timeseries.NanSum)
+	logErrors := timeseries.NewAggregate(timeseries.NanSum)
+	for _, i := range g.instances {
+		conns = append(conns, connections[i]...)
+		for _, c := range i.Containers {
+			cpu.Add(c.CpuUsage)
+			restarts.Add(c.Restarts)
+		}
+		for level, ts := range i.LogMessagesByLevel {
+			if level == LogLevelCritical || level == LogLevelError {
+				logErrors.Add(ts)
+			}
+		}
+	}
+	n := float32(len(g.instances))
+	g.requests = w.Mean(GetConnectionsRequestsSum(conns))
+	if errors := w.Mean(GetConnectionsErrorsSum(conns)); g.requests > 0 {
+		g.errorRate = nanToZero(errors) * 100 / g.requests
+	}
+	g.latency = w.Mean(GetConnectionsRequestsLatency(conns))
+	g.cpu = w.Mean(cpu.Get()) / n
+	g.restarts = nanToZero(w.Sum(restarts.Get()))
+	g.logErrors = nanToZero(w.Mean(logErrors.Get())) / n
+}
+
+// instanceConnections returns the inbound connections of the application grouped by the instance serving them.
+func instanceConnections(app *Application) map[*Instance][]*Connection {
+	res := map[*Instance][]*Connection{}
+	for _, c := range app.Downstreams {
+		if c.RemoteInstance != nil {
+			res[c.RemoteInstance] = append(res[c.RemoteInstance], c)
+		}
+	}
+	return res
+}
+
+func nanToZero(v float32) float32 {
+	if timeseries.IsNaN(v) {
+		return 0
+	}
+	return v
+}
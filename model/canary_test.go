@@ -0,0 +1,141 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type canaryInstance struct {
+	revision  string
+	cpu       float32
+	restarts  float32
+	logErrors float32
+	requests  float32
+	errors    float32
+	latency   float32
+}
+
+func canaryTestApp(instances ...canaryInstance) *Application {
+	app := NewApplication(NewApplicationId("default", ApplicationKindDeployment, "cart"))
+	series := func(v float32) *timeseries.TimeSeries {
+		return timeseries.NewWithData(0, timeseries.Minute, []float32{v, v, v, v, v, v, v, v, v, v, v})
+	}
+	for n, ci := range instances {
+		i := NewInstance(ci.revision+string(rune('a'+n)), app.Id)
+		i.Pod = &Pod{ReplicaSet: ci.revision, Running: series(1), Ready: series(1)}
+		c := i.GetOrCreateContainer("", "app")
+		c.MemoryRss = series(100)
+		c.CpuUsage = series(ci.cpu)
+		c.Restarts = timeseries.NewWithData(0, timeseries.Minute, []float32{ci.restarts})
+		i.LogMessagesByLevel[LogLevelError] = series(ci.logErrors)
+		app.Instances = append(app.Instances, i)
+		app.Downstreams = append(app.Downstreams, &Connection{
+			RemoteInstance:  i,
+			RequestsCount:   map[Protocol]map[string]*timeseries.TimeSeries{"http": {"200": series(ci.requests - ci.errors), "500": series(ci.errors)}},
+			RequestsLatency: map[Protocol]*timeseries.TimeSeries{"http": series(ci.latency)},
+		})
+	}
+	return app
+}
+
+func TestCalcCanaryAnalysis(t *testing.T) {
+	d := &ApplicationDeployment{Name: "cart-new", StartedAt: 0}
+	w := timeseries.Window{From: 0, To: timeseries.Time(CanaryWindow)}
+	old := canaryInstance{revision: "cart-old", cpu: 1, requests: 100, errors: 0, latency: 0.1}
+
+	for _, c := range []struct {
+		name     string
+		canary   canaryInstance
+		status   Status
+		messages []string
+	}{
+		{
+			name:     "passed",
+			canary:   canaryInstance{revision: "cart-new", cpu: 1.1, requests: 100, errors: 0.5, latency: 0.11},
+			status:   OK,
+			messages: []string{"Canary passed: 1 new vs 2 old instances over the last 10 minutes"},
+		},
+		{
+			name:   "errors",
+			canary: canaryInstance{revision: "cart-new", cpu: 1, requests: 100, errors: 5, latency: 0.1},
+			status: CRITICAL,
+			messages: []string{
+				"Canary failed: 1 new vs 2 old instances over the last 10 minutes",
+				"Errors: 5% of requests failed (0% on the previous version)",
+			},
+		},
+		{
+			name:   "latency",
+			canary: canaryInstance{revision: "cart-new", cpu: 1, requests: 100, latency: 0.2},
+			status: CRITICAL,
+			messages: []string{
+				"Canary failed: 1 new vs 2 old instances over the last 10 minutes",
+				"Latency: 200ms on average, +100% compared to the previous version",
+			},
+		},
+		{
+			name:   "cpu",
+			canary: canaryInstance{revision: "cart-new", cpu: 2, requests: 100, latency: 0.1},
+			status: WARNING,
+			messages: []string{
+				"Canary passed: 1 new vs 2 old instances over the last 10 minutes",
+				"CPU usage per instance: +100% compared to the previous version",
+			},
+		},
+		{
+			name:   "crash and log errors",
+			canary: canaryInstance{revision: "cart-new", cpu: 1, restarts: 2, logErrors: 1, requests: 100, latency: 0.1},
+			status: CRITICAL,
+			messages: []string{
+				"Canary failed: 1 new vs 2 old instances over the last 10 minutes",
+				"Crash: new containers have been restarted 2 times",
+				"Logs: new instances write more errors than the previous version",
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			res := CalcCanaryAnalysis(canaryTestApp(old, old, c.canary), d, w)
+			require.NotNil(t, res)
+			assert.Equal(t, 1, res.Canary)
+			assert.Equal(t, 2, res.Baseline)
+			assert.Equal(t, c.status, res.Status)
+			var messages []string
+			for _, s := range res.Summary {
+				messages = append(messages, s.Message)
+				assert.Equal(t, w.To, s.Time)
+			}
+			assert.Equal(t, c.messages, messages)
+			assert.Equal(t, c.status == OK, res.Summary[0].Ok)
+		})
+	}
+
+	app := canaryTestApp(old, canaryInstance{revision: "cart-new", cpu: 1})
+	assert.Nil(t, CalcCanaryAnalysis(app, d, timeseries.Window{From: 0, To: timeseries.Time(timeseries.Minute)}), "the window is too short")
+	assert.Nil(t, CalcCanaryAnalysis(canaryTestApp(old, old), d, w), "no canary instances")
+	assert.Nil(t, CalcCanaryAnalysis(canaryTestApp(canaryInstance{revision: "cart-new"}), d, w), "no baseline instances")
+}
+
+func TestCalcCanary(t *testing.T) {
+	app := canaryTestApp(
+		canaryInstance{revision: "cart-old", cpu: 1},
+		canaryInstance{revision: "cart-new", cpu: 1},
+	)
+	d := &ApplicationDeployment{Name: "cart-new", StartedAt: timeseries.Time(timeseries.Minute)}
+
+	s := ApplicationDeploymentStatus{State: ApplicationDeploymentStateSummary, Deployment: d}
+	s.CalcCanary(app, timeseries.Time(CanaryWindow))
+	assert.Nil(t, s.Canary, "the deployment has already been summarized")
+
+	s = ApplicationDeploymentStatus{State: ApplicationDeploymentStateInProgress, Deployment: d}
+	s.CalcCanary(app, timeseries.Time(CanaryWindow))
+	require.NotNil(t, s.Canary)
+	assert.Equal(t, "Canary passed: 1 new vs 1 old instances over the last 9 minutes", s.Canary.Summary[0].Message, "the window must start with the deployment")
+
+	d.FinishedAt = timeseries.Time(3 * timeseries.Minute)
+	s = ApplicationDeploymentStatus{State: ApplicationDeploymentStateDeployed, Deployment: d}
+	s.CalcCanary(app, timeseries.Time(CanaryWindow))
+	require.NotNil(t, s.Canary)
+	assert.Equal(t, timeseries.Time(3*timeseries.Minute), s.Canary.Summary[0].Time, "the window must end with the rollout")
+}
@@ -26,35 +26,74 @@ type Series struct {
 	Color     string `json:"color,omitempty"`
 	Fill      bool   `json:"fill,omitempty"`
 	Threshold string `json:"threshold,omitempty"`
+	Dashed    bool   `json:"dashed,omitempty"`
 
-	Data  SeriesData `json:"data"`
-	Value string     `json:"value"`
+	Data  SeriesData   `json:"data"`
+	Value string       `json:"value"`
+	Stats *SeriesStats `json:"stats,omitempty"`
+}
+
+// SeriesStats summarizes the values of a series, nil means there are no values.
+type SeriesStats struct {
+	Min  *float32 `json:"min"`
+	Max  *float32 `json:"max"`
+	Avg  *float32 `json:"avg"`
+	Last *float32 `json:"last"`
+}
+
+func NewSeriesStats(ts *timeseries.TimeSeries) *SeriesStats {
+	value := func(v float32) *float32 {
+		if timeseries.IsNaN(v) || timeseries.IsInf(v, 0) {
+			return nil
+		}
+		return &v
+	}
+	res := &SeriesStats{
+		Min: value(ts.Reduce(timeseries.Min)),
+		Max: value(ts.Reduce(timeseries.Max)),
+	}
+	if res.Max == nil {
+		return res
+	}
+	res.Avg = value(ts.Reduce(timeseries.NanSum) / ts.Map(timeseries.Defined).Reduce(timeseries.NanSum))
+	_, last := ts.LastNotNull()
+	res.Last = value(last)
+	return res
 }
 
 type SeriesList struct {
-	series []*Series
-	topN   int
-	topF   timeseries.F
+	series      []*Series
+	topN        int
+	topF        timeseries.F
+	summaryOnly bool
 }
 
+// MarshalJSON adds the stats to the series, the data points are omitted if the list is marked as summary only.
 func (sl SeriesList) MarshalJSON() ([]byte, error) {
-	ss := sl.series
-	if sl.topN > 0 && sl.topF != nil {
-		ss = topN(ss, sl.topN, sl.topF)
+	var res []*Series
+	for _, s := range sl.Get() {
+		ss := *s
+		ss.Stats = NewSeriesStats(s.Data.Get())
+		if sl.summaryOnly {
+			ss.Data = nil
+		}
+		res = append(res, &ss)
 	}
-	return json.Marshal(ss)
+	return json.Marshal(res)
 }
 
 type Chart struct {
 	Ctx timeseries.Context `json:"ctx"`
 
 	Title         string       `json:"title"`
+	Path          []string     `json:"path,omitempty"`
 	Series        SeriesList   `json:"series"`
 	Threshold     *Series      `json:"threshold"`
 	Featured      bool         `json:"featured"`
 	IsStacked     bool         `json:"stacked"`
 	IsSorted      bool         `json:"sorted"`
 	IsColumn      bool         `json:"column"`
+	IsHeatmap     bool         `json:"heatmap"`
 	ColorShift    int          `json:"color_shift"`
 	Annotations   []Annotation `json:"annotations"`
 	DrillDownLink *RouterLink  `json:"drill_down_link"`
@@ -84,6 +123,25 @@ func (chart *Chart) Column() *Chart {
 	return chart
 }
 
+// Heatmap makes the chart rendered as a heatmap: each series is a row (e.g., a histogram bucket), and the value is the color intensity.
+func (chart *Chart) Heatmap() *Chart {
+	chart.IsHeatmap = true
+	return chart
+}
+
+// AddHistogram adds the buckets of a cumulative histogram as separate series, from the lowest to the highest.
+func (chart *Chart) AddHistogram(buckets []HistogramBucket) *Chart {
+	for _, h := range HistogramSeries(buckets, 0, 0) {
+		if h.Data.IsEmpty() {
+			continue
+		}
+		s := h
+		s.Color, s.Threshold = "", ""
+		chart.Series.series = append(chart.Series.series, &s)
+	}
+	return chart
+}
+
 func (chart *Chart) ShiftColors() *Chart {
 	chart.ColorShift = 1
 	return chart
@@ -123,24 +181,93 @@ func (chart *Chart) SetThreshold(name string, data SeriesData) *Chart {
 	return chart
 }
 
+// SummaryOnly omits the data points of the series from the JSON representation of the chart, leaving only the stats.
+func (chart *Chart) SummaryOnly() *Chart {
+	chart.Series.summaryOnly = true
+	return chart
+}
+
 func (chart *Chart) Feature() *Chart {
 	chart.Featured = true
 	return chart
 }
 
+const chartPathSeparator = " / "
+
+// ChartGroup is a set of charts sharing the title, the chart to show is chosen with the selector (the <selector>
+// placeholder of the title). Selectors can be nested (e.g., instance → database): the names of the levels are set with
+// WithSelectors, and each chart is identified by its path, one value per level.
 type ChartGroup struct {
-	Title  string   `json:"title"`
-	Charts []*Chart `json:"charts"`
+	Title     string
+	Selectors []string
+	Charts    []*Chart
+
+	defaultSelection ChartSelection
+}
+
+// ChartSelection chooses the chart of a group shown by default, nil means no preference.
+type ChartSelection func(charts []*Chart) *Chart
+
+// SelectChart selects the chart matching the path, an empty value matches any value of the level.
+// If several charts match, the one with the most data is selected.
+func SelectChart(path ...string) ChartSelection {
+	return func(charts []*Chart) *Chart {
+		var res *Chart
+		var max float32
+		for _, ch := range charts {
+			if len(ch.Path) < len(path) {
+				continue
+			}
+			matches := true
+			for i, v := range path {
+				matches = matches && (v == "" || ch.Path[i] == v)
+			}
+			if !matches {
+				continue
+			}
+			if w := chartWeight(ch); res == nil || w > max {
+				res, max = ch, w
+			}
+		}
+		return res
+	}
+}
+
+func (cg *ChartGroup) WithSelectors(names ...string) *ChartGroup {
+	cg.Selectors = names
+	return cg
+}
+
+// WithDefault sets the rule choosing the default chart, by default the chart having notably more data than the others is chosen.
+func (cg *ChartGroup) WithDefault(s ChartSelection) *ChartGroup {
+	cg.defaultSelection = s
+	return cg
+}
+
+// ChartTitle returns the full title of a chart of the group.
+func (cg *ChartGroup) ChartTitle(ch *Chart) string {
+	return strings.Replace(cg.Title, "<selector>", ch.Title, 1)
 }
 
 func (cg *ChartGroup) MarshalJSON() ([]byte, error) {
+	if cg.defaultSelection != nil {
+		featured := false
+		for _, ch := range cg.Charts {
+			featured = featured || ch.Featured
+		}
+		if ch := cg.defaultSelection(cg.Charts); ch != nil && !featured {
+			ch.Featured = true
+		}
+	}
 	autoFeatureChart(cg.Charts)
 	return json.Marshal(struct {
-		Title  string   `json:"title"`
-		Charts []*Chart `json:"charts"`
+		Title     string   `json:"title"`
+		Selectors []string `json:"selectors,omitempty"`
+		Charts    []*Chart `json:"charts"`
 	}{
-		Title:  cg.Title,
-		Charts: cg.Charts,
+		Title:     cg.Title,
+		Selectors: cg.Selectors,
+		Charts:    cg.Charts,
 	})
 }
 
@@ -155,6 +282,13 @@ func (cg *ChartGroup) GetOrCreateChart(ctx timeseries.Context, title string) *Ch
 	return ch
 }
 
+// GetOrCreateChartByPath returns the chart with the given values of the nested selectors.
+func (cg *ChartGroup) GetOrCreateChartByPath(ctx timeseries.Context, path ...string) *Chart {
+	ch := cg.GetOrCreateChart(ctx, strings.Join(path, chartPathSeparator))
+	ch.Path = path
+	return ch
+}
+
 type Heatmap struct {
 	Ctx timeseries.Context `json:"ctx"`
 
@@ -199,11 +333,7 @@ func autoFeatureChart(charts []*Chart) {
 	}
 	weights := make([]weight, 0, len(charts))
 	for i, ch := range charts {
-		var w float32
-		for _, s := range ch.Series.series {
-			w += s.Data.Reduce(timeseries.NanSum)
-		}
-		weights = append(weights, weight{i: i, w: w})
+		weights = append(weights, weight{i: i, w: chartWeight(ch)})
 	}
 	sort.Slice(weights, func(i, j int) bool {
 		return weights[i].w > weights[j].w
@@ -213,6 +343,14 @@ func autoFeatureChart(charts []*Chart) {
 	}
 }
 
+func chartWeight(ch *Chart) float32 {
+	var w float32
+	for _, s := range ch.Series.series {
+		w += s.Data.Reduce(timeseries.NanSum)
+	}
+	return w
+}
+
 func topN(ss []*Series, n int, by timeseries.F) []*Series {
 	type weighted struct {
 		*Series
@@ -284,6 +422,12 @@ func EventsToAnnotations(events []*ApplicationEvent, ctx timeseries.Context) []A
 			case ApplicationEventTypeRollout:
 				msgs = append(msgs, "deployment "+e.Details)
 				i = "mdi-swap-horizontal-circle-outline"
+			case ApplicationEventTypeChange:
+				msgs = append(msgs, "change "+e.Details)
+				i = "mdi-flag-outline"
+			case ApplicationEventTypeTrafficShift:
+				msgs = append(msgs, "traffic shift "+e.Details)
+				i = "mdi-call-split"
 			case ApplicationEventTypeSwitchover:
 				msgs = append(msgs, "switchover "+e.Details)
 				i = "mdi-database-sync-outline"
@@ -311,10 +455,19 @@ func EventsToAnnotations(events []*ApplicationEvent, ctx timeseries.Context) []A
 func IncidentsToAnnotations(incidents []*ApplicationIncident, ctx timeseries.Context) []Annotation {
 	res := make([]Annotation, 0, len(incidents))
 	for _, i := range incidents {
+		to := i.ResolvedAt
 		if !i.Resolved() {
-			i.ResolvedAt = ctx.To
+			to = ctx.To
 		}
-		res = append(res, Annotation{Name: "incident", X1: i.OpenedAt, X2: i.ResolvedAt})
+		res = append(res, Annotation{Name: "incident", X1: i.OpenedAt, X2: to})
 	}
 	return res
 }
+
+// Get returns the series of the chart limited to the top N ones if configured.
+func (sl SeriesList) Get() []*Series {
+	if sl.topN > 0 && sl.topF != nil {
+		return topN(sl.series, sl.topN, sl.topF)
+	}
+	return sl.series
+}
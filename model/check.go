@@ -26,15 +26,51 @@ const (
 type CheckUnit string
 
 const (
-	CheckUnitPercent = "percent"
-	CheckUnitSecond  = "second"
-	CheckUnitByte    = "byte"
+	CheckUnitPercent     CheckUnit = "percent"
+	CheckUnitSecond      CheckUnit = "second"
+	CheckUnitMillisecond CheckUnit = "millisecond"
+	CheckUnitByte        CheckUnit = "byte"
 )
 
+func (u CheckUnit) Valid() bool {
+	switch u {
+	case "", CheckUnitPercent, CheckUnitSecond, CheckUnitMillisecond, CheckUnitByte:
+		return true
+	}
+	return false
+}
+
+// Convert converts the value measured in the unit to the given unit.
+// An empty unit means the value is already measured in the target unit.
+func (u CheckUnit) Convert(v float32, to CheckUnit) (float32, error) {
+	switch {
+	case u == to || u == "":
+		return v, nil
+	case u == CheckUnitMillisecond && to == CheckUnitSecond:
+		return v / 1000, nil
+	case u == CheckUnitSecond && to == CheckUnitMillisecond:
+		return v * 1000, nil
+	}
+	return v, fmt.Errorf("cannot convert %s to %s", u, to)
+}
+
+// ValidateThreshold checks whether the value is a valid threshold for the unit.
+func (u CheckUnit) ValidateThreshold(v float32) error {
+	if timeseries.IsNaN(v) {
+		return fmt.Errorf("invalid threshold")
+	}
+	if u != "" && v < 0 {
+		return fmt.Errorf("%s threshold must not be negative", u)
+	}
+	return nil
+}
+
 func (u CheckUnit) FormatValue(v float32) string {
 	switch u {
 	case CheckUnitSecond:
 		return utils.FormatDuration(timeseries.Duration(v), 1)
+	case CheckUnitMillisecond:
+		return utils.FormatDuration(timeseries.Duration(v/1000), 1)
 	case CheckUnitByte:
 		value, unit := utils.FormatBytes(v)
 		return value + unit
@@ -60,6 +96,9 @@ var Checks = struct {
 
 	SLOAvailability        CheckConfig
 	SLOLatency             CheckConfig
+	SLOFastBurn            CheckConfig
+	SLOSlowBurn            CheckConfig
+	SLOErrorBudget         CheckConfig
 	CPUNode                CheckConfig
 	CPUContainer           CheckConfig
 	MemoryOOM              CheckConfig
@@ -67,6 +106,12 @@ var Checks = struct {
 	StorageSpace           CheckConfig
 	StorageIO              CheckConfig
 	NetworkRTT             CheckConfig
+	NetworkRTTDegradation  CheckConfig
+	NetworkConntrack       CheckConfig
+	NetworkEphemeralPorts  CheckConfig
+	TlsCertificateExpiry   CheckConfig
+	DNSLatency             CheckConfig
+	DNSErrors              CheckConfig
 	InstanceAvailability   CheckConfig
 	DeploymentStatus       CheckConfig
 	InstanceRestarts       CheckConfig
@@ -99,6 +144,26 @@ var Checks = struct {
 		Unit:                    CheckUnitPercent,
 		ConditionFormatTemplate: "the percentage of requests served faster than <bucket> < <threshold>",
 	},
+	SLOFastBurn: CheckConfig{
+		Type:                    CheckTypeManual,
+		Title:                   "Fast burn",
+		MessageTemplate:         `the error budget is burning fast`,
+		ConditionFormatTemplate: "the error budget burn rate > 14.4x within 1 hour or > 6x within 6 hours",
+	},
+	SLOSlowBurn: CheckConfig{
+		Type:                    CheckTypeManual,
+		Title:                   "Slow burn",
+		MessageTemplate:         `the error budget is burning slowly`,
+		ConditionFormatTemplate: "the error budget burn rate > 3x within 1 day or > 1x within 3 days",
+	},
+	SLOErrorBudget: CheckConfig{
+		Type:                    CheckTypeValueBased,
+		Title:                   "Error budget",
+		MessageTemplate:         `the error budget is on pace to be exhausted by the end of the month`,
+		DefaultThreshold:        100,
+		Unit:                    CheckUnitPercent,
+		ConditionFormatTemplate: "the error budget consumption projected to the end of the month > <threshold>",
+	},
 	CPUNode: CheckConfig{
 		Type:                    CheckTypeItemBased,
 		Title:                   "Node CPU utilization",
@@ -153,6 +218,54 @@ var Checks = struct {
 		MessageTemplate:         `high network latency to {{.Items "upstream service"}}`,
 		ConditionFormatTemplate: "the RTT to an upstream service > <threshold>",
 	},
+	NetworkRTTDegradation: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Network RTT degradation",
+		DefaultThreshold:        100,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `network latency to {{.Items "upstream service"}} has degraded compared to the baseline`,
+		ConditionFormatTemplate: "the RTT to an upstream service exceeds its 7-day baseline by > <threshold>",
+	},
+	NetworkConntrack: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Conntrack table utilization",
+		DefaultThreshold:        80,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `the conntrack table is almost full on {{.Items "node"}}`,
+		ConditionFormatTemplate: "the conntrack table utilization of a node > <threshold>",
+	},
+	NetworkEphemeralPorts: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Ephemeral ports",
+		DefaultThreshold:        80,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `ephemeral ports are almost exhausted on {{.Items "instance"}}`,
+		ConditionFormatTemplate: "the estimated percentage of ephemeral ports used by an instance to an upstream > <threshold>",
+	},
+	TlsCertificateExpiry: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "TLS certificate expiry",
+		DefaultThreshold:        float32(14 * timeseries.Day),
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `{{.Items "TLS certificate"}} expiring soon`,
+		ConditionFormatTemplate: "the time until a TLS certificate expires < <threshold>",
+	},
+	DNSLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "DNS latency",
+		DefaultThreshold:        0.1,
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `high DNS latency on {{.Items "instance"}}`,
+		ConditionFormatTemplate: "the average DNS request latency of an instance > <threshold>",
+	},
+	DNSErrors: CheckConfig{
+		Type:                    CheckTypeValueBased,
+		Title:                   "DNS errors",
+		DefaultThreshold:        10,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `{{.Value}} of DNS requests failed`,
+		ConditionFormatTemplate: "the percentage of DNS requests failed with NXDOMAIN or SERVFAIL > <threshold>",
+	},
 	InstanceAvailability: CheckConfig{
 		Type:                    CheckTypeManual,
 		Title:                   "Instance availability",
@@ -265,6 +378,10 @@ func init() {
 	}
 }
 
+func GetCheckConfig(id CheckId) *CheckConfig {
+	return Checks.index[id]
+}
+
 type CheckContext struct {
 	items *utils.StringSet
 	count int64
@@ -301,20 +418,58 @@ func (c CheckContext) Value() string {
 }
 
 type Check struct {
-	Id                      CheckId   `json:"id"`
-	Title                   string    `json:"title"`
-	Status                  Status    `json:"status"`
-	Message                 string    `json:"message"`
-	Threshold               float32   `json:"threshold"`
-	Unit                    CheckUnit `json:"unit"`
-	ConditionFormatTemplate string    `json:"condition_format_template"`
+	Id                      CheckId          `json:"id"`
+	Title                   string           `json:"title"`
+	Status                  Status           `json:"status"`
+	Message                 string           `json:"message"`
+	Threshold               float32          `json:"threshold"`
+	CriticalThreshold       *float32         `json:"critical_threshold,omitempty"`
+	Severity                Status           `json:"severity"`
+	Unit                    CheckUnit        `json:"unit"`
+	ConditionFormatTemplate string           `json:"condition_format_template"`
+	Muted                   bool             `json:"muted"`
+	Maintenance             bool             `json:"maintenance,omitempty"`
+	SuppressedBy            string           `json:"suppressed_by,omitempty"`
+	Annotation              *CheckAnnotation `json:"annotation,omitempty"`
+	DrillDownLink           *RouterLink      `json:"drill_down_link,omitempty"`
 
 	typ             CheckType
 	messageTemplate string
 	items           *utils.StringSet
 	count           int64
 	value           float32
+	observed        bool
 	fired           bool
+	instances       *InstanceSelector
+
+	statusBeforeMaintenance Status
+}
+
+// Silenced reports whether the check must not affect the application status and notifications.
+func (ch *Check) Silenced() bool {
+	return ch.Muted || ch.SuppressedBy != ""
+}
+
+// SetMaintenance makes the check informational during a maintenance window.
+func (ch *Check) SetMaintenance() {
+	if !ch.Maintenance {
+		ch.statusBeforeMaintenance = ch.Status
+	}
+	ch.Maintenance = true
+	if ch.Status > INFO {
+		ch.Status = INFO
+	}
+}
+
+// Unsilence reverts the muting, the maintenance, and the suppression of the check,
+// so that a check of a reused report can be silenced according to the current settings.
+func (ch *Check) Unsilence() {
+	ch.Muted = false
+	ch.SuppressedBy = ""
+	if ch.Maintenance {
+		ch.Maintenance = false
+		ch.Status = ch.statusBeforeMaintenance
+	}
 }
 
 func (ch *Check) SetValue(v float32) {
@@ -338,6 +493,66 @@ func (ch *Check) AddItem(format string, a ...any) {
 	ch.items.Add(fmt.Sprintf(format, a...))
 }
 
+// Items returns the items (e.g., instances or volumes) the check failed for.
+func (ch *Check) Items() []string {
+	if ch.items == nil {
+		return nil
+	}
+	return ch.items.Items()
+}
+
+// Observe records the worst (max) value seen by the check.
+// It is compared with the critical threshold and persisted to track the check's trend.
+func (ch *Check) Observe(v float32) {
+	if timeseries.IsNaN(v) {
+		return
+	}
+	if !ch.observed || v > ch.value {
+		ch.value = v
+	}
+	ch.observed = true
+}
+
+// Value returns the check's current value if it has one.
+func (ch *Check) Value() (float32, bool) {
+	switch ch.typ {
+	case CheckTypeEventBased:
+		return float32(ch.count), true
+	case CheckTypeValueBased:
+		return ch.value, true
+	}
+	return ch.value, ch.observed
+}
+
+// ValueStatus returns the status of an item with the given value according to the check thresholds.
+func (ch *Check) ValueStatus(v float32) Status {
+	switch {
+	case ch.CriticalThreshold != nil && v > *ch.CriticalThreshold:
+		return CRITICAL
+	case v > ch.Threshold:
+		if ch.Severity > UNKNOWN {
+			return ch.Severity
+		}
+		return WARNING
+	}
+	return OK
+}
+
+// Matches reports whether the check applies to the instance.
+func (ch *Check) Matches(instance *Instance) bool {
+	return ch.instances.Matches(instance)
+}
+
+// Exceeds reports whether the value measured in the given unit is above the check threshold.
+func (ch *Check) Exceeds(v float32, unit CheckUnit) bool {
+	v, err := unit.Convert(v, ch.Unit)
+	if err != nil {
+		klog.Warningf("check %s: %s", ch.Id, err)
+		return false
+	}
+	return v > ch.Threshold
+}
+
 func (ch *Check) Inc(amount int64) {
 	ch.count += amount
 }
@@ -373,11 +588,85 @@ func (ch *Check) Calc() {
 		ch.SetStatus(UNKNOWN, "failed to render message: %s", err)
 		return
 	}
-	ch.SetStatus(WARNING, buf.String())
+	status := WARNING
+	if ch.Severity > UNKNOWN {
+		status = ch.Severity
+	}
+	if ch.critical() {
+		status = CRITICAL
+	}
+	ch.SetStatus(status, buf.String())
+}
+
+func (ch *Check) critical() bool {
+	if ch.CriticalThreshold == nil {
+		return false
+	}
+	switch ch.typ {
+	case CheckTypeEventBased:
+		return ch.count > int64(*ch.CriticalThreshold)
+	case CheckTypeItemBased, CheckTypeValueBased:
+		return ch.value > *ch.CriticalThreshold
+	}
+	return false
 }
 
 type CheckConfigSimple struct {
-	Threshold float32 `json:"threshold"`
+	Threshold         float32              `json:"threshold"`
+	CriticalThreshold *float32             `json:"critical_threshold,omitempty"`
+	Severity          Status               `json:"severity,omitempty"`
+	Adaptive          *AdaptiveThreshold   `json:"adaptive,omitempty"`
+	Schedule          []ScheduledThreshold `json:"schedule,omitempty"`
+	Unit              CheckUnit            `json:"unit,omitempty"`
+	Instances         *InstanceSelector    `json:"instances,omitempty"`
+}
+
+// ConvertUnit converts the thresholds from the unit they were specified in to the check's unit and validates them.
+func (cfg *CheckConfigSimple) ConvertUnit(to CheckUnit) error {
+	from := cfg.Unit
+	convert := func(v *float32) error {
+		if v == nil {
+			return nil
+		}
+		res, err := from.Convert(*v, to)
+		if err != nil {
+			return err
+		}
+		if err = to.ValidateThreshold(res); err != nil {
+			return err
+		}
+		*v = res
+		return nil
+	}
+	if err := convert(&cfg.Threshold); err != nil {
+		return err
+	}
+	if err := convert(cfg.CriticalThreshold); err != nil {
+		return err
+	}
+	for i := range cfg.Schedule {
+		if err := convert(&cfg.Schedule[i].Threshold); err != nil {
+			return err
+		}
+		if err := convert(cfg.Schedule[i].CriticalThreshold); err != nil {
+			return err
+		}
+	}
+	cfg.Unit = ""
+	return nil
+}
+
+// AdaptiveThreshold defines how a threshold is learned from the metric's history:
+// the given percentile of the values within the window plus the margin (in percent).
+// Until a value is learned, the static threshold is used.
+type AdaptiveThreshold struct {
+	Percentile float32             `json:"percentile"`
+	Margin     float32             `json:"margin"`
+	Window     timeseries.Duration `json:"window"`
+}
+
+func (at *AdaptiveThreshold) Calc(values ...*timeseries.TimeSeries) float32 {
+	return timeseries.Quantile(at.Percentile/100, values...) * (1 + at.Margin/100)
 }
 
 type CheckConfigSLOAvailability struct {
@@ -409,7 +698,7 @@ func (cfg *CheckConfigSLOLatency) Histogram() string {
 type CheckConfigs map[ApplicationId]map[CheckId]json.RawMessage
 
 func (cc CheckConfigs) getRaw(appId ApplicationId, checkId CheckId) json.RawMessage {
-	for _, i := range []ApplicationId{appId, {}} {
+	for _, i := range []ApplicationId{appId, NewNamespaceScopeId(appId.Namespace), {}} {
 		if appConfigs, ok := cc[i]; ok {
 			if cfg, ok := appConfigs[checkId]; ok {
 				return cfg
@@ -433,6 +722,8 @@ func (cc CheckConfigs) GetSimple(checkId CheckId, appId ApplicationId) CheckConf
 	return v
 }
 
+// GetSimpleAll returns the configs of the check defined at every level: the default, the project-level,
+// and, for an application, the namespace-level and the application-level ones (nil if not defined).
 func (cc CheckConfigs) GetSimpleAll(checkId CheckId, appId ApplicationId) []*CheckConfigSimple {
 	def := Checks.index[checkId]
 	if def == nil {
@@ -442,7 +733,7 @@ func (cc CheckConfigs) GetSimpleAll(checkId CheckId, appId ApplicationId) []*Che
 	res := []*CheckConfigSimple{{Threshold: Checks.index[checkId].DefaultThreshold}}
 	ids := []ApplicationId{ApplicationIdZero}
 	if !appId.IsZero() {
-		ids = append(ids, appId)
+		ids = append(ids, NewNamespaceScopeId(appId.Namespace), appId)
 	}
 	for _, id := range ids {
 		if appConfigs, ok := cc[id]; ok {
@@ -547,3 +838,25 @@ func unmarshal[T any](raw json.RawMessage) (T, error) {
 	}
 	return cfg, nil
 }
+
+// ApplyAdaptiveThresholds replaces the static thresholds of the checks configured in the adaptive mode with the learned ones.
+func (cc CheckConfigs) ApplyAdaptiveThresholds(learned map[ApplicationId]map[CheckId]float32) {
+	for appId, checks := range learned {
+		for checkId, threshold := range checks {
+			cfg := cc.GetSimple(checkId, appId)
+			if cfg.Adaptive == nil {
+				continue
+			}
+			cfg.Threshold = threshold
+			raw, err := json.Marshal(cfg)
+			if err != nil {
+				klog.Warningln("failed to marshal check config:", err)
+				continue
+			}
+			if cc[appId] == nil {
+				cc[appId] = map[CheckId]json.RawMessage{}
+			}
+			cc[appId][checkId] = raw
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+// CheckAnnotation holds the metadata of a check: a runbook, an owner, and a description.
+// An annotation with the zero ApplicationId applies to the check of all applications,
+// the fields of an application-specific annotation override it.
+type CheckAnnotation struct {
+	ApplicationId ApplicationId `json:"application_id"`
+	CheckId       CheckId       `json:"check_id"`
+	RunbookUrl    string        `json:"runbook_url"`
+	Owner         string        `json:"owner"`
+	Description   string        `json:"description"`
+}
+
+func (a CheckAnnotation) IsEmpty() bool {
+	return a.RunbookUrl == "" && a.Owner == "" && a.Description == ""
+}
+
+type CheckAnnotations []CheckAnnotation
+
+func (as CheckAnnotations) Find(appId ApplicationId, checkId CheckId) *CheckAnnotation {
+	for i := range as {
+		if as[i].ApplicationId == appId && as[i].CheckId == checkId {
+			return &as[i]
+		}
+	}
+	return nil
+}
+
+func (as CheckAnnotations) Get(appId ApplicationId, checkId CheckId) *CheckAnnotation {
+	res := CheckAnnotation{ApplicationId: appId, CheckId: checkId}
+	for _, a := range []*CheckAnnotation{as.Find(ApplicationIdZero, checkId), as.Find(appId, checkId)} {
+		if a == nil {
+			continue
+		}
+		if a.RunbookUrl != "" {
+			res.RunbookUrl = a.RunbookUrl
+		}
+		if a.Owner != "" {
+			res.Owner = a.Owner
+		}
+		if a.Description != "" {
+			res.Description = a.Description
+		}
+	}
+	if res.IsEmpty() {
+		return nil
+	}
+	return &res
+}
@@ -0,0 +1,29 @@
+package model
+
+// CheckCauses returns the checks of the upstream applications that may cause the given check of their clients to fail.
+// Only infrastructure-level checks are considered causes, so suppression never forms a cycle.
+func CheckCauses(id CheckId) []CheckId {
+	availability := []CheckId{
+		Checks.InstanceAvailability.Id,
+		Checks.PostgresAvailability.Id,
+		Checks.RedisAvailability.Id,
+		Checks.JvmAvailability.Id,
+	}
+	performance := []CheckId{
+		Checks.CPUNode.Id,
+		Checks.CPUContainer.Id,
+		Checks.StorageIO.Id,
+		Checks.PostgresLatency.Id,
+		Checks.RedisLatency.Id,
+		Checks.JvmSafepointTime.Id,
+	}
+	switch id {
+	case Checks.SLOAvailability.Id, Checks.LogErrors.Id:
+		return availability
+	case Checks.SLOLatency.Id, Checks.NetworkRTT.Id:
+		return append(availability, performance...)
+	case Checks.SLOFastBurn.Id, Checks.SLOSlowBurn.Id, Checks.SLOErrorBudget.Id:
+		return append(availability, performance...)
+	}
+	return nil
+}
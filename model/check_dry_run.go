@@ -0,0 +1,47 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+// CheckFiring is a period of time when a check was (or would have been) firing.
+type CheckFiring struct {
+	From   timeseries.Time `json:"from"`
+	To     timeseries.Time `json:"to"`
+	Status Status          `json:"status"`
+	Max    float32         `json:"max"`
+}
+
+// DryRunCheck evaluates the thresholds of the check config against the history of the check values
+// and returns the periods when the check would have fired.
+func DryRunCheck(values *timeseries.TimeSeries, step timeseries.Duration, cfg CheckConfigSimple) []CheckFiring {
+	ch := &Check{Threshold: cfg.Threshold, CriticalThreshold: cfg.CriticalThreshold, Severity: cfg.Severity}
+	var res []CheckFiring
+	var curr *CheckFiring
+	iter := values.Iter()
+	for iter.Next() {
+		t, v := iter.Value()
+		status := OK
+		if !timeseries.IsNaN(v) {
+			status = ch.ValueStatus(v)
+		}
+		if curr != nil && status != curr.Status {
+			res = append(res, *curr)
+			curr = nil
+		}
+		if status == OK {
+			continue
+		}
+		if curr == nil {
+			curr = &CheckFiring{From: t, Status: status, Max: v}
+		}
+		curr.To = t.Add(step)
+		if v > curr.Max {
+			curr.Max = v
+		}
+	}
+	if curr != nil {
+		res = append(res, *curr)
+	}
+	return res
+}
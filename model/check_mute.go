@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+	"time"
+)
+
+// CheckMute silences a check of an application either until the given time or,
+// if a cron schedule (in the project timezone) is specified, for the Duration after each scheduled time.
+type CheckMute struct {
+	Id            string              `json:"id"`
+	ApplicationId ApplicationId       `json:"application_id"`
+	CheckId       CheckId             `json:"check_id"`
+	Until         timeseries.Time     `json:"until"`
+	Schedule      string              `json:"schedule"`
+	Duration      timeseries.Duration `json:"duration"`
+	Comment       string              `json:"comment"`
+
+	cron *utils.CronSchedule
+}
+
+// UnmarshalJSON parses the schedule once the mute is loaded from the project settings,
+// rather than on every check of every application.
+func (m *CheckMute) UnmarshalJSON(data []byte) error {
+	type checkMute CheckMute
+	if err := json.Unmarshal(data, (*checkMute)(m)); err != nil {
+		return err
+	}
+	m.cron = nil
+	if m.Schedule == "" {
+		return nil
+	}
+	if s, err := utils.ParseCron(m.Schedule); err != nil {
+		klog.Warningln("invalid mute schedule:", err)
+	} else {
+		m.cron = s
+	}
+	return nil
+}
+
+func (m CheckMute) Active(t timeseries.Time, loc *time.Location) bool {
+	if m.Schedule == "" {
+		return !t.After(m.Until)
+	}
+	s := m.cron
+	if s == nil { // the mute isn't loaded from the settings
+		var err error
+		if s, err = utils.ParseCron(m.Schedule); err != nil {
+			return false
+		}
+	}
+	return s.Within(t.ToStandard().In(loc), m.Duration.ToStandard())
+}
+
+type CheckMutes []CheckMute
+
+func (ms CheckMutes) IsMuted(appId ApplicationId, checkId CheckId, t timeseries.Time, loc *time.Location) bool {
+	for _, m := range ms {
+		if m.ApplicationId == appId && m.CheckId == checkId && m.Active(t, loc) {
+			return true
+		}
+	}
+	return false
+}
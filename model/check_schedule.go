@@ -0,0 +1,116 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"k8s.io/klog"
+	"time"
+)
+
+// ScheduledThreshold overrides the thresholds of a check on the given days of the week (every day if empty)
+// between From and To ("HH:MM", To is exclusive, the interval may cross midnight) in the project timezone.
+type ScheduledThreshold struct {
+	Days              []time.Weekday `json:"days,omitempty"`
+	From              string         `json:"from"`
+	To                string         `json:"to"`
+	Threshold         float32        `json:"threshold"`
+	CriticalThreshold *float32       `json:"critical_threshold,omitempty"`
+}
+
+func (s ScheduledThreshold) Validate() error {
+	return validateTimeOfDayRange(s.Days, s.From, s.To)
+}
+
+func validateTimeOfDayRange(days []time.Weekday, fromStr, toStr string) error {
+	from, err := parseTimeOfDay(fromStr)
+	if err != nil {
+		return err
+	}
+	to, err := parseTimeOfDay(toStr)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return fmt.Errorf("empty interval")
+	}
+	for _, d := range days {
+		if d < time.Sunday || d > time.Saturday {
+			return fmt.Errorf("invalid day: %d", d)
+		}
+	}
+	return nil
+}
+
+// Active reports whether the schedule covers the given time, t must be in the project timezone.
+func (s ScheduledThreshold) Active(t time.Time) bool {
+	return timeOfDayRangeActive(s.Days, s.From, s.To, t)
+}
+
+func timeOfDayRangeActive(days []time.Weekday, fromStr, toStr string, t time.Time) bool {
+	from, err := parseTimeOfDay(fromStr)
+	if err != nil {
+		return false
+	}
+	to, err := parseTimeOfDay(toStr)
+	if err != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	if from > to { // crosses midnight: the part after midnight belongs to the previous day
+		if minute < to {
+			day = (day + 6) % 7
+		} else if minute < from {
+			return false
+		}
+	} else if minute < from || minute >= to {
+		return false
+	}
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ApplySchedules replaces the thresholds of the checks with the scheduled ones active at the given time.
+func (cc CheckConfigs) ApplySchedules(t time.Time) {
+	for appId, checks := range cc {
+		for checkId, raw := range checks {
+			switch checkId {
+			case Checks.SLOAvailability.Id, Checks.SLOLatency.Id:
+				continue
+			}
+			cfg, err := unmarshal[CheckConfigSimple](raw)
+			if err != nil || len(cfg.Schedule) == 0 {
+				continue
+			}
+			for _, s := range cfg.Schedule {
+				if !s.Active(t) {
+					continue
+				}
+				cfg.Threshold = s.Threshold
+				cfg.CriticalThreshold = s.CriticalThreshold
+				data, err := json.Marshal(cfg)
+				if err != nil {
+					klog.Warningln("failed to marshal check config:", err)
+					break
+				}
+				cc[appId][checkId] = data
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestScheduledThreshold(t *testing.T) {
+	businessHours := ScheduledThreshold{Days: []time.Weekday{time.Monday, time.Friday}, From: "09:00", To: "18:00"}
+	assert.NoError(t, businessHours.Validate())
+	assert.True(t, businessHours.Active(time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)))   // Monday
+	assert.False(t, businessHours.Active(time.Date(2023, 5, 1, 18, 0, 0, 0, time.UTC))) // Monday
+	assert.False(t, businessHours.Active(time.Date(2023, 5, 2, 10, 0, 0, 0, time.UTC))) // Tuesday
+
+	night := ScheduledThreshold{Days: []time.Weekday{time.Friday}, From: "22:00", To: "06:00"}
+	assert.True(t, night.Active(time.Date(2023, 5, 5, 23, 0, 0, 0, time.UTC)))  // Friday
+	assert.True(t, night.Active(time.Date(2023, 5, 6, 5, 59, 0, 0, time.UTC)))  // Saturday morning
+	assert.False(t, night.Active(time.Date(2023, 5, 5, 5, 0, 0, 0, time.UTC)))  // Friday morning
+	assert.False(t, night.Active(time.Date(2023, 5, 6, 12, 0, 0, 0, time.UTC))) // Saturday
+
+	assert.Error(t, ScheduledThreshold{From: "9am", To: "18:00"}.Validate())
+	assert.Error(t, ScheduledThreshold{From: "09:00", To: "09:00"}.Validate())
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+)
+
+// CheckTemplate defines check thresholds for all applications matching any of the categories or application patterns.
+type CheckTemplate struct {
+	Id           string                        `json:"id"`
+	Name         string                        `json:"name"`
+	Categories   []ApplicationCategory         `json:"categories"`
+	Applications []string                      `json:"applications"`
+	Checks       map[CheckId]CheckConfigSimple `json:"checks"`
+}
+
+func (t *CheckTemplate) Matches(appId ApplicationId, category ApplicationCategory) bool {
+	for _, c := range t.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return utils.GlobMatch(fmt.Sprintf("%s/%s", appId.Namespace, appId.Name), t.Applications)
+}
+
+// ApplyTemplates sets the configs of the matching templates for the application unless the application has its own ones.
+// If several templates match, the first one takes precedence.
+func (cc CheckConfigs) ApplyTemplates(appId ApplicationId, category ApplicationCategory, templates []CheckTemplate) {
+	for _, t := range templates {
+		if !t.Matches(appId, category) {
+			continue
+		}
+		for checkId, cfg := range t.Checks {
+			if _, ok := cc[appId][checkId]; ok {
+				continue
+			}
+			raw, err := json.Marshal(cfg)
+			if err != nil {
+				klog.Warningln("failed to marshal check config:", err)
+				continue
+			}
+			if cc[appId] == nil {
+				cc[appId] = map[CheckId]json.RawMessage{}
+			}
+			cc[appId][checkId] = raw
+		}
+	}
+}
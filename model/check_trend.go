@@ -0,0 +1,59 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+)
+
+const (
+	CheckValuesRetention = 30 * timeseries.Day
+	CheckTrendWindow     = 7 * timeseries.Day
+	CheckTrendRecent     = timeseries.Day
+
+	checkTrendMinChange = 20
+)
+
+// CheckValue is the value of a check computed during a single evaluation.
+type CheckValue struct {
+	ApplicationId ApplicationId
+	CheckId       CheckId
+	Report        AuditReportName
+	Value         float32
+	Status        Status
+}
+
+// CheckTrend compares the average value of a check over the last day with the average value over the rest of the trend window.
+type CheckTrend struct {
+	CheckId CheckId
+	Title   string
+	Report  AuditReportName
+	Diff    timeseries.Diff
+}
+
+func NewCheckTrend(id CheckId, report AuditReportName, before, after float32) CheckTrend {
+	return CheckTrend{CheckId: id, Report: report, Diff: timeseries.Ratio(before, after, checkTrendMinChange)}
+}
+
+// Degrading reports whether the check value has been gradually growing, i.e. moving towards the threshold.
+func (t CheckTrend) Degrading() bool {
+	return t.Diff.Significant && t.Diff.Change > 0
+}
+
+func (t CheckTrend) String() string {
+	return fmt.Sprintf("%s: degrading gradually (%s over the last week)", t.Title, t.Diff)
+}
+
+// CheckValues returns the values of the application checks to be persisted.
+func (app *Application) CheckValues() []CheckValue {
+	var res []CheckValue
+	for _, r := range app.Reports {
+		for _, ch := range r.Checks {
+			v, ok := ch.Value()
+			if !ok || timeseries.IsNaN(v) || ch.Status == UNKNOWN {
+				continue
+			}
+			res = append(res, CheckValue{ApplicationId: app.Id, CheckId: ch.Id, Report: r.Name, Value: v, Status: ch.Status})
+		}
+	}
+	return res
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"strings"
+)
+
+const (
+	CIPipelineStatusSuccess = "success"
+	CIPipelineStatusFailure = "failure"
+)
+
+// CIPipeline is a CI pipeline run (a GitHub Actions workflow run or a Jenkins build) reported through a webhook.
+// It's linked to the deployments of the revision it was run for.
+type CIPipeline struct {
+	Provider    string          `json:"provider"`
+	Id          string          `json:"id"`
+	Name        string          `json:"name"`
+	Url         string          `json:"url"`
+	Status      string          `json:"status"`
+	Sha         string          `json:"sha"`
+	Branch      string          `json:"branch,omitempty"`
+	StartedAt   timeseries.Time `json:"started_at"`
+	FinishedAt  timeseries.Time `json:"finished_at"`
+	Tests       *CITestResults  `json:"tests,omitempty"`
+	PullRequest *CIPullRequest  `json:"pull_request,omitempty"`
+}
+
+type CITestResults struct {
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+type CIPullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title,omitempty"`
+	Url    string `json:"url,omitempty"`
+	Author string `json:"author,omitempty"`
+}
+
+func (p *CIPipeline) Duration() timeseries.Duration {
+	if p.StartedAt.IsZero() || p.FinishedAt.IsZero() {
+		return 0
+	}
+	return p.FinishedAt.Sub(p.StartedAt)
+}
+
+// MatchesRevision reports whether the pipeline was run for the revision, which can be abbreviated.
+func (p *CIPipeline) MatchesRevision(revision string) bool {
+	return len(revision) >= 7 && p.Sha != "" && strings.HasPrefix(p.Sha, revision)
+}
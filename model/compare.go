@@ -0,0 +1,149 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"math"
+	"strconv"
+)
+
+const (
+	CompareModePreviousDay  = "1d"
+	CompareModePreviousWeek = "7d"
+	CompareModeDeployment   = "deployment"
+)
+
+// CompareOffset returns how far back the baseline window is from the current one.
+// For the deployment mode, the baseline window ends when the last deployment started.
+func CompareOffset(mode string, app *Application, ctx timeseries.Context) timeseries.Duration {
+	var offset timeseries.Duration
+	switch mode {
+	case CompareModePreviousDay:
+		offset = timeseries.Day
+	case CompareModePreviousWeek:
+		offset = 7 * timeseries.Day
+	case CompareModeDeployment:
+		var last *ApplicationDeployment
+		for _, d := range app.Deployments {
+			if d.StartedAt.Before(ctx.To) && (last == nil || d.StartedAt.After(last.StartedAt)) {
+				last = d
+			}
+		}
+		if last == nil {
+			return 0
+		}
+		offset = ctx.To.Sub(last.StartedAt)
+	}
+	if ctx.Step > 0 {
+		offset = offset.Truncate(ctx.Step)
+	}
+	return offset
+}
+
+// CompareReports overlays the charts of the reports with the data of the baseline reports
+// (calculated for the window shifted back by the offset) and annotates the numeric table values with deltas.
+func CompareReports(reports, baseline []*AuditReport, offset timeseries.Duration) {
+	byName := map[AuditReportName]*AuditReport{}
+	for _, r := range baseline {
+		byName[r.Name] = r
+	}
+	for _, r := range reports {
+		b := byName[r.Name]
+		if b == nil {
+			continue
+		}
+		for _, w := range r.Widgets {
+			switch {
+			case w.Chart != nil:
+				if bch := b.FindChart(w.Chart.Title); bch != nil {
+					compareCharts(w.Chart, bch, offset)
+				}
+			case w.ChartGroup != nil:
+				for _, ch := range w.ChartGroup.Charts {
+					if bch := b.FindChart(w.ChartGroup.ChartTitle(ch)); bch != nil {
+						compareCharts(ch, bch, offset)
+					}
+				}
+			case w.Table != nil:
+				for _, bw := range b.Widgets {
+					if bw.Table != nil && equalHeaders(w.Table.Header, bw.Table.Header) {
+						compareTables(w.Table, bw.Table)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func compareCharts(chart, baseline *Chart, offset timeseries.Duration) {
+	if chart.IsHeatmap {
+		return
+	}
+	if chart.IsStacked {
+		total := timeseries.NewAggregate(timeseries.NanSum)
+		for _, s := range baseline.Series.Get() {
+			total.Add(s.Data.Get())
+		}
+		chart.Series.series = append(chart.Series.series, &Series{Name: "total (baseline)", Color: "grey", Dashed: true, Data: total.Get().Shift(offset)})
+		return
+	}
+	for _, s := range baseline.Series.Get() {
+		data := s.Data.Get().Shift(offset)
+		if data.IsEmpty() {
+			continue
+		}
+		chart.Series.series = append(chart.Series.series, &Series{Name: s.Name + " (baseline)", Color: s.Color, Dashed: true, Data: data})
+	}
+}
+
+func compareTables(table, baseline *Table) {
+	rows := map[string]*TableRow{}
+	for _, r := range baseline.Rows {
+		if len(r.Cells) > 0 && r.Cells[0] != nil {
+			rows[r.Cells[0].Value] = r
+		}
+	}
+	for _, r := range table.Rows {
+		if len(r.Cells) == 0 || r.Cells[0] == nil {
+			continue
+		}
+		br := rows[r.Cells[0].Value]
+		if br == nil {
+			continue
+		}
+		for i, c := range r.Cells {
+			if i == 0 || c == nil || i >= len(br.Cells) || br.Cells[i] == nil || c.Unit != br.Cells[i].Unit {
+				continue
+			}
+			c.Delta = delta(c.Value, br.Cells[i].Value)
+		}
+	}
+}
+
+func delta(current, baseline string) string {
+	cur, err := strconv.ParseFloat(current, 64)
+	if err != nil {
+		return ""
+	}
+	base, err := strconv.ParseFloat(baseline, 64)
+	if err != nil || cur == base {
+		return ""
+	}
+	if base == 0 {
+		return fmt.Sprintf("%+g", cur-base)
+	}
+	return fmt.Sprintf("%+.0f%%", math.Round((cur-base)/math.Abs(base)*100))
+}
+
+func equalHeaders(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
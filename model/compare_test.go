@@ -0,0 +1,83 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCompareOffset(t *testing.T) {
+	ctx := timeseries.Context{From: 0, To: timeseries.Time(10 * timeseries.Hour), Step: timeseries.Minute}
+	app := NewApplication(NewApplicationId("default", ApplicationKindDeployment, "cart"))
+
+	assert.Equal(t, timeseries.Day, CompareOffset(CompareModePreviousDay, app, ctx))
+	assert.Equal(t, 7*timeseries.Day, CompareOffset(CompareModePreviousWeek, app, ctx))
+	assert.Equal(t, timeseries.Duration(0), CompareOffset(CompareModeDeployment, app, ctx))
+	assert.Equal(t, timeseries.Duration(0), CompareOffset("", app, ctx))
+
+	app.Deployments = []*ApplicationDeployment{
+		{StartedAt: timeseries.Time(2 * timeseries.Hour)},
+		{StartedAt: timeseries.Time(4*timeseries.Hour + 30)},
+		{StartedAt: timeseries.Time(11 * timeseries.Hour)}, // after the window
+	}
+	assert.Equal(t, 6*timeseries.Hour-timeseries.Minute, CompareOffset(CompareModeDeployment, app, ctx), "the offset must be aligned to the step")
+}
+
+func TestCompareReports(t *testing.T) {
+	ctx := timeseries.Context{From: 100, To: 130, Step: 10}
+	app := NewApplication(NewApplicationId("default", ApplicationKindDeployment, "cart"))
+	report := func(values []float32, usage string) *AuditReport {
+		r := NewAuditReport(app, ctx, nil, AuditReportCPU)
+		r.GetOrCreateChart("CPU usage, cores").AddSeries("cart-1", timeseries.NewWithData(ctx.From, ctx.Step, values))
+		r.GetOrCreateChart("CPU usage by container, cores").Stacked().
+			AddSeries("a", timeseries.NewWithData(ctx.From, ctx.Step, values)).
+			AddSeries("b", timeseries.NewWithData(ctx.From, ctx.Step, values))
+		r.GetOrCreateChartGroup("RTT to <selector>, seconds").GetOrCreateChart(ctx, "db").
+			AddSeries("rtt", timeseries.NewWithData(ctx.From, ctx.Step, values))
+		t := r.GetOrCreateTable("Container", "Usage", "Status")
+		t.AddRow(NewTableCell("cart-1"), NewTableCell(usage).SetUnit("cores"), NewTableCell("ok"))
+		t.AddRow(NewTableCell("cart-2"), NewTableCell(usage).SetUnit("cores"), NewTableCell("ok"))
+		return r
+	}
+	current := report([]float32{2, 2, 2}, "1.5")
+	baseline := report([]float32{1, 1, 1}, "1")
+	baseline.Widgets[len(baseline.Widgets)-1].Table.Rows[1].Cells[1].SetUnit("%")
+	memory := NewAuditReport(app, ctx, nil, AuditReportMemory)
+	memory.GetOrCreateChart("Memory usage, bytes").AddSeries("cart-1", timeseries.NewWithData(ctx.From, ctx.Step, []float32{1, 1, 1}))
+
+	offset := 2 * ctx.Step
+	CompareReports([]*AuditReport{current, memory}, []*AuditReport{baseline}, offset)
+
+	series := current.FindChart("CPU usage, cores").Series.Get()
+	require.Len(t, series, 2)
+	assert.Equal(t, "cart-1 (baseline)", series[1].Name)
+	assert.True(t, series[1].Dashed)
+	assert.Equal(t, "TimeSeries(120, 3, 10, [1 1 1])", series[1].Data.Get().String())
+
+	series = current.FindChart("CPU usage by container, cores").Series.Get()
+	require.Len(t, series, 3, "a stacked chart must be overlaid with the total only")
+	assert.Equal(t, "total (baseline)", series[2].Name)
+	assert.Equal(t, "TimeSeries(120, 3, 10, [2 2 2])", series[2].Data.Get().String())
+
+	series = current.FindChart("RTT to db, seconds").Series.Get()
+	require.Len(t, series, 2)
+	assert.Equal(t, "rtt (baseline)", series[1].Name)
+
+	assert.Len(t, memory.FindChart("Memory usage, bytes").Series.Get(), 1, "reports without a baseline must be left as is")
+
+	rows := current.Widgets[len(current.Widgets)-1].Table.Rows
+	assert.Equal(t, "+50%", rows[0].Cells[1].Delta)
+	assert.Equal(t, "", rows[1].Cells[1].Delta, "values with different units must not be compared")
+	assert.Equal(t, "", rows[0].Cells[2].Delta)
+}
+
+func TestDelta(t *testing.T) {
+	assert.Equal(t, "+50%", delta("1.5", "1"))
+	assert.Equal(t, "-25%", delta("3", "4"))
+	assert.Equal(t, "+200%", delta("1", "-1"))
+	assert.Equal(t, "+2", delta("2", "0"))
+	assert.Equal(t, "", delta("1", "1"))
+	assert.Equal(t, "", delta("ok", "1"))
+	assert.Equal(t, "", delta("1", ""))
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/utils"
+	"strings"
+)
+
+const CompositeCheckIdPrefix = "composite:"
+
+type CompositeOperator string
+
+const (
+	CompositeOperatorAnd     CompositeOperator = "and"
+	CompositeOperatorOr      CompositeOperator = "or"
+	CompositeOperatorAtLeast CompositeOperator = "at_least"
+)
+
+type CompositeCheck struct {
+	Id           string            `json:"id"`
+	Title        string            `json:"title"`
+	Operator     CompositeOperator `json:"operator"`
+	Count        int               `json:"count"`
+	Checks       []CheckId         `json:"checks"`
+	Applications []string          `json:"applications"`
+}
+
+func (cc *CompositeCheck) CheckId() CheckId {
+	return CheckId(CompositeCheckIdPrefix + cc.Id)
+}
+
+func (cc *CompositeCheck) Matches(appId ApplicationId) bool {
+	return utils.GlobMatch(fmt.Sprintf("%s/%s", appId.Namespace, appId.Name), cc.Applications)
+}
+
+func (cc *CompositeCheck) Config() CheckConfig {
+	var condition string
+	ids := make([]string, 0, len(cc.Checks))
+	for _, id := range cc.Checks {
+		ids = append(ids, string(id))
+	}
+	switch cc.Operator {
+	case CompositeOperatorAnd:
+		condition = strings.Join(ids, " AND ") + " fail"
+	case CompositeOperatorOr:
+		condition = strings.Join(ids, " OR ") + " fail"
+	case CompositeOperatorAtLeast:
+		condition = fmt.Sprintf("at least %d of %s fail", cc.Count, strings.Join(ids, ", "))
+	}
+	return CheckConfig{
+		Id:                      cc.CheckId(),
+		Type:                    CheckTypeManual,
+		Title:                   cc.Title,
+		ConditionFormatTemplate: condition,
+	}
+}
+
+// Evaluate calculates the composite status from the results of the underlying checks.
+// A check is considered failed if its status is WARNING or higher and it is neither muted nor suppressed.
+func (cc *CompositeCheck) Evaluate(checks map[CheckId]*Check) (Status, []string) {
+	status := OK
+	var failed []string
+	for _, id := range cc.Checks {
+		ch := checks[id]
+		if ch == nil || ch.Silenced() || ch.Status < WARNING {
+			continue
+		}
+		failed = append(failed, ch.Title)
+		if ch.Status > status {
+			status = ch.Status
+		}
+	}
+	required := cc.Count
+	switch cc.Operator {
+	case CompositeOperatorAnd:
+		required = len(cc.Checks)
+	case CompositeOperatorOr:
+		required = 1
+	}
+	if len(failed) == 0 || len(failed) < required {
+		return OK, nil
+	}
+	return status, failed
+}
@@ -39,6 +39,14 @@ type Container struct {
 	MemoryRequest *timeseries.TimeSeries
 
 	OOMKills *timeseries.TimeSeries
+
+	DNSRequests        map[DNSRequest]map[string]*timeseries.TimeSeries
+	DNSRequestsLatency *timeseries.TimeSeries
+}
+
+type DNSRequest struct {
+	Type   string
+	Domain string
 }
 
 func NewContainer(id, name string) *Container {
@@ -46,5 +54,6 @@ func NewContainer(id, name string) *Container {
 		Id:               id,
 		Name:             name,
 		ApplicationTypes: map[ApplicationType]bool{},
+		DNSRequests:      map[DNSRequest]map[string]*timeseries.TimeSeries{},
 	}
 }
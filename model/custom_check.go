@@ -0,0 +1,42 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/utils"
+)
+
+const CustomCheckIdPrefix = "custom:"
+
+type CustomCheck struct {
+	Id           string    `json:"id"`
+	Title        string    `json:"title"`
+	Query        string    `json:"query"`
+	Threshold    float32   `json:"threshold"`
+	Unit         CheckUnit `json:"unit"`
+	Applications []string  `json:"applications"`
+}
+
+func (cc *CustomCheck) CheckId() CheckId {
+	return CheckId(CustomCheckIdPrefix + cc.Id)
+}
+
+func (cc *CustomCheck) Matches(appId ApplicationId) bool {
+	return utils.GlobMatch(fmt.Sprintf("%s/%s", appId.Namespace, appId.Name), cc.Applications)
+}
+
+func (cc *CustomCheck) Config() CheckConfig {
+	return CheckConfig{
+		Id:                      cc.CheckId(),
+		Type:                    CheckTypeItemBased,
+		Title:                   cc.Title,
+		DefaultThreshold:        cc.Threshold,
+		Unit:                    cc.Unit,
+		MessageTemplate:         `{{.Items "series"}} above the threshold`,
+		ConditionFormatTemplate: "the value of a series > <threshold>",
+	}
+}
+
+type CustomCheckValues struct {
+	Check  CustomCheck
+	Values []MetricValues
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"regexp"
+)
+
+var legendLabelRe = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+
+// Dashboard is a user-defined set of panels. A panel shows either a chart from an audit report
+// of an application or the result of a PromQL query.
+type Dashboard struct {
+	Id     string           `json:"id"`
+	Name   string           `json:"name"`
+	Panels []DashboardPanel `json:"panels"`
+}
+
+type DashboardPanel struct {
+	Title string `json:"title"`
+	Width string `json:"width"`
+
+	ApplicationId string          `json:"application_id,omitempty"`
+	Report        AuditReportName `json:"report,omitempty"`
+	Chart         string          `json:"chart,omitempty"`
+
+	Query  string `json:"query,omitempty"`
+	Legend string `json:"legend,omitempty"`
+}
+
+func (p DashboardPanel) IsPromQL() bool {
+	return p.Query != ""
+}
+
+// SeriesName formats the name of a series of a PromQL panel using the legend template (e.g., "{{pod}}").
+func (p DashboardPanel) SeriesName(labels Labels) string {
+	if p.Legend == "" {
+		return labels.String()
+	}
+	return legendLabelRe.ReplaceAllStringFunc(p.Legend, func(s string) string {
+		return labels[legendLabelRe.FindStringSubmatch(s)[1]]
+	})
+}
+
+func (d Dashboard) Queries() []string {
+	var res []string
+	for _, p := range d.Panels {
+		if p.IsPromQL() {
+			res = append(res, p.Query)
+		}
+	}
+	return res
+}
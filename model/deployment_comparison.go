@@ -0,0 +1,111 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"strings"
+	"time"
+)
+
+// DeploymentComparisonWindow is the half-width of the window around a deployment, the same as linked from the Deployments report.
+const DeploymentComparisonWindow = 30 * timeseries.Minute
+
+// CompareDeploymentMetrics compares the metrics of the application within the windows following two deployments:
+// a (the baseline) and b.
+func CompareDeploymentMetrics(a, b *Application, wa, wb timeseries.Window) *Table {
+	ga, gb := deploymentWindowStats(a, wa), deploymentWindowStats(b, wb)
+	t := NewTable("Metric", "A", "B", "Change")
+	add := func(name string, va, vb float32, format func(float32) string, higherIsWorse bool) {
+		change := NewTableCell()
+		if diff := timeseries.Ratio(va, vb, canarySignificantChange); diff.String() != "" {
+			status := OK
+			if diff.Significant && higherIsWorse && diff.Change > 0 {
+				status = WARNING
+			}
+			change.SetStatus(status, diff.String())
+		}
+		t.AddRow(NewTableCell(name), NewTableCell(format(va)), NewTableCell(format(vb)), change)
+	}
+	count := func(v float32) string { return fmt.Sprint(int(v)) }
+	perSecond := func(v float32) string { return utils.FormatFloat(v) + "/s" }
+	add("Instances", float32(len(ga.instances)), float32(len(gb.instances)), count, false)
+	add("Requests", ga.requests, gb.requests, perSecond, false)
+	add("Errors", ga.errorRate, gb.errorRate, utils.FormatPercentage, true)
+	add("Latency", ga.latency*1000, gb.latency*1000, func(v float32) string { return utils.FormatFloat(v) + "ms" }, true)
+	add("CPU usage per instance", ga.cpu, gb.cpu, func(v float32) string { return utils.FormatFloat(v) + " cores" }, true)
+	add("Restarts", ga.restarts, gb.restarts, count, true)
+	add("Log errors per instance", ga.logErrors, gb.logErrors, perSecond, true)
+	return t
+}
+
+// CompareDeploymentChanges lists what differs between two deployments: the container images, the GitOps revisions,
+// and the Helm releases. Timestamps are formatted in the given location (the project's time zone).
+// Environment variables and config maps aren't collected, so such changes are visible only through GitOps syncs.
+func CompareDeploymentChanges(a, b *ApplicationDeployment, loc *time.Location) *Table {
+	t := NewTable("Attribute", "A", "B")
+	row := func(name string, va, vb []string) {
+		c, ca, cb := NewTableCell(name), NewTableCell(), NewTableCell()
+		ca.Values, cb.Values = va, vb
+		if strings.Join(va, ",") != strings.Join(vb, ",") {
+			c.SetIcon("mdi-pencil-outline", "orange")
+		}
+		t.AddRow(c, ca, cb)
+	}
+	row("Version", []string{a.Version()}, []string{b.Version()})
+	started := func(d *ApplicationDeployment) []string {
+		return []string{d.StartedAt.ToStandard().In(loc).Format("2006-01-02 15:04:05 MST")}
+	}
+	row("Started", started(a), started(b))
+	images := func(d *ApplicationDeployment) []string {
+		if d.Details == nil {
+			return nil
+		}
+		return d.Details.ContainerImages
+	}
+	row("Container images", images(a), images(b))
+	sync := func(d *ApplicationDeployment) []string {
+		if d.Details == nil || d.Details.Sync == nil {
+			return nil
+		}
+		return []string{d.Details.Sync.Application + "@" + d.Details.Sync.ShortRevision()}
+	}
+	if sa, sb := sync(a), sync(b); sa != nil || sb != nil {
+		row("GitOps revision", sa, sb)
+	}
+	helm := func(d *ApplicationDeployment) []string {
+		if d.Details == nil || d.Details.Helm == nil {
+			return nil
+		}
+		h := d.Details.Helm
+		res := []string{h.Release + ": " + h.String()}
+		if h.ValuesHash != "" {
+			res = append(res, "values "+h.ValuesHash)
+		}
+		return res
+	}
+	if ha, hb := helm(a), helm(b); ha != nil || hb != nil {
+		row("Helm release", ha, hb)
+	}
+	if b.Details != nil && len(b.Details.Commits) > 0 {
+		var commits []string
+		for _, c := range b.Details.Commits {
+			commits = append(commits, c.ShortSha()+" "+c.Message)
+		}
+		row("Commits", nil, commits)
+	}
+	return t
+}
+
+func deploymentWindowStats(app *Application, w timeseries.Window) *canaryGroup {
+	g := &canaryGroup{}
+	for _, i := range app.Instances {
+		if nanToZero(w.Sum(i.UpAndRunning())) > 0 {
+			g.instances = append(g.instances, i)
+		}
+	}
+	if len(g.instances) > 0 {
+		g.calc(instanceConnections(app), w)
+	}
+	return g
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCompareDeploymentChanges(t *testing.T) {
+	appId := NewApplicationId("default", ApplicationKindDeployment, "cart")
+	a := &ApplicationDeployment{ApplicationId: appId, Name: "cart-5d8f7c9b4", StartedAt: timeseries.Time(1700000000)}
+	b := &ApplicationDeployment{ApplicationId: appId, Name: "cart-7b6c5d4e3", StartedAt: timeseries.Time(1700003600)}
+
+	started := func(loc *time.Location) []string {
+		tbl := CompareDeploymentChanges(a, b, loc)
+		for _, r := range tbl.Rows {
+			if r.Cells[0].Text() == "Started" {
+				return []string{r.Cells[1].Text(), r.Cells[2].Text()}
+			}
+		}
+		return nil
+	}
+
+	assert.Equal(t, []string{"2023-11-14 22:13:20 UTC", "2023-11-14 23:13:20 UTC"}, started(time.UTC))
+
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2023-11-15 07:13:20 JST", "2023-11-15 08:13:20 JST"}, started(loc))
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"sort"
+	"time"
+)
+
+const (
+	deploymentRiskFreeze         = 14 * timeseries.Day
+	deploymentRiskLargeChangelog = 10
+)
+
+// ApplicationDeploymentRisk estimates how risky a rollout is, so that reviewers know which rollouts to watch closely.
+// Environment variables and config maps aren't collected, so their changes don't contribute to the score.
+type ApplicationDeploymentRisk struct {
+	Score   int
+	Factors []string
+}
+
+func (r *ApplicationDeploymentRisk) add(score int, format string, a ...any) {
+	r.Score += score
+	r.Factors = append(r.Factors, fmt.Sprintf(format, a...))
+}
+
+func (r *ApplicationDeploymentRisk) Status() Status {
+	switch {
+	case r.Score >= 60:
+		return CRITICAL
+	case r.Score >= 30:
+		return WARNING
+	}
+	return OK
+}
+
+func (r *ApplicationDeploymentRisk) Level() string {
+	switch r.Status() {
+	case CRITICAL:
+		return "high"
+	case WARNING:
+		return "medium"
+	}
+	return "low"
+}
+
+// CalcApplicationDeploymentRisk calculates the risk of the deployment compared with the previous one:
+// the images and the resource limits changed, the size of the changelog, a failed CI pipeline,
+// the time since the previous deployment, and the local time of the rollout.
+func CalcApplicationDeploymentRisk(d, prev *ApplicationDeployment, location *time.Location) *ApplicationDeploymentRisk {
+	r := &ApplicationDeploymentRisk{}
+	if prev == nil || d.IsChange() || d.IsTrafficShift() {
+		return r
+	}
+	if d.Details != nil && prev.Details != nil {
+		if added := imageNamesAdded(prev.Details.ContainerImages, d.Details.ContainerImages); len(added) > 0 {
+			r.add(30, "new image: %s", utils.LastPart(added[0], "/"))
+		}
+		if changed := limitsChanged(prev.Details.Limits, d.Details.Limits); len(changed) > 0 {
+			r.add(20, "limits changed: %s", changed[0])
+		}
+		if n := len(d.Details.Commits); n >= deploymentRiskLargeChangelog {
+			r.add(20, "%d commits", n)
+		}
+		if p := d.Details.Pipeline; p != nil && p.Status == CIPipelineStatusFailure {
+			r.add(30, "CI pipeline failed")
+		} else if p != nil && p.Tests != nil && p.Tests.Failed > 0 {
+			r.add(20, "%d tests failed", p.Tests.Failed)
+		}
+	}
+	if gap := d.StartedAt.Sub(prev.StartedAt); gap >= deploymentRiskFreeze {
+		r.add(20, "first deployment in %s", utils.FormatDuration(gap, 1))
+	}
+	t := d.StartedAt.ToStandard().In(location)
+	switch {
+	case t.Weekday() == time.Saturday || t.Weekday() == time.Sunday:
+		r.add(20, "weekend")
+	case t.Weekday() == time.Friday && t.Hour() >= 15:
+		r.add(20, "Friday afternoon")
+	case t.Hour() < 9 || t.Hour() >= 18:
+		r.add(15, "outside business hours")
+	}
+	if r.Score > 100 {
+		r.Score = 100
+	}
+	return r
+}
+
+func imageNamesAdded(prev, curr []string) []string {
+	names := utils.NewStringSet()
+	for _, i := range prev {
+		name, _ := splitImageTag(i)
+		names.Add(name)
+	}
+	var res []string
+	for _, i := range curr {
+		if name, _ := splitImageTag(i); !names.Has(name) {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+func limitsChanged(prev, curr map[string]*ApplicationDeploymentLimits) []string {
+	var res []string
+	for name, c := range curr {
+		p := prev[name]
+		if p == nil {
+			continue
+		}
+		if p.Cpu != c.Cpu {
+			res = append(res, fmt.Sprintf("%s cpu %s → %s", name, utils.FormatFloat(p.Cpu), utils.FormatFloat(c.Cpu)))
+		}
+		if p.Memory != c.Memory {
+			pv, pu := utils.FormatBytes(p.Memory)
+			cv, cu := utils.FormatBytes(c.Memory)
+			res = append(res, fmt.Sprintf("%s memory %s%s → %s%s", name, pv, pu, cv, cu))
+		}
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCalcApplicationDeploymentRisk(t *testing.T) {
+	at := func(t time.Time) timeseries.Time { return timeseries.Time(t.Unix()) }
+	prev := &ApplicationDeployment{
+		StartedAt: at(time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)), // Monday
+		Details: &ApplicationDeploymentDetails{
+			ContainerImages: []string{"registry/app:1.0"},
+			Limits:          map[string]*ApplicationDeploymentLimits{"app": {Cpu: 1}},
+		},
+	}
+
+	d := &ApplicationDeployment{
+		StartedAt: at(time.Date(2023, 5, 2, 11, 0, 0, 0, time.UTC)), // Tuesday
+		Details: &ApplicationDeploymentDetails{
+			ContainerImages: []string{"registry/app:1.1"},
+			Limits:          map[string]*ApplicationDeploymentLimits{"app": {Cpu: 1}},
+		},
+	}
+	r := CalcApplicationDeploymentRisk(d, prev, time.UTC)
+	assert.Equal(t, 0, r.Score)
+	assert.Equal(t, "low", r.Level())
+
+	d = &ApplicationDeployment{
+		StartedAt: at(time.Date(2023, 5, 19, 16, 0, 0, 0, time.UTC)), // Friday, 18 days later
+		Details: &ApplicationDeploymentDetails{
+			ContainerImages: []string{"registry/app-v2:1.0"},
+			Limits:          map[string]*ApplicationDeploymentLimits{"app": {Cpu: 2}},
+		},
+	}
+	r = CalcApplicationDeploymentRisk(d, prev, time.UTC)
+	assert.Equal(t, []string{"new image: app-v2", "limits changed: app cpu 1 → 2", "first deployment in 2 weeks", "Friday afternoon"}, r.Factors)
+	assert.Equal(t, 90, r.Score)
+	assert.Equal(t, "high", r.Level())
+
+	assert.Equal(t, 0, CalcApplicationDeploymentRisk(d, nil, time.UTC).Score)
+}
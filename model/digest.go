@@ -0,0 +1,221 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"sort"
+)
+
+const (
+	DigestPeriodDaily  = "daily"
+	DigestPeriodWeekly = "weekly"
+
+	digestMaxItems = 10
+)
+
+// DigestSchedule describes a periodic summary of the project state sent to the notification channels.
+// Cron is evaluated in the project timezone, if empty, daily digests are sent at 9:00 and weekly ones at 9:00 on Mondays.
+type DigestSchedule struct {
+	Id           string          `json:"id"`
+	Name         string          `json:"name"`
+	Period       string          `json:"period"`
+	Cron         string          `json:"cron"`
+	Slack        bool            `json:"slack"`
+	SlackChannel string          `json:"slack_channel"`
+	Teams        bool            `json:"teams"`
+	Mattermost   bool            `json:"mattermost"`
+	Content      DigestContent   `json:"content"`
+	SentAt       timeseries.Time `json:"sent_at"`
+}
+
+type DigestContent struct {
+	UnhealthyApps bool `json:"unhealthy_apps"`
+	SLO           bool `json:"slo"`
+	Deployments   bool `json:"deployments"`
+	Regressions   bool `json:"regressions"`
+}
+
+func (s DigestSchedule) Duration() timeseries.Duration {
+	if s.Period == DigestPeriodWeekly {
+		return 7 * timeseries.Day
+	}
+	return timeseries.Day
+}
+
+func (s DigestSchedule) CronExpr() string {
+	switch {
+	case s.Cron != "":
+		return s.Cron
+	case s.Period == DigestPeriodWeekly:
+		return "0 9 * * 1"
+	}
+	return "0 9 * * *"
+}
+
+// Digest is a summary of the project state over the period of a DigestSchedule.
+type Digest struct {
+	Name          string
+	Period        string
+	From          timeseries.Time
+	To            timeseries.Time
+	UnhealthyApps []DigestApplication
+	SLO           []DigestSLO
+	Deployments   []DigestDeployment
+	Regressions   []DigestRegression
+}
+
+type DigestApplication struct {
+	ApplicationId ApplicationId
+	Status        Status
+	Checks        []string
+}
+
+type DigestSLO struct {
+	ApplicationId ApplicationId
+	SLO           string
+	Objective     float32
+	Consumed      float32
+	Projected     float32
+	Status        Status
+}
+
+type DigestDeployment struct {
+	ApplicationId ApplicationId
+	Version       string
+	StartedAt     timeseries.Time
+	Status        Status
+	Summary       []ApplicationDeploymentSummary
+	Message       string
+}
+
+type DigestRegression struct {
+	ApplicationId ApplicationId
+	Title         string
+	Report        AuditReportName
+	Diff          timeseries.Diff
+}
+
+// NewDigest builds a digest from an audited world loaded for the period of the schedule.
+func NewDigest(w *World, s DigestSchedule) *Digest {
+	d := &Digest{Name: s.Name, Period: s.Period, From: w.Ctx.From, To: w.Ctx.To}
+	for _, app := range w.Applications {
+		if s.Content.UnhealthyApps && app.Status >= WARNING {
+			da := DigestApplication{ApplicationId: app.Id, Status: app.Status}
+			for _, r := range app.Reports {
+				for _, ch := range r.Checks {
+					if ch.Status >= WARNING && !ch.Silenced() {
+						da.Checks = append(da.Checks, fmt.Sprintf("%s / %s: %s", r.Name, ch.Title, ch.Message))
+					}
+				}
+			}
+			d.UnhealthyApps = append(d.UnhealthyApps, da)
+		}
+		if s.Content.SLO {
+			d.SLO = append(d.SLO, digestSLOs(app, w.Ctx.To)...)
+		}
+		if s.Content.Deployments {
+			for _, ds := range CalcApplicationDeploymentStatuses(app, w.CheckConfigs, w.Ctx.To) {
+				dep := ds.Deployment
+				if dep.StartedAt.Before(d.From) || (ds.Status < WARNING && len(ds.Summary) == 0) {
+					continue
+				}
+				d.Deployments = append(d.Deployments, DigestDeployment{
+					ApplicationId: app.Id,
+					Version:       dep.Version(),
+					StartedAt:     dep.StartedAt,
+					Status:        ds.Status,
+					Summary:       ds.Summary,
+					Message:       ds.Message,
+				})
+			}
+		}
+		if s.Content.Regressions {
+			for _, t := range app.CheckTrends {
+				if t.Degrading() {
+					d.Regressions = append(d.Regressions, DigestRegression{ApplicationId: app.Id, Title: t.Title, Report: t.Report, Diff: t.Diff})
+				}
+			}
+		}
+	}
+
+	sort.Slice(d.UnhealthyApps, func(i, j int) bool {
+		a, b := d.UnhealthyApps[i], d.UnhealthyApps[j]
+		if a.Status != b.Status {
+			return a.Status > b.Status
+		}
+		return a.ApplicationId.Name < b.ApplicationId.Name
+	})
+	sort.Slice(d.SLO, func(i, j int) bool { return d.SLO[i].Projected > d.SLO[j].Projected })
+	sort.Slice(d.Deployments, func(i, j int) bool { return d.Deployments[i].StartedAt.After(d.Deployments[j].StartedAt) })
+	sort.Slice(d.Regressions, func(i, j int) bool { return d.Regressions[i].Diff.Change > d.Regressions[j].Diff.Change })
+
+	if len(d.UnhealthyApps) > digestMaxItems {
+		d.UnhealthyApps = d.UnhealthyApps[:digestMaxItems]
+	}
+	if len(d.SLO) > digestMaxItems {
+		d.SLO = d.SLO[:digestMaxItems]
+	}
+	if len(d.Deployments) > digestMaxItems {
+		d.Deployments = d.Deployments[:digestMaxItems]
+	}
+	if len(d.Regressions) > digestMaxItems {
+		d.Regressions = d.Regressions[:digestMaxItems]
+	}
+	return d
+}
+
+func (d *Digest) IsEmpty() bool {
+	return len(d.UnhealthyApps) == 0 && len(d.SLO) == 0 && len(d.Deployments) == 0 && len(d.Regressions) == 0
+}
+
+// Status returns the worst status of the unhealthy applications.
+func (d *Digest) Status() Status {
+	status := OK
+	for _, a := range d.UnhealthyApps {
+		if a.Status > status {
+			status = a.Status
+		}
+	}
+	return status
+}
+
+func digestSLOs(app *Application, now timeseries.Time) []DigestSLO {
+	status := UNKNOWN
+	for _, r := range app.Reports {
+		if r.Name != AuditReportSLO {
+			continue
+		}
+		for _, ch := range r.Checks {
+			if ch.Id == Checks.SLOErrorBudget.Id {
+				status = ch.Status
+			}
+		}
+	}
+	objectives := map[string]float32{}
+	if len(app.AvailabilitySLIs) > 0 {
+		objectives[ErrorBudgetAvailability] = app.AvailabilitySLIs[0].Config.ObjectivePercentage
+	}
+	if len(app.LatencySLIs) > 0 {
+		objectives[ErrorBudgetLatency] = app.LatencySLIs[0].Config.ObjectivePercentage
+	}
+	var res []DigestSLO
+	for slo, objective := range objectives {
+		eb := NewErrorBudget(slo, objective, app.ErrorBudgetLedger, now)
+		if eb == nil {
+			continue
+		}
+		consumed := eb.ConsumedPercentage()
+		if timeseries.IsNaN(consumed) || consumed <= 0 {
+			continue
+		}
+		res = append(res, DigestSLO{
+			ApplicationId: app.Id,
+			SLO:           slo,
+			Objective:     objective,
+			Consumed:      consumed,
+			Projected:     eb.Projected(),
+			Status:        status,
+		})
+	}
+	return res
+}
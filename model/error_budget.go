@@ -0,0 +1,159 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"sort"
+	"time"
+)
+
+const (
+	ErrorBudgetAvailability = "availability"
+	ErrorBudgetLatency      = "latency"
+
+	ErrorBudgetRetention = 62 * timeseries.Day
+)
+
+// ErrorBudgetRecord is an entry of the error budget ledger:
+// the number of bad and total requests of an SLO within an hour starting at Time.
+type ErrorBudgetRecord struct {
+	ApplicationId ApplicationId
+	SLO           string
+	Time          timeseries.Time
+	Bad           float32
+	Total         float32
+}
+
+// NewErrorBudgetRecords aggregates the raw SLIs of the application into hourly ledger records.
+// The first hour is skipped if it's not fully covered by the data.
+func NewErrorBudgetRecords(app *Application, step timeseries.Duration) []ErrorBudgetRecord {
+	var res []ErrorBudgetRecord
+	if len(app.AvailabilitySLIs) > 0 {
+		total, failed := app.AvailabilitySLIs[0].GetTotalAndFailed(true)
+		res = append(res, errorBudgetRecords(app.Id, ErrorBudgetAvailability, failed, total, step)...)
+	}
+	if len(app.LatencySLIs) > 0 {
+		total, fast := app.LatencySLIs[0].GetTotalAndFast(true)
+		if fast.IsEmpty() {
+			fast = total.WithNewValue(0)
+		}
+		slow := timeseries.Sub(total, fast.Map(timeseries.NanToZero))
+		res = append(res, errorBudgetRecords(app.Id, ErrorBudgetLatency, slow, total, step)...)
+	}
+	return res
+}
+
+func errorBudgetRecords(appId ApplicationId, slo string, bad, total *timeseries.TimeSeries, step timeseries.Duration) []ErrorBudgetRecord {
+	if bad.IsEmpty() || total.IsEmpty() {
+		return nil
+	}
+	var res []ErrorBudgetRecord
+	var first timeseries.Time
+	bIter, tIter := bad.Iter(), total.Iter()
+	for bIter.Next() && tIter.Next() {
+		t, tv := tIter.Value()
+		_, bv := bIter.Value()
+		if first.IsZero() {
+			first = t
+		}
+		hour := t.Truncate(timeseries.Hour)
+		if hour.Before(first) || timeseries.IsNaN(tv) {
+			continue
+		}
+		if len(res) == 0 || res[len(res)-1].Time != hour {
+			res = append(res, ErrorBudgetRecord{ApplicationId: appId, SLO: slo, Time: hour})
+		}
+		r := &res[len(res)-1]
+		r.Total += tv * float32(step)
+		if !timeseries.IsNaN(bv) {
+			r.Bad += bv * float32(step)
+		}
+	}
+	return res
+}
+
+// ErrorBudgetPeriod returns the calendar month (UTC) containing the given time.
+func ErrorBudgetPeriod(t timeseries.Time) (timeseries.Time, timeseries.Time) {
+	tt := t.ToStandard().UTC()
+	from := time.Date(tt.Year(), tt.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return timeseries.Time(from.Unix()), timeseries.Time(from.AddDate(0, 1, 0).Unix())
+}
+
+// ErrorBudget tracks the consumption of the error budget of an SLO within the current period.
+// The budget is the allowed share of bad requests of all the requests expected within the period,
+// the expected number of requests is extrapolated from the ledger records available.
+type ErrorBudget struct {
+	SLO                 string
+	ObjectivePercentage float32
+	From                timeseries.Time
+	To                  timeseries.Time
+	Consumed            *timeseries.TimeSeries // hourly, percent
+
+	now   timeseries.Time
+	trend *timeseries.LinearRegression
+}
+
+func NewErrorBudget(slo string, objectivePercentage float32, ledger []ErrorBudgetRecord, now timeseries.Time) *ErrorBudget {
+	var records []ErrorBudgetRecord
+	from, to := ErrorBudgetPeriod(now)
+	for _, r := range ledger {
+		if r.SLO == slo && !r.Time.Before(from) && !r.Time.After(now) {
+			records = append(records, r)
+		}
+	}
+	if len(records) == 0 || objectivePercentage >= 100 {
+		return nil
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+
+	eb := &ErrorBudget{SLO: slo, ObjectivePercentage: objectivePercentage, From: from, To: to, now: now}
+	eb.Consumed = timeseries.New(from, int(to.Sub(from)/timeseries.Hour), timeseries.Hour)
+	allowed := 1 - objectivePercentage/100
+	period := float32(to.Sub(from))
+	start := records[0].Time
+	var bad, total float32
+	for _, r := range records {
+		bad += r.Bad
+		total += r.Total
+		if total == 0 {
+			continue
+		}
+		elapsed := float32(r.Time.Add(timeseries.Hour).Sub(start))
+		eb.Consumed.Set(r.Time, bad/total/allowed*elapsed/period*100)
+	}
+	eb.trend = timeseries.NewLinearRegression(eb.Consumed)
+	return eb
+}
+
+func (eb *ErrorBudget) ConsumedPercentage() float32 {
+	_, v := eb.Consumed.LastNotNull()
+	return v
+}
+
+// perHour returns the consumption rate of the budget (percent per hour) according to the trend.
+func (eb *ErrorBudget) perHour() float32 {
+	return eb.trend.Calc(eb.now) - eb.trend.Calc(eb.now.Add(-timeseries.Hour))
+}
+
+// Projected returns the consumption of the budget projected to the end of the period.
+func (eb *ErrorBudget) Projected() float32 {
+	v := eb.ConsumedPercentage()
+	if rate := eb.perHour(); rate > 0 {
+		v += rate * float32(eb.To.Sub(eb.now)) / float32(timeseries.Hour)
+	}
+	return v
+}
+
+// Exhaustion returns the (projected) time when the budget is exhausted, or zero if the budget is not expected to be exhausted.
+func (eb *ErrorBudget) Exhaustion() timeseries.Time {
+	iter := eb.Consumed.Iter()
+	for iter.Next() {
+		if t, v := iter.Value(); v >= 100 {
+			return t
+		}
+	}
+	rate := eb.perHour()
+	if timeseries.IsNaN(rate) || rate <= 0 {
+		return 0
+	}
+	return eb.now.Add(timeseries.Duration((100 - eb.ConsumedPercentage()) / rate * float32(timeseries.Hour)))
+}
@@ -0,0 +1,33 @@
+package model
+
+// EscalationPolicy defines where an incident of the given severity is escalated to if nobody acknowledges it in time.
+// Each step is taken once, when Delay minutes have passed since the incident was opened.
+type EscalationPolicy struct {
+	Id       string           `json:"id"`
+	Name     string           `json:"name"`
+	Severity Status           `json:"severity"`
+	Steps    []EscalationStep `json:"steps"`
+}
+
+// EscalationStep notifies the integration of the given type (e.g., slack or pagerduty) of the incident.
+// If Target is set, it overrides the recipient defined by the application owner:
+// a Slack channel, an Opsgenie escalation, or a comma-separated list of email addresses.
+type EscalationStep struct {
+	Delay       int    `json:"delay"`
+	Destination string `json:"destination"`
+	Target      string `json:"target"`
+}
+
+// Owner returns the owner of the application with the recipient of the destination replaced by Target.
+func (s EscalationStep) Owner(owner *ApplicationOwner) *ApplicationOwner {
+	return owner.WithTarget(s.Destination, s.Target)
+}
+
+func GetEscalationPolicy(policies []EscalationPolicy, severity Status) *EscalationPolicy {
+	for i := range policies {
+		if policies[i].Severity == severity && len(policies[i].Steps) > 0 {
+			return &policies[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEscalationStepOwner(t *testing.T) {
+	owner := &ApplicationOwner{Team: "payments", SlackChannel: "#payments", Email: "payments@example.com"}
+
+	assert.Equal(t, owner, EscalationStep{Destination: "slack"}.Owner(owner))
+
+	o := EscalationStep{Destination: "slack", Target: "#oncall"}.Owner(owner)
+	assert.Equal(t, "#oncall", o.SlackChannel)
+	assert.Equal(t, "payments", o.Team)
+	assert.Equal(t, "#payments", owner.SlackChannel)
+
+	o = EscalationStep{Destination: "email", Target: "cto@example.com"}.Owner(nil)
+	assert.Equal(t, []string{"cto@example.com"}, o.Emails())
+}
+
+func TestGetEscalationPolicy(t *testing.T) {
+	policies := []EscalationPolicy{
+		{Id: "empty", Severity: CRITICAL},
+		{Id: "critical", Severity: CRITICAL, Steps: []EscalationStep{{Delay: 15, Destination: "pagerduty"}}},
+	}
+	assert.Equal(t, "critical", GetEscalationPolicy(policies, CRITICAL).Id)
+	assert.Nil(t, GetEscalationPolicy(policies, WARNING))
+}
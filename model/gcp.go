@@ -0,0 +1,20 @@
+package model
+
+import "github.com/coroot/coroot/timeseries"
+
+// Gcp is a Cloud SQL or Memorystore instance monitored using the GCP APIs.
+type Gcp struct {
+	Status LabelLastValue
+
+	Engine          LabelLastValue
+	EngineVersion   LabelLastValue
+	HighlyAvailable bool
+
+	LifeSpan *timeseries.TimeSeries
+}
+
+// IsAvailable reports whether the instance is serving: RUNNABLE for Cloud SQL, READY for Memorystore.
+func (g *Gcp) IsAvailable() bool {
+	s := g.Status.Value()
+	return s == "RUNNABLE" || s == "READY"
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"sort"
+	"strings"
+)
+
+// HelmRelease describes the Helm release a pod belongs to.
+// The metadata is taken from the standard labels and the checksum annotations set by charts (e.g., checksum/config),
+// so a chart upgrade that changes only the values is distinguishable from the previous version.
+type HelmRelease struct {
+	Name       string
+	Chart      string
+	ValuesHash string
+}
+
+type HelmReleaseRevision struct {
+	Revision  int
+	CreatedAt timeseries.Time
+}
+
+func NewHelmReleaseFromLabels(labels Labels) *HelmRelease {
+	chart := labels["label_helm_sh_chart"]
+	if chart == "" && labels["label_app_kubernetes_io_managed_by"] != "Helm" {
+		return nil
+	}
+	name := labels["label_app_kubernetes_io_instance"]
+	if name == "" {
+		name = labels["label_release"]
+	}
+	if name == "" {
+		return nil
+	}
+	return &HelmRelease{Name: name, Chart: chart}
+}
+
+// HelmValuesHash returns a short hash of the checksum/* annotations of a pod.
+func HelmValuesHash(annotations Labels) string {
+	var checksums []string
+	for k, v := range annotations {
+		if strings.HasPrefix(k, "annotation_checksum_") && v != "" {
+			checksums = append(checksums, k+"="+v)
+		}
+	}
+	if len(checksums) == 0 {
+		return ""
+	}
+	sort.Strings(checksums)
+	return fmt.Sprintf("%x", sha1.Sum([]byte(strings.Join(checksums, ","))))[:8]
+}
+
+// ParseHelmReleaseSecret parses the name of a secret storing a Helm release revision: sh.helm.release.v1.<release>.v<revision>.
+func ParseHelmReleaseSecret(secret string) (string, int) {
+	s := strings.TrimPrefix(secret, "sh.helm.release.v1.")
+	if s == secret {
+		return "", 0
+	}
+	i := strings.LastIndex(s, ".v")
+	if i <= 0 {
+		return "", 0
+	}
+	var revision int
+	if _, err := fmt.Sscanf(s[i+2:], "%d", &revision); err != nil {
+		return "", 0
+	}
+	return s[:i], revision
+}
+
+// FindHelmReleaseRevision returns the latest revision created before t (with ApplicationDeploymentSyncTolerance).
+func FindHelmReleaseRevision(revisions []HelmReleaseRevision, t timeseries.Time) int {
+	res := 0
+	for _, r := range revisions {
+		if r.CreatedAt.After(t.Add(ApplicationDeploymentSyncTolerance)) {
+			continue
+		}
+		if r.Revision > res {
+			res = r.Revision
+		}
+	}
+	return res
+}
@@ -31,6 +31,7 @@ type Instance struct {
 	Pod *Pod
 
 	Rds *Rds
+	Gcp *Gcp
 
 	Jvm *Jvm
 
@@ -40,6 +41,8 @@ type Instance struct {
 
 	TcpListens map[Listen]bool
 
+	TlsCertificates map[TlsEndpoint]*timeseries.TimeSeries
+
 	Containers map[string]*Container
 
 	LogMessagesByLevel map[LogLevel]*timeseries.TimeSeries
@@ -61,6 +64,7 @@ func NewInstance(name string, owner ApplicationId) *Instance {
 		LogPatterns:        map[string]*LogPattern{},
 		Containers:         map[string]*Container{},
 		TcpListens:         map[Listen]bool{},
+		TlsCertificates:    map[TlsEndpoint]*timeseries.TimeSeries{},
 	}
 }
 
@@ -216,3 +220,10 @@ type Listen struct {
 	Port    string
 	Proxied bool
 }
+
+// TlsEndpoint identifies a TLS certificate probed at the endpoint (the target of blackbox_exporter):
+// either served by the instance or presented by a server the instance connects to (Client).
+type TlsEndpoint struct {
+	Endpoint string
+	Client   bool
+}
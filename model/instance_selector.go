@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/utils"
+)
+
+var instanceSelectorLabels = map[string]bool{"instance": true, "node": true, "role": true, "az": true, "region": true}
+
+// InstanceSelector limits a check to the instances whose labels match all the Include patterns (globs)
+// and none of the Exclude ones. The checks of the application as a whole ignore it: the SLOs, the instance availability
+// (compared to the desired number of instances) and the deployment checks.
+type InstanceSelector struct {
+	Include map[string]string `json:"include,omitempty"`
+	Exclude map[string]string `json:"exclude,omitempty"`
+}
+
+func (s *InstanceSelector) Validate() error {
+	for _, m := range []map[string]string{s.Include, s.Exclude} {
+		for k, p := range m {
+			if !instanceSelectorLabels[k] {
+				return fmt.Errorf("unknown label: %s", k)
+			}
+			if !utils.GlobValidate([]string{p}) {
+				return fmt.Errorf("invalid pattern: %s", p)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *InstanceSelector) Matches(instance *Instance) bool {
+	if s == nil {
+		return true
+	}
+	labels := instance.SelectorLabels()
+	for k, p := range s.Include {
+		if !utils.GlobMatch(labels[k], []string{p}) {
+			return false
+		}
+	}
+	for k, p := range s.Exclude {
+		if utils.GlobMatch(labels[k], []string{p}) {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectorLabels returns the labels of the instance that can be used in an InstanceSelector.
+func (instance *Instance) SelectorLabels() Labels {
+	res := Labels{
+		"instance": instance.Name,
+		"role":     instance.ClusterRoleLast().String(),
+	}
+	if n := instance.Node; n != nil {
+		res["node"] = n.Name.Value()
+		res["az"] = n.AvailabilityZone.Value()
+		res["region"] = n.Region.Value()
+	}
+	return res
+}
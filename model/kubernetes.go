@@ -19,6 +19,9 @@ const (
 	ApplicationKindExternalService ApplicationKind = "ExternalService"
 	ApplicationKindDatabaseCluster ApplicationKind = "DatabaseCluster"
 	ApplicationKindRds             ApplicationKind = "RDS"
+	ApplicationKindAws             ApplicationKind = "AWS"
+	ApplicationKindCloudSql        ApplicationKind = "CloudSQL"
+	ApplicationKindMemorystore     ApplicationKind = "Memorystore"
 	ApplicationKindNode            ApplicationKind = "Node"
 )
 
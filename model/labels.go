@@ -1,11 +1,27 @@
 package model
 
 import (
+	"fmt"
 	"github.com/coroot/coroot/timeseries"
+	"sort"
+	"strings"
 )
 
 type Labels map[string]string
 
+func (ls Labels) String() string {
+	names := make([]string, 0, len(ls))
+	for n := range ls {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, n := range names {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, n, ls[n]))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
 type MetricValues struct {
 	Labels     Labels
 	LabelsHash uint64
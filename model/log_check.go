@@ -0,0 +1,68 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+	"regexp"
+)
+
+const LogCheckIdPrefix = "log:"
+
+// LogCheck fires when the rate of the log messages matching the regexp exceeds the threshold (messages per minute).
+type LogCheck struct {
+	Id           string   `json:"id"`
+	Title        string   `json:"title"`
+	Regexp       string   `json:"regexp"`
+	Threshold    float32  `json:"threshold"`
+	Applications []string `json:"applications"`
+
+	re *regexp.Regexp
+}
+
+func (lc *LogCheck) CheckId() CheckId {
+	return CheckId(LogCheckIdPrefix + lc.Id)
+}
+
+func (lc *LogCheck) Matches(appId ApplicationId) bool {
+	return utils.GlobMatch(fmt.Sprintf("%s/%s", appId.Namespace, appId.Name), lc.Applications)
+}
+
+// UnmarshalJSON compiles the regexp once the check is loaded from the project settings,
+// since the same check is shared by the concurrent auditor workers.
+func (lc *LogCheck) UnmarshalJSON(data []byte) error {
+	type logCheck LogCheck
+	if err := json.Unmarshal(data, (*logCheck)(lc)); err != nil {
+		return err
+	}
+	lc.re = nil
+	if re, err := regexp.Compile(lc.Regexp); err != nil {
+		klog.Warningln("invalid log check regexp:", err)
+	} else {
+		lc.re = re
+	}
+	return nil
+}
+
+func (lc *LogCheck) MatchesMessage(sample string) bool {
+	re := lc.re
+	if re == nil { // the check isn't loaded from the settings, e.g., it's being dry-run
+		var err error
+		if re, err = regexp.Compile(lc.Regexp); err != nil {
+			return false
+		}
+	}
+	return re.MatchString(sample)
+}
+
+func (lc *LogCheck) Config() CheckConfig {
+	return CheckConfig{
+		Id:                      lc.CheckId(),
+		Type:                    CheckTypeValueBased,
+		Title:                   lc.Title,
+		DefaultThreshold:        lc.Threshold,
+		MessageTemplate:         `{{.Value}} matching log messages per minute`,
+		ConditionFormatTemplate: "the number of log messages matching the pattern > <threshold> per minute",
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestLogCheckMatchesMessage(t *testing.T) {
+	var checks []LogCheck
+	require.NoError(t, json.Unmarshal([]byte(`[{"id": "1", "regexp": "timeout|refused"}, {"id": "2", "regexp": "("}]`), &checks))
+	assert.NotNil(t, checks[0].re)
+	assert.Nil(t, checks[1].re)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.True(t, checks[0].MatchesMessage("dial tcp: connection refused"))
+			assert.False(t, checks[0].MatchesMessage("ok"))
+			assert.False(t, checks[1].MatchesMessage("("))
+		}()
+	}
+	wg.Wait()
+
+	lc := LogCheck{Regexp: "err"}
+	assert.True(t, lc.MatchesMessage("error"))
+	assert.Nil(t, lc.re)
+}
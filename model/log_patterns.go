@@ -22,4 +22,6 @@ type LogPatternInfo struct {
 	Percentage uint64                 `json:"percentage"`
 	Events     uint64                 `json:"events"`
 	Instances  *Chart                 `json:"instances"`
+
+	DrillDownLink *RouterLink `json:"drill_down_link,omitempty"`
 }
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+// MaintenanceWindowRetention defines how long past windows are kept to be shown on charts.
+const MaintenanceWindowRetention = 30 * timeseries.Day
+
+// MaintenanceWindow is a period of planned work in the project.
+// During the window, the checks (all or only the listed ones) are informational,
+// and deployments aren't flagged.
+type MaintenanceWindow struct {
+	Id      string          `json:"id"`
+	From    timeseries.Time `json:"from"`
+	To      timeseries.Time `json:"to"`
+	Checks  []CheckId       `json:"checks,omitempty"`
+	Comment string          `json:"comment"`
+}
+
+func (mw MaintenanceWindow) Active(t timeseries.Time) bool {
+	return !t.Before(mw.From) && !t.After(mw.To)
+}
+
+func (mw MaintenanceWindow) Covers(checkId CheckId) bool {
+	if len(mw.Checks) == 0 {
+		return true
+	}
+	for _, id := range mw.Checks {
+		if id == checkId {
+			return true
+		}
+	}
+	return false
+}
+
+type MaintenanceWindows []MaintenanceWindow
+
+func (mws MaintenanceWindows) Find(checkId CheckId, t timeseries.Time) *MaintenanceWindow {
+	for i := range mws {
+		if mws[i].Covers(checkId) && mws[i].Active(t) {
+			return &mws[i]
+		}
+	}
+	return nil
+}
+
+// ApplyToDeployments makes the deployments started during a maintenance window informational.
+func (mws MaintenanceWindows) ApplyToDeployments(statuses []ApplicationDeploymentStatus) {
+	for i := range statuses {
+		s := &statuses[i]
+		if s.Status > INFO && mws.Find(Checks.DeploymentStatus.Id, s.Deployment.StartedAt) != nil {
+			s.Status = INFO
+			if s.State == ApplicationDeploymentStateRollbackRecommended {
+				s.State = ApplicationDeploymentStateSummary
+			}
+		}
+	}
+}
+
+func (mws MaintenanceWindows) Annotations(ctx timeseries.Context) []Annotation {
+	var res []Annotation
+	for _, mw := range mws {
+		if mw.To.Before(ctx.From) || mw.From.After(ctx.To) {
+			continue
+		}
+		res = append(res, Annotation{Name: "maintenance", X1: mw.From, X2: mw.To, Icon: "mdi-wrench-outline"})
+	}
+	return res
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+const (
+	// NetworkRttBaselineWindow defines how long the history of the RTT values is kept to calculate the baseline.
+	NetworkRttBaselineWindow = 7 * timeseries.Day
+	// NetworkRttBaselineRecent is the most recent period excluded from the baseline to make degradations detectable.
+	NetworkRttBaselineRecent = timeseries.Hour
+	// NetworkRttMinDegradation is the minimal RTT increase (in seconds) considered as a degradation.
+	NetworkRttMinDegradation = 0.0005
+)
+
+type NetworkRtt struct {
+	ApplicationId ApplicationId
+	UpstreamId    ApplicationId
+	Rtt           float32
+}
+
+// UpstreamRtts returns the average RTT between the instances of the application and each upstream application.
+func (app *Application) UpstreamRtts() map[ApplicationId]*timeseries.TimeSeries {
+	sums := map[ApplicationId]*timeseries.Aggregate{}
+	counts := map[ApplicationId]*timeseries.Aggregate{}
+	for _, i := range app.Instances {
+		for _, u := range i.Upstreams {
+			if u.RemoteInstance == nil || u.Rtt == nil {
+				continue
+			}
+			id := u.RemoteInstance.OwnerId
+			if sums[id] == nil {
+				sums[id] = timeseries.NewAggregate(timeseries.NanSum)
+				counts[id] = timeseries.NewAggregate(timeseries.NanSum)
+			}
+			sums[id].Add(u.Rtt)
+			counts[id].Add(u.Rtt.Map(timeseries.Defined))
+		}
+	}
+	res := map[ApplicationId]*timeseries.TimeSeries{}
+	for id := range sums {
+		res[id] = timeseries.Div(sums[id].Get(), counts[id].Get())
+	}
+	return res
+}
+
+// NetworkRtts returns the current RTT values to be stored to calculate the baselines.
+func (app *Application) NetworkRtts() []NetworkRtt {
+	var res []NetworkRtt
+	for id, rtt := range app.UpstreamRtts() {
+		if last := rtt.Last(); !timeseries.IsNaN(last) {
+			res = append(res, NetworkRtt{ApplicationId: app.Id, UpstreamId: id, Rtt: last})
+		}
+	}
+	return res
+}
+
+// RttDegraded reports whether the RTT exceeds the baseline by more than the given percentage.
+func RttDegraded(rtt, baseline, percentage float32) bool {
+	return rtt-baseline > NetworkRttMinDegradation && rtt > baseline*(1+percentage/100)
+}
+
+// RttDegradationAnnotations returns the periods when the RTT exceeded the baseline by more than the given percentage.
+func RttDegradationAnnotations(rtt *timeseries.TimeSeries, baseline, percentage float32) []Annotation {
+	var res []Annotation
+	var current *Annotation
+	iter := rtt.Iter()
+	for iter.Next() {
+		t, v := iter.Value()
+		if !timeseries.IsNaN(v) && RttDegraded(v, baseline, percentage) {
+			if current == nil {
+				current = &Annotation{Name: "RTT degradation", X1: t, Icon: "mdi-lan-disconnect"}
+			}
+			current.X2 = t
+			continue
+		}
+		if current != nil {
+			res = append(res, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		res = append(res, *current)
+	}
+	return res
+}
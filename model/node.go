@@ -44,6 +44,9 @@ type Node struct {
 	Disks         map[string]*DiskStats
 	NetInterfaces []*InterfaceStats
 
+	ConntrackEntries *timeseries.TimeSeries // from node_exporter
+	ConntrackLimit   *timeseries.TimeSeries
+
 	Instances []*Instance `json:"-"`
 
 	CloudProvider     LabelLastValue
@@ -0,0 +1,75 @@
+package model
+
+import "fmt"
+
+// NotificationLimits protect the notification channels from alert storms.
+// An incident is opened (or its severity is changed) only if the SLO status persists for OpenAfter consecutive evaluations
+// and is resolved only if the recovery persists for ResolveAfter evaluations. HourlyLimit and ChannelHourlyLimit limit
+// the number of incident notifications per hour sent by the project and to each integration. Zero values disable the limits.
+type NotificationLimits struct {
+	OpenAfter          int `json:"open_after"`
+	ResolveAfter       int `json:"resolve_after"`
+	HourlyLimit        int `json:"hourly_limit"`
+	ChannelHourlyLimit int `json:"channel_hourly_limit"`
+}
+
+func (l NotificationLimits) Validate() error {
+	if l.OpenAfter < 0 || l.OpenAfter > 60 || l.ResolveAfter < 0 || l.ResolveAfter > 60 {
+		return fmt.Errorf("the number of evaluations must be between 0 and 60")
+	}
+	if l.HourlyLimit < 0 || l.ChannelHourlyLimit < 0 {
+		return fmt.Errorf("negative limit")
+	}
+	return nil
+}
+
+// FlapSuppressor holds the changes of the SLO status of applications until they persist for the required number of evaluations.
+type FlapSuppressor struct {
+	states map[ApplicationId]*flapState
+}
+
+type flapState struct {
+	accepted Status
+	pending  Status
+	count    int
+}
+
+func NewFlapSuppressor() *FlapSuppressor {
+	return &FlapSuppressor{states: map[ApplicationId]*flapState{}}
+}
+
+// Update returns the status the incident of the application must have given the observed one.
+// UNKNOWN means the status of the incident is not known yet (e.g., after a restart), so it must be left as is.
+func (s *FlapSuppressor) Update(appId ApplicationId, observed Status, limits NotificationLimits) Status {
+	st := s.states[appId]
+	if st == nil {
+		st = &flapState{accepted: UNKNOWN}
+		s.states[appId] = st
+	}
+	if observed == st.accepted {
+		st.pending, st.count = UNKNOWN, 0
+		return st.accepted
+	}
+	if observed == st.pending {
+		st.count++
+	} else {
+		st.pending, st.count = observed, 1
+	}
+	required := limits.OpenAfter
+	if observed == OK {
+		required = limits.ResolveAfter
+	}
+	if st.count >= required {
+		st.accepted, st.pending, st.count = observed, UNKNOWN, 0
+	}
+	return st.accepted
+}
+
+// Retain drops the states of the applications that are no longer present.
+func (s *FlapSuppressor) Retain(appIds map[ApplicationId]bool) {
+	for id := range s.states {
+		if !appIds[id] {
+			delete(s.states, id)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFlapSuppressor(t *testing.T) {
+	app := NewApplicationId("shop", ApplicationKindDeployment, "cart")
+	limits := NotificationLimits{OpenAfter: 3, ResolveAfter: 2}
+	s := NewFlapSuppressor()
+
+	assert.Equal(t, UNKNOWN, s.Update(app, OK, limits))
+	assert.Equal(t, OK, s.Update(app, OK, limits))
+
+	assert.Equal(t, OK, s.Update(app, CRITICAL, limits))
+	assert.Equal(t, OK, s.Update(app, OK, limits))
+	assert.Equal(t, OK, s.Update(app, CRITICAL, limits))
+	assert.Equal(t, OK, s.Update(app, CRITICAL, limits))
+	assert.Equal(t, CRITICAL, s.Update(app, CRITICAL, limits))
+
+	assert.Equal(t, CRITICAL, s.Update(app, OK, limits))
+	assert.Equal(t, CRITICAL, s.Update(app, CRITICAL, limits))
+	assert.Equal(t, CRITICAL, s.Update(app, OK, limits))
+	assert.Equal(t, OK, s.Update(app, OK, limits))
+
+	s = NewFlapSuppressor()
+	assert.Equal(t, WARNING, s.Update(app, WARNING, NotificationLimits{}))
+
+	s.Retain(map[ApplicationId]bool{})
+	assert.Equal(t, UNKNOWN, s.Update(app, OK, limits))
+}
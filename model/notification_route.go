@@ -0,0 +1,70 @@
+package model
+
+import "github.com/coroot/coroot/utils"
+
+// NotificationRoute sends the incidents matching its conditions through the Destination integration (e.g., slack or email).
+// Target overrides the recipient: a Slack channel, an Opsgenie escalation, or a comma-separated list of email addresses;
+// if it's empty, the incidents are sent to the owner of the application or the default recipient of the integration.
+// Empty conditions match any incident. Namespaces and Checks are glob patterns; Checks match if any of the failing checks does.
+// Teams are glob patterns matched against the team of the application owner.
+// Severity and MaxSeverity are the minimum and the maximum severity of the incident, e.g., to send warnings and criticals to different channels.
+type NotificationRoute struct {
+	Id          string                `json:"id"`
+	Name        string                `json:"name"`
+	Namespaces  []string              `json:"namespaces"`
+	Categories  []ApplicationCategory `json:"categories"`
+	Teams       []string              `json:"teams"`
+	Severity    Status                `json:"severity"`
+	MaxSeverity Status                `json:"max_severity"`
+	Checks      []string              `json:"checks"`
+	Destination string                `json:"destination"`
+	Target      string                `json:"target"`
+}
+
+func (r NotificationRoute) Matches(app *Application, severity Status) bool {
+	if r.Severity > OK && severity < r.Severity {
+		return false
+	}
+	if r.MaxSeverity > OK && severity > r.MaxSeverity {
+		return false
+	}
+	if len(r.Teams) > 0 && (app.Owner == nil || !utils.GlobMatch(app.Owner.Team, r.Teams)) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !utils.GlobMatch(app.Id.Namespace, r.Namespaces) {
+		return false
+	}
+	if len(r.Categories) > 0 {
+		matched := false
+		for _, c := range r.Categories {
+			if c == app.Category {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.Checks) == 0 {
+		return true
+	}
+	for _, report := range app.Reports {
+		for _, ch := range report.Checks {
+			if ch.Status >= WARNING && !ch.Silenced() && utils.GlobMatch(string(ch.Id), r.Checks) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetNotificationRoute returns the first route of the destination matching the incident of the application.
+func GetNotificationRoute(routes []NotificationRoute, app *Application, severity Status, destination string) *NotificationRoute {
+	for i := range routes {
+		if routes[i].Destination == destination && routes[i].Matches(app, severity) {
+			return &routes[i]
+		}
+	}
+	return nil
+}
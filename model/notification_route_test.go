@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNotificationRoute(t *testing.T) {
+	app := NewApplication(NewApplicationId("db", ApplicationKindStatefulSet, "postgres"))
+	app.Category = "databases"
+	app.Reports = []*AuditReport{{Name: AuditReportPostgres, Checks: []*Check{
+		{Id: "PostgresReplicationLag", Status: CRITICAL},
+		{Id: "PostgresConnections", Status: OK},
+	}}}
+
+	routes := []NotificationRoute{
+		{Id: "latency", Destination: "slack", Checks: []string{"SLOLatency"}},
+		{Id: "dba", Destination: "slack", Categories: []ApplicationCategory{"databases"}, Checks: []string{"Postgres*"}, Target: "#dba"},
+		{Id: "critical", Destination: "email", Severity: CRITICAL, Namespaces: []string{"db", "prod-*"}},
+	}
+	assert.Equal(t, "dba", GetNotificationRoute(routes, app, WARNING, "slack").Id)
+	assert.Nil(t, GetNotificationRoute(routes, app, WARNING, "email"))
+	assert.Equal(t, "critical", GetNotificationRoute(routes, app, CRITICAL, "email").Id)
+	assert.Nil(t, GetNotificationRoute(routes, app, CRITICAL, "pagerduty"))
+
+	app.Reports[0].Checks[0].Muted = true
+	assert.Nil(t, GetNotificationRoute(routes, app, WARNING, "slack"))
+
+	app.Owner = &ApplicationOwner{Team: "payments"}
+	routes = []NotificationRoute{
+		{Id: "digest", Destination: "slack", Teams: []string{"pay*"}, Severity: WARNING, MaxSeverity: WARNING, Target: "#payments-digest"},
+		{Id: "paging", Destination: "slack", Teams: []string{"pay*"}, Severity: CRITICAL, Target: "#payments-oncall"},
+		{Id: "other", Destination: "slack", Teams: []string{"search"}},
+	}
+	assert.Equal(t, "digest", GetNotificationRoute(routes, app, WARNING, "slack").Id)
+	assert.Equal(t, "paging", GetNotificationRoute(routes, app, CRITICAL, "slack").Id)
+	app.Owner = nil
+	assert.Nil(t, GetNotificationRoute(routes, app, WARNING, "slack"))
+
+	o := (&ApplicationOwner{Team: "dba"}).WithTarget("slack", "#dba")
+	assert.Equal(t, "#dba", o.SlackChannel)
+	assert.Equal(t, "dba", o.Team)
+}
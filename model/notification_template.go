@@ -0,0 +1,24 @@
+package model
+
+// NotificationTemplate customizes the incident notifications sent to an integration (e.g., to translate them).
+// Title and Body are Go templates, the empty ones keep the default wording.
+type NotificationTemplate struct {
+	Destination string `json:"destination"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+}
+
+func (t NotificationTemplate) IsEmpty() bool {
+	return t.Title == "" && t.Body == ""
+}
+
+type NotificationTemplates []NotificationTemplate
+
+func (ts NotificationTemplates) Get(destination string) *NotificationTemplate {
+	for i := range ts {
+		if ts[i].Destination == destination && !ts[i].IsEmpty() {
+			return &ts[i]
+		}
+	}
+	return nil
+}
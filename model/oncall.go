@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"time"
+)
+
+// QuietHours is the time range (in the project timezone) during which the notifications of non-critical incidents
+// and of deployments are held and sent once the quiet hours end. From and To are "HH:MM", the range may cross midnight.
+// Days limits the quiet hours to the given days of the week (every day if empty).
+type QuietHours struct {
+	Enabled bool           `json:"enabled"`
+	Days    []time.Weekday `json:"days,omitempty"`
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+}
+
+func (q QuietHours) Validate() error {
+	if !q.Enabled {
+		return nil
+	}
+	return validateTimeOfDayRange(q.Days, q.From, q.To)
+}
+
+// Active reports whether the quiet hours cover the given time, t must be in the project timezone.
+func (q QuietHours) Active(t time.Time) bool {
+	return q.Enabled && timeOfDayRangeActive(q.Days, q.From, q.To, t)
+}
+
+// OnCallSchedule is a rotation of the members responsible for critical incidents.
+// The first member is on call from Start for RotationDays days, then the next one, and so on.
+type OnCallSchedule struct {
+	Enabled      bool            `json:"enabled"`
+	Start        timeseries.Time `json:"start"`
+	RotationDays int             `json:"rotation_days"`
+	Members      []OnCallMember  `json:"members"`
+}
+
+// OnCallMember is reachable through a Slack channel (or a user ID for direct messages), email, and phone.
+type OnCallMember struct {
+	Name         string `json:"name"`
+	SlackChannel string `json:"slack_channel"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+}
+
+func (s OnCallSchedule) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	if s.RotationDays < 1 {
+		return fmt.Errorf("invalid rotation period: %d", s.RotationDays)
+	}
+	if len(s.Members) == 0 {
+		return fmt.Errorf("no members")
+	}
+	for _, m := range s.Members {
+		if m.Name == "" {
+			return fmt.Errorf("empty member name")
+		}
+		if err := ValidatePhones(m.Phone); err != nil {
+			return fmt.Errorf("%s: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// Current returns the member on call at the given time.
+func (s OnCallSchedule) Current(t timeseries.Time) *OnCallMember {
+	if !s.Enabled || len(s.Members) == 0 || s.RotationDays < 1 || t.Before(s.Start) {
+		return nil
+	}
+	rotation := int(t.Sub(s.Start) / (timeseries.Duration(s.RotationDays) * timeseries.Day))
+	return &s.Members[rotation%len(s.Members)]
+}
+
+// Owner returns the owner of the application with the Slack channel, the email, and the phone replaced by the ones of the member.
+func (m *OnCallMember) Owner(owner *ApplicationOwner) *ApplicationOwner {
+	return owner.WithTarget("slack", m.SlackChannel).WithTarget("email", m.Email).WithTarget("twilio", m.Phone)
+}
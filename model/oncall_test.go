@@ -0,0 +1,42 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOnCallSchedule(t *testing.T) {
+	start := timeseries.Time(1700000000)
+	s := OnCallSchedule{Enabled: true, Start: start, RotationDays: 7, Members: []OnCallMember{{Name: "alice"}, {Name: "bob"}}}
+	assert.Nil(t, s.Current(start.Add(-timeseries.Hour)))
+	assert.Equal(t, "alice", s.Current(start).Name)
+	assert.Equal(t, "alice", s.Current(start.Add(7*timeseries.Day-timeseries.Second)).Name)
+	assert.Equal(t, "bob", s.Current(start.Add(7*timeseries.Day)).Name)
+	assert.Equal(t, "alice", s.Current(start.Add(14*timeseries.Day)).Name)
+
+	s.Members[1].Phone = "+14155552671, +442071838750"
+	assert.NoError(t, s.Validate())
+	assert.Equal(t, []string{"+14155552671", "+442071838750"}, s.Members[1].Owner(nil).Phones())
+	s.Members[1].Phone = "4155552671"
+	assert.Error(t, s.Validate())
+
+	s.Enabled = false
+	assert.Nil(t, s.Current(start))
+}
+
+func TestQuietHours(t *testing.T) {
+	q := QuietHours{Enabled: true, From: "22:00", To: "08:00", Days: []time.Weekday{time.Friday}}
+	assert.NoError(t, q.Validate())
+	friday := time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+	assert.True(t, q.Active(friday.Add(23*time.Hour)))
+	assert.True(t, q.Active(friday.Add(31*time.Hour))) // Saturday 07:00
+	assert.False(t, q.Active(friday.Add(7*time.Hour)))
+	assert.False(t, q.Active(friday.Add(12*time.Hour)))
+
+	q.Enabled = false
+	assert.False(t, q.Active(friday.Add(23*time.Hour)))
+	q.From = "25:00"
+	assert.NoError(t, q.Validate())
+}
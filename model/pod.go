@@ -12,10 +12,25 @@ type Pod struct {
 	LifeSpan *timeseries.TimeSeries
 
 	ReplicaSet string
+	// ControllerRevision is the revision of a StatefulSet or DaemonSet pod prefixed with the name of the owner,
+	// so that it looks like the name of a ReplicaSet.
+	ControllerRevision string
+
+	Helm *HelmRelease
+
+	Owner *ApplicationOwner
 
 	InitContainers map[string]*Container
 }
 
+// Revision returns the ReplicaSet of a Deployment pod or the controller revision of a StatefulSet or DaemonSet pod.
+func (pod *Pod) Revision() string {
+	if pod.ReplicaSet != "" {
+		return pod.ReplicaSet
+	}
+	return pod.ControllerRevision
+}
+
 func (pod *Pod) IsRunning() bool {
 	return pod.Phase == "Running"
 }
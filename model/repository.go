@@ -0,0 +1,92 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Repository maps container images to the Git repository they're built from, so that deployments can be linked
+// to commits and changelogs. Image is an image name without the tag (e.g., ghcr.io/org/app) or a prefix ending with "*".
+// Url is the web URL of a GitHub or GitLab repository. Token is used to fetch the commits between versions.
+// The image tags are expected to be commit SHAs or Git tags (the "sha-" prefix is trimmed).
+type Repository struct {
+	Id    string `json:"id"`
+	Image string `json:"image"`
+	Url   string `json:"url"`
+	Token string `json:"token"`
+}
+
+type Repositories []Repository
+
+func (r *Repository) IsGitLab() bool {
+	u, err := url.Parse(r.Url)
+	return err == nil && strings.Contains(u.Host, "gitlab")
+}
+
+func (r *Repository) CommitUrl(revision string) string {
+	if r.IsGitLab() {
+		return strings.TrimSuffix(r.Url, "/") + "/-/commit/" + revision
+	}
+	return strings.TrimSuffix(r.Url, "/") + "/commit/" + revision
+}
+
+func (r *Repository) CompareUrl(from, to string) string {
+	if r.IsGitLab() {
+		return strings.TrimSuffix(r.Url, "/") + "/-/compare/" + from + "..." + to
+	}
+	return strings.TrimSuffix(r.Url, "/") + "/compare/" + from + "..." + to
+}
+
+func (r *Repository) matches(image string) bool {
+	if prefix := strings.TrimSuffix(r.Image, "*"); prefix != r.Image {
+		return strings.HasPrefix(image, prefix)
+	}
+	return image == r.Image
+}
+
+// Revision returns the repository of the first mapped container image of the deployment and the revision it was built from.
+func (rs Repositories) Revision(d *ApplicationDeployment) (*Repository, string) {
+	if d.Details == nil {
+		return nil, ""
+	}
+	for _, image := range d.Details.ContainerImages {
+		name, tag := splitImageTag(image)
+		if tag == "" || tag == "latest" {
+			continue
+		}
+		for i := range rs {
+			if rs[i].matches(name) {
+				return &rs[i], strings.TrimPrefix(tag, "sha-")
+			}
+		}
+	}
+	return nil, ""
+}
+
+func splitImageTag(image string) (string, string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
+
+// CommitSha returns the commit SHA of the deployment: the revision of the image mapped to a repository,
+// the GitOps revision, or the newest commit of the changelog.
+func (rs Repositories) CommitSha(d *ApplicationDeployment) string {
+	if _, rev := rs.Revision(d); rev != "" {
+		return rev
+	}
+	if d.Details == nil {
+		return ""
+	}
+	if d.Details.Sync != nil && d.Details.Sync.Revision != "" {
+		return d.Details.Sync.Revision
+	}
+	if len(d.Details.Commits) > 0 {
+		return d.Details.Commits[0].Sha
+	}
+	return ""
+}
@@ -0,0 +1,15 @@
+package model
+
+// SavedView is a bookmarked investigation context: a report of an application, the time range,
+// the charts selected in the chart groups, and the comparison mode.
+// From and To are kept as in the URL, so relative ranges (e.g., now-1h) stay relative.
+type SavedView struct {
+	Id            string            `json:"id"`
+	Name          string            `json:"name"`
+	ApplicationId string            `json:"application_id"`
+	Report        string            `json:"report"`
+	From          string            `json:"from"`
+	To            string            `json:"to"`
+	ChartGroups   map[string]string `json:"chart_groups"`
+	Compare       string            `json:"compare"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"math"
+	"regexp"
+	"sort"
+)
+
+// SchemaChangesWindow is how long before a deployment starts and after it finishes schema changes are attributed to it.
+const SchemaChangesWindow = 10 * timeseries.Minute
+
+var (
+	ddlQuery       = regexp.MustCompile(`(?is)^\s*(create|alter|drop|rename|truncate|comment\s+on)\s`)
+	migrationQuery = regexp.MustCompile(`(?is)^\s*insert\s+into\s+("?\w+"?\.)?"?(schema_migrations|flyway_schema_history|goose_db_version|django_migrations|alembic_version|__EFMigrationsHistory|knex_migrations|SequelizeMeta|databasechangelog)\b`)
+)
+
+type ApplicationDeploymentSchemaChanges struct {
+	Count   int      `json:"count"`
+	Queries []string `json:"queries,omitempty"`
+}
+
+func (sc *ApplicationDeploymentSchemaChanges) String() string {
+	if sc.Count == 1 {
+		return "1 schema change applied"
+	}
+	return fmt.Sprintf("%d schema changes applied", sc.Count)
+}
+
+// CalcSchemaChanges counts the schema changes applied to the Postgres databases used by the application within the window:
+// the migrations recorded by migration tools (e.g., Flyway, Liquibase, golang-migrate, Django) or, if none are found,
+// the DDL statements. The statements are taken from the top queries of pg_stat_statements, so rarely executed statements
+// can be missed on busy databases.
+func CalcSchemaChanges(w *World, app *Application, window timeseries.Window) *ApplicationDeploymentSchemaChanges {
+	ddl, migrations := map[string]float32{}, map[string]float32{}
+	for id := range app.GetUpstreamsConnections() {
+		upstream := w.GetApplication(id)
+		if upstream == nil {
+			continue
+		}
+		for _, i := range upstream.Instances {
+			if i.Postgres == nil {
+				continue
+			}
+			for k, qs := range i.Postgres.PerQuery {
+				var byQuery map[string]float32
+				switch {
+				case migrationQuery.MatchString(k.Query):
+					byQuery = migrations
+				case ddlQuery.MatchString(k.Query):
+					byQuery = ddl
+				default:
+					continue
+				}
+				if calls := window.Sum(qs.Calls); calls > 0 {
+					byQuery[k.Query] += calls * float32(w.Ctx.Step)
+				}
+			}
+		}
+	}
+	byQuery := migrations
+	if len(byQuery) == 0 {
+		byQuery = ddl
+	}
+	res := &ApplicationDeploymentSchemaChanges{}
+	for q, calls := range byQuery {
+		res.Count += int(math.Max(1, math.Round(float64(calls))))
+		res.Queries = append(res.Queries, q)
+	}
+	sort.Strings(res.Queries)
+	if len(res.Queries) > 5 {
+		res.Queries = res.Queries[:5]
+	}
+	return res
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSchemaChangeQueries(t *testing.T) {
+	assert.True(t, ddlQuery.MatchString("ALTER TABLE users ADD COLUMN email text"))
+	assert.True(t, ddlQuery.MatchString("  create index concurrently idx on orders (created_at)"))
+	assert.False(t, ddlQuery.MatchString("SELECT * FROM users WHERE created_at > $1"))
+	assert.False(t, ddlQuery.MatchString("UPDATE accounts SET dropped = $1"))
+
+	assert.True(t, migrationQuery.MatchString(`INSERT INTO "public"."flyway_schema_history" (installed_rank, version) VALUES ($1, $2)`))
+	assert.True(t, migrationQuery.MatchString("insert into schema_migrations (version) values ($1)"))
+	assert.False(t, migrationQuery.MatchString("INSERT INTO orders (id) VALUES ($1)"))
+}
@@ -17,6 +17,18 @@ type AvailabilitySLI struct {
 	FailedRequestsRaw *timeseries.TimeSeries
 }
 
+// GetTotalAndFailed returns the total and failed requests, missing failed requests are considered as zero.
+func (sli *AvailabilitySLI) GetTotalAndFailed(raw bool) (*timeseries.TimeSeries, *timeseries.TimeSeries) {
+	total, failed := sli.TotalRequests, sli.FailedRequests
+	if raw {
+		total, failed = sli.TotalRequestsRaw, sli.FailedRequestsRaw
+	}
+	if failed.IsEmpty() {
+		return total, total.WithNewValue(0)
+	}
+	return total, failed.Map(timeseries.NanToZero)
+}
+
 type HistogramBucket struct {
 	Le         float32
 	TimeSeries *timeseries.TimeSeries
@@ -29,21 +41,46 @@ type LatencySLI struct {
 	HistogramRaw []HistogramBucket
 }
 
+// GetTotalAndFast returns the total number of requests and the number of requests served faster than the objective.
+// If the objective doesn't match any bucket boundary, the number of fast requests is interpolated within the bucket.
 func (sli *LatencySLI) GetTotalAndFast(raw bool) (*timeseries.TimeSeries, *timeseries.TimeSeries) {
-	var total, fast *timeseries.TimeSeries
+	var total *timeseries.TimeSeries
+	var lower, upper *HistogramBucket
 	histogram := sli.Histogram
 	if raw {
 		histogram = sli.HistogramRaw
 	}
-	for _, b := range histogram {
-		if b.Le <= sli.Config.ObjectiveBucket {
-			fast = b.TimeSeries
-		}
-		if timeseries.IsInf(b.Le, 1) {
+	objective := sli.Config.ObjectiveBucket
+	for i := range histogram {
+		b := &histogram[i]
+		switch {
+		case timeseries.IsInf(b.Le, 1):
 			total = b.TimeSeries
+		case b.Le <= objective:
+			lower = b
+		case upper == nil:
+			upper = b
+		}
+	}
+	switch {
+	case lower != nil && (lower.Le == objective || upper == nil):
+		return total, lower.TimeSeries
+	case upper != nil:
+		lowerLe, lowerTs := float32(0), upper.TimeSeries.WithNewValue(0)
+		if lower != nil {
+			lowerLe, lowerTs = lower.Le, lower.TimeSeries
 		}
+		return total, timeseries.Aggregate2(lowerTs, upper.TimeSeries, func(l, u float32) float32 {
+			return interpolateFast(lowerLe, l, upper.Le, u, objective)
+		})
 	}
-	return total, fast
+	return total, nil
+}
+
+// interpolateFast estimates the number of requests served faster than the objective assuming
+// that requests are distributed uniformly within the bucket (the same way histogram_quantile does).
+func interpolateFast(lowerLe, lower, upperLe, upper, objective float32) float32 {
+	return lower + (upper-lower)*(objective-lowerLe)/(upperLe-lowerLe)
 }
 
 func HistogramSeries(buckets []HistogramBucket, objectiveBucket, objectivePercentage float32) []Series {
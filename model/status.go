@@ -31,6 +31,31 @@ func (s Status) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.String())
 }
 
+func (s *Status) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		var i int
+		if err := json.Unmarshal(b, &i); err != nil {
+			return err
+		}
+		*s = Status(i)
+		return nil
+	}
+	switch str {
+	case "ok":
+		*s = OK
+	case "info":
+		*s = INFO
+	case "warning":
+		*s = WARNING
+	case "critical":
+		*s = CRITICAL
+	default:
+		*s = UNKNOWN
+	}
+	return nil
+}
+
 func (s Status) Color() string {
 	switch s {
 	case OK:
@@ -0,0 +1,28 @@
+package model
+
+// StatusPageHistory is limited by the retention of the check values.
+const StatusPageHistory = CheckValuesRetention
+
+// StatusPage is a public read-only page showing the current status, the uptime history, and the incidents
+// of the published applications. The uptime is derived from the results of the SLO checks.
+type StatusPage struct {
+	Id           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	Description  string                  `json:"description"`
+	Applications []StatusPageApplication `json:"applications"`
+}
+
+// StatusPageApplication is an application published on a status page. Checks are the SLO checks the status and
+// the uptime are derived from (both availability and latency if empty).
+type StatusPageApplication struct {
+	ApplicationId string    `json:"application_id"`
+	Title         string    `json:"title"`
+	Checks        []CheckId `json:"checks"`
+}
+
+func (a StatusPageApplication) CheckIds() []CheckId {
+	if len(a.Checks) > 0 {
+		return a.Checks
+	}
+	return []CheckId{Checks.SLOAvailability.Id, Checks.SLOLatency.Id}
+}
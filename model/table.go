@@ -1,18 +1,37 @@
 package model
 
 import (
+	"encoding/csv"
 	"fmt"
 	"github.com/coroot/coroot/timeseries"
+	"io"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 type Table struct {
 	Header []string    `json:"header"`
 	Rows   []*TableRow `json:"rows"`
+	Total  int         `json:"total,omitempty"` // the number of rows matching the query, set if the table is paginated
 
 	sorted bool
 }
 
+// TableQuery defines a page of a table. Filters are case-insensitive substrings matched against the cells
+// of the columns (by index), rows are sorted by the columns in the order given.
+type TableQuery struct {
+	Offset  int
+	Limit   int
+	Filters map[int]string
+	Sort    []TableSort
+}
+
+type TableSort struct {
+	Column int
+	Desc   bool
+}
+
 func NewTable(header ...string) *Table {
 	return &Table{Header: header}
 }
@@ -38,6 +57,103 @@ func (t *Table) SetSorted(s bool) *Table {
 	return t
 }
 
+// Query returns a new table containing the rows matching the query. The original table isn't modified.
+func (t *Table) Query(q TableQuery) *Table {
+	res := &Table{Header: t.Header, sorted: true}
+	for _, r := range t.Rows {
+		if r.matches(q.Filters) {
+			res.Rows = append(res.Rows, r)
+		}
+	}
+	if len(q.Sort) > 0 {
+		sort.SliceStable(res.Rows, func(i, j int) bool {
+			for _, s := range q.Sort {
+				c := compareCells(res.Rows[i].cell(s.Column), res.Rows[j].cell(s.Column))
+				if c == 0 {
+					continue
+				}
+				return (c < 0) != s.Desc
+			}
+			return false
+		})
+	}
+	res.Total = len(res.Rows)
+	if q.Offset > 0 {
+		if q.Offset >= len(res.Rows) {
+			res.Rows = nil
+		} else {
+			res.Rows = res.Rows[q.Offset:]
+		}
+	}
+	if q.Limit > 0 && len(res.Rows) > q.Limit {
+		res.Rows = res.Rows[:q.Limit]
+	}
+	return res
+}
+
+// WriteCSV writes the table in CSV format. Units and tags are written to separate columns
+// following the value column, if any cell of the column has them.
+func (t *Table) WriteCSV(w io.Writer) error {
+	withUnits := make([]bool, len(t.Header))
+	withTags := make([]bool, len(t.Header))
+	for _, r := range t.Rows {
+		for i, c := range r.Cells {
+			if i >= len(t.Header) || c == nil {
+				continue
+			}
+			withUnits[i] = withUnits[i] || c.Unit != ""
+			withTags[i] = withTags[i] || len(c.Tags) > 0
+		}
+	}
+	cw := csv.NewWriter(w)
+	var header []string
+	for i, h := range t.Header {
+		header = append(header, csvEscape(h))
+		if withUnits[i] {
+			header = append(header, csvEscape(h+" unit"))
+		}
+		if withTags[i] {
+			header = append(header, csvEscape(h+" tags"))
+		}
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range t.Rows {
+		var record []string
+		for i := range t.Header {
+			c := &TableCell{}
+			if i < len(r.Cells) && r.Cells[i] != nil {
+				c = r.Cells[i]
+			}
+			record = append(record, csvEscape(c.Text()))
+			if withUnits[i] {
+				record = append(record, csvEscape(c.Unit))
+			}
+			if withTags[i] {
+				record = append(record, csvEscape(strings.Join(c.Tags, "; ")))
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvEscape prevents spreadsheet applications from interpreting cells, such as log messages or container names,
+// as formulas by prefixing the values starting with a formula character with a single quote. Numbers are left as is.
+func csvEscape(s string) string {
+	if s == "" || !strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return s
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "InfinityNaN") {
+		return s
+	}
+	return "'" + s
+}
+
 type TableRow struct {
 	Id    string       `json:"id"`
 	Cells []*TableCell `json:"cells"`
@@ -48,6 +164,47 @@ func (r *TableRow) SetId(id string) *TableRow {
 	return r
 }
 
+func (r *TableRow) cell(i int) *TableCell {
+	if i < 0 || i >= len(r.Cells) || r.Cells[i] == nil {
+		return &TableCell{}
+	}
+	return r.Cells[i]
+}
+
+func (r *TableRow) matches(filters map[int]string) bool {
+	for i, f := range filters {
+		if f == "" {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(r.cell(i).Text()), strings.ToLower(f)) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareCells compares cells numerically if both values are numbers, otherwise as strings.
+func compareCells(a, b *TableCell) int {
+	av, bv := a.Text(), b.Text()
+	af, aErr := strconv.ParseFloat(av, 64)
+	bf, bErr := strconv.ParseFloat(bv, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(av, bv)
+}
+
 type Progress struct {
 	Percent int    `json:"percent"`
 	Color   string `json:"color"`
@@ -68,12 +225,18 @@ type TableCell struct {
 	Unit          string                 `json:"unit"`
 	Status        *Status                `json:"status"`
 	Link          *RouterLink            `json:"link"`
+	Url           string                 `json:"url,omitempty"`
 	Progress      *Progress              `json:"progress"`
 	NetInterfaces []NetInterface         `json:"net_interfaces"`
 	Chart         *timeseries.TimeSeries `json:"chart"`
 	IsStub        bool                   `json:"is_stub"`
+	Delta         string                 `json:"delta,omitempty"`
 
 	DeploymentSummaries []ApplicationDeploymentSummary `json:"deployment_summaries"`
+	DeploymentCommits   []ApplicationDeploymentCommit  `json:"deployment_commits,omitempty"`
+
+	stubFormat string
+	stubArgs   []any
 }
 
 func NewTableCell(values ...string) *TableCell {
@@ -86,12 +249,31 @@ func NewTableCell(values ...string) *TableCell {
 	return &TableCell{Values: values}
 }
 
+// Text returns the value of the cell without formatting (units and tags aren't included).
+func (c *TableCell) Text() string {
+	var parts []string
+	if c.Value != "" {
+		parts = append(parts, c.Value)
+	}
+	parts = append(parts, c.Values...)
+	for _, ni := range c.NetInterfaces {
+		parts = append(parts, fmt.Sprintf("%s: rx %s, tx %s", ni.Name, ni.Rx, ni.Tx))
+	}
+	return strings.Join(parts, "; ")
+}
+
 func (c *TableCell) SetStatus(status Status, msg string) *TableCell {
 	c.Status = &status
 	c.Value = msg
 	return c
 }
 
+// SetUrl sets an external link (e.g., to a commit) shown next to the value.
+func (c *TableCell) SetUrl(url string) *TableCell {
+	c.Url = url
+	return c
+}
+
 func (c *TableCell) UpdateStatus(status Status) *TableCell {
 	c.Status = &status
 	return c
@@ -142,6 +324,7 @@ func (c *TableCell) SetChart(ts *timeseries.TimeSeries) *TableCell {
 func (c *TableCell) SetStub(format string, a ...any) *TableCell {
 	c.Value = fmt.Sprintf(format, a...)
 	c.IsStub = true
+	c.stubFormat, c.stubArgs = format, a
 	return c
 }
 
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTableWriteCSV(t *testing.T) {
+	tbl := NewTable("Container", "Restarts", "Message")
+	tbl.AddRow(NewTableCell("app"), NewTableCell("3").SetUnit("times"), NewTableCell("OOM killed").AddTag("node-1"))
+	tbl.AddRow(NewTableCell("=HYPERLINK(\"http://evil\")"), NewTableCell("-1"), NewTableCell("@SUM(A1:A2)").AddTag("+cmd"))
+	tbl.AddRow(NewTableCell("-x"), nil, NewTableCell("\tindent"))
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, tbl.WriteCSV(buf))
+	assert.Equal(t,
+		"Container,Restarts,Restarts unit,Message,Message tags\n"+
+			"'-x,,,'\tindent,\n"+
+			"\"'=HYPERLINK(\"\"http://evil\"\")\",-1,,'@SUM(A1:A2),'+cmd\n"+
+			"app,3,times,OOM killed,node-1\n",
+		buf.String(),
+	)
+}
+
+func TestCsvEscape(t *testing.T) {
+	for s, expected := range map[string]string{
+		"":          "",
+		"app":       "app",
+		"=1+2":      "'=1+2",
+		"+1 day":    "'+1 day",
+		"-cmd":      "'-cmd",
+		"@SUM(A1)":  "'@SUM(A1)",
+		"\r=1":      "'\r=1",
+		"-1.5":      "-1.5",
+		"+42":       "+42",
+		"a=b":       "a=b",
+		"node-1":    "node-1",
+		"-Infinity": "'-Infinity",
+	} {
+		assert.Equal(t, expected, csvEscape(s), s)
+	}
+}
+
+func TestTableQuery(t *testing.T) {
+	tbl := NewTable("Container", "Restarts", "Status")
+	tbl.AddRow(NewTableCell("cart"), NewTableCell("10"), NewTableCell("Running"))
+	tbl.AddRow(NewTableCell("orders"), NewTableCell("9"), NewTableCell("OOMKilled"))
+	tbl.AddRow(NewTableCell("payments"), NewTableCell("-"), NewTableCell("running"))
+	tbl.AddRow(NewTableCell("catalog"), NewTableCell("9"), nil)
+
+	names := func(tbl *Table) []string {
+		var res []string
+		for _, r := range tbl.Rows {
+			res = append(res, r.Cells[0].Value)
+		}
+		return res
+	}
+
+	res := tbl.Query(TableQuery{})
+	assert.Equal(t, []string{"cart", "catalog", "orders", "payments"}, names(res))
+	assert.Equal(t, 4, res.Total)
+
+	res = tbl.Query(TableQuery{Filters: map[int]string{2: "RUN", 5: "x"}})
+	assert.Empty(t, res.Rows, "a filter of a missing column matches nothing")
+	res = tbl.Query(TableQuery{Filters: map[int]string{2: "RUN", 0: ""}})
+	assert.Equal(t, []string{"cart", "payments"}, names(res), "empty filters are ignored")
+
+	// numbers are compared numerically and go before non-numbers, ties are broken by the next sort column
+	res = tbl.Query(TableQuery{Sort: []TableSort{{Column: 1}, {Column: 0, Desc: true}}})
+	assert.Equal(t, []string{"orders", "catalog", "cart", "payments"}, names(res))
+	res = tbl.Query(TableQuery{Sort: []TableSort{{Column: 1, Desc: true}}})
+	assert.Equal(t, []string{"payments", "cart", "catalog", "orders"}, names(res))
+
+	res = tbl.Query(TableQuery{Offset: 1, Limit: 2})
+	assert.Equal(t, []string{"catalog", "orders"}, names(res))
+	assert.Equal(t, 4, res.Total)
+	res = tbl.Query(TableQuery{Offset: 10, Limit: 2})
+	assert.Empty(t, res.Rows)
+	assert.Equal(t, 4, res.Total)
+
+	assert.Equal(t, []string{"cart", "catalog", "orders", "payments"}, names(tbl), "the original table must not be modified")
+}
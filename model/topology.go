@@ -0,0 +1,105 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"sort"
+)
+
+// Topology is the application-level dependency graph: the clients of an application and the applications it depends on.
+type Topology struct {
+	Application  TopologyNode    `json:"application"`
+	Clients      []*TopologyLink `json:"clients"`
+	Dependencies []*TopologyLink `json:"dependencies"`
+}
+
+type TopologyNode struct {
+	Id     ApplicationId `json:"id"`
+	Status Status        `json:"status"`
+}
+
+// TopologyLink is an edge of the graph. Status is the status of the edge itself, while Node.Status is the status of the application.
+type TopologyLink struct {
+	Node   TopologyNode `json:"node"`
+	Status Status       `json:"status"`
+	Stats  []string     `json:"stats"`
+	Weight float32      `json:"weight"`
+
+	Requests *timeseries.TimeSeries `json:"-"`
+	Latency  *timeseries.TimeSeries `json:"-"`
+	Errors   *timeseries.TimeSeries `json:"-"`
+	Rtt      *timeseries.TimeSeries `json:"-"`
+}
+
+func NewTopology(app *Application) *Topology {
+	t := &Topology{Application: TopologyNode{Id: app.Id}}
+	for id, connections := range app.GetClientsConnections() {
+		t.Clients = append(t.Clients, newTopologyLink(id, connections))
+	}
+	for id, connections := range app.GetUpstreamsConnections() {
+		t.Dependencies = append(t.Dependencies, newTopologyLink(id, connections))
+	}
+	for _, links := range [][]*TopologyLink{t.Clients, t.Dependencies} {
+		sort.Slice(links, func(i, j int) bool {
+			return links[i].Node.Id.Name < links[j].Node.Id.Name
+		})
+	}
+	return t
+}
+
+func (t *Topology) IsEmpty() bool {
+	return len(t.Clients) == 0 && len(t.Dependencies) == 0
+}
+
+// UpdateStatuses sets the statuses of the applications. It should be called once all the applications are audited.
+func (t *Topology) UpdateStatuses(w *World) {
+	if app := w.GetApplication(t.Application.Id); app != nil {
+		t.Application.Status = app.Status
+	}
+	for _, links := range [][]*TopologyLink{t.Clients, t.Dependencies} {
+		for _, l := range links {
+			if app := w.GetApplication(l.Node.Id); app != nil {
+				l.Node.Status = app.Status
+			}
+		}
+	}
+}
+
+func newTopologyLink(id ApplicationId, connections []*Connection) *TopologyLink {
+	l := &TopologyLink{
+		Node:     TopologyNode{Id: id},
+		Requests: GetConnectionsRequestsSum(connections),
+		Latency:  GetConnectionsRequestsLatency(connections),
+		Errors:   GetConnectionsErrorsSum(connections),
+	}
+	rttSum := timeseries.NewAggregate(timeseries.NanSum)
+	rttCount := timeseries.NewAggregate(timeseries.NanSum)
+	for _, c := range connections {
+		if s := c.Status(); s > l.Status {
+			l.Status = s
+		}
+		if c.Rtt != nil {
+			rttSum.Add(c.Rtt)
+			rttCount.Add(c.Rtt.Map(timeseries.Defined))
+		}
+	}
+	l.Rtt = timeseries.Div(rttSum.Get(), rttCount.Get())
+
+	if requests := l.Requests.Last(); !timeseries.IsNaN(requests) {
+		l.Weight = requests
+		l.Stats = append(l.Stats, utils.FormatFloat(requests)+" rps")
+	}
+	if latency := l.Latency.Last(); !timeseries.IsNaN(latency) {
+		l.Stats = append(l.Stats, utils.FormatLatency(latency))
+	}
+	if errors := l.Errors.Last(); errors > 0 {
+		l.Stats = append(l.Stats, utils.FormatFloat(errors)+" errors/s")
+		if l.Status < WARNING {
+			l.Status = WARNING
+		}
+	}
+	if rtt := l.Rtt.Last(); !timeseries.IsNaN(rtt) {
+		l.Stats = append(l.Stats, "rtt "+utils.FormatLatency(rtt))
+	}
+	return l
+}
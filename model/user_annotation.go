@@ -0,0 +1,35 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+// UserAnnotationRetention defines how long past annotations are kept.
+const UserAnnotationRetention = 30 * timeseries.Day
+
+// UserAnnotation is a note about something that happened in the project (e.g., a config change or a load test).
+// It's shown on the charts of the application, or on all the charts of the project if ApplicationId is empty.
+type UserAnnotation struct {
+	Id            string          `json:"id"`
+	ApplicationId string          `json:"application_id,omitempty"`
+	From          timeseries.Time `json:"from"`
+	To            timeseries.Time `json:"to"`
+	Text          string          `json:"text"`
+}
+
+type UserAnnotations []UserAnnotation
+
+// Annotations returns the chart annotations related to the application, pass ApplicationIdZero to get only the project-wide ones.
+func (as UserAnnotations) Annotations(appId ApplicationId, ctx timeseries.Context) []Annotation {
+	var res []Annotation
+	for _, a := range as {
+		if a.ApplicationId != "" && a.ApplicationId != appId.String() {
+			continue
+		}
+		if a.To.Before(ctx.From) || a.From.After(ctx.To) {
+			continue
+		}
+		res = append(res, Annotation{Name: a.Text, X1: a.From, X2: a.To, Icon: "mdi-note-text-outline"})
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+package model
+
+import "github.com/coroot/coroot/utils"
+
+// Webhook is an HTTP endpoint notified of the transitions of the application and check statuses (e.g., OK -> WARNING)
+// and of the deployments that should be rolled back. If Secret is set, the payload is signed with HMAC-SHA256, and the signature is sent in the X-Coroot-Signature header.
+// If Template is set, the payload is produced by executing the Go template over the WebhookEvent instead of encoding the event as JSON.
+// If GroupBy is set, the status transitions are buffered for GroupWait minutes and the transitions with the same status
+// of the same application (or of the same check in the same namespace) are sent as a single consolidated event.
+type Webhook struct {
+	Id            string         `json:"id"`
+	Name          string         `json:"name"`
+	Url           string         `json:"url"`
+	Secret        string         `json:"secret"`
+	CustomHeaders []utils.Header `json:"custom_headers"`
+	Applications  bool           `json:"applications"`
+	Checks        bool           `json:"checks"`
+	Deployments   bool           `json:"deployments"`
+	Template      string         `json:"template"`
+	GroupBy       WebhookGroupBy `json:"group_by"`
+	GroupWait     int            `json:"group_wait"`
+}
+
+type WebhookGroupBy string
+
+const (
+	WebhookGroupByNone        WebhookGroupBy = ""
+	WebhookGroupByApplication WebhookGroupBy = "application"
+	WebhookGroupByNamespace   WebhookGroupBy = "namespace"
+)
+
+// WebhookEvent is the payload of a webhook request. Check is set for check status transitions,
+// FailingChecks lists the failed checks of the application for application status transitions,
+// Deployment is set for rollback recommendations.
+// Group is set for consolidated events: Events lists the grouped transitions, and Items lists their failing items.
+type WebhookEvent struct {
+	ProjectId      string             `json:"project_id"`
+	ProjectName    string             `json:"project_name"`
+	ApplicationId  string             `json:"application_id"`
+	Check          *WebhookCheck      `json:"check,omitempty"`
+	PreviousStatus string             `json:"previous_status"`
+	Status         string             `json:"status"`
+	Timestamp      int64              `json:"timestamp"`
+	Url            string             `json:"url,omitempty"`
+	FailingChecks  []*WebhookCheck    `json:"failing_checks,omitempty"`
+	Deployment     *WebhookDeployment `json:"deployment,omitempty"`
+	Group          string             `json:"group,omitempty"`
+	Events         []*WebhookEvent    `json:"events,omitempty"`
+	Items          []string           `json:"items,omitempty"`
+}
+
+type WebhookCheck struct {
+	Id      string   `json:"id"`
+	Title   string   `json:"title"`
+	Report  string   `json:"report"`
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Items   []string `json:"items"`
+}
+
+type WebhookDeployment struct {
+	Name      string            `json:"name"`
+	Version   string            `json:"version"`
+	StartedAt int64             `json:"started_at"`
+	Verdict   DeploymentVerdict `json:"verdict"`
+	Reasons   []string          `json:"reasons"`
+}
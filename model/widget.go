@@ -1,15 +1,22 @@
 package model
 
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
 type Widget struct {
 	Chart         *Chart         `json:"chart,omitempty"`
 	ChartGroup    *ChartGroup    `json:"chart_group,omitempty"`
 	Table         *Table         `json:"table,omitempty"`
 	LogPatterns   *LogPatterns   `json:"log_patterns,omitempty"`
 	DependencyMap *DependencyMap `json:"dependency_map,omitempty"`
+	Topology      *Topology      `json:"topology,omitempty"`
 	Heatmap       *Heatmap       `json:"heatmap,omitempty"`
 
-	Profile *Profile `json:"profile,omitempty"`
-	Tracing *Tracing `json:"tracing,omitempty"`
+	Profile    *Profile    `json:"profile,omitempty"`
+	FlameGraph *FlameGraph `json:"flame_graph,omitempty"`
+	Tracing    *Tracing    `json:"tracing,omitempty"`
+	RawLogs    *RawLogs    `json:"raw_logs,omitempty"`
 
 	Width string `json:"width,omitempty"`
 }
@@ -35,6 +42,18 @@ func (w *Widget) AddAnnotation(annotations ...Annotation) {
 	}
 }
 
+// SummaryOnly omits the data points of the chart series, leaving only the stats.
+func (w *Widget) SummaryOnly() {
+	if w.Chart != nil {
+		w.Chart.SummaryOnly()
+	}
+	if w.ChartGroup != nil {
+		for _, ch := range w.ChartGroup.Charts {
+			ch.SummaryOnly()
+		}
+	}
+}
+
 type RouterLink struct {
 	Title  string         `json:"title"`
 	Route  string         `json:"name,omitempty"`
@@ -77,6 +96,23 @@ type Profile struct {
 	ApplicationId ApplicationId `json:"application_id"`
 }
 
+// FlameGraph is a profile of the application (or of one of its instances) within the window,
+// or within the time range of the report if the window isn't set.
+// Profile is the profile type with an optional name as in the Profiling report (e.g., "CPU" or "CPU:off_cpu").
+type FlameGraph struct {
+	ApplicationId ApplicationId   `json:"application_id"`
+	Title         string          `json:"title"`
+	Profile       string          `json:"profile"`
+	Instance      string          `json:"instance,omitempty"`
+	From          timeseries.Time `json:"from,omitempty"`
+	To            timeseries.Time `json:"to,omitempty"`
+}
+
 type Tracing struct {
 	ApplicationId ApplicationId `json:"application_id"`
 }
+
+// RawLogs shows the log lines of the application retrieved from Loki.
+type RawLogs struct {
+	ApplicationId ApplicationId `json:"application_id"`
+}
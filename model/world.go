@@ -14,10 +14,39 @@ type IntegrationStatus struct {
 	}
 }
 
+type MemoryUsage struct {
+	Series int
+	Bytes  int64
+	Limit  int64
+
+	RequestedStep timeseries.Duration
+}
+
+func (mu MemoryUsage) Degraded() bool {
+	return mu.RequestedStep > 0
+}
+
+func CalcMemoryUsage(metrics map[string][]MetricValues) MemoryUsage {
+	var mu MemoryUsage
+	for _, mvs := range metrics {
+		for _, mv := range mvs {
+			if mv.Values.IsEmpty() {
+				continue
+			}
+			mu.Series++
+			mu.Bytes += int64(mv.Values.Size())
+		}
+	}
+	return mu
+}
+
 type World struct {
 	Ctx timeseries.Context
 
+	MemoryUsage MemoryUsage
+
 	CheckConfigs CheckConfigs
+	CustomChecks []*CustomCheckValues
 
 	Nodes        []*Node
 	Applications []*Application
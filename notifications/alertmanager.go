@@ -0,0 +1,160 @@
+package notifications
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const alertmanagerQueueSize = 100
+
+// AlertmanagerAlert is an alert in the format of the Alertmanager API v2 (POST /api/v2/alerts).
+type AlertmanagerAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       *time.Time        `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// AlertmanagerNotifier forwards the failing checks to the Alertmanager configured in the project, so that its routing tree,
+// silences, and inhibition rules apply to them. Alertmanager resolves the alerts that haven't been received within resolve_timeout,
+// so the failing checks are sent on every check cycle, and the checks that stopped failing are sent once with EndsAt set.
+// The alerts are sent by a single goroutine in the order they were produced, so a resolved alert can't overtake a firing one.
+type AlertmanagerNotifier struct {
+	lock   sync.Mutex
+	firing map[db.ProjectId]map[string]*AlertmanagerAlert
+	queue  chan alertmanagerBatch
+}
+
+type alertmanagerBatch struct {
+	cfg    db.IntegrationAlertmanager
+	alerts []*AlertmanagerAlert
+}
+
+func NewAlertmanagerNotifier() *AlertmanagerNotifier {
+	n := &AlertmanagerNotifier{
+		firing: map[db.ProjectId]map[string]*AlertmanagerAlert{},
+		queue:  make(chan alertmanagerBatch, alertmanagerQueueSize),
+	}
+	go n.send()
+	return n
+}
+
+func (n *AlertmanagerNotifier) send() {
+	for b := range n.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		if err := SendAlertmanagerAlerts(ctx, b.cfg, b.alerts); err != nil {
+			klog.Errorf("failed to send alerts to alertmanager: %s", err)
+		}
+		cancel()
+	}
+}
+
+func (n *AlertmanagerNotifier) Notify(project *db.Project, world *model.World, now timeseries.Time) {
+	cfg := project.Settings.Integrations.Alertmanager
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if cfg == nil {
+		delete(n.firing, project.Id)
+		return
+	}
+	prev := n.firing[project.Id]
+	curr := map[string]*AlertmanagerAlert{}
+	for _, app := range world.Applications {
+		for _, r := range app.Reports {
+			for _, ch := range r.Checks {
+				if ch.Status < model.WARNING || ch.Silenced() {
+					continue
+				}
+				a := alertmanagerAlert(project, app, r.Name, ch)
+				key := a.Labels["application_id"] + "/" + string(ch.Id)
+				if p := prev[key]; p != nil {
+					a.StartsAt = p.StartsAt
+				} else {
+					a.StartsAt = now.ToStandard()
+				}
+				curr[key] = a
+			}
+		}
+	}
+	n.firing[project.Id] = curr
+
+	alerts := make([]*AlertmanagerAlert, 0, len(curr))
+	for _, a := range curr {
+		alerts = append(alerts, a)
+	}
+	for key, a := range prev {
+		if curr[key] == nil {
+			resolved, endsAt := *a, now.ToStandard()
+			resolved.EndsAt = &endsAt
+			alerts = append(alerts, &resolved)
+		}
+	}
+	if len(alerts) == 0 {
+		return
+	}
+	select {
+	case n.queue <- alertmanagerBatch{cfg: *cfg, alerts: alerts}:
+	default:
+		klog.Errorf("%s: the alertmanager queue is full, dropping %d alerts", project.Id, len(alerts))
+	}
+}
+
+func alertmanagerAlert(project *db.Project, app *model.Application, report model.AuditReportName, ch *model.Check) *AlertmanagerAlert {
+	a := &AlertmanagerAlert{
+		Labels: map[string]string{
+			"alertname":      string(ch.Id),
+			"project":        project.Name,
+			"application_id": app.Id.String(),
+			"application":    app.Id.Name,
+			"namespace":      app.Id.Namespace,
+			"kind":           string(app.Id.Kind),
+			"report":         string(report),
+			"check":          ch.Title,
+			"severity":       ch.Status.String(),
+		},
+		Annotations: map[string]string{
+			"summary":     fmt.Sprintf("%s: %s", app.Id.Name, ch.Title),
+			"description": ch.Message,
+		},
+	}
+	if app.Owner != nil && app.Owner.Team != "" {
+		a.Labels["team"] = app.Owner.Team
+	}
+	if items := ch.Items(); len(items) > 0 {
+		a.Annotations["items"] = strings.Join(items, ", ")
+	}
+	if ann := ch.Annotation; ann != nil && ann.RunbookUrl != "" {
+		a.Annotations["runbook_url"] = ann.RunbookUrl
+	}
+	if baseUrl := project.Settings.Integrations.BaseUrl; baseUrl != "" {
+		a.GeneratorURL = fmt.Sprintf("%s/p/%s/app/%s/%s", baseUrl, project.Id, app.Id.String(), report)
+	}
+	for k, v := range a.Labels {
+		if v == "" {
+			delete(a.Labels, k)
+		}
+	}
+	return a
+}
+
+func SendAlertmanagerAlerts(ctx context.Context, cfg db.IntegrationAlertmanager, alerts []*AlertmanagerAlert) error {
+	client := http.DefaultClient
+	if cfg.TlsSkipVerify {
+		client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	}
+	var user, password string
+	if cfg.BasicAuth != nil {
+		user, password = cfg.BasicAuth.User, cfg.BasicAuth.Password
+	}
+	return sendJson(ctx, client, http.MethodPost, strings.TrimRight(cfg.Url, "/")+"/api/v2/alerts", user, password, alerts, nil)
+}
@@ -0,0 +1,44 @@
+package notifications
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAlertmanagerNotifierPreservesOrder(t *testing.T) {
+	received := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/alerts", r.URL.Path)
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "admin:secret", user+":"+password)
+		var alerts []*AlertmanagerAlert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alerts))
+		for _, a := range alerts {
+			state := "firing"
+			if a.EndsAt != nil {
+				state = "resolved"
+			}
+			received <- a.Labels["alertname"] + ":" + state
+		}
+	}))
+	defer srv.Close()
+
+	cfg := db.IntegrationAlertmanager{Url: srv.URL + "/", BasicAuth: &utils.BasicAuth{User: "admin", Password: "secret"}}
+	n := NewAlertmanagerNotifier()
+	endsAt := time.Now()
+	for i := 0; i < 5; i++ {
+		n.queue <- alertmanagerBatch{cfg: cfg, alerts: []*AlertmanagerAlert{{Labels: map[string]string{"alertname": "CPU"}}}}
+		n.queue <- alertmanagerBatch{cfg: cfg, alerts: []*AlertmanagerAlert{{Labels: map[string]string{"alertname": "CPU"}, EndsAt: &endsAt}}}
+	}
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, "CPU:firing", <-received)
+		assert.Equal(t, "CPU:resolved", <-received)
+	}
+}
@@ -0,0 +1,145 @@
+package notifications
+
+import (
+	"bytes"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/timeseries"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+const (
+	chartWidth   = 640
+	chartHeight  = 240
+	chartPadding = 10
+)
+
+var (
+	chartBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	chartGrid       = color.RGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+	chartLine       = color.RGBA{R: 0x42, G: 0xa5, B: 0xf5, A: 0xff}
+	chartFill       = color.RGBA{R: 0xe3, G: 0xf2, B: 0xfd, A: 0xff}
+	chartThreshold  = color.RGBA{R: 0xf4, G: 0x43, B: 0x36, A: 0xff}
+)
+
+// renderChart renders the chart as a PNG image. There are no labels: the title is supposed to be sent as a caption.
+// The Y axis is scaled to fit the values and the threshold, so that small deviations are noticeable.
+func renderChart(c *db.IncidentNotificationDetailsChart) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	for x := 0; x < chartWidth; x++ {
+		for y := 0; y < chartHeight; y++ {
+			img.Set(x, y, chartBackground)
+		}
+	}
+
+	lo, hi := c.Threshold, c.Threshold
+	for _, v := range c.Values {
+		if f := float32(v); !timeseries.IsNaN(f) {
+			lo, hi = min32(lo, f), max32(hi, f)
+		}
+	}
+	if hi == lo {
+		hi++
+	}
+	margin := (hi - lo) * 0.1
+	lo, hi = lo-margin, hi+margin
+	w, h := chartWidth-2*chartPadding, chartHeight-2*chartPadding
+	toY := func(v float32) int {
+		return chartPadding + h - int(float32(h)*(v-lo)/(hi-lo))
+	}
+	toX := func(i int) int {
+		if len(c.Values) < 2 {
+			return chartPadding
+		}
+		return chartPadding + i*w/(len(c.Values)-1)
+	}
+
+	for i := 0; i <= 4; i++ {
+		y := chartPadding + i*h/4
+		for x := chartPadding; x < chartPadding+w; x++ {
+			img.Set(x, y, chartGrid)
+		}
+	}
+
+	prevX, prevY := -1, -1
+	for i, v := range c.Values {
+		f := float32(v)
+		if timeseries.IsNaN(f) {
+			prevX, prevY = -1, -1
+			continue
+		}
+		x, y := toX(i), toY(f)
+		if prevX >= 0 {
+			for xx := prevX; xx <= x; xx++ {
+				yy := prevY
+				if x > prevX {
+					yy = prevY + (y-prevY)*(xx-prevX)/(x-prevX)
+				}
+				for fy := yy + 2; fy < chartPadding+h; fy++ {
+					img.Set(xx, fy, chartFill)
+				}
+			}
+			drawLine(img, prevX, prevY, x, y, chartLine)
+		}
+		prevX, prevY = x, y
+	}
+
+	ty := toY(c.Threshold)
+	for x := chartPadding; x < chartPadding+w; x++ {
+		if (x/6)%2 == 0 {
+			img.Set(x, ty, chartThreshold)
+			img.Set(x, ty+1, chartThreshold)
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine draws a 2px wide line using the Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := int(math.Abs(float64(x1-x0))), -int(math.Abs(float64(y1-y0)))
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		img.Set(x0, y0+1, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
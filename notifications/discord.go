@@ -0,0 +1,98 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"strconv"
+	"strings"
+)
+
+// Discord sends messages with embeds to a channel through an incoming webhook.
+type Discord struct {
+	webhookUrl string
+}
+
+func NewDiscord(webhookUrl string) *Discord {
+	return &Discord{webhookUrl: webhookUrl}
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Url         string              `json:"url,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func (d *Discord) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	e := discordEmbed{Url: incidentUrl(baseUrl, n), Color: discordColor(n.Status)}
+	if n.Status == model.OK {
+		e.Title = fmt.Sprintf("%s incident resolved", n.ApplicationId.Name)
+	} else {
+		e.Title = fmt.Sprintf("[%s] %s is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name)
+	}
+	if n.Title != "" {
+		e.Title = n.Title
+	}
+	if n.Details != nil {
+		var lines []string
+		for _, r := range n.Details.Reports {
+			lines = append(lines, fmt.Sprintf("• %s**%s** / %s: %s", checkSeverity(r.Status), r.Name, r.Check, r.Message+checkAnnotation(r.Annotation)))
+		}
+		e.Description = strings.Join(lines, "\n")
+		if n.Text != "" {
+			e.Description = n.Text
+		}
+		if o := incidentOwner(n.Details); o != nil && o.Team != "" {
+			e.Fields = append(e.Fields, discordEmbedField{Name: "Owner", Value: o.Team, Inline: true})
+		}
+		if dep := n.Details.Deployment; dep != nil {
+			e.Fields = append(e.Fields, discordEmbedField{Name: "Recent deployment", Value: dep.Version, Inline: true})
+		}
+	}
+	return d.send(ctx, e)
+}
+
+func (d *Discord) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	if ds.State == model.ApplicationDeploymentStateInProgress {
+		return nil
+	}
+	dep := ds.Deployment
+	e := discordEmbed{
+		Title: deploymentTitle(project, dep),
+		Url:   deploymentUrl(project.Settings.Integrations.BaseUrl, project.Id, dep),
+		Color: discordColor(ds.Status),
+		Fields: []discordEmbedField{
+			{Name: "Status", Value: deploymentState(ds.State), Inline: true},
+			{Name: "Version", Value: dep.Version(), Inline: true},
+		},
+	}
+	if summary := deploymentSummary(ds); summary != nil {
+		e.Fields = append(e.Fields, discordEmbedField{Name: "Summary", Value: strings.Join(summary, "\n")})
+	}
+	return d.send(ctx, e)
+}
+
+func (d *Discord) send(ctx context.Context, e discordEmbed) error {
+	// embed titles are limited to 256 characters and descriptions to 4096 characters
+	if len(e.Title) > 256 {
+		e.Title = e.Title[:253] + "..."
+	}
+	if len(e.Description) > 4096 {
+		e.Description = e.Description[:4093] + "..."
+	}
+	return postJson(ctx, d.webhookUrl, map[string]any{"username": "Coroot", "embeds": []discordEmbed{e}})
+}
+
+func discordColor(s model.Status) int {
+	c, _ := strconv.ParseInt(strings.TrimPrefix(s.Color(), "#"), 16, 32)
+	return int(c)
+}
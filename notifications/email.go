@@ -0,0 +1,254 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"html/template"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; font-size: 14px; color: #212121">
+<div style="border-left: 4px solid {{.Color}}; padding-left: 12px; margin-bottom: 16px">
+	<a href="{{.Url}}" style="font-size: 18px; font-weight: bold; color: #212121; text-decoration: none">{{.Title}}</a>
+</div>
+{{if .Chart}}
+<div style="margin-bottom: 16px">
+	<div style="color: #757575; margin-bottom: 4px">{{.Chart}}</div>
+	<img src="cid:chart" alt="{{.Chart}}" width="640" height="240">
+</div>
+{{end}}
+{{if .Rows}}
+<table style="border-collapse: collapse; margin-bottom: 16px">
+	<tr>{{range .Header}}<th style="text-align: left; padding: 4px 12px 4px 0; border-bottom: 1px solid #e0e0e0">{{.}}</th>{{end}}</tr>
+	{{range .Rows}}<tr>{{range .}}<td style="padding: 4px 12px 4px 0; border-bottom: 1px solid #eeeeee; vertical-align: top">{{.}}</td>{{end}}</tr>{{end}}
+</table>
+{{end}}
+{{range .Lines}}<div>{{.}}</div>{{end}}
+<p><a href="{{.Url}}">Open in Coroot</a></p>
+</body>
+</html>
+`))
+
+type emailContent struct {
+	Title  string
+	Url    string
+	Color  string
+	Chart  string
+	Header []string
+	Rows   [][]string
+	Lines  []string
+}
+
+// Email sends HTML emails through an SMTP server.
+type Email struct {
+	cfg db.IntegrationEmail
+}
+
+func NewEmail(cfg db.IntegrationEmail) *Email {
+	return &Email{cfg: cfg}
+}
+
+// SendIncident sends the incident to the email addresses of the application owner or, if there are none, to the default recipients.
+// The email contains the failing checks and the chart of the failing SLI.
+func (e *Email) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	c := emailContent{Url: incidentUrl(baseUrl, n), Color: n.Status.Color()}
+	if n.Status == model.OK {
+		c.Title = fmt.Sprintf("%s incident resolved", n.ApplicationId.Name)
+	} else {
+		c.Title = fmt.Sprintf("[%s] %s is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name)
+	}
+	if n.Title != "" {
+		c.Title = n.Title
+	}
+	var chart []byte
+	to := e.cfg.Recipients()
+	if n.Details != nil {
+		c.Header = []string{"Report", "Check", "Status", "Message", "Items"}
+		for _, r := range n.Details.Reports {
+			status := ""
+			if r.Status >= model.WARNING {
+				status = strings.ToUpper(r.Status.String())
+			}
+			c.Rows = append(c.Rows, []string{string(r.Name), r.Check, status, r.Message + checkAnnotation(r.Annotation), strings.Join(r.Items, ", ")})
+		}
+		if o := incidentOwner(n.Details); o != nil {
+			if o.Team != "" {
+				c.Lines = append(c.Lines, "Owner: "+o.Team)
+			}
+			if emails := o.Emails(); len(emails) > 0 {
+				to = emails
+			}
+		}
+		if d := n.Details.Deployment; d != nil {
+			c.Lines = append(c.Lines, "Recent deployment: "+d.Version)
+		}
+		if ch := n.Details.Chart; ch != nil && len(ch.Values) > 0 {
+			img, err := renderChart(ch)
+			if err != nil {
+				return err
+			}
+			chart, c.Chart = img, ch.Title
+		}
+	}
+	if n.Text != "" {
+		c.Header, c.Rows, c.Lines = nil, nil, strings.Split(n.Text, "\n")
+	}
+	return e.send(ctx, to, c, chart)
+}
+
+func (e *Email) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	if ds.State == model.ApplicationDeploymentStateInProgress {
+		return nil
+	}
+	d := ds.Deployment
+	c := emailContent{
+		Title:  deploymentTitle(project, d),
+		Url:    deploymentUrl(project.Settings.Integrations.BaseUrl, project.Id, d),
+		Color:  ds.Status.Color(),
+		Header: []string{"Status", "Version"},
+		Rows:   [][]string{{deploymentState(ds.State), d.Version()}},
+		Lines:  deploymentSummary(ds),
+	}
+	return e.send(ctx, e.cfg.Recipients(), c, nil)
+}
+
+func (e *Email) send(ctx context.Context, to []string, c emailContent, chart []byte) error {
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	html := bytes.NewBuffer(nil)
+	if err := emailTemplate.Execute(html, c); err != nil {
+		return err
+	}
+
+	msg := bytes.NewBuffer(nil)
+	mw := multipart.NewWriter(msg)
+	headers := map[string]string{
+		"From":         e.cfg.From,
+		"To":           strings.Join(to, ", "),
+		"Subject":      mimeHeader(c.Title),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"MIME-Version": "1.0",
+		"Content-Type": "multipart/related; boundary=" + mw.Boundary(),
+	}
+	for _, k := range []string{"From", "To", "Subject", "Date", "MIME-Version", "Content-Type"} {
+		fmt.Fprintf(msg, "%s: %s\r\n", k, headers[k])
+	}
+	msg.WriteString("\r\n")
+	pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}, "Content-Transfer-Encoding": {"base64"}})
+	if err != nil {
+		return err
+	}
+	if _, err = pw.Write(base64Lines(html.Bytes())); err != nil {
+		return err
+	}
+	if chart != nil {
+		pw, err = mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"image/png"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-ID":                {"<chart>"},
+			"Content-Disposition":       {`inline; filename="chart.png"`},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = pw.Write(base64Lines(chart)); err != nil {
+			return err
+		}
+	}
+	if err = mw.Close(); err != nil {
+		return err
+	}
+	return e.sendMail(ctx, to, msg.Bytes())
+}
+
+func (e *Email) sendMail(ctx context.Context, to []string, msg []byte) error {
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if e.cfg.TLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: e.cfg.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok && !e.cfg.TLS {
+		if err = c.StartTLS(&tls.Config{ServerName: e.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if e.cfg.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	from, err := mail.ParseAddress(e.cfg.From)
+	if err != nil {
+		return err
+	}
+	if err = c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		rcpt, err := mail.ParseAddress(addr)
+		if err != nil {
+			return err
+		}
+		if err = c.Rcpt(rcpt.Address); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func mimeHeader(s string) string {
+	return "=?UTF-8?B?" + base64.StdEncoding.EncodeToString([]byte(s)) + "?="
+}
+
+// base64Lines encodes the data to base64 split into lines of 76 characters as required by RFC 2045.
+func base64Lines(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	res := bytes.NewBuffer(nil)
+	for len(encoded) > 76 {
+		res.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	res.WriteString(encoded + "\r\n")
+	return res.Bytes()
+}
@@ -11,11 +11,12 @@ import (
 )
 
 type IncidentNotifier struct {
-	db *db.DB
+	db      *db.DB
+	limiter *rateLimiter
 }
 
 func NewIncidentNotifier(db *db.DB) *IncidentNotifier {
-	n := IncidentNotifier{db: db}
+	n := IncidentNotifier{db: db, limiter: newRateLimiter()}
 	go func() {
 		for range time.Tick(retryInterval) {
 			n.sendIncidents()
@@ -26,14 +27,149 @@ func NewIncidentNotifier(db *db.DB) *IncidentNotifier {
 
 func (n *IncidentNotifier) Enqueue(project *db.Project, app *model.Application, incident *model.ApplicationIncident, now timeseries.Time) {
 	integrations := project.Settings.Integrations
+	var destinations []db.IntegrationType
+	enabled := map[db.IntegrationType]bool{}
 	for _, i := range integrations.GetInfo() {
-		if i.Configured && i.Incidents {
-			n.enqueue(project, app, incident, i.Type, now)
+		if !i.Configured || !i.Incidents || integrations.TicketThreshold(i.Type) > 0 { // tickets are opened by OpenTickets
+			continue
+		}
+		if i.Type == db.IntegrationTypeTwilio && integrations.Twilio.Fallback { // see fallback
+			continue
+		}
+		destinations = append(destinations, i.Type)
+		enabled[i.Type] = true
+	}
+	for _, r := range project.Settings.NotificationRoutes {
+		d := db.IntegrationType(r.Destination)
+		if !enabled[d] && !incident.Resolved() && r.Matches(app, incident.Severity) && newClient(d, integrations) != nil {
+			destinations = append(destinations, d)
+			enabled[d] = true
 		}
 	}
+	if incident.Resolved() {
+		// the integrations the incident was routed or escalated to must be notified of the resolution as well
+		escalated, err := n.db.GetIncidentNotificationDestinations(project.Id, app.Id, incident.Key)
+		if err != nil {
+			klog.Errorln(err)
+		}
+		for _, d := range escalated {
+			if !enabled[d] && newClient(d, integrations) != nil {
+				destinations = append(destinations, d)
+			}
+		}
+	}
+	details := incidentDetailsOnce(app, incident)
+	for _, d := range destinations {
+		n.enqueue(project, app, incident, details(), d, now, nil)
+	}
 	n.sendIncidents()
 }
 
+// Escalate notifies the targets of the escalation policy steps that are due for the open incidents that haven't been acknowledged.
+func (n *IncidentNotifier) Escalate(project *db.Project, world *model.World, now timeseries.Time) {
+	policies := project.Settings.EscalationPolicies
+	if len(policies) == 0 {
+		return
+	}
+	incidents, err := n.db.GetApplicationIncidents(project.Id, now, now)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	integrations := project.Settings.Integrations
+	var enqueued bool
+	for appId, appIncidents := range incidents {
+		app := world.GetApplication(appId)
+		if app == nil {
+			continue
+		}
+		for _, incident := range appIncidents {
+			if incident.Resolved() || incident.Acknowledged() {
+				continue
+			}
+			policy := model.GetEscalationPolicy(policies, incident.Severity)
+			if policy == nil {
+				continue
+			}
+			details := incidentDetailsOnce(app, incident)
+			step := incident.EscalationStep
+			for ; step < len(policy.Steps); step++ {
+				s := policy.Steps[step]
+				if incident.OpenedAt.Add(timeseries.Duration(s.Delay) * timeseries.Minute).After(now) {
+					break
+				}
+				destination := db.IntegrationType(s.Destination)
+				if newClient(destination, integrations) == nil {
+					klog.Warningf("escalation policy %s: %s is not configured", policy.Name, destination)
+					continue
+				}
+				n.enqueue(project, app, incident, details(), destination, now, &s)
+				enqueued = true
+			}
+			if step != incident.EscalationStep {
+				if err = n.db.SetIncidentEscalationStep(project.Id, incident.Key, step); err != nil {
+					klog.Errorln(err)
+				}
+			}
+		}
+	}
+	if enqueued {
+		n.sendIncidents()
+	}
+}
+
+// OpenTickets opens tickets in the ticketing integrations for the critical incidents that have been open for longer than the thresholds
+// of the integrations. The tickets are closed by Enqueue, since the resolution is sent to every destination notified of the incident.
+func (n *IncidentNotifier) OpenTickets(project *db.Project, world *model.World, now timeseries.Time) {
+	integrations := project.Settings.Integrations
+	var ticketing []db.IntegrationType
+	for _, i := range integrations.GetInfo() {
+		if i.Configured && i.Incidents && integrations.TicketThreshold(i.Type) > 0 {
+			ticketing = append(ticketing, i.Type)
+		}
+	}
+	if len(ticketing) == 0 {
+		return
+	}
+	incidents, err := n.db.GetApplicationIncidents(project.Id, now, now)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	var enqueued bool
+	for appId, appIncidents := range incidents {
+		app := world.GetApplication(appId)
+		if app == nil {
+			continue
+		}
+		for _, incident := range appIncidents {
+			if incident.Resolved() || incident.Severity != model.CRITICAL {
+				continue
+			}
+			notified := map[db.IntegrationType]bool{}
+			destinations, err := n.db.GetIncidentNotificationDestinations(project.Id, app.Id, incident.Key)
+			if err != nil {
+				klog.Errorln(err)
+				continue
+			}
+			for _, d := range destinations {
+				notified[d] = true
+			}
+			details := incidentDetailsOnce(app, incident)
+			for _, d := range ticketing {
+				if notified[d] || incident.OpenedAt.Add(integrations.TicketThreshold(d)).After(now) {
+					continue
+				}
+				n.enqueue(project, app, incident, details(), d, now, nil)
+				enqueued = true
+			}
+		}
+	}
+	if enqueued {
+		n.sendIncidents()
+	}
+}
+
 type destinationKey struct {
 	integration db.IntegrationType
 	projectId   db.ProjectId
@@ -53,11 +189,19 @@ func (n *IncidentNotifier) sendIncidents() {
 		projects[p.Id] = p
 	}
 	failedDestinations := map[destinationKey]bool{}
-	notifications, err := n.db.GetNotSentIncidentNotifications(timeseries.Now().Add(-retryWindow))
+	now := timeseries.Now()
+	// the notifications held during the quiet hours are kept for up to a day
+	notifications, err := n.db.GetNotSentIncidentNotifications(now.Add(-timeseries.Day))
 	if err != nil {
 		klog.Errorln(err)
 		return
 	}
+	critical := map[string]bool{}
+	for _, notification := range notifications {
+		if notification.Status == model.CRITICAL {
+			critical[string(notification.ProjectId)+"/"+notification.IncidentKey] = true
+		}
+	}
 	for _, notification := range notifications {
 		dKey := destinationKey{integration: notification.Destination, projectId: notification.ProjectId}
 		if failedDestinations[dKey] {
@@ -67,11 +211,41 @@ func (n *IncidentNotifier) sendIncidents() {
 		if project == nil {
 			continue
 		}
+		if notification.Timestamp.Before(now.Add(-retryWindow)) && !project.QuietHoursActive(notification.Timestamp) {
+			continue
+		}
+		if project.QuietHoursActive(now) && n.held(notification, critical) {
+			continue
+		}
+		if notification.NextAttemptAt.After(now) {
+			failedDestinations[dKey] = true // keeps the order of the notifications sent to the destination
+			continue
+		}
 		integrations := project.Settings.Integrations
-		var sendErr error
 		client := getClient(notification.Destination, integrations)
+		if client == nil && notification.Details != nil && notification.Details.Routed {
+			client = newClient(notification.Destination, integrations)
+		}
+		if client != nil && notification.Attempts == 0 && !n.allow(project, notification) {
+			klog.Warningf("%s: rate limit exceeded, the notification to %s was dropped", project.Id, notification.Destination)
+			LogDelivery(n.db, db.NotificationDelivery{
+				ProjectId:     notification.ProjectId,
+				Kind:          db.NotificationKindIncident,
+				Destination:   string(notification.Destination),
+				ApplicationId: notification.ApplicationId.String(),
+				Subject:       fmt.Sprintf("%s: %s", notification.IncidentKey, notification.Status),
+				Attempt:       1,
+			}, time.Now(), errRateLimitExceeded)
+			notification.DroppedAt = timeseries.Now()
+			if err := n.db.DropIncidentNotification(notification); err != nil {
+				klog.Errorln(err)
+			}
+			continue
+		}
+		var sendErr error
 		if client != nil {
-			if notification.Destination == db.IntegrationTypeSlack {
+			// the updates are posted to the Slack thread or to the ticket opened for the incident
+			if notification.ExternalKey == "" && (notification.Destination == db.IntegrationTypeSlack || integrations.TicketThreshold(notification.Destination) > 0) {
 				if prevNotifications, err := n.db.GetPreviousIncidentNotifications(notification); err != nil {
 					klog.Errorln(err)
 				} else {
@@ -82,13 +256,36 @@ func (n *IncidentNotifier) sendIncidents() {
 					}
 				}
 			}
+			if t := project.Settings.NotificationTemplates.Get(string(notification.Destination)); t != nil {
+				data := NewIncidentTemplateData(project.Name, integrations.BaseUrl, &notification)
+				if notification.Title, notification.Text, err = RenderIncidentTemplate(*t, data); err != nil {
+					klog.Errorf("failed to render the %s notification template: %s", notification.Destination, err)
+				}
+			}
+			started := time.Now()
 			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
 			sendErr = client.SendIncident(ctx, integrations.BaseUrl, &notification)
 			cancel()
+			LogDelivery(n.db, db.NotificationDelivery{
+				ProjectId:     notification.ProjectId,
+				Kind:          db.NotificationKindIncident,
+				Destination:   string(notification.Destination),
+				ApplicationId: notification.ApplicationId.String(),
+				Subject:       fmt.Sprintf("%s: %s", notification.IncidentKey, notification.Status),
+				Attempt:       notification.Attempts + 1,
+			}, started, sendErr)
 		}
 		if sendErr != nil {
 			klog.Errorf("send error %s: %s", notification.Destination, sendErr)
 			failedDestinations[dKey] = true
+			notification.Attempts++
+			notification.NextAttemptAt = now.Add(retryBackoff(notification.Attempts))
+			if err := n.db.UpdateIncidentNotificationAttempts(notification); err != nil {
+				klog.Errorln(err)
+			}
+			if notification.Attempts == 1 {
+				n.fallback(project, notification)
+			}
 		} else {
 			notification.SentAt = timeseries.Now()
 			if err := n.db.UpdateIncidentNotification(notification); err != nil {
@@ -98,7 +295,87 @@ func (n *IncidentNotifier) sendIncidents() {
 	}
 }
 
-func (n *IncidentNotifier) enqueue(project *db.Project, app *model.Application, incident *model.ApplicationIncident, destination db.IntegrationType, now timeseries.Time) {
+// allow checks the notification against the project-wide and the per-integration hourly limits.
+// Resolutions and escalations are never limited: otherwise, the incidents opened by the ticketing and paging integrations
+// would never be closed, and the escalation targets would never be notified.
+func (n *IncidentNotifier) allow(project *db.Project, notification db.IncidentNotification) bool {
+	if notification.Status == model.OK || (notification.Details != nil && notification.Details.Escalation) {
+		return true
+	}
+	limits := project.Settings.NotificationLimits
+	return n.limiter.allow(time.Now(),
+		rateLimit{key: string(project.Id), limit: limits.HourlyLimit},
+		rateLimit{key: string(project.Id) + "/" + string(notification.Destination), limit: limits.ChannelHourlyLimit},
+	)
+}
+
+// routeChanged reports whether the previous notification of the incident was sent to another Slack channel.
+func (n *IncidentNotifier) routeChanged(notification db.IncidentNotification, owner *model.ApplicationOwner) bool {
+	prev, err := n.db.GetPreviousIncidentNotifications(notification)
+	if err != nil {
+		klog.Errorln(err)
+		return false
+	}
+	if len(prev) == 0 {
+		return false
+	}
+	last := prev[len(prev)-1]
+	return last.Details == nil || last.Details.Owner == nil || last.Details.Owner.SlackChannel != owner.SlackChannel
+}
+
+// fallback sends the critical incident through Twilio if it's configured as the last resort
+// and the incident's notification to a chat-based integration has failed.
+func (n *IncidentNotifier) fallback(project *db.Project, notification db.IncidentNotification) {
+	cfg := project.Settings.Integrations.Twilio
+	if cfg == nil || !cfg.Incidents || !cfg.Fallback || notification.Status != model.CRITICAL {
+		return
+	}
+	switch notification.Destination {
+	case db.IntegrationTypeSlack, db.IntegrationTypeTeams, db.IntegrationTypeTelegram, db.IntegrationTypeDiscord, db.IntegrationTypeMattermost:
+	default:
+		return
+	}
+	destinations, err := n.db.GetIncidentNotificationDestinations(notification.ProjectId, notification.ApplicationId, notification.IncidentKey)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	for _, d := range destinations {
+		if d == db.IntegrationTypeTwilio {
+			return
+		}
+	}
+	klog.Infof("%s: %s is unavailable, falling back to Twilio", project.Id, notification.Destination)
+	notification.Destination = db.IntegrationTypeTwilio
+	notification.ExternalKey = ""
+	notification.Attempts = 0
+	notification.NextAttemptAt = 0
+	n.db.PutIncidentNotification(notification)
+}
+
+// held reports whether the notification must be held until the end of the quiet hours.
+// The notifications of the incidents that are or have been critical are never held.
+func (n *IncidentNotifier) held(notification db.IncidentNotification, critical map[string]bool) bool {
+	key := string(notification.ProjectId) + "/" + notification.IncidentKey
+	if c, ok := critical[key]; ok {
+		return !c
+	}
+	prev, err := n.db.GetPreviousIncidentNotifications(notification)
+	if err != nil {
+		klog.Errorln(err)
+		return true
+	}
+	critical[key] = false
+	for _, p := range prev {
+		if p.Status == model.CRITICAL {
+			critical[key] = true
+		}
+	}
+	return !critical[key]
+}
+
+// enqueue creates the notification of the incident for the destination, the details are customized for the destination in place.
+func (n *IncidentNotifier) enqueue(project *db.Project, app *model.Application, incident *model.ApplicationIncident, details *db.IncidentNotificationDetails, destination db.IntegrationType, now timeseries.Time, escalation *model.EscalationStep) {
 	notification := db.IncidentNotification{
 		ProjectId:     project.Id,
 		ApplicationId: app.Id,
@@ -107,12 +384,78 @@ func (n *IncidentNotifier) enqueue(project *db.Project, app *model.Application,
 		Timestamp:     now,
 		Status:        incident.Severity,
 	}
+	slackKey := ""
+	routed := false // the destination is targeted by a notification route or an escalation policy
+	switch {
+	case incident.Resolved():
+		// the resolution is sent to the recipient the incident was routed to
+		if prev, err := n.db.GetPreviousIncidentNotifications(notification); err != nil {
+			klog.Errorln(err)
+		} else if len(prev) > 0 && prev[len(prev)-1].Details != nil {
+			if details != nil {
+				details.Owner = prev[len(prev)-1].Details.Owner
+			}
+			routed = prev[len(prev)-1].Details.Routed
+		}
+	case escalation != nil:
+		routed = true
+		if details == nil {
+			details = &db.IncidentNotificationDetails{}
+		}
+		details.Owner = escalation.Owner(app.Owner)
+		details.Escalation = true
+		if destination == db.IntegrationTypeSlack && escalation.Target != "" {
+			slackKey = escalation.Target + ":" // a new thread in the target channel
+		}
+	default:
+		// the routes take precedence over the on-call schedule, which takes precedence over the application owner
+		var m *model.OnCallMember
+		if incident.Severity == model.CRITICAL {
+			m = project.Settings.OnCall.Current(now)
+		}
+		r := model.GetNotificationRoute(project.Settings.NotificationRoutes, app, incident.Severity, string(destination))
+		routed = r != nil
+		if r != nil && r.Target == "" {
+			r = nil
+		}
+		if m == nil && r == nil {
+			break
+		}
+		if details == nil {
+			details = &db.IncidentNotificationDetails{}
+		}
+		if m != nil {
+			details.Owner = m.Owner(app.Owner)
+			details.OnCall = m.Name
+		}
+		if r != nil {
+			details.Owner = app.Owner.WithTarget(r.Destination, r.Target)
+			if destination == db.IntegrationTypeSlack && n.routeChanged(notification, details.Owner) {
+				slackKey = r.Target + ":" // e.g., the incident has become critical and is routed to another channel
+			}
+		}
+	}
+	if getClient(destination, project.Settings.Integrations) == nil {
+		if !routed { // e.g., the resolution of an incident notified of before the incident notifications were disabled
+			return
+		}
+		if details == nil {
+			details = &db.IncidentNotificationDetails{}
+		}
+		details.Routed = true
+	}
 	switch destination {
-	case db.IntegrationTypeSlack, db.IntegrationTypeTeams:
+	case db.IntegrationTypeSlack, db.IntegrationTypeTeams, db.IntegrationTypeTelegram, db.IntegrationTypeDiscord, db.IntegrationTypeMattermost, db.IntegrationTypeEmail, db.IntegrationTypeTwilio:
+		if incident.Resolved() {
+			n.onResolve("", notification, details)
+		} else {
+			n.onOpen(slackKey, notification, details)
+		}
+	case db.IntegrationTypeJira, db.IntegrationTypeServiceNow:
 		if incident.Resolved() {
-			n.onResolve("", notification, incidentDetails(app, incident))
+			n.onResolve("", notification, details)
 		} else {
-			n.onOpen("", notification, incidentDetails(app, incident))
+			n.onOpen("", notification, details)
 		}
 	case db.IntegrationTypePagerduty, db.IntegrationTypeOpsgenie:
 		openCriticalKey, openWarningKey, err := n.getOpenIncidents(notification)
@@ -133,9 +476,9 @@ func (n *IncidentNotifier) enqueue(project *db.Project, app *model.Application,
 			if openCriticalKey != "" {
 				n.onResolve(openCriticalKey, notification, nil)
 			}
-			n.onOpen(externalKey, notification, incidentDetails(app, incident))
+			n.onOpen(externalKey, notification, details)
 		case incident.Severity == model.CRITICAL:
-			n.onOpen(externalKey, notification, incidentDetails(app, incident))
+			n.onOpen(externalKey, notification, details)
 		}
 	default:
 		klog.Errorln("unknown destination:", destination)
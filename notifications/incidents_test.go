@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIncidentNotifierAllow(t *testing.T) {
+	n := &IncidentNotifier{limiter: newRateLimiter()}
+	p := &db.Project{Id: "p"}
+	p.Settings.NotificationLimits.HourlyLimit = 1
+
+	critical := db.IncidentNotification{ProjectId: p.Id, Destination: db.IntegrationTypePagerduty, Status: model.CRITICAL}
+	assert.True(t, n.allow(p, critical))
+	assert.False(t, n.allow(p, critical))
+
+	resolved := critical
+	resolved.Status = model.OK
+	assert.True(t, n.allow(p, resolved))
+
+	escalation := critical
+	escalation.Details = &db.IncidentNotificationDetails{Escalation: true}
+	assert.True(t, n.allow(p, escalation))
+}
+
+func TestGetClient(t *testing.T) {
+	integrations := db.Integrations{Slack: &db.IntegrationSlack{Token: "token", DefaultChannel: "ops"}}
+	assert.Nil(t, getClient(db.IntegrationTypeSlack, integrations))
+	assert.NotNil(t, newClient(db.IntegrationTypeSlack, integrations))
+	assert.Nil(t, newClient(db.IntegrationTypeTeams, integrations))
+
+	integrations.Slack.Incidents = true
+	assert.NotNil(t, getClient(db.IntegrationTypeSlack, integrations))
+}
+
+func TestIncidentDetailsOnce(t *testing.T) {
+	app := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "app"))
+	app.Owner = &model.ApplicationOwner{Team: "team"}
+	details := incidentDetailsOnce(app, &model.ApplicationIncident{Severity: model.CRITICAL})
+
+	d1 := details()
+	d2 := details()
+	assert.NotNil(t, d1)
+	assert.Equal(t, d1, d2)
+	d1.Routed = true
+	assert.False(t, d2.Routed, "each destination gets its own copy")
+
+	assert.Nil(t, incidentDetailsOnce(model.NewApplication(app.Id), &model.ApplicationIncident{Severity: model.CRITICAL})())
+}
@@ -0,0 +1,81 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"net/http"
+	"strings"
+)
+
+type Jira struct {
+	cfg db.IntegrationJira
+}
+
+func NewJira(cfg db.IntegrationJira) *Jira {
+	return &Jira{cfg: cfg}
+}
+
+// SendIncident opens an issue and saves its key as the external key of the notification.
+// The resolution is posted as a comment, and then the issue is transitioned to the first available "done" status, if any.
+func (j *Jira) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	if n.Status == model.OK {
+		if n.ExternalKey == "" { // the issue hasn't been opened
+			return nil
+		}
+		return j.close(ctx, baseUrl, n)
+	}
+	issueType := j.cfg.IssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+	req := map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": j.cfg.ProjectKey},
+			"issuetype":   map[string]string{"name": issueType},
+			"summary":     ticketSummary(n),
+			"description": ticketDescription(baseUrl, n),
+			"labels":      []string{"coroot", "coroot-" + n.IncidentKey},
+		},
+	}
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := j.request(ctx, http.MethodPost, "/rest/api/2/issue", req, &resp); err != nil {
+		return err
+	}
+	n.ExternalKey = resp.Key
+	return nil
+}
+
+func (j *Jira) close(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	path := "/rest/api/2/issue/" + n.ExternalKey
+	comment := map[string]string{"body": fmt.Sprintf("The incident has been resolved.\n%s", incidentUrl(baseUrl, n))}
+	if err := j.request(ctx, http.MethodPost, path+"/comment", comment, nil); err != nil {
+		return err
+	}
+	var resp struct {
+		Transitions []struct {
+			Id string `json:"id"`
+			To struct {
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := j.request(ctx, http.MethodGet, path+"/transitions", nil, &resp); err != nil {
+		return err
+	}
+	for _, t := range resp.Transitions {
+		if t.To.StatusCategory.Key == "done" {
+			return j.request(ctx, http.MethodPost, path+"/transitions", map[string]any{"transition": map[string]string{"id": t.Id}}, nil)
+		}
+	}
+	return nil
+}
+
+func (j *Jira) request(ctx context.Context, method, path string, body, res any) error {
+	return sendJson(ctx, http.DefaultClient, method, strings.TrimRight(j.cfg.Url, "/")+path, j.cfg.Email, j.cfg.ApiToken, body, res)
+}
@@ -0,0 +1,113 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"strings"
+)
+
+// Mattermost sends messages through an incoming webhook. The payload is compatible with Slack's attachments.
+type Mattermost struct {
+	webhookUrl string
+	channel    string
+}
+
+func NewMattermost(webhookUrl, channel string) *Mattermost {
+	return &Mattermost{webhookUrl: webhookUrl, channel: channel}
+}
+
+type mattermostAttachment struct {
+	Color     string            `json:"color"`
+	Fallback  string            `json:"fallback"`
+	Title     string            `json:"title,omitempty"`
+	TitleLink string            `json:"title_link,omitempty"`
+	Text      string            `json:"text,omitempty"`
+	Fields    []mattermostField `json:"fields,omitempty"`
+}
+
+type mattermostField struct {
+	Short bool   `json:"short"`
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func (m *Mattermost) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	a := mattermostAttachment{Color: n.Status.Color(), TitleLink: incidentUrl(baseUrl, n)}
+	if n.Status == model.OK {
+		a.Title = fmt.Sprintf("%s incident resolved", n.ApplicationId.Name)
+	} else {
+		a.Title = fmt.Sprintf("[%s] %s is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name)
+	}
+	if n.Title != "" {
+		a.Title = n.Title
+	}
+	a.Fallback = a.Title
+	var details []string
+	if n.Details != nil {
+		for _, r := range n.Details.Reports {
+			details = append(details, fmt.Sprintf("• %s**%s** / %s: %s", checkSeverity(r.Status), r.Name, r.Check, r.Message+checkAnnotation(r.Annotation)))
+		}
+	}
+	if team := ownerTeam(n.Details); team != "" {
+		details = append(details, team)
+	}
+	a.Text = strings.Join(details, "\n")
+	if n.Text != "" {
+		a.Text = n.Text
+	}
+	return m.send(ctx, a)
+}
+
+func (m *Mattermost) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	if ds.State == model.ApplicationDeploymentStateInProgress {
+		return nil
+	}
+	d := ds.Deployment
+	status := deploymentState(ds.State)
+	a := mattermostAttachment{
+		Color:     ds.Status.Color(),
+		Title:     deploymentTitle(project, d),
+		TitleLink: deploymentUrl(project.Settings.Integrations.BaseUrl, project.Id, d),
+		Fields: []mattermostField{
+			{Short: true, Title: "Status", Value: status},
+			{Short: true, Title: "Version", Value: d.Version()},
+		},
+	}
+	a.Fallback = a.Title + ": " + status
+	if summary := deploymentSummary(ds); summary != nil {
+		a.Fields = append(a.Fields, mattermostField{Title: "Summary", Value: strings.Join(summary, "\n")})
+	}
+	return m.send(ctx, a)
+}
+
+func (m *Mattermost) SendDigest(ctx context.Context, project *db.Project, d *model.Digest) error {
+	title := digestTitle(project, d)
+	a := mattermostAttachment{
+		Color:     d.Status().Color(),
+		Fallback:  title,
+		Title:     title,
+		TitleLink: projectUrl(project.Settings.Integrations.BaseUrl, project.Id),
+	}
+	sections := digestSections(d, func(v string) string { return "**" + v + "**" })
+	if len(sections) == 0 {
+		a.Text = "Nothing notable happened"
+	}
+	var texts []string
+	for _, sec := range sections {
+		texts = append(texts, fmt.Sprintf("**%s**\n• %s", sec.title, strings.Join(sec.items, "\n• ")))
+	}
+	if len(texts) > 0 {
+		a.Text = strings.Join(texts, "\n\n")
+	}
+	return m.send(ctx, a)
+}
+
+func (m *Mattermost) send(ctx context.Context, a mattermostAttachment) error {
+	payload := map[string]any{"username": "Coroot", "attachments": []mattermostAttachment{a}}
+	if m.channel != "" {
+		payload["channel"] = m.channel
+	}
+	return postJson(ctx, m.webhookUrl, payload)
+}
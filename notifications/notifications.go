@@ -1,11 +1,21 @@
 package notifications
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -13,43 +23,149 @@ const (
 	sendTimeout   = 30 * time.Second
 	retryInterval = time.Minute
 	retryWindow   = timeseries.Hour
+
+	webhookAttempts = 3
+
+	incidentDeploymentWindow = timeseries.Hour
 )
 
+var errRateLimitExceeded = errors.New("suppressed: rate limit exceeded")
+
+// LogDelivery saves the attempt to send the notification that started at the given time.
+func LogDelivery(database *db.DB, d db.NotificationDelivery, started time.Time, err error) {
+	d.Timestamp = timeseries.Now()
+	d.DurationMs = time.Since(started).Milliseconds()
+	if d.Attempt == 0 {
+		d.Attempt = 1
+	}
+	if err != nil {
+		d.Error = redactUrls(err.Error())
+	}
+	database.LogNotificationDelivery(d)
+}
+
+var urlRe = regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^\s"'<>]+`)
+
+// redactUrls strips the URLs in the error message down to the scheme and host before the message is stored:
+// the paths and the query strings of the notification URLs often contain secrets (e.g., the tokens of the webhook URLs).
+func redactUrls(s string) string {
+	return urlRe.ReplaceAllStringFunc(s, func(raw string) string {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return "<redacted>"
+		}
+		res := u.Scheme + "://" + u.Host
+		if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			res += "/<redacted>"
+		}
+		return res
+	})
+}
+
+// retryBackoff returns the delay before the next attempt to send a notification: it doubles with each failed attempt up to 16 minutes.
+func retryBackoff(attempts int) timeseries.Duration {
+	if attempts > 5 {
+		attempts = 5
+	}
+	return timeseries.Duration(retryInterval.Seconds()) << (attempts - 1)
+}
+
 type NotificationClient interface {
 	SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error
 }
 
+// getClient returns the client of the integration if it's configured and incident notifications are enabled for it.
 func getClient(destination db.IntegrationType, integrations db.Integrations) NotificationClient {
+	for _, i := range integrations.GetInfo() {
+		if i.Type == destination && i.Configured && i.Incidents {
+			return newClient(destination, integrations)
+		}
+	}
+	return nil
+}
+
+// newClient returns the client of the integration if it's configured, regardless of whether incident notifications are enabled for it:
+// the integrations used only as the destinations of the notification routes or the escalation policies receive incidents as well.
+func newClient(destination db.IntegrationType, integrations db.Integrations) NotificationClient {
 	switch destination {
 	case db.IntegrationTypeSlack:
-		if cfg := integrations.Slack; cfg != nil && cfg.Incidents {
-			return NewSlack(cfg.Token, cfg.DefaultChannel)
+		if cfg := integrations.Slack; cfg != nil {
+			c := NewSlack(cfg.Token, cfg.DefaultChannel)
+			c.interactive = cfg.SigningSecret != ""
+			return c
+		}
+	case db.IntegrationTypeMattermost:
+		if cfg := integrations.Mattermost; cfg != nil {
+			return NewMattermost(cfg.WebhookUrl, cfg.Channel)
 		}
 	case db.IntegrationTypeTeams:
-		if cfg := integrations.Teams; cfg != nil && cfg.Incidents {
+		if cfg := integrations.Teams; cfg != nil {
 			return NewTeams(cfg.WebhookUrl)
 		}
+	case db.IntegrationTypeTelegram:
+		if cfg := integrations.Telegram; cfg != nil {
+			return NewTelegram(cfg.BotToken, cfg.ChatId)
+		}
+	case db.IntegrationTypeDiscord:
+		if cfg := integrations.Discord; cfg != nil {
+			return NewDiscord(cfg.WebhookUrl)
+		}
+	case db.IntegrationTypeEmail:
+		if cfg := integrations.Email; cfg != nil {
+			return NewEmail(*cfg)
+		}
 	case db.IntegrationTypePagerduty:
-		if cfg := integrations.Pagerduty; cfg != nil && cfg.Incidents {
+		if cfg := integrations.Pagerduty; cfg != nil {
 			return NewPagerduty(cfg.IntegrationKey)
 		}
 	case db.IntegrationTypeOpsgenie:
-		if cfg := integrations.Opsgenie; cfg != nil && cfg.Incidents {
+		if cfg := integrations.Opsgenie; cfg != nil {
 			return NewOpsgenie(cfg.ApiKey, cfg.EUInstance)
 		}
+	case db.IntegrationTypeTwilio:
+		if cfg := integrations.Twilio; cfg != nil {
+			return NewTwilio(*cfg)
+		}
+	case db.IntegrationTypeJira:
+		if cfg := integrations.Jira; cfg != nil {
+			return NewJira(*cfg)
+		}
+	case db.IntegrationTypeServiceNow:
+		if cfg := integrations.ServiceNow; cfg != nil {
+			return NewServiceNow(*cfg)
+		}
 	}
 	return nil
 }
 
+// incidentDetailsOnce returns a function building the details of the incident (including the chart) on the first call,
+// the following calls return copies of them, so they can be customized for each destination.
+func incidentDetailsOnce(app *model.Application, incident *model.ApplicationIncident) func() *db.IncidentNotificationDetails {
+	var details *db.IncidentNotificationDetails
+	built := false
+	return func() *db.IncidentNotificationDetails {
+		if !built {
+			details, built = incidentDetails(app, incident), true
+		}
+		if details == nil {
+			return nil
+		}
+		d := *details
+		return &d
+	}
+}
+
 func incidentDetails(app *model.Application, incident *model.ApplicationIncident) *db.IncidentNotificationDetails {
 	var reports []db.IncidentNotificationDetailsReport
 	if !incident.Resolved() {
 		for _, r := range app.Reports {
 			for _, ch := range r.Checks {
-				if ch.Status < model.WARNING {
+				if ch.Status < model.WARNING || ch.Silenced() {
 					continue
 				}
-				reports = append(reports, db.IncidentNotificationDetailsReport{Name: r.Name, Check: ch.Title, Message: ch.Message})
+				reports = append(reports, db.IncidentNotificationDetailsReport{
+					Name: r.Name, CheckId: ch.Id, Check: ch.Title, Status: ch.Status, Message: ch.Message, Annotation: ch.Annotation, Items: ch.Items(),
+				})
 			}
 		}
 	} else {
@@ -62,16 +178,344 @@ func incidentDetails(app *model.Application, incident *model.ApplicationIncident
 			}
 		}
 	}
-	if len(reports) == 0 {
+	deployment := incidentDeployment(app, incident)
+	if len(reports) == 0 && app.Owner == nil && deployment == nil {
+		return nil
+	}
+	return &db.IncidentNotificationDetails{Reports: reports, Owner: app.Owner, Deployment: deployment, Chart: incidentChart(app, incident)}
+}
+
+// incidentChart returns the snapshot of the first failing SLI: the percentage of successful requests
+// or the percentage of requests served faster than the objective.
+func incidentChart(app *model.Application, incident *model.ApplicationIncident) *db.IncidentNotificationDetailsChart {
+	if incident.Resolved() {
+		return nil
+	}
+	failing := map[model.CheckId]bool{}
+	for _, r := range app.Reports {
+		if r.Name != model.AuditReportSLO {
+			continue
+		}
+		for _, ch := range r.Checks {
+			if ch.Status >= model.WARNING {
+				failing[ch.Id] = true
+			}
+		}
+	}
+	var chart *db.IncidentNotificationDetailsChart
+	var good *timeseries.TimeSeries
+	switch {
+	case failing[model.Checks.SLOAvailability.Id] && len(app.AvailabilitySLIs) > 0:
+		sli := app.AvailabilitySLIs[0]
+		total, failed := sli.GetTotalAndFailed(false)
+		good = timeseries.Aggregate2(total, failed, func(total, failed float32) float32 { return (total - failed) / total * 100 })
+		chart = &db.IncidentNotificationDetailsChart{Title: "Successful requests, %", Threshold: sli.Config.ObjectivePercentage}
+	case failing[model.Checks.SLOLatency.Id] && len(app.LatencySLIs) > 0:
+		sli := app.LatencySLIs[0]
+		total, fast := sli.GetTotalAndFast(false)
+		good = timeseries.Aggregate2(total, fast, func(total, fast float32) float32 { return fast / total * 100 })
+		chart = &db.IncidentNotificationDetailsChart{
+			Title:     "Requests served faster than " + utils.FormatLatency(sli.Config.ObjectiveBucket) + ", %",
+			Threshold: sli.Config.ObjectivePercentage,
+		}
+	}
+	if good.IsEmpty() {
+		return nil
+	}
+	iter := good.Iter()
+	for iter.Next() {
+		_, v := iter.Value()
+		chart.Values = append(chart.Values, timeseries.Value(v))
+	}
+	return chart
+}
+
+// incidentDeployment returns the latest deployment started within incidentDeploymentWindow before the incident was opened.
+func incidentDeployment(app *model.Application, incident *model.ApplicationIncident) *db.IncidentNotificationDetailsDeployment {
+	if incident.Resolved() {
+		return nil
+	}
+	for i := len(app.Deployments) - 1; i >= 0; i-- {
+		d := app.Deployments[i]
+		if d.IsTrafficShift() || d.StartedAt.After(incident.OpenedAt) {
+			continue
+		}
+		if incident.OpenedAt.Sub(d.StartedAt) > incidentDeploymentWindow {
+			return nil
+		}
+		return &db.IncidentNotificationDetailsDeployment{Id: d.Id(), Version: d.Version(), StartedAt: d.StartedAt}
+	}
+	return nil
+}
+
+func incidentOwner(details *db.IncidentNotificationDetails) *model.ApplicationOwner {
+	if details == nil || details.Owner.IsEmpty() {
 		return nil
 	}
-	return &db.IncidentNotificationDetails{Reports: reports}
+	return details.Owner
+}
+
+func ownerTeam(details *db.IncidentNotificationDetails) string {
+	var parts []string
+	if o := incidentOwner(details); o != nil && o.Team != "" {
+		parts = append(parts, "Owner: "+o.Team)
+	}
+	if details != nil && details.OnCall != "" {
+		parts = append(parts, "On-call: "+details.OnCall)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func checkSeverity(status model.Status) string {
+	if status < model.WARNING {
+		return ""
+	}
+	return "[" + strings.ToUpper(status.String()) + "] "
+}
+
+func checkAnnotation(a *model.CheckAnnotation) string {
+	if a == nil {
+		return ""
+	}
+	var parts []string
+	if a.Description != "" {
+		parts = append(parts, a.Description)
+	}
+	if a.Owner != "" {
+		parts = append(parts, "owner: "+a.Owner)
+	}
+	if a.RunbookUrl != "" {
+		parts = append(parts, "runbook: "+a.RunbookUrl)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
 }
 
 func incidentUrl(baseUrl string, n *db.IncidentNotification) string {
 	return fmt.Sprintf("%s/p/%s/app/%s?incident=%s", baseUrl, n.ProjectId, n.ApplicationId.String(), n.IncidentKey)
 }
 
+func ticketSummary(n *db.IncidentNotification) string {
+	return fmt.Sprintf("[%s] %s is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name)
+}
+
+// ticketDescription is a plain-text description of the incident with the links to the reports of the failing checks
+// and to the deployment that preceded the incident.
+func ticketDescription(baseUrl string, n *db.IncidentNotification) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Application: %s\n", n.ApplicationId.String())
+	if team := ownerTeam(n.Details); team != "" {
+		b.WriteString(team + "\n")
+	}
+	if n.Details != nil {
+		if len(n.Details.Reports) > 0 {
+			b.WriteString("\nFailing checks:\n")
+		}
+		for _, r := range n.Details.Reports {
+			fmt.Fprintf(&b, "- %s%s / %s: %s", checkSeverity(r.Status), r.Name, r.Check, r.Message+checkAnnotation(r.Annotation))
+			if len(r.Items) > 0 {
+				fmt.Fprintf(&b, " (%s)", strings.Join(r.Items, ", "))
+			}
+			fmt.Fprintf(&b, "\n  %s\n", reportUrl(baseUrl, n, r.Name))
+		}
+		if d := n.Details.Deployment; d != nil {
+			fmt.Fprintf(&b, "\nDeployment: %s, %s before the incident\n", d.Version, utils.FormatDuration(n.Timestamp.Sub(d.StartedAt), 1))
+			fmt.Fprintf(&b, "  %s\n", incidentDeploymentUrl(baseUrl, n))
+		}
+	}
+	fmt.Fprintf(&b, "\nIncident: %s\n", incidentUrl(baseUrl, n))
+	return b.String()
+}
+
+// postJson sends the body encoded as JSON to a webhook URL.
+func postJson(ctx context.Context, url string, body any) error {
+	return sendJson(ctx, http.DefaultClient, http.MethodPost, url, "", "", body, nil)
+}
+
+// sendJson sends the body encoded as JSON, with basic authentication if the credentials are set, and decodes the response into res, if it's not nil.
+func sendJson(ctx context.Context, client *http.Client, method, url, user, password string, body, res any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Coroot")
+	if user != "" || password != "" {
+		req.SetBasicAuth(user, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, msg)
+	}
+	if res == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+func reportUrl(baseUrl string, n *db.IncidentNotification, report model.AuditReportName) string {
+	return fmt.Sprintf("%s/p/%s/app/%s/%s?incident=%s", baseUrl, n.ProjectId, n.ApplicationId.String(), report, n.IncidentKey)
+}
+
 func deploymentUrl(baseUrl string, projectId db.ProjectId, d *model.ApplicationDeployment) string {
-	return fmt.Sprintf("%s/p/%s/app/%s/Deployments#%s", baseUrl, projectId, d.ApplicationId.String(), d.Id())
+	return applicationDeploymentUrl(baseUrl, projectId, d.ApplicationId, d.Id())
+}
+
+// incidentDeploymentUrl returns the link to the deployment preceding the incident, the notification must have one.
+func incidentDeploymentUrl(baseUrl string, n *db.IncidentNotification) string {
+	return applicationDeploymentUrl(baseUrl, n.ProjectId, n.ApplicationId, n.Details.Deployment.Id)
+}
+
+func applicationDeploymentUrl(baseUrl string, projectId db.ProjectId, appId model.ApplicationId, deploymentId string) string {
+	return fmt.Sprintf("%s/p/%s/app/%s/Deployments#%s", baseUrl, projectId, appId.String(), deploymentId)
+}
+
+func deploymentTitle(project *db.Project, d *model.ApplicationDeployment) string {
+	return fmt.Sprintf("Deployment of %s to %s", d.ApplicationId.Name, project.Name)
+}
+
+func deploymentState(s model.ApplicationDeploymentState) string {
+	switch s {
+	case model.ApplicationDeploymentStateInProgress:
+		return "In-progress"
+	case model.ApplicationDeploymentStateStuck:
+		return "Stuck"
+	case model.ApplicationDeploymentStateCancelled:
+		return "Cancelled"
+	case model.ApplicationDeploymentStateRollbackRecommended:
+		return "Rollback recommended"
+	}
+	return "Deployed"
+}
+
+// deploymentSummary returns the lines of the deployment summary, or nil if the summary is not ready yet.
+func deploymentSummary(ds model.ApplicationDeploymentStatus) []string {
+	if ds.State < model.ApplicationDeploymentStateSummary {
+		return nil
+	}
+	if len(ds.Summary) == 0 {
+		return []string{"No notable changes"}
+	}
+	lines := make([]string, 0, len(ds.Summary))
+	for _, s := range ds.Summary {
+		lines = append(lines, fmt.Sprintf("%s %s", s.Emoji(), s.Message))
+	}
+	return lines
+}
+
+type digestSection struct {
+	title string
+	items []string
+}
+
+// digestSections renders the sections of a digest, bold formats a string using the markup of the messenger.
+func digestSections(d *model.Digest, bold func(string) string) []digestSection {
+	var res []digestSection
+	if len(d.UnhealthyApps) > 0 {
+		s := digestSection{title: "Unhealthy applications"}
+		for _, a := range d.UnhealthyApps {
+			checks := a.Checks
+			if len(checks) > 3 {
+				checks = append(checks[:3:3], fmt.Sprintf("and %d more", len(a.Checks)-3))
+			}
+			s.items = append(s.items, fmt.Sprintf("%s%s: %s", checkSeverity(a.Status), bold(a.ApplicationId.Name), strings.Join(checks, "; ")))
+		}
+		res = append(res, s)
+	}
+	if len(d.SLO) > 0 {
+		s := digestSection{title: "Error budget burn"}
+		for _, slo := range d.SLO {
+			s.items = append(s.items, fmt.Sprintf("%s%s / %s (%s): %.0f%% of the budget consumed, %.0f%% projected",
+				checkSeverity(slo.Status), bold(slo.ApplicationId.Name), slo.SLO, utils.FormatPercentage(slo.Objective), slo.Consumed, slo.Projected))
+		}
+		res = append(res, s)
+	}
+	if len(d.Deployments) > 0 {
+		s := digestSection{title: "Notable deployments"}
+		for _, dep := range d.Deployments {
+			details := []string{dep.Message}
+			if len(dep.Summary) > 0 {
+				details = details[:0]
+				for _, ss := range dep.Summary {
+					details = append(details, ss.Emoji()+" "+ss.Message)
+				}
+			}
+			s.items = append(s.items, fmt.Sprintf("%s%s %s (%s): %s", checkSeverity(dep.Status), bold(dep.ApplicationId.Name), dep.Version,
+				dep.StartedAt.ToStandard().UTC().Format("Jan 2, 15:04 UTC"), strings.Join(details, "; ")))
+		}
+		res = append(res, s)
+	}
+	if len(d.Regressions) > 0 {
+		s := digestSection{title: "Top regressions"}
+		for _, r := range d.Regressions {
+			s.items = append(s.items, fmt.Sprintf("%s / %s: %s over the last week", bold(r.ApplicationId.Name), r.Title, r.Diff))
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+func digestTitle(project *db.Project, d *model.Digest) string {
+	period := "Daily"
+	if d.Period == model.DigestPeriodWeekly {
+		period = "Weekly"
+	}
+	return fmt.Sprintf("%s digest of %s: %s", period, project.Name, d.Name)
+}
+
+func projectUrl(baseUrl string, projectId db.ProjectId) string {
+	return fmt.Sprintf("%s/p/%s", baseUrl, projectId)
+}
+
+type rateLimit struct {
+	key   string
+	limit int // 0 means no limit
+}
+
+// rateLimiter allows up to a limit of messages per key within a sliding hour.
+type rateLimiter struct {
+	lock sync.Mutex
+	sent map[string][]time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{sent: map[string][]time.Time{}}
+}
+
+// allow records the message for every key if none of the limits is exceeded.
+func (l *rateLimiter) allow(now time.Time, limits ...rateLimit) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, rl := range limits {
+		var recent []time.Time
+		for _, t := range l.sent[rl.key] {
+			if now.Sub(t) < time.Hour {
+				recent = append(recent, t)
+			}
+		}
+		l.sent[rl.key] = recent
+		if rl.limit > 0 && len(recent) >= rl.limit {
+			return false
+		}
+	}
+	for _, rl := range limits {
+		l.sent[rl.key] = append(l.sent[rl.key], now)
+	}
+	return true
 }
@@ -45,6 +45,8 @@ func (og *Opsgenie) SendIncident(ctx context.Context, baseUrl string, n *db.Inci
 		Message: fmt.Sprintf("[%s] %s is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name),
 		Alias:   n.ExternalKey,
 		Source:  "Coroot",
+		Entity:  n.ApplicationId.String(),
+		Tags:    opsgenieTags(n),
 	}
 	switch n.Status {
 	case model.CRITICAL:
@@ -56,10 +58,46 @@ func (og *Opsgenie) SendIncident(ctx context.Context, baseUrl string, n *db.Inci
 	}
 	if n.Details != nil && len(n.Details.Reports) > 0 {
 		for _, r := range n.Details.Reports {
-			req.Description += fmt.Sprintf("• %s / %s: %s\n", r.Name, r.Check, r.Message)
+			req.Description += fmt.Sprintf("• %s%s / %s: %s\n", checkSeverity(r.Status), r.Name, r.Check, r.Message+checkAnnotation(r.Annotation))
+		}
+	}
+	if o := incidentOwner(n.Details); o != nil {
+		if o.Team != "" {
+			req.Responders = append(req.Responders, alert.Responder{Type: alert.TeamResponder, Name: o.Team})
+		}
+		if o.Escalation != "" {
+			req.Responders = append(req.Responders, alert.Responder{Type: alert.EscalationResponder, Name: o.Escalation})
 		}
 	}
 	req.Description += fmt.Sprintf("\n%s", incidentUrl(baseUrl, n))
 	_, err := og.client.Create(ctx, req)
 	return err
 }
+
+// opsgenieTags returns the tags of the alert: the project, the application, its namespace, and the failing checks.
+// Opsgenie accepts up to 20 tags of up to 50 characters.
+func opsgenieTags(n *db.IncidentNotification) []string {
+	tags := []string{"project:" + string(n.ProjectId), "application:" + n.ApplicationId.Name}
+	if n.ApplicationId.Namespace != "" {
+		tags = append(tags, "namespace:"+n.ApplicationId.Namespace)
+	}
+	if n.Details != nil {
+		seen := map[string]bool{}
+		for _, r := range n.Details.Reports {
+			if r.Status < model.WARNING || seen[r.Check] {
+				continue
+			}
+			seen[r.Check] = true
+			tags = append(tags, "check:"+r.Check)
+		}
+	}
+	if len(tags) > 20 {
+		tags = tags[:20]
+	}
+	for i, t := range tags {
+		if len(t) > 50 {
+			tags[i] = t[:50]
+		}
+	}
+	return tags
+}
@@ -31,13 +31,30 @@ func (pd *Pagerduty) SendIncident(ctx context.Context, baseUrl string, n *db.Inc
 		e.Payload = &pagerduty.V2Payload{
 			Summary:   fmt.Sprintf("[%s] %s is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name),
 			Source:    "Coroot",
-			Severity:  n.Status.String(),
+			Severity:  pagerdutySeverity(n.Status),
 			Timestamp: n.Timestamp.ToStandard().String(),
+			Component: n.ApplicationId.Name,
+		}
+		if o := incidentOwner(n.Details); o != nil {
+			e.Payload.Group = o.Team
 		}
 		if n.Details != nil && len(n.Details.Reports) > 0 {
 			details := map[string]string{}
+			seen := map[model.AuditReportName]bool{}
+			var worst model.Status
 			for _, r := range n.Details.Reports {
-				details[fmt.Sprintf("%s / %s", r.Name, r.Check)] = r.Message
+				msg := checkSeverity(r.Status) + r.Message + checkAnnotation(r.Annotation)
+				if len(r.Items) > 0 {
+					msg += fmt.Sprintf(" [%s]", strings.Join(r.Items, ", "))
+				}
+				details[fmt.Sprintf("%s / %s", r.Name, r.Check)] = msg
+				if r.Status > worst {
+					worst, e.Payload.Class = r.Status, r.Check
+				}
+				if !seen[r.Name] {
+					seen[r.Name] = true
+					e.Links = append(e.Links, map[string]string{"href": reportUrl(baseUrl, n, r.Name), "text": fmt.Sprintf("%s report", r.Name)})
+				}
 			}
 			e.Payload.Details = details
 		}
@@ -45,3 +62,16 @@ func (pd *Pagerduty) SendIncident(ctx context.Context, baseUrl string, n *db.Inc
 	_, err := pagerduty.ManageEventWithContext(ctx, e)
 	return err
 }
+
+// pagerdutySeverity maps the status to one of the severities supported by the Events API: critical, error, warning, info.
+func pagerdutySeverity(s model.Status) string {
+	switch s {
+	case model.CRITICAL:
+		return "critical"
+	case model.WARNING:
+		return "warning"
+	case model.INFO, model.OK:
+		return "info"
+	}
+	return "error"
+}
@@ -0,0 +1,60 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"net/http"
+	"strings"
+)
+
+const serviceNowStateResolved = "6"
+
+type ServiceNow struct {
+	cfg db.IntegrationServiceNow
+}
+
+func NewServiceNow(cfg db.IntegrationServiceNow) *ServiceNow {
+	return &ServiceNow{cfg: cfg}
+}
+
+// SendIncident opens an incident through the Table API and saves its sys_id as the external key of the notification.
+func (s *ServiceNow) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	if n.Status == model.OK {
+		if n.ExternalKey == "" { // the incident hasn't been opened
+			return nil
+		}
+		req := map[string]string{
+			"state":       serviceNowStateResolved,
+			"close_code":  "Solved (Permanently)",
+			"close_notes": fmt.Sprintf("The incident has been resolved.\n%s", incidentUrl(baseUrl, n)),
+		}
+		return s.request(ctx, http.MethodPatch, "/api/now/table/incident/"+n.ExternalKey, req, nil)
+	}
+	urgency := "2"
+	if n.Status == model.CRITICAL {
+		urgency = "1"
+	}
+	req := map[string]string{
+		"short_description": ticketSummary(n),
+		"description":       ticketDescription(baseUrl, n),
+		"urgency":           urgency,
+		"impact":            urgency,
+		"correlation_id":    fmt.Sprintf("coroot:%s:%s", n.ProjectId, n.IncidentKey),
+	}
+	var resp struct {
+		Result struct {
+			SysId string `json:"sys_id"`
+		} `json:"result"`
+	}
+	if err := s.request(ctx, http.MethodPost, "/api/now/table/incident", req, &resp); err != nil {
+		return err
+	}
+	n.ExternalKey = resp.Result.SysId
+	return nil
+}
+
+func (s *ServiceNow) request(ctx context.Context, method, path string, body, res any) error {
+	return sendJson(ctx, http.DefaultClient, method, strings.TrimRight(s.cfg.Url, "/")+path, s.cfg.Username, s.cfg.Password, body, res)
+}
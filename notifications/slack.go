@@ -2,17 +2,31 @@ package notifications
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
 	"github.com/slack-go/slack"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
+const (
+	slackActionAck     = "ack"
+	slackActionSilence = "silence"
+	slackActionOpen    = "open"
+)
+
+var slackSilenceHours = []int{1, 4, 24}
+
 type Slack struct {
-	channel string
-	client  *slack.Client
+	channel     string
+	client      *slack.Client
+	interactive bool
 }
 
 func NewSlack(token, channel string) *Slack {
@@ -30,6 +44,9 @@ func (s *Slack) SendIncident(ctx context.Context, baseUrl string, n *db.Incident
 	}
 	if ch == "" {
 		ch = s.channel
+		if o := incidentOwner(n.Details); o != nil && o.SlackChannel != "" {
+			ch = o.SlackChannel
+		}
 	}
 	var header, snippet string
 	if n.Status == model.OK {
@@ -39,13 +56,26 @@ func (s *Slack) SendIncident(ctx context.Context, baseUrl string, n *db.Incident
 		header = fmt.Sprintf("[%s] <%s|*%s* is not meeting its SLOs>", strings.ToUpper(n.Status.String()), incidentUrl(baseUrl, n), n.ApplicationId.Name)
 		snippet = fmt.Sprintf("%s is not meeting its SLOs", n.ApplicationId.Name)
 	}
+	if n.Title != "" {
+		header, snippet = n.Title, n.Title
+	}
 	var details []string
 	if n.Details != nil {
 		for _, r := range n.Details.Reports {
-			details = append(details, fmt.Sprintf("• *%s* / %s: %s", r.Name, r.Check, r.Message))
+			details = append(details, fmt.Sprintf("• %s*%s* / %s: %s", checkSeverity(r.Status), r.Name, r.Check, r.Message+checkAnnotation(r.Annotation)))
 		}
 	}
-	body := s.body(n.Status.Color(), snippet, s.section(s.text(header)), s.section(s.text(strings.Join(details, "\n"))))
+	if team := ownerTeam(n.Details); team != "" {
+		details = append(details, team)
+	}
+	if n.Text != "" {
+		details = []string{n.Text}
+	}
+	blocks := []slack.Block{s.section(s.text(header)), s.section(s.text(strings.Join(details, "\n")))}
+	if s.interactive && n.Status != model.OK {
+		blocks = append(blocks, s.incidentActions(baseUrl, n))
+	}
+	body := s.body(n.Status.Color(), snippet, blocks...)
 	opts := []slack.MsgOption{body, slack.MsgOptionDisableLinkUnfurl()}
 	if ts != "" {
 		opts = append(opts, slack.MsgOptionTS(ts), slack.MsgOptionBroadcast())
@@ -59,6 +89,126 @@ func (s *Slack) SendIncident(ctx context.Context, baseUrl string, n *db.Incident
 	return nil
 }
 
+// incidentActions returns the buttons to acknowledge the incident, silence one of the failing checks, and open the report of the first one.
+// The incident is identified by the ID of the block, so that the values of the elements fit the limits of Slack.
+func (s *Slack) incidentActions(baseUrl string, n *db.IncidentNotification) *slack.ActionBlock {
+	ack := slack.NewButtonBlockElement(slackActionAck, slackActionAck, slack.NewTextBlockObject(slack.PlainTextType, "Acknowledge", false, false))
+	ack.WithStyle(slack.StylePrimary)
+	elements := []slack.BlockElement{ack}
+	var options []*slack.OptionBlockObject
+	link := incidentUrl(baseUrl, n)
+	if n.Details != nil {
+		for _, r := range n.Details.Reports {
+			if r.CheckId == "" || r.Status < model.WARNING {
+				continue
+			}
+			if len(options) == 0 {
+				link = reportUrl(baseUrl, n, r.Name)
+			}
+			title := r.Check
+			if len(title) > 64 { // the text of an option is limited to 75 characters
+				title = title[:64]
+			}
+			for _, h := range slackSilenceHours {
+				text := fmt.Sprintf("%s for %dh", title, h)
+				options = append(options, slack.NewOptionBlockObject(fmt.Sprintf("%s|%d", r.CheckId, h), slack.NewTextBlockObject(slack.PlainTextType, text, false, false), nil))
+			}
+		}
+	}
+	if len(options) > 100 {
+		options = options[:100]
+	}
+	if len(options) > 0 {
+		placeholder := slack.NewTextBlockObject(slack.PlainTextType, "Silence a check", false, false)
+		elements = append(elements, slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, slackActionSilence, options...))
+	}
+	open := slack.NewButtonBlockElement(slackActionOpen, "", slack.NewTextBlockObject(slack.PlainTextType, "Open report", false, false))
+	open.URL = link
+	elements = append(elements, open)
+	// block IDs are limited to 255 characters, so the application isn't included: it's looked up by the incident key
+	blockId := strings.Join([]string{"coroot", string(n.ProjectId), n.IncidentKey}, "|")
+	return slack.NewActionBlock(blockId, elements...)
+}
+
+// SlackAction is an action taken on an interactive incident message: acknowledging the incident or silencing a check.
+// ApplicationId is empty unless it's in the block ID of the message, it should then be looked up by the incident key.
+type SlackAction struct {
+	Type          string
+	ProjectId     db.ProjectId
+	IncidentKey   string
+	ApplicationId model.ApplicationId
+	CheckId       model.CheckId
+	Duration      timeseries.Duration
+	User          string
+	ResponseUrl   string
+}
+
+// ParseSlackAction parses the payload of an interactivity request. It returns nil if the request doesn't contain an action
+// to be handled by Coroot (e.g., a click on a link button). The request must then be verified with VerifySlackRequest
+// using the signing secret of the project.
+func ParseSlackAction(body []byte) (*SlackAction, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	var callback slack.InteractionCallback
+	if err = json.Unmarshal([]byte(form.Get("payload")), &callback); err != nil {
+		return nil, err
+	}
+	for _, a := range callback.ActionCallback.BlockActions {
+		parts := strings.SplitN(a.BlockID, "|", 4)
+		if len(parts) < 3 || parts[0] != "coroot" {
+			continue
+		}
+		action := &SlackAction{
+			Type:        a.ActionID,
+			ProjectId:   db.ProjectId(parts[1]),
+			IncidentKey: parts[2],
+			User:        callback.User.Name,
+			ResponseUrl: callback.ResponseURL,
+		}
+		if len(parts) == 4 { // messages sent before the application was dropped from the block ID
+			if action.ApplicationId, err = model.NewApplicationIdFromString(parts[3]); err != nil {
+				return nil, err
+			}
+		}
+		switch a.ActionID {
+		case slackActionAck:
+		case slackActionSilence:
+			v := strings.SplitN(a.SelectedOption.Value, "|", 2)
+			if len(v) != 2 {
+				return nil, fmt.Errorf("invalid option: %s", a.SelectedOption.Value)
+			}
+			hours, err := strconv.Atoi(v[1])
+			if err != nil || hours < 1 {
+				return nil, fmt.Errorf("invalid option: %s", a.SelectedOption.Value)
+			}
+			action.CheckId = model.CheckId(v[0])
+			action.Duration = timeseries.Duration(hours) * timeseries.Hour
+		default:
+			continue
+		}
+		return action, nil
+	}
+	return nil, nil
+}
+
+func VerifySlackRequest(header http.Header, body []byte, secret string) error {
+	sv, err := slack.NewSecretsVerifier(header, secret)
+	if err != nil {
+		return err
+	}
+	if _, err = sv.Write(body); err != nil {
+		return err
+	}
+	return sv.Ensure()
+}
+
+// Respond posts the result of the action to the channel of the incident message.
+func (a *SlackAction) Respond(ctx context.Context, text string) error {
+	return slack.PostWebhookContext(ctx, a.ResponseUrl, &slack.WebhookMessage{Text: text, ResponseType: slack.ResponseTypeInChannel})
+}
+
 func (s *Slack) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
 	d := ds.Deployment
 
@@ -70,10 +220,12 @@ func (s *Slack) SendDeployment(ctx context.Context, project *db.Project, ds mode
 		status = "Stuck"
 	case model.ApplicationDeploymentStateCancelled:
 		status = "Cancelled"
+	case model.ApplicationDeploymentStateRollbackRecommended:
+		status = "Rollback recommended"
 	}
 
 	var summary *slack.SectionBlock
-	if ds.State == model.ApplicationDeploymentStateSummary {
+	if ds.State >= model.ApplicationDeploymentStateSummary {
 		items := "No notable changes"
 		if len(ds.Summary) > 0 {
 			items = ""
@@ -129,6 +281,28 @@ func (s *Slack) SendDeployment(ctx context.Context, project *db.Project, ds mode
 	return nil
 }
 
+func (s *Slack) SendDigest(ctx context.Context, project *db.Project, d *model.Digest) error {
+	title := digestTitle(project, d)
+	blocks := []slack.Block{
+		s.section(s.text("<%s|*%s*>", projectUrl(project.Settings.Integrations.BaseUrl, project.Id), title)),
+	}
+	sections := digestSections(d, func(v string) string { return "*" + v + "*" })
+	if len(sections) == 0 {
+		blocks = append(blocks, s.section(s.text("Nothing notable happened")))
+	}
+	for _, sec := range sections {
+		blocks = append(blocks, s.section(s.text("*%s*\n• %s", sec.title, strings.Join(sec.items, "\n• "))))
+	}
+	blocks = append(blocks, slack.NewContextBlock("", s.text("<!date^%d^{date_short_pretty} at {time}|%s> - <!date^%d^{date_short_pretty} at {time}|%s>",
+		d.From, d.From.ToStandard().Format(time.RFC1123), d.To, d.To.ToStandard().Format(time.RFC1123))))
+
+	_, _, err := s.client.PostMessageContext(ctx, s.channel, s.body(d.Status().Color(), title, blocks...), slack.MsgOptionDisableLinkUnfurl())
+	if err != nil {
+		return fmt.Errorf("slack error: %w", err)
+	}
+	return nil
+}
+
 func (s *Slack) body(color string, fallback string, blocks ...slack.Block) slack.MsgOption {
 	return slack.MsgOptionAttachments(slack.Attachment{
 		Color:    color,
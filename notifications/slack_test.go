@@ -0,0 +1,67 @@
+package notifications
+
+import (
+	"encoding/json"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseSlackAction(t *testing.T) {
+	n := &db.IncidentNotification{
+		ProjectId:     "p",
+		ApplicationId: model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"),
+		IncidentKey:   "abc",
+		Status:        model.CRITICAL,
+		Details: &db.IncidentNotificationDetails{Reports: []db.IncidentNotificationDetailsReport{
+			{Name: model.AuditReportSLO, CheckId: model.Checks.SLOLatency.Id, Check: model.Checks.SLOLatency.Title, Status: model.CRITICAL},
+		}},
+	}
+	block := (&Slack{}).incidentActions("http://coroot", n)
+	assert.Len(t, block.Elements.ElementSet, 3)
+
+	payload := func(actionId, option string) []byte {
+		action := map[string]any{"action_id": actionId, "block_id": block.BlockID, "type": "button"}
+		if option != "" {
+			action["selected_option"] = map[string]string{"value": option}
+		}
+		data, err := json.Marshal(map[string]any{
+			"type":         "block_actions",
+			"user":         map[string]string{"name": "alice"},
+			"response_url": "https://hooks.slack.com/actions/1",
+			"actions":      []any{action},
+		})
+		require.NoError(t, err)
+		return []byte(url.Values{"payload": {string(data)}}.Encode())
+	}
+
+	a, err := ParseSlackAction(payload(slackActionAck, ""))
+	require.NoError(t, err)
+	assert.Equal(t, &SlackAction{
+		Type: slackActionAck, ProjectId: "p", IncidentKey: "abc", User: "alice", ResponseUrl: "https://hooks.slack.com/actions/1",
+	}, a)
+
+	a, err = ParseSlackAction(payload(slackActionSilence, string(model.Checks.SLOLatency.Id)+"|4"))
+	require.NoError(t, err)
+	assert.Equal(t, model.Checks.SLOLatency.Id, a.CheckId)
+	assert.Equal(t, 4*timeseries.Hour, a.Duration)
+
+	a, err = ParseSlackAction(payload(slackActionOpen, ""))
+	require.NoError(t, err)
+	assert.Nil(t, a)
+
+	// the block ID of the messages sent by the previous versions includes the application
+	block.BlockID = "coroot|p|abc|" + n.ApplicationId.String()
+	a, err = ParseSlackAction(payload(slackActionAck, ""))
+	require.NoError(t, err)
+	assert.Equal(t, n.ApplicationId, a.ApplicationId)
+
+	// block IDs are limited to 255 characters
+	n.ApplicationId = model.NewApplicationId(strings.Repeat("n", 63), model.ApplicationKindDeployment, strings.Repeat("a", 253))
+	assert.LessOrEqual(t, len((&Slack{}).incidentActions("http://coroot", n).BlockID), 255)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/atc0005/go-teams-notify/v2"
+	"github.com/atc0005/go-teams-notify/v2/adaptivecard"
 	"github.com/atc0005/go-teams-notify/v2/messagecard"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
 	"strings"
 )
 
@@ -22,32 +24,82 @@ func NewTeams(webhookUrl string) *Teams {
 	}
 }
 
+// SendIncident sends an Adaptive Card with the failing checks, the owner, and the recent deployment of the application.
 func (t *Teams) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
 	var title string
 	if n.Status == model.OK {
-		title = fmt.Sprintf("**%s** incident resolved", n.ApplicationId.Name)
+		title = fmt.Sprintf("%s incident resolved", n.ApplicationId.Name)
 	} else {
-		title = fmt.Sprintf("[%s] **%s** is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name)
+		title = fmt.Sprintf("[%s] %s is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name)
+	}
+	if n.Title != "" {
+		title = n.Title
 	}
 
-	msg := messagecard.NewMessageCard()
-	msg.Summary = title
-	msg.ThemeColor = n.Status.Color()
-	msg.Text = "# " + title + "\n"
+	card := adaptivecard.NewCard()
+	card.SetFullWidth()
+	heading := adaptivecard.NewTitleTextBlock(title, true)
+	heading.Color = teamsColor(n.Status)
+	_ = card.AddElement(false, heading)
+
+	facts := adaptivecard.NewFactSet()
+	_ = facts.AddFact(adaptivecard.Fact{Title: "Application", Value: n.ApplicationId.String()})
+	if n.Text != "" {
+		_ = card.AddElement(false, adaptivecard.NewTextBlock(n.Text, true))
+	}
 	if n.Details != nil {
-		s := &messagecard.Section{}
 		for _, r := range n.Details.Reports {
-			s.Text += fmt.Sprintf("• **%s** / %s: %s<br>", r.Name, r.Check, r.Message)
+			if n.Text != "" {
+				break
+			}
+			text := fmt.Sprintf("%s**%s** / %s: %s", checkSeverity(r.Status), r.Name, r.Check, r.Message+checkAnnotation(r.Annotation))
+			if len(r.Items) > 0 {
+				text += fmt.Sprintf(" (%s)", strings.Join(r.Items, ", "))
+			}
+			_ = card.AddElement(false, adaptivecard.NewTextBlock("• "+text, true))
+		}
+		if o := incidentOwner(n.Details); o != nil && o.Team != "" {
+			_ = facts.AddFact(adaptivecard.Fact{Title: "Owner", Value: o.Team})
+		}
+		if d := n.Details.Deployment; d != nil {
+			_ = facts.AddFact(adaptivecard.Fact{
+				Title: "Deployment",
+				Value: fmt.Sprintf("%s, %s before the incident", d.Version, utils.FormatDuration(n.Timestamp.Sub(d.StartedAt), 1)),
+			})
 		}
-		_ = msg.AddSection(s)
 	}
-	action, _ := messagecard.NewPotentialAction(messagecard.PotentialActionOpenURIType, "View incident")
-	action.Targets = []messagecard.PotentialActionOpenURITarget{{OS: "default", URI: incidentUrl(baseUrl, n)}}
-	_ = msg.AddPotentialAction(action)
-	if err := t.client.SendWithContext(ctx, t.webhookUrl, msg); err != nil {
+	_ = card.AddFactSet(false, facts)
+
+	var actions []adaptivecard.Action
+	if a, err := adaptivecard.NewActionOpenURL(incidentUrl(baseUrl, n), "View incident"); err == nil {
+		actions = append(actions, a)
+	}
+	if n.Details != nil && n.Details.Deployment != nil {
+		if a, err := adaptivecard.NewActionOpenURL(incidentDeploymentUrl(baseUrl, n), "View deployment"); err == nil {
+			actions = append(actions, a)
+		}
+	}
+	if len(actions) > 0 {
+		_ = card.AddAction(false, actions...)
+	}
+
+	msg, err := adaptivecard.NewMessageFromCard(card)
+	if err != nil {
 		return err
 	}
-	return nil
+	return t.client.SendWithContext(ctx, t.webhookUrl, msg)
+}
+
+func teamsColor(s model.Status) string {
+	switch s {
+	case model.CRITICAL:
+		return adaptivecard.ColorAttention
+	case model.WARNING:
+		return adaptivecard.ColorWarning
+	case model.OK:
+		return adaptivecard.ColorGood
+	}
+	return adaptivecard.ColorDefault
 }
 
 func (t *Teams) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
@@ -61,6 +113,8 @@ func (t *Teams) SendDeployment(ctx context.Context, project *db.Project, ds mode
 		status = "Stuck"
 	case model.ApplicationDeploymentStateCancelled:
 		status = "Cancelled"
+	case model.ApplicationDeploymentStateRollbackRecommended:
+		status = "Rollback recommended"
 	}
 
 	title := fmt.Sprintf("Deployment of **%s** to **%s**", d.ApplicationId.Name, project.Name)
@@ -76,7 +130,7 @@ func (t *Teams) SendDeployment(ctx context.Context, project *db.Project, ds mode
 		},
 	})
 
-	if ds.State == model.ApplicationDeploymentStateSummary {
+	if ds.State >= model.ApplicationDeploymentStateSummary {
 		summary := "No notable changes"
 		if len(ds.Summary) > 0 {
 			summary = ""
@@ -97,3 +151,28 @@ func (t *Teams) SendDeployment(ctx context.Context, project *db.Project, ds mode
 
 	return nil
 }
+
+func (t *Teams) SendDigest(ctx context.Context, project *db.Project, d *model.Digest) error {
+	title := digestTitle(project, d)
+
+	msg := messagecard.NewMessageCard()
+	msg.Summary = title
+	msg.ThemeColor = d.Status().Color()
+	msg.Text = "# " + title + "\n"
+	sections := digestSections(d, func(v string) string { return "**" + v + "**" })
+	if len(sections) == 0 {
+		_ = msg.AddSection(&messagecard.Section{Text: "Nothing notable happened"})
+	}
+	for _, sec := range sections {
+		_ = msg.AddSection(&messagecard.Section{Text: "**" + sec.title + "**<br>• " + strings.Join(sec.items, "<br>• ")})
+	}
+
+	action, _ := messagecard.NewPotentialAction(messagecard.PotentialActionOpenURIType, "Open Coroot")
+	action.Targets = []messagecard.PotentialActionOpenURITarget{{OS: "default", URI: projectUrl(project.Settings.Integrations.BaseUrl, project.Id)}}
+	_ = msg.AddPotentialAction(action)
+
+	if err := t.client.SendWithContext(ctx, t.webhookUrl, msg); err != nil {
+		return err
+	}
+	return nil
+}
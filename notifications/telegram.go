@@ -0,0 +1,148 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"html"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const telegramApiUrl = "https://api.telegram.org"
+
+// Telegram sends messages to a chat (a group, a channel, or a private chat) through the Bot API.
+type Telegram struct {
+	token  string
+	chatId string
+}
+
+func NewTelegram(token, chatId string) *Telegram {
+	return &Telegram{token: token, chatId: chatId}
+}
+
+// SendIncident sends the incident as a message or, if a chart of the failing SLI is available, as a photo with a caption.
+func (t *Telegram) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	var text string
+	if n.Status == model.OK {
+		text = fmt.Sprintf(`<a href="%s"><b>%s</b> incident resolved</a>`, incidentUrl(baseUrl, n), html.EscapeString(n.ApplicationId.Name))
+	} else {
+		text = fmt.Sprintf(`[%s] <a href="%s"><b>%s</b> is not meeting its SLOs</a>`,
+			strings.ToUpper(n.Status.String()), incidentUrl(baseUrl, n), html.EscapeString(n.ApplicationId.Name))
+	}
+	if n.Title != "" {
+		text = n.Title
+	}
+	if n.Text != "" {
+		text += "\n" + n.Text
+	}
+	if n.Details != nil && n.Text == "" {
+		for _, r := range n.Details.Reports {
+			text += fmt.Sprintf("\n• %s<b>%s</b> / %s: %s", checkSeverity(r.Status), r.Name, html.EscapeString(r.Check), html.EscapeString(r.Message+checkAnnotation(r.Annotation)))
+		}
+		if team := ownerTeam(n.Details); team != "" {
+			text += "\n" + html.EscapeString(team)
+		}
+		if d := n.Details.Deployment; d != nil {
+			text += fmt.Sprintf("\nRecent deployment: %s", html.EscapeString(d.Version))
+		}
+		if c := n.Details.Chart; c != nil && len(c.Values) > 0 {
+			img, err := renderChart(c)
+			if err != nil {
+				return err
+			}
+			return t.sendPhoto(ctx, img, html.EscapeString(c.Title)+"\n"+text)
+		}
+	}
+	return t.sendMessage(ctx, text)
+}
+
+func (t *Telegram) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	if ds.State == model.ApplicationDeploymentStateInProgress {
+		return nil
+	}
+	d := ds.Deployment
+	text := fmt.Sprintf(`Deployment of <a href="%s"><b>%s</b></a> to <b>%s</b>`,
+		deploymentUrl(project.Settings.Integrations.BaseUrl, project.Id, d), html.EscapeString(d.ApplicationId.Name), html.EscapeString(project.Name))
+	text += fmt.Sprintf("\n<b>Status:</b> %s\n<b>Version:</b> %s", deploymentState(ds.State), html.EscapeString(d.Version()))
+	if summary := deploymentSummary(ds); summary != nil {
+		text += "\n<b>Summary:</b>"
+		for _, l := range summary {
+			text += "\n" + html.EscapeString(l)
+		}
+	}
+	return t.sendMessage(ctx, text)
+}
+
+func (t *Telegram) sendMessage(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  t.chatId,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	return t.call(ctx, "sendMessage", "application/json", bytes.NewReader(body))
+}
+
+func (t *Telegram) sendPhoto(ctx context.Context, img []byte, caption string) error {
+	// captions are limited to 1024 characters
+	if len(caption) > 1024 {
+		return t.sendMessage(ctx, caption)
+	}
+	body := bytes.NewBuffer(nil)
+	mw := multipart.NewWriter(body)
+	_ = mw.WriteField("chat_id", t.chatId)
+	_ = mw.WriteField("caption", caption)
+	_ = mw.WriteField("parse_mode", "HTML")
+	fw, err := mw.CreateFormFile("photo", "chart.png")
+	if err != nil {
+		return err
+	}
+	if _, err = fw.Write(img); err != nil {
+		return err
+	}
+	if err = mw.Close(); err != nil {
+		return err
+	}
+	return t.call(ctx, "sendPhoto", mw.FormDataContentType(), body)
+}
+
+// call sends a request to the Bot API. The bot token is part of the request URL,
+// so the URL is stripped from the returned errors as they are logged and stored in the delivery log.
+func (t *Telegram) call(ctx context.Context, method, contentType string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/%s", telegramApiUrl, t.token, method), body)
+	if err != nil {
+		return fmt.Errorf("telegram: failed to create request")
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			return fmt.Errorf("telegram: %s %s: %w", ue.Op, method, ue.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	var res struct {
+		Ok          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	if !res.Ok {
+		return fmt.Errorf("telegram: %s", res.Description)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package notifications
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTelegramErrorsDoNotLeakToken(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := NewTelegram("123456:secret-token", "42").sendMessage(ctx, "test")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+	assert.ErrorIs(t, err, context.Canceled)
+}
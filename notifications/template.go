@@ -0,0 +1,137 @@
+package notifications
+
+import (
+	"bytes"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// IncidentTemplateData is the data the notification templates are executed with.
+type IncidentTemplateData struct {
+	Project     string
+	Application IncidentTemplateApplication
+	Status      string // critical, warning, or ok (resolved)
+	Resolved    bool
+	Url         string
+	Checks      []IncidentTemplateCheck
+	Owner       string
+	OnCall      string
+	Deployment  *IncidentTemplateDeployment
+}
+
+type IncidentTemplateApplication struct {
+	Id        string
+	Name      string
+	Namespace string
+	Kind      string
+}
+
+type IncidentTemplateCheck struct {
+	Report  string
+	Check   string
+	Status  string
+	Message string
+	Items   []string
+	Url     string
+}
+
+type IncidentTemplateDeployment struct {
+	Version   string
+	StartedAt time.Time
+	Url       string
+}
+
+// ParseNotificationTemplate parses the title or the body template of incident notifications.
+func ParseNotificationTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+func NewIncidentTemplateData(projectName, baseUrl string, n *db.IncidentNotification) IncidentTemplateData {
+	data := IncidentTemplateData{
+		Project: projectName,
+		Application: IncidentTemplateApplication{
+			Id:        n.ApplicationId.String(),
+			Name:      n.ApplicationId.Name,
+			Namespace: n.ApplicationId.Namespace,
+			Kind:      string(n.ApplicationId.Kind),
+		},
+		Status:   n.Status.String(),
+		Resolved: n.Status == model.OK,
+		Url:      incidentUrl(baseUrl, n),
+	}
+	if n.Details == nil {
+		return data
+	}
+	for _, r := range n.Details.Reports {
+		c := IncidentTemplateCheck{
+			Report:  string(r.Name),
+			Check:   r.Check,
+			Message: r.Message + checkAnnotation(r.Annotation),
+			Items:   r.Items,
+			Url:     reportUrl(baseUrl, n, r.Name),
+		}
+		if r.Status >= model.WARNING {
+			c.Status = r.Status.String()
+		}
+		data.Checks = append(data.Checks, c)
+	}
+	if o := incidentOwner(n.Details); o != nil {
+		data.Owner = o.Team
+	}
+	data.OnCall = n.Details.OnCall
+	if d := n.Details.Deployment; d != nil {
+		data.Deployment = &IncidentTemplateDeployment{
+			Version:   d.Version,
+			StartedAt: d.StartedAt.ToStandard(),
+			Url:       incidentDeploymentUrl(baseUrl, n),
+		}
+	}
+	return data
+}
+
+// RenderIncidentTemplate renders the title and the body of the notification. The empty parts of the template are left empty.
+func RenderIncidentTemplate(t model.NotificationTemplate, data IncidentTemplateData) (string, string, error) {
+	render := func(name, text string) (string, error) {
+		if strings.TrimSpace(text) == "" {
+			return "", nil
+		}
+		tmpl, err := ParseNotificationTemplate(name, text)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+	title, err := render("title", t.Title)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := render("body", t.Body)
+	if err != nil {
+		return "", "", err
+	}
+	return title, body, nil
+}
+
+// SampleIncidentTemplateData is used to validate and preview the templates.
+func SampleIncidentTemplateData(projectName, baseUrl string) IncidentTemplateData {
+	return IncidentTemplateData{
+		Project:     projectName,
+		Application: IncidentTemplateApplication{Id: "default:Deployment:app", Name: "app", Namespace: "default", Kind: "Deployment"},
+		Status:      model.CRITICAL.String(),
+		Url:         baseUrl,
+		Checks: []IncidentTemplateCheck{
+			{Report: string(model.AuditReportSLO), Check: "Availability", Status: model.CRITICAL.String(), Message: "error budget burn rate is 26x within 1 hour", Url: baseUrl},
+			{Report: string(model.AuditReportCPU), Check: "CPU usage", Status: model.WARNING.String(), Message: "high CPU utilization", Items: []string{"app-5b8f9c7d4-x2k9p"}, Url: baseUrl},
+		},
+		Owner:      "payments",
+		OnCall:     "John Doe",
+		Deployment: &IncidentTemplateDeployment{Version: "v1.2.3", StartedAt: time.Now().Add(-10 * time.Minute), Url: baseUrl},
+	}
+}
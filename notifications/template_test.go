@@ -0,0 +1,47 @@
+package notifications
+
+import (
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderIncidentTemplate(t *testing.T) {
+	n := &db.IncidentNotification{
+		ProjectId:     "p",
+		ApplicationId: model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"),
+		IncidentKey:   "abc",
+		Status:        model.CRITICAL,
+		Details: &db.IncidentNotificationDetails{
+			Reports: []db.IncidentNotificationDetailsReport{
+				{Name: model.AuditReportSLO, Check: "Availability", Status: model.CRITICAL, Message: "error budget burn rate is 26x within 1 hour"},
+			},
+			OnCall: "alice",
+		},
+	}
+	data := NewIncidentTemplateData("prod", "http://coroot", n)
+
+	tmpl := model.NotificationTemplate{
+		Title: "[{{upper .Status}}] {{.Application.Name}} не соответствует SLO",
+		Body:  "{{range .Checks}}• {{.Check}}: {{.Message}}\n{{end}}Дежурный: {{.OnCall}}",
+	}
+	title, body, err := RenderIncidentTemplate(tmpl, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "[CRITICAL] cart не соответствует SLO", title)
+	assert.Equal(t, "• Availability: error budget burn rate is 26x within 1 hour\nДежурный: alice", body)
+
+	title, body, err = RenderIncidentTemplate(model.NotificationTemplate{Body: "{{.Url}}"}, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "", title)
+	assert.Equal(t, "http://coroot/p/p/app/shop:Deployment:cart?incident=abc", body)
+
+	n.Details.Deployment = &db.IncidentNotificationDetailsDeployment{Id: "5d8f7c9b4:1700000000", Version: "5d8f7c9b4"}
+	data = NewIncidentTemplateData("prod", "http://coroot", n)
+	_, body, err = RenderIncidentTemplate(model.NotificationTemplate{Body: "{{.Deployment.Url}}"}, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://coroot/p/p/app/shop:Deployment:cart/Deployments#5d8f7c9b4:1700000000", body)
+
+	_, _, err = RenderIncidentTemplate(model.NotificationTemplate{Title: "{{.Unknown}}"}, data)
+	assert.Error(t, err)
+}
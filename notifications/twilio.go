@@ -0,0 +1,119 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"html"
+	"io"
+	"k8s.io/klog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	twilioApiUrl           = "https://api.twilio.com/2010-04-01/Accounts/"
+	twilioDefaultRateLimit = 5
+)
+
+// twilioLimiter is shared by all the clients, since a client is created for each notification.
+var twilioLimiter = newRateLimiter()
+
+type Twilio struct {
+	cfg    db.IntegrationTwilio
+	apiUrl string
+}
+
+func NewTwilio(cfg db.IntegrationTwilio) *Twilio {
+	return &Twilio{cfg: cfg, apiUrl: twilioApiUrl}
+}
+
+// SendIncident texts (and calls, if voice is enabled) the phone numbers of the application owner or the default ones.
+// Only critical incidents are sent, the numbers that exceeded the rate limit are skipped.
+// The messages and calls that succeeded (or were skipped) are recorded in the external key of the notification,
+// so a retry reaches only the numbers that haven't been notified yet.
+func (t *Twilio) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	if n.Status != model.CRITICAL {
+		return nil
+	}
+	recipients := incidentOwner(n.Details).Phones()
+	if len(recipients) == 0 {
+		recipients = t.cfg.Recipients()
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("no phone numbers")
+	}
+	limit := t.cfg.RateLimit
+	if limit <= 0 {
+		limit = twilioDefaultRateLimit
+	}
+	done := map[string]bool{}
+	for _, k := range strings.Split(n.ExternalKey, ",") {
+		done[k] = k != ""
+	}
+	markDone := func(key string) {
+		done[key] = true
+		if n.ExternalKey != "" {
+			n.ExternalKey += ","
+		}
+		n.ExternalKey += key
+	}
+	text := fmt.Sprintf("[CRITICAL] %s is not meeting its SLOs", n.ApplicationId.Name)
+	var lastErr error
+	failed := 0
+	for _, to := range recipients {
+		sms, call := "sms:"+to, "call:"+to
+		if done[sms] && (done[call] || !t.cfg.Voice) {
+			continue
+		}
+		if !done[sms] && !twilioLimiter.allow(time.Now(), rateLimit{key: to, limit: limit}) {
+			klog.Warningf("twilio: rate limit exceeded for %s", to)
+			markDone(sms)
+			markDone(call)
+			continue
+		}
+		var err error
+		if !done[sms] {
+			if err = t.request(ctx, "Messages.json", url.Values{"To": {to}, "From": {t.cfg.From}, "Body": {text + "\n" + incidentUrl(baseUrl, n)}}); err == nil {
+				markDone(sms)
+			}
+		}
+		if err == nil && t.cfg.Voice && !done[call] {
+			twiml := fmt.Sprintf("<Response><Say>%s</Say></Response>", html.EscapeString(text))
+			if err = t.request(ctx, "Calls.json", url.Values{"To": {to}, "From": {t.cfg.From}, "Twiml": {twiml}}); err == nil {
+				markDone(call)
+			}
+		}
+		if err != nil {
+			lastErr = err
+			failed++
+		}
+	}
+	if lastErr != nil {
+		return fmt.Errorf("failed to notify %d of %d phone numbers: %w", failed, len(recipients), lastErr)
+	}
+	return nil
+}
+
+func (t *Twilio) request(ctx context.Context, resource string, form url.Values) error {
+	u := t.apiUrl + url.PathEscape(t.cfg.AccountSid) + "/" + resource
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.cfg.AccountSid, t.cfg.AuthToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
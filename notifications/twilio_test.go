@@ -0,0 +1,52 @@
+package notifications
+
+import (
+	"context"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTwilioSendIncident(t *testing.T) {
+	var sent []string
+	failing := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		req := r.URL.Path + " " + r.PostForm.Get("To")
+		sent = append(sent, req)
+		if failing[req] {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewTwilio(db.IntegrationTwilio{AccountSid: "AC1", From: "+15550000000", To: "+1 (555) 000-0001; +15550000002", Voice: true, RateLimit: 10})
+	c.apiUrl = srv.URL + "/"
+	n := &db.IncidentNotification{ApplicationId: model.NewApplicationId("default", model.ApplicationKindDeployment, "cart"), Status: model.CRITICAL}
+
+	failing["/AC1/Calls.json +15550000002"] = true
+	err := c.SendIncident(context.Background(), "", n)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to notify 1 of 2 phone numbers")
+	assert.Equal(t, []string{
+		"/AC1/Messages.json +15550000001", "/AC1/Calls.json +15550000001",
+		"/AC1/Messages.json +15550000002", "/AC1/Calls.json +15550000002",
+	}, sent)
+
+	sent = nil
+	failing = map[string]bool{}
+	require.NoError(t, c.SendIncident(context.Background(), "", n))
+	assert.Equal(t, []string{"/AC1/Calls.json +15550000002"}, sent, "only the failed call must be retried")
+
+	sent = nil
+	require.NoError(t, c.SendIncident(context.Background(), "", n))
+	assert.Empty(t, sent)
+
+	sent = nil
+	require.NoError(t, c.SendIncident(context.Background(), "", &db.IncidentNotification{ApplicationId: n.ApplicationId, Status: model.OK}))
+	assert.Empty(t, sent, "only critical incidents are sent")
+}
@@ -0,0 +1,461 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"io"
+	"k8s.io/klog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// WebhookNotifier fires the webhooks of the projects on the transitions of the application and check statuses.
+// The statuses observed for the first time after the start are considered the baseline and don't fire webhooks.
+// The events are queued in the notification delivery log and sent in the background, so the failed ones
+// are retried even after a restart. The events of webhooks with grouping enabled are only queued once their group
+// is flushed: until then they are kept in memory, so the groups pending at a restart are lost.
+type WebhookNotifier struct {
+	db         *db.DB
+	lock       sync.Mutex
+	statuses   map[db.ProjectId]map[string]model.Status
+	groups     map[db.ProjectId]map[string]map[string]*webhookGroup
+	delivering map[db.ProjectId]bool
+	seq        uint64
+}
+
+// webhookGroup accumulates the transitions of a webhook with grouping enabled until flushAt.
+// Only the latest transition of each application and check is kept.
+type webhookGroup struct {
+	name    string
+	appId   string
+	status  string
+	flushAt timeseries.Time
+	keys    []string
+	events  map[string]*model.WebhookEvent
+}
+
+func NewWebhookNotifier(database *db.DB) *WebhookNotifier {
+	return &WebhookNotifier{
+		db:         database,
+		statuses:   map[db.ProjectId]map[string]model.Status{},
+		groups:     map[db.ProjectId]map[string]map[string]*webhookGroup{},
+		delivering: map[db.ProjectId]bool{},
+	}
+}
+
+// Notify compares the statuses of the audited world with the previous ones and sends the transitions to the webhooks.
+func (n *WebhookNotifier) Notify(project *db.Project, world *model.World, now timeseries.Time) {
+	n.lock.Lock()
+	prev := n.statuses[project.Id]
+	curr := map[string]model.Status{}
+	n.statuses[project.Id] = curr
+	n.lock.Unlock()
+
+	var events []*model.WebhookEvent
+	transition := func(key string, status model.Status) (model.Status, bool) {
+		if status == model.UNKNOWN {
+			if s, ok := prev[key]; ok {
+				curr[key] = s
+			}
+			return 0, false
+		}
+		curr[key] = status
+		p, ok := prev[key]
+		return p, ok && p != status
+	}
+	for _, app := range world.Applications {
+		var failing []*model.WebhookCheck
+		for _, r := range app.Reports {
+			for _, ch := range r.Checks {
+				status := ch.Status
+				if ch.Silenced() && status > model.OK {
+					status = model.OK
+				}
+				c := &model.WebhookCheck{
+					Id:      string(ch.Id),
+					Title:   ch.Title,
+					Report:  string(r.Name),
+					Status:  status.String(),
+					Message: ch.Message,
+					Items:   ch.Items(),
+				}
+				if status >= model.WARNING {
+					failing = append(failing, c)
+				}
+				if p, ok := transition(app.Id.String()+"/"+string(ch.Id), status); ok {
+					events = append(events, webhookEvent(project, app, p, status, now, c, nil))
+				}
+			}
+		}
+		if p, ok := transition(app.Id.String(), app.Status); ok {
+			events = append(events, webhookEvent(project, app, p, app.Status, now, nil, failing))
+		}
+	}
+	n.lock.Lock()
+	groups := map[string]map[string]*webhookGroup{}
+	for _, wh := range project.Settings.Webhooks {
+		var matched []*model.WebhookEvent
+		for _, e := range events {
+			if (e.Check != nil && wh.Checks) || (e.Check == nil && wh.Applications) {
+				matched = append(matched, e)
+			}
+		}
+		if wh.GroupBy != model.WebhookGroupByNone {
+			groups[wh.Id] = n.groups[project.Id][wh.Id]
+			if groups[wh.Id] == nil {
+				groups[wh.Id] = map[string]*webhookGroup{}
+			}
+			matched = groupWebhookEvents(project, wh, groups[wh.Id], matched, now)
+		}
+		for _, e := range matched {
+			n.seq++
+			queueWebhookEvent(n.db, project.Id, wh, e, fmt.Sprintf("%010d-%09d", now, n.seq), now)
+		}
+	}
+	n.groups[project.Id] = groups
+	if n.delivering[project.Id] {
+		n.lock.Unlock()
+		return
+	}
+	n.delivering[project.Id] = true
+	n.lock.Unlock()
+
+	go func() {
+		sendPendingWebhooks(n.db, project, now)
+		n.lock.Lock()
+		delete(n.delivering, project.Id)
+		n.lock.Unlock()
+	}()
+}
+
+// groupWebhookEvents adds the events to the groups of the webhook and returns the consolidated events of the groups that are due.
+func groupWebhookEvents(project *db.Project, wh model.Webhook, groups map[string]*webhookGroup, events []*model.WebhookEvent, now timeseries.Time) []*model.WebhookEvent {
+	for _, e := range events {
+		appId, _ := model.NewApplicationIdFromString(e.ApplicationId)
+		var key, name string
+		check := ""
+		if e.Check != nil {
+			check = e.Check.Id
+		}
+		switch wh.GroupBy {
+		case model.WebhookGroupByApplication:
+			key = e.ApplicationId + "|" + e.Status
+			name = "application=" + e.ApplicationId
+		case model.WebhookGroupByNamespace:
+			key = appId.Namespace + "|" + check + "|" + e.Status
+			name = "namespace=" + appId.Namespace
+			if check != "" {
+				name += ", check=" + check
+			}
+		}
+		g := groups[key]
+		if g == nil {
+			g = &webhookGroup{name: name, status: e.Status, flushAt: now.Add(timeseries.Duration(wh.GroupWait) * timeseries.Minute), events: map[string]*model.WebhookEvent{}}
+			if wh.GroupBy == model.WebhookGroupByApplication {
+				g.appId = e.ApplicationId
+			}
+			groups[key] = g
+		}
+		eventKey := e.ApplicationId + "/" + check
+		if g.events[eventKey] == nil {
+			g.keys = append(g.keys, eventKey)
+		}
+		g.events[eventKey] = e
+	}
+
+	var due []string
+	for key, g := range groups {
+		if !g.flushAt.After(now) {
+			due = append(due, key)
+		}
+	}
+	sort.Strings(due)
+	var res []*model.WebhookEvent
+	for _, key := range due {
+		res = append(res, groups[key].event(project, wh.GroupBy, now))
+		delete(groups, key)
+	}
+	return res
+}
+
+func (g *webhookGroup) event(project *db.Project, groupBy model.WebhookGroupBy, now timeseries.Time) *model.WebhookEvent {
+	if len(g.keys) == 1 {
+		return g.events[g.keys[0]]
+	}
+	e := &model.WebhookEvent{
+		ProjectId:     string(project.Id),
+		ProjectName:   project.Name,
+		ApplicationId: g.appId,
+		Status:        g.status,
+		Timestamp:     int64(now),
+		Group:         g.name,
+	}
+	if baseUrl := project.Settings.Integrations.BaseUrl; baseUrl != "" && g.appId != "" {
+		e.Url = fmt.Sprintf("%s/p/%s/app/%s", baseUrl, project.Id, g.appId)
+	}
+	seen := map[string]bool{}
+	addItem := func(item string) {
+		if !seen[item] {
+			seen[item] = true
+			e.Items = append(e.Items, item)
+		}
+	}
+	for _, k := range g.keys {
+		ev := g.events[k]
+		e.Events = append(e.Events, ev)
+		var items []string
+		if ev.Check != nil {
+			items = ev.Check.Items
+		}
+		if groupBy == model.WebhookGroupByApplication {
+			for _, item := range items {
+				addItem(item)
+			}
+			continue
+		}
+		appId, _ := model.NewApplicationIdFromString(ev.ApplicationId)
+		if len(items) == 0 {
+			addItem(appId.Name)
+		}
+		for _, item := range items {
+			addItem(appId.Name + ": " + item)
+		}
+	}
+	return e
+}
+
+func webhookEvent(project *db.Project, app *model.Application, prev, status model.Status, now timeseries.Time, check *model.WebhookCheck, failing []*model.WebhookCheck) *model.WebhookEvent {
+	e := &model.WebhookEvent{
+		ProjectId:      string(project.Id),
+		ProjectName:    project.Name,
+		ApplicationId:  app.Id.String(),
+		Check:          check,
+		PreviousStatus: prev.String(),
+		Status:         status.String(),
+		Timestamp:      int64(now),
+		FailingChecks:  failing,
+	}
+	if baseUrl := project.Settings.Integrations.BaseUrl; baseUrl != "" {
+		e.Url = fmt.Sprintf("%s/p/%s/app/%s", baseUrl, project.Id, app.Id.String())
+		if check != nil {
+			e.Url += "/" + check.Report
+		}
+	}
+	return e
+}
+
+// SendRollbackRecommendation notifies the webhooks subscribed to deployment events that the deployment should be rolled back.
+// It returns false if there are no such webhooks.
+func SendRollbackRecommendation(ctx context.Context, database *db.DB, project *db.Project, ds model.ApplicationDeploymentStatus) (bool, error) {
+	d := ds.Deployment
+	verdict, reasons := ds.Verdict()
+	e := &model.WebhookEvent{
+		ProjectId:     string(project.Id),
+		ProjectName:   project.Name,
+		ApplicationId: d.ApplicationId.String(),
+		Status:        ds.Status.String(),
+		Timestamp:     int64(timeseries.Now()),
+		Deployment: &model.WebhookDeployment{
+			Name:      d.Name,
+			Version:   d.Version(),
+			StartedAt: int64(d.StartedAt),
+			Verdict:   verdict,
+			Reasons:   reasons,
+		},
+	}
+	if baseUrl := project.Settings.Integrations.BaseUrl; baseUrl != "" {
+		e.Url = deploymentUrl(baseUrl, project.Id, d)
+	}
+	sent := false
+	for _, wh := range project.Settings.Webhooks {
+		if !wh.Deployments {
+			continue
+		}
+		started := time.Now()
+		err := SendWebhook(ctx, wh, e)
+		LogDelivery(database, webhookDelivery(project.Id, wh, e, 1), started, err)
+		if err != nil {
+			return sent, fmt.Errorf("failed to send webhook %s: %w", wh.Name, err)
+		}
+		sent = true
+	}
+	return sent, nil
+}
+
+// queueWebhookEvent saves the event to the delivery log as the attempt 0, the event is sent by sendPendingWebhooks.
+func queueWebhookEvent(database *db.DB, projectId db.ProjectId, wh model.Webhook, e *model.WebhookEvent, eventId string, now timeseries.Time) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		klog.Errorln("failed to marshal webhook event:", err)
+		return
+	}
+	d := webhookDelivery(projectId, wh, e, 0)
+	d.Timestamp = now
+	d.NextAttemptAt = now
+	d.EventId = eventId
+	d.Payload = string(payload)
+	database.LogNotificationDelivery(d)
+}
+
+// sendPendingWebhooks sends the queued events and retries the failed ones that are due. The events of each webhook are
+// sent in order: the following events are held until the failed one is delivered or dropped after webhookAttempts attempts.
+// The events of the deleted webhooks and the ones queued more than retryWindow ago are dropped.
+func sendPendingWebhooks(database *db.DB, project *db.Project, now timeseries.Time) {
+	pending, err := database.GetPendingWebhookDeliveries(project.Id)
+	if err != nil {
+		klog.Errorln("failed to get pending webhooks:", err)
+		return
+	}
+	webhooks := map[string]model.Webhook{}
+	for _, wh := range project.Settings.Webhooks {
+		webhooks[wh.Id] = wh
+	}
+	blocked := map[string]bool{}
+	for _, d := range pending {
+		if blocked[d.WebhookId] {
+			continue
+		}
+		wh, ok := webhooks[d.WebhookId]
+		expired := d.Timestamp.Before(now.Add(-retryWindow))
+		if ok && !expired && d.NextAttemptAt.After(now) {
+			blocked[d.WebhookId] = true
+			continue
+		}
+		if claimed, err := database.ClaimWebhookDelivery(project.Id, d.EventId); err != nil || !claimed {
+			if err != nil {
+				klog.Errorln("failed to claim webhook delivery:", err)
+			}
+			blocked[d.WebhookId] = true
+			continue
+		}
+		if !ok || expired {
+			klog.Warningf("dropping the webhook event %s of %s: the webhook has been deleted or the event is too old", d.EventId, d.Destination)
+			continue
+		}
+		var e model.WebhookEvent
+		if err = json.Unmarshal([]byte(d.Payload), &e); err != nil {
+			klog.Errorln("failed to unmarshal webhook event:", err)
+			continue
+		}
+		started := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err = SendWebhook(ctx, wh, &e)
+		cancel()
+		next := webhookDelivery(project.Id, wh, &e, d.Attempt+1)
+		if err != nil {
+			klog.Errorf("failed to send webhook %s (attempt %d): %s", wh.Name, next.Attempt, err)
+			blocked[wh.Id] = true
+			if next.Attempt < webhookAttempts {
+				next.NextAttemptAt = now.Add(retryBackoff(next.Attempt))
+				next.EventId = d.EventId
+				next.Payload = d.Payload
+			}
+		}
+		LogDelivery(database, next, started, err)
+	}
+}
+
+func webhookDelivery(projectId db.ProjectId, wh model.Webhook, e *model.WebhookEvent, attempt int) db.NotificationDelivery {
+	subject := e.Status
+	switch {
+	case e.Deployment != nil:
+		subject = e.Deployment.Version + ": " + string(e.Deployment.Verdict)
+	case e.Check != nil:
+		subject = e.Check.Id + ": " + e.Status
+	case e.Group != "":
+		subject = e.Group + ": " + e.Status
+	}
+	return db.NotificationDelivery{
+		ProjectId:     projectId,
+		Kind:          db.NotificationKindWebhook,
+		Destination:   wh.Name,
+		ApplicationId: e.ApplicationId,
+		Subject:       subject,
+		Attempt:       attempt,
+		WebhookId:     wh.Id,
+	}
+}
+
+// templateFuncs are available to the webhook and notification templates besides the builtin functions:
+// json (encodes a value as JSON, e.g., to escape a string), join, upper, and lower.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseWebhookTemplate parses the payload template of a webhook.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+func webhookPayload(wh model.Webhook, event *model.WebhookEvent) ([]byte, error) {
+	if strings.TrimSpace(wh.Template) == "" {
+		return json.Marshal(event)
+	}
+	tmpl, err := ParseWebhookTemplate(wh.Template)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(nil)
+	if err = tmpl.Execute(buf, event); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// webhookContentType returns the content type of the payload: the payloads rendered from a template
+// aren't necessarily JSON (e.g., form-encoded or plain text), so their type is detected.
+func webhookContentType(wh model.Webhook, body []byte) string {
+	if strings.TrimSpace(wh.Template) == "" || json.Valid(body) {
+		return "application/json"
+	}
+	return http.DetectContentType(body)
+}
+
+func SendWebhook(ctx context.Context, wh model.Webhook, event *model.WebhookEvent) error {
+	body, err := webhookPayload(wh, event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", webhookContentType(wh, body))
+	req.Header.Set("User-Agent", "Coroot")
+	for _, h := range wh.CustomHeaders { // a custom Content-Type header overrides the detected one
+		req.Header.Set(h.Key, h.Value)
+	}
+	if wh.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(wh.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Coroot-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
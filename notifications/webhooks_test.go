@@ -0,0 +1,147 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGroupWebhookEvents(t *testing.T) {
+	project := &db.Project{Id: "p", Name: "prod"}
+	wh := model.Webhook{Id: "wh", GroupBy: model.WebhookGroupByNamespace, GroupWait: 5}
+	groups := map[string]*webhookGroup{}
+	event := func(app, check string, items ...string) *model.WebhookEvent {
+		return &model.WebhookEvent{
+			ApplicationId: model.NewApplicationId("shop", model.ApplicationKindDeployment, app).String(),
+			Status:        "critical",
+			Check:         &model.WebhookCheck{Id: check, Status: "critical", Items: items},
+		}
+	}
+	now := timeseries.Time(1000)
+
+	res := groupWebhookEvents(project, wh, groups, []*model.WebhookEvent{event("cart", "CPUNode", "node-1"), event("orders", "CPUNode", "node-1", "node-2")}, now)
+	assert.Empty(t, res)
+
+	res = groupWebhookEvents(project, wh, groups, []*model.WebhookEvent{event("cart", "CPUNode", "node-3"), event("cart", "Memory")}, now.Add(timeseries.Minute))
+	assert.Empty(t, res)
+
+	res = groupWebhookEvents(project, wh, groups, nil, now.Add(5*timeseries.Minute))
+	assert.Len(t, res, 1)
+	assert.Equal(t, "namespace=shop, check=CPUNode", res[0].Group)
+	assert.Len(t, res[0].Events, 2)
+	assert.Equal(t, []string{"cart: node-3", "orders: node-1", "orders: node-2"}, res[0].Items)
+
+	res = groupWebhookEvents(project, wh, groups, nil, now.Add(6*timeseries.Minute))
+	assert.Len(t, res, 1)
+	assert.Equal(t, "Memory", res[0].Check.Id)
+	assert.Empty(t, groups)
+}
+
+func TestWebhookContentType(t *testing.T) {
+	event := &model.WebhookEvent{ApplicationId: "default:Deployment:cart", Status: "critical"}
+	for _, c := range []struct {
+		template    string
+		headers     []utils.Header
+		contentType string
+	}{
+		{contentType: "application/json"},
+		{template: `{"text": {{ json .ApplicationId }}}`, contentType: "application/json"},
+		{template: `{{ .ApplicationId }} is {{ .Status }}`, contentType: "text/plain; charset=utf-8"},
+		{template: `<?xml version="1.0"?><event app="{{ .ApplicationId }}"/>`, contentType: "text/xml; charset=utf-8"},
+		{
+			template:    `app={{ .ApplicationId }}&status={{ .Status }}`,
+			headers:     []utils.Header{{Key: "Content-Type", Value: "application/x-www-form-urlencoded"}},
+			contentType: "application/x-www-form-urlencoded",
+		},
+	} {
+		var contentType string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+		}))
+		wh := model.Webhook{Url: srv.URL, Template: c.template, CustomHeaders: c.headers}
+		require.NoError(t, SendWebhook(context.Background(), wh, event))
+		srv.Close()
+		assert.Equal(t, c.contentType, contentType, c.template)
+	}
+}
+
+func TestSendPendingWebhooks(t *testing.T) {
+	database, err := db.Open(t.TempDir(), "")
+	require.NoError(t, err)
+	projectId, err := database.SaveProject(db.Project{Name: "test"})
+	require.NoError(t, err)
+
+	failing := true
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e model.WebhookEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+		received = append(received, e.ApplicationId)
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	wh := model.Webhook{Id: "wh", Name: "test", Url: srv.URL}
+	project := &db.Project{Id: projectId, Settings: db.Settings{Webhooks: []model.Webhook{wh}}}
+	now := timeseries.Time(1700000000)
+	queue := func(eventId, appId string) {
+		queueWebhookEvent(database, projectId, wh, &model.WebhookEvent{ApplicationId: appId, Status: "critical"}, eventId, now)
+	}
+	pending := func() []int {
+		deliveries, err := database.GetPendingWebhookDeliveries(projectId)
+		require.NoError(t, err)
+		var res []int
+		for _, d := range deliveries {
+			res = append(res, d.Attempt)
+		}
+		return res
+	}
+
+	queue("1", "cart")
+	queue("2", "orders")
+	sendPendingWebhooks(database, project, now)
+	assert.Equal(t, []string{"cart"}, received, "the following events must be held until the failed one is delivered")
+	assert.Equal(t, []int{1, 0}, pending())
+
+	sendPendingWebhooks(database, project, now.Add(30))
+	assert.Len(t, received, 1, "the retry isn't due yet")
+
+	failing = false
+	sendPendingWebhooks(database, project, now.Add(retryBackoff(1)))
+	assert.Equal(t, []string{"cart", "cart", "orders"}, received)
+	assert.Empty(t, pending())
+
+	failing = true
+	received = nil
+	queue("3", "cart")
+	for _, offset := range []timeseries.Duration{0, retryBackoff(1), retryBackoff(1) + retryBackoff(2)} {
+		sendPendingWebhooks(database, project, now.Add(offset))
+	}
+	assert.Equal(t, []string{"cart", "cart", "cart"}, received)
+	assert.Empty(t, pending(), "the event must be dropped after the last attempt")
+
+	deliveries, err := database.GetNotificationDeliveries(projectId, 0, true, 100)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 4)
+
+	received = nil
+	queue("4", "cart")
+	sendPendingWebhooks(database, &db.Project{Id: projectId}, now)
+	assert.Empty(t, received)
+	assert.Empty(t, pending(), "the events of the deleted webhooks must be dropped")
+
+	queue("5", "cart")
+	sendPendingWebhooks(database, project, now.Add(retryWindow+1))
+	assert.Empty(t, received)
+	assert.Empty(t, pending(), "the outdated events must be dropped")
+}
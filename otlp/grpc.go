@@ -0,0 +1,96 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type GrpcCode int
+
+const (
+	GrpcOK                GrpcCode = 0
+	GrpcInvalidArgument   GrpcCode = 3
+	GrpcNotFound          GrpcCode = 5
+	GrpcResourceExhausted GrpcCode = 8
+	GrpcInternal          GrpcCode = 13
+	GrpcUnavailable       GrpcCode = 14
+	GrpcUnauthenticated   GrpcCode = 16
+)
+
+var ErrTooLarge = errors.New("the request is too large")
+
+// ReadGrpcRequest reads the message of a unary gRPC call: a single length-prefixed message, optionally gzip-compressed.
+func ReadGrpcRequest(r *http.Request, maxSize int) ([]byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r.Body, header); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if int64(size) > int64(maxSize) {
+		return nil, ErrTooLarge
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.Body, data); err != nil {
+		return nil, err
+	}
+	if header[0] == 0 {
+		return data, nil
+	}
+	if enc := r.Header.Get("Grpc-Encoding"); enc != "gzip" {
+		return nil, fmt.Errorf("unsupported grpc-encoding: %s", enc)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	data, err = io.ReadAll(io.LimitReader(gz, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSize {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+// WriteGrpcResponse responds to a unary gRPC call. On success, the response message is empty,
+// which means all the data points were accepted. The status is sent in the trailers.
+func WriteGrpcResponse(w http.ResponseWriter, code GrpcCode, message string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+	if code == GrpcOK {
+		_, _ = w.Write([]byte{0, 0, 0, 0, 0})
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(int(code)))
+	if message != "" {
+		w.Header().Set("Grpc-Message", url.PathEscape(message))
+	}
+}
+
+// GrpcCodeFromHttpStatus maps the status of the OTLP/HTTP response to the gRPC code with the same meaning.
+func GrpcCodeFromHttpStatus(status int) GrpcCode {
+	switch status {
+	case http.StatusOK:
+		return GrpcOK
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+		return GrpcInvalidArgument
+	case http.StatusUnauthorized:
+		return GrpcUnauthenticated
+	case http.StatusNotFound:
+		return GrpcNotFound
+	case http.StatusRequestEntityTooLarge:
+		return GrpcResourceExhausted
+	case http.StatusServiceUnavailable:
+		return GrpcUnavailable
+	}
+	return GrpcInternal
+}
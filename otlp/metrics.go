@@ -0,0 +1,374 @@
+package otlp
+
+import (
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	LabelApplicationId       = "application_id"
+	LabelApplicationInstance = "application_instance"
+
+	temporalityDelta    = 1
+	flagNoRecordedValue = 1
+)
+
+// DecodeMetrics decodes an OTLP ExportMetricsServiceRequest and converts the metrics to Prometheus series
+// the way the Prometheus OTLP receiver does: monotonic sums become counters with the _total suffix,
+// histograms and summaries are split into the _bucket/quantile, _count, and _sum series.
+// Delta temporality and exponential histograms can't be represented, so such metrics are skipped.
+// The series are labeled with the job and instance derived from the resource attributes,
+// as well as with the id and the instance of the Coroot application (see ApplicationId).
+func DecodeMetrics(data []byte) ([]prompb.TimeSeries, error) {
+	c := &metricsConverter{}
+	err := ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		if num == 1 && typ == protowire.BytesType {
+			return c.resourceMetrics(b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.series, nil
+}
+
+type metricsConverter struct {
+	series []prompb.TimeSeries
+}
+
+func (c *metricsConverter) resourceMetrics(data []byte) error {
+	resource := map[string]string{}
+	var scopeMetrics [][]byte
+	err := ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			return DecodeAttributes(b, 1, resource)
+		case 2:
+			scopeMetrics = append(scopeMetrics, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	common := map[string]string{}
+	if name := resource["service.name"]; name != "" {
+		common["job"] = name
+		if ns := resource["service.namespace"]; ns != "" {
+			common["job"] = ns + "/" + name
+		}
+	}
+	if id := resource["service.instance.id"]; id != "" {
+		common["instance"] = id
+	}
+	appId, instance := ApplicationId(resource)
+	if !appId.IsZero() {
+		common[LabelApplicationId] = appId.String()
+	}
+	if instance != "" {
+		common[LabelApplicationInstance] = instance
+	}
+
+	first := len(c.series)
+	for _, sm := range scopeMetrics {
+		err = ForEachField(sm, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+			if num == 2 && typ == protowire.BytesType {
+				return c.metric(b, common)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// the resource attributes are exposed as the labels of target_info, so they can be joined with the metrics
+	var latest int64
+	for _, s := range c.series[first:] {
+		for _, sample := range s.Samples {
+			if sample.Timestamp > latest {
+				latest = sample.Timestamp
+			}
+		}
+	}
+	if len(resource) > 0 && latest > 0 {
+		labels := map[string]string{}
+		for k, v := range resource {
+			labels[k] = v
+		}
+		c.add("target_info", common, labels, latest, 1)
+	}
+	return nil
+}
+
+func (c *metricsConverter) metric(data []byte, common map[string]string) error {
+	var name string
+	var typ protowire.Number
+	var body []byte
+	err := ForEachField(data, func(num protowire.Number, t protowire.Type, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			name = string(b)
+		case 5, 7, 9, 11:
+			typ, body = num, b
+		}
+		return nil
+	})
+	if err != nil || body == nil {
+		return err
+	}
+	name = sanitizeName(name)
+
+	var points [][]byte
+	monotonic := false
+	temporality := uint64(0)
+	err = ForEachField(body, func(num protowire.Number, t protowire.Type, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			points = append(points, b)
+		case 2:
+			temporality = v
+		case 3:
+			monotonic = v != 0
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if typ == 11 { // summaries have no temporality, field 2 isn't used
+		temporality = 0
+	}
+	if temporality == temporalityDelta {
+		return nil
+	}
+
+	if typ == 7 && monotonic && !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+	for _, p := range points {
+		switch typ {
+		case 5, 7:
+			err = c.numberPoint(p, name, common)
+		case 9:
+			err = c.histogramPoint(p, name, common)
+		case 11:
+			err = c.summaryPoint(p, name, common)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *metricsConverter) numberPoint(data []byte, name string, common map[string]string) error {
+	attrs := map[string]string{}
+	var ts int64
+	var v float64
+	var flags uint64
+	err := ForEachField(data, func(num protowire.Number, typ protowire.Type, val uint64, b []byte) error {
+		switch num {
+		case 3:
+			ts = nanosToMillis(val)
+		case 4:
+			v = math.Float64frombits(val)
+		case 6:
+			v = float64(int64(val))
+		case 7:
+			return DecodeKeyValue(b, attrs)
+		case 8:
+			flags = val
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.add(name, common, attrs, ts, sampleValue(v, flags))
+	return nil
+}
+
+func (c *metricsConverter) histogramPoint(data []byte, name string, common map[string]string) error {
+	attrs := map[string]string{}
+	var ts int64
+	var count uint64
+	var sum float64
+	hasSum := false
+	var counts []uint64
+	var bounds []float64
+	var flags uint64
+	err := ForEachField(data, func(num protowire.Number, typ protowire.Type, val uint64, b []byte) error {
+		switch num {
+		case 3:
+			ts = nanosToMillis(val)
+		case 4:
+			count = val
+		case 5:
+			sum, hasSum = math.Float64frombits(val), true
+		case 6:
+			return forEachFixed64(typ, val, b, func(v uint64) { counts = append(counts, v) })
+		case 7:
+			return forEachFixed64(typ, val, b, func(v uint64) { bounds = append(bounds, math.Float64frombits(v)) })
+		case 9:
+			return DecodeKeyValue(b, attrs)
+		case 10:
+			flags = val
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var cumulative uint64
+	for i, bound := range bounds {
+		if i < len(counts) {
+			cumulative += counts[i]
+		}
+		c.addWithLabel(name+"_bucket", common, attrs, "le", strconv.FormatFloat(bound, 'f', -1, 64), ts, sampleValue(float64(cumulative), flags))
+	}
+	c.addWithLabel(name+"_bucket", common, attrs, "le", "+Inf", ts, sampleValue(float64(count), flags))
+	c.add(name+"_count", common, attrs, ts, sampleValue(float64(count), flags))
+	if hasSum {
+		c.add(name+"_sum", common, attrs, ts, sampleValue(sum, flags))
+	}
+	return nil
+}
+
+func (c *metricsConverter) summaryPoint(data []byte, name string, common map[string]string) error {
+	attrs := map[string]string{}
+	var ts int64
+	var count uint64
+	var sum float64
+	type quantile struct{ q, v float64 }
+	var quantiles []quantile
+	var flags uint64
+	err := ForEachField(data, func(num protowire.Number, typ protowire.Type, val uint64, b []byte) error {
+		switch num {
+		case 3:
+			ts = nanosToMillis(val)
+		case 4:
+			count = val
+		case 5:
+			sum = math.Float64frombits(val)
+		case 6:
+			var q quantile
+			err := ForEachField(b, func(num protowire.Number, typ protowire.Type, val uint64, b []byte) error {
+				switch num {
+				case 1:
+					q.q = math.Float64frombits(val)
+				case 2:
+					q.v = math.Float64frombits(val)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			quantiles = append(quantiles, q)
+		case 7:
+			return DecodeKeyValue(b, attrs)
+		case 8:
+			flags = val
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, q := range quantiles {
+		c.addWithLabel(name, common, attrs, "quantile", strconv.FormatFloat(q.q, 'f', -1, 64), ts, sampleValue(q.v, flags))
+	}
+	c.add(name+"_count", common, attrs, ts, sampleValue(float64(count), flags))
+	c.add(name+"_sum", common, attrs, ts, sampleValue(sum, flags))
+	return nil
+}
+
+func (c *metricsConverter) add(name string, common, attrs map[string]string, ts int64, v float64) {
+	c.addWithLabel(name, common, attrs, "", "", ts, v)
+}
+
+func (c *metricsConverter) addWithLabel(name string, common, attrs map[string]string, extraName, extraValue string, ts int64, v float64) {
+	labels := map[string]string{}
+	for k, v := range attrs {
+		if v != "" {
+			labels[sanitizeLabel(k)] = v
+		}
+	}
+	for k, v := range common { // the resource labels take precedence over the data point attributes
+		labels[k] = v
+	}
+	if extraName != "" {
+		labels[extraName] = extraValue
+	}
+	labels["__name__"] = name
+	s := prompb.TimeSeries{
+		Labels:  make([]prompb.Label, 0, len(labels)),
+		Samples: []prompb.Sample{{Timestamp: ts, Value: v}},
+	}
+	for k, v := range labels {
+		s.Labels = append(s.Labels, prompb.Label{Name: k, Value: v})
+	}
+	sort.Slice(s.Labels, func(i, j int) bool { return s.Labels[i].Name < s.Labels[j].Name })
+	c.series = append(c.series, s)
+}
+
+// forEachFixed64 iterates over a repeated fixed64/double field, which is usually packed.
+func forEachFixed64(typ protowire.Type, v uint64, b []byte, f func(uint64)) error {
+	if typ == protowire.Fixed64Type {
+		f(v)
+		return nil
+	}
+	for len(b) > 0 {
+		v, n := protowire.ConsumeFixed64(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		f(v)
+		b = b[n:]
+	}
+	return nil
+}
+
+func sampleValue(v float64, flags uint64) float64 {
+	if flags&flagNoRecordedValue != 0 {
+		return math.Float64frombits(value.StaleNaN)
+	}
+	return v
+}
+
+func nanosToMillis(ns uint64) int64 {
+	return int64(ns / 1e6)
+}
+
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func sanitizeLabel(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "key_" + name
+	}
+	return name
+}
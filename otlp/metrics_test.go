@@ -0,0 +1,121 @@
+package otlp
+
+import (
+	"github.com/coroot/coroot/utils/prototest"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+	"testing"
+	"time"
+)
+
+func keyValue(key, value string) []byte {
+	return prototest.Message(prototest.Bytes(1, []byte(key)), prototest.Bytes(2, prototest.Message(prototest.Bytes(1, []byte(value)))))
+}
+
+func attr(key, value string) prototest.Field {
+	return prototest.Bytes(1, keyValue(key, value))
+}
+
+func seriesMap(series []prompb.TimeSeries) map[string]prompb.TimeSeries {
+	res := map[string]prompb.TimeSeries{}
+	for _, s := range series {
+		key := ""
+		for _, l := range s.Labels {
+			switch l.Name {
+			case "__name__", "le", "quantile", "method":
+				key += l.Value + ";"
+			}
+		}
+		res[key] = s
+	}
+	return res
+}
+
+func TestDecodeMetrics(t *testing.T) {
+	ts := uint64(time.Date(2023, 2, 2, 10, 0, 0, 0, time.UTC).UnixNano())
+	tsMs := int64(ts / 1e6)
+
+	resource := prototest.Message(
+		attr("service.name", "api"),
+		attr("k8s.namespace.name", "shop"),
+		attr("k8s.deployment.name", "api"),
+		attr("k8s.pod.name", "api-7d5f-x2"),
+		attr("service.instance.id", "api-7d5f-x2"),
+	)
+	sum := prototest.Message(
+		prototest.Bytes(1, prototest.Message(prototest.Fixed64(3, ts), prototest.Bytes(7, keyValue("method", "GET")), prototest.Fixed64(6, 42))),
+		prototest.Varint(2, 2), // cumulative
+		prototest.Varint(3, 1), // monotonic
+	)
+	deltaSum := prototest.Message(
+		prototest.Bytes(1, prototest.Message(prototest.Fixed64(3, ts), prototest.Fixed64(6, 1))),
+		prototest.Varint(2, 1),
+	)
+	gauge := prototest.Message(
+		prototest.Bytes(1, prototest.Message(prototest.Fixed64(3, ts), prototest.Fixed64(4, math.Float64bits(0.5)), prototest.Varint(8, 1))),
+	)
+	histogram := prototest.Message(
+		prototest.Bytes(1, prototest.Message(
+			prototest.Fixed64(3, ts),
+			prototest.Fixed64(4, 5),
+			prototest.Fixed64(5, math.Float64bits(1.5)),
+			prototest.Bytes(6, protowire.AppendFixed64(protowire.AppendFixed64(protowire.AppendFixed64(nil, 2), 2), 1)),
+			prototest.Bytes(7, protowire.AppendFixed64(protowire.AppendFixed64(nil, math.Float64bits(0.1)), math.Float64bits(1))),
+		)),
+		prototest.Varint(2, 2),
+	)
+	summary := prototest.Message(
+		prototest.Bytes(1, prototest.Message(
+			prototest.Fixed64(3, ts),
+			prototest.Fixed64(4, 10),
+			prototest.Fixed64(5, math.Float64bits(3)),
+			prototest.Bytes(6, prototest.Message(prototest.Fixed64(1, math.Float64bits(0.99)), prototest.Fixed64(2, math.Float64bits(0.8)))),
+		)),
+	)
+	req := prototest.Message(prototest.Bytes(1, prototest.Message(
+		prototest.Bytes(1, resource),
+		prototest.Bytes(2, prototest.Message(
+			prototest.Bytes(2, prototest.Message(prototest.Bytes(1, []byte("http.requests")), prototest.Bytes(7, sum))),
+			prototest.Bytes(2, prototest.Message(prototest.Bytes(1, []byte("delta")), prototest.Bytes(7, deltaSum))),
+			prototest.Bytes(2, prototest.Message(prototest.Bytes(1, []byte("queue.usage")), prototest.Bytes(5, gauge))),
+			prototest.Bytes(2, prototest.Message(prototest.Bytes(1, []byte("latency")), prototest.Bytes(9, histogram))),
+			prototest.Bytes(2, prototest.Message(prototest.Bytes(1, []byte("gc")), prototest.Bytes(11, summary))),
+		)),
+	)))
+
+	series, err := DecodeMetrics(req)
+	require.NoError(t, err)
+	m := seriesMap(series)
+	assert.Len(t, m, 11) // the delta sum is skipped
+
+	s := m["http_requests_total;GET;"]
+	assert.Equal(t, []prompb.Sample{{Timestamp: tsMs, Value: 42}}, s.Samples)
+	assert.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "http_requests_total"},
+		{Name: LabelApplicationId, Value: "shop:Deployment:api"},
+		{Name: LabelApplicationInstance, Value: "api-7d5f-x2"},
+		{Name: "instance", Value: "api-7d5f-x2"},
+		{Name: "job", Value: "api"},
+		{Name: "method", Value: "GET"},
+	}, s.Labels)
+
+	assert.True(t, value.IsStaleNaN(m["queue_usage;"].Samples[0].Value))
+
+	assert.Equal(t, float64(2), m["latency_bucket;0.1;"].Samples[0].Value)
+	assert.Equal(t, float64(4), m["latency_bucket;1;"].Samples[0].Value)
+	assert.Equal(t, float64(5), m["latency_bucket;+Inf;"].Samples[0].Value)
+	assert.Equal(t, float64(5), m["latency_count;"].Samples[0].Value)
+	assert.Equal(t, 1.5, m["latency_sum;"].Samples[0].Value)
+
+	assert.Equal(t, 0.8, m["gc;0.99;"].Samples[0].Value)
+	assert.Equal(t, float64(10), m["gc_count;"].Samples[0].Value)
+	assert.Equal(t, float64(3), m["gc_sum;"].Samples[0].Value)
+
+	info := m["target_info;"]
+	assert.Equal(t, tsMs, info.Samples[0].Timestamp)
+	assert.Contains(t, info.Labels, prompb.Label{Name: "k8s_deployment_name", Value: "api"})
+}
@@ -0,0 +1,46 @@
+package otlp
+
+import (
+	"github.com/coroot/coroot/model"
+	"strings"
+)
+
+var workloadAttributes = []struct {
+	attr string
+	kind model.ApplicationKind
+}{
+	{attr: "k8s.deployment.name", kind: model.ApplicationKindDeployment},
+	{attr: "k8s.statefulset.name", kind: model.ApplicationKindStatefulSet},
+	{attr: "k8s.daemonset.name", kind: model.ApplicationKindDaemonSet},
+	{attr: "k8s.cronjob.name", kind: model.ApplicationKindCronJob},
+	{attr: "k8s.job.name", kind: model.ApplicationKindJob},
+}
+
+// ApplicationId maps the resource attributes to the Coroot application and the instance the telemetry belongs to.
+// Kubernetes workloads are identified the same way as the ones discovered by the node agent,
+// other services are identified by service.name as plain containers are.
+func ApplicationId(resource map[string]string) (model.ApplicationId, string) {
+	ns := resource["k8s.namespace.name"]
+	instance := resource["k8s.pod.name"]
+	if ns != "" {
+		for _, w := range workloadAttributes {
+			if name := resource[w.attr]; name != "" {
+				return model.NewApplicationId(ns, w.kind, name), instance
+			}
+		}
+	}
+	if instance == "" {
+		instance = resource["service.instance.id"]
+	}
+	if instance == "" {
+		instance = resource["host.name"]
+	}
+	name := resource["service.name"]
+	if name == "" || strings.HasPrefix(name, "unknown_service") { // the default name set by the SDKs
+		return model.ApplicationIdZero, instance
+	}
+	if ns == "" {
+		ns = resource["service.namespace"]
+	}
+	return model.NewApplicationId(ns, model.ApplicationKindUnknown, name), instance
+}
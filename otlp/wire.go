@@ -0,0 +1,158 @@
+// Package otlp decodes the OpenTelemetry protocol messages.
+// The messages are parsed field by field with protowire (see the field numbers in opentelemetry-proto),
+// so only the fields Coroot needs are decoded and no generated code is required.
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+	"strconv"
+)
+
+// DecodeAttributes decodes the repeated KeyValue field of a message.
+func DecodeAttributes(data []byte, field protowire.Number, dst map[string]string) error {
+	return ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		if num == field && typ == protowire.BytesType {
+			return DecodeKeyValue(b, dst)
+		}
+		return nil
+	})
+}
+
+// DecodeKeyValue decodes a KeyValue message, the values other than strings are formatted the same way the OpenTelemetry Collector does.
+func DecodeKeyValue(data []byte, dst map[string]string) error {
+	var key string
+	var value any
+	err := ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			key = string(b)
+		case 2:
+			var err error
+			value, err = decodeAnyValue(b)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return nil
+	}
+	dst[key], err = formatValue(value)
+	return err
+}
+
+// DecodeValue decodes an AnyValue message (e.g., the body of a log record) formatting it the same way as the attribute values.
+func DecodeValue(data []byte) (string, error) {
+	value, err := decodeAnyValue(data)
+	if err != nil {
+		return "", err
+	}
+	return formatValue(value)
+}
+
+func formatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	default: // arrays and key-value lists are stored as JSON, the same way the OpenTelemetry Collector does
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+func decodeAnyValue(data []byte) (any, error) {
+	var value any
+	err := ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			value = string(b)
+		case 2:
+			value = v != 0
+		case 3:
+			value = int64(v)
+		case 4:
+			value = math.Float64frombits(v)
+		case 5:
+			values := []any{}
+			err := ForEachField(b, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+				if num != 1 {
+					return nil
+				}
+				item, err := decodeAnyValue(b)
+				if err != nil {
+					return err
+				}
+				values = append(values, item)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			value = values
+		case 6:
+			values := map[string]string{}
+			if err := DecodeAttributes(b, 1, values); err != nil {
+				return err
+			}
+			value = values
+		case 7:
+			value = b
+		}
+		return nil
+	})
+	return value, err
+}
+
+// ForEachField calls f for every field of the message.
+// v holds the value of a varint or a fixed-size field, b holds the content of a length-delimited one.
+func ForEachField(data []byte, f func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		var v uint64
+		var b []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(data)
+		case protowire.Fixed32Type:
+			var v32 uint32
+			v32, n = protowire.ConsumeFixed32(data)
+			v = uint64(v32)
+		case protowire.BytesType:
+			b, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := f(num, typ, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
-	"fmt"
 	"github.com/buger/jsonparser"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
@@ -18,6 +17,7 @@ import (
 	"k8s.io/klog"
 	"net"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -47,28 +47,67 @@ func init() {
 	}}
 }
 
+// ClientConfig configures a client of the Prometheus-compatible API. TenantId is sent as X-Scope-OrgID to multi-tenant
+// storages (Mimir, Cortex, Thanos). The series differing only in ReplicaLabels (e.g., those of HA Prometheus pairs) are deduplicated.
+// Range queries crossing the boundaries of SplitInterval are split into aligned intervals queried concurrently, which query frontends can cache.
+// Backend is one of the Backend* constants. ScrapeInterval is the interval the metrics are collected with,
+// the raw samples are exported from VictoriaMetrics only if the step is close to it.
+type ClientConfig struct {
+	Url            string
+	BasicAuth      *utils.BasicAuth
+	TlsSkipVerify  bool
+	ExtraSelector  string
+	CustomHeaders  []utils.Header
+	Backend        string
+	TenantId       string
+	ReplicaLabels  []string
+	SplitInterval  timeseries.Duration
+	ScrapeInterval timeseries.Duration
+}
+
 type ApiClient struct {
-	api           v1.API
-	apiClient     api.Client
-	httpClient    *http.Client
-	extraSelector string
-	customHeaders []utils.Header
+	api            v1.API
+	apiClient      api.Client
+	httpClient     *http.Client
+	address        string
+	extraSelector  string
+	backend        string
+	replicaLabels  []string
+	splitInterval  timeseries.Duration
+	scrapeInterval timeseries.Duration
 }
 
-func NewApiClient(address string, basicAuth *utils.BasicAuth, skipTlsVerify bool, extraSelector string, customHeaders []utils.Header) (*ApiClient, error) {
-	address, err := basicAuth.AddTo(address)
+func NewApiClient(cfg ClientConfig) (*ApiClient, error) {
+	address, err := cfg.BasicAuth.AddTo(cfg.Url)
 	if err != nil {
 		return nil, err
 	}
 	cl := secureClient
-	if skipTlsVerify {
+	if cfg.TlsSkipVerify {
 		cl = insecureClient
 	}
+	headers := cfg.CustomHeaders
+	if cfg.TenantId != "" {
+		headers = append(headers, utils.Header{Key: "X-Scope-OrgID", Value: cfg.TenantId})
+	}
+	if len(headers) > 0 {
+		cl = &http.Client{Transport: &headersTransport{base: cl.Transport, headers: headers}}
+	}
 	c, err := api.NewClient(api.Config{Address: address, Client: cl})
 	if err != nil {
 		return nil, err
 	}
-	return &ApiClient{api: v1.NewAPI(c), apiClient: c, httpClient: cl, extraSelector: extraSelector, customHeaders: customHeaders}, nil
+	return &ApiClient{
+		api:            v1.NewAPI(c),
+		apiClient:      c,
+		httpClient:     cl,
+		address:        address,
+		extraSelector:  cfg.ExtraSelector,
+		backend:        cfg.Backend,
+		replicaLabels:  cfg.ReplicaLabels,
+		splitInterval:  cfg.SplitInterval,
+		scrapeInterval: cfg.ScrapeInterval,
+	}, nil
 }
 
 func (c *ApiClient) Ping(ctx context.Context) error {
@@ -77,40 +116,89 @@ func (c *ApiClient) Ping(ctx context.Context) error {
 }
 
 func (c *ApiClient) QueryRange(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration) ([]model.MetricValues, error) {
-	query = strings.ReplaceAll(query, "$RANGE", fmt.Sprintf(`%.0fs`, (step*3).ToStandard().Seconds()))
+	from = from.Truncate(step)
+	to = to.Truncate(step)
+	var res []model.MetricValues
 	var err error
-	query, err = addExtraSelector(query, c.extraSelector)
+	if c.splitInterval > 0 && from.Truncate(c.splitInterval) != to.Truncate(c.splitInterval) {
+		res, err = c.queryRangeSplit(ctx, query, from, to, step)
+	} else {
+		res, err = c.queryRange(ctx, query, from, to, step)
+	}
 	if err != nil {
 		return nil, err
 	}
-	from = from.Truncate(step)
-	to = to.Truncate(step)
+	if len(c.replicaLabels) > 0 {
+		res = deduplicate(res, c.replicaLabels, from, to, step)
+	}
+	return res, nil
+}
+
+// queryRange splits the range in halves if the query exceeds the limit of the samples the storage can load
+// (-search.maxSamplesPerQuery of VictoriaMetrics, --query.max-samples of Prometheus).
+func (c *ApiClient) queryRange(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration) ([]model.MetricValues, error) {
+	res, err := c.queryRangeOnce(ctx, query, from, to, step)
+	if !errors.Is(err, errTooManySamples) || !to.After(from) {
+		return res, err
+	}
+	middle := from.Add(to.Sub(from) / 2).Truncate(step)
+	if middle.Before(from) {
+		middle = from
+	}
+	first, err := c.queryRange(ctx, query, from, middle, step)
+	if err != nil {
+		return nil, err
+	}
+	second, err := c.queryRange(ctx, query, middle.Add(step), to, step)
+	if err != nil {
+		return nil, err
+	}
+	return mergeSeries([][]model.MetricValues{first, second}, from, to, step, nil), nil
+}
 
-	u := c.apiClient.URL("/api/v1/query_range", nil)
-	q := u.Query()
+func (c *ApiClient) queryRangeOnce(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration) ([]model.MetricValues, error) {
+	query = ExpandRange(query, step)
 
+	q := url.Values{}
+	vm := c.isVictoriaMetrics()
+	if vm {
+		if selector := plainSelector(query); selector != "" && c.exportable(step) {
+			res, err := c.export(ctx, selector, from, to, step)
+			if !errors.Is(err, errExportNotSupported) {
+				return res, err
+			}
+			vm = c.isVictoriaMetrics()
+		}
+	}
+	if vm {
+		// the extra selector is applied by VictoriaMetrics, so the query may use MetricsQL functions
+		if c.extraSelector != "" {
+			q.Set("extra_filters[]", c.extraSelector)
+		}
+	} else {
+		var err error
+		if query, err = addExtraSelector(query, c.extraSelector); err != nil {
+			return nil, err
+		}
+	}
 	q.Set("query", query)
 	q.Set("start", from.String())
 	q.Set("end", to.String())
 	q.Set("step", strconv.FormatInt(int64(step), 10))
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(q.Encode()))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	for _, h := range c.customHeaders {
-		req.Header.Add(h.Key, h.Value)
+	if len(c.replicaLabels) > 0 { // Thanos deduplicates the series itself
+		q.Set("dedup", "true")
+		for _, l := range c.replicaLabels {
+			q.Add("replicaLabels[]", l)
+		}
 	}
-	req = req.WithContext(ctx)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.post(ctx, "/api/v1/query_range", q)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status)
+		return nil, responseError(resp)
 	}
 	buf := pool.Get().(*bytes.Buffer)
 	buf.Reset()
@@ -118,6 +206,9 @@ func (c *ApiClient) QueryRange(ctx context.Context, query string, from, to times
 	if _, err = buf.ReadFrom(resp.Body); err != nil {
 		return nil, err
 	}
+	if !vm && c.backend == BackendAuto {
+		detectVictoriaMetrics(c.address, buf.Bytes())
+	}
 
 	var res []model.MetricValues
 	f := func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
@@ -186,6 +277,15 @@ func (c *ApiClient) QueryRange(ctx context.Context, query string, from, to times
 	return res, nil
 }
 
+func (c *ApiClient) post(ctx context.Context, path string, form url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiClient.URL(path, nil).String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.httpClient.Do(req)
+}
+
 func (c *ApiClient) Proxy(r *http.Request, w http.ResponseWriter) {
 	reStr, err := mux.CurrentRoute(r).GetPathRegexp()
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -32,7 +33,7 @@ func TestQueryRange(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(h))
 	defer ts.Close()
 
-	client, err := NewApiClient(ts.URL, nil, true, "", nil)
+	client, err := NewApiClient(ClientConfig{Url: ts.URL, TlsSkipVerify: true, Backend: BackendPrometheus})
 	require.NoError(t, err)
 
 	ctx := context.Background()
@@ -70,3 +71,46 @@ func Test_addExtraSelector(t *testing.T) {
 		`{cluster="cluster1"}`,
 		`rate(node_resources_cpu_usage_seconds_total{cluster="cluster1",mode!="idle"}[30s]) / ignoring (mode) group_left () sum without (mode) (rate(node_resources_cpu_usage_seconds_total{cluster="cluster1"}[30s])) * 100`)
 }
+
+func TestQueryRangeSplitAndDedup(t *testing.T) {
+	from := timeseries.Time(3600 - 60)
+	to := timeseries.Time(3600 + 30)
+	step := timeseries.Duration(30)
+
+	var starts []string
+	var lock sync.Mutex
+	h := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tenant-1", r.Header.Get("X-Scope-OrgID"))
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "true", r.Form.Get("dedup"))
+		assert.Equal(t, []string{"replica"}, r.Form["replicaLabels[]"])
+		lock.Lock()
+		starts = append(starts, r.Form.Get("start"))
+		lock.Unlock()
+		var data string
+		switch r.Form.Get("start") {
+		case "3540":
+			data = `{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"job":"j","replica":"a"},"values":[[3540,"1"]]},
+				{"metric":{"job":"j","replica":"b"},"values":[[3570,"2"]]}]}}`
+		case "3600":
+			data = `{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"job":"j","replica":"b"},"values":[[3600,"3"],[3630,"4"]]}]}}`
+		}
+		w.Write([]byte(data))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(h))
+	defer ts.Close()
+
+	client, err := NewApiClient(ClientConfig{
+		Url: ts.URL, Backend: BackendPrometheus, TenantId: "tenant-1", ReplicaLabels: []string{"replica"}, SplitInterval: timeseries.Hour,
+	})
+	require.NoError(t, err)
+
+	res, err := client.QueryRange(context.Background(), `metric`, from, to, step)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"3540", "3600"}, starts)
+	require.Len(t, res, 1)
+	assert.Equal(t, model.Labels{"job": "j"}, res[0].Labels)
+	assert.Equal(t, "TimeSeries(3540, 4, 30, [1 2 3 4])", res[0].Values.String())
+}
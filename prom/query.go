@@ -0,0 +1,108 @@
+package prom
+
+import (
+	"context"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	promModel "github.com/prometheus/common/model"
+	"net/http"
+	"sync"
+)
+
+const splitConcurrency = 4
+
+// headersTransport adds the custom and the tenant headers to every request, including the proxied ones.
+type headersTransport struct {
+	base    http.RoundTripper
+	headers []utils.Header
+}
+
+func (t *headersTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	for _, h := range t.headers {
+		r.Header.Set(h.Key, h.Value)
+	}
+	return t.base.RoundTrip(r)
+}
+
+// queryRangeSplit queries the intervals aligned to the split interval concurrently,
+// so that a query frontend (Thanos, Mimir, Cortex) can serve the past ones from its results cache.
+func (c *ApiClient) queryRangeSplit(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration) ([]model.MetricValues, error) {
+	type interval struct{ from, to timeseries.Time }
+	var intervals []interval
+	for start := from; !start.After(to); {
+		boundary := start.Truncate(c.splitInterval).Add(c.splitInterval)
+		end := start.Add((boundary.Sub(start) + step - 1) / step * step).Add(-step)
+		if end.After(to) {
+			end = to
+		}
+		intervals = append(intervals, interval{from: start, to: end})
+		start = end.Add(step)
+	}
+
+	parts := make([][]model.MetricValues, len(intervals))
+	errs := make([]error, len(intervals))
+	sem := make(chan struct{}, splitConcurrency)
+	wg := sync.WaitGroup{}
+	for i, in := range intervals {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, in interval) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			parts[i], errs[i] = c.queryRange(ctx, query, in.from, in.to, step)
+		}(i, in)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mergeSeries(parts, from, to, step, nil), nil
+}
+
+// deduplicate merges the series that differ only in the replica labels, e.g., the ones collected by both Prometheus servers of an HA pair.
+// The gaps of a replica are filled with the values of the others.
+func deduplicate(series []model.MetricValues, replicaLabels []string, from, to timeseries.Time, step timeseries.Duration) []model.MetricValues {
+	return mergeSeries([][]model.MetricValues{series}, from, to, step, func(ls model.Labels) {
+		for _, l := range replicaLabels {
+			delete(ls, l)
+		}
+	})
+}
+
+func mergeSeries(parts [][]model.MetricValues, from, to timeseries.Time, step timeseries.Duration, relabel func(model.Labels)) []model.MetricValues {
+	byHash := map[uint64]*model.MetricValues{}
+	var res []*model.MetricValues
+	for _, part := range parts {
+		for _, s := range part {
+			labels, hash := s.Labels, s.LabelsHash
+			if relabel != nil {
+				relabel(labels)
+				hash = promModel.LabelsToSignature(labels)
+			}
+			mv := byHash[hash]
+			if mv == nil {
+				mv = &model.MetricValues{Labels: labels, LabelsHash: hash, Values: timeseries.New(from, int(to.Sub(from)/step)+1, step)}
+				byHash[hash] = mv
+				res = append(res, mv)
+			}
+			iter := s.Values.Iter()
+			for iter.Next() {
+				t, v := iter.Value()
+				if !timeseries.IsNaN(v) {
+					mv.Values.Set(t, v)
+				}
+			}
+		}
+	}
+	merged := make([]model.MetricValues, 0, len(res))
+	for _, mv := range res {
+		merged = append(merged, *mv)
+	}
+	return merged
+}
@@ -1,7 +1,11 @@
 package prom
 
 import (
+	"fmt"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql/parser"
+	"strings"
 )
 
 func IsSelectorValid(selector string) bool {
@@ -11,3 +15,32 @@ func IsSelectorValid(selector string) bool {
 	_, err := parser.ParseMetricSelector(selector)
 	return err == nil
 }
+
+func IsQueryValid(query string) bool {
+	_, err := parser.ParseExpr(strings.ReplaceAll(query, "$RANGE", "1m"))
+	return err == nil
+}
+
+// ExpandRange replaces the $RANGE placeholder with the window covering three steps.
+func ExpandRange(query string, step timeseries.Duration) string {
+	return strings.ReplaceAll(query, "$RANGE", fmt.Sprintf(`%.0fs`, (step*3).ToStandard().Seconds()))
+}
+
+// AddLabelMatcher adds the name="value" matcher to every selector of the query.
+func AddLabelMatcher(query, name, value string) (string, error) {
+	expr, err := parser.ParseExpr(query)
+	if err != nil {
+		return "", err
+	}
+	m, err := labels.NewMatcher(labels.MatchEqual, name, value)
+	if err != nil {
+		return "", err
+	}
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		if vs, ok := node.(*parser.VectorSelector); ok {
+			vs.LabelMatchers = append(vs.LabelMatchers, m)
+		}
+		return nil
+	})
+	return expr.String(), nil
+}
@@ -0,0 +1,275 @@
+package prom
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/buger/jsonparser"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	promModel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const (
+	BackendAuto            = ""
+	BackendPrometheus      = "prometheus"
+	BackendVictoriaMetrics = "victoriametrics"
+
+	exportLookbackMax = 5 * timeseries.Minute
+	// exportMaxStep is the max ratio of the step to the scrape interval the raw samples are exported with:
+	// with a larger step, most of the exported samples would be discarded by the alignment.
+	exportMaxStep = 2
+)
+
+var (
+	// errExportNotSupported means the address doesn't serve /api/v1/export, e.g., it was detected as VictoriaMetrics
+	// but the datasource has been switched since then.
+	errExportNotSupported = errors.New("export is not supported")
+	// errTooManySamples means the query exceeds the limit of the samples the storage can load.
+	errTooManySamples = errors.New("too many samples")
+)
+
+// victoriaMetrics holds the addresses detected as VictoriaMetrics by the responses of the query API.
+var victoriaMetrics sync.Map
+
+// ResetBackendDetection forgets the detected backends, e.g., once the Prometheus datasource of a project has been changed.
+func ResetBackendDetection() {
+	victoriaMetrics.Range(func(key, value any) bool {
+		victoriaMetrics.Delete(key)
+		return true
+	})
+}
+
+func IsBackendValid(backend string) bool {
+	switch backend {
+	case BackendAuto, BackendPrometheus, BackendVictoriaMetrics:
+		return true
+	}
+	return false
+}
+
+func (c *ApiClient) isVictoriaMetrics() bool {
+	switch c.backend {
+	case BackendVictoriaMetrics:
+		return true
+	case BackendPrometheus:
+		return false
+	}
+	_, ok := victoriaMetrics.Load(c.address)
+	return ok
+}
+
+// detectVictoriaMetrics looks for the fields VictoriaMetrics adds to the responses of the query API:
+// isPartial (vmselect) and stats (single-node).
+func detectVictoriaMetrics(address string, response []byte) {
+	for _, key := range []string{"isPartial", "stats"} {
+		if _, _, _, err := jsonparser.Get(response, key); err == nil {
+			victoriaMetrics.Store(address, true)
+			return
+		}
+	}
+}
+
+// exportable reports whether the step is close enough to the scrape interval to export the raw samples
+// instead of evaluating the selector.
+func (c *ApiClient) exportable(step timeseries.Duration) bool {
+	return c.scrapeInterval > 0 && step <= exportMaxStep*c.scrapeInterval
+}
+
+// responseError returns errTooManySamples if the storage rejected the query because of its samples limit.
+func responseError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnprocessableEntity || resp.StatusCode == http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if bytes.Contains(msg, []byte("maxSamplesPerQuery")) || bytes.Contains(msg, []byte("too many samples")) {
+			return fmt.Errorf("%w: %s", errTooManySamples, resp.Status)
+		}
+	}
+	return errors.New(resp.Status)
+}
+
+// plainSelector returns the query if it's a bare metric selector, which can be exported without evaluating it.
+func plainSelector(query string) string {
+	expr, err := parser.ParseExpr(query)
+	if err != nil {
+		return ""
+	}
+	vs, ok := expr.(*parser.VectorSelector)
+	if !ok || vs.OriginalOffset != 0 || vs.Timestamp != nil || vs.StartOrEnd != 0 {
+		return ""
+	}
+	return vs.String()
+}
+
+type exportedSeries struct {
+	labels     model.Labels
+	timestamps []int64
+	values     []float64
+}
+
+func (s *exportedSeries) Len() int { return len(s.timestamps) }
+func (s *exportedSeries) Less(i, j int) bool {
+	return s.timestamps[i] < s.timestamps[j]
+}
+func (s *exportedSeries) Swap(i, j int) {
+	s.timestamps[i], s.timestamps[j] = s.timestamps[j], s.timestamps[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+// export retrieves the raw samples of the series matching the selector through /api/v1/export of VictoriaMetrics,
+// bypassing the query engine, and aligns them to the step the same way an instant selector does:
+// a point gets the latest sample within the lookback window (twice the scrape interval, but not less than the step).
+func (c *ApiClient) export(ctx context.Context, selector string, from, to timeseries.Time, step timeseries.Duration) ([]model.MetricValues, error) {
+	q := url.Values{}
+	q.Set("match[]", selector)
+	if c.extraSelector != "" {
+		q.Set("extra_filters[]", c.extraSelector)
+	}
+	q.Set("start", from.Add(-exportLookbackMax).String())
+	q.Set("end", to.String())
+	resp, err := c.post(ctx, "/api/v1/export", q)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusBadRequest:
+		if c.backend != BackendAuto {
+			return nil, errors.New(resp.Status)
+		}
+		victoriaMetrics.Delete(c.address)
+		return nil, errExportNotSupported
+	default:
+		return nil, errors.New(resp.Status)
+	}
+
+	series := map[uint64]*exportedSeries{}
+	var order []uint64
+	r := bufio.NewReaderSize(resp.Body, 64*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			s, perr := parseExportLine(line)
+			if perr != nil {
+				return nil, perr
+			}
+			hash := promModel.LabelsToSignature(s.labels)
+			if existing := series[hash]; existing != nil { // a series may be split into several lines
+				existing.timestamps = append(existing.timestamps, s.timestamps...)
+				existing.values = append(existing.values, s.values...)
+			} else {
+				series[hash] = s
+				order = append(order, hash)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]model.MetricValues, 0, len(order))
+	for _, hash := range order {
+		s := series[hash]
+		sort.Stable(s)
+		mv := model.MetricValues{
+			Labels:     s.labels,
+			LabelsHash: hash,
+			Values:     timeseries.New(from, int(to.Sub(from)/step)+1, step),
+		}
+		lookback := exportLookback(s.timestamps, step)
+		i := 0
+		for t := from; !t.After(to); t = t.Add(step) {
+			for i < len(s.timestamps) && s.timestamps[i] <= int64(t)*1000 {
+				i++
+			}
+			if i == 0 {
+				continue
+			}
+			last := i - 1
+			if int64(t)*1000-s.timestamps[last] >= int64(lookback)*1000 || math.IsNaN(s.values[last]) { // a staleness marker
+				continue
+			}
+			mv.Values.Set(t, float32(s.values[last]))
+		}
+		res = append(res, mv)
+	}
+	return res, nil
+}
+
+func exportLookback(timestamps []int64, step timeseries.Duration) timeseries.Duration {
+	var interval int64
+	for i := 1; i < len(timestamps); i++ {
+		if d := timestamps[i] - timestamps[i-1]; d > 0 && (interval == 0 || d < interval) {
+			interval = d
+		}
+	}
+	if interval == 0 {
+		return exportLookbackMax
+	}
+	lookback := timeseries.Duration(2 * interval / 1000)
+	if lookback < step {
+		lookback = step
+	}
+	if lookback > exportLookbackMax {
+		lookback = exportLookbackMax
+	}
+	return lookback
+}
+
+// parseExportLine parses a line of the JSON export: {"metric":{...},"values":[...],"timestamps":[...]}.
+func parseExportLine(line []byte) (*exportedSeries, error) {
+	s := &exportedSeries{labels: model.Labels{}}
+	err := jsonparser.ObjectEach(line, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+		v, err := jsonparser.ParseString(value)
+		if err != nil {
+			return err
+		}
+		s.labels[string(key)] = v
+		return nil
+	}, "metric")
+	if err != nil {
+		return nil, err
+	}
+	var parseErr error
+	_, err = jsonparser.ArrayEach(line, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		v, err := strconv.ParseFloat(string(value), 64)
+		if err != nil { // e.g., null
+			v = math.NaN()
+		}
+		s.values = append(s.values, v)
+	}, "values")
+	if err != nil {
+		return nil, err
+	}
+	_, err = jsonparser.ArrayEach(line, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		t, err := jsonparser.ParseInt(value)
+		if err != nil {
+			parseErr = err
+			return
+		}
+		s.timestamps = append(s.timestamps, t)
+	}, "timestamps")
+	if err != nil {
+		return nil, err
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if len(s.values) != len(s.timestamps) {
+		return nil, errors.New("the numbers of values and timestamps differ")
+	}
+	return s, nil
+}
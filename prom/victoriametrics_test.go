@@ -0,0 +1,142 @@
+package prom
+
+import (
+	"context"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVictoriaMetricsExport(t *testing.T) {
+	export := `{"metric":{"__name__":"container_info","container_id":"c1"},"values":[1,1],"timestamps":[1675329000000,1675329015000]}
+{"metric":{"__name__":"container_info","container_id":"c2"},"values":[2],"timestamps":[1675329030000]}
+{"metric":{"__name__":"container_info","container_id":"c1"},"values":[1,1],"timestamps":[1675329030000,1675329045000]}
+`
+	queryRange := `{"status":"success","isPartial":false,"data":{"resultType":"matrix","result":[]}}`
+
+	var paths []string
+	h := func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.NoError(t, r.ParseForm())
+		switch r.URL.Path {
+		case "/api/v1/export":
+			assert.Equal(t, "container_info", r.Form.Get("match[]"))
+			assert.Equal(t, `{cluster="c"}`, r.Form.Get("extra_filters[]"))
+			w.Write([]byte(export))
+		case "/api/v1/query_range":
+			w.Write([]byte(queryRange))
+		}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(h))
+	defer ts.Close()
+
+	client, err := NewApiClient(ClientConfig{Url: ts.URL, TlsSkipVerify: true, ExtraSelector: `{cluster="c"}`, ScrapeInterval: 15})
+	require.NoError(t, err)
+	ctx := context.Background()
+	from := timeseries.Time(1675329000)
+	to := timeseries.Time(1675329090)
+	step := timeseries.Duration(15)
+
+	_, err = client.QueryRange(ctx, `rate(metric[$RANGE])`, from, to, step)
+	require.NoError(t, err)
+	res, err := client.QueryRange(ctx, `container_info`, from, to, step)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/v1/query_range", "/api/v1/export"}, paths)
+
+	require.Len(t, res, 2)
+	assert.Equal(t, model.Labels{"__name__": "container_info", "container_id": "c1"}, res[0].Labels)
+	assert.Equal(t, "TimeSeries(1675329000, 7, 15, [1 1 1 1 1 . .])", res[0].Values.String())
+	assert.Equal(t, "TimeSeries(1675329000, 7, 15, [. . 2 2 2 2 2])", res[1].Values.String())
+}
+
+func TestVictoriaMetricsExportFallback(t *testing.T) {
+	exportStatus := http.StatusNotFound
+	queryRange := `{"status":"success","isPartial":false,"data":{"resultType":"matrix","result":[]}}`
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v1/export":
+			w.WriteHeader(exportStatus)
+		case "/api/v1/query_range":
+			w.Write([]byte(queryRange))
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewApiClient(ClientConfig{Url: ts.URL, ScrapeInterval: 15})
+	require.NoError(t, err)
+	ctx := context.Background()
+	from, to := timeseries.Time(1675329000), timeseries.Time(1675332600)
+
+	_, err = client.QueryRange(ctx, `container_info`, from, to, 15)
+	require.NoError(t, err)
+	require.True(t, client.isVictoriaMetrics())
+
+	paths = nil
+	_, err = client.QueryRange(ctx, `container_info`, from, to, timeseries.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/v1/query_range"}, paths, "the step is too large to export the raw samples")
+
+	paths = nil
+	queryRange = `{"status":"success","data":{"resultType":"matrix","result":[]}}` // the datasource has been switched to Prometheus
+	_, err = client.QueryRange(ctx, `container_info`, from, to, 15)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/v1/export", "/api/v1/query_range"}, paths)
+	assert.False(t, client.isVictoriaMetrics(), "the address doesn't serve the export API, so it isn't VictoriaMetrics anymore")
+
+	victoriaMetrics.Store(client.address, true)
+	ResetBackendDetection()
+	assert.False(t, client.isVictoriaMetrics())
+
+	client, err = NewApiClient(ClientConfig{Url: ts.URL, ScrapeInterval: 15, Backend: BackendVictoriaMetrics})
+	require.NoError(t, err)
+	exportStatus = http.StatusServiceUnavailable
+	_, err = client.QueryRange(ctx, `container_info`, from, to, 15)
+	assert.Error(t, err)
+}
+
+func TestQueryRangeTooManySamples(t *testing.T) {
+	var ranges [][2]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		start, end := r.Form.Get("start"), r.Form.Get("end")
+		ranges = append(ranges, [2]string{start, end})
+		if start == "0" && end == "120" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"status":"error","errorType":"422","error":"cannot select more than -search.maxSamplesPerQuery=1000 samples"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"a":"b"},"values":[[` + start + `,"1"],[` + end + `,"2"]]}]}}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewApiClient(ClientConfig{Url: ts.URL, Backend: BackendPrometheus})
+	require.NoError(t, err)
+	res, err := client.QueryRange(context.Background(), `metric`, 0, 120, 30)
+	require.NoError(t, err)
+	assert.Equal(t, [][2]string{{"0", "120"}, {"0", "60"}, {"90", "120"}}, ranges)
+	require.Len(t, res, 1)
+	assert.Equal(t, "TimeSeries(0, 5, 30, [1 . 2 1 2])", res[0].Values.String())
+
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"status":"error","error":"query processing would load too many samples into memory in query execution"}`))
+	}))
+	defer limited.Close()
+	client, err = NewApiClient(ClientConfig{Url: limited.URL, Backend: BackendPrometheus})
+	require.NoError(t, err)
+	_, err = client.QueryRange(context.Background(), `metric`, 0, 120, 30)
+	assert.ErrorIs(t, err, errTooManySamples)
+}
+
+func TestPlainSelector(t *testing.T) {
+	assert.Equal(t, `kube_pod_status_ready{condition="true"}`, plainSelector(`kube_pod_status_ready{condition="true"}`))
+	assert.Equal(t, "", plainSelector(`kube_pod_status_scheduled{condition="true"} > 0`))
+	assert.Equal(t, "", plainSelector(`rate(metric[1m])`))
+	assert.Equal(t, "", plainSelector(`metric offset 1m`))
+}
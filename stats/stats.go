@@ -96,6 +96,8 @@ type Collector struct {
 	pricing *cloud_pricing.Manager
 	client  *http.Client
 
+	worldMemoryLimit int64
+
 	instanceUuid    string
 	instanceVersion string
 
@@ -109,7 +111,7 @@ type Collector struct {
 	memUsage []float32
 }
 
-func NewCollector(instanceUuid, version string, db *db.DB, cache *cache.Cache, pricing *cloud_pricing.Manager) *Collector {
+func NewCollector(instanceUuid, version string, db *db.DB, cache *cache.Cache, pricing *cloud_pricing.Manager, worldMemoryLimit int64) *Collector {
 	c := &Collector{
 		db:      db,
 		cache:   cache,
@@ -117,6 +119,8 @@ func NewCollector(instanceUuid, version string, db *db.DB, cache *cache.Cache, p
 
 		client: &http.Client{Timeout: sendTimeout},
 
+		worldMemoryLimit: worldMemoryLimit,
+
 		instanceUuid:    instanceUuid,
 		instanceVersion: version,
 
@@ -254,7 +258,7 @@ func (c *Collector) collect() Stats {
 	var loadTime, auditTime []time.Duration
 	now := timeseries.Now()
 	for _, p := range projects {
-		if p.Prometheus.Url != "" {
+		if p.Prometheus.Configured() {
 			stats.Integration.Prometheus = true
 		}
 		if stats.Integration.PrometheusRefreshInterval == 0 || int(p.Prometheus.RefreshInterval) < stats.Integration.PrometheusRefreshInterval {
@@ -305,7 +309,7 @@ func (c *Collector) collect() Stats {
 		}
 		t := time.Now()
 		step := p.Prometheus.RefreshInterval
-		cr := constructor.New(c.db, p, cc, c.pricing, constructor.OptionLoadPerConnectionHistograms)
+		cr := constructor.New(c.db, p, cc, c.pricing, constructor.OptionLoadPerConnectionHistograms).WithMemoryLimit(c.worldMemoryLimit)
 		w, err := cr.LoadWorld(context.Background(), cacheTo.Add(-worldWindow), cacheTo, step, &stats.Performance.Constructor)
 		if err != nil {
 			klog.Errorln("failed to load world:", err)
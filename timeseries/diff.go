@@ -0,0 +1,133 @@
+package timeseries
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+	"math"
+)
+
+const significanceLevel = 0.05
+
+type Window struct {
+	From Time
+	To   Time
+}
+
+func (w Window) Contains(t Time) bool {
+	return !t.Before(w.From) && !t.After(w.To)
+}
+
+// Mean returns the mean of the values of the series within the window, NaN if there are no values.
+func (w Window) Mean(ts *TimeSeries) float32 {
+	values := windowValues(ts, w)
+	if len(values) == 0 {
+		return NaN
+	}
+	return float32(stat.Mean(values, nil))
+}
+
+// Sum returns the sum of the values of the series within the window, NaN if there are no values.
+func (w Window) Sum(ts *TimeSeries) float32 {
+	values := windowValues(ts, w)
+	if len(values) == 0 {
+		return NaN
+	}
+	return float32(floats.Sum(values))
+}
+
+// WindowStats summarizes the values of a series within a window. It's enough to compare two windows
+// when the series themselves are no longer available (e.g., the metrics snapshots persisted with deployments).
+type WindowStats struct {
+	Mean   float32 `json:"mean"`
+	StdDev float32 `json:"std_dev"`
+	Count  int     `json:"count"`
+}
+
+// Stats returns the stats of the values of the series within the window, Count is 0 if there are no values.
+func (w Window) Stats(ts *TimeSeries) WindowStats {
+	values := windowValues(ts, w)
+	switch len(values) {
+	case 0:
+		return WindowStats{}
+	case 1:
+		return WindowStats{Mean: float32(values[0]), Count: 1}
+	}
+	mean, std := stat.MeanStdDev(values, nil)
+	return WindowStats{Mean: float32(mean), StdDev: float32(std), Count: len(values)}
+}
+
+type Diff struct {
+	Before      float32
+	After       float32
+	Change      float32 // percent
+	Significant bool
+}
+
+func (d Diff) String() string {
+	if IsNaN(d.Change) {
+		return ""
+	}
+	return fmt.Sprintf("%+.f%%", d.Change)
+}
+
+// Ratio compares two aggregated values (e.g., metrics snapshots taken before and after a deployment).
+// A change is considered significant if it exceeds minChange percent.
+func Ratio(before, after float32, minChange float32) Diff {
+	d := Diff{Before: before, After: after, Change: percentChange(before, after)}
+	d.Significant = !IsNaN(d.Change) && float32(math.Abs(float64(d.Change))) > minChange
+	return d
+}
+
+// DiffWindows compares the mean values of a series within two windows.
+func DiffWindows(ts *TimeSeries, before, after Window, minChange float32) Diff {
+	return DiffStats(before.Stats(ts), after.Stats(ts), minChange)
+}
+
+// DiffStats compares the stats of two windows. A change is considered significant if it exceeds minChange percent
+// and Welch's t-test rejects the hypothesis that both windows have the same mean.
+func DiffStats(before, after WindowStats, minChange float32) Diff {
+	if before.Count == 0 || after.Count == 0 {
+		return Diff{Before: NaN, After: NaN, Change: NaN}
+	}
+	d := Ratio(before.Mean, after.Mean, minChange)
+	if d.Significant {
+		d.Significant = before.Count > 1 && after.Count > 1 &&
+			welchPValue(float64(before.Mean), float64(before.StdDev), float64(before.Count), float64(after.Mean), float64(after.StdDev), float64(after.Count)) < significanceLevel
+	}
+	return d
+}
+
+func windowValues(ts *TimeSeries, w Window) []float64 {
+	var res []float64
+	iter := ts.Iter()
+	for iter.Next() {
+		t, v := iter.Value()
+		if IsNaN(v) || !w.Contains(t) {
+			continue
+		}
+		res = append(res, float64(v))
+	}
+	return res
+}
+
+func percentChange(before, after float32) float32 {
+	if IsNaN(before) || IsNaN(after) || before == 0 {
+		return NaN
+	}
+	return (after - before) * 100 / before
+}
+
+func welchPValue(m1, s1, n1, m2, s2, n2 float64) float64 {
+	v1, v2 := s1*s1/n1, s2*s2/n2
+	if v1+v2 == 0 {
+		if m1 == m2 {
+			return 1
+		}
+		return 0
+	}
+	t := (m1 - m2) / math.Sqrt(v1+v2)
+	df := (v1 + v2) * (v1 + v2) / (v1*v1/(n1-1) + v2*v2/(n2-1))
+	return 2 * distuv.StudentsT{Mu: 0, Sigma: 1, Nu: df}.Survival(math.Abs(t))
+}
@@ -0,0 +1,64 @@
+package timeseries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRatio(t *testing.T) {
+	d := Ratio(100, 135, 5)
+	assert.Equal(t, "+35%", d.String())
+	assert.True(t, d.Significant)
+
+	d = Ratio(100, 97, 5)
+	assert.Equal(t, "-3%", d.String())
+	assert.False(t, d.Significant)
+
+	d = Ratio(0, 10, 5)
+	assert.Equal(t, "", d.String())
+	assert.False(t, d.Significant)
+}
+
+func TestDiffWindows(t *testing.T) {
+	ts := NewWithData(0, 1, []float32{10, 11, 9, 10, NaN, 14, 13, 14, 13})
+	before, after := Window{From: 0, To: 3}, Window{From: 5, To: 8}
+
+	d := DiffWindows(ts, before, after, 5)
+	assert.Equal(t, float32(10), d.Before)
+	assert.Equal(t, float32(13.5), d.After)
+	assert.Equal(t, "+35%", d.String())
+	assert.True(t, d.Significant)
+
+	noisy := NewWithData(0, 1, []float32{1, 20, 2, 19, NaN, 25, 3, 18, 4})
+	d = DiffWindows(noisy, before, after, 5)
+	assert.False(t, d.Significant, "the change is within the noise")
+
+	d = DiffWindows(ts, before, Window{From: 100, To: 200}, 5)
+	assert.False(t, d.Significant)
+	assert.True(t, IsNaN(d.Change))
+}
+
+func TestDiffStats(t *testing.T) {
+	ts := NewWithData(0, 1, []float32{10, 11, 9, 10})
+	s := Window{From: 0, To: 3}.Stats(ts)
+	assert.Equal(t, float32(10), s.Mean)
+	assert.Equal(t, 4, s.Count)
+	assert.InDelta(t, 0.8165, s.StdDev, 0.0001)
+	assert.Equal(t, WindowStats{}, Window{From: 100, To: 200}.Stats(ts))
+	assert.Equal(t, WindowStats{Mean: 11, Count: 1}, Window{From: 1, To: 1}.Stats(ts))
+
+	assert.True(t, DiffStats(s, WindowStats{Mean: 13.5, StdDev: 0.5, Count: 4}, 5).Significant)
+	assert.False(t, DiffStats(s, WindowStats{Mean: 13.5, Count: 1}, 5).Significant, "a single value isn't enough for the test")
+	assert.False(t, DiffStats(s, WindowStats{Mean: 10.2, StdDev: 0.1, Count: 100}, 5).Significant, "the change is below the threshold")
+}
+
+func TestWindowAggregates(t *testing.T) {
+	ts := NewWithData(0, 10, []float32{1, NaN, 3, 5, 7})
+	w := Window{From: 10, To: 30}
+	assert.Equal(t, float32(4), w.Mean(ts))
+	assert.Equal(t, float32(8), w.Sum(ts))
+	w = Window{From: 100, To: 200}
+	assert.True(t, IsNaN(w.Mean(ts)))
+	assert.True(t, IsNaN(w.Sum(ts)))
+	assert.True(t, IsNaN(w.Sum(nil)))
+}
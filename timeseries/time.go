@@ -46,6 +46,30 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// IncreaseStepForBigDurations returns a step large enough to keep the number of points reasonable for the duration.
+func IncreaseStepForBigDurations(duration, step Duration) Duration {
+	switch {
+	case duration > 5*24*Hour:
+		return maxDuration(step, 60*Minute)
+	case duration > 24*Hour:
+		return maxDuration(step, 15*Minute)
+	case duration > 12*Hour:
+		return maxDuration(step, 10*Minute)
+	case duration > 6*Hour:
+		return maxDuration(step, 5*Minute)
+	case duration > Hour:
+		return maxDuration(step, Minute)
+	}
+	return step
+}
+
+func maxDuration(d1, d2 Duration) Duration {
+	if d1 >= d2 {
+		return d1
+	}
+	return d2
+}
+
 type Time int64
 
 func Now() Time {
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
+	"unsafe"
 )
 
 var NaN = float32(math.NaN())
@@ -47,6 +49,14 @@ func (ts *TimeSeries) Len() int {
 	return len(ts.data)
 }
 
+// Size returns the approximate number of bytes occupied by the series in memory.
+func (ts *TimeSeries) Size() int {
+	if ts.IsEmpty() {
+		return 0
+	}
+	return int(unsafe.Sizeof(*ts)) + cap(ts.data)*int(unsafe.Sizeof(float32(0)))
+}
+
 func (ts *TimeSeries) MarshalJSON() ([]byte, error) {
 	if ts.IsEmpty() {
 		return json.Marshal(nil)
@@ -197,6 +207,14 @@ func (ts *TimeSeries) Map(f func(t Time, v float32) float32) *TimeSeries {
 	return NewWithData(ts.from, ts.step, data)
 }
 
+// Shift returns the same data moved in time by the given duration.
+func (ts *TimeSeries) Shift(d Duration) *TimeSeries {
+	if ts.IsEmpty() {
+		return nil
+	}
+	return NewWithData(ts.from.Add(d), ts.step, ts.data)
+}
+
 func (ts *TimeSeries) WithNewValue(newValue float32) *TimeSeries {
 	if ts.IsEmpty() {
 		return nil
@@ -255,6 +273,27 @@ func Increase(x, status *TimeSeries) *TimeSeries {
 	return NewWithData(x.from, x.step, data)
 }
 
+// Quantile returns the q-quantile (0 <= q <= 1) of all non-NaN values of the given series.
+func Quantile(q float32, tss ...*TimeSeries) float32 {
+	var values []float32
+	for _, ts := range tss {
+		if ts.IsEmpty() {
+			continue
+		}
+		iter := ts.Iter()
+		for iter.Next() {
+			if _, v := iter.Value(); !IsNaN(v) {
+				values = append(values, v)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return NaN
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values[int(q*float32(len(values)-1))]
+}
+
 func Aggregate2(x, y *TimeSeries, f func(x, y float32) float32) *TimeSeries {
 	if x.IsEmpty() || y.IsEmpty() {
 		return nil
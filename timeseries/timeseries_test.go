@@ -27,3 +27,19 @@ func TestIncrease(t *testing.T) {
 	status := NewWithData(0, 1, []float32{1, 1, 1, 1, 1, 1, 1, NaN, 1, 1, 0, 1, 1})
 	assert.Equal(t, "TimeSeries(0, 13, 1, [. 1 0 0 1 0 0 . . 10 . . 1])", Increase(x, status).String())
 }
+
+func TestQuantile(t *testing.T) {
+	x := NewWithData(0, 1, []float32{5, NaN, 1, 3})
+	y := NewWithData(0, 1, []float32{2, 4, NaN})
+	assert.Equal(t, float32(1), Quantile(0, x, y))
+	assert.Equal(t, float32(3), Quantile(0.5, x, y))
+	assert.Equal(t, float32(5), Quantile(1, x, y))
+	assert.True(t, IsNaN(Quantile(0.99, nil)))
+}
+
+func TestShift(t *testing.T) {
+	x := NewWithData(0, 10, []float32{1, NaN, 3})
+	assert.Equal(t, "TimeSeries(100, 3, 10, [1 . 3])", x.Shift(100).String())
+	assert.Equal(t, "TimeSeries(0, 3, 10, [1 . 3])", x.String())
+	assert.Nil(t, (*TimeSeries)(nil).Shift(100))
+}
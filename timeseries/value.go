@@ -27,3 +27,16 @@ func (v Value) MarshalJSON() ([]byte, error) {
 	}
 	return json.Marshal(f)
 }
+
+func (v *Value) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*v = Value(NaN)
+		return nil
+	}
+	var f float32
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	*v = Value(f)
+	return nil
+}
@@ -2,9 +2,9 @@ package tracing
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"github.com/ClickHouse/clickhouse-go/v2"
+	chstore "github.com/coroot/coroot/clickhouse"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
@@ -19,33 +19,17 @@ type ClickhouseClient struct {
 }
 
 func NewClickhouseClient(protocol, addr string, tlsEnable, tlsSkipVerify bool, auth utils.BasicAuth, database, tracesTable string) (*ClickhouseClient, error) {
-	opts := &clickhouse.Options{
-		Addr: []string{addr},
-		Auth: clickhouse.Auth{
-			Database: database,
-			Username: auth.User,
-			Password: auth.Password,
-		},
-		Compression: &clickhouse.Compression{Method: clickhouse.CompressionLZ4},
-		DialTimeout: 10 * time.Second,
-	}
-	switch protocol {
-	case "native":
-		opts.Protocol = clickhouse.Native
-	case "http":
-		opts.Protocol = clickhouse.HTTP
-	default:
-		return nil, fmt.Errorf("unknown protocol: %s", protocol)
-	}
 	if tracesTable == "" {
 		return nil, fmt.Errorf("empty table name")
 	}
-	if tlsEnable {
-		opts.TLS = &tls.Config{
-			InsecureSkipVerify: tlsSkipVerify,
-		}
-	}
-	conn, err := clickhouse.Open(opts)
+	conn, err := chstore.Connect(chstore.ClientConfig{
+		Protocol:      protocol,
+		Addr:          addr,
+		TlsEnable:     tlsEnable,
+		TlsSkipVerify: tlsSkipVerify,
+		Auth:          auth,
+		Database:      database,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +40,76 @@ func (c *ClickhouseClient) Ping(ctx context.Context) error {
 	return c.conn.Ping(ctx)
 }
 
+func (c *ClickhouseClient) Close() error {
+	return c.conn.Close()
+}
+
+// CreateTables creates the tables of the OpenTelemetry Collector ClickHouse exporter if they don't exist,
+// so that Coroot can store the spans it receives via OTLP. A zero ttl means the spans are kept forever.
+func (c *ClickhouseClient) CreateTables(ctx context.Context, ttl time.Duration) error {
+	ttlExpr := func(column string) string {
+		if ttl <= 0 {
+			return ""
+		}
+		return fmt.Sprintf("TTL toDateTime(%s) + toIntervalSecond(%d)", column, int64(ttl.Seconds()))
+	}
+	for _, q := range []string{
+		fmt.Sprintf(createTracesTable, c.tracesTable, ttlExpr("Timestamp")),
+		fmt.Sprintf(createTraceIdTsTable, c.traceIdTsTable(), ttlExpr("Start")),
+		fmt.Sprintf(createTraceIdTsView, c.traceIdTsTable(), c.traceIdTsTable(), c.tracesTable),
+	} {
+		if err := c.conn.Exec(ctx, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ClickhouseClient) InsertSpans(ctx context.Context, spans []*Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	batch, err := c.conn.PrepareBatch(ctx, "INSERT INTO "+c.tracesTable)
+	if err != nil {
+		return err
+	}
+	for _, s := range spans {
+		eventsTimestamp := make([]time.Time, 0, len(s.Events))
+		eventsName := make([]string, 0, len(s.Events))
+		eventsAttributes := make([]map[string]string, 0, len(s.Events))
+		for _, e := range s.Events {
+			eventsTimestamp = append(eventsTimestamp, e.Timestamp)
+			eventsName = append(eventsName, e.Name)
+			eventsAttributes = append(eventsAttributes, e.Attributes)
+		}
+		linksTraceId := make([]string, 0, len(s.Links))
+		linksSpanId := make([]string, 0, len(s.Links))
+		linksTraceState := make([]string, 0, len(s.Links))
+		linksAttributes := make([]map[string]string, 0, len(s.Links))
+		for _, l := range s.Links {
+			linksTraceId = append(linksTraceId, l.TraceId)
+			linksSpanId = append(linksSpanId, l.SpanId)
+			linksTraceState = append(linksTraceState, l.TraceState)
+			linksAttributes = append(linksAttributes, l.Attributes)
+		}
+		err = batch.Append(
+			s.Timestamp, s.TraceId, s.SpanId, s.ParentSpanId, s.TraceState, s.Name, s.Kind, s.ServiceName,
+			s.ResourceAttributes, s.ScopeName, s.ScopeVersion, s.Attributes, s.Duration.Nanoseconds(), s.StatusCode, s.StatusMessage,
+			eventsTimestamp, eventsName, eventsAttributes,
+			linksTraceId, linksSpanId, linksTraceState, linksAttributes,
+		)
+		if err != nil {
+			_ = batch.Abort()
+			return err
+		}
+	}
+	return batch.Send()
+}
+
+func (c *ClickhouseClient) traceIdTsTable() string {
+	return c.tracesTable + "_trace_id_ts"
+}
+
 func (c *ClickhouseClient) GetServiceNames(ctx context.Context) ([]string, error) {
 	q := "SELECT DISTINCT ServiceName"
 	q += " FROM " + c.tracesTable
@@ -128,7 +182,7 @@ func (c *ClickhouseClient) GetParentSpans(ctx context.Context, spans []*Span) ([
 		return nil, nil
 	}
 	return c.getSpans(ctx, 0, 0, 0, 0, false,
-		"@traceIds as traceIds, (SELECT min(Start) FROM otel_traces_trace_id_ts WHERE TraceId IN (traceIds)) as start, (SELECT max(End) + 1 FROM otel_traces_trace_id_ts WHERE TraceId IN (traceIds)) as end",
+		fmt.Sprintf("@traceIds as traceIds, (SELECT min(Start) FROM %[1]s WHERE TraceId IN (traceIds)) as start, (SELECT max(End) + 1 FROM %[1]s WHERE TraceId IN (traceIds)) as end", c.traceIdTsTable()),
 		"", 0,
 		"Timestamp BETWEEN start AND end AND TraceId IN (traceIds) AND (TraceId, SpanId) IN (@ids)",
 		clickhouse.Named("traceIds", traceIds.Items()),
@@ -138,7 +192,7 @@ func (c *ClickhouseClient) GetParentSpans(ctx context.Context, spans []*Span) ([
 
 func (c *ClickhouseClient) GetSpansByTraceId(ctx context.Context, traceId string) ([]*Span, error) {
 	return c.getSpans(ctx, 0, 0, 0, 0, false,
-		"(SELECT min(Start) FROM otel_traces_trace_id_ts WHERE TraceId = @traceId) as start, (SELECT max(End) + 1 FROM otel_traces_trace_id_ts WHERE TraceId = @traceId) as end",
+		fmt.Sprintf("(SELECT min(Start) FROM %[1]s WHERE TraceId = @traceId) as start, (SELECT max(End) + 1 FROM %[1]s WHERE TraceId = @traceId) as end", c.traceIdTsTable()),
 		"Timestamp", 0,
 		"TraceId = @traceId AND Timestamp BETWEEN start AND end",
 		clickhouse.Named("traceId", traceId),
@@ -189,7 +243,7 @@ func (c *ClickhouseClient) getSpans(ctx context.Context, tsFrom, tsTo timeseries
 	if with != "" {
 		q += "WITH " + with
 	}
-	q += " SELECT Timestamp, TraceId, SpanId, ParentSpanId, SpanName, ServiceName, Duration, StatusCode, StatusMessage, SpanAttributes, Events.Timestamp, Events.Name, Events.Attributes"
+	q += " SELECT Timestamp, TraceId, SpanId, ParentSpanId, SpanName, ServiceName, ResourceAttributes, Duration, StatusCode, StatusMessage, SpanAttributes, Events.Timestamp, Events.Name, Events.Attributes"
 	q += " FROM " + c.tracesTable
 	q += " WHERE " + strings.Join(filters, " AND ")
 	if orderBy != "" {
@@ -212,7 +266,7 @@ func (c *ClickhouseClient) getSpans(ctx context.Context, tsFrom, tsTo timeseries
 		var eventsTimestamp []time.Time
 		var eventsName []string
 		var eventsAttributes []map[string]string
-		if err = rows.Scan(&s.Timestamp, &s.TraceId, &s.SpanId, &s.ParentSpanId, &s.Name, &s.ServiceName, &s.Duration,
+		if err = rows.Scan(&s.Timestamp, &s.TraceId, &s.SpanId, &s.ParentSpanId, &s.Name, &s.ServiceName, &s.ResourceAttributes, &s.Duration,
 			&s.StatusCode, &s.StatusMessage, &s.Attributes, &eventsTimestamp, &eventsName, &eventsAttributes,
 		); err != nil {
 			return nil, err
@@ -230,3 +284,66 @@ func (c *ClickhouseClient) getSpans(ctx context.Context, tsFrom, tsTo timeseries
 	}
 	return res, nil
 }
+
+const (
+	createTracesTable = `
+CREATE TABLE IF NOT EXISTS %s (
+	Timestamp DateTime64(9) CODEC(Delta, ZSTD(1)),
+	TraceId String CODEC(ZSTD(1)),
+	SpanId String CODEC(ZSTD(1)),
+	ParentSpanId String CODEC(ZSTD(1)),
+	TraceState String CODEC(ZSTD(1)),
+	SpanName LowCardinality(String) CODEC(ZSTD(1)),
+	SpanKind LowCardinality(String) CODEC(ZSTD(1)),
+	ServiceName LowCardinality(String) CODEC(ZSTD(1)),
+	ResourceAttributes Map(LowCardinality(String), String) CODEC(ZSTD(1)),
+	ScopeName String CODEC(ZSTD(1)),
+	ScopeVersion String CODEC(ZSTD(1)),
+	SpanAttributes Map(LowCardinality(String), String) CODEC(ZSTD(1)),
+	Duration Int64 CODEC(ZSTD(1)),
+	StatusCode LowCardinality(String) CODEC(ZSTD(1)),
+	StatusMessage String CODEC(ZSTD(1)),
+	Events Nested (
+		Timestamp DateTime64(9),
+		Name LowCardinality(String),
+		Attributes Map(LowCardinality(String), String)
+	) CODEC(ZSTD(1)),
+	Links Nested (
+		TraceId String,
+		SpanId String,
+		TraceState String,
+		Attributes Map(LowCardinality(String), String)
+	) CODEC(ZSTD(1)),
+	INDEX idx_trace_id TraceId TYPE bloom_filter(0.001) GRANULARITY 1,
+	INDEX idx_res_attr_key mapKeys(ResourceAttributes) TYPE bloom_filter(0.01) GRANULARITY 1,
+	INDEX idx_res_attr_value mapValues(ResourceAttributes) TYPE bloom_filter(0.01) GRANULARITY 1,
+	INDEX idx_span_attr_key mapKeys(SpanAttributes) TYPE bloom_filter(0.01) GRANULARITY 1,
+	INDEX idx_span_attr_value mapValues(SpanAttributes) TYPE bloom_filter(0.01) GRANULARITY 1,
+	INDEX idx_duration Duration TYPE minmax GRANULARITY 1
+) ENGINE MergeTree()
+%s
+PARTITION BY toDate(Timestamp)
+ORDER BY (ServiceName, SpanName, toUnixTimestamp(Timestamp), TraceId)
+SETTINGS index_granularity=8192, ttl_only_drop_parts = 1`
+
+	createTraceIdTsTable = `
+CREATE TABLE IF NOT EXISTS %s (
+	TraceId String CODEC(ZSTD(1)),
+	Start DateTime64(9) CODEC(Delta, ZSTD(1)),
+	End DateTime64(9) CODEC(Delta, ZSTD(1)),
+	INDEX idx_trace_id TraceId TYPE bloom_filter(0.01) GRANULARITY 1
+) ENGINE MergeTree()
+%s
+ORDER BY (TraceId, toUnixTimestamp(Start))
+SETTINGS index_granularity=8192`
+
+	createTraceIdTsView = `
+CREATE MATERIALIZED VIEW IF NOT EXISTS %s_mv TO %s
+AS SELECT
+	TraceId,
+	min(Timestamp) as Start,
+	max(Timestamp) as End
+FROM %s
+WHERE TraceId != ''
+GROUP BY TraceId`
+)
@@ -0,0 +1,190 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"github.com/coroot/coroot/otlp"
+	"google.golang.org/protobuf/encoding/protowire"
+	"time"
+)
+
+var (
+	spanKinds = []string{
+		"SPAN_KIND_UNSPECIFIED", "SPAN_KIND_INTERNAL", "SPAN_KIND_SERVER", "SPAN_KIND_CLIENT", "SPAN_KIND_PRODUCER", "SPAN_KIND_CONSUMER",
+	}
+	statusCodes = []string{
+		"STATUS_CODE_UNSET", "STATUS_CODE_OK", "STATUS_CODE_ERROR",
+	}
+)
+
+// DecodeOtlpTraces decodes a protobuf-encoded OTLP ExportTraceServiceRequest.
+// The messages are parsed field by field, so only the fields stored in ClickHouse are decoded
+// (see opentelemetry-proto/opentelemetry/proto/trace/v1/trace.proto for the field numbers).
+func DecodeOtlpTraces(data []byte) ([]*Span, error) {
+	var spans []*Span
+	err := otlp.ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		if num == 1 && typ == protowire.BytesType {
+			return decodeResourceSpans(b, &spans)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
+
+func decodeResourceSpans(data []byte, spans *[]*Span) error {
+	resource := map[string]string{}
+	var scopeSpans [][]byte
+	err := otlp.ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			return otlp.DecodeAttributes(b, 1, resource)
+		case 2:
+			scopeSpans = append(scopeSpans, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// the fields may come in any order, so the spans are decoded once the resource is known
+	for _, ss := range scopeSpans {
+		if err = decodeScopeSpans(ss, resource, spans); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeScopeSpans(data []byte, resource map[string]string, spans *[]*Span) error {
+	var scopeName, scopeVersion string
+	var encoded [][]byte
+	err := otlp.ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			return otlp.ForEachField(b, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+				switch {
+				case num == 1 && typ == protowire.BytesType:
+					scopeName = string(b)
+				case num == 2 && typ == protowire.BytesType:
+					scopeVersion = string(b)
+				}
+				return nil
+			})
+		case 2:
+			encoded = append(encoded, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, b := range encoded {
+		s := &Span{
+			ServiceName:        resource["service.name"],
+			ResourceAttributes: resource,
+			ScopeName:          scopeName,
+			ScopeVersion:       scopeVersion,
+			Attributes:         map[string]string{},
+		}
+		if err = decodeSpan(b, s); err != nil {
+			return err
+		}
+		*spans = append(*spans, s)
+	}
+	return nil
+}
+
+func decodeSpan(data []byte, s *Span) error {
+	var start, end uint64
+	s.Kind = spanKinds[0]
+	s.StatusCode = statusCodes[0]
+	err := otlp.ForEachField(data, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			s.TraceId = hex.EncodeToString(b)
+		case 2:
+			s.SpanId = hex.EncodeToString(b)
+		case 3:
+			s.TraceState = string(b)
+		case 4:
+			s.ParentSpanId = hex.EncodeToString(b)
+		case 5:
+			s.Name = string(b)
+		case 6:
+			if v < uint64(len(spanKinds)) {
+				s.Kind = spanKinds[v]
+			}
+		case 7:
+			start = v
+		case 8:
+			end = v
+		case 9:
+			return otlp.DecodeKeyValue(b, s.Attributes)
+		case 11:
+			e := Event{Attributes: map[string]string{}}
+			err := otlp.ForEachField(b, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+				switch num {
+				case 1:
+					e.Timestamp = time.Unix(0, int64(v))
+				case 2:
+					e.Name = string(b)
+				case 3:
+					return otlp.DecodeKeyValue(b, e.Attributes)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			s.Events = append(s.Events, e)
+		case 13:
+			l := Link{Attributes: map[string]string{}}
+			err := otlp.ForEachField(b, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+				switch num {
+				case 1:
+					l.TraceId = hex.EncodeToString(b)
+				case 2:
+					l.SpanId = hex.EncodeToString(b)
+				case 3:
+					l.TraceState = string(b)
+				case 4:
+					return otlp.DecodeKeyValue(b, l.Attributes)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			s.Links = append(s.Links, l)
+		case 15:
+			return otlp.ForEachField(b, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) error {
+				switch num {
+				case 2:
+					s.StatusMessage = string(b)
+				case 3:
+					if v < uint64(len(statusCodes)) {
+						s.StatusCode = statusCodes[v]
+					}
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.Timestamp = time.Unix(0, int64(start))
+	if end > start {
+		s.Duration = time.Duration(end - start)
+	}
+	return nil
+}
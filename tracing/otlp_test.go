@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"github.com/coroot/coroot/utils/prototest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+func keyValue(key string, value []byte) []byte {
+	return prototest.Message(prototest.Bytes(1, []byte(key)), prototest.Bytes(2, value))
+}
+
+func TestDecodeOtlpTraces(t *testing.T) {
+	start := time.Date(2023, 2, 2, 10, 0, 0, 0, time.UTC)
+	span := prototest.Message(
+		prototest.Bytes(1, []byte{0xab, 0xcd}),
+		prototest.Bytes(2, []byte{0x01}),
+		prototest.Bytes(4, []byte{0x02}),
+		prototest.Bytes(5, []byte("GET /users")),
+		prototest.Varint(6, 2),
+		prototest.Fixed64(7, uint64(start.UnixNano())),
+		prototest.Fixed64(8, uint64(start.Add(150*time.Millisecond).UnixNano())),
+		prototest.Bytes(9, keyValue("http.status_code", prototest.Message(prototest.Varint(3, 500)))),
+		prototest.Bytes(9, keyValue("retry", prototest.Message(prototest.Varint(2, 1)))),
+		prototest.Bytes(9, keyValue("ratio", prototest.Message(prototest.Fixed64(4, math.Float64bits(0.5))))),
+		prototest.Bytes(9, keyValue("tags", prototest.Message(prototest.Bytes(5, prototest.Message(
+			prototest.Bytes(1, prototest.Message(prototest.Bytes(1, []byte("a")))),
+			prototest.Bytes(1, prototest.Message(prototest.Varint(3, 1))),
+		))))),
+		prototest.Bytes(11, prototest.Message(
+			prototest.Fixed64(1, uint64(start.Add(time.Millisecond).UnixNano())),
+			prototest.Bytes(2, []byte("exception")),
+			prototest.Bytes(3, keyValue("exception.type", prototest.Message(prototest.Bytes(1, []byte("IOError"))))),
+		)),
+		prototest.Bytes(15, prototest.Message(prototest.Bytes(2, []byte("timeout")), prototest.Varint(3, 2))),
+	)
+	scopeSpans := prototest.Message(
+		prototest.Bytes(2, span), // the spans before the scope
+		prototest.Bytes(1, prototest.Message(prototest.Bytes(1, []byte("io.opentelemetry.http")), prototest.Bytes(2, []byte("1.0")))),
+	)
+	resourceSpans := prototest.Message(
+		prototest.Bytes(2, scopeSpans), // the scope spans before the resource
+		prototest.Bytes(1, prototest.Message(
+			prototest.Bytes(1, keyValue("service.name", prototest.Message(prototest.Bytes(1, []byte("users"))))),
+			prototest.Bytes(1, keyValue("k8s.pod.name", prototest.Message(prototest.Bytes(1, []byte("users-7f9c-x2k4"))))),
+		)),
+	)
+
+	spans, err := DecodeOtlpTraces(prototest.Message(prototest.Bytes(1, resourceSpans)))
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "abcd", s.TraceId)
+	assert.Equal(t, "01", s.SpanId)
+	assert.Equal(t, "02", s.ParentSpanId)
+	assert.Equal(t, "GET /users", s.Name)
+	assert.Equal(t, "SPAN_KIND_SERVER", s.Kind)
+	assert.Equal(t, "users", s.ServiceName)
+	assert.Equal(t, "users-7f9c-x2k4", s.ResourceAttributes["k8s.pod.name"])
+	assert.Equal(t, "io.opentelemetry.http", s.ScopeName)
+	assert.Equal(t, "1.0", s.ScopeVersion)
+	assert.True(t, s.Timestamp.Equal(start))
+	assert.Equal(t, 150*time.Millisecond, s.Duration)
+	assert.Equal(t, map[string]string{"http.status_code": "500", "retry": "true", "ratio": "0.5", "tags": `["a",1]`}, s.Attributes)
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, "exception", s.Events[0].Name)
+	assert.Equal(t, "IOError", s.Events[0].Attributes["exception.type"])
+	assert.Equal(t, "STATUS_CODE_ERROR", s.StatusCode)
+	assert.Equal(t, "timeout", s.StatusMessage)
+
+	_, err = DecodeOtlpTraces([]byte{0x0a, 0x05, 0x01})
+	assert.Error(t, err)
+}
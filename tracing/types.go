@@ -12,17 +12,23 @@ const (
 )
 
 type Span struct {
-	Timestamp     time.Time
-	Name          string
-	TraceId       string
-	SpanId        string
-	ParentSpanId  string
-	ServiceName   string
-	Duration      time.Duration
-	StatusCode    string
-	StatusMessage string
-	Attributes    map[string]string
-	Events        []Event
+	Timestamp          time.Time
+	Name               string
+	TraceId            string
+	SpanId             string
+	ParentSpanId       string
+	TraceState         string
+	Kind               string
+	ServiceName        string
+	ResourceAttributes map[string]string
+	ScopeName          string
+	ScopeVersion       string
+	Duration           time.Duration
+	StatusCode         string
+	StatusMessage      string
+	Attributes         map[string]string
+	Events             []Event
+	Links              []Link
 }
 
 type Event struct {
@@ -30,3 +36,10 @@ type Event struct {
 	Name       string
 	Attributes map[string]string
 }
+
+type Link struct {
+	TraceId    string
+	SpanId     string
+	TraceState string
+	Attributes map[string]string
+}
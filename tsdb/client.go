@@ -0,0 +1,59 @@
+package tsdb
+
+import (
+	"context"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/prom"
+	"github.com/coroot/coroot/timeseries"
+	promModel "github.com/prometheus/common/model"
+)
+
+// Client implements prom.Client by evaluating queries against the project's embedded database.
+type Client struct {
+	storage   *Storage
+	projectId db.ProjectId
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.storage.get(c.projectId)
+	return err
+}
+
+func (c *Client) QueryRange(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration) ([]model.MetricValues, error) {
+	from = from.Truncate(step)
+	to = to.Truncate(step)
+	tdb, err := c.storage.get(c.projectId)
+	if err != nil {
+		return nil, err
+	}
+	q, err := c.storage.engine.NewRangeQuery(tdb, nil, prom.ExpandRange(query, step), from.ToStandard(), to.ToStandard(), step.ToStandard())
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+	r := q.Exec(ctx)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	matrix, err := r.Matrix()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.MetricValues, 0, len(matrix))
+	for _, s := range matrix {
+		mv := model.MetricValues{
+			Labels: make(model.Labels, len(s.Metric)),
+			Values: timeseries.New(from, int(to.Sub(from)/step)+1, step),
+		}
+		for _, l := range s.Metric {
+			mv.Labels[l.Name] = l.Value
+		}
+		mv.LabelsHash = promModel.LabelsToSignature(mv.Labels)
+		for _, p := range s.Points {
+			mv.Values.Set(timeseries.Time(p.T/1000), float32(p.V))
+		}
+		res = append(res, mv)
+	}
+	return res, nil
+}
@@ -0,0 +1,145 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/utils"
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+	promTsdb "github.com/prometheus/prometheus/tsdb"
+	"io"
+	"k8s.io/klog"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	MaxWriteRequestSize = 32 << 20
+
+	queryTimeout    = 2 * time.Minute
+	queryMaxSamples = 50000000
+	lookbackDelta   = 5 * time.Minute
+)
+
+type Config struct {
+	Path      string
+	Retention time.Duration
+}
+
+// Storage is the embedded TSDB the agents can push metrics to using the Prometheus remote-write protocol.
+// Each project gets a separate database, so the data of the projects is isolated without any extra selectors.
+type Storage struct {
+	cfg    Config
+	engine *promql.Engine
+
+	lock sync.Mutex
+	dbs  map[db.ProjectId]*promTsdb.DB
+}
+
+func NewStorage(cfg Config) (*Storage, error) {
+	if err := utils.CreateDirectoryIfNotExists(cfg.Path); err != nil {
+		return nil, err
+	}
+	return &Storage{
+		cfg: cfg,
+		engine: promql.NewEngine(promql.EngineOpts{
+			MaxSamples:    queryMaxSamples,
+			Timeout:       queryTimeout,
+			LookbackDelta: lookbackDelta,
+		}),
+		dbs: map[db.ProjectId]*promTsdb.DB{},
+	}, nil
+}
+
+func (s *Storage) get(projectId db.ProjectId) (*promTsdb.DB, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if tdb := s.dbs[projectId]; tdb != nil {
+		return tdb, nil
+	}
+	opts := promTsdb.DefaultOptions()
+	if s.cfg.Retention > 0 {
+		opts.RetentionDuration = s.cfg.Retention.Milliseconds()
+	}
+	tdb, err := promTsdb.Open(path.Join(s.cfg.Path, string(projectId)), nil, nil, opts, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the TSDB of project %s: %w", projectId, err)
+	}
+	klog.Infof("opened the TSDB of project %s", projectId)
+	s.dbs[projectId] = tdb
+	return tdb, nil
+}
+
+// DecodeWriteRequest reads a snappy-compressed protobuf-encoded remote-write request.
+func DecodeWriteRequest(r io.Reader) (*prompb.WriteRequest, error) {
+	compressed, err := io.ReadAll(io.LimitReader(r, MaxWriteRequestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed) > MaxWriteRequestSize {
+		return nil, errors.New("the request is too large")
+	}
+	size, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxWriteRequestSize {
+		return nil, errors.New("the decompressed request is too large")
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, err
+	}
+	req := &prompb.WriteRequest{}
+	if err = req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Write appends the samples of the request to the project's database.
+// The samples the database can't accept (out of order, outside the head block, duplicates) are skipped,
+// as a remote-write sender would retry the whole request otherwise.
+func (s *Storage) Write(ctx context.Context, projectId db.ProjectId, req *prompb.WriteRequest) error {
+	tdb, err := s.get(projectId)
+	if err != nil {
+		return err
+	}
+	app := tdb.Appender(ctx)
+	skipped := 0
+	for _, ts := range req.Timeseries {
+		ls := make(labels.Labels, 0, len(ts.Labels))
+		for _, l := range ts.Labels {
+			ls = append(ls, labels.Label{Name: l.Name, Value: l.Value})
+		}
+		sort.Sort(ls)
+		var ref storage.SeriesRef
+		for _, sample := range ts.Samples {
+			ref, err = app.Append(ref, ls, sample.Timestamp, sample.Value)
+			switch {
+			case err == nil:
+			case errors.Is(err, storage.ErrOutOfOrderSample), errors.Is(err, storage.ErrOutOfBounds),
+				errors.Is(err, storage.ErrTooOldSample), errors.Is(err, storage.ErrDuplicateSampleForTimestamp):
+				skipped++
+			default:
+				_ = app.Rollback()
+				return err
+			}
+		}
+	}
+	if skipped > 0 {
+		klog.Warningf("project %s: skipped %d samples rejected by the TSDB", projectId, skipped)
+	}
+	return app.Commit()
+}
+
+func (s *Storage) Client(projectId db.ProjectId) *Client {
+	return &Client{storage: s, projectId: projectId}
+}
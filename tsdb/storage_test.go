@@ -0,0 +1,51 @@
+package tsdb
+
+import (
+	"bytes"
+	"context"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWriteAndQuery(t *testing.T) {
+	storage, err := NewStorage(Config{Path: t.TempDir()})
+	require.NoError(t, err)
+
+	now := timeseries.Now().Truncate(timeseries.Minute)
+	series := prompb.TimeSeries{
+		Labels: []prompb.Label{{Name: "job", Value: "node"}, {Name: "__name__", Value: "node_info"}},
+	}
+	for ts := now.Add(-10 * timeseries.Minute); !ts.After(now); ts = ts.Add(15) {
+		series.Samples = append(series.Samples, prompb.Sample{Timestamp: int64(ts) * 1000, Value: 1})
+	}
+	data, err := (&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{series}}).Marshal()
+	require.NoError(t, err)
+	req, err := DecodeWriteRequest(bytes.NewReader(snappy.Encode(nil, data)))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, storage.Write(ctx, "project", req))
+	require.NoError(t, storage.Write(ctx, "project", req)) // duplicates are skipped
+
+	client := storage.Client("project")
+	res, err := client.QueryRange(ctx, `count_over_time(node_info[$RANGE])`, now.Add(-5*timeseries.Minute), now, timeseries.Minute)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "node", res[0].Labels["job"])
+	assert.Equal(t, float32(13), res[0].Values.Last()) // both ends of the 3m window are included
+
+	res, err = storage.Client("other").QueryRange(ctx, `node_info`, now.Add(-5*timeseries.Minute), now, timeseries.Minute)
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+}
+
+func TestDecodeWriteRequestTooLarge(t *testing.T) {
+	compressed := snappy.Encode(nil, make([]byte, MaxWriteRequestSize+1))
+	require.Less(t, len(compressed), MaxWriteRequestSize)
+	_, err := DecodeWriteRequest(bytes.NewReader(compressed))
+	assert.Error(t, err)
+}
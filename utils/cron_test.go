@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCron(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+
+	s, err := ParseCron("30 1 * * 1-5")
+	assert.NoError(t, err)
+	assert.True(t, s.Match(time.Date(2023, 3, 6, 1, 30, 0, 0, time.UTC)))  // Monday
+	assert.False(t, s.Match(time.Date(2023, 3, 5, 1, 30, 0, 0, time.UTC))) // Sunday
+	assert.False(t, s.Match(time.Date(2023, 3, 6, 1, 31, 0, 0, time.UTC)))
+
+	assert.True(t, s.Within(time.Date(2023, 3, 6, 2, 30, 0, 0, time.UTC), time.Hour))
+	assert.False(t, s.Within(time.Date(2023, 3, 6, 2, 31, 0, 0, time.UTC), time.Hour))
+	assert.False(t, s.Within(time.Date(2023, 3, 6, 1, 29, 0, 0, time.UTC), time.Hour))
+
+	s, err = ParseCron("*/15 0,12 1 * *")
+	assert.NoError(t, err)
+	assert.True(t, s.Match(time.Date(2023, 3, 1, 12, 45, 0, 0, time.UTC)))
+	assert.False(t, s.Match(time.Date(2023, 3, 1, 12, 50, 0, 0, time.UTC)))
+	assert.False(t, s.Match(time.Date(2023, 3, 2, 0, 0, 0, 0, time.UTC)))
+}
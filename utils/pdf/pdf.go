@@ -0,0 +1,151 @@
+// Package pdf implements a minimal PDF writer supporting text in the standard Helvetica fonts,
+// lines, polylines, and rectangles. It's enough to render reports without external dependencies.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	PageWidth  = 842 // A4 landscape, points
+	PageHeight = 595
+)
+
+type Color struct {
+	R, G, B uint8
+}
+
+var (
+	Black = RGB(0, 0, 0)
+	Grey  = RGB(158, 158, 158)
+)
+
+func RGB(r, g, b uint8) Color {
+	return Color{R: r, G: g, B: b}
+}
+
+type Document struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage starts a new page. The coordinates of all the drawing methods are relative to the top left corner of the page.
+func (d *Document) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+}
+
+func (d *Document) Text(x, y, size float64, bold bool, color Color, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page, "BT %s rg /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", rgb(color), font, size, x, PageHeight-y, escape(text))
+}
+
+func (d *Document) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(d.page, "%s RG %.2f w %.2f %.2f m %.2f %.2f l S\n", rgb(color), width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Polyline draws connected segments. Points are pairs of x, y coordinates; a nil point breaks the line.
+func (d *Document) Polyline(points [][]float64, width float64, color Color) {
+	fmt.Fprintf(d.page, "%s RG %.2f w\n", rgb(color), width)
+	started := false
+	for _, p := range points {
+		if p == nil {
+			started = false
+			continue
+		}
+		op := "l"
+		if !started {
+			op = "m"
+			started = true
+		}
+		fmt.Fprintf(d.page, "%.2f %.2f %s\n", p[0], PageHeight-p[1], op)
+	}
+	d.page.WriteString("S\n")
+}
+
+func (d *Document) Rect(x, y, w, h float64, color Color, fill bool) {
+	op := "S"
+	colorOp := "RG"
+	if fill {
+		op = "f"
+		colorOp = "rg"
+	}
+	fmt.Fprintf(d.page, "%s %s 0.5 w %.2f %.2f %.2f %.2f re %s\n", rgb(color), colorOp, x, PageHeight-y-h, w, h, op)
+}
+
+// TextWidth estimates the width of a text rendered in Helvetica.
+func TextWidth(text string, size float64) float64 {
+	return float64(len(text)) * size * 0.52
+}
+
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	buf := &bytes.Buffer{}
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	buf.WriteString("%PDF-1.4\n")
+
+	const firstPageObj = 5
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPageObj+i*2))
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range d.pages {
+		obj(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPageObj+i*2+1,
+		))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.Len(), p.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.WriteTo(w)
+}
+
+func rgb(c Color) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteRune(' ')
+		case r < 128:
+			b.WriteRune(r)
+		case r >= 160 && r < 256:
+			b.WriteString(fmt.Sprintf("\\%03o", r))
+		default:
+			b.WriteRune('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	d := New()
+	d.AddPage()
+	d.Text(10, 20, 12, true, Black, "Title (draft)")
+	d.Line(0, 0, 100, 100, 1, Grey)
+	d.AddPage()
+	d.Polyline([][]float64{{0, 0}, {10, 10}, nil, {20, 20}, {30, 30}}, 1, RGB(255, 0, 0))
+	d.Rect(10, 10, 50, 20, Black, true)
+
+	buf := &bytes.Buffer{}
+	_, err := d.WriteTo(buf)
+	require.NoError(t, err)
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	assert.Contains(t, out, "/Count 2")
+	assert.Contains(t, out, "(Title \\(draft\\)) Tj")
+	assert.Equal(t, 2, strings.Count(out, " m\n"), "the nil point must start a new segment")
+
+	// every xref entry must point to the beginning of the corresponding object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	require.Len(t, m, 2)
+	xref, err := strconv.Atoi(m[1])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out[xref:], "xref\n"))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out[xref:], -1)
+	require.Len(t, entries, 8) // catalog, pages, 2 fonts, and a page with its content per page
+	for i, e := range entries {
+		offset, err := strconv.Atoi(e[1])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out[offset:], fmt.Sprintf("%d 0 obj\n", i+1)), "object %d", i+1)
+	}
+}
+
+func TestEmptyDocument(t *testing.T) {
+	buf := &bytes.Buffer{}
+	_, err := New().WriteTo(buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "/Count 1")
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\\b \(c\)`, escape(`a\b (c)`))
+	assert.Equal(t, "a b", escape("a\nb"))
+	assert.Equal(t, `caf\351`, escape("café"))
+	assert.Equal(t, "?", escape("日"))
+}
@@ -0,0 +1,39 @@
+// Package prototest builds protobuf messages field by field, so the tests of the decoders of OTLP and other protocols
+// don't depend on the generated types.
+package prototest
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field appends an encoded field to the message.
+type Field func([]byte) []byte
+
+func Message(fields ...Field) []byte {
+	var b []byte
+	for _, f := range fields {
+		b = f(b)
+	}
+	return b
+}
+
+func Bytes(num protowire.Number, v []byte) Field {
+	return func(b []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, v)
+	}
+}
+
+func Varint(num protowire.Number, v uint64) Field {
+	return func(b []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		return protowire.AppendVarint(b, v)
+	}
+}
+
+func Fixed64(num protowire.Number, v uint64) Field {
+	return func(b []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, v)
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYaml encodes v as YAML using its JSON field names.
+func MarshalYaml(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(dropNulls(doc))
+}
+
+// UnmarshalYaml decodes a YAML document into v using its JSON field names.
+func UnmarshalYaml(data []byte, v any) error {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	data, err := json.Marshal(stringKeys(doc))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func stringKeys(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		for k, x := range vv {
+			vv[k] = stringKeys(x)
+		}
+		return vv
+	case map[any]any:
+		res := make(map[string]any, len(vv))
+		for k, x := range vv {
+			res[fmt.Sprint(k)] = stringKeys(x)
+		}
+		return res
+	case []any:
+		for i, x := range vv {
+			vv[i] = stringKeys(x)
+		}
+		return vv
+	}
+	return v
+}
+
+func dropNulls(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		for k, x := range vv {
+			if x == nil {
+				delete(vv, k)
+				continue
+			}
+			vv[k] = dropNulls(x)
+		}
+	case []any:
+		for i, x := range vv {
+			vv[i] = dropNulls(x)
+		}
+	}
+	return v
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestYaml(t *testing.T) {
+	type cfg struct {
+		Name      string             `json:"name"`
+		Threshold float32            `json:"threshold"`
+		Checks    map[string]float32 `json:"checks,omitempty"`
+		Tags      []string           `json:"tags"`
+	}
+	data, err := MarshalYaml(cfg{Name: "test", Threshold: 1.5, Checks: map[string]float32{"1": 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, "checks:\n    \"1\": 2\nname: test\nthreshold: 1.5\n", string(data))
+
+	var c cfg
+	assert.NoError(t, UnmarshalYaml([]byte("name: test\nthreshold: 2\nchecks:\n  1: 3\n"), &c))
+	assert.Equal(t, cfg{Name: "test", Threshold: 2, Checks: map[string]float32{"1": 3}}, c)
+}
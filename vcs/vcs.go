@@ -0,0 +1,122 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+)
+
+type Commit struct {
+	Sha     string
+	Message string
+	Author  string
+	Time    time.Time
+}
+
+// Commits returns the commits between two revisions of a GitHub or GitLab repository (including self-hosted instances)
+// in chronological order. The messages are truncated to the first line.
+func Commits(ctx context.Context, repoUrl, token string, gitlab bool, from, to string) ([]Commit, error) {
+	u, err := url.Parse(repoUrl)
+	if err != nil {
+		return nil, err
+	}
+	path := strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/")
+	if gitlab {
+		return gitlabCommits(ctx, u, path, token, from, to)
+	}
+	return githubCommits(ctx, u, path, token, from, to)
+}
+
+func githubCommits(ctx context.Context, u *url.URL, path, token, from, to string) ([]Commit, error) {
+	api := "https://api.github.com"
+	if u.Host != "github.com" {
+		api = u.Scheme + "://" + u.Host + "/api/v3"
+	}
+	var resp struct {
+		Commits []struct {
+			Sha    string `json:"sha"`
+			Commit struct {
+				Message string `json:"message"`
+				Author  struct {
+					Name string `json:"name"`
+				} `json:"author"`
+				Committer struct {
+					Date time.Time `json:"date"`
+				} `json:"committer"`
+			} `json:"commit"`
+		} `json:"commits"`
+	}
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	if err := getJson(ctx, fmt.Sprintf("%s/repos/%s/compare/%s...%s", api, path, from, to), headers, &resp); err != nil {
+		return nil, err
+	}
+	res := make([]Commit, 0, len(resp.Commits))
+	for _, c := range resp.Commits {
+		res = append(res, Commit{Sha: c.Sha, Message: firstLine(c.Commit.Message), Author: c.Commit.Author.Name, Time: c.Commit.Committer.Date})
+	}
+	return res, nil
+}
+
+func gitlabCommits(ctx context.Context, u *url.URL, path, token, from, to string) ([]Commit, error) {
+	var resp struct {
+		Commits []struct {
+			Id            string    `json:"id"`
+			Title         string    `json:"title"`
+			AuthorName    string    `json:"author_name"`
+			CommittedDate time.Time `json:"committed_date"`
+		} `json:"commits"`
+	}
+	headers := map[string]string{}
+	if token != "" {
+		headers["PRIVATE-TOKEN"] = token
+	}
+	q := url.Values{"from": {from}, "to": {to}}
+	uri := fmt.Sprintf("%s://%s/api/v4/projects/%s/repository/compare?%s", u.Scheme, u.Host, url.PathEscape(path), q.Encode())
+	if err := getJson(ctx, uri, headers, &resp); err != nil {
+		return nil, err
+	}
+	res := make([]Commit, 0, len(resp.Commits))
+	for _, c := range resp.Commits {
+		res = append(res, Commit{Sha: c.Id, Message: firstLine(c.Title), Author: c.AuthorName, Time: c.CommittedDate})
+	}
+	return res, nil
+}
+
+func getJson(ctx context.Context, uri string, headers map[string]string, dest any) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", uri, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+func firstLine(s string) string {
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,46 @@
+package cloudwatch
+
+import (
+	"context"
+	"github.com/coroot/coroot/aws"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/tsdb"
+	"github.com/coroot/coroot/watchers/poller"
+	"github.com/prometheus/prometheus/prompb"
+	"time"
+)
+
+// window is the period the metrics are requested for on each poll. It's longer than the polling interval,
+// so the periods missed by a failed poll are collected by the next ones (the ones already stored are skipped by the TSDB).
+// The periods CloudWatch may still update aren't collected at all (see aws.CollectResources).
+const window = 10 * time.Minute
+
+// Watcher periodically polls the CloudWatch metrics of the target groups, SQS queues and Lambda functions tagged with
+// the applications they belong to and stores them in the embedded TSDB. The agents can't see these managed components
+// from inside the pods, so the metrics are the only source of their reports.
+type Watcher struct {
+	*poller.Poller[db.IntegrationAWS, *aws.Client]
+}
+
+func NewWatcher(database *db.DB, tsdb *tsdb.Storage) *Watcher {
+	return &Watcher{poller.New(database, tsdb, "AWS resources", config, newClient, poll)}
+}
+
+func config(p *db.Project) *db.IntegrationAWS {
+	if cfg := p.Settings.Integrations.AWS; cfg != nil && cfg.ApplicationTag != "" {
+		return cfg
+	}
+	return nil
+}
+
+func newClient(cfg db.IntegrationAWS) (*aws.Client, error) {
+	return aws.NewClient(cfg.ClientConfig())
+}
+
+func poll(ctx context.Context, client *aws.Client, cfg db.IntegrationAWS) ([]prompb.TimeSeries, error) {
+	resources, err := client.Resources(ctx, cfg.ApplicationTag)
+	if err != nil {
+		return nil, err
+	}
+	return client.CollectResources(ctx, resources, window)
+}
@@ -0,0 +1,110 @@
+package deployments
+
+import (
+	"context"
+	"github.com/coroot/coroot/argocd"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+const (
+	argocdLookback      = 24 * timeseries.Hour
+	argocdSyncTolerance = 5 * timeseries.Minute
+)
+
+// enrichFromArgoCD matches recent deployments to the sync operations of the ArgoCD Applications
+// managing them and saves the synced revision and its author.
+func (w *Watcher) enrichFromArgoCD(project *db.Project, applications []*model.Application, now timeseries.Time) {
+	cfg := project.Settings.Integrations.ArgoCD
+	if cfg == nil {
+		return
+	}
+	var candidates []*model.ApplicationDeployment
+	for _, app := range applications {
+		for _, d := range app.Deployments {
+			if d.IsChange() || d.IsTrafficShift() || d.StartedAt.Before(now.Add(-argocdLookback)) || (d.Details != nil && d.Details.Sync != nil) {
+				continue
+			}
+			candidates = append(candidates, d)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	client := argocd.NewClient(cfg.Url, cfg.Token, cfg.TlsSkipVerify)
+	argoApps, err := client.Applications(ctx)
+	if err != nil {
+		klog.Errorln("failed to get ArgoCD applications:", err)
+		return
+	}
+	byAppId := map[model.ApplicationId]*argocd.Application{}
+	for i := range argoApps {
+		a := &argoApps[i]
+		for _, r := range a.Resources {
+			if kind := model.ApplicationKind(r.Kind); trackedKinds[kind] {
+				byAppId[model.NewApplicationId(r.Namespace, kind, r.Name)] = a
+			}
+		}
+	}
+
+	for _, d := range candidates {
+		a := byAppId[d.ApplicationId]
+		if a == nil {
+			continue
+		}
+		sync := matchSync(a.History, d.StartedAt, now)
+		if sync == nil {
+			continue
+		}
+		s := &model.ApplicationDeploymentSync{
+			Application: a.Name,
+			Revision:    sync.Revision,
+			Status:      sync.Status,
+			InitiatedBy: sync.InitiatedBy,
+			Automated:   sync.Automated,
+		}
+		if sync.Revision != "" {
+			if md, err := client.RevisionMetadata(ctx, a.Name, sync.Revision); err != nil {
+				klog.Warningln("failed to get revision metadata:", err)
+			} else {
+				s.Author, s.Message = md.Author, md.Message
+			}
+		}
+		if d.Details == nil {
+			d.Details = &model.ApplicationDeploymentDetails{}
+		}
+		d.Details.Sync = s
+		if err := w.db.SaveApplicationDeploymentDetails(project.Id, d); err != nil {
+			klog.Errorln("failed to save deployment details:", err)
+		}
+	}
+}
+
+// matchSync returns the sync operation that was running when the deployment started.
+// If there are several, the one that started closest to the deployment is chosen.
+func matchSync(history []argocd.Sync, startedAt, now timeseries.Time) *argocd.Sync {
+	var res *argocd.Sync
+	var best timeseries.Duration
+	for i := range history {
+		s := &history[i]
+		finishedAt := s.FinishedAt
+		if finishedAt.IsZero() {
+			finishedAt = now
+		}
+		if startedAt.Before(s.StartedAt.Add(-argocdSyncTolerance)) || startedAt.After(finishedAt.Add(argocdSyncTolerance)) {
+			continue
+		}
+		d := startedAt.Sub(s.StartedAt)
+		if d < 0 {
+			d = -d
+		}
+		if res == nil || d < best {
+			res, best = s, d
+		}
+	}
+	return res
+}
@@ -0,0 +1,60 @@
+package deployments
+
+import (
+	"context"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/vcs"
+	"k8s.io/klog"
+)
+
+const (
+	changelogLookback   = 24 * timeseries.Hour
+	changelogMaxCommits = 20
+)
+
+// fetchChangelogs saves the commits between the previous and the current versions of recent deployments
+// whose images are mapped to repositories.
+func (w *Watcher) fetchChangelogs(project *db.Project, applications []*model.Application, now timeseries.Time) {
+	repos := project.Settings.Repositories
+	if len(repos) == 0 {
+		return
+	}
+	for _, app := range applications {
+		var prev *model.ApplicationDeployment
+		for _, d := range app.Deployments {
+			if d.IsConfigOnly() || d.IsChange() {
+				continue
+			}
+			p := prev
+			prev = d
+			if p == nil || d.Details == nil || d.Details.Commits != nil || d.StartedAt.Before(now.Add(-changelogLookback)) {
+				continue
+			}
+			repo, rev := repos.Revision(d)
+			prevRepo, prevRev := repos.Revision(p)
+			if repo == nil || prevRepo == nil || repo.Id != prevRepo.Id || rev == prevRev {
+				continue
+			}
+			commits, err := vcs.Commits(context.Background(), repo.Url, repo.Token, repo.IsGitLab(), prevRev, rev)
+			if err != nil {
+				klog.Warningln("failed to get commits:", err)
+				continue
+			}
+			if len(commits) > changelogMaxCommits {
+				commits = commits[len(commits)-changelogMaxCommits:]
+			}
+			d.Details.Commits = make([]model.ApplicationDeploymentCommit, 0, len(commits))
+			for i := len(commits) - 1; i >= 0; i-- {
+				c := commits[i]
+				d.Details.Commits = append(d.Details.Commits, model.ApplicationDeploymentCommit{
+					Sha: c.Sha, Message: c.Message, Author: c.Author, Time: timeseries.Time(c.Time.Unix()),
+				})
+			}
+			if err := w.db.SaveApplicationDeploymentDetails(project.Id, d); err != nil {
+				klog.Errorln("failed to save deployment details:", err)
+			}
+		}
+	}
+}
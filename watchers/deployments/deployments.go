@@ -24,10 +24,20 @@ type Watcher struct {
 	db      *db.DB
 	cache   *cache.Cache
 	pricing *cloud_pricing.Manager
+
+	worldMemoryLimit int64
+}
+
+func NewWatcher(db *db.DB, cache *cache.Cache, pricing *cloud_pricing.Manager, worldMemoryLimit int64) *Watcher {
+	return &Watcher{db: db, cache: cache, pricing: pricing, worldMemoryLimit: worldMemoryLimit}
 }
 
-func NewWatcher(db *db.DB, cache *cache.Cache, pricing *cloud_pricing.Manager) *Watcher {
-	return &Watcher{db: db, cache: cache, pricing: pricing}
+// trackedKinds are the kinds of applications whose rollouts are tracked: Deployments through their ReplicaSets,
+// StatefulSets and DaemonSets through their controller revisions.
+var trackedKinds = map[model.ApplicationKind]bool{
+	model.ApplicationKindDeployment:  true,
+	model.ApplicationKindStatefulSet: true,
+	model.ApplicationKindDaemonSet:   true,
 }
 
 func (w *Watcher) Start(interval time.Duration) {
@@ -43,6 +53,10 @@ func (w *Watcher) Start(interval time.Duration) {
 				if world == nil {
 					continue
 				}
+				w.enrichFromArgoCD(project, world.Applications, cacheTo)
+				w.fetchChangelogs(project, world.Applications, cacheTo)
+				w.linkPipelines(project, world.Applications, cacheTo)
+				w.saveSchemaChanges(project.Id, world, cacheTo)
 				w.snapshotDeploymentMetrics(project, world.Applications)
 				w.sendNotifications(project, world, cacheTo)
 			}
@@ -65,14 +79,14 @@ func (w *Watcher) discoverAndSaveDeployments(project *db.Project) (*model.World,
 	step := project.Prometheus.RefreshInterval
 	to := cacheTo
 	from := to.Add(-timeseries.Hour)
-	world, err := constructor.New(w.db, project, cacheClient, w.pricing).LoadWorld(context.Background(), from, to, step, nil)
+	world, err := constructor.New(w.db, project, cacheClient, w.pricing).WithMemoryLimit(w.worldMemoryLimit).LoadWorld(context.Background(), from, to, step, nil)
 	if err != nil {
 		klog.Errorln("failed to load world:", err)
 		return nil, cacheTo
 	}
 
 	for _, app := range world.Applications {
-		if app.Id.Kind != model.ApplicationKindDeployment {
+		if !trackedKinds[app.Id.Kind] {
 			continue
 		}
 		apps++
@@ -93,6 +107,9 @@ func (w *Watcher) discoverAndSaveDeployments(project *db.Project) (*model.World,
 					break
 				}
 			}
+			if known == nil {
+				w.mergeConfigOnlyDeployment(project.Id, app, d)
+			}
 			if known == nil || known.FinishedAt != d.FinishedAt {
 				if err := w.db.SaveApplicationDeployment(project.Id, d); err != nil {
 					klog.Errorln("failed to save deployment:", err)
@@ -104,10 +121,111 @@ func (w *Watcher) discoverAndSaveDeployments(project *db.Project) (*model.World,
 				app.Deployments = append(app.Deployments, d)
 			}
 		}
+		w.saveHelmUpgrades(project.Id, app, world.Ctx.From)
 	}
+	w.saveTrafficShifts(project.Id, calcTrafficShifts(world.Applications))
 	return world, cacheTo
 }
 
+func (w *Watcher) saveTrafficShifts(projectId db.ProjectId, shifts map[*model.Application][]*model.ApplicationDeployment) {
+	for app, deployments := range shifts {
+		var known []*model.ApplicationDeployment
+		for _, d := range app.Deployments {
+			if d.IsTrafficShift() {
+				known = append(known, d)
+			}
+		}
+		for _, d := range deployments {
+			if model.FindApplicationDeployment(known, d.StartedAt) != nil {
+				continue
+			}
+			if err := w.db.SaveApplicationDeployment(projectId, d); err != nil {
+				klog.Errorln("failed to save deployment:", err)
+				return
+			}
+			klog.Infof("traffic shift detected for %s: %s", app.Id, d.Version())
+			app.Deployments = append(app.Deployments, d)
+		}
+	}
+}
+
+// saveHelmUpgrades records the Helm release revisions that didn't roll out a new ReplicaSet (e.g., only a ConfigMap
+// or a Service was changed) as config-only deployments.
+func (w *Watcher) saveHelmUpgrades(projectId db.ProjectId, app *model.Application, from timeseries.Time) {
+	if len(app.Deployments) == 0 {
+		return
+	}
+	var release *model.HelmRelease
+	for _, i := range app.Instances {
+		if i.Pod != nil && i.Pod.Helm != nil {
+			release = i.Pod.Helm
+		}
+	}
+	if release == nil {
+		return
+	}
+	for _, r := range app.HelmRevisions {
+		if r.CreatedAt.Before(from) || model.FindApplicationDeployment(app.Deployments, r.CreatedAt) != nil {
+			continue
+		}
+		d := &model.ApplicationDeployment{
+			ApplicationId: app.Id,
+			Name:          fmt.Sprintf("helm-%s-%d", release.Name, r.Revision),
+			StartedAt:     r.CreatedAt,
+			FinishedAt:    r.CreatedAt,
+			Details: &model.ApplicationDeploymentDetails{
+				ConfigOnly: true,
+				Helm: &model.ApplicationDeploymentHelm{
+					Release:    release.Name,
+					Chart:      release.Chart,
+					Revision:   r.Revision,
+					ValuesHash: release.ValuesHash,
+				},
+			},
+		}
+		if err := w.db.SaveApplicationDeployment(projectId, d); err != nil {
+			klog.Errorln("failed to save deployment:", err)
+			return
+		}
+		klog.Infof("helm release upgrade detected for %s: %s", app.Id, d.Name)
+		app.Deployments = append(app.Deployments, d)
+	}
+}
+
+// mergeConfigOnlyDeployment replaces the deployment recorded from a GitOps sync (see api.FluxEvent) or a Helm release upgrade
+// with the rollout caused by the same change, which is discovered later from the ReplicaSet metrics.
+func (w *Watcher) mergeConfigOnlyDeployment(projectId db.ProjectId, app *model.Application, d *model.ApplicationDeployment) {
+	var configOnly []*model.ApplicationDeployment
+	for _, dd := range app.Deployments {
+		if dd.IsConfigOnly() {
+			configOnly = append(configOnly, dd)
+		}
+	}
+	c := model.FindApplicationDeployment(configOnly, d.StartedAt)
+	if c == nil {
+		return
+	}
+	if err := w.db.DeleteApplicationDeployment(projectId, c); err != nil {
+		klog.Errorln("failed to delete deployment:", err)
+		return
+	}
+	if d.Details == nil {
+		d.Details = &model.ApplicationDeploymentDetails{}
+	}
+	if c.Details.Sync != nil {
+		d.Details.Sync = c.Details.Sync
+	}
+	if c.Details.Helm != nil && d.Details.Helm == nil {
+		d.Details.Helm = c.Details.Helm
+	}
+	for i, dd := range app.Deployments {
+		if dd == c {
+			app.Deployments = append(app.Deployments[:i], app.Deployments[i+1:]...)
+			break
+		}
+	}
+}
+
 func (w *Watcher) snapshotDeploymentMetrics(project *db.Project, applications []*model.Application) {
 	if len(applications) == 0 {
 		return
@@ -132,7 +250,7 @@ func (w *Watcher) snapshotDeploymentMetrics(project *db.Project, applications []
 			if to.After(nextOrNow) {
 				continue
 			}
-			world, err := constructor.New(w.db, project, cacheClient, w.pricing).LoadWorld(context.Background(), from, to, step, nil)
+			world, err := constructor.New(w.db, project, cacheClient, w.pricing).WithMemoryLimit(w.worldMemoryLimit).LoadWorld(context.Background(), from, to, step, nil)
 			if err != nil {
 				klog.Errorln("failed to load world:", err)
 				continue
@@ -154,11 +272,17 @@ func (w *Watcher) snapshotDeploymentMetrics(project *db.Project, applications []
 func (w *Watcher) sendNotifications(project *db.Project, world *model.World, now timeseries.Time) {
 	integrations := project.Settings.Integrations
 	categorySettings := project.Settings.ApplicationCategorySettings
+	quiet := project.QuietHoursActive(now)
 	for _, app := range world.Applications {
+		statuses := model.CalcApplicationDeploymentStatuses(app, world.CheckConfigs, now)
+		project.Settings.MaintenanceWindows.ApplyToDeployments(statuses)
+		if n := len(statuses); n > 0 {
+			w.sendRollbackRecommendation(project, statuses[n-1], now)
+		}
 		if !categorySettings[app.Category].NotifyOfDeployments {
 			continue
 		}
-		for _, ds := range model.CalcApplicationDeploymentStatuses(app, world.CheckConfigs, now) {
+		for _, ds := range statuses {
 			d := ds.Deployment
 			if now.Sub(d.StartedAt) > timeseries.Day {
 				continue
@@ -169,12 +293,12 @@ func (w *Watcher) sendNotifications(project *db.Project, world *model.World, now
 			if d.Notifications.State >= ds.State {
 				continue
 			}
+			if quiet && ds.State != model.ApplicationDeploymentStateRollbackRecommended {
+				continue // held until the end of the quiet hours
+			}
 			needSave := false
 			if cfg := integrations.Slack; cfg != nil && cfg.Deployments && d.Notifications.Slack.State < ds.State {
-				client := notifications.NewSlack(cfg.Token, cfg.DefaultChannel)
-				ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
-				err := client.SendDeployment(ctx, project, ds)
-				cancel()
+				err := w.sendDeployment(project, db.IntegrationTypeSlack, notifications.NewSlack(cfg.Token, cfg.DefaultChannel), ds)
 				if err != nil {
 					klog.Errorln(err)
 				} else {
@@ -183,10 +307,7 @@ func (w *Watcher) sendNotifications(project *db.Project, world *model.World, now
 				}
 			}
 			if cfg := integrations.Teams; cfg != nil && cfg.Deployments && d.Notifications.Teams.State < ds.State {
-				client := notifications.NewTeams(cfg.WebhookUrl)
-				ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
-				err := client.SendDeployment(ctx, project, ds)
-				cancel()
+				err := w.sendDeployment(project, db.IntegrationTypeTeams, notifications.NewTeams(cfg.WebhookUrl), ds)
 				if err != nil {
 					klog.Errorln(err)
 				} else {
@@ -194,6 +315,42 @@ func (w *Watcher) sendNotifications(project *db.Project, world *model.World, now
 					needSave = true
 				}
 			}
+			if cfg := integrations.Telegram; cfg != nil && cfg.Deployments && d.Notifications.Telegram.State < ds.State {
+				err := w.sendDeployment(project, db.IntegrationTypeTelegram, notifications.NewTelegram(cfg.BotToken, cfg.ChatId), ds)
+				if err != nil {
+					klog.Errorln(err)
+				} else {
+					d.Notifications.Telegram.State = ds.State
+					needSave = true
+				}
+			}
+			if cfg := integrations.Discord; cfg != nil && cfg.Deployments && d.Notifications.Discord.State < ds.State {
+				err := w.sendDeployment(project, db.IntegrationTypeDiscord, notifications.NewDiscord(cfg.WebhookUrl), ds)
+				if err != nil {
+					klog.Errorln(err)
+				} else {
+					d.Notifications.Discord.State = ds.State
+					needSave = true
+				}
+			}
+			if cfg := integrations.Mattermost; cfg != nil && cfg.Deployments && d.Notifications.Mattermost.State < ds.State {
+				err := w.sendDeployment(project, db.IntegrationTypeMattermost, notifications.NewMattermost(cfg.WebhookUrl, cfg.Channel), ds)
+				if err != nil {
+					klog.Errorln(err)
+				} else {
+					d.Notifications.Mattermost.State = ds.State
+					needSave = true
+				}
+			}
+			if cfg := integrations.Email; cfg != nil && cfg.Deployments && d.Notifications.Email.State < ds.State {
+				err := w.sendDeployment(project, db.IntegrationTypeEmail, notifications.NewEmail(*cfg), ds)
+				if err != nil {
+					klog.Errorln(err)
+				} else {
+					d.Notifications.Email.State = ds.State
+					needSave = true
+				}
+			}
 			if !needSave {
 				continue
 			}
@@ -204,6 +361,53 @@ func (w *Watcher) sendNotifications(project *db.Project, world *model.World, now
 	}
 }
 
+type deploymentNotifier interface {
+	SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error
+}
+
+func (w *Watcher) sendDeployment(project *db.Project, destination db.IntegrationType, client deploymentNotifier, ds model.ApplicationDeploymentStatus) error {
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	err := client.SendDeployment(ctx, project, ds)
+	notifications.LogDelivery(w.db, db.NotificationDelivery{
+		ProjectId:     project.Id,
+		Kind:          db.NotificationKindDeployment,
+		Destination:   string(destination),
+		ApplicationId: ds.Deployment.ApplicationId.String(),
+		Subject:       fmt.Sprintf("%s: %s", ds.Deployment.Version(), ds.State),
+	}, started, err)
+	return err
+}
+
+func (w *Watcher) sendRollbackRecommendation(project *db.Project, ds model.ApplicationDeploymentStatus, now timeseries.Time) {
+	d := ds.Deployment
+	if ds.State != model.ApplicationDeploymentStateRollbackRecommended || now.Sub(d.StartedAt) > timeseries.Day {
+		return
+	}
+	if d.Notifications == nil {
+		d.Notifications = &model.ApplicationDeploymentNotifications{}
+	}
+	if d.Notifications.Webhooks.State >= ds.State {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	sent, err := notifications.SendRollbackRecommendation(ctx, w.db, project, ds)
+	cancel()
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	if !sent {
+		return
+	}
+	klog.Infof("%s: rollback of %s recommended", project.Id, d.Name)
+	d.Notifications.Webhooks.State = ds.State
+	if err := w.db.SaveApplicationDeploymentNotifications(project.Id, d); err != nil {
+		klog.Errorln(err)
+	}
+}
+
 func (w *Watcher) getCacheClient(project *db.Project) (*cache.Client, timeseries.Time, error) {
 	cc := w.cache.GetCacheClient(project)
 	cacheTo, err := cc.GetTo()
@@ -217,17 +421,22 @@ func (w *Watcher) getCacheClient(project *db.Project) (*cache.Client, timeseries
 }
 
 func calcDeployments(app *model.Application) []*model.ApplicationDeployment {
-	if app.Id.Kind != model.ApplicationKindDeployment || len(app.Instances) == 0 {
+	if !trackedKinds[app.Id.Kind] || len(app.Instances) == 0 {
 		return nil
 	}
+	if app.Id.Kind == model.ApplicationKindStatefulSet && len(app.UpdateRevisions) > 0 {
+		return calcStatefulSetDeployments(app)
+	}
 
 	lifeSpans := map[string]*timeseries.Aggregate{}
 	images := map[string]*utils.StringSet{}
+	helm := map[string]*model.HelmRelease{}
+	limits := map[string]map[string]*model.ApplicationDeploymentLimits{}
 	for _, instance := range app.Instances {
-		if instance.Pod == nil || instance.Pod.ReplicaSet == "" {
+		if instance.Pod == nil || instance.Pod.Revision() == "" {
 			continue
 		}
-		rs := instance.Pod.ReplicaSet
+		rs := instance.Pod.Revision()
 		ts := lifeSpans[rs]
 		if ts == nil {
 			ts = timeseries.NewAggregate(timeseries.NanSum)
@@ -239,6 +448,15 @@ func calcDeployments(app *model.Application) []*model.ApplicationDeployment {
 		}
 		for _, container := range instance.Containers {
 			images[rs].Add(container.Image)
+			if l := containerLimits(container); l != nil {
+				if limits[rs] == nil {
+					limits[rs] = map[string]*model.ApplicationDeploymentLimits{}
+				}
+				limits[rs][container.Name] = l
+			}
+		}
+		if instance.Pod.Helm != nil {
+			helm[rs] = instance.Pod.Helm
 		}
 	}
 	if len(lifeSpans) == 0 {
@@ -329,17 +547,122 @@ func calcDeployments(app *model.Application) []*model.ApplicationDeployment {
 				ContainerImages: images[d.Name].Items(),
 			}
 		}
+		if l := limits[d.Name]; l != nil && d.Details != nil {
+			d.Details.Limits = l
+		}
+		if h := helm[d.Name]; h != nil {
+			if d.Details == nil {
+				d.Details = &model.ApplicationDeploymentDetails{}
+			}
+			d.Details.Helm = &model.ApplicationDeploymentHelm{
+				Release:    h.Name,
+				Chart:      h.Chart,
+				Revision:   model.FindHelmReleaseRevision(app.HelmRevisions, d.StartedAt),
+				ValuesHash: h.ValuesHash,
+			}
+		}
+	}
+
+	return deployments
+}
+
+// calcStatefulSetDeployments detects the rollouts of a StatefulSet by the changes of its update revision.
+// A rollout is finished when all the replicas are updated, so a partitioned rollout stays in progress until
+// the partition is lowered to zero.
+func calcStatefulSetDeployments(app *model.Application) []*model.ApplicationDeployment {
+	// the revisions are sorted, so that the result doesn't depend on the map iteration order when several revisions overlap
+	revisions := make([]string, 0, len(app.UpdateRevisions))
+	for r := range app.UpdateRevisions {
+		revisions = append(revisions, r)
+	}
+	sort.Strings(revisions)
+	iters := make([]*timeseries.Iterator, 0, len(revisions))
+	for _, r := range revisions {
+		iters = append(iters, app.UpdateRevisions[r].Iter())
+	}
+	updated, desired := app.UpdatedInstances.Iter(), app.DesiredInstances.Iter()
+
+	var deployments []*model.ApplicationDeployment
+	var deployment *model.ApplicationDeployment
+	prev := ""
+	for {
+		var t timeseries.Time
+		curr := ""
+		done := true
+		for i, iter := range iters {
+			if !iter.Next() {
+				continue
+			}
+			done = false
+			var v float32
+			t, v = iter.Value()
+			if v > 0 {
+				curr = revisions[i]
+			}
+		}
+		if done {
+			break
+		}
+		u, d := float32(timeseries.NaN), float32(timeseries.NaN)
+		if updated.Next() {
+			_, u = updated.Value()
+		}
+		if desired.Next() {
+			_, d = desired.Value()
+		}
+		if curr == "" {
+			continue
+		}
+		if prev != "" && curr != prev {
+			deployment = &model.ApplicationDeployment{ApplicationId: app.Id, Name: curr, StartedAt: t}
+			deployments = append(deployments, deployment)
+		}
+		prev = curr
+		if deployment != nil && deployment.Name == curr && (app.UpdatedInstances.IsEmpty() || u >= d) {
+			deployment.FinishedAt = t
+			deployment = nil
+		}
 	}
 
+	for _, d := range deployments {
+		images := utils.NewStringSet()
+		for _, i := range app.Instances {
+			if i.Pod == nil || i.Pod.ControllerRevision != d.Name {
+				continue
+			}
+			for _, c := range i.Containers {
+				images.Add(c.Image)
+			}
+		}
+		if images.Len() > 0 {
+			d.Details = &model.ApplicationDeploymentDetails{ContainerImages: images.Items()}
+		}
+	}
 	return deployments
 }
 
+func containerLimits(c *model.Container) *model.ApplicationDeploymentLimits {
+	_, cpu := c.CpuLimit.LastNotNull()
+	_, memory := c.MemoryLimit.LastNotNull()
+	l := &model.ApplicationDeploymentLimits{}
+	if cpu > 0 {
+		l.Cpu = cpu
+	}
+	if memory > 0 {
+		l.Memory = memory
+	}
+	if *l == (model.ApplicationDeploymentLimits{}) {
+		return nil
+	}
+	return l
+}
+
 func calcInitialDeployment(app *model.Application, now timeseries.Time) *model.ApplicationDeployment {
 	name := ""
 	images := utils.NewStringSet()
 	for _, i := range app.Instances {
-		if i.Pod != nil && i.Pod.ReplicaSet != "" {
-			name = i.Pod.ReplicaSet
+		if i.Pod != nil && i.Pod.Revision() != "" {
+			name = i.Pod.Revision()
 		}
 		for _, c := range i.Containers {
 			if c.Image != "" {
@@ -364,9 +687,11 @@ func calcInitialDeployment(app *model.Application, now timeseries.Time) *model.A
 
 func calcMetricsSnapshot(app *model.Application, from, to timeseries.Time, step timeseries.Duration) *model.MetricsSnapshot {
 	ms := model.MetricsSnapshot{Timestamp: to, Duration: to.Sub(from), Latency: map[string]int64{}}
+	var requests *timeseries.TimeSeries
 	for _, sli := range app.AvailabilitySLIs {
 		ms.Requests = sumR(sli.TotalRequests, step)
 		ms.Errors = sumR(sli.FailedRequests, step)
+		requests = sli.TotalRequests
 		break
 	}
 	for _, sli := range app.LatencySLIs {
@@ -405,6 +730,25 @@ func calcMetricsSnapshot(app *model.Application, from, to timeseries.Time, step
 	ms.Restarts = sum(restarts.Get())
 	ms.LogErrors = sum(logErrors.Get())
 	ms.LogWarnings = sum(logWarnings.Get())
+
+	w := timeseries.Window{From: from, To: to}
+	perRequest := func(ts *timeseries.TimeSeries, perSecond bool) *timeseries.WindowStats {
+		s := w.Stats(timeseries.Aggregate2(ts, requests, func(v, rps float32) float32 {
+			if rps <= 0 {
+				return timeseries.NaN
+			}
+			if !perSecond { // the values are the numbers of events per step
+				rps *= float32(step / timeseries.Second)
+			}
+			return v / rps
+		}))
+		if s.Count == 0 {
+			return nil
+		}
+		return &s
+	}
+	ms.CPUUsagePerRequest = perRequest(cpuUsage.Get(), true)
+	ms.LogErrorsPerRequest = perRequest(logErrors.Get(), false)
 	return &ms
 }
 
@@ -92,3 +92,104 @@ func TestCalcDeployments(t *testing.T) {
 	addInstance("i2", "rs2", 0, 0, 1, 1, 0, 0)
 	checkDeployments("3-0:rs2;5-5:rs1")
 }
+
+func TestCalcDeploymentsHelm(t *testing.T) {
+	app := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog"))
+	app.HelmRevisions = []model.HelmReleaseRevision{{Revision: 1, CreatedAt: 1}, {Revision: 2, CreatedAt: 4}, {Revision: 3, CreatedAt: 1000}}
+	i1 := app.GetOrCreateInstance("i1", nil)
+	i1.Pod = &model.Pod{ReplicaSet: "rs1", Helm: &model.HelmRelease{Name: "catalog", Chart: "catalog-1.0.0"}}
+	i1.Pod.LifeSpan = timeseries.NewWithData(1, 1, []float32{1, 1, 1, 0, 0, 0})
+	i2 := app.GetOrCreateInstance("i2", nil)
+	i2.Pod = &model.Pod{ReplicaSet: "rs2", Helm: &model.HelmRelease{Name: "catalog", Chart: "catalog-1.1.0", ValuesHash: "abcd1234"}}
+	i2.Pod.LifeSpan = timeseries.NewWithData(1, 1, []float32{0, 0, 0, 1, 1, 1})
+
+	deployments := calcDeployments(app)
+	assert.Len(t, deployments, 1)
+	assert.Equal(t, &model.ApplicationDeploymentHelm{Release: "catalog", Chart: "catalog-1.1.0", Revision: 2, ValuesHash: "abcd1234"}, deployments[0].Details.Helm)
+	assert.Equal(t, "rs2 (helm: catalog-1.1.0 r2)", deployments[0].Version())
+}
+
+func TestDetectTrafficShifts(t *testing.T) {
+	points := func(shares ...float32) []trafficPoint {
+		var res []trafficPoint
+		for i, s := range shares {
+			res = append(res, trafficPoint{t: timeseries.Time(i), shares: []float32{s, 1 - s}})
+		}
+		return res
+	}
+	assert.Equal(t, [][2]int{{1, 2}}, detectShifts(points(0, 0, 1, 1), 0))             // cutover
+	assert.Equal(t, [][2]int{{0, 3}}, detectShifts(points(0, 0.1, 0.25, 0.5, 0.5), 0)) // gradual weight shift
+	assert.Nil(t, detectShifts(points(0, 0, 1, 1), 1))
+	assert.Nil(t, detectShifts(points(0.5, 0.52, 0.49, 0.51), 0))
+	assert.Nil(t, detectShifts(points(0, 0, 0.5, 1), 0)) // still in progress
+
+	assert.Equal(t, "catalog", variantBase("catalog-green"))
+	assert.Equal(t, "catalog", variantBase("catalog-v2"))
+	assert.Equal(t, "", variantBase("catalog-api"))
+}
+
+func TestCalcDeploymentsStatefulSet(t *testing.T) {
+	app := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindStatefulSet, "db"))
+	app.GetOrCreateInstance("db-0", nil).Pod = &model.Pod{ControllerRevision: "db-rev2"}
+	app.UpdateRevisions = map[string]*timeseries.TimeSeries{
+		"db-rev1": timeseries.NewWithData(1, 1, []float32{1, 1, 0, 0, 0, 0}),
+		"db-rev2": timeseries.NewWithData(1, 1, []float32{0, 0, 1, 1, 1, 1}),
+	}
+	app.DesiredInstances = timeseries.NewWithData(1, 1, []float32{2, 2, 2, 2, 2, 2})
+
+	app.UpdatedInstances = timeseries.NewWithData(1, 1, []float32{2, 2, 0, 1, 2, 2})
+	deployments := calcDeployments(app)
+	assert.Len(t, deployments, 1)
+	assert.Equal(t, "db-rev2", deployments[0].Name)
+	assert.Equal(t, timeseries.Time(3), deployments[0].StartedAt)
+	assert.Equal(t, timeseries.Time(5), deployments[0].FinishedAt)
+
+	// partitioned rollout
+	app.UpdatedInstances = timeseries.NewWithData(1, 1, []float32{2, 2, 0, 1, 1, 1})
+	deployments = calcDeployments(app)
+	assert.Len(t, deployments, 1)
+	assert.True(t, deployments[0].FinishedAt.IsZero())
+
+	// overlapping revisions
+	app.UpdateRevisions["db-rev3"] = timeseries.NewWithData(1, 1, []float32{0, 0, 1, 1, 1, 1})
+	app.UpdatedInstances = timeseries.NewWithData(1, 1, []float32{2, 2, 0, 1, 2, 2})
+	for i := 0; i < 20; i++ {
+		deployments = calcDeployments(app)
+		assert.Len(t, deployments, 1)
+		assert.Equal(t, "db-rev3", deployments[0].Name)
+	}
+}
+
+func TestCalcDeploymentsDaemonSet(t *testing.T) {
+	app := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindDaemonSet, "agent"))
+	addInstance := func(name string, revision string, lifeSpan ...float32) {
+		i := app.GetOrCreateInstance(name, nil)
+		i.Pod = &model.Pod{ControllerRevision: revision}
+		i.Pod.LifeSpan = timeseries.NewWithData(1, 1, lifeSpan)
+	}
+	addInstance("agent-abcde", "agent-rev1", 1, 1, 1, 0, 0, 0)
+	addInstance("agent-fghij", "agent-rev2", 0, 0, 0, 1, 1, 1)
+	deployments := calcDeployments(app)
+	assert.Len(t, deployments, 1)
+	assert.Equal(t, "agent-rev2", deployments[0].Name)
+}
+
+func TestCalcMetricsSnapshotPerRequest(t *testing.T) {
+	app := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog"))
+	app.AvailabilitySLIs = []*model.AvailabilitySLI{{TotalRequests: timeseries.NewWithData(0, 60, []float32{10, 20, 0, 10})}}
+	i := app.GetOrCreateInstance("catalog-1", nil)
+	c := i.GetOrCreateContainer("", "app")
+	c.CpuUsage = timeseries.NewWithData(0, 60, []float32{1, 2, 1, timeseries.NaN})
+	i.LogMessagesByLevel[model.LogLevelError] = timeseries.NewWithData(0, 60, []float32{60, 60, 60, 60})
+
+	ms := calcMetricsSnapshot(app, 0, 180, 60)
+	assert.Equal(t, int64(2400), ms.Requests)
+	assert.Equal(t, &timeseries.WindowStats{Mean: 0.1, Count: 2}, ms.CPUUsagePerRequest, "the steps without requests or CPU usage are skipped")
+	assert.Equal(t, 3, ms.LogErrorsPerRequest.Count)
+	assert.InDelta(t, 0.0833, ms.LogErrorsPerRequest.Mean, 0.0001)
+
+	app.AvailabilitySLIs = nil
+	ms = calcMetricsSnapshot(app, 0, 180, 60)
+	assert.Nil(t, ms.CPUUsagePerRequest)
+	assert.Nil(t, ms.LogErrorsPerRequest)
+}
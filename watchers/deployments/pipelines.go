@@ -0,0 +1,59 @@
+package deployments
+
+import (
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+const (
+	pipelinesLookback = 24 * timeseries.Hour
+	pipelinesTTL      = 7 * timeseries.Day
+)
+
+// linkPipelines links recent deployments to the CI pipeline runs (see api.CIEventV1) of the revisions they deployed.
+// Pipeline runs are kept for pipelinesTTL, so older builds that are deployed again can be linked too.
+func (w *Watcher) linkPipelines(project *db.Project, applications []*model.Application, now timeseries.Time) {
+	var candidates []*model.ApplicationDeployment
+	for _, app := range applications {
+		for _, d := range app.Deployments {
+			if d.IsChange() || d.Details == nil || d.Details.Pipeline != nil || d.StartedAt.Before(now.Add(-pipelinesLookback)) {
+				continue
+			}
+			candidates = append(candidates, d)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	if err := w.db.DeleteCIPipelines(project.Id, now.Add(-pipelinesTTL)); err != nil {
+		klog.Errorln("failed to delete pipelines:", err)
+	}
+	pipelines, err := w.db.GetCIPipelines(project.Id, now.Add(-pipelinesTTL))
+	if err != nil {
+		klog.Errorln("failed to get pipelines:", err)
+		return
+	}
+	if len(pipelines) == 0 {
+		return
+	}
+	for _, d := range candidates {
+		sha := project.Settings.Repositories.CommitSha(d)
+		if sha == "" {
+			continue
+		}
+		for _, p := range pipelines {
+			if p.MatchesRevision(sha) && !p.FinishedAt.After(d.StartedAt.Add(model.ApplicationDeploymentSyncTolerance)) {
+				d.Details.Pipeline = p
+				break
+			}
+		}
+		if d.Details.Pipeline == nil {
+			continue
+		}
+		if err := w.db.SaveApplicationDeploymentDetails(project.Id, d); err != nil {
+			klog.Errorln("failed to save deployment details:", err)
+		}
+	}
+}
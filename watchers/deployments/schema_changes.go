@@ -0,0 +1,31 @@
+package deployments
+
+import (
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+// saveSchemaChanges saves the number of schema changes applied around each deployment once the window after
+// the deployment is over. Deployments whose window isn't covered by the loaded metrics are skipped.
+func (w *Watcher) saveSchemaChanges(projectId db.ProjectId, world *model.World, now timeseries.Time) {
+	for _, app := range world.Applications {
+		for _, d := range app.Deployments {
+			if d.IsTrafficShift() || d.FinishedAt.IsZero() || (d.Details != nil && d.Details.SchemaChanges != nil) {
+				continue
+			}
+			window := timeseries.Window{From: d.StartedAt.Add(-model.SchemaChangesWindow), To: d.FinishedAt.Add(model.SchemaChangesWindow)}
+			if window.From.Before(world.Ctx.From) || window.To.After(now) {
+				continue
+			}
+			if d.Details == nil {
+				d.Details = &model.ApplicationDeploymentDetails{}
+			}
+			d.Details.SchemaChanges = model.CalcSchemaChanges(world, app, window)
+			if err := w.db.SaveApplicationDeploymentDetails(projectId, d); err != nil {
+				klog.Errorln("failed to save deployment details:", err)
+			}
+		}
+	}
+}
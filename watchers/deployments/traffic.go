@@ -0,0 +1,196 @@
+package deployments
+
+import (
+	"fmt"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	trafficShiftMinRate   = 0.1  // requests (or connections) per second
+	trafficShiftMinStep   = 0.05 // the minimum change of the share between two points to be considered as a part of a shift
+	trafficShiftMinChange = 0.2
+)
+
+var (
+	variantSuffixes = map[string]bool{"blue": true, "green": true, "canary": true, "stable": true, "primary": true, "preview": true}
+	versionSuffix   = regexp.MustCompile(`^v\d+$`)
+)
+
+type trafficPoint struct {
+	t      timeseries.Time
+	shares []float32
+}
+
+// calcTrafficShifts detects shifts of traffic between the variants of a service: Deployments receiving requests
+// through the same Kubernetes Service or named after the same service with a variant suffix (e.g., app-blue and app-green).
+// Shifts are detected from the observed traffic, so blue/green cutovers, Istio or ALB weight changes are detected alike.
+// A shift is recorded as a deployment of the application whose share of the traffic increased.
+func calcTrafficShifts(applications []*model.Application) map[*model.Application][]*model.ApplicationDeployment {
+	groups := map[string][]*model.Application{}
+	for _, app := range applications {
+		if app.Id.Kind != model.ApplicationKindDeployment {
+			continue
+		}
+		keys := map[string]bool{}
+		for _, c := range app.Downstreams {
+			if c.ServiceRemoteIP != "" && c.Instance.OwnerId != app.Id {
+				keys["svc:"+c.ServiceRemoteIP+":"+c.ServiceRemotePort] = true
+			}
+		}
+		if base := variantBase(app.Id.Name); base != "" {
+			keys["name:"+app.Id.Namespace+"/"+base] = true
+		}
+		for k := range keys {
+			groups[k] = append(groups[k], app)
+		}
+	}
+
+	res := map[*model.Application][]*model.ApplicationDeployment{}
+	seen := map[string]bool{}
+	for _, apps := range groups {
+		if len(apps) < 2 {
+			continue
+		}
+		sort.Slice(apps, func(i, j int) bool { return apps[i].Id.String() < apps[j].Id.String() })
+		var ids []string
+		for _, app := range apps {
+			ids = append(ids, app.Id.String())
+		}
+		key := strings.Join(ids, ",")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		points := trafficShares(apps)
+		for i, app := range apps {
+			for _, s := range detectShifts(points, i) {
+				start, end := points[s[0]], points[s[1]]
+				shift := &model.ApplicationTrafficShift{ShareBefore: start.shares[i], ShareAfter: end.shares[i]}
+				var maxDecrease float32
+				for j, other := range apps {
+					if decrease := start.shares[j] - end.shares[j]; j != i && decrease > maxDecrease {
+						shift.From, maxDecrease = other.Id, decrease
+					}
+				}
+				if shift.From.IsZero() {
+					continue
+				}
+				res[app] = append(res[app], &model.ApplicationDeployment{
+					ApplicationId: app.Id,
+					Name:          fmt.Sprintf("traffic-%d", start.t),
+					StartedAt:     start.t,
+					FinishedAt:    end.t,
+					Details:       &model.ApplicationDeploymentDetails{TrafficShift: shift},
+				})
+			}
+		}
+	}
+	return res
+}
+
+func variantBase(name string) string {
+	i := strings.LastIndex(name, "-")
+	if i <= 0 {
+		return ""
+	}
+	if suffix := name[i+1:]; variantSuffixes[suffix] || versionSuffix.MatchString(suffix) {
+		return name[:i]
+	}
+	return ""
+}
+
+// trafficShares returns the shares of the inbound traffic of the applications at the points
+// where the total traffic is significant. Requests are compared if all the applications are instrumented
+// to report them, otherwise new connections are compared.
+func trafficShares(apps []*model.Application) []trafficPoint {
+	requests := make([]*timeseries.TimeSeries, len(apps))
+	connects := make([]*timeseries.TimeSeries, len(apps))
+	withRequests := true
+	for i, app := range apps {
+		requests[i], connects[i] = inboundTraffic(app)
+		if !(requests[i].Reduce(timeseries.NanSum) > 0) {
+			withRequests = false
+		}
+	}
+	series := connects
+	if withRequests {
+		series = requests
+	}
+	iters := make([]*timeseries.Iterator, len(apps))
+	for i, ts := range series {
+		if ts.IsEmpty() {
+			continue
+		}
+		iters[i] = ts.Iter()
+	}
+	var res []trafficPoint
+	for {
+		p := trafficPoint{shares: make([]float32, len(apps))}
+		var total float32
+		next := false
+		for i, iter := range iters {
+			if iter == nil || !iter.Next() {
+				continue
+			}
+			next = true
+			t, v := iter.Value()
+			if timeseries.IsNaN(v) {
+				v = 0
+			}
+			p.t, p.shares[i] = t, v
+			total += v
+		}
+		if !next {
+			return res
+		}
+		if total < trafficShiftMinRate {
+			continue
+		}
+		for i := range p.shares {
+			p.shares[i] /= total
+		}
+		res = append(res, p)
+	}
+}
+
+// inboundTraffic returns the requests and the new connections per second received by the application from its clients.
+func inboundTraffic(app *model.Application) (*timeseries.TimeSeries, *timeseries.TimeSeries) {
+	var connections []*model.Connection
+	connects := timeseries.NewAggregate(timeseries.NanSum)
+	for _, c := range app.Downstreams {
+		if c.Instance.OwnerId != app.Id {
+			connections = append(connections, c)
+			connects.Add(c.Connects)
+		}
+	}
+	return model.GetConnectionsRequestsSum(connections), connects.Get()
+}
+
+// detectShifts returns the start and end indexes of the periods during which the share of the i-th application
+// increased by at least trafficShiftMinChange. A shift still in progress at the last point isn't returned.
+func detectShifts(points []trafficPoint, i int) [][2]int {
+	var res [][2]int
+	start := -1
+	finish := func(end int) {
+		if start >= 0 && points[end].shares[i]-points[start].shares[i] >= trafficShiftMinChange {
+			res = append(res, [2]int{start, end})
+		}
+		start = -1
+	}
+	for k := 1; k < len(points); k++ {
+		if points[k].shares[i]-points[k-1].shares[i] >= trafficShiftMinStep {
+			if start < 0 {
+				start = k - 1
+			}
+			continue
+		}
+		if start >= 0 {
+			finish(k - 1)
+		}
+	}
+	return res
+}
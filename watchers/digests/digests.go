@@ -0,0 +1,147 @@
+package digests
+
+import (
+	"context"
+	"fmt"
+	"github.com/coroot/coroot/auditor"
+	"github.com/coroot/coroot/cache"
+	cloud_pricing "github.com/coroot/coroot/cloud-pricing"
+	"github.com/coroot/coroot/constructor"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/notifications"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+	"time"
+)
+
+const (
+	sendTimeout = 30 * time.Second
+)
+
+type Watcher struct {
+	db      *db.DB
+	cache   *cache.Cache
+	pricing *cloud_pricing.Manager
+
+	worldMemoryLimit int64
+}
+
+func NewWatcher(db *db.DB, cache *cache.Cache, pricing *cloud_pricing.Manager, worldMemoryLimit int64) *Watcher {
+	return &Watcher{db: db, cache: cache, pricing: pricing, worldMemoryLimit: worldMemoryLimit}
+}
+
+func (w *Watcher) Start(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			projects, err := w.db.GetProjects()
+			if err != nil {
+				klog.Errorln("failed to get projects:", err)
+				continue
+			}
+			for _, project := range projects {
+				now := time.Now().In(project.Location())
+				for _, s := range project.Settings.DigestSchedules {
+					if !due(s, now, interval) {
+						continue
+					}
+					w.send(project, s)
+				}
+			}
+		}
+	}()
+}
+
+func due(s model.DigestSchedule, now time.Time, interval time.Duration) bool {
+	cron, err := utils.ParseCron(s.CronExpr())
+	if err != nil {
+		klog.Warningf("invalid schedule of digest %s: %s", s.Id, err)
+		return false
+	}
+	if !cron.Within(now, interval) {
+		return false
+	}
+	return s.SentAt.IsZero() || now.Sub(s.SentAt.ToStandard()) > 2*interval
+}
+
+func (w *Watcher) send(project *db.Project, s model.DigestSchedule) {
+	world, err := w.loadWorld(project, s.Duration())
+	if err != nil {
+		klog.Errorln("failed to load world:", err)
+		return
+	}
+	auditor.Audit(world, project)
+	digest := model.NewDigest(world, s)
+
+	integrations := project.Settings.Integrations
+	sent := false
+	if cfg := integrations.Slack; s.Slack && cfg != nil {
+		channel := cfg.DefaultChannel
+		if s.SlackChannel != "" {
+			channel = s.SlackChannel
+		}
+		err := w.sendDigest(project, s, db.IntegrationTypeSlack, notifications.NewSlack(cfg.Token, channel), digest)
+		if err != nil {
+			klog.Errorln(err)
+		} else {
+			sent = true
+		}
+	}
+	if cfg := integrations.Teams; s.Teams && cfg != nil {
+		err := w.sendDigest(project, s, db.IntegrationTypeTeams, notifications.NewTeams(cfg.WebhookUrl), digest)
+		if err != nil {
+			klog.Errorln(err)
+		} else {
+			sent = true
+		}
+	}
+	if cfg := integrations.Mattermost; s.Mattermost && cfg != nil {
+		err := w.sendDigest(project, s, db.IntegrationTypeMattermost, notifications.NewMattermost(cfg.WebhookUrl, cfg.Channel), digest)
+		if err != nil {
+			klog.Errorln(err)
+		} else {
+			sent = true
+		}
+	}
+	if !sent {
+		return
+	}
+	klog.Infof("%s: digest %s sent", project.Id, s.Id)
+	if err := w.db.SaveDigestSentAt(project.Id, s.Id, timeseries.Now()); err != nil {
+		klog.Errorln("failed to save digest:", err)
+	}
+}
+
+type digestNotifier interface {
+	SendDigest(ctx context.Context, project *db.Project, digest *model.Digest) error
+}
+
+func (w *Watcher) sendDigest(project *db.Project, s model.DigestSchedule, destination db.IntegrationType, client digestNotifier, digest *model.Digest) error {
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	err := client.SendDigest(ctx, project, digest)
+	notifications.LogDelivery(w.db, db.NotificationDelivery{
+		ProjectId:   project.Id,
+		Kind:        db.NotificationKindDigest,
+		Destination: string(destination),
+		Subject:     s.Name,
+	}, started, err)
+	return err
+}
+
+func (w *Watcher) loadWorld(project *db.Project, duration timeseries.Duration) (*model.World, error) {
+	cc := w.cache.GetCacheClient(project)
+	cacheTo, err := cc.GetTo()
+	if err != nil {
+		return nil, err
+	}
+	if cacheTo.IsZero() {
+		return nil, fmt.Errorf("cache is empty")
+	}
+	step := timeseries.IncreaseStepForBigDurations(duration, project.Prometheus.RefreshInterval)
+	to := cacheTo.Truncate(step)
+	from := to.Add(-duration)
+	return constructor.New(w.db, project, cc, w.pricing).WithMemoryLimit(w.worldMemoryLimit).LoadWorld(context.Background(), from, to, step, nil)
+}
@@ -0,0 +1,40 @@
+package gcp
+
+import (
+	"context"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/gcp"
+	"github.com/coroot/coroot/tsdb"
+	"github.com/coroot/coroot/watchers/poller"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Watcher polls the Cloud SQL and Memorystore instances selected in the GCP integration of each project.
+// The instances are listed on every poll, so the ones created later are picked up without changing the integration.
+type Watcher struct {
+	*poller.Poller[db.IntegrationGCP, *gcp.Client]
+}
+
+func NewWatcher(database *db.DB, tsdb *tsdb.Storage) *Watcher {
+	return &Watcher{poller.New(database, tsdb, "Cloud SQL and Memorystore instances", config, newClient, collect)}
+}
+
+func config(p *db.Project) *db.IntegrationGCP {
+	return p.Settings.Integrations.GCP
+}
+
+func newClient(cfg db.IntegrationGCP) (*gcp.Client, error) {
+	return gcp.NewClient(cfg.ClientConfig())
+}
+
+func collect(ctx context.Context, client *gcp.Client, cfg db.IntegrationGCP) ([]prompb.TimeSeries, error) {
+	sqlInstances, err := client.CloudSqlInstances(ctx, cfg.CloudSqlInstances)
+	if err != nil {
+		return nil, err
+	}
+	redisInstances, err := client.MemorystoreInstances(ctx, cfg.MemorystoreInstances)
+	if err != nil {
+		return nil, err
+	}
+	return client.Collect(ctx, sqlInstances, redisInstances), nil
+}
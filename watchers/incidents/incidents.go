@@ -18,10 +18,25 @@ type Watcher struct {
 	db       *db.DB
 	cache    *cache.Cache
 	notifier *notifications.IncidentNotifier
+	webhooks *notifications.WebhookNotifier
+	alerts   *notifications.AlertmanagerNotifier
+	auditor  *auditor.Incremental
+	flaps    map[db.ProjectId]*model.FlapSuppressor
+
+	worldMemoryLimit int64
 }
 
-func NewWatcher(db *db.DB, cache *cache.Cache, notifier *notifications.IncidentNotifier) *Watcher {
-	return &Watcher{db: db, cache: cache, notifier: notifier}
+func NewWatcher(database *db.DB, cache *cache.Cache, notifier *notifications.IncidentNotifier, webhooks *notifications.WebhookNotifier, alerts *notifications.AlertmanagerNotifier, worldMemoryLimit int64) *Watcher {
+	return &Watcher{
+		db:               database,
+		cache:            cache,
+		notifier:         notifier,
+		webhooks:         webhooks,
+		alerts:           alerts,
+		auditor:          auditor.NewIncremental(),
+		flaps:            map[db.ProjectId]*model.FlapSuppressor{},
+		worldMemoryLimit: worldMemoryLimit,
+	}
 }
 
 func (w *Watcher) Start(checkInterval time.Duration) {
@@ -52,15 +67,32 @@ func (w *Watcher) checkProject(project *db.Project) {
 		return
 	}
 
-	auditor.Audit(world, project)
+	w.auditor.Audit(world, project)
+
+	flaps := w.flaps[project.Id]
+	if flaps == nil {
+		flaps = model.NewFlapSuppressor()
+		w.flaps[project.Id] = flaps
+	}
+	present := map[model.ApplicationId]bool{}
 
+	now := timeseries.Now()
+	var values []model.CheckValue
+	var budgets []model.ErrorBudgetRecord
+	var rtts []model.NetworkRtt
 	for _, app := range world.Applications {
+		values = append(values, app.CheckValues()...)
+		budgets = append(budgets, model.NewErrorBudgetRecords(app, project.Prometheus.RefreshInterval)...)
+		rtts = append(rtts, app.NetworkRtts()...)
 		status := app.SLOStatus()
 		if status == model.UNKNOWN {
 			continue
 		}
 		apps++
-		now := timeseries.Now()
+		present[app.Id] = true
+		if status = flaps.Update(app.Id, status, project.Settings.NotificationLimits); status == model.UNKNOWN {
+			continue
+		}
 		incident, err := w.db.CreateOrUpdateIncident(project.Id, app.Id, now, status)
 		if err != nil {
 			klog.Errorln(err)
@@ -71,6 +103,33 @@ func (w *Watcher) checkProject(project *db.Project) {
 		}
 		w.notifier.Enqueue(project, app, incident, now)
 	}
+	flaps.Retain(present)
+	w.notifier.Escalate(project, world, now)
+	w.notifier.OpenTickets(project, world, now)
+	w.webhooks.Notify(project, world, now)
+	w.alerts.Notify(project, world, now)
+
+	if err := w.db.SaveCheckValues(project.Id, now, values); err != nil {
+		klog.Errorln("failed to save check values:", err)
+	}
+	if err := w.db.DeleteCheckValues(project.Id, now.Add(-model.CheckValuesRetention)); err != nil {
+		klog.Errorln("failed to delete outdated check values:", err)
+	}
+	if err := w.db.SaveErrorBudgetRecords(project.Id, budgets); err != nil {
+		klog.Errorln("failed to save error budget records:", err)
+	}
+	if err := w.db.DeleteErrorBudgetRecords(project.Id, now.Add(-model.ErrorBudgetRetention)); err != nil {
+		klog.Errorln("failed to delete outdated error budget records:", err)
+	}
+	if err := w.db.SaveNetworkRtts(project.Id, now, rtts); err != nil {
+		klog.Errorln("failed to save network RTTs:", err)
+	}
+	if err := w.db.DeleteNetworkRtts(project.Id, now.Add(-model.NetworkRttBaselineWindow)); err != nil {
+		klog.Errorln("failed to delete outdated network RTTs:", err)
+	}
+	if err := w.db.DeleteNotificationDeliveries(project.Id, now.Add(-db.NotificationDeliveryRetention)); err != nil {
+		klog.Errorln("failed to delete outdated notification deliveries:", err)
+	}
 }
 
 func (w *Watcher) loadWorld(project *db.Project) (*model.World, error) {
@@ -85,5 +144,5 @@ func (w *Watcher) loadWorld(project *db.Project) (*model.World, error) {
 	step := project.Prometheus.RefreshInterval
 	to := cacheTo.Truncate(step)
 	from := to.Add(-timeseries.Hour)
-	return constructor.New(w.db, project, cc, nil).LoadWorld(context.Background(), from, to, step, nil)
+	return constructor.New(w.db, project, cc, nil).WithMemoryLimit(w.worldMemoryLimit).LoadWorld(context.Background(), from, to, step, nil)
 }
@@ -0,0 +1,138 @@
+package poller
+
+import (
+	"context"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/tsdb"
+	"github.com/prometheus/prometheus/prompb"
+	"k8s.io/klog"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// maxConcurrentProjects limits the number of projects polled at the same time.
+const maxConcurrentProjects = 8
+
+// Poller periodically collects the metrics of a cloud integration (C is its config, T is the client) for each project
+// and stores them in the embedded TSDB, so the managed components get the same reports as the ones monitored by the agents.
+// The clients are kept between the polls, so their credentials and tokens are reused, and replaced once the integration changes.
+type Poller[C any, T any] struct {
+	db   *db.DB
+	tsdb *tsdb.Storage
+
+	name      string
+	config    func(p *db.Project) *C
+	newClient func(cfg C) (T, error)
+	collect   func(ctx context.Context, client T, cfg C) ([]prompb.TimeSeries, error)
+
+	lock     sync.Mutex
+	clients  map[db.ProjectId]*client[C, T]
+	reported map[db.ProjectId]bool
+}
+
+type client[C any, T any] struct {
+	cfg    C
+	client T
+}
+
+// New creates a poller of the metrics described by name (e.g., "RDS instances").
+// The config function returns nil if the project doesn't have the integration configured.
+func New[C any, T any](
+	database *db.DB, tsdb *tsdb.Storage, name string,
+	config func(p *db.Project) *C,
+	newClient func(cfg C) (T, error),
+	collect func(ctx context.Context, client T, cfg C) ([]prompb.TimeSeries, error),
+) *Poller[C, T] {
+	return &Poller[C, T]{
+		db: database, tsdb: tsdb, name: name, config: config, newClient: newClient, collect: collect,
+		clients: map[db.ProjectId]*client[C, T]{}, reported: map[db.ProjectId]bool{},
+	}
+}
+
+func (p *Poller[C, T]) Start(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			projects, err := p.db.GetProjects()
+			if err != nil {
+				klog.Errorln("failed to get projects:", err)
+				continue
+			}
+			sem := make(chan struct{}, maxConcurrentProjects)
+			wg := sync.WaitGroup{}
+			for _, project := range projects {
+				cfg := p.config(project)
+				if cfg == nil || !p.tsdbConfigured(project) {
+					continue
+				}
+				sem <- struct{}{}
+				wg.Add(1)
+				go func(projectId db.ProjectId, cfg C) {
+					defer func() {
+						<-sem
+						wg.Done()
+					}()
+					ctx, cancel := context.WithTimeout(context.Background(), interval)
+					defer cancel()
+					p.poll(ctx, projectId, cfg)
+				}(project.Id, *cfg)
+			}
+			wg.Wait()
+		}
+	}()
+}
+
+// tsdbConfigured reports whether the metrics of the project can be stored, the error is logged once per project.
+func (p *Poller[C, T]) tsdbConfigured(project *db.Project) bool {
+	var reason string
+	switch {
+	case p.tsdb == nil:
+		reason = "the embedded TSDB is disabled (see --embedded-tsdb)"
+	case !project.Prometheus.Embedded:
+		reason = "the project isn't configured to use the embedded TSDB"
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if reason == "" {
+		delete(p.reported, project.Id)
+		return true
+	}
+	if !p.reported[project.Id] {
+		klog.Errorf("%s: the metrics of %s aren't collected: %s", project.Id, p.name, reason)
+		p.reported[project.Id] = true
+	}
+	return false
+}
+
+func (p *Poller[C, T]) getClient(projectId db.ProjectId, cfg C) (T, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if c := p.clients[projectId]; c != nil && reflect.DeepEqual(c.cfg, cfg) {
+		return c.client, nil
+	}
+	c, err := p.newClient(cfg)
+	if err != nil {
+		return c, err
+	}
+	p.clients[projectId] = &client[C, T]{cfg: cfg, client: c}
+	return c, nil
+}
+
+func (p *Poller[C, T]) poll(ctx context.Context, projectId db.ProjectId, cfg C) {
+	t := time.Now()
+	c, err := p.getClient(projectId, cfg)
+	if err != nil {
+		klog.Errorln(projectId, err)
+		return
+	}
+	series, err := p.collect(ctx, c, cfg)
+	if err != nil {
+		klog.Errorln(projectId, err)
+		return
+	}
+	if err = p.tsdb.Write(ctx, projectId, &prompb.WriteRequest{Timeseries: series}); err != nil {
+		klog.Errorf("%s: failed to store the metrics of %s: %s", projectId, p.name, err)
+		return
+	}
+	klog.V(1).Infof("%s: collected %d series of %s in %s", projectId, len(series), p.name, time.Since(t).Truncate(time.Millisecond))
+}
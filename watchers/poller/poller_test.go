@@ -0,0 +1,47 @@
+package poller
+
+import (
+	"context"
+	"github.com/coroot/coroot/db"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type config struct {
+	Key       string
+	Instances []string
+}
+
+func TestGetClient(t *testing.T) {
+	created := 0
+	p := New(nil, nil, "test",
+		func(p *db.Project) *config { return nil },
+		func(cfg config) (int, error) {
+			created++
+			return created, nil
+		},
+		func(ctx context.Context, client int, cfg config) ([]prompb.TimeSeries, error) { return nil, nil },
+	)
+	c, err := p.getClient("p1", config{Key: "k", Instances: []string{"a"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c)
+
+	c, _ = p.getClient("p1", config{Key: "k", Instances: []string{"a"}})
+	assert.Equal(t, 1, c, "the client is reused")
+
+	c, _ = p.getClient("p2", config{Key: "k", Instances: []string{"a"}})
+	assert.Equal(t, 2, c)
+
+	c, _ = p.getClient("p1", config{Key: "k", Instances: []string{"b"}})
+	assert.Equal(t, 3, c, "the integration has changed")
+}
+
+func TestTsdbConfigured(t *testing.T) {
+	p := New[config, int](nil, nil, "test", nil, nil, nil)
+	project := &db.Project{Id: "p1"}
+	project.Prometheus.Embedded = true
+	assert.False(t, p.tsdbConfigured(project))
+	assert.True(t, p.reported["p1"])
+}
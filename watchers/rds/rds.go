@@ -0,0 +1,36 @@
+package rds
+
+import (
+	"context"
+	"github.com/coroot/coroot/aws"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/tsdb"
+	"github.com/coroot/coroot/watchers/poller"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Watcher polls the RDS and Aurora instances selected in the AWS integration of each project,
+// including their top queries if Performance Insights is enabled in the integration.
+type Watcher struct {
+	*poller.Poller[db.IntegrationAWS, *aws.Client]
+}
+
+func NewWatcher(database *db.DB, tsdb *tsdb.Storage) *Watcher {
+	return &Watcher{poller.New(database, tsdb, "RDS instances", config, newClient, collect)}
+}
+
+func config(p *db.Project) *db.IntegrationAWS {
+	return p.Settings.Integrations.AWS
+}
+
+func newClient(cfg db.IntegrationAWS) (*aws.Client, error) {
+	return aws.NewClient(cfg.ClientConfig())
+}
+
+func collect(ctx context.Context, client *aws.Client, cfg db.IntegrationAWS) ([]prompb.TimeSeries, error) {
+	instances, err := client.Instances(ctx, cfg.RdsInstances)
+	if err != nil {
+		return nil, err
+	}
+	return client.Collect(ctx, instances, cfg.PerformanceInsights), nil
+}
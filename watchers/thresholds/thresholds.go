@@ -0,0 +1,146 @@
+package thresholds
+
+import (
+	"context"
+	"fmt"
+	"github.com/coroot/coroot/auditor"
+	"github.com/coroot/coroot/cache"
+	"github.com/coroot/coroot/constructor"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+	"time"
+)
+
+const (
+	defaultWindow = 7 * timeseries.Day
+	maxPoints     = 2000
+)
+
+type Watcher struct {
+	db    *db.DB
+	cache *cache.Cache
+
+	worldMemoryLimit int64
+
+	// onLearned is called once new thresholds of a project are saved, as they change the checks of the reports.
+	onLearned func()
+}
+
+func NewWatcher(db *db.DB, cache *cache.Cache, worldMemoryLimit int64, onLearned func()) *Watcher {
+	return &Watcher{db: db, cache: cache, worldMemoryLimit: worldMemoryLimit, onLearned: onLearned}
+}
+
+func (w *Watcher) Start(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			projects, err := w.db.GetProjects()
+			if err != nil {
+				klog.Errorln("failed to get projects:", err)
+				continue
+			}
+			for _, project := range projects {
+				w.learnProject(project)
+			}
+		}
+	}()
+}
+
+func (w *Watcher) learnProject(project *db.Project) {
+	t := time.Now()
+	var learned int
+	defer func() {
+		klog.Infof("%s: learned %d thresholds in %s", project.Id, learned, time.Since(t).Truncate(time.Millisecond))
+		if learned > 0 && w.onLearned != nil {
+			w.onLearned()
+		}
+	}()
+
+	checkConfigs, err := w.db.GetCheckConfigs(project.Id)
+	if err != nil {
+		klog.Errorln("failed to get check configs:", err)
+		return
+	}
+	window := timeseries.Duration(0)
+	updateWindow := func(cfg model.CheckConfigSimple) {
+		if cfg.Adaptive != nil && windowOf(cfg.Adaptive) > window {
+			window = windowOf(cfg.Adaptive)
+		}
+	}
+	for appId := range checkConfigs {
+		for _, checkId := range auditor.AdaptiveThresholdChecks {
+			updateWindow(checkConfigs.GetSimple(checkId, appId))
+		}
+	}
+	for _, tpl := range project.Settings.CheckTemplates {
+		for _, cfg := range tpl.Checks {
+			updateWindow(cfg)
+		}
+	}
+	if window == 0 {
+		return
+	}
+
+	world, err := w.loadWorld(project, window)
+	if err != nil {
+		klog.Errorln("failed to load world:", err)
+		return
+	}
+	now := timeseries.Now()
+	for _, app := range world.Applications {
+		for _, checkId := range auditor.AdaptiveThresholdChecks {
+			cfg := world.CheckConfigs.GetSimple(checkId, app.Id)
+			if cfg.Adaptive == nil {
+				continue
+			}
+			metrics := auditor.AdaptiveThresholdMetrics(app, checkId)
+			if len(metrics) == 0 {
+				continue
+			}
+			from := world.Ctx.To.Add(-windowOf(cfg.Adaptive))
+			for i, m := range metrics {
+				metrics[i] = m.Map(func(t timeseries.Time, v float32) float32 {
+					if t.Before(from) {
+						return timeseries.NaN
+					}
+					return v
+				})
+			}
+			threshold := cfg.Adaptive.Calc(metrics...)
+			if timeseries.IsNaN(threshold) {
+				continue
+			}
+			if err := w.db.SaveCheckThreshold(project.Id, app.Id, checkId, threshold, now); err != nil {
+				klog.Errorln("failed to save threshold:", err)
+				continue
+			}
+			learned++
+		}
+	}
+}
+
+func (w *Watcher) loadWorld(project *db.Project, window timeseries.Duration) (*model.World, error) {
+	cc := w.cache.GetCacheClient(project)
+	cacheTo, err := cc.GetTo()
+	if err != nil {
+		return nil, err
+	}
+	if cacheTo.IsZero() {
+		return nil, fmt.Errorf("cache is empty")
+	}
+	step := project.Prometheus.RefreshInterval
+	if s := (window / maxPoints).Truncate(step); s > step {
+		step = s
+	}
+	to := cacheTo.Truncate(step)
+	from := to.Add(-window)
+	return constructor.New(w.db, project, cc, nil, constructor.OptionDoNotLoadRawSLIs).WithMemoryLimit(w.worldMemoryLimit).LoadWorld(context.Background(), from, to, step, nil)
+}
+
+func windowOf(at *model.AdaptiveThreshold) timeseries.Duration {
+	if at.Window > 0 {
+		return at.Window
+	}
+	return defaultWindow
+}